@@ -3,13 +3,16 @@ package agent
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/netip"
 	"os"
+	"os/exec"
 	"os/user"
 	"path/filepath"
 	"sort"
@@ -35,12 +38,17 @@ import (
 	"cdr.dev/slog"
 	"github.com/coder/retry"
 	"github.com/onchainengineering/hmi-w
+	"github.com/onchainengineering/hmi-wirtual/agent/agentcontainers"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentexec"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentlogfiles"
 	"github.com/onchainengineering/hmi-wirtual/agent/agentscripts"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentservices"
 	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
 	"github.com/onchainengineering/hmi-wirtual/agent/proto"
 	"github.com/onchainengineering/hmi-wirtual/agent/reconnectingpty"
 	"github.com/onchainengineering/hmi-wirtual/buildinfo"
 	"github.com/onchainengineering/hmi-wirtual/cli/gitauth"
+	"github.com/onchainengineering/hmi-wirtual/pty"
 	"github.com/onchainengineering/hmi-wirtual/tailnet"
 	tailnetproto "github.com/onchainengineering/hmi-wirtual/tailnet/proto"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
@@ -81,11 +89,31 @@ type Options struct {
 	ReportMetadataInterval       time.Duration
 	ServiceBannerRefreshInterval time.Duration
 	BlockFileTransfer            bool
+	// ReportTopProcesses is the number of busiest processes to report with
+	// stats, or zero to not report any.
+	ReportTopProcesses int
+	// Cgroups are the cgroups limiting the processes started by the agent,
+	// created by agentexec.SetupCgroups. OOM kills in them are reported as
+	// agent logs.
+	Cgroups *agentexec.Cgroups
+	// UpdatePublicKey is the ed25519 key that signs agent updates. Updates
+	// offered in the manifest are only installed if it's set.
+	UpdatePublicKey ed25519.PublicKey
+	// UpdateHTTPClient downloads agent updates.
+	UpdateHTTPClient *http.Client
+	// DevcontainerFolders are searched for devcontainer.json files once the
+	// startup scripts have run. Relative folders are resolved against the
+	// agent directory.
+	DevcontainerFolders []string
+	// LogFiles are glob patterns of local log files that are forwarded to
+	// wirtuald as log sources. Relative patterns are resolved against the
+	// agent directory.
+	LogFiles []string
 }
 
 type Client interface {
-	ConnectRPC23(ctx context.Context) (
-		proto.DRPCAgentClient23, tailnetproto.DRPCTailnetClient23, error,
+	ConnectRPC24(ctx context.Context) (
+		proto.DRPCAgentClient24, tailnetproto.DRPCTailnetClient24, error,
 	)
 	RewriteDERPMap(derpMap *tailcfg.DERPMap)
 }
@@ -134,12 +162,17 @@ func New(options Options) Agent {
 	if options.PortCacheDuration == 0 {
 		options.PortCacheDuration = 1 * time.Second
 	}
+	if options.UpdateHTTPClient == nil {
+		options.UpdateHTTPClient = http.DefaultClient
+	}
 
 	prometheusRegistry := options.PrometheusRegistry
 	if prometheusRegistry == nil {
 		prometheusRegistry = prometheus.NewRegistry()
 	}
 
+	updatedFrom, updatedLifecycle, updateInstall := takeUpdatedEnv()
+
 	hardCtx, hardCancel := context.WithCancel(context.Background())
 	gracefulCtx, gracefulCancel := context.WithCancel(hardCtx)
 	a := &agent{
@@ -169,6 +202,15 @@ func New(options Options) Agent {
 		subsystems:                         options.Subsystems,
 		logSender:                          agentsdk.NewLogSender(options.Logger),
 		blockFileTransfer:                  options.BlockFileTransfer,
+		reportTopProcesses:                 options.ReportTopProcesses,
+		cgroups:                            options.Cgroups,
+		updatePublicKey:                    options.UpdatePublicKey,
+		updateHTTPClient:                   options.UpdateHTTPClient,
+		devcontainerFolders:                options.DevcontainerFolders,
+		logFilePatterns:                    options.LogFiles,
+		updatedFrom:                        updatedFrom,
+		updatedLifecycle:                   updatedLifecycle,
+		updateInstall:                      updateInstall,
 
 		prometheusRegistry: prometheusRegistry,
 		metrics:            newAgentMetrics(prometheusRegistry),
@@ -219,12 +261,33 @@ type agent struct {
 	manifest                           atomic.Pointer[agentsdk.Manifest] // manifest is atomic because values can change after reconnection.
 	reportMetadataInterval             time.Duration
 	scriptRunner                       *agentscripts.Runner
+	serviceSupervisor                  *agentservices.Supervisor
+	devcontainerFolders                []string
+	devcontainers                      *agentcontainers.Manager
+	logFilePatterns                    []string
+	logFiles                           *agentlogfiles.Forwarder
 	announcementBanners                atomic.Pointer[[]wirtualsdk.BannerConfig] // announcementBanners is atomic because it is periodically updated.
 	announcementBannersRefreshInterval time.Duration
 	sessionToken                       atomic.Pointer[string]
 	sshServer                          *agentssh.Server
 	sshMaxTimeout                      time.Duration
 	blockFileTransfer                  bool
+	reportTopProcesses                 int
+	cgroups                            *agentexec.Cgroups
+
+	updatePublicKey  ed25519.PublicKey
+	updateHTTPClient *http.Client
+	// updating is set while an update is being installed, so only one runs
+	// at a time.
+	updating atomic.Bool
+	// updatedFrom is the version the agent was updated from, if it was
+	// started by an update rather than by the workspace, and
+	// updatedLifecycle the lifecycle state the older version was in.
+	updatedFrom      string
+	updatedLifecycle wirtualsdk.WorkspaceAgentLifecycle
+	// updateInstall is the executable of the older version, which this
+	// version replaces once it's running.
+	updateInstall string
 
 	lifecycleUpdate            chan struct{}
 	lifecycleReported          chan wirtualsdk.WorkspaceAgentLifecycle
@@ -247,6 +310,16 @@ func (a *agent) TailnetConn() *tailnet.Conn {
 }
 
 func (a *agent) init() {
+	a.devcontainers = agentcontainers.New(agentcontainers.Options{
+		Logger:  a.logger.Named("devcontainers"),
+		Folders: a.devcontainerFolders,
+		LogDir:  a.logDir,
+	})
+	a.logFiles = agentlogfiles.New(agentlogfiles.Options{
+		Logger:   a.logger.Named("logfiles"),
+		Patterns: a.logFilePatterns,
+		Enqueue:  a.logSender.Enqueue,
+	})
 	// pass the "hard" context because we explicitly close the SSH server as part of graceful shutdown.
 	sshSrv, err := agentssh.NewServer(a.hardCtx, a.logger.Named("ssh-server"), a.prometheusRegistry, a.filesystem, &agentssh.Config{
 		MaxTimeout:          a.sshMaxTimeout,
@@ -255,6 +328,12 @@ func (a *agent) init() {
 		UpdateEnv:           a.updateCommandEnv,
 		WorkingDirectory:    func() string { return a.manifest.Load().Directory },
 		BlockFileTransfer:   a.blockFileTransfer,
+		ReconnectingPTY: func(id uuid.UUID, cmd *pty.Cmd, conn net.Conn, offset int64, height, width uint16) error {
+			// Use the agent context rather than the session context so the
+			// shell survives the SSH session ending.
+			return a.reconnectingPTYServer.AttachSSH(a.gracefulCtx, id, cmd, conn, offset, height, width)
+		},
+		ContainerCommand: a.devcontainers.Command,
 	})
 	if err != nil {
 		panic(err)
@@ -273,6 +352,11 @@ func (a *agent) init() {
 	// Register runner metrics. If the prom registry is nil, the metrics
 	// will not report anywhere.
 	a.scriptRunner.RegisterMetrics(a.prometheusRegistry)
+	a.serviceSupervisor = agentservices.New(agentservices.Options{
+		LogDir:    a.logDir,
+		Logger:    a.logger.Named("services"),
+		SSHServer: sshSrv,
+	})
 
 	a.reconnectingPTYServer = reconnectingpty.NewServer(
 		a.logger.Named("reconnecting-pty"),
@@ -280,6 +364,9 @@ func (a *agent) init() {
 		a.metrics.connectionsTotal, a.metrics.reconnectingPTYErrors,
 		a.reconnectingPTYTimeout,
 	)
+	if a.cgroups != nil {
+		go a.reportCgroupOOMKills(a.hardCtx)
+	}
 	go a.runLoop()
 }
 
@@ -313,6 +400,16 @@ func (a *agent) runLoop() {
 	}
 }
 
+// createAppHealthCommand runs app health check commands like agent scripts
+// and metadata, through the SSH server so they get the same environment.
+func (a *agent) createAppHealthCommand(ctx context.Context, command string) (*exec.Cmd, error) {
+	cmdPty, err := a.sshServer.CreateCommand(ctx, command, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cmdPty.AsExec(), nil
+}
+
 func (a *agent) collectMetadata(ctx context.Context, md wirtualsdk.WorkspaceAgentMetadataDescription, now time.Time) *wirtualsdk.WorkspaceAgentMetadataResult {
 	var out bytes.Buffer
 	result := &wirtualsdk.WorkspaceAgentMetadataResult{
@@ -392,7 +489,7 @@ func (t *trySingleflight) Do(key string, fn func()) {
 	fn()
 }
 
-func (a *agent) reportMetadata(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
+func (a *agent) reportMetadata(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
 	tickerDone := make(chan struct{})
 	collectDone := make(chan struct{})
 	ctx, cancel := context.WithCancel(ctx)
@@ -608,7 +705,7 @@ func (a *agent) reportMetadata(ctx context.Context, aAPI proto.DRPCAgentClient23
 
 // reportLifecycle reports the current lifecycle state once. All state
 // changes are reported in order.
-func (a *agent) reportLifecycle(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
+func (a *agent) reportLifecycle(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
 	for {
 		select {
 		case <-a.lifecycleUpdate:
@@ -690,7 +787,7 @@ func (a *agent) setLifecycle(state wirtualsdk.WorkspaceAgentLifecycle) {
 // fetchServiceBannerLoop fetches the service banner on an interval.  It will
 // not be fetched immediately; the expectation is that it is primed elsewhere
 // (and must be done before the session actually starts).
-func (a *agent) fetchServiceBannerLoop(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
+func (a *agent) fetchServiceBannerLoop(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
 	ticker := time.NewTicker(a.announcementBannersRefreshInterval)
 	defer ticker.Stop()
 	for {
@@ -726,7 +823,7 @@ func (a *agent) run() (retErr error) {
 	a.sessionToken.Store(&sessionToken)
 
 	// ConnectRPC returns the dRPC connection we use for the Agent and Tailnet v2+ APIs
-	aAPI, tAPI, err := a.client.ConnectRPC23(a.hardCtx)
+	aAPI, tAPI, err := a.client.ConnectRPC24(a.hardCtx)
 	if err != nil {
 		return err
 	}
@@ -743,7 +840,7 @@ func (a *agent) run() (retErr error) {
 	connMan := newAPIConnRoutineManager(a.gracefulCtx, a.hardCtx, a.logger, aAPI, tAPI)
 
 	connMan.startAgentAPI("init notification banners", gracefulShutdownBehaviorStop,
-		func(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
+		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
 			bannersProto, err := aAPI.GetAnnouncementBanners(ctx, &proto.GetAnnouncementBannersRequest{})
 			if err != nil {
 				return xerrors.Errorf("fetch service banner: %w", err)
@@ -760,7 +857,7 @@ func (a *agent) run() (retErr error) {
 	// sending logs gets gracefulShutdownBehaviorRemain because we want to send logs generated by
 	// shutdown scripts.
 	connMan.startAgentAPI("send logs", gracefulShutdownBehaviorRemain,
-		func(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
+		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
 			err := a.logSender.SendLoop(ctx, aAPI)
 			if xerrors.Is(err, agentsdk.LogLimitExceededError) {
 				// we don't want this error to tear down the API connection and propagate to the
@@ -800,22 +897,46 @@ func (a *agent) run() (retErr error) {
 	connMan.startAgentAPI("handle manifest", gracefulShutdownBehaviorStop, a.handleManifest(manifestOK))
 
 	connMan.startAgentAPI("app health reporter", gracefulShutdownBehaviorStop,
-		func(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
+		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
 			if err := manifestOK.wait(ctx); err != nil {
 				return xerrors.Errorf("no manifest: %w", err)
 			}
 			manifest := a.manifest.Load()
 			NewWorkspaceAppHealthReporter(
-				a.logger, manifest.Apps, agentsdk.AppHealthPoster(aAPI),
+				a.logger, manifest.Apps, agentsdk.AppHealthPoster(aAPI), a.createAppHealthCommand,
 			)(ctx)
 			return nil
 		})
 
+	connMan.startAgentAPI("report service statuses", gracefulShutdownBehaviorStop,
+		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+			if err := manifestOK.wait(ctx); err != nil {
+				return xerrors.Errorf("no manifest: %w", err)
+			}
+			return a.serviceSupervisor.ReportLoop(ctx, aAPI.BatchUpdateServiceStatuses)
+		})
+
+	connMan.startAgentAPI("report dev container statuses", gracefulShutdownBehaviorStop,
+		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+			if err := manifestOK.wait(ctx); err != nil {
+				return xerrors.Errorf("no manifest: %w", err)
+			}
+			return a.devcontainers.ReportLoop(ctx, aAPI.BatchUpdateDevcontainerStatuses)
+		})
+
+	connMan.startAgentAPI("forward log files", gracefulShutdownBehaviorStop,
+		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+			if err := manifestOK.wait(ctx); err != nil {
+				return xerrors.Errorf("no manifest: %w", err)
+			}
+			return a.logFiles.Run(ctx, a.manifest.Load().Directory, aAPI.BatchCreateLogSources)
+		})
+
 	connMan.startAgentAPI("create or update network", gracefulShutdownBehaviorStop,
 		a.createOrUpdateNetwork(manifestOK, networkOK))
 
 	connMan.startTailnetAPI("coordination", gracefulShutdownBehaviorStop,
-		func(ctx context.Context, tAPI tailnetproto.DRPCTailnetClient23) error {
+		func(ctx context.Context, tAPI tailnetproto.DRPCTailnetClient24) error {
 			if err := networkOK.wait(ctx); err != nil {
 				return xerrors.Errorf("no network: %w", err)
 			}
@@ -824,7 +945,7 @@ func (a *agent) run() (retErr error) {
 	)
 
 	connMan.startTailnetAPI("derp map subscriber", gracefulShutdownBehaviorStop,
-		func(ctx context.Context, tAPI tailnetproto.DRPCTailnetClient23) error {
+		func(ctx context.Context, tAPI tailnetproto.DRPCTailnetClient24) error {
 			if err := networkOK.wait(ctx); err != nil {
 				return xerrors.Errorf("no network: %w", err)
 			}
@@ -833,7 +954,7 @@ func (a *agent) run() (retErr error) {
 
 	connMan.startAgentAPI("fetch service banner loop", gracefulShutdownBehaviorStop, a.fetchServiceBannerLoop)
 
-	connMan.startAgentAPI("stats report loop", gracefulShutdownBehaviorStop, func(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
+	connMan.startAgentAPI("stats report loop", gracefulShutdownBehaviorStop, func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
 		if err := networkOK.wait(ctx); err != nil {
 			return xerrors.Errorf("no network: %w", err)
 		}
@@ -844,8 +965,8 @@ func (a *agent) run() (retErr error) {
 }
 
 // handleManifest returns a function that fetches and processes the manifest
-func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
-	return func(ctx context.Context, aAPI proto.DRPCAgentClient23) error {
+func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+	return func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
 		var (
 			sentResult = false
 			err        error
@@ -897,7 +1018,12 @@ func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context,
 		manifestOK.complete(nil)
 		sentResult = true
 
+		a.handleAgentUpdate(ctx, manifest.AgentUpdate)
+
 		// The startup script should only execute on the first run!
+		if oldManifest == nil && a.updatedFrom != "" {
+			return a.resumeAfterUpdate(ctx, aAPI, manifest)
+		}
 		if oldManifest == nil {
 			a.setLifecycle(wirtualsdk.WorkspaceAgentLifecycleStarting)
 
@@ -943,6 +1069,19 @@ func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context,
 				}
 				a.metrics.startupScriptSeconds.WithLabelValues(label).Set(dur)
 				a.scriptRunner.StartCron()
+
+				// Services commonly depend on tools installed by the
+				// startup scripts, so they are started afterwards.
+				err = a.serviceSupervisor.Start(manifest.Services)
+				if err != nil {
+					a.logger.Warn(ctx, "start services failed", slog.Error(err))
+				}
+				// Dev containers are started last for the same reason,
+				// the scripts may clone the repositories defining them.
+				err = a.devcontainers.Start(manifest.Directory)
+				if err != nil {
+					a.logger.Warn(ctx, "start dev containers failed", slog.Error(err))
+				}
 			})
 			if err != nil {
 				return xerrors.Errorf("track conn goroutine: %w", err)
@@ -954,8 +1093,8 @@ func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context,
 
 // createOrUpdateNetwork waits for the manifest to be set using manifestOK, then creates or updates
 // the tailnet using the information in the manifest
-func (a *agent) createOrUpdateNetwork(manifestOK, networkOK *checkpoint) func(context.Context, proto.DRPCAgentClient23) error {
-	return func(ctx context.Context, _ proto.DRPCAgentClient23) (retErr error) {
+func (a *agent) createOrUpdateNetwork(manifestOK, networkOK *checkpoint) func(context.Context, proto.DRPCAgentClient24) error {
+	return func(ctx context.Context, _ proto.DRPCAgentClient24) (retErr error) {
 		if err := manifestOK.wait(ctx); err != nil {
 			return xerrors.Errorf("no manifest: %w", err)
 		}
@@ -970,7 +1109,7 @@ func (a *agent) createOrUpdateNetwork(manifestOK, networkOK *checkpoint) func(co
 		if network == nil {
 			// use the graceful context here, because creating the tailnet is not itself tied to the
 			// agent API.
-			network, err = a.createTailnet(a.gracefulCtx, manifest.AgentID, manifest.DERPMap, manifest.DERPForceWebSockets, manifest.DisableDirectConnections)
+			network, err = a.createTailnet(a.gracefulCtx, manifest.AgentID, manifest.DERPMap, manifest.DERPForceWebSockets, manifest.DisableDirectConnections, manifest.DNSRecords)
 			if err != nil {
 				return xerrors.Errorf("create tailnet: %w", err)
 			}
@@ -988,7 +1127,7 @@ func (a *agent) createOrUpdateNetwork(manifestOK, networkOK *checkpoint) func(co
 			}
 		} else {
 			// Update the wireguard IPs if the agent ID changed.
-			err := network.SetAddresses(a.wireguardAddresses(manifest.AgentID))
+			err := network.SetAddresses(a.wireguardAddresses(manifest.AgentID, manifest.DNSRecords))
 			if err != nil {
 				a.logger.Error(a.gracefulCtx, "update tailnet addresses", slog.Error(err))
 			}
@@ -1086,14 +1225,14 @@ func (a *agent) updateCommandEnv(current []string) (updated []string, err error)
 	return updated, nil
 }
 
-func (*agent) wireguardAddresses(agentID uuid.UUID) []netip.Prefix {
-	return []netip.Prefix{
+func (*agent) wireguardAddresses(agentID uuid.UUID, dnsRecords []agentsdk.DNSRecord) []netip.Prefix {
+	return append([]netip.Prefix{
 		// This is the IP that should be used primarily.
 		tailnet.TailscaleServicePrefix.PrefixFromUUID(agentID),
 		// We'll need this address for CoderVPN, but aren't using it from clients until that feature
 		// is ready
 		tailnet.CoderServicePrefix.PrefixFromUUID(agentID),
-	}
+	}, dnsRecordPrefixes(agentID, dnsRecords)...)
 }
 
 func (a *agent) trackGoroutine(fn func()) error {
@@ -1110,7 +1249,7 @@ func (a *agent) trackGoroutine(fn func()) error {
 	return nil
 }
 
-func (a *agent) createTailnet(ctx context.Context, agentID uuid.UUID, derpMap *tailcfg.DERPMap, derpForceWebSockets, disableDirectConnections bool) (_ *tailnet.Conn, err error) {
+func (a *agent) createTailnet(ctx context.Context, agentID uuid.UUID, derpMap *tailcfg.DERPMap, derpForceWebSockets, disableDirectConnections bool, dnsRecords []agentsdk.DNSRecord) (_ *tailnet.Conn, err error) {
 	// Inject `WIRTUAL_AGENT_HEADER` into the DERP header.
 	var header http.Header
 	if client, ok := a.client.(*agentsdk.Client); ok {
@@ -1120,7 +1259,7 @@ func (a *agent) createTailnet(ctx context.Context, agentID uuid.UUID, derpMap *t
 	}
 	network, err := tailnet.NewConn(&tailnet.Options{
 		ID:                  agentID,
-		Addresses:           a.wireguardAddresses(agentID),
+		Addresses:           a.wireguardAddresses(agentID, dnsRecords),
 		DERPMap:             derpMap,
 		DERPForceWebSockets: derpForceWebSockets,
 		DERPHeader:          &header,
@@ -1220,6 +1359,38 @@ func (a *agent) createTailnet(ctx context.Context, agentID uuid.UUID, derpMap *t
 		return nil, err
 	}
 
+	mtuProbeListener, err := network.Listen("tcp", ":"+strconv.Itoa(workspacesdk.AgentMTUProbePort))
+	if err != nil {
+		return nil, xerrors.Errorf("listen for mtu probes: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = mtuProbeListener.Close()
+		}
+	}()
+	if err = a.trackGoroutine(func() {
+		var wg sync.WaitGroup
+		for {
+			conn, err := mtuProbeListener.Accept()
+			if err != nil {
+				if !a.isClosed() {
+					a.logger.Debug(ctx, "mtu probe listener failed", slog.Error(err))
+				}
+				break
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if pErr := tailnet.ServeMTUProbe(conn); pErr != nil {
+					a.logger.Debug(ctx, "mtu probe ended with error", slog.Error(pErr))
+				}
+			}()
+		}
+		wg.Wait()
+	}); err != nil {
+		return nil, err
+	}
+
 	apiListener, err := network.Listen("tcp", ":"+strconv.Itoa(workspacesdk.AgentHTTPAPIServerPort))
 	if err != nil {
 		return nil, xerrors.Errorf("api listener: %w", err)
@@ -1254,12 +1425,18 @@ func (a *agent) createTailnet(ctx context.Context, agentID uuid.UUID, derpMap *t
 		return nil, err
 	}
 
+	for _, record := range dnsRecords {
+		if err = a.serveDNSRecord(ctx, network, agentID, record); err != nil {
+			return nil, err
+		}
+	}
+
 	return network, nil
 }
 
 // runCoordinator runs a coordinator and returns whether a reconnect
 // should occur.
-func (a *agent) runCoordinator(ctx context.Context, tClient tailnetproto.DRPCTailnetClient23, network *tailnet.Conn) error {
+func (a *agent) runCoordinator(ctx context.Context, tClient tailnetproto.DRPCTailnetClient24, network *tailnet.Conn) error {
 	defer a.logger.Debug(ctx, "disconnected from coordination RPC")
 	// we run the RPC on the hardCtx so that we have a chance to send the disconnect message if we
 	// gracefully shut down.
@@ -1306,7 +1483,7 @@ func (a *agent) runCoordinator(ctx context.Context, tClient tailnetproto.DRPCTai
 }
 
 // runDERPMapSubscriber runs a coordinator and returns if a reconnect should occur.
-func (a *agent) runDERPMapSubscriber(ctx context.Context, tClient tailnetproto.DRPCTailnetClient23, network *tailnet.Conn) error {
+func (a *agent) runDERPMapSubscriber(ctx context.Context, tClient tailnetproto.DRPCTailnetClient24, network *tailnet.Conn) error {
 	defer a.logger.Debug(ctx, "disconnected from derp map RPC")
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -1413,6 +1590,9 @@ func (a *agent) Collect(ctx context.Context, networkStats map[netlogtype.Connect
 	// currentConnections behaves like a hypothetical `GaugeFuncVec` and is only set at collection time.
 	a.metrics.currentConnections.WithLabelValues("p2p").Set(float64(p2pConns))
 	a.metrics.currentConnections.WithLabelValues("derp").Set(float64(derpConns))
+	if a.reportTopProcesses > 0 {
+		a.collectTopProcesses(ctx)
+	}
 	metricsCtx, cancelFunc := context.WithTimeout(ctx, 5*time.Second)
 	defer cancelFunc()
 	a.logger.Debug(ctx, "collecting agent metrics for stats")
@@ -1541,6 +1721,19 @@ func (a *agent) Close() error {
 	// they might hang instead of being closed.
 	a.gracefulCancel()
 
+	// Stop services before the shutdown scripts run so that the scripts
+	// can safely clean up after them.
+	err = a.serviceSupervisor.Close()
+	if err != nil {
+		a.logger.Error(a.hardCtx, "service supervisor close", slog.Error(err))
+	}
+	// Dev containers are left running, but builds in progress are
+	// cancelled.
+	err = a.devcontainers.Close()
+	if err != nil {
+		a.logger.Error(a.hardCtx, "dev containers close", slog.Error(err))
+	}
+
 	lifecycleState := wirtualsdk.WorkspaceAgentLifecycleOff
 	err = a.scriptRunner.Execute(a.hardCtx, agentscripts.ExecuteStopScripts)
 	if err != nil {
@@ -1676,8 +1869,8 @@ const (
 
 type apiConnRoutineManager struct {
 	logger    slog.Logger
-	aAPI      proto.DRPCAgentClient23
-	tAPI      tailnetproto.DRPCTailnetClient23
+	aAPI      proto.DRPCAgentClient24
+	tAPI      tailnetproto.DRPCTailnetClient24
 	eg        *errgroup.Group
 	stopCtx   context.Context
 	remainCtx context.Context
@@ -1685,7 +1878,7 @@ type apiConnRoutineManager struct {
 
 func newAPIConnRoutineManager(
 	gracefulCtx, hardCtx context.Context, logger slog.Logger,
-	aAPI proto.DRPCAgentClient23, tAPI tailnetproto.DRPCTailnetClient23,
+	aAPI proto.DRPCAgentClient24, tAPI tailnetproto.DRPCTailnetClient24,
 ) *apiConnRoutineManager {
 	// routines that remain in operation during graceful shutdown use the remainCtx.  They'll still
 	// exit if the errgroup hits an error, which usually means a problem with the conn.
@@ -1718,7 +1911,7 @@ func newAPIConnRoutineManager(
 // but for Tailnet.
 func (a *apiConnRoutineManager) startAgentAPI(
 	name string, behavior gracefulShutdownBehavior,
-	f func(context.Context, proto.DRPCAgentClient23) error,
+	f func(context.Context, proto.DRPCAgentClient24) error,
 ) {
 	logger := a.logger.With(slog.F("name", name))
 	var ctx context.Context
@@ -1755,7 +1948,7 @@ func (a *apiConnRoutineManager) startAgentAPI(
 // but for the Agent API.
 func (a *apiConnRoutineManager) startTailnetAPI(
 	name string, behavior gracefulShutdownBehavior,
-	f func(context.Context, tailnetproto.DRPCTailnetClient23) error,
+	f func(context.Context, tailnetproto.DRPCTailnetClient24) error,
 ) {
 	logger := a.logger.With(slog.F("name", name))
 	var ctx context.Context
@@ -0,0 +1,702 @@
+// Package agentcontainers discovers the dev containers defined in a
+// workspace and builds, starts and stops them with the local Docker or
+// Podman daemon.
+package agentcontainers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"cdr.dev/slog"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentexec"
+	"github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/pty"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// The labels of the containers created for dev containers. They match the
+// labels set by the dev containers CLI, so containers it created are picked
+// up too.
+const (
+	LabelLocalFolder = "devcontainer.local_folder"
+	LabelConfigFile  = "devcontainer.config_file"
+)
+
+// keepAliveScript is run in containers whose command is overridden, so they
+// keep running until they're stopped.
+const keepAliveScript = `echo Container started
+trap "exit 0" 15
+while sleep 1 & wait $!; do :; done`
+
+// loginShellScript starts the login shell of the user in the container.
+const loginShellScript = `shell=$(getent passwd "$(id -un)" 2>/dev/null | cut -d: -f7); exec "${shell:-/bin/sh}" -l`
+
+// reportInterval is how often the report loop checks the containers for
+// changes made outside of the agent, for example with `docker stop`.
+const reportInterval = 30 * time.Second
+
+// ErrNotFound is returned when a name does not match any dev container.
+var ErrNotFound = xerrors.New("dev container not found")
+
+// ReportStatusesFunc sends dev container statuses to wirtuald.
+type ReportStatusesFunc func(context.Context, *proto.BatchUpdateDevcontainerStatusesRequest) (*proto.BatchUpdateDevcontainerStatusesResponse, error)
+
+// Options are a set of options for the manager.
+type Options struct {
+	Logger slog.Logger
+	// Folders are searched for devcontainer.json files. They may be glob
+	// patterns, relative folders are resolved against the directory passed
+	// to Start.
+	Folders []string
+	// LogDir is where the output of building and starting each dev
+	// container is written.
+	LogDir string
+	// Socket is the Docker or Podman socket. Defaults to FindSocket.
+	Socket string
+	// CLI is the docker or podman binary that runs commands in the
+	// containers. Defaults to the one matching the socket in PATH.
+	CLI string
+}
+
+// Manager builds and starts the dev containers found in the folders, and
+// creates the commands that run in them.
+type Manager struct {
+	Options
+
+	docker *dockerClient
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// updated is signaled whenever the state of a dev container changes.
+	updated chan struct{}
+
+	mu            sync.Mutex
+	started       bool
+	folders       []string
+	devcontainers map[string]*devcontainer
+}
+
+type devcontainer struct {
+	Discovered
+	// busy is set while the container is being built or started.
+	busy   bool
+	status wirtualsdk.WorkspaceAgentDevcontainer
+}
+
+// New creates a manager. Dev containers are not discovered until Start is
+// called.
+func New(opts Options) *Manager {
+	if opts.Socket == "" {
+		opts.Socket = FindSocket()
+	}
+	if opts.CLI == "" {
+		opts.CLI = findCLI(opts.Socket)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		Options:       opts,
+		ctx:           ctx,
+		cancel:        cancel,
+		updated:       make(chan struct{}, 1),
+		devcontainers: map[string]*devcontainer{},
+	}
+	if opts.Socket != "" {
+		m.docker = newDockerClient(opts.Socket)
+	}
+	return m
+}
+
+// findCLI returns the binary that talks to the same daemon as the socket.
+func findCLI(socket string) string {
+	names := []string{"docker", "podman"}
+	if strings.Contains(socket, "podman") {
+		names = []string{"podman", "docker"}
+	}
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Enabled returns whether any folders are searched for dev containers.
+func (m *Manager) Enabled() bool {
+	return len(m.Folders) > 0
+}
+
+// Start discovers the dev containers and starts them in the background.
+// Relative folders are resolved against dir. It may only be called once.
+func (m *Manager) Start(dir string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return xerrors.New("start: already started")
+	}
+	m.started = true
+	if !m.Enabled() {
+		return nil
+	}
+	if m.docker == nil {
+		return xerrors.New("no Docker or Podman socket found, set DOCKER_HOST to the socket of the daemon")
+	}
+
+	for _, folder := range m.Folders {
+		if !filepath.IsAbs(folder) {
+			folder = filepath.Join(dir, folder)
+		}
+		m.folders = append(m.folders, folder)
+	}
+	m.discoverLocked()
+	m.Logger.Info(m.ctx, "starting dev containers", slog.F("count", len(m.devcontainers)), slog.F("folders", m.folders))
+	for _, dc := range m.devcontainers {
+		m.upLocked(dc, false)
+	}
+	return nil
+}
+
+// discoverLocked adds the dev containers found since the last discovery.
+// Dev containers whose config was removed are kept, as their container may
+// still be running.
+func (m *Manager) discoverLocked() {
+	for _, d := range Discover(m.folders) {
+		if _, ok := m.devcontainers[d.Name]; ok {
+			continue
+		}
+		m.devcontainers[d.Name] = &devcontainer{
+			Discovered: d,
+			status: wirtualsdk.WorkspaceAgentDevcontainer{
+				Name:            d.Name,
+				WorkspaceFolder: d.LocalFolder,
+				ConfigPath:      d.ConfigPath,
+				State:           wirtualsdk.WorkspaceAgentDevcontainerStopped,
+			},
+		}
+	}
+}
+
+// Devcontainers returns the dev containers along with the current state of
+// their containers, sorted by name.
+func (m *Manager) Devcontainers(ctx context.Context) ([]wirtualsdk.WorkspaceAgentDevcontainer, error) {
+	m.mu.Lock()
+	started := m.started && m.docker != nil
+	if started {
+		m.discoverLocked()
+	}
+	m.mu.Unlock()
+	if !started {
+		return []wirtualsdk.WorkspaceAgentDevcontainer{}, nil
+	}
+
+	containers, err := m.docker.listContainers(ctx, LabelConfigFile)
+	if err != nil {
+		return nil, xerrors.Errorf("list containers: %w", err)
+	}
+	byConfig := map[string]dockerContainer{}
+	for _, c := range containers {
+		byConfig[c.Labels[LabelConfigFile]] = c
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]wirtualsdk.WorkspaceAgentDevcontainer, 0, len(m.devcontainers))
+	for _, dc := range m.devcontainers {
+		if !dc.busy {
+			c, ok := byConfig[dc.ConfigPath]
+			state := wirtualsdk.WorkspaceAgentDevcontainerStopped
+			if ok && c.State == "running" {
+				state = wirtualsdk.WorkspaceAgentDevcontainerRunning
+			}
+			// A dev container that failed to start stays failed until it's
+			// started again, so the error isn't lost.
+			if dc.status.State != wirtualsdk.WorkspaceAgentDevcontainerFailed {
+				setState(&dc.status, state, "")
+			}
+			dc.status.ContainerID = c.ID
+			dc.status.Image = c.Image
+		}
+		statuses = append(statuses, dc.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// StartDevcontainer starts the container of the dev container in the
+// background, creating it if it doesn't exist.
+func (m *Manager) StartDevcontainer(name string) error {
+	return m.up(name, false)
+}
+
+// RebuildDevcontainer replaces the container of the dev container with a
+// new one, built from the current devcontainer.json, in the background.
+func (m *Manager) RebuildDevcontainer(name string) error {
+	return m.up(name, true)
+}
+
+func (m *Manager) up(name string, rebuild bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.started || m.docker == nil {
+		return xerrors.New("dev containers are not enabled")
+	}
+	m.discoverLocked()
+	dc, ok := m.devcontainers[name]
+	if !ok {
+		return ErrNotFound
+	}
+	if dc.busy {
+		return xerrors.Errorf("dev container %q is already starting", name)
+	}
+	m.upLocked(dc, rebuild)
+	return nil
+}
+
+func (m *Manager) upLocked(dc *devcontainer, rebuild bool) {
+	dc.busy = true
+	setState(&dc.status, wirtualsdk.WorkspaceAgentDevcontainerStarting, "")
+	m.notify()
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		logger := m.Logger.With(slog.F("devcontainer", dc.Name), slog.F("config", dc.ConfigPath))
+		containerID, err := m.runUp(m.ctx, dc.Discovered, rebuild)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		defer m.notify()
+		dc.busy = false
+		dc.status.ContainerID = containerID
+		if err != nil {
+			logger.Warn(m.ctx, "start dev container", slog.Error(err))
+			setState(&dc.status, wirtualsdk.WorkspaceAgentDevcontainerFailed, err.Error())
+			return
+		}
+		logger.Info(m.ctx, "dev container running", slog.F("container_id", containerID))
+		setState(&dc.status, wirtualsdk.WorkspaceAgentDevcontainerRunning, "")
+	}()
+}
+
+// runUp creates and starts the container of the dev container, unless it's
+// running already, and returns its ID. The output is written to the log
+// file of the dev container.
+func (m *Manager) runUp(ctx context.Context, d Discovered, rebuild bool) (string, error) {
+	logFile, err := os.OpenFile(m.logPath(d.Name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", xerrors.Errorf("open log file: %w", err)
+	}
+	defer logFile.Close()
+	_, _ = fmt.Fprintf(logFile, "%s Starting dev container %s from %s\n", time.Now().Format(time.RFC3339), d.Name, d.ConfigPath)
+
+	data, err := os.ReadFile(d.ConfigPath)
+	if err != nil {
+		return "", xerrors.Errorf("read config: %w", err)
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		return "", err
+	}
+
+	containers, err := m.docker.listContainers(ctx, LabelConfigFile+"="+d.ConfigPath)
+	if err != nil {
+		return "", xerrors.Errorf("list containers: %w", err)
+	}
+	if rebuild {
+		for _, c := range containers {
+			_, _ = fmt.Fprintf(logFile, "Removing container %s\n", c.ID)
+			err = m.docker.removeContainer(ctx, c.ID)
+			if err != nil {
+				return "", xerrors.Errorf("remove container: %w", err)
+			}
+		}
+		containers = nil
+	}
+
+	created := false
+	var containerID string
+	if len(containers) > 0 {
+		containerID = containers[0].ID
+		if containers[0].State == "running" {
+			return containerID, nil
+		}
+	} else {
+		containerID, err = m.create(ctx, d, config, logFile)
+		if err != nil {
+			return "", err
+		}
+		created = true
+	}
+
+	_, _ = fmt.Fprintf(logFile, "Starting container %s\n", containerID)
+	err = m.docker.startContainer(ctx, containerID)
+	if err != nil {
+		return containerID, xerrors.Errorf("start container: %w", err)
+	}
+
+	if created {
+		err = m.runLifecycleCommand(ctx, containerID, d, config, "postCreateCommand", config.PostCreateCommand, logFile)
+		if err != nil {
+			return containerID, err
+		}
+	}
+	err = m.runLifecycleCommand(ctx, containerID, d, config, "postStartCommand", config.PostStartCommand, logFile)
+	if err != nil {
+		return containerID, err
+	}
+	return containerID, nil
+}
+
+// create builds or pulls the image of the dev container and creates its
+// container.
+func (m *Manager) create(ctx context.Context, d Discovered, config Config, logFile io.Writer) (string, error) {
+	configDir := filepath.Dir(d.ConfigPath)
+	image := config.Image
+	if dockerfile := config.Dockerfile(); dockerfile != "" {
+		contextDir := filepath.Join(configDir, config.BuildContext())
+		rel, err := filepath.Rel(contextDir, filepath.Join(configDir, dockerfile))
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", xerrors.Errorf("the Dockerfile %s must be in the build context %s", dockerfile, contextDir)
+		}
+		image = imageTag(d.Name, d.ConfigPath)
+		_, _ = fmt.Fprintf(logFile, "Building image %s\n", image)
+		err = m.docker.buildImage(ctx, contextDir, rel, image, config.Build.Target, config.Build.Args, logFile)
+		if err != nil {
+			return "", xerrors.Errorf("build image: %w", err)
+		}
+	} else {
+		_, _ = fmt.Fprintf(logFile, "Pulling image %s\n", image)
+		err := m.docker.pullImage(ctx, image, logFile)
+		if err != nil {
+			// The image may exist locally, creating the container fails
+			// if it doesn't.
+			_, _ = fmt.Fprintf(logFile, "Failed to pull image: %s\n", err)
+		}
+	}
+
+	req := dockerCreateRequest{
+		Image:      image,
+		User:       config.ContainerUser,
+		WorkingDir: workspaceFolder(d, config),
+		Labels: map[string]string{
+			LabelLocalFolder: d.LocalFolder,
+			LabelConfigFile:  d.ConfigPath,
+		},
+	}
+	for k, v := range config.ContainerEnv {
+		req.Env = append(req.Env, k+"="+v)
+	}
+	sort.Strings(req.Env)
+	if config.OverrideCommand == nil || *config.OverrideCommand {
+		req.Entrypoint = []string{"/bin/sh"}
+		req.Cmd = []string{"-c", keepAliveScript}
+	}
+	req.HostConfig.Binds = []string{d.LocalFolder + ":" + workspaceFolder(d, config)}
+	req.HostConfig.Privileged = config.Privileged
+	req.HostConfig.CapAdd = config.CapAdd
+
+	_, _ = fmt.Fprintf(logFile, "Creating container from %s\n", image)
+	id, err := m.docker.createContainer(ctx, req)
+	if err != nil {
+		return "", xerrors.Errorf("create container: %w", err)
+	}
+	return id, nil
+}
+
+// runLifecycleCommand runs a postCreateCommand or postStartCommand in the
+// container, one entry after the other.
+func (m *Manager) runLifecycleCommand(ctx context.Context, containerID string, d Discovered, config Config, name string, command Command, logFile io.Writer) error {
+	for _, args := range command {
+		_, _ = fmt.Fprintf(logFile, "Running %s: %s\n", name, strings.Join(args, " "))
+		if len(args) == 1 {
+			args = []string{"/bin/sh", "-c", args[0]}
+		}
+		if m.CLI == "" {
+			return xerrors.Errorf("run %s: docker or podman must be installed to run commands in dev containers", name)
+		}
+		cmd, err := agentexec.CommandContext(ctx, m.CLI, m.execArgs(containerID, d, config, false, nil, args)...)
+		if err != nil {
+			return xerrors.Errorf("run %s: %w", name, err)
+		}
+		cmd.Env = m.cliEnv()
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		err = cmd.Run()
+		if err != nil {
+			return xerrors.Errorf("run %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// StopDevcontainer stops the container of the dev container.
+func (m *Manager) StopDevcontainer(ctx context.Context, name string) error {
+	m.mu.Lock()
+	dc, ok := m.devcontainers[name]
+	enabled := m.started && m.docker != nil
+	var d Discovered
+	if ok {
+		d = dc.Discovered
+		if dc.busy {
+			m.mu.Unlock()
+			return xerrors.Errorf("dev container %q is starting", name)
+		}
+	}
+	m.mu.Unlock()
+	if !enabled {
+		return xerrors.New("dev containers are not enabled")
+	}
+	if !ok {
+		return ErrNotFound
+	}
+
+	containers, err := m.docker.listContainers(ctx, LabelConfigFile+"="+d.ConfigPath)
+	if err != nil {
+		return xerrors.Errorf("list containers: %w", err)
+	}
+	for _, c := range containers {
+		if c.State != "running" {
+			continue
+		}
+		err = m.docker.stopContainer(ctx, c.ID)
+		if err != nil {
+			return xerrors.Errorf("stop container: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	setState(&dc.status, wirtualsdk.WorkspaceAgentDevcontainerStopped, "")
+	m.mu.Unlock()
+	m.notify()
+	return nil
+}
+
+// Command creates the command that runs script in the running container of
+// the named dev container, as the remote user of devcontainer.json and in
+// its workspace folder. An empty script starts a login shell.
+func (m *Manager) Command(ctx context.Context, name, script string, env []string, tty bool) (*pty.Cmd, error) {
+	m.mu.Lock()
+	dc, ok := m.devcontainers[name]
+	var d Discovered
+	if ok {
+		d = dc.Discovered
+	}
+	enabled := m.started && m.docker != nil
+	m.mu.Unlock()
+	if !enabled {
+		return nil, xerrors.New("dev containers are not enabled")
+	}
+	if !ok {
+		return nil, xerrors.Errorf("%w: %q", ErrNotFound, name)
+	}
+	if m.CLI == "" {
+		return nil, xerrors.New("docker or podman must be installed to connect to dev containers")
+	}
+
+	data, err := os.ReadFile(d.ConfigPath)
+	if err != nil {
+		return nil, xerrors.Errorf("read config: %w", err)
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := m.docker.listContainers(ctx, LabelConfigFile+"="+d.ConfigPath)
+	if err != nil {
+		return nil, xerrors.Errorf("list containers: %w", err)
+	}
+	containerID := ""
+	for _, c := range containers {
+		if c.State == "running" {
+			containerID = c.ID
+			break
+		}
+	}
+	if containerID == "" {
+		return nil, xerrors.Errorf("dev container %q is not running", name)
+	}
+
+	if script == "" {
+		script = loginShellScript
+	}
+	cmd, err := agentexec.PTYCommandContext(ctx, m.CLI, m.execArgs(containerID, d, config, tty, env, []string{"/bin/sh", "-c", script})...)
+	if err != nil {
+		return nil, xerrors.Errorf("pty command context: %w", err)
+	}
+	cmd.Env = m.cliEnv()
+	return cmd, nil
+}
+
+// execArgs returns the arguments of the CLI that run args in the container.
+// env is added to the remote environment of devcontainer.json.
+func (m *Manager) execArgs(containerID string, d Discovered, config Config, tty bool, env []string, args []string) []string {
+	execArgs := []string{"exec", "-i"}
+	if tty {
+		// TERM is set on the CLI process by the SSH server.
+		execArgs = append(execArgs, "-t", "-e", "TERM")
+	}
+	user := config.RemoteUser
+	if user == "" {
+		user = config.ContainerUser
+	}
+	if user != "" {
+		execArgs = append(execArgs, "-u", user)
+	}
+	execArgs = append(execArgs, "-w", workspaceFolder(d, config))
+	remoteEnv := make([]string, 0, len(config.RemoteEnv))
+	for k, v := range config.RemoteEnv {
+		remoteEnv = append(remoteEnv, k+"="+v)
+	}
+	sort.Strings(remoteEnv)
+	for _, kv := range append(remoteEnv, env...) {
+		execArgs = append(execArgs, "-e", kv)
+	}
+	execArgs = append(execArgs, containerID)
+	return append(execArgs, args...)
+}
+
+// cliEnv returns the environment of the CLI, which points it at the socket
+// the containers were created with.
+func (m *Manager) cliEnv() []string {
+	env := os.Environ()
+	if filepath.Base(m.CLI) == "docker" {
+		env = append(env, "DOCKER_HOST=unix://"+m.Socket)
+	}
+	return env
+}
+
+func (m *Manager) logPath(name string) string {
+	return filepath.Join(m.LogDir, "devcontainer-"+name+".log")
+}
+
+// Logs returns the output of the last build and start of the dev container.
+func (m *Manager) Logs(name string) ([]byte, error) {
+	m.mu.Lock()
+	_, ok := m.devcontainers[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(m.logPath(name))
+	if os.IsNotExist(err) {
+		return []byte{}, nil
+	}
+	return data, err
+}
+
+// ReportLoop sends the state of every dev container to wirtuald, and then
+// again whenever it changes, until the context is canceled. All states are
+// sent on each call so that wirtuald catches up after a reconnect, and
+// wirtuald can show them while the agent is unreachable.
+func (m *Manager) ReportLoop(ctx context.Context, report ReportStatusesFunc) error {
+	if !m.Enabled() {
+		return nil
+	}
+
+	ticker := time.NewTicker(reportInterval)
+	defer ticker.Stop()
+	var last []*proto.DevcontainerStatus
+	for {
+		devcontainers, err := m.Devcontainers(ctx)
+		if err != nil {
+			// The daemon may be restarting, the state is sent again once
+			// it's back.
+			m.Logger.Warn(ctx, "list dev containers for report", slog.Error(err))
+		} else {
+			statuses := make([]*proto.DevcontainerStatus, 0, len(devcontainers))
+			for _, devcontainer := range devcontainers {
+				statuses = append(statuses, protoFromDevcontainer(devcontainer))
+			}
+			if len(statuses) > 0 && !slices.EqualFunc(last, statuses, statusEqual) {
+				_, err = report(ctx, &proto.BatchUpdateDevcontainerStatusesRequest{Statuses: statuses})
+				if err != nil {
+					return xerrors.Errorf("batch update dev container statuses: %w", err)
+				}
+				last = statuses
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-m.updated:
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify wakes up the report loop.
+func (m *Manager) notify() {
+	select {
+	case m.updated <- struct{}{}:
+	default:
+		// A report is already pending.
+	}
+}
+
+// Close waits for the dev containers being started. The containers are
+// left running.
+func (m *Manager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
+
+// workspaceFolder is where the local folder is mounted in the container.
+func workspaceFolder(d Discovered, config Config) string {
+	if config.WorkspaceFolder != "" {
+		return config.WorkspaceFolder
+	}
+	return "/workspaces/" + filepath.Base(d.LocalFolder)
+}
+
+func setState(status *wirtualsdk.WorkspaceAgentDevcontainer, state wirtualsdk.WorkspaceAgentDevcontainerState, message string) {
+	if status.State != state || status.StateChangedAt == nil {
+		now := time.Now()
+		status.StateChangedAt = &now
+	}
+	status.State = state
+	status.Error = message
+}
+
+func statusEqual(a, b *proto.DevcontainerStatus) bool {
+	return protobuf.Equal(a, b)
+}
+
+func protoFromDevcontainer(devcontainer wirtualsdk.WorkspaceAgentDevcontainer) *proto.DevcontainerStatus {
+	var state proto.DevcontainerStatus_State
+	switch devcontainer.State {
+	case wirtualsdk.WorkspaceAgentDevcontainerStopped:
+		state = proto.DevcontainerStatus_STOPPED
+	case wirtualsdk.WorkspaceAgentDevcontainerStarting:
+		state = proto.DevcontainerStatus_STARTING
+	case wirtualsdk.WorkspaceAgentDevcontainerRunning:
+		state = proto.DevcontainerStatus_RUNNING
+	case wirtualsdk.WorkspaceAgentDevcontainerFailed:
+		state = proto.DevcontainerStatus_FAILED
+	}
+	status := &proto.DevcontainerStatus{
+		Name:            devcontainer.Name,
+		WorkspaceFolder: devcontainer.WorkspaceFolder,
+		ConfigPath:      devcontainer.ConfigPath,
+		State:           state,
+		ContainerId:     devcontainer.ContainerID,
+		Image:           devcontainer.Image,
+		Error:           devcontainer.Error,
+	}
+	if devcontainer.StateChangedAt != nil {
+		status.ChangedAt = timestamppb.New(*devcontainer.StateChangedAt)
+	}
+	return status
+}
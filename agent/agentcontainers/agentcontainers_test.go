@@ -0,0 +1,242 @@
+package agentcontainers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentcontainers"
+	"github.com/onchainengineering/hmi-wirtual/agent/agenttest"
+	"github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "project", ".devcontainer", "devcontainer.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o700))
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"image": "ubuntu", "containerEnv": {"FOO": "bar"}}`), 0o600))
+
+	docker := newFakeDocker(t)
+	m := agentcontainers.New(agentcontainers.Options{
+		Logger:  slogtest.Make(t, nil),
+		Folders: []string{"*"},
+		LogDir:  t.TempDir(),
+		Socket:  docker.socket,
+	})
+	t.Cleanup(func() { _ = m.Close() })
+	require.True(t, m.Enabled())
+	require.NoError(t, m.Start(dir))
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	waitForState := func(state wirtualsdk.WorkspaceAgentDevcontainerState) wirtualsdk.WorkspaceAgentDevcontainer {
+		t.Helper()
+		var devcontainer wirtualsdk.WorkspaceAgentDevcontainer
+		testutil.Eventually(ctx, t, func(ctx context.Context) bool {
+			devcontainers, err := m.Devcontainers(ctx)
+			if err != nil || len(devcontainers) != 1 {
+				return false
+			}
+			devcontainer = devcontainers[0]
+			return devcontainer.State == state
+		}, testutil.IntervalFast)
+		return devcontainer
+	}
+
+	devcontainer := waitForState(wirtualsdk.WorkspaceAgentDevcontainerRunning)
+	require.Equal(t, "project", devcontainer.Name)
+	require.Equal(t, configPath, devcontainer.ConfigPath)
+	require.Equal(t, "ubuntu", devcontainer.Image)
+	created := docker.created()
+	require.Len(t, created, 1)
+	require.Equal(t, []string{"FOO=bar"}, created[0].Env)
+	require.Equal(t, "/workspaces/project", created[0].WorkingDir)
+	require.Equal(t, []string{filepath.Join(dir, "project") + ":/workspaces/project"}, created[0].HostConfig.Binds)
+
+	logs, err := m.Logs("project")
+	require.NoError(t, err)
+	require.Contains(t, string(logs), "Pulling image ubuntu")
+
+	require.NoError(t, m.StopDevcontainer(ctx, "project"))
+	waitForState(wirtualsdk.WorkspaceAgentDevcontainerStopped)
+
+	// Starting again reuses the stopped container.
+	require.NoError(t, m.StartDevcontainer("project"))
+	waitForState(wirtualsdk.WorkspaceAgentDevcontainerRunning)
+	require.Len(t, docker.created(), 1)
+
+	// Rebuilding replaces it.
+	require.NoError(t, m.RebuildDevcontainer("project"))
+	devcontainer = waitForState(wirtualsdk.WorkspaceAgentDevcontainerRunning)
+	require.Len(t, docker.created(), 2)
+	require.Equal(t, "container-2", devcontainer.ContainerID)
+
+	require.ErrorIs(t, m.StartDevcontainer("missing"), agentcontainers.ErrNotFound)
+	_, err = m.Logs("missing")
+	require.ErrorIs(t, err, agentcontainers.ErrNotFound)
+}
+
+func TestReportLoop(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "project", ".devcontainer", "devcontainer.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o700))
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"image": "ubuntu"}`), 0o600))
+
+	docker := newFakeDocker(t)
+	m := agentcontainers.New(agentcontainers.Options{
+		Logger:  slogtest.Make(t, nil),
+		Folders: []string{"*"},
+		LogDir:  t.TempDir(),
+		Socket:  docker.socket,
+	})
+	t.Cleanup(func() { _ = m.Close() })
+	require.NoError(t, m.Start(dir))
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	aAPI := agenttest.NewFakeAgentAPI(t, testutil.Logger(t), nil, nil)
+	loopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- m.ReportLoop(loopCtx, aAPI.BatchUpdateDevcontainerStatuses)
+	}()
+
+	waitForReport := func(state proto.DevcontainerStatus_State) {
+		t.Helper()
+		testutil.Eventually(ctx, t, func(context.Context) bool {
+			status, ok := aAPI.GetDevcontainerStatuses()["project"]
+			return ok && status.State == state
+		}, testutil.IntervalFast)
+	}
+	waitForReport(proto.DevcontainerStatus_RUNNING)
+	status := aAPI.GetDevcontainerStatuses()["project"]
+	require.Equal(t, configPath, status.ConfigPath)
+	require.Equal(t, "ubuntu", status.Image)
+	require.NotNil(t, status.ChangedAt)
+
+	require.NoError(t, m.StopDevcontainer(ctx, "project"))
+	waitForReport(proto.DevcontainerStatus_STOPPED)
+
+	cancel()
+	require.ErrorIs(t, testutil.RequireRecvCtx(ctx, t, done), context.Canceled)
+}
+
+type fakeContainer struct {
+	ID         string            `json:"Id"`
+	Image      string            `json:"Image"`
+	State      string            `json:"State"`
+	Labels     map[string]string `json:"Labels"`
+	Env        []string          `json:"Env"`
+	WorkingDir string            `json:"WorkingDir"`
+	HostConfig struct {
+		Binds []string `json:"Binds"`
+	} `json:"HostConfig"`
+}
+
+// fakeDocker implements the parts of the Docker Engine API the manager uses.
+type fakeDocker struct {
+	socket string
+
+	mu         sync.Mutex
+	containers []*fakeContainer
+	history    []fakeContainer
+}
+
+func newFakeDocker(t *testing.T) *fakeDocker {
+	t.Helper()
+	// Unix socket paths are limited in length, so the socket isn't put in
+	// the test's temp dir.
+	dir, err := os.MkdirTemp("", "docker")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	d := &fakeDocker{socket: filepath.Join(dir, "docker.sock")}
+	l, err := net.Listen("unix", d.socket)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(d.serveHTTP))
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return d
+}
+
+func (d *fakeDocker) created() []fakeContainer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]fakeContainer(nil), d.history...)
+}
+
+func (d *fakeDocker) find(id string) *fakeContainer {
+	for _, c := range d.containers {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (d *fakeDocker) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1.41/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1.41/containers/json":
+		var filters map[string][]string
+		_ = json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+		containers := []*fakeContainer{}
+		for _, c := range d.containers {
+			for _, label := range filters["label"] {
+				key, value, hasValue := strings.Cut(label, "=")
+				if v, ok := c.Labels[key]; ok && (!hasValue || v == value) {
+					containers = append(containers, c)
+				}
+			}
+		}
+		_ = json.NewEncoder(rw).Encode(containers)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1.41/images/create":
+		_, _ = fmt.Fprintln(rw, `{"status": "Pulled"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1.41/containers/create":
+		var c fakeContainer
+		_ = json.NewDecoder(r.Body).Decode(&c)
+		c.ID = fmt.Sprintf("container-%d", len(d.history)+1)
+		c.State = "created"
+		d.containers = append(d.containers, &c)
+		d.history = append(d.history, c)
+		_ = json.NewEncoder(rw).Encode(map[string]string{"Id": c.ID})
+	case len(parts) == 3 && r.Method == http.MethodPost && (parts[2] == "start" || parts[2] == "stop"):
+		c := d.find(parts[1])
+		if c == nil {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		c.State = map[string]string{"start": "running", "stop": "exited"}[parts[2]]
+		rw.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		for i, c := range d.containers {
+			if c.ID == parts[1] {
+				d.containers = append(d.containers[:i], d.containers[i+1:]...)
+				break
+			}
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	default:
+		rw.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(rw, `{"message": "unexpected request %s %s"}`, r.Method, r.URL.Path)
+	}
+}
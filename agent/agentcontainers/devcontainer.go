@@ -0,0 +1,285 @@
+package agentcontainers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Config is the subset of devcontainer.json the agent understands. See
+// https://containers.dev/implementors/json_reference/ for the full format.
+type Config struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	Build struct {
+		Dockerfile string            `json:"dockerfile"`
+		Context    string            `json:"context"`
+		Args       map[string]string `json:"args"`
+		Target     string            `json:"target"`
+	} `json:"build"`
+	// DockerFile and Context are the legacy spelling of build.dockerfile and
+	// build.context.
+	DockerFile string `json:"dockerFile"`
+	Context    string `json:"context"`
+
+	DockerComposeFile any `json:"dockerComposeFile"`
+
+	WorkspaceFolder   string            `json:"workspaceFolder"`
+	ContainerEnv      map[string]string `json:"containerEnv"`
+	RemoteEnv         map[string]string `json:"remoteEnv"`
+	ContainerUser     string            `json:"containerUser"`
+	RemoteUser        string            `json:"remoteUser"`
+	OverrideCommand   *bool             `json:"overrideCommand"`
+	Privileged        bool              `json:"privileged"`
+	CapAdd            []string          `json:"capAdd"`
+	PostCreateCommand Command           `json:"postCreateCommand"`
+	PostStartCommand  Command           `json:"postStartCommand"`
+}
+
+// Command is a lifecycle command, which devcontainer.json allows to be a
+// shell command, an array of arguments or an object of commands run in
+// parallel. Each entry is a list of arguments, a shell command has a single
+// argument run with /bin/sh -c.
+type Command [][]string
+
+func (c *Command) UnmarshalJSON(data []byte) error {
+	var shell string
+	if err := json.Unmarshal(data, &shell); err == nil {
+		if shell != "" {
+			*c = Command{{shell}}
+		}
+		return nil
+	}
+	var args []string
+	if err := json.Unmarshal(data, &args); err == nil {
+		if len(args) > 0 {
+			*c = Command{args}
+		}
+		return nil
+	}
+	var parallel map[string]Command
+	if err := json.Unmarshal(data, &parallel); err != nil {
+		return xerrors.New("command must be a string, an array or an object")
+	}
+	// Entries are run in order of their names, as the agent runs them one
+	// after the other.
+	names := make([]string, 0, len(parallel))
+	for name := range parallel {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	*c = nil
+	for _, name := range names {
+		*c = append(*c, parallel[name]...)
+	}
+	return nil
+}
+
+// Dockerfile returns the path of the Dockerfile relative to the directory of
+// devcontainer.json, or an empty string if the container uses an image.
+func (c Config) Dockerfile() string {
+	if c.Build.Dockerfile != "" {
+		return c.Build.Dockerfile
+	}
+	return c.DockerFile
+}
+
+// BuildContext returns the path of the build context relative to the
+// directory of devcontainer.json.
+func (c Config) BuildContext() string {
+	if c.Build.Context != "" {
+		return c.Build.Context
+	}
+	if c.Context != "" {
+		return c.Context
+	}
+	return "."
+}
+
+// ParseConfig parses a devcontainer.json file, which is JSON with comments
+// and trailing commas.
+func ParseConfig(data []byte) (Config, error) {
+	var config Config
+	err := json.Unmarshal(standardizeJSONC(data), &config)
+	if err != nil {
+		return Config{}, xerrors.Errorf("parse devcontainer.json: %w", err)
+	}
+	if config.DockerComposeFile != nil {
+		return Config{}, xerrors.New("dev containers using Docker Compose are not supported")
+	}
+	if config.Image == "" && config.Dockerfile() == "" {
+		return Config{}, xerrors.New("devcontainer.json must set image or build.dockerfile")
+	}
+	return config, nil
+}
+
+// standardizeJSONC strips the comments and trailing commas that are allowed
+// in devcontainer.json but not in JSON. Stripped characters are replaced by
+// spaces so offsets in errors still match the file.
+func standardizeJSONC(data []byte) []byte {
+	out := bytes.Clone(data)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(out)
+			} else {
+				end += i + 4
+			}
+			for ; i < end; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		case c == ',':
+			// A comma is trailing if the next character that isn't space
+			// or a comment closes an object or array. The comments after
+			// the comma haven't been stripped yet, so they're skipped.
+			j := i + 1
+			for j < len(out) {
+				if isJSONSpace(out[j]) {
+					j++
+					continue
+				}
+				if out[j] == '/' && j+1 < len(out) && out[j+1] == '/' {
+					for j < len(out) && out[j] != '\n' {
+						j++
+					}
+					continue
+				}
+				if out[j] == '/' && j+1 < len(out) && out[j+1] == '*' {
+					end := bytes.Index(out[j+2:], []byte("*/"))
+					if end < 0 {
+						j = len(out)
+					} else {
+						j += end + 4
+					}
+					continue
+				}
+				break
+			}
+			if j < len(out) && (out[j] == '}' || out[j] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// configPaths are the locations of devcontainer.json in a folder, as looked
+// up by the dev containers CLI. The last one matches a folder per dev
+// container when a repository defines several.
+var configPaths = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+	filepath.Join(".devcontainer", "*", "devcontainer.json"),
+}
+
+// Discovered is a devcontainer.json file found in a folder.
+type Discovered struct {
+	// Name identifies the dev container. It's derived from the folder, and
+	// the subfolder of .devcontainer if there are several configs.
+	Name string
+	// LocalFolder is the folder holding the config, which is mounted in the
+	// container.
+	LocalFolder string
+	// ConfigPath is the absolute path of devcontainer.json.
+	ConfigPath string
+}
+
+// Discover finds the devcontainer.json files in the folders, which may be
+// glob patterns. Names are unique, clashes get a numeric suffix.
+func Discover(folders []string) []Discovered {
+	var found []Discovered
+	seen := map[string]bool{}
+	for _, pattern := range folders {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		sort.Strings(matches)
+		for _, folder := range matches {
+			if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+				continue
+			}
+			for _, configPath := range configPaths {
+				configs, _ := filepath.Glob(filepath.Join(folder, configPath))
+				sort.Strings(configs)
+				for _, config := range configs {
+					if seen[config] {
+						continue
+					}
+					seen[config] = true
+					name := sanitizeName(filepath.Base(folder))
+					if sub := filepath.Base(filepath.Dir(config)); sub != ".devcontainer" && sub != filepath.Base(folder) {
+						name += "-" + sanitizeName(sub)
+					}
+					found = append(found, Discovered{
+						Name:        name,
+						LocalFolder: folder,
+						ConfigPath:  config,
+					})
+				}
+			}
+		}
+	}
+
+	counts := map[string]int{}
+	for i := range found {
+		counts[found[i].Name]++
+		if n := counts[found[i].Name]; n > 1 {
+			found[i].Name += "-" + strconv.Itoa(n)
+		}
+	}
+	return found
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// sanitizeName turns a folder name into a name that can be used in a host
+// name, such as `coder ssh workspace.name`.
+func sanitizeName(s string) string {
+	s = invalidNameChars.ReplaceAllString(strings.ToLower(s), "-")
+	s = strings.Trim(s, "-")
+	if s == "" {
+		return "devcontainer"
+	}
+	return s
+}
+
+// imageTag is the tag of the image built for a config. The path is hashed
+// so dev containers with the same name in different workspaces sharing a
+// daemon don't overwrite each other's images.
+func imageTag(name, configPath string) string {
+	sum := sha256.Sum256([]byte(configPath))
+	return "wirtual-devcontainer-" + name + "-" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,101 @@
+package agentcontainers_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agentcontainers"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("JSONC", func(t *testing.T) {
+		t.Parallel()
+		config, err := agentcontainers.ParseConfig([]byte(`{
+	// The image is pulled if it doesn't exist.
+	"name": "Go",
+	"image": "mcr.microsoft.com/devcontainers/go:1", /* inline */
+	"remoteEnv": {
+		"URL": "http://example.com//not-a-comment",
+	},
+	"capAdd": ["SYS_PTRACE",],
+}`))
+		require.NoError(t, err)
+		require.Equal(t, "Go", config.Name)
+		require.Equal(t, "mcr.microsoft.com/devcontainers/go:1", config.Image)
+		require.Equal(t, map[string]string{"URL": "http://example.com//not-a-comment"}, config.RemoteEnv)
+		require.Equal(t, []string{"SYS_PTRACE"}, config.CapAdd)
+	})
+
+	t.Run("Build", func(t *testing.T) {
+		t.Parallel()
+		config, err := agentcontainers.ParseConfig([]byte(`{"build": {"dockerfile": "Dockerfile", "context": ".."}}`))
+		require.NoError(t, err)
+		require.Equal(t, "Dockerfile", config.Dockerfile())
+		require.Equal(t, "..", config.BuildContext())
+
+		config, err = agentcontainers.ParseConfig([]byte(`{"dockerFile": "Dockerfile"}`))
+		require.NoError(t, err)
+		require.Equal(t, "Dockerfile", config.Dockerfile())
+		require.Equal(t, ".", config.BuildContext())
+	})
+
+	t.Run("Commands", func(t *testing.T) {
+		t.Parallel()
+		config, err := agentcontainers.ParseConfig([]byte(`{
+	"image": "ubuntu",
+	"postCreateCommand": "make deps",
+	"postStartCommand": {"b": ["echo", "b"], "a": "echo a"}
+}`))
+		require.NoError(t, err)
+		require.Equal(t, agentcontainers.Command{{"make deps"}}, config.PostCreateCommand)
+		require.Equal(t, agentcontainers.Command{{"echo a"}, {"echo", "b"}}, config.PostStartCommand)
+
+		_, err = agentcontainers.ParseConfig([]byte(`{"image": "ubuntu", "postStartCommand": 1}`))
+		require.ErrorContains(t, err, "command must be")
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		t.Parallel()
+		_, err := agentcontainers.ParseConfig([]byte(`{"dockerComposeFile": "compose.yml", "service": "app"}`))
+		require.ErrorContains(t, err, "Docker Compose")
+		_, err = agentcontainers.ParseConfig([]byte(`{"name": "empty"}`))
+		require.ErrorContains(t, err, "must set image")
+	})
+}
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeConfig := func(path string) string {
+		t.Helper()
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(`{"image": "ubuntu"}`), 0o600))
+		return path
+	}
+	api := writeConfig("repos/My_API/.devcontainer/devcontainer.json")
+	web := writeConfig("repos/web/.devcontainer.json")
+	webDocs := writeConfig("repos/web/.devcontainer/docs/devcontainer.json")
+	otherAPI := writeConfig("other/my-api/.devcontainer.json")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "repos", "empty"), 0o700))
+
+	found := agentcontainers.Discover([]string{
+		filepath.Join(dir, "repos", "*"),
+		filepath.Join(dir, "other", "my-api"),
+		// Folders matched twice are only reported once.
+		filepath.Join(dir, "repos", "web"),
+		filepath.Join(dir, "missing"),
+	})
+	require.Equal(t, []agentcontainers.Discovered{
+		{Name: "my-api", LocalFolder: filepath.Dir(filepath.Dir(api)), ConfigPath: api},
+		{Name: "web", LocalFolder: filepath.Dir(web), ConfigPath: web},
+		{Name: "web-docs", LocalFolder: filepath.Dir(web), ConfigPath: webDocs},
+		{Name: "my-api-2", LocalFolder: filepath.Dir(otherAPI), ConfigPath: otherAPI},
+	}, found)
+}
@@ -0,0 +1,305 @@
+package agentcontainers
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// dockerAPIVersion is the version of the Docker Engine API used. Podman
+// implements it as well.
+const dockerAPIVersion = "v1.41"
+
+// FindSocket returns the path of the Docker or Podman socket, looked up
+// from DOCKER_HOST, CONTAINER_HOST and the default locations, or an empty
+// string if there is none.
+func FindSocket() string {
+	for _, env := range []string{"DOCKER_HOST", "CONTAINER_HOST"} {
+		if path, ok := strings.CutPrefix(os.Getenv(env), "unix://"); ok {
+			return path
+		}
+	}
+	candidates := []string{"/var/run/docker.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "docker.sock"), filepath.Join(dir, "podman", "podman.sock"))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && info.Mode()&fs.ModeSocket != 0 {
+			return path
+		}
+	}
+	return ""
+}
+
+// dockerClient talks to the Docker Engine API over a unix socket.
+type dockerClient struct {
+	client *http.Client
+}
+
+func newDockerClient(socket string) *dockerClient {
+	return &dockerClient{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+func (d *dockerClient) request(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := url.URL{Scheme: "http", Host: "docker", Path: "/" + dockerAPIVersion + path, RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res, err := d.client.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("%s %s: %w", method, path, err)
+	}
+	if res.StatusCode >= 400 {
+		defer res.Body.Close()
+		var msg struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		if json.Unmarshal(data, &msg) != nil || msg.Message == "" {
+			msg.Message = strings.TrimSpace(string(data))
+		}
+		return nil, xerrors.Errorf("%s %s: %s: %s", method, path, res.Status, msg.Message)
+	}
+	return res, nil
+}
+
+func (d *dockerClient) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	res, err := d.request(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+type dockerContainer struct {
+	ID     string            `json:"Id"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// listContainers returns the containers, running or not, with the label.
+func (d *dockerClient) listContainers(ctx context.Context, label string) ([]dockerContainer, error) {
+	filters, err := json.Marshal(map[string][]string{"label": {label}})
+	if err != nil {
+		return nil, err
+	}
+	var containers []dockerContainer
+	err = d.do(ctx, http.MethodGet, "/containers/json", url.Values{
+		"all":     {"1"},
+		"filters": {string(filters)},
+	}, nil, &containers)
+	return containers, err
+}
+
+type dockerInspect struct {
+	ID    string `json:"Id"`
+	State struct {
+		Status  string `json:"Status"`
+		Running bool   `json:"Running"`
+	} `json:"State"`
+	Config struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+}
+
+func (d *dockerClient) inspectContainer(ctx context.Context, id string) (dockerInspect, error) {
+	var inspect dockerInspect
+	err := d.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/json", nil, nil, &inspect)
+	return inspect, err
+}
+
+type dockerCreateRequest struct {
+	Image      string            `json:"Image"`
+	Entrypoint []string          `json:"Entrypoint,omitempty"`
+	Cmd        []string          `json:"Cmd,omitempty"`
+	Env        []string          `json:"Env,omitempty"`
+	User       string            `json:"User,omitempty"`
+	WorkingDir string            `json:"WorkingDir,omitempty"`
+	Labels     map[string]string `json:"Labels"`
+	HostConfig struct {
+		Binds      []string `json:"Binds,omitempty"`
+		Privileged bool     `json:"Privileged,omitempty"`
+		CapAdd     []string `json:"CapAdd,omitempty"`
+	} `json:"HostConfig"`
+}
+
+func (d *dockerClient) createContainer(ctx context.Context, req dockerCreateRequest) (string, error) {
+	var res struct {
+		ID string `json:"Id"`
+	}
+	err := d.do(ctx, http.MethodPost, "/containers/create", nil, req, &res)
+	return res.ID, err
+}
+
+func (d *dockerClient) startContainer(ctx context.Context, id string) error {
+	return d.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/start", nil, nil, nil)
+}
+
+func (d *dockerClient) stopContainer(ctx context.Context, id string) error {
+	return d.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/stop", url.Values{"t": {"10"}}, nil, nil)
+}
+
+func (d *dockerClient) removeContainer(ctx context.Context, id string) error {
+	return d.do(ctx, http.MethodDelete, "/containers/"+url.PathEscape(id), url.Values{"force": {"1"}}, nil, nil)
+}
+
+// pullImage pulls the image, writing progress to w.
+func (d *dockerClient) pullImage(ctx context.Context, image string, w io.Writer) error {
+	res, err := d.request(ctx, http.MethodPost, "/images/create", url.Values{"fromImage": {image}}, nil, "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return readJSONMessages(res.Body, w)
+}
+
+// buildImage builds the image from the context directory, writing the
+// output of the build to w. dockerfile is relative to the context.
+func (d *dockerClient) buildImage(ctx context.Context, contextDir, dockerfile, tag, target string, args map[string]string, w io.Writer) error {
+	buildArgs, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	query := url.Values{
+		"t":          {tag},
+		"dockerfile": {filepath.ToSlash(dockerfile)},
+		"buildargs":  {string(buildArgs)},
+		"rm":         {"1"},
+	}
+	if target != "" {
+		query.Set("target", target)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(writeTar(pw, contextDir))
+	}()
+	defer pr.Close()
+	res, err := d.request(ctx, http.MethodPost, "/build", query, pr, "application/x-tar")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return readJSONMessages(res.Body, w)
+}
+
+// readJSONMessages copies the output of a pull or build to w, and returns
+// the error reported in the stream if there is one.
+func readJSONMessages(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var msg struct {
+			Stream   string `json:"stream"`
+			Status   string `json:"status"`
+			Progress string `json:"progress"`
+			Error    string `json:"error"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil {
+			continue
+		}
+		if msg.Error != "" {
+			return xerrors.New(strings.TrimSpace(msg.Error))
+		}
+		switch {
+		case msg.Stream != "":
+			_, _ = io.WriteString(w, msg.Stream)
+		case msg.Status != "" && msg.Progress == "":
+			_, _ = fmt.Fprintln(w, msg.Status)
+		}
+	}
+	return scanner.Err()
+}
+
+// writeTar writes the directory to w as a tar archive, which is how build
+// contexts are sent to the daemon.
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			// Sockets and other special files can't be archived.
+			return nil
+		}
+		header.Name = filepath.ToSlash(rel)
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,233 @@
+package agentexec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/xerrors"
+)
+
+// CgroupClass groups the processes started by the agent so they can be given
+// separate resource limits.
+type CgroupClass string
+
+const (
+	// CgroupSSH contains SSH sessions and commands, including the IDEs
+	// connecting over SSH.
+	CgroupSSH CgroupClass = "ssh"
+	// CgroupReconnectingPTY contains the terminals opened from the
+	// dashboard.
+	CgroupReconnectingPTY CgroupClass = "reconnecting-pty"
+	// CgroupScripts contains the startup and shutdown scripts.
+	CgroupScripts CgroupClass = "scripts"
+)
+
+// CgroupClasses are all the classes that can be limited.
+var CgroupClasses = []CgroupClass{CgroupSSH, CgroupReconnectingPTY, CgroupScripts}
+
+// The environment variables holding the cgroup limits of each class. The
+// value is a comma-separated list of limits, for example
+// "cpu_weight=50,cpu_max=2,memory_max=4GiB,pids_max=1000".
+const (
+	EnvProcCgroupSSH             = "WIRTUAL_PROC_CGROUP_SSH"
+	EnvProcCgroupReconnectingPTY = "WIRTUAL_PROC_CGROUP_RECONNECTING_PTY"
+	EnvProcCgroupScripts         = "WIRTUAL_PROC_CGROUP_SCRIPTS"
+)
+
+// agentCgroupName is the cgroup the agent moves itself, and every other
+// process it shares a cgroup with, into. Processes can only live in the
+// leaves of a cgroup v2 hierarchy with controllers enabled.
+const agentCgroupName = "agent"
+
+// Env returns the environment variable holding the limits of the class.
+func (c CgroupClass) Env() string {
+	switch c {
+	case CgroupSSH:
+		return EnvProcCgroupSSH
+	case CgroupReconnectingPTY:
+		return EnvProcCgroupReconnectingPTY
+	case CgroupScripts:
+		return EnvProcCgroupScripts
+	default:
+		return ""
+	}
+}
+
+// CgroupLimits are the cgroup v2 limits applied to a class. Zero values are
+// unlimited.
+type CgroupLimits struct {
+	// CPUWeight is the share of CPU time the class gets when the CPU is
+	// contended, from 1 to 10000. The default weight of a cgroup is 100.
+	CPUWeight uint64 `json:"cpu_weight,omitempty"`
+	// CPUMax is the number of CPUs the class may use.
+	CPUMax float64 `json:"cpu_max,omitempty"`
+	// MemoryMax is the memory the class may use in bytes, above which its
+	// processes are OOM killed.
+	MemoryMax uint64 `json:"memory_max,omitempty"`
+	// PIDsMax is the number of processes the class may run.
+	PIDsMax uint64 `json:"pids_max,omitempty"`
+}
+
+// ParseCgroupLimits parses limits in the format of the WIRTUAL_PROC_CGROUP_*
+// environment variables.
+func ParseCgroupLimits(s string) (CgroupLimits, error) {
+	var limits CgroupLimits
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return CgroupLimits{}, xerrors.Errorf("limit %q must be in the format key=value", part)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		var err error
+		switch key {
+		case "cpu_weight":
+			limits.CPUWeight, err = strconv.ParseUint(value, 10, 64)
+			if err == nil && (limits.CPUWeight < 1 || limits.CPUWeight > 10000) {
+				err = xerrors.New("must be between 1 and 10000")
+			}
+		case "cpu_max":
+			limits.CPUMax, err = strconv.ParseFloat(value, 64)
+			if err == nil && limits.CPUMax <= 0 {
+				err = xerrors.New("must be positive")
+			}
+		case "memory_max":
+			limits.MemoryMax, err = humanize.ParseBytes(value)
+		case "pids_max":
+			limits.PIDsMax, err = strconv.ParseUint(value, 10, 64)
+		default:
+			return CgroupLimits{}, xerrors.Errorf("unknown limit %q", key)
+		}
+		if err != nil {
+			return CgroupLimits{}, xerrors.Errorf("parse %s: %w", key, err)
+		}
+	}
+	return limits, nil
+}
+
+// cgroupFiles returns the contents of the cgroup interface files that apply
+// the limits, keyed by file name.
+func (l CgroupLimits) cgroupFiles() map[string]string {
+	files := map[string]string{}
+	if l.CPUWeight > 0 {
+		files["cpu.weight"] = strconv.FormatUint(l.CPUWeight, 10)
+	}
+	if l.CPUMax > 0 {
+		const period = 100000
+		files["cpu.max"] = fmt.Sprintf("%d %d", int64(l.CPUMax*period), period)
+	}
+	if l.MemoryMax > 0 {
+		files["memory.max"] = strconv.FormatUint(l.MemoryMax, 10)
+	}
+	if l.PIDsMax > 0 {
+		files["pids.max"] = strconv.FormatUint(l.PIDsMax, 10)
+	}
+	return files
+}
+
+// configuredCgroupLimits returns the limits of every class with a non-empty
+// environment variable.
+func configuredCgroupLimits() (map[CgroupClass]CgroupLimits, error) {
+	classes := map[CgroupClass]CgroupLimits{}
+	for _, class := range CgroupClasses {
+		value := strings.TrimSpace(os.Getenv(class.Env()))
+		if value == "" {
+			continue
+		}
+		limits, err := ParseCgroupLimits(value)
+		if err != nil {
+			return nil, xerrors.Errorf("%s: %w", class.Env(), err)
+		}
+		classes[class] = limits
+	}
+	return classes, nil
+}
+
+// Cgroups are the cgroups created by SetupCgroups.
+type Cgroups struct {
+	// root is the directory of the cgroup the agent was started in, which
+	// holds a cgroup per class.
+	root    string
+	classes map[CgroupClass]CgroupLimits
+}
+
+// activeCgroups is set by SetupCgroups. Commands are only moved into a
+// cgroup once it exists.
+var activeCgroups atomic.Pointer[Cgroups]
+
+// Classes returns the limited classes, sorted by name.
+func (c *Cgroups) Classes() []CgroupClass {
+	classes := make([]CgroupClass, 0, len(c.classes))
+	for class := range c.classes {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i] < classes[j] })
+	return classes
+}
+
+// Limits returns the limits applied to the class.
+func (c *Cgroups) Limits(class CgroupClass) (CgroupLimits, bool) {
+	limits, ok := c.classes[class]
+	return limits, ok
+}
+
+// Dir returns the cgroup directory of the class.
+func (c *Cgroups) Dir(class CgroupClass) (string, bool) {
+	if _, ok := c.classes[class]; !ok {
+		return "", false
+	}
+	return filepath.Join(c.root, string(class)), true
+}
+
+// OOMKills returns the number of processes the kernel killed in the class
+// because it ran out of memory.
+func (c *Cgroups) OOMKills(class CgroupClass) (uint64, error) {
+	dir, ok := c.Dir(class)
+	if !ok {
+		return 0, xerrors.Errorf("class %q is not limited", class)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "memory.events"))
+	if err != nil {
+		return 0, xerrors.Errorf("read memory events: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			return strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	return 0, nil
+}
+
+type cgroupClassKey struct{}
+
+// WithCgroupClass returns a context that places the commands created with it
+// in the cgroup of the class, if the class is limited.
+func WithCgroupClass(ctx context.Context, class CgroupClass) context.Context {
+	return context.WithValue(ctx, cgroupClassKey{}, class)
+}
+
+// cgroupDir returns the cgroup the commands created with the context should
+// be placed in, or an empty string if they aren't limited.
+func cgroupDir(ctx context.Context) string {
+	class, ok := ctx.Value(cgroupClassKey{}).(CgroupClass)
+	if !ok {
+		return ""
+	}
+	cgroups := activeCgroups.Load()
+	if cgroups == nil {
+		return ""
+	}
+	dir, _ := cgroups.Dir(class)
+	return dir
+}
@@ -0,0 +1,145 @@
+//go:build linux
+// +build linux
+
+package agentexec
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/xerrors"
+)
+
+// cgroupMount is where the cgroup v2 hierarchy is mounted.
+const cgroupMount = "/sys/fs/cgroup"
+
+// SetupCgroups creates a cgroup for each class configured with a
+// WIRTUAL_PROC_CGROUP_* environment variable, next to a cgroup holding the
+// agent itself, and applies the limits of the class to it. Commands created
+// with a context from WithCgroupClass are started in the cgroup of the class
+// from then on.
+//
+// The agent must be able to write to the cgroup it was started in, which is
+// the case in most containers with a private cgroup namespace. Only cgroup
+// v2 is supported. SetupCgroups returns nil if no limits are configured.
+func SetupCgroups() (*Cgroups, error) {
+	classes, err := configuredCgroupLimits()
+	if err != nil {
+		return nil, err
+	}
+	if len(classes) == 0 {
+		return nil, nil
+	}
+
+	self, err := selfCgroup()
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Join(cgroupMount, self)
+	if filepath.Base(root) == agentCgroupName {
+		// The agent was restarted in the same cgroup hierarchy.
+		root = filepath.Dir(root)
+	}
+
+	// Processes can't stay in a cgroup that distributes resources to its
+	// children, so every process is moved into the agent cgroup first.
+	agentDir := filepath.Join(root, agentCgroupName)
+	err = os.MkdirAll(agentDir, 0o755)
+	if err != nil {
+		return nil, xerrors.Errorf("create agent cgroup: %w", err)
+	}
+	err = moveCgroupProcs(root, agentDir)
+	if err != nil {
+		return nil, xerrors.Errorf("move processes to agent cgroup: %w", err)
+	}
+
+	err = enableCgroupControllers(root, classes)
+	if err != nil {
+		return nil, err
+	}
+
+	for class, limits := range classes {
+		dir := filepath.Join(root, string(class))
+		err = os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return nil, xerrors.Errorf("create %s cgroup: %w", class, err)
+		}
+		for name, value := range limits.cgroupFiles() {
+			err = os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600)
+			if err != nil {
+				return nil, xerrors.Errorf("set %s of %s cgroup: %w", name, class, err)
+			}
+		}
+	}
+
+	cgroups := &Cgroups{root: root, classes: classes}
+	activeCgroups.Store(cgroups)
+	return cgroups, nil
+}
+
+// selfCgroup returns the cgroup v2 path of the current process, relative to
+// the cgroup mount.
+func selfCgroup() (string, error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", xerrors.Errorf("read cgroup: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		// The cgroup v2 hierarchy has ID 0 and no controllers.
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", xerrors.New("cgroup v2 is not in use")
+}
+
+func moveCgroupProcs(from, to string) error {
+	data, err := os.ReadFile(filepath.Join(from, "cgroup.procs"))
+	if err != nil {
+		return err
+	}
+	for _, pid := range strings.Fields(string(data)) {
+		err = os.WriteFile(filepath.Join(to, "cgroup.procs"), []byte(pid), 0o600)
+		// The process may have exited in the meantime.
+		if err != nil && !errors.Is(err, syscall.ESRCH) {
+			return xerrors.Errorf("move process %s: %w", pid, err)
+		}
+	}
+	return nil
+}
+
+// enableCgroupControllers enables the controllers needed by the limits for
+// the children of the cgroup.
+func enableCgroupControllers(dir string, classes map[CgroupClass]CgroupLimits) error {
+	needed := map[string]bool{}
+	for _, limits := range classes {
+		for name := range limits.cgroupFiles() {
+			controller, _, _ := strings.Cut(name, ".")
+			needed[controller] = true
+		}
+	}
+	// OOM kills are counted by the memory controller.
+	needed["memory"] = true
+
+	data, err := os.ReadFile(filepath.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		return xerrors.Errorf("read available cgroup controllers: %w", err)
+	}
+	available := map[string]bool{}
+	for _, controller := range strings.Fields(string(data)) {
+		available[controller] = true
+	}
+	for controller := range needed {
+		if !available[controller] {
+			return xerrors.Errorf("the %s cgroup controller is not available", controller)
+		}
+		err = os.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), []byte("+"+controller), 0o600)
+		if err != nil {
+			return xerrors.Errorf("enable %s cgroup controller: %w", controller, err)
+		}
+	}
+	return nil
+}
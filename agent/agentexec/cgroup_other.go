@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package agentexec
+
+import "golang.org/x/xerrors"
+
+// SetupCgroups is only supported on Linux. It returns nil if no limits are
+// configured.
+func SetupCgroups() (*Cgroups, error) {
+	classes, err := configuredCgroupLimits()
+	if err != nil {
+		return nil, err
+	}
+	if len(classes) == 0 {
+		return nil, nil
+	}
+	return nil, xerrors.New("cgroup limits are only supported on Linux")
+}
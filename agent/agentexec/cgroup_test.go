@@ -0,0 +1,71 @@
+package agentexec_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agentexec"
+)
+
+func TestParseCgroupLimits(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		Name   string
+		Value  string
+		Limits agentexec.CgroupLimits
+		Error  string
+	}{
+		{
+			Name:  "All",
+			Value: "cpu_weight=50, cpu_max=1.5, memory_max=4GiB, pids_max=1000",
+			Limits: agentexec.CgroupLimits{
+				CPUWeight: 50,
+				CPUMax:    1.5,
+				MemoryMax: 4 << 30,
+				PIDsMax:   1000,
+			},
+		},
+		{
+			Name:   "Memory",
+			Value:  "memory_max=512M",
+			Limits: agentexec.CgroupLimits{MemoryMax: 512_000_000},
+		},
+		{
+			Name:  "Empty",
+			Value: "",
+		},
+		{
+			Name:  "UnknownKey",
+			Value: "disk_max=10G",
+			Error: `unknown limit "disk_max"`,
+		},
+		{
+			Name:  "MissingValue",
+			Value: "cpu_max",
+			Error: "must be in the format key=value",
+		},
+		{
+			Name:  "WeightOutOfRange",
+			Value: "cpu_weight=0",
+			Error: "must be between 1 and 10000",
+		},
+		{
+			Name:  "NegativeCPU",
+			Value: "cpu_max=-1",
+			Error: "must be positive",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			t.Parallel()
+			limits, err := agentexec.ParseCgroupLimits(tt.Value)
+			if tt.Error != "" {
+				require.ErrorContains(t, err, tt.Error)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.Limits, limits)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -30,9 +31,11 @@ func CLI() error {
 	defer runtime.UnlockOSThread()
 
 	var (
-		fs   = flag.NewFlagSet("agent-exec", flag.ExitOnError)
-		nice = fs.Int("coder-nice", unset, "")
-		oom  = fs.Int("coder-oom", unset, "")
+		fs     = flag.NewFlagSet("agent-exec", flag.ExitOnError)
+		nice   = fs.Int("coder-nice", unset, "")
+		oom    = fs.Int("coder-oom", unset, "")
+		prio   = fs.Bool("coder-prio", true, "")
+		cgroup = fs.String("coder-cgroup", "", "")
 	)
 
 	if len(os.Args) < 3 {
@@ -51,6 +54,19 @@ func CLI() error {
 		return xerrors.Errorf("no exec command provided %+v", os.Args)
 	}
 
+	if *cgroup != "" {
+		err = joinCgroup(*cgroup)
+		if err != nil {
+			// Like the priorities below, a broken cgroup shouldn't prevent
+			// the user from running commands.
+			printfStdErr("failed to join cgroup %s for cmd %+v: %v", *cgroup, args, err)
+		}
+	}
+
+	if !*prio {
+		return execCmd(args)
+	}
+
 	if *nice == unset {
 		// If an explicit nice score isn't set, we use the default.
 		*nice, err = defaultNiceScore()
@@ -83,6 +99,10 @@ func CLI() error {
 		printfStdErr("failed to adjust oom score to %d for cmd %+v: %v", *oom, args, err)
 	}
 
+	return execCmd(args)
+}
+
+func execCmd(args []string) error {
 	path, err := exec.LookPath(args[0])
 	if err != nil {
 		return xerrors.Errorf("look path: %w", err)
@@ -91,6 +111,13 @@ func CLI() error {
 	return syscall.Exec(path, args, os.Environ())
 }
 
+// joinCgroup moves the current process into the cgroup directory, so the
+// command it execs is subject to the limits of the cgroup.
+func joinCgroup(dir string) error {
+	// Writing 0 moves the writing process.
+	return os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte("0"), 0o600)
+}
+
 func defaultNiceScore() (int, error) {
 	score, err := unix.Getpriority(unix.PRIO_PROCESS, 0)
 	if err != nil {
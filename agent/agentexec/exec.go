@@ -23,11 +23,12 @@ const (
 )
 
 // CommandContext returns an exec.Cmd that calls "coder agent-exec" prior to exec'ing
-// the provided command if WIRTUAL_PROC_PRIO_MGMT is set, otherwise a normal exec.Cmd
-// is returned. All instances of exec.Cmd should flow through this function to ensure
-// proper resource constraints are applied to the child process.
+// the provided command if WIRTUAL_PROC_PRIO_MGMT is set or the context places it in a
+// limited cgroup, otherwise a normal exec.Cmd is returned. All instances of exec.Cmd
+// should flow through this function to ensure proper resource constraints are
+// applied to the child process.
 func CommandContext(ctx context.Context, cmd string, args ...string) (*exec.Cmd, error) {
-	cmd, args, err := agentExecCmd(cmd, args...)
+	cmd, args, err := agentExecCmd(ctx, cmd, args...)
 	if err != nil {
 		return nil, xerrors.Errorf("agent exec cmd: %w", err)
 	}
@@ -35,20 +36,22 @@ func CommandContext(ctx context.Context, cmd string, args ...string) (*exec.Cmd,
 }
 
 // PTYCommandContext returns an pty.Cmd that calls "coder agent-exec" prior to exec'ing
-// the provided command if WIRTUAL_PROC_PRIO_MGMT is set, otherwise a normal pty.Cmd
-// is returned. All instances of pty.Cmd should flow through this function to ensure
-// proper resource constraints are applied to the child process.
+// the provided command if WIRTUAL_PROC_PRIO_MGMT is set or the context places it in a
+// limited cgroup, otherwise a normal pty.Cmd is returned. All instances of pty.Cmd
+// should flow through this function to ensure proper resource constraints are
+// applied to the child process.
 func PTYCommandContext(ctx context.Context, cmd string, args ...string) (*pty.Cmd, error) {
-	cmd, args, err := agentExecCmd(cmd, args...)
+	cmd, args, err := agentExecCmd(ctx, cmd, args...)
 	if err != nil {
 		return nil, xerrors.Errorf("agent exec cmd: %w", err)
 	}
 	return pty.CommandContext(ctx, cmd, args...), nil
 }
 
-func agentExecCmd(cmd string, args ...string) (string, []string, error) {
+func agentExecCmd(ctx context.Context, cmd string, args ...string) (string, []string, error) {
 	_, enabled := os.LookupEnv(EnvProcPrioMgmt)
-	if runtime.GOOS != "linux" || !enabled {
+	cgroup := cgroupDir(ctx)
+	if runtime.GOOS != "linux" || (!enabled && cgroup == "") {
 		return cmd, args, nil
 	}
 
@@ -63,12 +66,20 @@ func agentExecCmd(cmd string, args ...string) (string, []string, error) {
 	}
 
 	execArgs := []string{"agent-exec"}
-	if score, ok := envValInt(EnvProcOOMScore); ok {
-		execArgs = append(execArgs, oomScoreArg(score))
+	if enabled {
+		if score, ok := envValInt(EnvProcOOMScore); ok {
+			execArgs = append(execArgs, oomScoreArg(score))
+		}
+
+		if score, ok := envValInt(EnvProcNiceScore); ok {
+			execArgs = append(execArgs, niceScoreArg(score))
+		}
+	} else {
+		// Only the cgroup is managed, so priorities are left alone.
+		execArgs = append(execArgs, prioArg(false))
 	}
-
-	if score, ok := envValInt(EnvProcNiceScore); ok {
-		execArgs = append(execArgs, niceScoreArg(score))
+	if cgroup != "" {
+		execArgs = append(execArgs, cgroupArg(cgroup))
 	}
 	execArgs = append(execArgs, "--", cmd)
 	execArgs = append(execArgs, args...)
@@ -95,8 +106,10 @@ func envValInt(key string) (int, bool) {
 // environment variables to avoid having to deal with a caller overriding the
 // environment variables.
 const (
-	niceFlag = "coder-nice"
-	oomFlag  = "coder-oom"
+	niceFlag   = "coder-nice"
+	oomFlag    = "coder-oom"
+	prioFlag   = "coder-prio"
+	cgroupFlag = "coder-cgroup"
 )
 
 func niceScoreArg(score int) string {
@@ -106,3 +119,11 @@ func niceScoreArg(score int) string {
 func oomScoreArg(score int) string {
 	return fmt.Sprintf("--%s=%d", oomFlag, score)
 }
+
+func prioArg(enabled bool) string {
+	return fmt.Sprintf("--%s=%t", prioFlag, enabled)
+}
+
+func cgroupArg(dir string) string {
+	return fmt.Sprintf("--%s=%s", cgroupFlag, dir)
+}
@@ -0,0 +1,304 @@
+// Package agentlogfiles forwards local log files of a workspace to wirtuald,
+// each as a log source of the agent.
+package agentlogfiles
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/quartz"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/agentsdk"
+)
+
+const (
+	// DefaultPollInterval is how often log files are checked for new lines
+	// and the patterns for new files.
+	DefaultPollInterval = time.Second
+
+	// Icon is the icon of the log sources of forwarded files.
+	Icon = "/emojis/1f4c4.png"
+
+	// maxLineLength is the number of bytes kept of a line, longer lines are
+	// truncated.
+	maxLineLength = 4 << 10
+	// maxReadPerPoll limits how much of a file is read in one poll, so a
+	// file that grows quickly can't hold up the others.
+	maxReadPerPoll = 1 << 20
+	// maxDisplayNameLength is the length of the display name column of log
+	// sources.
+	maxDisplayNameLength = 127
+)
+
+// logSourceNamespace derives log source IDs from file paths, so a file keeps
+// its log source when the agent restarts.
+var logSourceNamespace = uuid.MustParse("5a4c2b9e-6a0e-4f3b-9b1e-0d5c8f2e7a61")
+
+// LogSourceID returns the ID of the log source of the file at path.
+func LogSourceID(path string) uuid.UUID {
+	return uuid.NewSHA1(logSourceNamespace, []byte(path))
+}
+
+// CreateLogSourcesFunc creates log sources in wirtuald.
+type CreateLogSourcesFunc func(context.Context, *proto.BatchCreateLogSourcesRequest) (*proto.BatchCreateLogSourcesResponse, error)
+
+// Options are a set of options for the forwarder.
+type Options struct {
+	Logger slog.Logger
+	// Patterns are the glob patterns of the files to forward. Relative
+	// patterns are resolved against the directory passed to Run.
+	Patterns []string
+	// Enqueue queues lines to be sent for a log source, see
+	// agentsdk.LogSender.
+	Enqueue func(src uuid.UUID, logs ...agentsdk.Log)
+	// PollInterval defaults to DefaultPollInterval.
+	PollInterval time.Duration
+	// Clock defaults to the real clock.
+	Clock quartz.Clock
+}
+
+// Forwarder tails the files matching a set of glob patterns and forwards
+// their lines. Files that exist when the forwarder first runs are read from
+// their end, so only new lines are forwarded, and files found later are
+// read from the start.
+type Forwarder struct {
+	Options
+
+	mu      sync.Mutex
+	scanned bool
+	// files is kept across runs, so lines aren't forwarded twice when the
+	// agent reconnects.
+	files map[string]*file
+}
+
+type file struct {
+	path     string
+	sourceID uuid.UUID
+	info     os.FileInfo
+	offset   int64
+	// partial is the start of a line that hasn't been terminated yet.
+	partial []byte
+}
+
+func New(opts Options) *Forwarder {
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Clock == nil {
+		opts.Clock = quartz.NewReal()
+	}
+	return &Forwarder{
+		Options: opts,
+		files:   make(map[string]*file),
+	}
+}
+
+// Enabled returns whether any log files are configured.
+func (f *Forwarder) Enabled() bool {
+	return len(f.Patterns) > 0
+}
+
+// Run forwards the log files until the context is canceled. Log sources are
+// created for the files before their first lines are queued.
+func (f *Forwarder) Run(ctx context.Context, dir string, createSources CreateLogSourcesFunc) error {
+	if !f.Enabled() {
+		return nil
+	}
+	created := make(map[uuid.UUID]struct{})
+	ticker := f.Clock.NewTicker(f.PollInterval, "agentlogfiles", "poll")
+	defer ticker.Stop()
+	for {
+		err := f.poll(ctx, dir, created, createSources)
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *Forwarder) poll(ctx context.Context, dir string, created map[uuid.UUID]struct{}, createSources CreateLogSourcesFunc) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.scan(ctx, dir)
+
+	var pending []*file
+	for _, lf := range f.files {
+		if _, ok := created[lf.sourceID]; !ok {
+			pending = append(pending, lf)
+		}
+	}
+	if len(pending) > 0 {
+		sort.Slice(pending, func(i, j int) bool {
+			return pending[i].path < pending[j].path
+		})
+		sources := make([]*proto.LogSource, 0, len(pending))
+		for _, lf := range pending {
+			sources = append(sources, &proto.LogSource{
+				Id:          lf.sourceID[:],
+				DisplayName: displayName(lf.path),
+				Icon:        Icon,
+			})
+		}
+		_, err := createSources(ctx, &proto.BatchCreateLogSourcesRequest{LogSources: sources})
+		if err != nil {
+			return xerrors.Errorf("batch create log sources: %w", err)
+		}
+		for _, lf := range pending {
+			created[lf.sourceID] = struct{}{}
+		}
+	}
+
+	for _, lf := range f.files {
+		logs, err := lf.read()
+		if err != nil {
+			f.Logger.Debug(ctx, "read log file", slog.F("path", lf.path), slog.Error(err))
+			continue
+		}
+		if len(logs) > 0 {
+			f.Enqueue(lf.sourceID, logs...)
+		}
+	}
+	return nil
+}
+
+// scan adds the files matching the patterns that aren't forwarded yet.
+func (f *Forwarder) scan(ctx context.Context, dir string) {
+	initial := !f.scanned
+	f.scanned = true
+	for _, pattern := range f.Patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Logger.Warn(ctx, "invalid log file pattern", slog.F("pattern", pattern), slog.Error(err))
+			continue
+		}
+		for _, path := range matches {
+			if _, ok := f.files[path]; ok {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			lf := &file{
+				path:     path,
+				sourceID: LogSourceID(path),
+				info:     info,
+			}
+			if initial {
+				lf.offset = info.Size()
+			}
+			f.Logger.Debug(ctx, "forwarding log file", slog.F("path", path), slog.F("offset", lf.offset))
+			f.files[path] = lf
+		}
+	}
+}
+
+// read returns the lines added to the file since the last read. Files that
+// were truncated or replaced, like when they're rotated, are read from the
+// start again.
+func (lf *file) read() ([]agentsdk.Log, error) {
+	info, err := os.Stat(lf.path)
+	if err != nil {
+		return nil, err
+	}
+	if !os.SameFile(lf.info, info) || info.Size() < lf.offset {
+		lf.offset = 0
+		lf.partial = nil
+	}
+	lf.info = info
+	if info.Size() == lf.offset {
+		return nil, nil
+	}
+
+	fd, err := os.Open(lf.path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	data, err := io.ReadAll(io.LimitReader(io.NewSectionReader(fd, lf.offset, info.Size()-lf.offset), maxReadPerPoll))
+	if err != nil {
+		return nil, err
+	}
+	lf.offset += int64(len(data))
+
+	now := time.Now()
+	var logs []agentsdk.Log
+	data = append(lf.partial, data...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if log, ok := parseLine(now, data[:i]); ok {
+			logs = append(logs, log)
+		}
+		data = data[i+1:]
+	}
+	// Lines without a newline are kept until they're terminated, unless
+	// they're already too long to be kept whole.
+	if len(data) >= maxLineLength {
+		if log, ok := parseLine(now, data); ok {
+			logs = append(logs, log)
+		}
+		data = nil
+	}
+	lf.partial = append([]byte(nil), data...)
+	return logs, nil
+}
+
+func parseLine(now time.Time, line []byte) (agentsdk.Log, bool) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return agentsdk.Log{}, false
+	}
+	if len(line) > maxLineLength {
+		line = line[:maxLineLength]
+		// Don't cut a multi-byte character in half.
+		for i := 1; i < utf8.UTFMax; i++ {
+			r, size := utf8.DecodeLastRune(line)
+			if r != utf8.RuneError || size != 1 {
+				break
+			}
+			line = line[:len(line)-1]
+		}
+	}
+	output := string(bytes.ToValidUTF8(line, []byte("�")))
+	return agentsdk.Log{
+		CreatedAt: now,
+		Output:    output,
+		Level:     ParseLevel(output),
+	}, true
+}
+
+// displayName returns the path, shortened from the start to fit the display
+// name of a log source.
+func displayName(path string) string {
+	if len(path) <= maxDisplayNameLength {
+		return path
+	}
+	path = path[len(path)-maxDisplayNameLength+3:]
+	for len(path) > 0 && !utf8.RuneStart(path[0]) {
+		path = path[1:]
+	}
+	return "..." + path
+}
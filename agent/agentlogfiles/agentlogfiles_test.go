@@ -0,0 +1,142 @@
+package agentlogfiles_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentlogfiles"
+	"github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/agentsdk"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		line  string
+		level wirtualsdk.LogLevel
+	}{
+		{`{"level": "error", "msg": "failed"}`, wirtualsdk.LogLevelError},
+		{`{"severity": "WARNING", "msg": "slow"}`, wirtualsdk.LogLevelWarn},
+		{`{"msg": "no level"}`, wirtualsdk.LogLevelInfo},
+		{`time=2024-01-02T15:04:05Z level=debug msg="connecting"`, wirtualsdk.LogLevelDebug},
+		{`2024-01-02T15:04:05Z [ERROR] connection refused`, wirtualsdk.LogLevelError},
+		{`WARNING: disk almost full`, wirtualsdk.LogLevelWarn},
+		{`2024/01/02 15:04:05 trace: entering handler`, wirtualsdk.LogLevelTrace},
+		{`[fatal] out of memory`, wirtualsdk.LogLevelError},
+		{`listening on :8080`, wirtualsdk.LogLevelInfo},
+		// Keywords far into the message aren't levels.
+		{`request completed in 12ms with a response from the upstream error handler`, wirtualsdk.LogLevelInfo},
+		// Words that merely contain a level aren't levels.
+		{`information about the build`, wirtualsdk.LogLevelInfo},
+	} {
+		require.Equal(t, tc.level, agentlogfiles.ParseLevel(tc.line), tc.line)
+	}
+}
+
+func TestForwarder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.log")
+	require.NoError(t, os.WriteFile(existing, []byte("old line\n"), 0o600))
+	// Files that don't match the pattern are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("ignored\n"), 0o600))
+
+	var (
+		mu      sync.Mutex
+		sources = map[uuid.UUID]*proto.LogSource{}
+		logs    = map[uuid.UUID][]agentsdk.Log{}
+	)
+	f := agentlogfiles.New(agentlogfiles.Options{
+		Logger:   slogtest.Make(t, nil),
+		Patterns: []string{"*.log"},
+		Enqueue: func(src uuid.UUID, l ...agentsdk.Log) {
+			mu.Lock()
+			defer mu.Unlock()
+			require.Contains(t, sources, src, "logs queued before their source was created")
+			logs[src] = append(logs[src], l...)
+		},
+		PollInterval: testutil.IntervalFast,
+	})
+	require.True(t, f.Enabled())
+
+	ctx, cancel := context.WithCancel(testutil.Context(t, testutil.WaitLong))
+	done := make(chan error, 1)
+	go func() {
+		done <- f.Run(ctx, dir, func(_ context.Context, req *proto.BatchCreateLogSourcesRequest) (*proto.BatchCreateLogSourcesResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, source := range req.LogSources {
+				sources[uuid.UUID(source.Id)] = source
+			}
+			return &proto.BatchCreateLogSourcesResponse{}, nil
+		})
+	}()
+
+	outputs := func(path string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var out []string
+		for _, l := range logs[agentlogfiles.LogSourceID(path)] {
+			out = append(out, string(l.Level)+" "+l.Output)
+		}
+		return out
+	}
+	waitForOutputs := func(path string, want ...string) {
+		t.Helper()
+		testutil.Eventually(ctx, t, func(context.Context) bool {
+			return strings.Join(outputs(path), "\n") == strings.Join(want, "\n")
+		}, testutil.IntervalFast)
+	}
+
+	// Files that exist when the forwarder first runs are only forwarded from
+	// their end, so wait for the first scan before appending.
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return sources[agentlogfiles.LogSourceID(existing)] != nil
+	}, testutil.IntervalFast)
+	appendFile(t, existing, "new line\nERROR: partial")
+	waitForOutputs(existing, "info new line")
+	appendFile(t, existing, " line\n")
+	waitForOutputs(existing, "info new line", "error ERROR: partial line")
+
+	// New files are forwarded from their start.
+	created := filepath.Join(dir, "created.log")
+	appendFile(t, created, `{"level": "warn", "msg": "hello"}`+"\n")
+	waitForOutputs(created, `warn {"level": "warn", "msg": "hello"}`)
+
+	// Truncated files are read from the start again.
+	require.NoError(t, os.WriteFile(created, []byte("rotated\n"), 0o600))
+	waitForOutputs(created, `warn {"level": "warn", "msg": "hello"}`, "info rotated")
+
+	mu.Lock()
+	require.Len(t, sources, 2)
+	source := sources[agentlogfiles.LogSourceID(created)]
+	mu.Unlock()
+	require.Equal(t, created, source.DisplayName)
+	require.Equal(t, agentlogfiles.Icon, source.Icon)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	fd, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = fd.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+}
@@ -0,0 +1,73 @@
+package agentlogfiles
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+var (
+	// logfmtLevel matches the level of logfmt lines, like "level=warn".
+	logfmtLevel = regexp.MustCompile(`(?i)\b(?:level|lvl|severity)=["']?([a-z]+)`)
+	// prefixLevel matches a level near the start of a line, like
+	// "2024-01-02T15:04:05Z [ERROR] message" or "WARNING: message".
+	prefixLevel = regexp.MustCompile(`(?i)\b(trace|debug|info|notice|warn|warning|error|err|fatal|panic|crit|critical)\b`)
+)
+
+// levelPrefixLength is how far into a line a level keyword is looked for.
+// Keywords later in the line are most likely part of the message.
+const levelPrefixLength = 48
+
+// ParseLevel detects the level of a log line. JSON lines use their level
+// field, other lines a level keyword near their start. Lines without a level
+// are info.
+func ParseLevel(line string) wirtualsdk.LogLevel {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		var fields map[string]any
+		if json.Unmarshal([]byte(trimmed), &fields) == nil {
+			for _, key := range []string{"level", "lvl", "severity", "log.level"} {
+				if value, ok := fields[key].(string); ok {
+					if level, ok := levelFromName(value); ok {
+						return level
+					}
+				}
+			}
+			return wirtualsdk.LogLevelInfo
+		}
+	}
+	if match := logfmtLevel.FindStringSubmatch(line); match != nil {
+		if level, ok := levelFromName(match[1]); ok {
+			return level
+		}
+	}
+	prefix := line
+	if len(prefix) > levelPrefixLength {
+		prefix = prefix[:levelPrefixLength]
+	}
+	if match := prefixLevel.FindStringSubmatch(prefix); match != nil {
+		if level, ok := levelFromName(match[1]); ok {
+			return level
+		}
+	}
+	return wirtualsdk.LogLevelInfo
+}
+
+func levelFromName(name string) (wirtualsdk.LogLevel, bool) {
+	switch strings.ToLower(name) {
+	case "trace":
+		return wirtualsdk.LogLevelTrace, true
+	case "debug":
+		return wirtualsdk.LogLevelDebug, true
+	case "info", "notice":
+		return wirtualsdk.LogLevelInfo, true
+	case "warn", "warning":
+		return wirtualsdk.LogLevelWarn, true
+	case "error", "err", "fatal", "panic", "crit", "critical":
+		return wirtualsdk.LogLevelError, true
+	default:
+		return "", false
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
 	"os/user"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,6 +25,7 @@ import (
 
 	"cdr.dev/slog"
 
+	"github.com/onchainengineering/hmi-wirtual/agent/agentexec"
 	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
 	"github.com/onchainengineering/hmi-wirtual/agent/proto"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
@@ -41,10 +44,21 @@ var (
 	//    blocking login, and avoiding doing so indefinitely)
 	// 2. Improved command cancellation on timeout
 	ErrOutputPipesOpen = xerrors.New("script exited without closing output pipes")
+	// ErrNotReady is returned when the readiness condition of a script is not
+	// met within its readiness timeout.
+	ErrNotReady = xerrors.New("script readiness condition not met")
 
 	parser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.DowOptional)
 )
 
+const (
+	// DefaultReadyTimeout is how long the readiness condition of a script is
+	// waited for when the script does not set a timeout.
+	DefaultReadyTimeout = 5 * time.Minute
+
+	readyPollInterval = 250 * time.Millisecond
+)
+
 type ScriptLogger interface {
 	Send(ctx context.Context, log ...agentsdk.Log) error
 	Flush(context.Context) error
@@ -126,12 +140,16 @@ func (r *Runner) Init(scripts []wirtualsdk.WorkspaceAgentScript, scriptCompleted
 	if r.initialized.Load() {
 		return xerrors.New("init: already initialized")
 	}
+	err := wirtualsdk.ValidateWorkspaceAgentScriptDependencies(scripts)
+	if err != nil {
+		return xerrors.Errorf("init: %w", err)
+	}
 	r.initialized.Store(true)
 	r.scripts = scripts
 	r.scriptCompleted = scriptCompleted
 	r.Logger.Info(r.cronCtx, "initializing agent scripts", slog.F("script_count", len(scripts)), slog.F("log_dir", r.LogDir))
 
-	err := r.Filesystem.MkdirAll(r.ScriptBinDir(), 0o700)
+	err = r.Filesystem.MkdirAll(r.ScriptBinDir(), 0o700)
 	if err != nil {
 		return xerrors.Errorf("create script bin dir: %w", err)
 	}
@@ -190,9 +208,12 @@ const (
 	ExecuteCronScripts
 )
 
-// Execute runs a set of scripts according to a filter.
+// Execute runs a set of scripts according to a filter. Scripts start
+// concurrently, except that a script waits for the scripts it depends on to
+// succeed, or become ready. When one of them fails the script is skipped, and
+// so are its own dependents, while other scripts are unaffected.
 func (r *Runner) Execute(ctx context.Context, option ExecuteOption) error {
-	var eg errgroup.Group
+	scripts := make([]wirtualsdk.WorkspaceAgentScript, 0, len(r.scripts))
 	for _, script := range r.scripts {
 		runScript := (option == ExecuteStartScripts && script.RunOnStart) ||
 			(option == ExecuteStopScripts && script.RunOnStop) ||
@@ -202,10 +223,42 @@ func (r *Runner) Execute(ctx context.Context, option ExecuteOption) error {
 		if !runScript {
 			continue
 		}
+		scripts = append(scripts, script)
+	}
+
+	results := make([]*scriptResult, len(scripts))
+	resultsByID := make(map[uuid.UUID]*scriptResult, len(scripts))
+	for i, script := range scripts {
+		results[i] = &scriptResult{script: script, done: make(chan struct{})}
+		resultsByID[script.ID] = results[i]
+	}
 
+	var eg errgroup.Group
+	for i, script := range scripts {
 		script := script
+		result := results[i]
 		eg.Go(func() error {
-			err := r.trackRun(ctx, script, option)
+			for _, dependencyID := range script.DependsOn {
+				dependency, ok := resultsByID[dependencyID]
+				if !ok {
+					// The dependency is not run by this execution, e.g. a
+					// start script that a stop script depends on.
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					result.resolve(false)
+					return ctx.Err()
+				case <-dependency.done:
+				}
+				if !dependency.ok {
+					result.resolve(false)
+					r.skip(ctx, script, option, dependency.script)
+					return nil
+				}
+			}
+
+			err := r.runUntilReady(ctx, script, option, result.resolve)
 			if err != nil {
 				return xerrors.Errorf("run agent script %q: %w", script.LogSourceID, err)
 			}
@@ -215,6 +268,143 @@ func (r *Runner) Execute(ctx context.Context, option ExecuteOption) error {
 	return eg.Wait()
 }
 
+// scriptResult is resolved once the dependents of a script may start, or
+// must be skipped.
+type scriptResult struct {
+	script wirtualsdk.WorkspaceAgentScript
+	done   chan struct{}
+	// ok is set before done is closed.
+	ok bool
+}
+
+func (s *scriptResult) resolve(ok bool) {
+	s.ok = ok
+	close(s.done)
+}
+
+// runUntilReady runs the script and resolves whether its dependents may
+// start. A script without a readiness condition is ready once it exits
+// successfully. Otherwise the condition is polled while the script runs,
+// and after it exits successfully, until it holds or the readiness timeout
+// passes.
+func (r *Runner) runUntilReady(ctx context.Context, script wirtualsdk.WorkspaceAgentScript, option ExecuteOption, resolve func(ok bool)) error {
+	if script.Ready == nil {
+		err := r.trackRun(ctx, script, option)
+		resolve(err == nil)
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- r.trackRun(ctx, script, option)
+	}()
+
+	timeout := script.Ready.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	var (
+		err      error
+		running  = true
+		readyErr error
+	)
+wait:
+	for {
+		if isReady(script.Ready) {
+			resolve(true)
+			break
+		}
+		select {
+		case err = <-exited:
+			running = false
+			if err != nil {
+				resolve(false)
+				return err
+			}
+			// The script may have started a process in the background,
+			// so keep waiting for the condition.
+			exited = nil
+		case <-ticker.C:
+		case <-timer.C:
+			resolve(false)
+			readyErr = ErrNotReady
+			message := fmt.Sprintf("%s after %s, skipping the scripts that depend on it", ErrNotReady, timeout)
+			r.Logger.Warn(ctx, message, slog.F("log_source_id", script.LogSourceID))
+			r.sendLog(ctx, script, wirtualsdk.LogLevelError, message)
+			break wait
+		case <-ctx.Done():
+			resolve(false)
+			break wait
+		}
+	}
+	if running {
+		err = <-exited
+	}
+	if err == nil {
+		err = readyErr
+	}
+	return err
+}
+
+// isReady reports whether all conditions that are set hold.
+func isReady(ready *wirtualsdk.WorkspaceAgentScriptReadiness) bool {
+	if ready.File != "" {
+		_, err := os.Stat(os.ExpandEnv(ready.File))
+		if err != nil {
+			return false
+		}
+	}
+	if ready.Port != 0 {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(int(ready.Port))), time.Second)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+	}
+	return true
+}
+
+// skip reports a script that is not run because a script it depends on did
+// not succeed.
+func (r *Runner) skip(ctx context.Context, script wirtualsdk.WorkspaceAgentScript, option ExecuteOption, dependency wirtualsdk.WorkspaceAgentScript) {
+	dependencyName := dependency.DisplayName
+	if dependencyName == "" {
+		dependencyName = dependency.ID.String()
+	}
+	message := fmt.Sprintf("Skipped, the script %q it depends on did not succeed.", dependencyName)
+	logger := r.Logger.With(slog.F("log_source_id", script.LogSourceID))
+	logger.Warn(ctx, "skipping agent script", slog.F("dependency_id", dependency.ID))
+	r.sendLog(ctx, script, wirtualsdk.LogLevelWarn, message)
+
+	now := dbtime.Now()
+	r.reportCompleted(logger, script, option, &proto.Timing{
+		Start:  timestamppb.New(now),
+		End:    timestamppb.New(now),
+		Status: proto.Timing_SKIPPED,
+	})
+}
+
+// sendLog sends a single line to the logs of the script.
+func (r *Runner) sendLog(ctx context.Context, script wirtualsdk.WorkspaceAgentScript, level wirtualsdk.LogLevel, output string) {
+	scriptLogger := r.GetScriptLogger(script.LogSourceID)
+	err := scriptLogger.Send(ctx, agentsdk.Log{
+		CreatedAt: dbtime.Now(),
+		Output:    output,
+		Level:     level,
+	})
+	if err == nil {
+		err = scriptLogger.Flush(ctx)
+	}
+	if err != nil {
+		r.Logger.Warn(ctx, "send agent script log failed", slog.F("log_source_id", script.LogSourceID), slog.Error(err))
+	}
+}
+
 // trackRun wraps "run" with metrics.
 func (r *Runner) trackRun(ctx context.Context, script wirtualsdk.WorkspaceAgentScript, option ExecuteOption) error {
 	err := r.run(ctx, script, option)
@@ -283,7 +473,7 @@ func (r *Runner) run(ctx context.Context, script wirtualsdk.WorkspaceAgentScript
 		cmdCtx, ctxCancel = context.WithTimeout(ctx, script.Timeout)
 		defer ctxCancel()
 	}
-	cmdPty, err := r.SSHServer.CreateCommand(cmdCtx, script.Script, nil)
+	cmdPty, err := r.SSHServer.CreateCommand(agentexec.WithCgroupClass(cmdCtx, agentexec.CgroupScripts), script.Script, nil)
 	if err != nil {
 		return xerrors.Errorf("%s script: create command: %w", logPath, err)
 	}
@@ -333,59 +523,24 @@ func (r *Runner) run(ctx context.Context, script wirtualsdk.WorkspaceAgentScript
 			logger.Info(ctx, fmt.Sprintf("%s script completed", logPath), slog.F("execution_time", execTime), slog.F("exit_code", exitCode))
 		}
 
-		if r.scriptCompleted == nil {
-			logger.Debug(ctx, "r.scriptCompleted unexpectedly nil")
-			return
+		var status proto.Timing_Status
+		switch {
+		case errors.Is(err, ErrTimeout):
+			status = proto.Timing_TIMED_OUT
+		case errors.Is(err, ErrOutputPipesOpen):
+			status = proto.Timing_PIPES_LEFT_OPEN
+		case exitCode != 0:
+			status = proto.Timing_EXIT_FAILURE
+		default:
+			status = proto.Timing_OK
 		}
 
-		// We want to check this outside of the goroutine to avoid a race condition
-		timedOut := errors.Is(err, ErrTimeout)
-		pipesLeftOpen := errors.Is(err, ErrOutputPipesOpen)
-
-		err = r.trackCommandGoroutine(func() {
-			var stage proto.Timing_Stage
-			switch option {
-			case ExecuteStartScripts:
-				stage = proto.Timing_START
-			case ExecuteStopScripts:
-				stage = proto.Timing_STOP
-			case ExecuteCronScripts:
-				stage = proto.Timing_CRON
-			}
-
-			var status proto.Timing_Status
-			switch {
-			case timedOut:
-				status = proto.Timing_TIMED_OUT
-			case pipesLeftOpen:
-				status = proto.Timing_PIPES_LEFT_OPEN
-			case exitCode != 0:
-				status = proto.Timing_EXIT_FAILURE
-			default:
-				status = proto.Timing_OK
-			}
-
-			reportTimeout := 30 * time.Second
-			reportCtx, cancel := context.WithTimeout(context.Background(), reportTimeout)
-			defer cancel()
-
-			_, err := r.scriptCompleted(reportCtx, &proto.WorkspaceAgentScriptCompletedRequest{
-				Timing: &proto.Timing{
-					ScriptId: script.ID[:],
-					Start:    timestamppb.New(start),
-					End:      timestamppb.New(end),
-					ExitCode: int32(exitCode),
-					Stage:    stage,
-					Status:   status,
-				},
-			})
-			if err != nil {
-				logger.Error(ctx, fmt.Sprintf("reporting script completed: %s", err.Error()))
-			}
+		r.reportCompleted(logger, script, option, &proto.Timing{
+			Start:    timestamppb.New(start),
+			End:      timestamppb.New(end),
+			ExitCode: int32(exitCode),
+			Status:   status,
 		})
-		if err != nil {
-			logger.Error(ctx, fmt.Sprintf("reporting script completed: track command goroutine: %s", err.Error()))
-		}
 	}()
 
 	err = cmd.Start()
@@ -434,6 +589,42 @@ func (r *Runner) run(ctx context.Context, script wirtualsdk.WorkspaceAgentScript
 	return err
 }
 
+// reportCompleted reports the timing of a script in the background. The
+// script ID and stage are filled in from the script and option.
+func (r *Runner) reportCompleted(logger slog.Logger, script wirtualsdk.WorkspaceAgentScript, option ExecuteOption, timing *proto.Timing) {
+	ctx := context.Background()
+	if r.scriptCompleted == nil {
+		logger.Debug(ctx, "r.scriptCompleted unexpectedly nil")
+		return
+	}
+
+	timing.ScriptId = script.ID[:]
+	switch option {
+	case ExecuteStartScripts:
+		timing.Stage = proto.Timing_START
+	case ExecuteStopScripts:
+		timing.Stage = proto.Timing_STOP
+	case ExecuteCronScripts:
+		timing.Stage = proto.Timing_CRON
+	}
+
+	err := r.trackCommandGoroutine(func() {
+		reportTimeout := 30 * time.Second
+		reportCtx, cancel := context.WithTimeout(ctx, reportTimeout)
+		defer cancel()
+
+		_, err := r.scriptCompleted(reportCtx, &proto.WorkspaceAgentScriptCompletedRequest{
+			Timing: timing,
+		})
+		if err != nil {
+			logger.Error(ctx, fmt.Sprintf("reporting script completed: %s", err.Error()))
+		}
+	})
+	if err != nil {
+		logger.Error(ctx, fmt.Sprintf("reporting script completed: track command goroutine: %s", err.Error()))
+	}
+}
+
 func (r *Runner) Close() error {
 	r.closeMutex.Lock()
 	defer r.closeMutex.Unlock()
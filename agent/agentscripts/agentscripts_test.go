@@ -2,6 +2,7 @@ package agentscripts_test
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -17,6 +18,7 @@ import (
 	"github.com/onchainengineering/hmi-wirtual/agent/agentscripts"
 	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
 	"github.com/onchainengineering/hmi-wirtual/agent/agenttest"
+	"github.com/onchainengineering/hmi-wirtual/agent/proto"
 	"github.com/onchainengineering/hmi-wirtual/testutil"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/agentsdk"
@@ -141,6 +143,180 @@ func TestScriptReportsTiming(t *testing.T) {
 	require.GreaterOrEqual(t, timing.End.AsTime(), timing.Start.AsTime())
 }
 
+func TestScriptDependencies(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("scripts use POSIX shell syntax")
+	}
+
+	t.Run("Order", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		runner := setup(t, nil)
+		aAPI := agenttest.NewFakeAgentAPI(t, testutil.Logger(t), nil, nil)
+		marker := filepath.Join(t.TempDir(), "database")
+		database := uuid.New()
+		err := runner.Init([]wirtualsdk.WorkspaceAgentScript{{
+			ID:          uuid.New(),
+			DisplayName: "migrate",
+			LogSourceID: uuid.New(),
+			Script:      "test -f " + marker,
+			DependsOn:   []uuid.UUID{database},
+		}, {
+			ID:          database,
+			DisplayName: "database",
+			LogSourceID: uuid.New(),
+			Script:      "sleep 0.1 && touch " + marker,
+		}}, aAPI.ScriptCompleted)
+		require.NoError(t, err)
+		require.NoError(t, runner.Execute(ctx, agentscripts.ExecuteAllScripts))
+		require.NoError(t, runner.Close())
+
+		timings := aAPI.GetTimings()
+		require.Len(t, timings, 2)
+		for _, timing := range timings {
+			require.Equal(t, proto.Timing_OK, timing.Status)
+		}
+	})
+
+	t.Run("FailureSkipsDependents", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		runner := setup(t, nil)
+		aAPI := agenttest.NewFakeAgentAPI(t, testutil.Logger(t), nil, nil)
+		database, migrate, seed, unrelated := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		err := runner.Init([]wirtualsdk.WorkspaceAgentScript{{
+			ID:          database,
+			DisplayName: "database",
+			LogSourceID: uuid.New(),
+			Script:      "exit 1",
+		}, {
+			ID:          migrate,
+			DisplayName: "migrate",
+			LogSourceID: uuid.New(),
+			Script:      "true",
+			DependsOn:   []uuid.UUID{database},
+		}, {
+			ID:          seed,
+			DisplayName: "seed",
+			LogSourceID: uuid.New(),
+			Script:      "true",
+			DependsOn:   []uuid.UUID{migrate},
+		}, {
+			ID:          unrelated,
+			DisplayName: "unrelated",
+			LogSourceID: uuid.New(),
+			Script:      "true",
+		}}, aAPI.ScriptCompleted)
+		require.NoError(t, err)
+		require.Error(t, runner.Execute(ctx, agentscripts.ExecuteAllScripts))
+		require.NoError(t, runner.Close())
+
+		statuses := make(map[uuid.UUID]proto.Timing_Status)
+		for _, timing := range aAPI.GetTimings() {
+			id, err := uuid.FromBytes(timing.ScriptId)
+			require.NoError(t, err)
+			statuses[id] = timing.Status
+		}
+		require.Equal(t, map[uuid.UUID]proto.Timing_Status{
+			database:  proto.Timing_EXIT_FAILURE,
+			migrate:   proto.Timing_SKIPPED,
+			seed:      proto.Timing_SKIPPED,
+			unrelated: proto.Timing_OK,
+		}, statuses)
+	})
+
+	t.Run("ReadyFile", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		runner := setup(t, nil)
+		aAPI := agenttest.NewFakeAgentAPI(t, testutil.Logger(t), nil, nil)
+		dir := t.TempDir()
+		ready := filepath.Join(dir, "ready")
+		server := uuid.New()
+		err := runner.Init([]wirtualsdk.WorkspaceAgentScript{{
+			ID:          server,
+			DisplayName: "server",
+			LogSourceID: uuid.New(),
+			// The script keeps running after it becomes ready, like a
+			// server would, until its dependent is done.
+			Script: fmt.Sprintf("touch %s && while [ ! -f %s ]; do sleep 0.05; done", ready, filepath.Join(dir, "done")),
+			Ready: &wirtualsdk.WorkspaceAgentScriptReadiness{
+				File:    ready,
+				Timeout: testutil.WaitShort,
+			},
+		}, {
+			ID:          uuid.New(),
+			DisplayName: "client",
+			LogSourceID: uuid.New(),
+			Script:      "touch " + filepath.Join(dir, "done"),
+			DependsOn:   []uuid.UUID{server},
+		}}, aAPI.ScriptCompleted)
+		require.NoError(t, err)
+		require.NoError(t, runner.Execute(ctx, agentscripts.ExecuteAllScripts))
+		require.NoError(t, runner.Close())
+		require.Len(t, aAPI.GetTimings(), 2)
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		runner := setup(t, nil)
+		aAPI := agenttest.NewFakeAgentAPI(t, testutil.Logger(t), nil, nil)
+		server := uuid.New()
+		err := runner.Init([]wirtualsdk.WorkspaceAgentScript{{
+			ID:          server,
+			DisplayName: "server",
+			LogSourceID: uuid.New(),
+			Script:      "true",
+			Ready: &wirtualsdk.WorkspaceAgentScriptReadiness{
+				File:    filepath.Join(t.TempDir(), "never"),
+				Timeout: 100 * time.Millisecond,
+			},
+		}, {
+			ID:          uuid.New(),
+			DisplayName: "client",
+			LogSourceID: uuid.New(),
+			Script:      "true",
+			DependsOn:   []uuid.UUID{server},
+		}}, aAPI.ScriptCompleted)
+		require.NoError(t, err)
+		require.ErrorIs(t, runner.Execute(ctx, agentscripts.ExecuteAllScripts), agentscripts.ErrNotReady)
+		require.NoError(t, runner.Close())
+
+		var skipped int
+		for _, timing := range aAPI.GetTimings() {
+			if timing.Status == proto.Timing_SKIPPED {
+				skipped++
+			}
+		}
+		require.Equal(t, 1, skipped)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		t.Parallel()
+
+		runner := setup(t, nil)
+		defer runner.Close()
+		aAPI := agenttest.NewFakeAgentAPI(t, testutil.Logger(t), nil, nil)
+		migrate, seed := uuid.New(), uuid.New()
+		err := runner.Init([]wirtualsdk.WorkspaceAgentScript{{
+			ID:          migrate,
+			DisplayName: "migrate",
+			DependsOn:   []uuid.UUID{seed},
+		}, {
+			ID:          seed,
+			DisplayName: "seed",
+			DependsOn:   []uuid.UUID{migrate},
+		}}, aAPI.ScriptCompleted)
+		require.ErrorContains(t, err, "script dependency cycle")
+	})
+}
+
 // TestCronClose exists because cron.Run() can happen after cron.Close().
 // If this happens, there used to be a deadlock.
 func TestCronClose(t *testing.T) {
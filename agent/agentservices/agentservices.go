@@ -0,0 +1,619 @@
+package agentservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"cdr.dev/slog"
+	"github.com/coder/quartz"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
+	"github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+const (
+	// DefaultRestartBackoff is the delay before the first restart of a
+	// service that does not specify one.
+	DefaultRestartBackoff = time.Second
+	// DefaultMaxRestartBackoff caps the exponential backoff between restarts
+	// of a service that does not specify a maximum.
+	DefaultMaxRestartBackoff = 5 * time.Minute
+
+	// stableRunDuration is how long a service must stay up before its
+	// backoff is reset to the initial value.
+	stableRunDuration = time.Minute
+	// logBufferSize is the number of bytes of recent output kept in memory
+	// for each service.
+	logBufferSize = 64 << 10
+)
+
+// ErrServiceNotFound is returned when a service name does not match any
+// service in the manifest.
+var ErrServiceNotFound = xerrors.New("service not found")
+
+// Options are a set of options for the supervisor.
+type Options struct {
+	LogDir    string
+	Logger    slog.Logger
+	SSHServer *agentssh.Server
+	// Clock is used for backoff and health check timers. Defaults to the
+	// real clock.
+	Clock quartz.Clock
+}
+
+// ReportStatusesFunc sends service statuses to wirtuald.
+type ReportStatusesFunc func(context.Context, *proto.BatchUpdateServiceStatusesRequest) (*proto.BatchUpdateServiceStatusesResponse, error)
+
+// Supervisor starts the services declared in the agent manifest and keeps
+// them running according to their restart policy, much like a minimal init
+// system.
+type Supervisor struct {
+	Options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	started  bool
+	closed   bool
+	services []*service
+	// updated is signaled whenever the state of a service changes.
+	updated chan struct{}
+}
+
+type service struct {
+	// def is the service definition from the manifest and is never
+	// modified.
+	def wirtualsdk.WorkspaceAgentService
+	// status holds the definition along with the live state. It is
+	// protected by the supervisor mutex.
+	status  wirtualsdk.WorkspaceAgentService
+	logs    *ringBuffer
+	restart chan struct{}
+}
+
+// New creates a supervisor. Services are not started until Start is called.
+func New(opts Options) *Supervisor {
+	if opts.Clock == nil {
+		opts.Clock = quartz.NewReal()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Supervisor{
+		Options: opts,
+		ctx:     ctx,
+		cancel:  cancel,
+		updated: make(chan struct{}, 1),
+	}
+}
+
+// Start launches and supervises the provided services. It may only be
+// called once, unless the services are stopped in between.
+func (s *Supervisor) Start(services []wirtualsdk.WorkspaceAgentService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return xerrors.New("start: supervisor closed")
+	}
+	if s.started {
+		return xerrors.New("start: already started")
+	}
+	s.started = true
+
+	s.Logger.Info(s.ctx, "starting agent services", slog.F("service_count", len(services)), slog.F("log_dir", s.LogDir))
+	for _, def := range services {
+		if def.RestartBackoff <= 0 {
+			def.RestartBackoff = DefaultRestartBackoff
+		}
+		if def.MaxRestartBackoff <= 0 {
+			def.MaxRestartBackoff = DefaultMaxRestartBackoff
+		}
+		if def.MaxRestartBackoff < def.RestartBackoff {
+			def.MaxRestartBackoff = def.RestartBackoff
+		}
+		def.State = wirtualsdk.WorkspaceAgentServicePending
+		def.RestartCount = 0
+		def.ExitCode = 0
+		def.StatusMessage = ""
+		def.StateChangedAt = nil
+
+		svc := &service{
+			def:     def,
+			status:  def,
+			logs:    newRingBuffer(logBufferSize),
+			restart: make(chan struct{}, 1),
+		}
+		s.services = append(s.services, svc)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.supervise(svc)
+		}()
+	}
+	return nil
+}
+
+// Services returns the services along with their current state, sorted by
+// name.
+func (s *Supervisor) Services() []wirtualsdk.WorkspaceAgentService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	services := make([]wirtualsdk.WorkspaceAgentService, 0, len(s.services))
+	for _, svc := range s.services {
+		services = append(services, svc.status)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	return services
+}
+
+// Restart stops the named service if it is running and starts it again
+// immediately, skipping any pending backoff. Services that have exited are
+// started again regardless of their restart policy.
+func (s *Supervisor) Restart(name string) error {
+	svc, err := s.service(name)
+	if err != nil {
+		return err
+	}
+	select {
+	case svc.restart <- struct{}{}:
+	default:
+		// A restart is already pending.
+	}
+	return nil
+}
+
+// Logs returns the most recent output of the named service.
+func (s *Supervisor) Logs(name string) ([]byte, error) {
+	svc, err := s.service(name)
+	if err != nil {
+		return nil, err
+	}
+	return svc.logs.Bytes(), nil
+}
+
+// ReportLoop sends the state of every service to wirtuald, and then again
+// whenever any of them change, until the context is canceled. All states
+// are sent on each call so that wirtuald catches up after a reconnect.
+func (s *Supervisor) ReportLoop(ctx context.Context, report ReportStatusesFunc) error {
+	for {
+		statuses := s.protoStatuses()
+		if len(statuses) > 0 {
+			_, err := report(ctx, &proto.BatchUpdateServiceStatusesRequest{Statuses: statuses})
+			if err != nil {
+				return xerrors.Errorf("batch update service statuses: %w", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.updated:
+		}
+	}
+}
+
+// Stop stops all services and waits for them to exit. Unlike Close, the
+// services may be started again afterwards, which the agent does when an
+// update fails to replace it.
+func (s *Supervisor) Stop() {
+	s.mu.Lock()
+	if s.closed || !s.started {
+		s.mu.Unlock()
+		return
+	}
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.started = false
+	s.services = nil
+}
+
+// Close stops all services and waits for them to exit.
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Supervisor) service(name string) (*service, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, svc := range s.services {
+		if svc.def.Name == name {
+			return svc, nil
+		}
+	}
+	return nil, xerrors.Errorf("%w: %q", ErrServiceNotFound, name)
+}
+
+func (s *Supervisor) protoStatuses() []*proto.ServiceStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var statuses []*proto.ServiceStatus
+	for _, svc := range s.services {
+		status, ok := protoFromStatus(svc.status)
+		if !ok {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// setState records a state transition and notifies the report loop.
+func (s *Supervisor) setState(svc *service, state wirtualsdk.WorkspaceAgentServiceState, exitCode int, message string) {
+	s.mu.Lock()
+	now := s.Clock.Now()
+	svc.status.State = state
+	// The exit code is kept while the service starts and runs again, so it
+	// still shows why the service was last restarted.
+	switch state {
+	case wirtualsdk.WorkspaceAgentServiceBackoff, wirtualsdk.WorkspaceAgentServiceExited, wirtualsdk.WorkspaceAgentServiceFailed:
+		svc.status.ExitCode = int32(exitCode)
+	}
+	svc.status.StatusMessage = message
+	svc.status.StateChangedAt = &now
+	s.mu.Unlock()
+
+	s.Logger.Debug(s.ctx, "service state changed",
+		slog.F("service", svc.def.Name),
+		slog.F("state", state),
+		slog.F("exit_code", exitCode),
+		slog.F("message", message),
+	)
+
+	select {
+	case s.updated <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Supervisor) incrementRestartCount(svc *service) {
+	s.mu.Lock()
+	svc.status.RestartCount++
+	s.mu.Unlock()
+}
+
+// supervise runs a service until the supervisor is closed, restarting it
+// according to its restart policy.
+func (s *Supervisor) supervise(svc *service) {
+	def := svc.def
+	logger := s.Logger.With(slog.F("service", def.Name))
+	backoff := def.RestartBackoff
+
+	for {
+		start := s.Clock.Now()
+		res := s.run(svc, logger)
+		if s.ctx.Err() != nil {
+			return
+		}
+		if res.restartRequested {
+			backoff = def.RestartBackoff
+			s.incrementRestartCount(svc)
+			continue
+		}
+		if s.Clock.Since(start) >= stableRunDuration {
+			backoff = def.RestartBackoff
+		}
+
+		if !res.shouldRestart(def.RestartPolicy) {
+			state := wirtualsdk.WorkspaceAgentServiceExited
+			if res.startErr != nil {
+				state = wirtualsdk.WorkspaceAgentServiceFailed
+			}
+			s.setState(svc, state, res.exitCode, res.message())
+			logger.Info(s.ctx, "service stopped, not restarting", slog.F("exit_code", res.exitCode), slog.F("restart_policy", def.RestartPolicy))
+
+			// Stay stopped until a manual restart is requested.
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-svc.restart:
+				backoff = def.RestartBackoff
+				s.incrementRestartCount(svc)
+				continue
+			}
+		}
+
+		s.setState(svc, wirtualsdk.WorkspaceAgentServiceBackoff, res.exitCode, res.message())
+		logger.Info(s.ctx, "service stopped, restarting after backoff", slog.F("exit_code", res.exitCode), slog.F("backoff", backoff))
+
+		timer := s.Clock.NewTimer(backoff, "backoff", def.Name)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-svc.restart:
+			timer.Stop()
+			backoff = def.RestartBackoff
+		case <-timer.C:
+			backoff *= 2
+			if backoff > def.MaxRestartBackoff {
+				backoff = def.MaxRestartBackoff
+			}
+		}
+		s.incrementRestartCount(svc)
+	}
+}
+
+type runResult struct {
+	exitCode         int
+	err              error
+	startErr         error
+	unhealthy        bool
+	restartRequested bool
+}
+
+func (r runResult) shouldRestart(policy wirtualsdk.WorkspaceAgentServiceRestartPolicy) bool {
+	switch policy {
+	case wirtualsdk.WorkspaceAgentServiceRestartAlways:
+		return true
+	case wirtualsdk.WorkspaceAgentServiceRestartOnFailure:
+		return r.startErr != nil || r.unhealthy || r.exitCode != 0
+	default:
+		return false
+	}
+}
+
+func (r runResult) message() string {
+	switch {
+	case r.startErr != nil:
+		return r.startErr.Error()
+	case r.unhealthy:
+		return "health check failed"
+	case r.err != nil:
+		return r.err.Error()
+	default:
+		return ""
+	}
+}
+
+// run starts the service process and waits for it to exit, fail its health
+// check, or be restarted.
+func (s *Supervisor) run(svc *service, logger slog.Logger) runResult {
+	def := svc.def
+	s.setState(svc, wirtualsdk.WorkspaceAgentServiceStarting, 0, "")
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	cmd, logFile, err := s.command(ctx, svc)
+	if err != nil {
+		logger.Warn(ctx, "create service command", slog.Error(err))
+		return runResult{exitCode: -1, startErr: err}
+	}
+	defer func() {
+		err := logFile.Close()
+		if err != nil {
+			logger.Warn(ctx, "close service log file", slog.Error(err))
+		}
+	}()
+
+	logger.Info(ctx, "starting service", slog.F("command", def.Command), slog.F("dir", cmd.Dir))
+	err = cmd.Start()
+	if err != nil {
+		logger.Warn(ctx, "start service", slog.Error(err))
+		return runResult{exitCode: -1, startErr: xerrors.Errorf("start command: %w", err)}
+	}
+	s.setState(svc, wirtualsdk.WorkspaceAgentServiceRunning, 0, "")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	unhealthy := make(chan struct{})
+	healthcheckDone := make(chan struct{})
+	if shouldHealthcheck(def.Healthcheck) {
+		go func() {
+			defer close(healthcheckDone)
+			s.healthcheck(ctx, svc, logger, unhealthy)
+		}()
+	} else {
+		close(healthcheckDone)
+	}
+	defer func() {
+		cancel()
+		<-healthcheckDone
+	}()
+
+	var res runResult
+	select {
+	case err = <-done:
+	case <-svc.restart:
+		res.restartRequested = true
+		logger.Info(ctx, "restarting service on request")
+		cancel()
+		err = <-done
+	case <-unhealthy:
+		res.unhealthy = true
+		logger.Warn(ctx, "service unhealthy, stopping")
+		cancel()
+		err = <-done
+	case <-s.ctx.Done():
+		err = <-done
+	}
+
+	res.err = err
+	if err != nil {
+		res.exitCode = 255 // Unknown status.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			res.exitCode = exitErr.ExitCode()
+		}
+	}
+	return res
+}
+
+// command builds the process for a service in the same environment as
+// agent scripts and SSH sessions.
+func (s *Supervisor) command(ctx context.Context, svc *service) (*exec.Cmd, *os.File, error) {
+	def := svc.def
+	env := make([]string, 0, len(def.Env))
+	for k, v := range def.Env {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(env)
+
+	cmdPty, err := s.SSHServer.CreateCommand(ctx, def.Command, env)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("create command: %w", err)
+	}
+	cmd := cmdPty.AsExec()
+	cmd.SysProcAttr = cmdSysProcAttr()
+	cmd.WaitDelay = 10 * time.Second
+	cmd.Cancel = cmdCancel(cmd)
+	if def.Directory != "" {
+		dir, err := expandPath(def.Directory)
+		if err != nil {
+			return nil, nil, xerrors.Errorf("expand directory: %w", err)
+		}
+		cmd.Dir = dir
+	}
+
+	logPath := filepath.Join(s.LogDir, fmt.Sprintf("coder-service-%s.log", def.Name))
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("open service log file %q: %w", logPath, err)
+	}
+	// Use the same writer for both streams so that exec only copies from
+	// a single pipe and their output stays in order.
+	output := io.MultiWriter(logFile, svc.logs)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd, logFile, nil
+}
+
+// healthcheck polls the health check URL of a running service. The service
+// is marked unhealthy once the failure threshold is reached. Unless the
+// service is never restarted, unhealthy is then closed so that it is
+// stopped and restarted.
+func (s *Supervisor) healthcheck(ctx context.Context, svc *service, logger slog.Logger, unhealthy chan<- struct{}) {
+	def := svc.def
+	interval := time.Duration(def.Healthcheck.Interval) * time.Second
+	failures := 0
+	isUnhealthy := false
+	_ = s.Clock.TickerFunc(ctx, interval, func() error {
+		reqCtx, reqCancel := context.WithTimeout(ctx, interval)
+		defer reqCancel()
+
+		err := func() error {
+			req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, def.Healthcheck.URL, nil)
+			if err != nil {
+				return err
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			// A successful health check is a non-5XX status code.
+			_ = res.Body.Close()
+			if res.StatusCode >= http.StatusInternalServerError {
+				return xerrors.Errorf("error status code: %d", res.StatusCode)
+			}
+			return nil
+		}()
+		if err == nil {
+			failures = 0
+			if isUnhealthy {
+				isUnhealthy = false
+				s.setState(svc, wirtualsdk.WorkspaceAgentServiceRunning, 0, "")
+			}
+			return nil
+		}
+
+		failures++
+		logger.Debug(ctx, "service health check failed", slog.F("failures", failures), slog.Error(err))
+		if failures < int(def.Healthcheck.Threshold) || isUnhealthy {
+			return nil
+		}
+		isUnhealthy = true
+		s.setState(svc, wirtualsdk.WorkspaceAgentServiceUnhealthy, 0, err.Error())
+		if def.RestartPolicy != wirtualsdk.WorkspaceAgentServiceRestartNever {
+			close(unhealthy)
+			return xerrors.New("unhealthy")
+		}
+		return nil
+	}, "healthcheck", def.Name).Wait()
+}
+
+func shouldHealthcheck(hc wirtualsdk.Healthcheck) bool {
+	return hc.URL != "" && hc.Interval > 0 && hc.Threshold > 0
+}
+
+func protoFromStatus(status wirtualsdk.WorkspaceAgentService) (*proto.ServiceStatus, bool) {
+	var state proto.ServiceStatus_State
+	switch status.State {
+	case wirtualsdk.WorkspaceAgentServiceStarting:
+		state = proto.ServiceStatus_STARTING
+	case wirtualsdk.WorkspaceAgentServiceRunning:
+		state = proto.ServiceStatus_RUNNING
+	case wirtualsdk.WorkspaceAgentServiceUnhealthy:
+		state = proto.ServiceStatus_UNHEALTHY
+	case wirtualsdk.WorkspaceAgentServiceBackoff:
+		state = proto.ServiceStatus_BACKOFF
+	case wirtualsdk.WorkspaceAgentServiceExited:
+		state = proto.ServiceStatus_EXITED
+	case wirtualsdk.WorkspaceAgentServiceFailed:
+		state = proto.ServiceStatus_FAILED
+	default:
+		// Pending services have nothing to report yet.
+		return nil, false
+	}
+	ps := &proto.ServiceStatus{
+		Id:           status.ID[:],
+		State:        state,
+		RestartCount: status.RestartCount,
+		ExitCode:     status.ExitCode,
+		Message:      status.StatusMessage,
+	}
+	if status.StateChangedAt != nil {
+		ps.ChangedAt = timestamppb.New(*status.StateChangedAt)
+	}
+	return ps, true
+}
+
+func expandPath(path string) (string, error) {
+	if path[0] == '~' {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			u, err := user.Current()
+			if err != nil {
+				return "", xerrors.Errorf("current user: %w", err)
+			}
+			homeDir = u.HomeDir
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+	return os.ExpandEnv(path), nil
+}
@@ -0,0 +1,201 @@
+package agentservices_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agentservices"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
+	"github.com/onchainengineering/hmi-wirtual/agent/agenttest"
+	"github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestRestartOnFailure(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell exit codes differ on Windows")
+	}
+	ctx := testutil.Context(t, testutil.WaitLong)
+	supervisor := setup(t)
+
+	err := supervisor.Start([]wirtualsdk.WorkspaceAgentService{{
+		ID:             uuid.New(),
+		Name:           "flaky",
+		Command:        "echo starting; exit 3",
+		RestartPolicy:  wirtualsdk.WorkspaceAgentServiceRestartOnFailure,
+		RestartBackoff: time.Millisecond,
+	}})
+	require.NoError(t, err)
+
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		services := supervisor.Services()
+		return len(services) == 1 && services[0].RestartCount >= 2
+	}, testutil.IntervalFast)
+
+	service := supervisor.Services()[0]
+	require.EqualValues(t, 3, service.ExitCode)
+	logs, err := supervisor.Logs("flaky")
+	require.NoError(t, err)
+	require.Contains(t, string(logs), "starting")
+}
+
+func TestRestartNever(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+	supervisor := setup(t)
+
+	err := supervisor.Start([]wirtualsdk.WorkspaceAgentService{{
+		ID:            uuid.New(),
+		Name:          "oneshot",
+		Command:       "echo hello",
+		RestartPolicy: wirtualsdk.WorkspaceAgentServiceRestartNever,
+	}})
+	require.NoError(t, err)
+
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		return supervisor.Services()[0].State == wirtualsdk.WorkspaceAgentServiceExited
+	}, testutil.IntervalFast)
+
+	service := supervisor.Services()[0]
+	require.EqualValues(t, 0, service.ExitCode)
+	require.EqualValues(t, 0, service.RestartCount)
+	logs, err := supervisor.Logs("oneshot")
+	require.NoError(t, err)
+	require.Contains(t, string(logs), "hello")
+
+	// A manual restart runs the service again even though it would not be
+	// restarted automatically.
+	require.NoError(t, supervisor.Restart("oneshot"))
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		service := supervisor.Services()[0]
+		return service.RestartCount == 1 && service.State == wirtualsdk.WorkspaceAgentServiceExited
+	}, testutil.IntervalFast)
+}
+
+func TestManualRestart(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep is not available on Windows")
+	}
+	ctx := testutil.Context(t, testutil.WaitLong)
+	supervisor := setup(t)
+
+	err := supervisor.Start([]wirtualsdk.WorkspaceAgentService{{
+		ID:            uuid.New(),
+		Name:          "sleeper",
+		Command:       "sleep 300",
+		RestartPolicy: wirtualsdk.WorkspaceAgentServiceRestartAlways,
+	}})
+	require.NoError(t, err)
+
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		return supervisor.Services()[0].State == wirtualsdk.WorkspaceAgentServiceRunning
+	}, testutil.IntervalFast)
+
+	require.NoError(t, supervisor.Restart("sleeper"))
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		service := supervisor.Services()[0]
+		return service.RestartCount == 1 && service.State == wirtualsdk.WorkspaceAgentServiceRunning
+	}, testutil.IntervalFast)
+}
+
+func TestStop(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep is not available on Windows")
+	}
+	ctx := testutil.Context(t, testutil.WaitLong)
+	supervisor := setup(t)
+
+	services := []wirtualsdk.WorkspaceAgentService{{
+		ID:            uuid.New(),
+		Name:          "sleeper",
+		Command:       "sleep 300",
+		RestartPolicy: wirtualsdk.WorkspaceAgentServiceRestartAlways,
+	}}
+	require.NoError(t, supervisor.Start(services))
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		return supervisor.Services()[0].State == wirtualsdk.WorkspaceAgentServiceRunning
+	}, testutil.IntervalFast)
+
+	// Stopped services can be started again.
+	supervisor.Stop()
+	require.Empty(t, supervisor.Services())
+	require.NoError(t, supervisor.Start(services))
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		return supervisor.Services()[0].State == wirtualsdk.WorkspaceAgentServiceRunning
+	}, testutil.IntervalFast)
+}
+
+func TestNotFound(t *testing.T) {
+	t.Parallel()
+	supervisor := setup(t)
+	require.NoError(t, supervisor.Start(nil))
+
+	require.ErrorIs(t, supervisor.Restart("missing"), agentservices.ErrServiceNotFound)
+	_, err := supervisor.Logs("missing")
+	require.ErrorIs(t, err, agentservices.ErrServiceNotFound)
+}
+
+func TestReportLoop(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+	supervisor := setup(t)
+	aAPI := agenttest.NewFakeAgentAPI(t, testutil.Logger(t), nil, nil)
+
+	id := uuid.New()
+	err := supervisor.Start([]wirtualsdk.WorkspaceAgentService{{
+		ID:            id,
+		Name:          "oneshot",
+		Command:       "echo hello",
+		RestartPolicy: wirtualsdk.WorkspaceAgentServiceRestartNever,
+	}})
+	require.NoError(t, err)
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- supervisor.ReportLoop(loopCtx, aAPI.BatchUpdateServiceStatuses)
+	}()
+
+	testutil.Eventually(ctx, t, func(context.Context) bool {
+		status, ok := aAPI.GetServiceStatuses()[id.String()]
+		return ok && status.State == proto.ServiceStatus_EXITED
+	}, testutil.IntervalFast)
+
+	cancel()
+	require.ErrorIs(t, testutil.RequireRecvCtx(ctx, t, done), context.Canceled)
+}
+
+func setup(t *testing.T) *agentservices.Supervisor {
+	t.Helper()
+	logger := testutil.Logger(t)
+	s, err := agentssh.NewServer(context.Background(), logger, prometheus.NewRegistry(), afero.NewMemMapFs(), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	supervisor := agentservices.New(agentservices.Options{
+		LogDir:    t.TempDir(),
+		Logger:    logger,
+		SSHServer: s,
+	})
+	t.Cleanup(func() {
+		_ = supervisor.Close()
+	})
+	return supervisor
+}
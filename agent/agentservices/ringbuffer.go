@@ -0,0 +1,36 @@
+package agentservices
+
+import "sync"
+
+// ringBuffer is an io.Writer that keeps the last size bytes written to it.
+type ringBuffer struct {
+	mu   sync.Mutex
+	buf  []byte
+	size int
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{size: size}
+}
+
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(p)
+	if n >= r.size {
+		r.buf = append(r.buf[:0], p[n-r.size:]...)
+		return n, nil
+	}
+	if overflow := len(r.buf) + n - r.size; overflow > 0 {
+		r.buf = append(r.buf[:0], r.buf[overflow:]...)
+	}
+	r.buf = append(r.buf, p...)
+	return n, nil
+}
+
+// Bytes returns a copy of the buffered output.
+func (r *ringBuffer) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]byte(nil), r.buf...)
+}
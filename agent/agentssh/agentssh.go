@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +36,7 @@ import (
 	"github.com/onchainengineering/hmi-wirtual/agent/usershell"
 	"github.com/onchainengineering/hmi-wirtual/pty"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/workspacesdk"
 )
 
 const (
@@ -50,6 +53,20 @@ const (
 	// MagicSessionTypeJetBrains is set in the SSH config by the JetBrains
 	// extension to identify itself.
 	MagicSessionTypeJetBrains = "jetbrains"
+	// MagicSessionIDEnvironmentVariable is set by `coder ssh` to a UUID
+	// identifying a resumable PTY session. Sessions with the same ID attach to
+	// the same shell, so a client that loses its connection can pick up where
+	// it left off. This is stripped from any commands being executed.
+	MagicSessionIDEnvironmentVariable = "WIRTUAL_SSH_SESSION_ID"
+	// MagicSessionOffsetEnvironmentVariable is set by `coder ssh` when it
+	// resumes a session to the number of output bytes it already received,
+	// so only the output it missed is replayed. This is stripped from any
+	// commands being executed.
+	MagicSessionOffsetEnvironmentVariable = "WIRTUAL_SSH_SESSION_OFFSET"
+	// MagicSessionContainerEnvironmentVariable is set by `coder ssh` to the
+	// name of a dev container the session runs in, rather than in the
+	// workspace. This is stripped from any commands being executed.
+	MagicSessionContainerEnvironmentVariable = "WIRTUAL_SSH_CONTAINER"
 	// MagicProcessCmdlineJetBrains is a string in a process's command line that
 	// uniquely identifies it as JetBrains software.
 	MagicProcessCmdlineJetBrains = "idea.vendor.name=JetBrains"
@@ -85,6 +102,16 @@ type Config struct {
 	X11DisplayOffset *int
 	// BlockFileTransfer restricts use of file transfer applications.
 	BlockFileTransfer bool
+	// ReconnectingPTY attaches conn to the reconnecting PTY with the given
+	// ID, starting cmd if it does not exist yet, and replays the output after
+	// offset. conn speaks the reconnecting PTY protocol and is closed when the
+	// SSH session ends. Once cmd exits, its error is returned as for
+	// exec.Cmd.Wait. If nil, resumable sessions are not supported and a
+	// regular PTY is used instead.
+	ReconnectingPTY func(id uuid.UUID, cmd *pty.Cmd, conn net.Conn, offset int64, height, width uint16) error
+	// ContainerCommand creates the command that runs script in the named
+	// dev container. If nil, sessions can't run in dev containers.
+	ContainerCommand func(ctx context.Context, container, script string, env []string, tty bool) (*pty.Cmd, error)
 }
 
 type Server struct {
@@ -388,6 +415,43 @@ func (s *Server) sessionStart(logger slog.Logger, session ssh.Session, extraEnv
 		magicType = strings.ToLower(strings.TrimPrefix(kv, MagicSessionTypeEnvironmentVariable+"="))
 		env = append(env[:index], env[index+1:]...)
 	}
+	var sessionID uuid.UUID
+	for index, kv := range env {
+		if !strings.HasPrefix(kv, MagicSessionIDEnvironmentVariable+"=") {
+			continue
+		}
+		id, err := uuid.Parse(strings.TrimPrefix(kv, MagicSessionIDEnvironmentVariable+"="))
+		if err != nil {
+			logger.Warn(ctx, "invalid ssh session id specified", slog.F("session_id", kv), slog.Error(err))
+		} else {
+			sessionID = id
+		}
+		env = append(env[:index], env[index+1:]...)
+		break
+	}
+	var sessionOffset int64
+	for index, kv := range env {
+		if !strings.HasPrefix(kv, MagicSessionOffsetEnvironmentVariable+"=") {
+			continue
+		}
+		offset, err := strconv.ParseInt(strings.TrimPrefix(kv, MagicSessionOffsetEnvironmentVariable+"="), 10, 64)
+		if err != nil {
+			logger.Warn(ctx, "invalid ssh session offset specified", slog.F("session_offset", kv), slog.Error(err))
+		} else {
+			sessionOffset = offset
+		}
+		env = append(env[:index], env[index+1:]...)
+		break
+	}
+	var container string
+	for index, kv := range env {
+		if !strings.HasPrefix(kv, MagicSessionContainerEnvironmentVariable+"=") {
+			continue
+		}
+		container = strings.TrimPrefix(kv, MagicSessionContainerEnvironmentVariable+"=")
+		env = append(env[:index], env[index+1:]...)
+		break
+	}
 
 	// Always force lowercase checking to be case-insensitive.
 	switch magicType {
@@ -407,7 +471,14 @@ func (s *Server) sessionStart(logger slog.Logger, session ssh.Session, extraEnv
 	magicTypeLabel := magicTypeMetricLabel(magicType)
 	sshPty, windowSize, isPty := session.Pty()
 
-	cmd, err := s.CreateCommand(ctx, session.RawCommand(), env)
+	var cmd *pty.Cmd
+	var err error
+	if container != "" {
+		logger = logger.With(slog.F("container", container))
+		cmd, err = s.CreateContainerCommand(agentexec.WithCgroupClass(ctx, agentexec.CgroupSSH), container, session.RawCommand(), env, isPty)
+	} else {
+		cmd, err = s.CreateCommand(agentexec.WithCgroupClass(ctx, agentexec.CgroupSSH), session.RawCommand(), env)
+	}
 	if err != nil {
 		ptyLabel := "no"
 		if isPty {
@@ -433,6 +504,9 @@ func (s *Server) sessionStart(logger slog.Logger, session ssh.Session, extraEnv
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", "SSH_AUTH_SOCK", l.Addr().String()))
 	}
 
+	if isPty && sessionID != uuid.Nil && s.config.ReconnectingPTY != nil {
+		return s.startReconnectingPTYSession(logger.With(slog.F("session_id", sessionID)), session, magicTypeLabel, sessionID, sessionOffset, cmd, sshPty, windowSize)
+	}
 	if isPty {
 		return s.startPTYSession(logger, session, magicTypeLabel, cmd, sshPty, windowSize)
 	}
@@ -610,6 +684,89 @@ func (s *Server) startPTYSession(logger slog.Logger, session ptySession, magicTy
 	return nil
 }
 
+// startReconnectingPTYSession attaches the session to a reconnecting PTY so
+// that the shell keeps running when the client disconnects, and a later
+// session with the same ID re-attaches to it. Input and window changes from
+// the session are translated to reconnecting PTY requests.
+func (s *Server) startReconnectingPTYSession(logger slog.Logger, session ptySession, magicTypeLabel string, id uuid.UUID, offset int64, cmd *pty.Cmd, sshPty ssh.Pty, windowSize <-chan ssh.Window) error {
+	s.metrics.sessionsTotal.WithLabelValues(magicTypeLabel, "yes").Add(1)
+
+	ctx := session.Context()
+	// Disable minimal PTY emulation set by gliderlabs/ssh (NL-to-CRNL).
+	session.DisablePTYEmulation()
+	cmd.Env = append(cmd.Env, fmt.Sprintf("TERM=%s", sshPty.Term))
+
+	local, remote := net.Pipe()
+	defer local.Close()
+
+	var mu sync.Mutex
+	encoder := json.NewEncoder(local)
+	send := func(req workspacesdk.ReconnectingPTYRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return encoder.Encode(req)
+	}
+	go func() {
+		// Closing the pipe detaches from the reconnecting PTY, which keeps
+		// running until it times out or another session attaches.
+		<-ctx.Done()
+		_ = local.Close()
+	}()
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := session.Read(buf)
+			if n > 0 {
+				if sendErr := send(workspacesdk.ReconnectingPTYRequest{Data: string(buf[:n])}); sendErr != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case win, ok := <-windowSize:
+				if !ok {
+					return
+				}
+				if err := send(workspacesdk.ReconnectingPTYRequest{
+					Height: uint16(win.Height),
+					Width:  uint16(win.Width),
+				}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer remote.Close()
+		errCh <- s.config.ReconnectingPTY(id, cmd, remote, offset, uint16(sshPty.Window.Height), uint16(sshPty.Window.Width))
+	}()
+
+	n, err := io.Copy(session, local)
+	logger.Debug(ctx, "copy reconnecting pty output done", slog.F("bytes", n), slog.Error(err))
+	_ = local.Close()
+	err = <-errCh
+	var exitErr *exec.ExitError
+	// The shell exiting with a non-zero exit code is reported to the client
+	// like for other sessions.
+	if err != nil && !xerrors.As(err, &exitErr) {
+		s.metrics.sessionErrors.WithLabelValues(magicTypeLabel, "yes", "reconnecting_pty").Add(1)
+	}
+	if err != nil {
+		return xerrors.Errorf("attach reconnecting pty: %w", err)
+	}
+	return nil
+}
+
 func (s *Server) handleSignal(logger slog.Logger, ssig ssh.Signal, signaler interface{ Signal(os.Signal) error }, magicTypeLabel string) {
 	ctx := context.Background()
 	sig := osSignalFrom(ssig)
@@ -668,6 +825,16 @@ func (s *Server) sftpHandler(logger slog.Logger, session ssh.Session) {
 	_ = session.Exit(1)
 }
 
+// CreateContainerCommand creates the command that runs script in the named
+// dev container. If the script is empty, it starts the login shell of the
+// user in the container.
+func (s *Server) CreateContainerCommand(ctx context.Context, container, script string, env []string, tty bool) (*pty.Cmd, error) {
+	if s.config.ContainerCommand == nil {
+		return nil, xerrors.New("dev containers are not supported by this agent")
+	}
+	return s.config.ContainerCommand(ctx, container, script, env, tty)
+}
+
 // CreateCommand processes raw command input with OpenSSH-like behavior.
 // If the script provided is empty, it will default to the users shell.
 // This injects environment variables specified by the user at launch too.
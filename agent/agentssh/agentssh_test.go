@@ -6,25 +6,31 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
+	"os/exec"
 	"runtime"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
 
 	"cdr.dev/slog/sloggers/slogtest"
 
 	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
+	"github.com/onchainengineering/hmi-wirtual/pty"
 	"github.com/onchainengineering/hmi-wirtual/pty/ptytest"
 	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/workspacesdk"
 )
 
 func TestMain(m *testing.M) {
@@ -296,6 +302,81 @@ func TestNewServer_Signal(t *testing.T) {
 	})
 }
 
+func TestNewServer_ResumableSession(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("PTY sessions are not tested on Windows")
+	}
+
+	ctx := context.Background()
+	logger := testutil.Logger(t)
+	sessionID := uuid.New()
+	attached := make(chan uuid.UUID, 1)
+	offsets := make(chan int64, 1)
+	s, err := agentssh.NewServer(ctx, logger, prometheus.NewRegistry(), afero.NewMemMapFs(), &agentssh.Config{
+		ReconnectingPTY: func(id uuid.UUID, cmd *pty.Cmd, conn net.Conn, offset int64, height, width uint16) error {
+			attached <- id
+			offsets <- offset
+			for _, kv := range cmd.Env {
+				if strings.HasPrefix(kv, agentssh.MagicSessionIDEnvironmentVariable+"=") ||
+					strings.HasPrefix(kv, agentssh.MagicSessionOffsetEnvironmentVariable+"=") {
+					return xerrors.New("session variable leaked into command environment")
+				}
+			}
+			var req workspacesdk.ReconnectingPTYRequest
+			if err := json.NewDecoder(conn).Decode(&req); err != nil {
+				return err
+			}
+			_, err := conn.Write([]byte("echo: " + req.Data))
+			if err != nil {
+				return err
+			}
+			// The exit status of the shell reaches the client.
+			return exec.Command("sh", "-c", "exit 3").Run()
+		},
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := s.Serve(ln)
+		assert.Error(t, err) // Server is closed.
+	}()
+
+	c := sshClient(t, ln.Addr().String())
+
+	var b bytes.Buffer
+	sess, err := c.NewSession()
+	require.NoError(t, err)
+	sess.Stdout = &b
+	sess.Stdin = strings.NewReader("hello")
+	err = sess.Setenv(agentssh.MagicSessionIDEnvironmentVariable, sessionID.String())
+	require.NoError(t, err)
+	err = sess.Setenv(agentssh.MagicSessionOffsetEnvironmentVariable, "42")
+	require.NoError(t, err)
+	err = sess.RequestPty("xterm", 80, 80, ssh.TerminalModes{})
+	require.NoError(t, err)
+	err = sess.Shell()
+	require.NoError(t, err)
+
+	err = sess.Wait()
+	var exitErr *ssh.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 3, exitErr.ExitStatus())
+	require.Equal(t, sessionID, <-attached)
+	require.EqualValues(t, 42, <-offsets)
+	require.Equal(t, "echo: hello", b.String())
+
+	err = s.Close()
+	require.NoError(t, err)
+	<-done
+}
+
 func sshClient(t *testing.T, addr string) *ssh.Client {
 	conn, err := net.Dial("tcp", addr)
 	require.NoError(t, err)
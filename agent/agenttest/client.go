@@ -96,8 +96,8 @@ func (c *Client) Close() {
 	c.derpMapOnce.Do(func() { close(c.derpMapUpdates) })
 }
 
-func (c *Client) ConnectRPC23(ctx context.Context) (
-	agentproto.DRPCAgentClient23, proto.DRPCTailnetClient23, error,
+func (c *Client) ConnectRPC24(ctx context.Context) (
+	agentproto.DRPCAgentClient24, proto.DRPCTailnetClient24, error,
 ) {
 	conn, lis := drpcsdk.MemTransportPipe()
 	c.LastWorkspaceAgent = func() {
@@ -131,6 +131,14 @@ func (c *Client) GetMetadata() map[string]agentsdk.Metadata {
 	return c.fakeAgentAPI.GetMetadata()
 }
 
+func (c *Client) GetServiceStatuses() map[string]*agentproto.ServiceStatus {
+	return c.fakeAgentAPI.GetServiceStatuses()
+}
+
+func (c *Client) GetDevcontainerStatuses() map[string]*agentproto.DevcontainerStatus {
+	return c.fakeAgentAPI.GetDevcontainerStatuses()
+}
+
 func (c *Client) GetStartupLogs() []agentsdk.Log {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -170,6 +178,9 @@ type FakeAgentAPI struct {
 	lifecycleStates []wirtualsdk.WorkspaceAgentLifecycle
 	metadata        map[string]agentsdk.Metadata
 	timings         []*agentproto.Timing
+	serviceStatuses map[string]*agentproto.ServiceStatus
+	logSources      map[uuid.UUID]*agentproto.LogSource
+	devcontainers   map[string]*agentproto.DevcontainerStatus
 
 	getAnnouncementBannersFunc func() ([]wirtualsdk.BannerConfig, error)
 }
@@ -315,6 +326,76 @@ func (f *FakeAgentAPI) ScriptCompleted(_ context.Context, req *agentproto.Worksp
 	return &agentproto.WorkspaceAgentScriptCompletedResponse{}, nil
 }
 
+func (f *FakeAgentAPI) BatchUpdateServiceStatuses(ctx context.Context, req *agentproto.BatchUpdateServiceStatusesRequest) (*agentproto.BatchUpdateServiceStatusesResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.serviceStatuses == nil {
+		f.serviceStatuses = make(map[string]*agentproto.ServiceStatus)
+	}
+	for _, status := range req.Statuses {
+		id, err := uuid.FromBytes(status.Id)
+		if err != nil {
+			return nil, xerrors.Errorf("parse service id: %w", err)
+		}
+		f.serviceStatuses[id.String()] = status
+		f.logger.Debug(ctx, "update service status", slog.F("id", id), slog.F("status", status))
+	}
+	return &agentproto.BatchUpdateServiceStatusesResponse{}, nil
+}
+
+// GetServiceStatuses returns the last status reported for each service,
+// keyed by service ID.
+func (f *FakeAgentAPI) GetServiceStatuses() map[string]*agentproto.ServiceStatus {
+	f.Lock()
+	defer f.Unlock()
+	return maps.Clone(f.serviceStatuses)
+}
+
+func (f *FakeAgentAPI) BatchUpdateDevcontainerStatuses(ctx context.Context, req *agentproto.BatchUpdateDevcontainerStatusesRequest) (*agentproto.BatchUpdateDevcontainerStatusesResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.devcontainers == nil {
+		f.devcontainers = make(map[string]*agentproto.DevcontainerStatus)
+	}
+	for _, status := range req.Statuses {
+		f.devcontainers[status.Name] = status
+		f.logger.Debug(ctx, "update dev container status", slog.F("name", status.Name), slog.F("status", status))
+	}
+	return &agentproto.BatchUpdateDevcontainerStatusesResponse{}, nil
+}
+
+// GetDevcontainerStatuses returns the last status reported for each dev
+// container, keyed by name.
+func (f *FakeAgentAPI) GetDevcontainerStatuses() map[string]*agentproto.DevcontainerStatus {
+	f.Lock()
+	defer f.Unlock()
+	return maps.Clone(f.devcontainers)
+}
+
+func (f *FakeAgentAPI) BatchCreateLogSources(ctx context.Context, req *agentproto.BatchCreateLogSourcesRequest) (*agentproto.BatchCreateLogSourcesResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.logSources == nil {
+		f.logSources = make(map[uuid.UUID]*agentproto.LogSource)
+	}
+	for _, source := range req.LogSources {
+		id, err := uuid.FromBytes(source.Id)
+		if err != nil {
+			return nil, xerrors.Errorf("parse log source id: %w", err)
+		}
+		f.logSources[id] = source
+		f.logger.Debug(ctx, "create log source", slog.F("id", id), slog.F("display_name", source.DisplayName))
+	}
+	return &agentproto.BatchCreateLogSourcesResponse{}, nil
+}
+
+// GetLogSources returns the log sources created by the agent, keyed by ID.
+func (f *FakeAgentAPI) GetLogSources() map[uuid.UUID]*agentproto.LogSource {
+	f.Lock()
+	defer f.Unlock()
+	return maps.Clone(f.logSources)
+}
+
 func NewFakeAgentAPI(t testing.TB, logger slog.Logger, manifest *agentproto.Manifest, statsCh chan *agentproto.Stats) *FakeAgentAPI {
 	return &FakeAgentAPI{
 		t:           t,
@@ -0,0 +1,167 @@
+// Package agentupdate downloads, verifies and installs new versions of the
+// agent binary.
+package agentupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/xerrors"
+
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/agentsdk"
+)
+
+// checkTimeout bounds running a downloaded binary to check its version.
+const checkTimeout = 30 * time.Second
+
+var (
+	// ErrInvalidSignature is returned when an update isn't signed by the
+	// trusted key.
+	ErrInvalidSignature = xerrors.New("update signature is invalid")
+	// ErrNotNewer is returned when an update isn't newer than the running
+	// agent, so a signed older binary can't be used to downgrade it.
+	ErrNotNewer = xerrors.New("update is not newer than the running agent")
+)
+
+// Manifest returns the message that the signature of an update signs. It
+// binds the checksum to the version and platform, so a signed binary can't be
+// offered as another version or for another platform.
+func Manifest(version, goos, goarch string, sha256 []byte) []byte {
+	return []byte(fmt.Sprintf("coder-agent-update\nversion=%s\nos=%s\narch=%s\nsha256=%s\n",
+		version, goos, goarch, hex.EncodeToString(sha256)))
+}
+
+// Verify checks that the manifest of the update is signed by the key, that
+// the update is built for the platform of the agent and that it's newer than
+// the current version.
+func Verify(update agentsdk.AgentUpdate, publicKey ed25519.PublicKey, current string) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return xerrors.Errorf("public key is %d bytes, expected %d", len(publicKey), ed25519.PublicKeySize)
+	}
+	if len(update.SHA256) != sha256.Size {
+		return ErrInvalidSignature
+	}
+	manifest := Manifest(update.Version, update.OS, update.Arch, update.SHA256)
+	if !ed25519.Verify(publicKey, manifest, update.Signature) {
+		return ErrInvalidSignature
+	}
+	if update.OS != runtime.GOOS || update.Arch != runtime.GOARCH {
+		return xerrors.Errorf("update is for %s/%s, the agent runs on %s/%s", update.OS, update.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+	if !semver.IsValid(update.Version) || !semver.IsValid(current) || semver.Compare(update.Version, current) <= 0 {
+		return xerrors.Errorf("update to %s from %s: %w", update.Version, current, ErrNotNewer)
+	}
+	return nil
+}
+
+// Download downloads the update into a new file in dir and returns its path.
+// The update is verified before downloading and the checksum after, the file
+// is removed if either doesn't match.
+func Download(ctx context.Context, client *http.Client, update agentsdk.AgentUpdate, publicKey ed25519.PublicKey, current, dir string) (_ string, err error) {
+	err = Verify(update, publicKey, current)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, update.URL, nil)
+	if err != nil {
+		return "", xerrors.Errorf("create request: %w", err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", xerrors.Errorf("download %s: %w", update.URL, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", xerrors.Errorf("download %s: unexpected status %s", update.URL, res.Status)
+	}
+
+	file, err := os.CreateTemp(dir, ".coder-agent-update-*")
+	if err != nil {
+		return "", xerrors.Errorf("create file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		if err != nil {
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, h), res.Body)
+	if err != nil {
+		return "", xerrors.Errorf("download %s: %w", update.URL, err)
+	}
+	if sum := h.Sum(nil); !bytes.Equal(sum, update.SHA256) {
+		return "", xerrors.Errorf("checksum mismatch: got %s, expected %s", hex.EncodeToString(sum), hex.EncodeToString(update.SHA256))
+	}
+	err = file.Close()
+	if err != nil {
+		return "", xerrors.Errorf("close file: %w", err)
+	}
+	//nolint:gosec // The binary must be executable.
+	err = os.Chmod(file.Name(), 0o755)
+	if err != nil {
+		return "", xerrors.Errorf("chmod: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// Check runs the downloaded binary and checks that it reports the version of
+// the update, so a binary that can't run on this machine is caught before the
+// agent replaces itself with it.
+func Check(ctx context.Context, path, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	//nolint:gosec // The binary was verified by Download.
+	out, err := exec.CommandContext(ctx, path, "version", "--output", "json").Output()
+	if err != nil {
+		return xerrors.Errorf("run %s: %w", path, err)
+	}
+	var info struct {
+		Version string `json:"version"`
+	}
+	err = json.Unmarshal(out, &info)
+	if err != nil {
+		return xerrors.Errorf("parse version of %s: %w", path, err)
+	}
+	if info.Version != version {
+		return xerrors.Errorf("binary reports version %q, expected %q", info.Version, version)
+	}
+	return nil
+}
+
+// Install replaces the executable with the downloaded binary and returns
+// the path of the agent. It's called by the new version once it's running,
+// so the executable is only replaced by a binary that is known to work. The
+// executable can only be replaced if the binary was downloaded to the same
+// directory, otherwise the downloaded binary stays where it is.
+func Install(downloaded, executable string) (string, error) {
+	if filepath.Dir(downloaded) != filepath.Dir(executable) {
+		return downloaded, nil
+	}
+	// A rename within a directory is atomic, and the running binary stays
+	// intact.
+	err := os.Rename(downloaded, executable)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return downloaded, nil
+		}
+		return "", xerrors.Errorf("replace executable: %w", err)
+	}
+	return executable, nil
+}
@@ -0,0 +1,198 @@
+package agentupdate_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agentupdate"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/agentsdk"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestDownload(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte("#!/bin/sh\necho updated\n")
+	sum := sha256.Sum256(binary)
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(binary)
+	}))
+	t.Cleanup(srv.Close)
+
+	update := func() agentsdk.AgentUpdate {
+		return signedUpdate(privateKey, srv.URL, "v2.1.0", runtime.GOOS, runtime.GOARCH, sum[:])
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path, err := agentupdate.Download(context.Background(), srv.Client(), update(), publicKey, "v2.0.0", dir)
+		require.NoError(t, err)
+		require.Equal(t, dir, filepath.Dir(path))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, binary, data)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NotZero(t, info.Mode().Perm()&0o100, "binary must be executable")
+	})
+
+	t.Run("UntrustedKey", func(t *testing.T) {
+		t.Parallel()
+		otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		dir := t.TempDir()
+		_, err = agentupdate.Download(context.Background(), srv.Client(), update(), otherKey, "v2.0.0", dir)
+		require.ErrorIs(t, err, agentupdate.ErrInvalidSignature)
+		requireEmptyDir(t, dir)
+	})
+
+	t.Run("TamperedManifest", func(t *testing.T) {
+		t.Parallel()
+		// A signed binary can't be offered as another version.
+		u := update()
+		u.Version = "v2.2.0"
+		dir := t.TempDir()
+		_, err := agentupdate.Download(context.Background(), srv.Client(), u, publicKey, "v2.0.0", dir)
+		require.ErrorIs(t, err, agentupdate.ErrInvalidSignature)
+		requireEmptyDir(t, dir)
+	})
+
+	t.Run("OtherPlatform", func(t *testing.T) {
+		t.Parallel()
+		u := signedUpdate(privateKey, srv.URL, "v2.1.0", runtime.GOOS, "other", sum[:])
+		dir := t.TempDir()
+		_, err := agentupdate.Download(context.Background(), srv.Client(), u, publicKey, "v2.0.0", dir)
+		require.ErrorContains(t, err, "the agent runs on")
+		requireEmptyDir(t, dir)
+	})
+
+	t.Run("Downgrade", func(t *testing.T) {
+		t.Parallel()
+		for _, current := range []string{"v2.1.0", "v2.2.0"} {
+			dir := t.TempDir()
+			_, err := agentupdate.Download(context.Background(), srv.Client(), update(), publicKey, current, dir)
+			require.ErrorIs(t, err, agentupdate.ErrNotNewer, current)
+			requireEmptyDir(t, dir)
+		}
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		t.Parallel()
+		// The signature is valid, but the binary served doesn't match it.
+		other := sha256.Sum256([]byte("something else"))
+		u := signedUpdate(privateKey, srv.URL, "v2.1.0", runtime.GOOS, runtime.GOARCH, other[:])
+		dir := t.TempDir()
+		_, err := agentupdate.Download(context.Background(), srv.Client(), u, publicKey, "v2.0.0", dir)
+		require.ErrorContains(t, err, "checksum mismatch")
+		requireEmptyDir(t, dir)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		u := update()
+		notFound := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(notFound.Close)
+		u.URL = notFound.URL
+		dir := t.TempDir()
+		_, err := agentupdate.Download(context.Background(), notFound.Client(), u, publicKey, "v2.0.0", dir)
+		require.ErrorContains(t, err, "unexpected status")
+		requireEmptyDir(t, dir)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test binary is a shell script")
+	}
+
+	path := filepath.Join(t.TempDir(), "coder")
+	//nolint:gosec // The script must be executable.
+	err := os.WriteFile(path, []byte("#!/bin/sh\necho '{\"version\":\"v2.1.0\"}'\n"), 0o755)
+	require.NoError(t, err)
+
+	err = agentupdate.Check(context.Background(), path, "v2.1.0")
+	require.NoError(t, err)
+	err = agentupdate.Check(context.Background(), path, "v2.2.0")
+	require.ErrorContains(t, err, "expected")
+
+	broken := filepath.Join(t.TempDir(), "coder")
+	//nolint:gosec // The binary must be executable.
+	err = os.WriteFile(broken, []byte("not a binary"), 0o755)
+	require.NoError(t, err)
+	err = agentupdate.Check(context.Background(), broken, "v2.1.0")
+	require.Error(t, err)
+}
+
+func TestInstall(t *testing.T) {
+	t.Parallel()
+
+	t.Run("SameDir", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		executable := filepath.Join(dir, "coder")
+		downloaded := filepath.Join(dir, ".coder-agent-update-1")
+		require.NoError(t, os.WriteFile(executable, []byte("old"), 0o600))
+		require.NoError(t, os.WriteFile(downloaded, []byte("new"), 0o600))
+
+		path, err := agentupdate.Install(downloaded, executable)
+		require.NoError(t, err)
+		require.Equal(t, executable, path)
+		data, err := os.ReadFile(executable)
+		require.NoError(t, err)
+		require.Equal(t, "new", string(data))
+		_, err = os.Stat(downloaded)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("OtherDir", func(t *testing.T) {
+		t.Parallel()
+		executable := filepath.Join(t.TempDir(), "coder")
+		downloaded := filepath.Join(t.TempDir(), ".coder-agent-update-1")
+		require.NoError(t, os.WriteFile(executable, []byte("old"), 0o600))
+		require.NoError(t, os.WriteFile(downloaded, []byte("new"), 0o600))
+
+		path, err := agentupdate.Install(downloaded, executable)
+		require.NoError(t, err)
+		require.Equal(t, downloaded, path)
+		data, err := os.ReadFile(executable)
+		require.NoError(t, err)
+		require.Equal(t, "old", string(data))
+	})
+}
+
+func signedUpdate(privateKey ed25519.PrivateKey, url, version, goos, goarch string, sum []byte) agentsdk.AgentUpdate {
+	return agentsdk.AgentUpdate{
+		Version:   version,
+		OS:        goos,
+		Arch:      goarch,
+		URL:       url,
+		SHA256:    sum,
+		Signature: ed25519.Sign(privateKey, agentupdate.Manifest(version, goos, goarch, sum)),
+	}
+}
+
+func requireEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
@@ -34,10 +34,23 @@ func (a *agent) apiHandler() http.Handler {
 	lp := &listeningPortsHandler{
 		ignorePorts:   cpy,
 		cacheDuration: cacheDuration,
+		protocols:     make(map[listeningPortKey]wirtualsdk.WorkspaceAgentPortShareProtocol),
+		probing:       make(map[listeningPortKey]struct{}),
 	}
 	promHandler := PrometheusMetricsHandler(a.prometheusRegistry, a.logger)
 	r.Get("/api/v0/listening-ports", lp.handler)
+	r.Get("/api/v0/listening-ports/watch", lp.watchHandler)
 	r.Get("/api/v0/netcheck", a.HandleNetcheck)
+	r.Get("/api/v0/processes", a.HandleProcesses)
+	r.Post("/api/v0/exec", a.HandleExec)
+	r.Get("/api/v0/services", a.HandleServices)
+	r.Post("/api/v0/services/{name}/restart", a.HandleServiceRestart)
+	r.Get("/api/v0/services/{name}/logs", a.HandleServiceLogs)
+	r.Get("/api/v0/devcontainers", a.HandleDevcontainers)
+	r.Post("/api/v0/devcontainers/{name}/start", a.HandleDevcontainerStart)
+	r.Post("/api/v0/devcontainers/{name}/stop", a.HandleDevcontainerStop)
+	r.Post("/api/v0/devcontainers/{name}/rebuild", a.HandleDevcontainerRebuild)
+	r.Get("/api/v0/devcontainers/{name}/logs", a.HandleDevcontainerLogs)
 	r.Get("/debug/logs", a.HandleHTTPDebugLogs)
 	r.Get("/debug/magicsock", a.HandleHTTPDebugMagicsock)
 	r.Get("/debug/magicsock/debug-logging/{state}", a.HandleHTTPMagicsockDebugLoggingState)
@@ -57,6 +70,25 @@ type listeningPortsHandler struct {
 	ports []wirtualsdk.WorkspaceAgentListeningPort
 	//nolint: unused  // used on some but not all platforms
 	mtime time.Time
+	// probeHosts holds the address each listening port is probed on, so
+	// listeners bound to a single address or family are reached. It's
+	// guarded by mut.
+	probeHosts map[uint16]string
+
+	// protocolsMu guards the probed protocols. It's separate from mut, so
+	// slow probes never block port scans.
+	protocolsMu sync.Mutex
+	// protocols caches the protocol probed for each listener, so ports are
+	// only probed when they open.
+	protocols map[listeningPortKey]wirtualsdk.WorkspaceAgentPortShareProtocol
+	// probing holds the listeners that are being probed.
+	probing map[listeningPortKey]struct{}
+}
+
+// listeningPortKey identifies a process listening on a port.
+type listeningPortKey struct {
+	port uint16
+	pid  int32
 }
 
 // handler returns a list of listening ports. This is tested by wirtuald's
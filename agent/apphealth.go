@@ -1,8 +1,16 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"maps"
+	"net"
 	"net/http"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,12 +26,16 @@ import (
 // PostWorkspaceAgentAppHealth updates the workspace app health.
 type PostWorkspaceAgentAppHealth func(context.Context, agentsdk.PostAppHealthsRequest) error
 
+// CreateAppHealthCommand creates the process of a health check that runs a
+// command, in the same environment as agent scripts.
+type CreateAppHealthCommand func(ctx context.Context, command string) (*exec.Cmd, error)
+
 // WorkspaceAppHealthReporter is a function that checks and reports the health of the workspace apps until the passed context is canceled.
 type WorkspaceAppHealthReporter func(ctx context.Context)
 
 // NewWorkspaceAppHealthReporter creates a WorkspaceAppHealthReporter that reports app health to wirtuald.
-func NewWorkspaceAppHealthReporter(logger slog.Logger, apps []wirtualsdk.WorkspaceApp, postWorkspaceAgentAppHealth PostWorkspaceAgentAppHealth) WorkspaceAppHealthReporter {
-	return NewAppHealthReporterWithClock(logger, apps, postWorkspaceAgentAppHealth, quartz.NewReal())
+func NewWorkspaceAppHealthReporter(logger slog.Logger, apps []wirtualsdk.WorkspaceApp, postWorkspaceAgentAppHealth PostWorkspaceAgentAppHealth, createCommand CreateAppHealthCommand) WorkspaceAppHealthReporter {
+	return NewAppHealthReporterWithClock(logger, apps, postWorkspaceAgentAppHealth, createCommand, quartz.NewReal())
 }
 
 // NewAppHealthReporterWithClock is only called directly by test code.  Product code should call
@@ -32,6 +44,7 @@ func NewAppHealthReporterWithClock(
 	logger slog.Logger,
 	apps []wirtualsdk.WorkspaceApp,
 	postWorkspaceAgentAppHealth PostWorkspaceAgentAppHealth,
+	createCommand CreateAppHealthCommand,
 	clk quartz.Clock,
 ) WorkspaceAppHealthReporter {
 	logger = logger.Named("apphealth")
@@ -63,11 +76,13 @@ func NewAppHealthReporterWithClock(
 		// run a ticker for each app health check.
 		var mu sync.RWMutex
 		failures := make(map[uuid.UUID]int, 0)
+		reasons := make(map[uuid.UUID]string, 0)
 		for _, nextApp := range apps {
 			if !shouldStartTicker(nextApp) {
 				continue
 			}
 			app := nextApp
+			checker := newAppHealthChecker(app.Healthcheck, createCommand)
 			go func() {
 				_ = clk.TickerFunc(ctx, time.Duration(app.Healthcheck.Interval)*time.Second, func() error {
 					// We time out at the healthcheck interval to prevent getting too backed up, but
@@ -86,23 +101,7 @@ func NewAppHealthReporterWithClock(
 						"timeout", app.Slug)
 					defer timeout.Stop()
 
-					err := func() error {
-						req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, app.Healthcheck.URL, nil)
-						if err != nil {
-							return err
-						}
-						res, err := http.DefaultClient.Do(req)
-						if err != nil {
-							return err
-						}
-						// successful healthcheck is a non-5XX status code
-						_ = res.Body.Close()
-						if res.StatusCode >= http.StatusInternalServerError {
-							return xerrors.Errorf("error status code: %d", res.StatusCode)
-						}
-
-						return nil
-					}()
+					err := checker.check(reqCtx)
 					if err != nil {
 						nowUnhealthy := false
 						mu.Lock()
@@ -114,6 +113,7 @@ func NewAppHealthReporterWithClock(
 							// set to unhealthy if we hit the failure threshold.
 							// we stop incrementing at the threshold to prevent the failure value from increasing forever.
 							health[app.ID] = wirtualsdk.WorkspaceAppHealthUnhealthy
+							reasons[app.ID] = err.Error()
 							nowUnhealthy = true
 						}
 						mu.Unlock()
@@ -126,6 +126,7 @@ func NewAppHealthReporterWithClock(
 						mu.Lock()
 						// we only need one successful health check to be considered healthy.
 						health[app.ID] = wirtualsdk.WorkspaceAppHealthHealthy
+						delete(reasons, app.ID)
 						failures[app.ID] = 0
 						mu.Unlock()
 						logger.Debug(ctx, "workspace app healthy", slog.F("id", app.ID.String()), slog.F("slug", app.Slug))
@@ -137,10 +138,11 @@ func NewAppHealthReporterWithClock(
 
 		mu.Lock()
 		lastHealth := copyHealth(health)
+		lastReasons := maps.Clone(reasons)
 		mu.Unlock()
 		reportTicker := clk.TickerFunc(ctx, time.Second, func() error {
 			mu.RLock()
-			changed := healthChanged(lastHealth, health)
+			changed := healthChanged(lastHealth, health) || !maps.Equal(lastReasons, reasons)
 			mu.RUnlock()
 			if !changed {
 				return nil
@@ -148,9 +150,11 @@ func NewAppHealthReporterWithClock(
 
 			mu.Lock()
 			lastHealth = copyHealth(health)
+			lastReasons = maps.Clone(reasons)
 			mu.Unlock()
 			err := postWorkspaceAgentAppHealth(ctx, agentsdk.PostAppHealthsRequest{
 				Healths: lastHealth,
+				Reasons: lastReasons,
 			})
 			if err != nil {
 				logger.Error(ctx, "failed to report workspace app health", slog.Error(err))
@@ -164,7 +168,130 @@ func NewAppHealthReporterWithClock(
 }
 
 func shouldStartTicker(app wirtualsdk.WorkspaceApp) bool {
-	return app.Healthcheck.URL != "" && app.Healthcheck.Interval > 0 && app.Healthcheck.Threshold > 0
+	return app.Healthcheck.Enabled() && app.Healthcheck.Interval > 0 && app.Healthcheck.Threshold > 0
+}
+
+const (
+	// appHealthBodyLimit is how much of an HTTP response body is matched
+	// against the body regex.
+	appHealthBodyLimit = 1 << 20
+	// appHealthOutputLimit is how much of the output of a failed command is
+	// included in the failure reason.
+	appHealthOutputLimit = 256
+)
+
+// appHealthChecker performs a single kind of health check for an app.
+type appHealthChecker struct {
+	healthcheck   wirtualsdk.Healthcheck
+	createCommand CreateAppHealthCommand
+	bodyRegex     *regexp.Regexp
+	// err is returned by every check when the health check is misconfigured.
+	err error
+}
+
+func newAppHealthChecker(healthcheck wirtualsdk.Healthcheck, createCommand CreateAppHealthCommand) *appHealthChecker {
+	c := &appHealthChecker{
+		healthcheck:   healthcheck,
+		createCommand: createCommand,
+	}
+	if healthcheck.BodyRegex != "" {
+		c.bodyRegex, c.err = regexp.Compile(healthcheck.BodyRegex)
+		if c.err != nil {
+			c.err = xerrors.Errorf("invalid body regex: %w", c.err)
+		}
+	}
+	return c
+}
+
+// check returns why the app is unhealthy, or nil if it is healthy.
+func (c *appHealthChecker) check(ctx context.Context) error {
+	if c.err != nil {
+		return c.err
+	}
+	switch {
+	case c.healthcheck.TCP != "":
+		return c.checkTCP(ctx)
+	case c.healthcheck.Command != "":
+		return c.checkCommand(ctx)
+	default:
+		return c.checkHTTP(ctx)
+	}
+}
+
+func (c *appHealthChecker) checkTCP(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.healthcheck.TCP)
+	if err != nil {
+		return err
+	}
+	_ = conn.Close()
+	return nil
+}
+
+func (c *appHealthChecker) checkCommand(ctx context.Context) error {
+	if c.createCommand == nil {
+		return xerrors.New("command health checks are not supported")
+	}
+	cmd, err := c.createCommand(ctx, c.healthcheck.Command)
+	if err != nil {
+		return xerrors.Errorf("create command: %w", err)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	if err != nil {
+		output := strings.TrimSpace(out.String())
+		if len(output) > appHealthOutputLimit {
+			output = output[len(output)-appHealthOutputLimit:]
+		}
+		// The reason is sent as a protobuf string, which must be valid UTF-8.
+		output = strings.ToValidUTF8(output, "")
+		if output != "" {
+			return xerrors.Errorf("%w: %s", err, output)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *appHealthChecker) checkHTTP(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.healthcheck.URL, nil)
+	if err != nil {
+		return err
+	}
+	for name, value := range c.healthcheck.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if len(c.healthcheck.ExpectedStatuses) > 0 {
+		if !slices.Contains(c.healthcheck.ExpectedStatuses, int32(res.StatusCode)) {
+			return xerrors.Errorf("unexpected status code: %d", res.StatusCode)
+		}
+	} else if res.StatusCode >= http.StatusInternalServerError {
+		// successful healthcheck is a non-5XX status code
+		return xerrors.Errorf("error status code: %d", res.StatusCode)
+	}
+
+	if c.bodyRegex != nil {
+		body, err := io.ReadAll(io.LimitReader(res.Body, appHealthBodyLimit))
+		if err != nil {
+			return xerrors.Errorf("read response body: %w", err)
+		}
+		if !c.bodyRegex.Match(body) {
+			return xerrors.Errorf("response body does not match %q", c.healthcheck.BodyRegex)
+		}
+	}
+	return nil
 }
 
 func healthChanged(old map[uuid.UUID]wirtualsdk.WorkspaceAppHealth, new map[uuid.UUID]wirtualsdk.WorkspaceAppHealth) bool {
@@ -2,8 +2,11 @@ package agent_test
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os/exec"
+	"runtime"
 	"slices"
 	"strings"
 	"testing"
@@ -159,6 +162,100 @@ func TestAppHealth_500(t *testing.T) {
 	require.Len(t, update.GetUpdates(), 1)
 	applyUpdate(t, apps, update)
 	require.Equal(t, wirtualsdk.WorkspaceAppHealthUnhealthy, apps[0].Health)
+	require.Equal(t, "error status code: 500", update.GetUpdates()[0].GetReason())
+}
+
+func TestAppHealth_HTTPOptions(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "starting"}`))
+	})
+
+	t.Run("Healthy", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		update := checkAppHealthTwice(ctx, t, wirtualsdk.Healthcheck{
+			ExpectedStatuses: []int32{http.StatusOK},
+			BodyRegex:        `"status":\s*"(starting|ok)"`,
+			Headers:          map[string]string{"Authorization": "Bearer token"},
+		}, handler)
+		require.Equal(t, proto.AppHealth_HEALTHY, update.GetHealth())
+		require.Empty(t, update.GetReason())
+	})
+
+	t.Run("UnexpectedStatus", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		update := checkAppHealthTwice(ctx, t, wirtualsdk.Healthcheck{
+			ExpectedStatuses: []int32{http.StatusOK},
+		}, handler)
+		require.Equal(t, proto.AppHealth_UNHEALTHY, update.GetHealth())
+		require.Equal(t, "unexpected status code: 401", update.GetReason())
+	})
+
+	t.Run("BodyMismatch", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		update := checkAppHealthTwice(ctx, t, wirtualsdk.Healthcheck{
+			BodyRegex: `"status":\s*"ok"`,
+			Headers:   map[string]string{"Authorization": "Bearer token"},
+		}, handler)
+		require.Equal(t, proto.AppHealth_UNHEALTHY, update.GetHealth())
+		require.Contains(t, update.GetReason(), "response body does not match")
+	})
+}
+
+func TestAppHealth_TCP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Healthy", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+		update := checkAppHealthTwice(ctx, t, wirtualsdk.Healthcheck{TCP: l.Addr().String()}, nil)
+		require.Equal(t, proto.AppHealth_HEALTHY, update.GetHealth())
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := l.Addr().String()
+		require.NoError(t, l.Close())
+		update := checkAppHealthTwice(ctx, t, wirtualsdk.Healthcheck{TCP: addr}, nil)
+		require.Equal(t, proto.AppHealth_UNHEALTHY, update.GetHealth())
+		require.Contains(t, update.GetReason(), addr)
+	})
+}
+
+func TestAppHealth_Command(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("health check commands use POSIX shell syntax in this test")
+	}
+
+	t.Run("Healthy", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		update := checkAppHealthTwice(ctx, t, wirtualsdk.Healthcheck{Command: "true"}, nil)
+		require.Equal(t, proto.AppHealth_HEALTHY, update.GetHealth())
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		update := checkAppHealthTwice(ctx, t, wirtualsdk.Healthcheck{Command: "echo database is down; exit 2"}, nil)
+		require.Equal(t, proto.AppHealth_UNHEALTHY, update.GetHealth())
+		require.Equal(t, "exit status 2: database is down", update.GetReason())
+	})
 }
 
 func TestAppHealth_Timeout(t *testing.T) {
@@ -229,6 +326,53 @@ func TestAppHealth_Timeout(t *testing.T) {
 	require.Equal(t, wirtualsdk.WorkspaceAppHealthUnhealthy, apps[0].Health)
 }
 
+// checkAppHealthTwice runs two health checks of a single app with a
+// threshold of one, enough for it to become healthy or unhealthy, and returns
+// the last update reported for it.
+func checkAppHealthTwice(ctx context.Context, t *testing.T, healthcheck wirtualsdk.Healthcheck, handler http.Handler) *proto.BatchUpdateAppHealthRequest_HealthUpdate {
+	t.Helper()
+	healthcheck.Interval = 1
+	healthcheck.Threshold = 1
+	apps := []wirtualsdk.WorkspaceApp{{
+		ID:          uuid.UUID{1},
+		Slug:        "app1",
+		Healthcheck: healthcheck,
+		Health:      wirtualsdk.WorkspaceAppHealthInitializing,
+	}}
+
+	mClock := quartz.NewMock(t)
+	healthcheckTrap := mClock.Trap().TickerFunc("healthcheck")
+	defer healthcheckTrap.Close()
+	reportTrap := mClock.Trap().TickerFunc("report")
+	defer reportTrap.Close()
+
+	fakeAPI, closeFn := setupAppReporter(ctx, t, apps, []http.Handler{handler}, mClock)
+	defer closeFn()
+	healthcheckTrap.MustWait(ctx).Release()
+	// advance the clock 1ms before the report ticker starts, so that it's not
+	// simultaneous with the checks.
+	mClock.Advance(time.Millisecond).MustWait(ctx)
+	reportTrap.MustWait(ctx).Release()
+
+	for i := 0; i < 2; i++ {
+		mClock.Advance(999 * time.Millisecond).MustWait(ctx) // check gets triggered
+		mClock.Advance(time.Millisecond).MustWait(ctx)       // report gets triggered
+	}
+
+	var update *proto.BatchUpdateAppHealthRequest
+	for {
+		select {
+		case update = <-fakeAPI.AppHealthCh():
+			continue
+		default:
+		}
+		break
+	}
+	require.NotNil(t, update, "no app health reported")
+	require.Len(t, update.GetUpdates(), 1)
+	return update.GetUpdates()[0]
+}
+
 func setupAppReporter(
 	ctx context.Context, t *testing.T,
 	apps []wirtualsdk.WorkspaceApp,
@@ -258,9 +402,12 @@ func setupAppReporter(
 	// post function.
 	fakeAAPI := agenttest.NewFakeAgentAPI(t, testutil.Logger(t), nil, nil)
 
+	createCommand := func(ctx context.Context, command string) (*exec.Cmd, error) {
+		return exec.CommandContext(ctx, "sh", "-c", command), nil
+	}
 	go agent.NewAppHealthReporterWithClock(
 		testutil.Logger(t),
-		apps, agentsdk.AppHealthPoster(fakeAAPI), clk,
+		apps, agentsdk.AppHealthPoster(fakeAAPI), createCommand, clk,
 	)(ctx)
 
 	return fakeAAPI, func() {
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"cdr.dev/slog"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentexec"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/agentsdk"
+)
+
+// cgroupOOMCheckInterval is how often the cgroups are checked for processes
+// killed by the kernel for exceeding their memory limit.
+const cgroupOOMCheckInterval = 10 * time.Second
+
+// reportCgroupOOMKills watches the cgroups of the agent for OOM kills, and
+// reports them as agent logs so they show up next to the output of the
+// scripts in the dashboard.
+func (a *agent) reportCgroupOOMKills(ctx context.Context) {
+	logger := a.logger.Named("cgroups")
+	kills := map[agentexec.CgroupClass]uint64{}
+	for _, class := range a.cgroups.Classes() {
+		count, err := a.cgroups.OOMKills(class)
+		if err != nil {
+			logger.Warn(ctx, "read cgroup oom kills", slog.F("class", class), slog.Error(err))
+		}
+		kills[class] = count
+	}
+
+	ticker := time.NewTicker(cgroupOOMCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, class := range a.cgroups.Classes() {
+			count, err := a.cgroups.OOMKills(class)
+			if err != nil {
+				logger.Debug(ctx, "read cgroup oom kills", slog.F("class", class), slog.Error(err))
+				continue
+			}
+			if count <= kills[class] {
+				continue
+			}
+			killed := count - kills[class]
+			kills[class] = count
+
+			limits, _ := a.cgroups.Limits(class)
+			message := fmt.Sprintf("%d %s process(es) were killed for running out of memory", killed, class)
+			if limits.MemoryMax > 0 {
+				message += fmt.Sprintf(", the limit is %s", humanize.IBytes(limits.MemoryMax))
+			}
+			logger.Warn(ctx, "cgroup oom kill", slog.F("class", class), slog.F("killed", killed))
+			a.logSender.Enqueue(agentsdk.ExternalLogSourceID, agentsdk.Log{
+				CreatedAt: dbtime.Now(),
+				Output:    message,
+				Level:     wirtualsdk.LogLevelWarn,
+			})
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agentcontainers"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// HandleDevcontainers returns the dev containers found by the agent along
+// with the state of their containers.
+func (a *agent) HandleDevcontainers(rw http.ResponseWriter, r *http.Request) {
+	devcontainers, err := a.devcontainers.Devcontainers(r.Context())
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Failed to list dev containers.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, wirtualsdk.WorkspaceAgentDevcontainersResponse{
+		Devcontainers: devcontainers,
+	})
+}
+
+func (a *agent) HandleDevcontainerStart(rw http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.devcontainers.StartDevcontainer(name)
+	if err != nil {
+		writeDevcontainerError(rw, r, name, err)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, wirtualsdk.Response{
+		Message: fmt.Sprintf("Starting dev container %q.", name),
+	})
+}
+
+func (a *agent) HandleDevcontainerStop(rw http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.devcontainers.StopDevcontainer(r.Context(), name)
+	if err != nil {
+		writeDevcontainerError(rw, r, name, err)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, wirtualsdk.Response{
+		Message: fmt.Sprintf("Stopped dev container %q.", name),
+	})
+}
+
+func (a *agent) HandleDevcontainerRebuild(rw http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.devcontainers.RebuildDevcontainer(name)
+	if err != nil {
+		writeDevcontainerError(rw, r, name, err)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, wirtualsdk.Response{
+		Message: fmt.Sprintf("Rebuilding dev container %q.", name),
+	})
+}
+
+func (a *agent) HandleDevcontainerLogs(rw http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	logs, err := a.devcontainers.Logs(name)
+	if err != nil {
+		writeDevcontainerError(rw, r, name, err)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(logs)
+}
+
+func writeDevcontainerError(rw http.ResponseWriter, r *http.Request, name string, err error) {
+	if errors.Is(err, agentcontainers.ErrNotFound) {
+		httpapi.Write(r.Context(), rw, http.StatusNotFound, wirtualsdk.Response{
+			Message: fmt.Sprintf("Dev container %q not found.", name),
+		})
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusInternalServerError, wirtualsdk.Response{
+		Message: fmt.Sprintf("Failed to access dev container %q.", name),
+		Detail:  err.Error(),
+	})
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
+	"github.com/onchainengineering/hmi-wirtual/tailnet"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/agentsdk"
+)
+
+// dnsRecordHTTPPort is the port served on the address of a DNS record, so
+// that clients can open http://<record>.<workspace>.coder without a port.
+const dnsRecordHTTPPort = 80
+
+// dnsRecordPrefixes returns the addresses of the DNS records, which the agent
+// serves in addition to its own.
+func dnsRecordPrefixes(agentID uuid.UUID, records []agentsdk.DNSRecord) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(records))
+	for _, record := range records {
+		prefixes = append(prefixes, netip.PrefixFrom(tailnet.DNSRecordAddr(agentID, record.Name), 128))
+	}
+	return prefixes
+}
+
+// serveDNSRecord forwards connections to port 80 of the record's address to
+// the record's port on localhost. The listener is closed with the network.
+func (a *agent) serveDNSRecord(ctx context.Context, network *tailnet.Conn, agentID uuid.UUID, record agentsdk.DNSRecord) error {
+	addr := netip.AddrPortFrom(tailnet.DNSRecordAddr(agentID, record.Name), dnsRecordHTTPPort)
+	ln, err := network.Listen("tcp", addr.String())
+	if err != nil {
+		return xerrors.Errorf("listen for dns record %q: %w", record.Name, err)
+	}
+	logger := a.logger.With(slog.F("dns_record", record.Name), slog.F("port", record.Port))
+	return a.trackGoroutine(func() {
+		defer ln.Close()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				logger.Debug(ctx, "stopped serving dns record", slog.Error(err))
+				return
+			}
+			go func() {
+				defer conn.Close()
+				var d net.Dialer
+				local, err := d.DialContext(ctx, "tcp", fmt.Sprintf("127.0.0.1:%d", record.Port))
+				if err != nil {
+					logger.Debug(ctx, "dial dns record port", slog.Error(err))
+					return
+				}
+				agentssh.Bicopy(ctx, conn, local)
+			}()
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"cdr.dev/slog"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentexec"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// execWaitDelay is how long the output of a killed command is still read,
+// in case it started processes that keep its output open.
+const execWaitDelay = 5 * time.Second
+
+// HandleExec runs a command and streams its output and exit code as
+// server-sent events. The command is run like commands over SSH, and it's
+// killed when the request is canceled.
+func (a *agent) HandleExec(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req wirtualsdk.WorkspaceAgentExecRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if len(req.Command) == 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: "A command is required.",
+		})
+		return
+	}
+	if req.TimeoutMillis < 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: "The timeout can't be negative.",
+		})
+		return
+	}
+
+	if req.TimeoutMillis > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMillis)*time.Millisecond)
+		defer cancel()
+	}
+
+	env := make([]string, 0, len(req.Env))
+	for k, v := range req.Env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	cmdPty, err := a.sshServer.CreateCommand(agentexec.WithCgroupClass(ctx, agentexec.CgroupSSH), shellquote.Join(req.Command...), env)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Failed to create command.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	cmd := cmdPty.AsExec()
+	if req.WorkingDirectory != "" {
+		dir := req.WorkingDirectory
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cmd.Dir, dir)
+		}
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+				Message: fmt.Sprintf("Working directory %q doesn't exist.", dir),
+			})
+			return
+		}
+		cmd.Dir = dir
+	}
+	cmd.WaitDelay = execWaitDelay
+
+	// The sender stops once the request context is done, which is canceled
+	// when the handler returns after the exit event.
+	sendCtx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	sendEvent, senderClosed, err := httpapi.ServerSentEventSender(rw, r.WithContext(sendCtx))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error setting up server-sent events.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Prevent handler from returning until the sender is closed.
+	defer func() {
+		cancel()
+		<-senderClosed
+	}()
+	send := func(event wirtualsdk.WorkspaceAgentExecEvent) error {
+		return sendEvent(sendCtx, wirtualsdk.ServerSentEvent{
+			Type: wirtualsdk.ServerSentEventTypeData,
+			Data: event,
+		})
+	}
+	cmd.Stdout = execOutputWriter{eventType: wirtualsdk.WorkspaceAgentExecEventStdout, send: send}
+	cmd.Stderr = execOutputWriter{eventType: wirtualsdk.WorkspaceAgentExecEventStderr, send: send}
+
+	logger := a.logger.With(slog.F("command", req.Command), slog.F("dir", cmd.Dir))
+	logger.Debug(ctx, "running command")
+	result := runExecCommand(ctx, cmd)
+	logger.Debug(ctx, "command exited", slog.F("exit_code", result.ExitCode), slog.F("timed_out", result.TimedOut), slog.F("error", result.Error))
+	_ = send(wirtualsdk.WorkspaceAgentExecEvent{
+		Type:   wirtualsdk.WorkspaceAgentExecEventExit,
+		Result: &result,
+	})
+}
+
+func runExecCommand(ctx context.Context, cmd *exec.Cmd) wirtualsdk.WorkspaceAgentExecResult {
+	err := cmd.Start()
+	if err != nil {
+		return wirtualsdk.WorkspaceAgentExecResult{
+			ExitCode: -1,
+			Error:    fmt.Sprintf("start command: %s", err),
+		}
+	}
+	err = cmd.Wait()
+	result := wirtualsdk.WorkspaceAgentExecResult{
+		ExitCode: -1,
+		TimedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+		result.Error = fmt.Sprintf("wait for command: %s", err)
+	}
+	return result
+}
+
+// execOutputWriter sends the output written to it as events.
+type execOutputWriter struct {
+	eventType wirtualsdk.WorkspaceAgentExecEventType
+	send      func(wirtualsdk.WorkspaceAgentExecEvent) error
+}
+
+func (w execOutputWriter) Write(p []byte) (int, error) {
+	err := w.send(wirtualsdk.WorkspaceAgentExecEvent{
+		Type:   w.eventType,
+		Output: p,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -20,6 +20,11 @@ type agentMetrics struct {
 	// took to run. This is reported once per agent.
 	startupScriptSeconds *prometheus.GaugeVec
 	currentConnections   *prometheus.GaugeVec
+	// topProcessCPUPercent and topProcessMemoryBytes describe the busiest
+	// processes in the workspace, when the agent is configured to report
+	// them.
+	topProcessCPUPercent  *prometheus.GaugeVec
+	topProcessMemoryBytes *prometheus.GaugeVec
 }
 
 func newAgentMetrics(registerer prometheus.Registerer) *agentMetrics {
@@ -54,11 +59,29 @@ func newAgentMetrics(registerer prometheus.Registerer) *agentMetrics {
 	}, []string{"connection_type"})
 	registerer.MustRegister(currentConnections)
 
+	topProcessCPUPercent := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "wirtuald",
+		Subsystem: "agentstats",
+		Name:      "top_process_cpu_percent",
+		Help:      "The percentage of one CPU core used by each of the busiest processes in the workspace.",
+	}, []string{"rank", "process"})
+	registerer.MustRegister(topProcessCPUPercent)
+
+	topProcessMemoryBytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "wirtuald",
+		Subsystem: "agentstats",
+		Name:      "top_process_memory_rss_bytes",
+		Help:      "The resident memory of each of the busiest processes in the workspace.",
+	}, []string{"rank", "process"})
+	registerer.MustRegister(topProcessMemoryBytes)
+
 	return &agentMetrics{
 		connectionsTotal:      connectionsTotal,
 		reconnectingPTYErrors: reconnectingPTYErrors,
 		startupScriptSeconds:  startupScriptSeconds,
 		currentConnections:    currentConnections,
+		topProcessCPUPercent:  topProcessCPUPercent,
+		topProcessMemoryBytes: topProcessMemoryBytes,
 	}
 }
 
@@ -0,0 +1,281 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/workspacesdk"
+)
+
+// listeningPortProbeTimeout bounds each attempt to guess the protocol served
+// on a listening port.
+const listeningPortProbeTimeout = 500 * time.Millisecond
+
+// watchHandler streams listening port events as server-sent events. The
+// first event reports every port that is already listening, later events
+// report ports as they open and close.
+//
+// With ?probe=true, the protocol of each port is probed in the background,
+// except for the ports listed in skip_probe. A port is reported again once
+// its protocol is known.
+func (lp *listeningPortsHandler) watchHandler(rw http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	probe := r.URL.Query().Get("probe") == "true"
+	skipProbe := map[uint16]struct{}{}
+	if raw := r.URL.Query().Get("skip_probe"); raw != "" {
+		for _, s := range strings.Split(raw, ",") {
+			port, err := strconv.ParseUint(s, 10, 16)
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+					Message: "Invalid skip_probe port.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			skipProbe[uint16(port)] = struct{}{}
+		}
+	}
+
+	sendEvent, senderClosed, err := httpapi.ServerSentEventSender(rw, r)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error setting up server-sent events.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Prevent handler from returning until the sender is closed.
+	defer func() {
+		cancel()
+		<-senderClosed
+	}()
+
+	ticker := time.NewTicker(lp.cacheDuration)
+	defer ticker.Stop()
+
+	var (
+		prev      []wirtualsdk.WorkspaceAgentListeningPort
+		firstScan = true
+	)
+	for {
+		ports, err := lp.getListeningPorts()
+		if err != nil {
+			_ = sendEvent(ctx, wirtualsdk.ServerSentEvent{
+				Type: wirtualsdk.ServerSentEventTypeError,
+				Data: wirtualsdk.Response{
+					Message: "Could not scan for listening ports.",
+					Detail:  err.Error(),
+				},
+			})
+			return
+		}
+		if probe {
+			ports = lp.probeProtocols(ports, skipProbe)
+		}
+		// Always send the first scan, even if it's empty, so the client
+		// knows the watch has started.
+		events := diffListeningPorts(prev, ports)
+		if firstScan || len(events) > 0 {
+			err = sendEvent(ctx, wirtualsdk.ServerSentEvent{
+				Type: wirtualsdk.ServerSentEventTypeData,
+				Data: events,
+			})
+			if err != nil {
+				return
+			}
+		}
+		prev = ports
+		firstScan = false
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-senderClosed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// diffListeningPorts returns the events that turn prev into cur, ordered by
+// port. A port whose process or protocol changed is reported as closed and
+// opened again.
+func diffListeningPorts(prev, cur []wirtualsdk.WorkspaceAgentListeningPort) []wirtualsdk.WorkspaceAgentListeningPortEvent {
+	prevByPort := make(map[uint16]wirtualsdk.WorkspaceAgentListeningPort, len(prev))
+	for _, port := range prev {
+		prevByPort[port.Port] = port
+	}
+	curByPort := make(map[uint16]wirtualsdk.WorkspaceAgentListeningPort, len(cur))
+	for _, port := range cur {
+		curByPort[port.Port] = port
+	}
+
+	events := []wirtualsdk.WorkspaceAgentListeningPortEvent{}
+	for _, port := range prev {
+		next, ok := curByPort[port.Port]
+		if ok && next == port {
+			continue
+		}
+		events = append(events, wirtualsdk.WorkspaceAgentListeningPortEvent{
+			Type: wirtualsdk.WorkspaceAgentListeningPortClosed,
+			Port: port,
+		})
+	}
+	for _, port := range cur {
+		old, ok := prevByPort[port.Port]
+		if ok && old == port {
+			continue
+		}
+		events = append(events, wirtualsdk.WorkspaceAgentListeningPortEvent{
+			Type: wirtualsdk.WorkspaceAgentListeningPortOpened,
+			Port: port,
+		})
+	}
+	// Stable, so a changed port is closed before it's opened again.
+	slices.SortStableFunc(events, func(a, b wirtualsdk.WorkspaceAgentListeningPortEvent) int {
+		return int(a.Port.Port) - int(b.Port.Port)
+	})
+	return events
+}
+
+// probeProtocols sets the protocols that have been probed on ports, and
+// starts probing the listeners that haven't been probed yet. Probes run in the
+// background, so their results are only reported by later scans. Well-known
+// ports of other protocols are never probed.
+func (lp *listeningPortsHandler) probeProtocols(ports []wirtualsdk.WorkspaceAgentListeningPort, skip map[uint16]struct{}) []wirtualsdk.WorkspaceAgentListeningPort {
+	lp.protocolsMu.Lock()
+	defer lp.protocolsMu.Unlock()
+
+	listening := make(map[listeningPortKey]struct{}, len(ports))
+	for i, port := range ports {
+		key := listeningPortKey{port: port.Port, pid: port.PID}
+		listening[key] = struct{}{}
+		if protocol, ok := lp.protocols[key]; ok {
+			ports[i].Protocol = protocol
+			continue
+		}
+		if _, ok := skip[port.Port]; ok {
+			continue
+		}
+		if _, ok := workspacesdk.AgentIgnoredListeningPorts[port.Port]; ok {
+			continue
+		}
+		if _, ok := lp.probing[key]; ok {
+			continue
+		}
+		lp.probing[key] = struct{}{}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*listeningPortProbeTimeout)
+			defer cancel()
+			protocol := probeListeningPortProtocol(ctx, lp.probeHost(key.port), key.port)
+
+			lp.protocolsMu.Lock()
+			defer lp.protocolsMu.Unlock()
+			delete(lp.probing, key)
+			lp.protocols[key] = protocol
+		}()
+	}
+	// Forget listeners that closed, so a new process on the port is probed.
+	for key := range lp.protocols {
+		if _, ok := listening[key]; !ok {
+			delete(lp.protocols, key)
+		}
+	}
+	return ports
+}
+
+// probeHost returns the host that the protocol of a listening port is probed
+// on, as recorded by the last port scan.
+func (lp *listeningPortsHandler) probeHost(port uint16) string {
+	lp.mut.Lock()
+	defer lp.mut.Unlock()
+	if host, ok := lp.probeHosts[port]; ok {
+		return host
+	}
+	return "127.0.0.1"
+}
+
+// listeningPortProbeHost returns the host to probe a listener bound to ip on.
+// Listeners on the unspecified address are probed on the loopback address of
+// the same family, others on the address they're bound to.
+func listeningPortProbeHost(ip net.IP) string {
+	switch {
+	case ip == nil, ip.Equal(net.IPv4zero):
+		return "127.0.0.1"
+	case ip.Equal(net.IPv6unspecified):
+		return "::1"
+	default:
+		return ip.String()
+	}
+}
+
+// probeListeningPortProtocol guesses the protocol served on a local port. It
+// returns an empty protocol if the port serves neither HTTPS nor HTTP, or
+// doesn't answer in time.
+func probeListeningPortProtocol(ctx context.Context, host string, port uint16) wirtualsdk.WorkspaceAgentPortShareProtocol {
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+
+	// TLS is tried first, since some HTTPS servers answer a plain HTTP
+	// request with an HTTP error.
+	if probeTLS(ctx, addr) {
+		return wirtualsdk.WorkspaceAgentPortShareProtocolHTTPS
+	}
+	if probeHTTP(ctx, addr) {
+		return wirtualsdk.WorkspaceAgentPortShareProtocolHTTP
+	}
+	return ""
+}
+
+func probeTLS(ctx context.Context, addr string) bool {
+	ctx, cancel := context.WithTimeout(ctx, listeningPortProbeTimeout)
+	defer cancel()
+
+	d := &tls.Dialer{
+		//nolint:gosec // Only the handshake is checked, nothing is sent.
+		Config: &tls.Config{InsecureSkipVerify: true},
+	}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+func probeHTTP(ctx context.Context, addr string) bool {
+	ctx, cancel := context.WithTimeout(ctx, listeningPortProbeTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	_, err = io.WriteString(conn, "HEAD / HTTP/1.0\r\nHost: localhost\r\n\r\n")
+	if err != nil {
+		return false
+	}
+	buf := make([]byte, len("HTTP/"))
+	_, err = io.ReadFull(conn, buf)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(buf, []byte("HTTP/"))
+}
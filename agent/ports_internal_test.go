@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestDiffListeningPorts(t *testing.T) {
+	t.Parallel()
+
+	web := wirtualsdk.WorkspaceAgentListeningPort{Network: "tcp", Port: 3000, PID: 10, ProcessName: "node"}
+	api := wirtualsdk.WorkspaceAgentListeningPort{Network: "tcp", Port: 8080, PID: 20, ProcessName: "server"}
+	restartedAPI := api
+	restartedAPI.PID = 21
+
+	t.Run("Initial", func(t *testing.T) {
+		t.Parallel()
+		events := diffListeningPorts(nil, []wirtualsdk.WorkspaceAgentListeningPort{api, web})
+		require.Equal(t, []wirtualsdk.WorkspaceAgentListeningPortEvent{
+			{Type: wirtualsdk.WorkspaceAgentListeningPortOpened, Port: web},
+			{Type: wirtualsdk.WorkspaceAgentListeningPortOpened, Port: api},
+		}, events)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		t.Parallel()
+		ports := []wirtualsdk.WorkspaceAgentListeningPort{web, api}
+		require.Empty(t, diffListeningPorts(ports, ports))
+	})
+
+	t.Run("OpenedAndClosed", func(t *testing.T) {
+		t.Parallel()
+		events := diffListeningPorts(
+			[]wirtualsdk.WorkspaceAgentListeningPort{web},
+			[]wirtualsdk.WorkspaceAgentListeningPort{api},
+		)
+		require.Equal(t, []wirtualsdk.WorkspaceAgentListeningPortEvent{
+			{Type: wirtualsdk.WorkspaceAgentListeningPortClosed, Port: web},
+			{Type: wirtualsdk.WorkspaceAgentListeningPortOpened, Port: api},
+		}, events)
+	})
+
+	t.Run("ProcessChanged", func(t *testing.T) {
+		t.Parallel()
+		events := diffListeningPorts(
+			[]wirtualsdk.WorkspaceAgentListeningPort{web, api},
+			[]wirtualsdk.WorkspaceAgentListeningPort{web, restartedAPI},
+		)
+		require.Equal(t, []wirtualsdk.WorkspaceAgentListeningPortEvent{
+			{Type: wirtualsdk.WorkspaceAgentListeningPortClosed, Port: api},
+			{Type: wirtualsdk.WorkspaceAgentListeningPortOpened, Port: restartedAPI},
+		}, events)
+	})
+}
+
+func TestProbeListeningPortProtocol(t *testing.T) {
+	t.Parallel()
+
+	serverPort := func(t *testing.T, addr string) uint16 {
+		t.Helper()
+		addrPort, err := netip.ParseAddrPort(addr)
+		require.NoError(t, err)
+		return addrPort.Port()
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		srv := httptest.NewServer(handler)
+		defer srv.Close()
+		port := serverPort(t, srv.Listener.Addr().String())
+		require.Equal(t, wirtualsdk.WorkspaceAgentPortShareProtocolHTTP, probeListeningPortProtocol(ctx, "127.0.0.1", port))
+	})
+
+	t.Run("IPv6", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		l, err := net.Listen("tcp", "[::1]:0")
+		if err != nil {
+			t.Skipf("IPv6 is unavailable: %s", err)
+		}
+		srv := httptest.NewUnstartedServer(handler)
+		_ = srv.Listener.Close()
+		srv.Listener = l
+		srv.Start()
+		defer srv.Close()
+		port := serverPort(t, l.Addr().String())
+		require.Equal(t, wirtualsdk.WorkspaceAgentPortShareProtocolHTTP, probeListeningPortProtocol(ctx, "::1", port))
+		require.Empty(t, probeListeningPortProtocol(ctx, "127.0.0.1", port), "the listener is IPv6 only")
+	})
+
+	t.Run("HTTPS", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		srv := httptest.NewTLSServer(handler)
+		defer srv.Close()
+		port := serverPort(t, srv.Listener.Addr().String())
+		require.Equal(t, wirtualsdk.WorkspaceAgentPortShareProtocolHTTPS, probeListeningPortProtocol(ctx, "127.0.0.1", port))
+	})
+
+	t.Run("Other", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+		go func() {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				// Speak first, like SSH does, and ignore the client.
+				_, _ = conn.Write([]byte("SSH-2.0-test\r\n"))
+				_ = conn.Close()
+			}
+		}()
+		port := serverPort(t, l.Addr().String())
+		require.Empty(t, probeListeningPortProtocol(ctx, "127.0.0.1", port))
+	})
+}
+
+func TestListeningPortProbeHost(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "127.0.0.1", listeningPortProbeHost(nil))
+	require.Equal(t, "127.0.0.1", listeningPortProbeHost(net.IPv4zero))
+	require.Equal(t, "127.0.0.1", listeningPortProbeHost(net.ParseIP("127.0.0.1")))
+	require.Equal(t, "::1", listeningPortProbeHost(net.IPv6unspecified))
+	require.Equal(t, "::1", listeningPortProbeHost(net.IPv6loopback))
+	require.Equal(t, "10.0.0.5", listeningPortProbeHost(net.ParseIP("10.0.0.5")))
+}
+
+func TestProbeProtocols(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	addrPort, err := netip.ParseAddrPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+
+	lp := &listeningPortsHandler{
+		protocols: make(map[listeningPortKey]wirtualsdk.WorkspaceAgentPortShareProtocol),
+		probing:   make(map[listeningPortKey]struct{}),
+	}
+	scan := func() []wirtualsdk.WorkspaceAgentListeningPort {
+		return []wirtualsdk.WorkspaceAgentListeningPort{
+			{Network: "tcp", Port: addrPort.Port()},
+			{Network: "tcp", Port: 5432},
+			{Network: "tcp", Port: 9999},
+		}
+	}
+	skip := map[uint16]struct{}{9999: {}}
+
+	// Probes run in the background, so the first scan has no protocols.
+	ports := lp.probeProtocols(scan(), skip)
+	require.Empty(t, ports[0].Protocol)
+
+	require.Eventually(t, func() bool {
+		ports = lp.probeProtocols(scan(), skip)
+		return ports[0].Protocol == wirtualsdk.WorkspaceAgentPortShareProtocolHTTP
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	// Well-known and skipped ports are never probed.
+	lp.protocolsMu.Lock()
+	defer lp.protocolsMu.Unlock()
+	require.Len(t, lp.protocols, 1)
+	require.Empty(t, lp.probing)
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// listeningPortProcess returns the command line and owner of a process. Both
+// are empty if the process can't be inspected, e.g. if it belongs to another
+// user.
+func listeningPortProcess(pid int) (cmdline string, username string) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err == nil {
+		// Arguments are separated and terminated by NUL bytes.
+		cmdline = string(bytes.ReplaceAll(bytes.TrimRight(data, "\x00"), []byte{0}, []byte{' '}))
+	}
+
+	info, err := os.Stat(fmt.Sprintf("/proc/%d", pid))
+	if err != nil {
+		return cmdline, ""
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return cmdline, ""
+	}
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	u, err := user.LookupId(uid)
+	if err != nil {
+		return cmdline, uid
+	}
+	return cmdline, u.Username
+}
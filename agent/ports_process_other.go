@@ -0,0 +1,11 @@
+//go:build !linux
+
+package agent
+
+// listeningPortProcess returns the command line and owner of a process. They
+// are only available on Linux.
+//
+//nolint:unused // used on some but not all platforms
+func listeningPortProcess(int) (cmdline string, username string) {
+	return "", ""
+}
@@ -23,15 +23,22 @@ func (lp *listeningPortsHandler) getListeningPorts() ([]wirtualsdk.WorkspaceAgen
 		return ports, nil
 	}
 
-	tabs, err := netstat.TCPSocks(func(s *netstat.SockTabEntry) bool {
+	listening := func(s *netstat.SockTabEntry) bool {
 		return s.State == netstat.Listen
-	})
+	}
+	tabs, err := netstat.TCPSocks(listening)
 	if err != nil {
 		return nil, xerrors.Errorf("scan listening ports: %w", err)
 	}
+	// IPv6 may be disabled, in which case only IPv4 listeners are reported.
+	tabs6, err := netstat.TCP6Socks(listening)
+	if err == nil {
+		tabs = append(tabs, tabs6...)
+	}
 
 	seen := make(map[uint16]struct{}, len(tabs))
 	ports := []wirtualsdk.WorkspaceAgentListeningPort{}
+	probeHosts := make(map[uint16]string, len(tabs))
 	for _, tab := range tabs {
 		if tab.LocalAddr == nil || tab.LocalAddr.Port < workspacesdk.AgentMinimumListeningPort {
 			continue
@@ -48,19 +55,22 @@ func (lp *listeningPortsHandler) getListeningPorts() ([]wirtualsdk.WorkspaceAgen
 			continue
 		}
 		seen[tab.LocalAddr.Port] = struct{}{}
+		probeHosts[tab.LocalAddr.Port] = listeningPortProbeHost(tab.LocalAddr.IP)
 
-		procName := ""
+		port := wirtualsdk.WorkspaceAgentListeningPort{
+			Network: "tcp",
+			Port:    tab.LocalAddr.Port,
+		}
 		if tab.Process != nil {
-			procName = tab.Process.Name
+			port.ProcessName = tab.Process.Name
+			port.PID = int32(tab.Process.Pid)
+			port.Cmdline, port.User = listeningPortProcess(tab.Process.Pid)
 		}
-		ports = append(ports, wirtualsdk.WorkspaceAgentListeningPort{
-			ProcessName: procName,
-			Network:     "tcp",
-			Port:        tab.LocalAddr.Port,
-		})
+		ports = append(ports, port)
 	}
 
 	lp.ports = ports
+	lp.probeHosts = probeHosts
 	lp.mtime = time.Now()
 
 	// copy
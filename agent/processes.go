@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cdr.dev/slog"
+	"github.com/onchainengineering/hmi-wirtual/cli/clistat"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// processSampleInterval is how long CPU usage is measured for when taking a
+// snapshot of the processes in the workspace.
+const processSampleInterval = 500 * time.Millisecond
+
+// HandleProcesses returns a snapshot of the busiest processes in the
+// workspace. The limit query parameter caps the number of processes.
+func (*agent) HandleProcesses(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+				Message: fmt.Sprintf("Invalid limit %q, it must be a non-negative integer.", raw),
+			})
+			return
+		}
+	}
+
+	procs, err := topProcesses(limit)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Could not collect processes.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	resp := wirtualsdk.WorkspaceAgentProcessesResponse{
+		CollectedAt: time.Now(),
+		Processes:   make([]wirtualsdk.WorkspaceAgentProcess, 0, len(procs)),
+	}
+	for _, proc := range procs {
+		resp.Processes = append(resp.Processes, wirtualsdk.WorkspaceAgentProcess{
+			PID:            proc.PID,
+			Name:           proc.Name,
+			Cmdline:        proc.Cmdline,
+			User:           proc.User,
+			CPUPercent:     proc.CPU,
+			MemoryRSSBytes: proc.RSS,
+			IOReadBytes:    proc.ReadBytes,
+			IOWriteBytes:   proc.WriteBytes,
+			Cgroup:         proc.Cgroup,
+		})
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// collectTopProcesses updates the top process metrics, which are sent to
+// wirtuald with the rest of the stats.
+func (a *agent) collectTopProcesses(ctx context.Context) {
+	a.metrics.topProcessCPUPercent.Reset()
+	a.metrics.topProcessMemoryBytes.Reset()
+
+	procs, err := topProcesses(a.reportTopProcesses)
+	if err != nil {
+		a.logger.Debug(ctx, "collect top processes", slog.Error(err))
+		return
+	}
+	for i, proc := range procs {
+		rank := strconv.Itoa(i + 1)
+		a.metrics.topProcessCPUPercent.WithLabelValues(rank, proc.Name).Set(proc.CPU)
+		a.metrics.topProcessMemoryBytes.WithLabelValues(rank, proc.Name).Set(float64(proc.RSS))
+	}
+}
+
+func topProcesses(limit int) ([]clistat.Process, error) {
+	st, err := clistat.New(clistat.WithSampleInterval(processSampleInterval))
+	if err != nil {
+		return nil, err
+	}
+	return st.Processes(limit)
+}
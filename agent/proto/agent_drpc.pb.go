@@ -48,6 +48,9 @@ type DRPCAgentClient interface {
 	BatchCreateLogs(ctx context.Context, in *BatchCreateLogsRequest) (*BatchCreateLogsResponse, error)
 	GetAnnouncementBanners(ctx context.Context, in *GetAnnouncementBannersRequest) (*GetAnnouncementBannersResponse, error)
 	ScriptCompleted(ctx context.Context, in *WorkspaceAgentScriptCompletedRequest) (*WorkspaceAgentScriptCompletedResponse, error)
+	BatchUpdateServiceStatuses(ctx context.Context, in *BatchUpdateServiceStatusesRequest) (*BatchUpdateServiceStatusesResponse, error)
+	BatchCreateLogSources(ctx context.Context, in *BatchCreateLogSourcesRequest) (*BatchCreateLogSourcesResponse, error)
+	BatchUpdateDevcontainerStatuses(ctx context.Context, in *BatchUpdateDevcontainerStatusesRequest) (*BatchUpdateDevcontainerStatusesResponse, error)
 }
 
 type drpcAgentClient struct {
@@ -150,6 +153,33 @@ func (c *drpcAgentClient) ScriptCompleted(ctx context.Context, in *WorkspaceAgen
 	return out, nil
 }
 
+func (c *drpcAgentClient) BatchUpdateServiceStatuses(ctx context.Context, in *BatchUpdateServiceStatusesRequest) (*BatchUpdateServiceStatusesResponse, error) {
+	out := new(BatchUpdateServiceStatusesResponse)
+	err := c.cc.Invoke(ctx, "/coder.agent.v2.Agent/BatchUpdateServiceStatuses", drpcEncoding_File_agent_proto_agent_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAgentClient) BatchCreateLogSources(ctx context.Context, in *BatchCreateLogSourcesRequest) (*BatchCreateLogSourcesResponse, error) {
+	out := new(BatchCreateLogSourcesResponse)
+	err := c.cc.Invoke(ctx, "/coder.agent.v2.Agent/BatchCreateLogSources", drpcEncoding_File_agent_proto_agent_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcAgentClient) BatchUpdateDevcontainerStatuses(ctx context.Context, in *BatchUpdateDevcontainerStatusesRequest) (*BatchUpdateDevcontainerStatusesResponse, error) {
+	out := new(BatchUpdateDevcontainerStatusesResponse)
+	err := c.cc.Invoke(ctx, "/coder.agent.v2.Agent/BatchUpdateDevcontainerStatuses", drpcEncoding_File_agent_proto_agent_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 type DRPCAgentServer interface {
 	GetManifest(context.Context, *GetManifestRequest) (*Manifest, error)
 	GetServiceBanner(context.Context, *GetServiceBannerRequest) (*ServiceBanner, error)
@@ -161,6 +191,9 @@ type DRPCAgentServer interface {
 	BatchCreateLogs(context.Context, *BatchCreateLogsRequest) (*BatchCreateLogsResponse, error)
 	GetAnnouncementBanners(context.Context, *GetAnnouncementBannersRequest) (*GetAnnouncementBannersResponse, error)
 	ScriptCompleted(context.Context, *WorkspaceAgentScriptCompletedRequest) (*WorkspaceAgentScriptCompletedResponse, error)
+	BatchUpdateServiceStatuses(context.Context, *BatchUpdateServiceStatusesRequest) (*BatchUpdateServiceStatusesResponse, error)
+	BatchCreateLogSources(context.Context, *BatchCreateLogSourcesRequest) (*BatchCreateLogSourcesResponse, error)
+	BatchUpdateDevcontainerStatuses(context.Context, *BatchUpdateDevcontainerStatusesRequest) (*BatchUpdateDevcontainerStatusesResponse, error)
 }
 
 type DRPCAgentUnimplementedServer struct{}
@@ -205,9 +238,21 @@ func (s *DRPCAgentUnimplementedServer) ScriptCompleted(context.Context, *Workspa
 	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
 }
 
+func (s *DRPCAgentUnimplementedServer) BatchUpdateServiceStatuses(context.Context, *BatchUpdateServiceStatusesRequest) (*BatchUpdateServiceStatusesResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAgentUnimplementedServer) BatchCreateLogSources(context.Context, *BatchCreateLogSourcesRequest) (*BatchCreateLogSourcesResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCAgentUnimplementedServer) BatchUpdateDevcontainerStatuses(context.Context, *BatchUpdateDevcontainerStatusesRequest) (*BatchUpdateDevcontainerStatusesResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
 type DRPCAgentDescription struct{}
 
-func (DRPCAgentDescription) NumMethods() int { return 10 }
+func (DRPCAgentDescription) NumMethods() int { return 13 }
 
 func (DRPCAgentDescription) Method(n int) (string, drpc.Encoding, drpc.Receiver, interface{}, bool) {
 	switch n {
@@ -301,6 +346,33 @@ func (DRPCAgentDescription) Method(n int) (string, drpc.Encoding, drpc.Receiver,
 						in1.(*WorkspaceAgentScriptCompletedRequest),
 					)
 			}, DRPCAgentServer.ScriptCompleted, true
+	case 10:
+		return "/coder.agent.v2.Agent/BatchUpdateServiceStatuses", drpcEncoding_File_agent_proto_agent_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAgentServer).
+					BatchUpdateServiceStatuses(
+						ctx,
+						in1.(*BatchUpdateServiceStatusesRequest),
+					)
+			}, DRPCAgentServer.BatchUpdateServiceStatuses, true
+	case 11:
+		return "/coder.agent.v2.Agent/BatchCreateLogSources", drpcEncoding_File_agent_proto_agent_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAgentServer).
+					BatchCreateLogSources(
+						ctx,
+						in1.(*BatchCreateLogSourcesRequest),
+					)
+			}, DRPCAgentServer.BatchCreateLogSources, true
+	case 12:
+		return "/coder.agent.v2.Agent/BatchUpdateDevcontainerStatuses", drpcEncoding_File_agent_proto_agent_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCAgentServer).
+					BatchUpdateDevcontainerStatuses(
+						ctx,
+						in1.(*BatchUpdateDevcontainerStatusesRequest),
+					)
+			}, DRPCAgentServer.BatchUpdateDevcontainerStatuses, true
 	default:
 		return "", nil, nil, nil, false
 	}
@@ -469,3 +541,51 @@ func (x *drpcAgent_ScriptCompletedStream) SendAndClose(m *WorkspaceAgentScriptCo
 	}
 	return x.CloseSend()
 }
+
+type DRPCAgent_BatchUpdateServiceStatusesStream interface {
+	drpc.Stream
+	SendAndClose(*BatchUpdateServiceStatusesResponse) error
+}
+
+type drpcAgent_BatchUpdateServiceStatusesStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAgent_BatchUpdateServiceStatusesStream) SendAndClose(m *BatchUpdateServiceStatusesResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_agent_proto_agent_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAgent_BatchCreateLogSourcesStream interface {
+	drpc.Stream
+	SendAndClose(*BatchCreateLogSourcesResponse) error
+}
+
+type drpcAgent_BatchCreateLogSourcesStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAgent_BatchCreateLogSourcesStream) SendAndClose(m *BatchCreateLogSourcesResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_agent_proto_agent_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCAgent_BatchUpdateDevcontainerStatusesStream interface {
+	drpc.Stream
+	SendAndClose(*BatchUpdateDevcontainerStatusesResponse) error
+}
+
+type drpcAgent_BatchUpdateDevcontainerStatusesStream struct {
+	drpc.Stream
+}
+
+func (x *drpcAgent_BatchUpdateDevcontainerStatusesStream) SendAndClose(m *BatchUpdateDevcontainerStatusesResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_agent_proto_agent_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
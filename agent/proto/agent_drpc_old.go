@@ -40,3 +40,12 @@ type DRPCAgentClient23 interface {
 	DRPCAgentClient22
 	ScriptCompleted(ctx context.Context, in *WorkspaceAgentScriptCompletedRequest) (*WorkspaceAgentScriptCompletedResponse, error)
 }
+
+// DRPCAgentClient24 is the Agent API at v2.4. It adds the BatchUpdateServiceStatuses,
+// BatchCreateLogSources and BatchUpdateDevcontainerStatuses RPCs.
+type DRPCAgentClient24 interface {
+	DRPCAgentClient23
+	BatchUpdateServiceStatuses(ctx context.Context, in *BatchUpdateServiceStatusesRequest) (*BatchUpdateServiceStatusesResponse, error)
+	BatchCreateLogSources(ctx context.Context, in *BatchCreateLogSourcesRequest) (*BatchCreateLogSourcesResponse, error)
+	BatchUpdateDevcontainerStatuses(ctx context.Context, in *BatchUpdateDevcontainerStatusesRequest) (*BatchUpdateDevcontainerStatusesResponse, error)
+}
@@ -177,13 +177,20 @@ func (rpty *bufferedReconnectingPTY) lifecycle(ctx context.Context, logger slog.
 }
 
 func (rpty *bufferedReconnectingPTY) Attach(ctx context.Context, connID string, conn net.Conn, height, width uint16, logger slog.Logger) error {
-	logger.Info(ctx, "attach to reconnecting pty")
+	return rpty.attach(ctx, connID, conn, 0, height, width, logger)
+}
+
+// attach is like Attach, but only replays the output after the first offset
+// bytes written by the process, which the client already received through an
+// earlier connection.
+func (rpty *bufferedReconnectingPTY) attach(ctx context.Context, connID string, conn net.Conn, offset int64, height, width uint16, logger slog.Logger) error {
+	logger.Info(ctx, "attach to reconnecting pty", slog.F("offset", offset))
 
 	// This will kill the heartbeat once we hit EOF or an error.
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	err := rpty.doAttach(connID, conn)
+	err := rpty.doAttach(connID, conn, offset)
 	if err != nil {
 		return err
 	}
@@ -217,14 +224,20 @@ func (rpty *bufferedReconnectingPTY) Attach(ctx context.Context, connID string,
 // doAttach adds the connection to the map and replays the buffer.  It exists
 // separately only for convenience to defer the mutex unlock which is not
 // possible in Attach since it blocks.
-func (rpty *bufferedReconnectingPTY) doAttach(connID string, conn net.Conn) error {
+func (rpty *bufferedReconnectingPTY) doAttach(connID string, conn net.Conn, offset int64) error {
 	rpty.state.cond.L.Lock()
 	defer rpty.state.cond.L.Unlock()
 
 	// Write any previously stored data for the TTY.  Since the command might be
 	// short-lived and have already exited, make sure we always at least output
-	// the buffer before returning, mostly just so tests pass.
-	prevBuf := slices.Clone(rpty.circularBuffer.Bytes())
+	// the buffer before returning, mostly just so tests pass.  If part of the
+	// output after offset was already dropped from the buffer, all of it is
+	// written.
+	prevBuf := rpty.circularBuffer.Bytes()
+	if missed := rpty.circularBuffer.TotalWritten() - offset; missed >= 0 && missed < int64(len(prevBuf)) {
+		prevBuf = prevBuf[int64(len(prevBuf))-missed:]
+	}
+	prevBuf = slices.Clone(prevBuf)
 	_, err := conn.Write(prevBuf)
 	if err != nil {
 		rpty.metrics.WithLabelValues("write").Add(1)
@@ -236,6 +249,18 @@ func (rpty *bufferedReconnectingPTY) doAttach(connID string, conn net.Conn) erro
 	return nil
 }
 
+// exitError returns the error of the process as for exec.Cmd.Wait once the
+// reconnecting pty is closing, or nil while it's still running.
+func (rpty *bufferedReconnectingPTY) exitError() error {
+	rpty.state.cond.L.Lock()
+	closing := rpty.state.state >= StateClosing
+	rpty.state.cond.L.Unlock()
+	if !closing || rpty.process == nil {
+		return nil
+	}
+	return rpty.process.Wait()
+}
+
 func (rpty *bufferedReconnectingPTY) Wait() {
 	_, _ = rpty.state.waitForState(StateClosing)
 }
@@ -31,6 +31,9 @@ type Options struct {
 	Timeout time.Duration
 	// Metrics tracks various error counters.
 	Metrics *prometheus.CounterVec
+	// BackendType is "screen" or "buffered". If empty, screen is used when
+	// it's installed.
+	BackendType string
 }
 
 // ReconnectingPTY is a pty that can be reconnected within a timeout and to
@@ -63,11 +66,14 @@ func New(ctx context.Context, cmd *pty.Cmd, options *Options, logger slog.Logger
 	// runs) but in CI screen often incorrectly claims the session name does not
 	// exist even though screen -list shows it.  For now, restrict screen to
 	// Linux.
-	backendType := "buffered"
-	if runtime.GOOS == "linux" {
-		_, err := exec.LookPath("screen")
-		if err == nil {
-			backendType = "screen"
+	backendType := options.BackendType
+	if backendType == "" {
+		backendType = "buffered"
+		if runtime.GOOS == "linux" {
+			_, err := exec.LookPath("screen")
+			if err == nil {
+				backendType = "screen"
+			}
 		}
 	}
 
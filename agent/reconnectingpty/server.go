@@ -14,7 +14,9 @@ import (
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentexec"
 	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
+	"github.com/onchainengineering/hmi-wirtual/pty"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/workspacesdk"
 )
 
@@ -83,6 +85,17 @@ func (s *Server) ConnCount() int64 {
 	return s.connCount.Load()
 }
 
+// PTYCount returns the number of reconnecting PTYs that are alive, including
+// the ones no connection is attached to.
+func (s *Server) PTYCount() int {
+	count := 0
+	s.reconnectingPTYs.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+	return count
+}
+
 func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Conn) (retErr error) {
 	defer conn.Close()
 	s.connectionsTotal.Add(1)
@@ -117,6 +130,9 @@ func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Co
 
 	connectionID := uuid.NewString()
 	connLogger := logger.With(slog.F("message_id", msg.ID), slog.F("connection_id", connectionID))
+	if msg.Container != "" {
+		connLogger = connLogger.With(slog.F("container", msg.Container))
+	}
 	connLogger.Debug(ctx, "starting handler")
 
 	defer func() {
@@ -131,20 +147,65 @@ func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Co
 		connLogger.Info(ctx, "reconnecting pty connection closed")
 	}()
 
+	_, err = s.attach(ctx, connLogger, connectionID, conn, msg.ID, 0, msg.Height, msg.Width, "", func() (*pty.Cmd, error) {
+		ctx := agentexec.WithCgroupClass(ctx, agentexec.CgroupReconnectingPTY)
+		if msg.Container != "" {
+			return s.commandCreator.CreateContainerCommand(ctx, msg.Container, msg.Command, nil, true)
+		}
+		// Empty command will default to the users shell!
+		return s.commandCreator.CreateCommand(ctx, msg.Command, nil)
+	})
+	return err
+}
+
+// AttachSSH attaches conn to the reconnecting PTY with the given ID, starting
+// cmd in a new reconnecting PTY if none exists yet. conn must speak the same
+// protocol as connections to the reconnecting PTY port. It allows SSH sessions
+// to re-attach to a shell that survived the client losing its connection.
+// The reconnecting PTY is closed when ctx ends, so it should outlive the SSH
+// session.
+//
+// Only the output after the first offset bytes is replayed, as the client
+// already received those through an earlier session. Once the command exits,
+// its error is returned as for exec.Cmd.Wait, so the client gets its exit
+// status.
+func (s *Server) AttachSSH(ctx context.Context, id uuid.UUID, cmd *pty.Cmd, conn net.Conn, offset int64, height, width uint16) error {
+	s.connectionsTotal.Add(1)
+	s.connCount.Add(1)
+	defer s.connCount.Add(-1)
+
+	connectionID := uuid.NewString()
+	connLogger := s.logger.With(slog.F("message_id", id), slog.F("connection_id", connectionID), slog.F("ssh", true))
+	connLogger.Debug(ctx, "starting ssh handler")
+	// Screen redraws the screen on attach instead of replaying the output,
+	// and hides the exit status of the command.
+	rpty, err := s.attach(ctx, connLogger, connectionID, conn, id, offset, height, width, "buffered", func() (*pty.Cmd, error) {
+		return cmd, nil
+	})
+	if err != nil {
+		return err
+	}
+	if buffered, ok := rpty.(*bufferedReconnectingPTY); ok {
+		return buffered.exitError()
+	}
+	return nil
+}
+
+func (s *Server) attach(ctx context.Context, connLogger slog.Logger, connectionID string, conn net.Conn, id uuid.UUID, offset int64, height, width uint16, backendType string, createCommand func() (*pty.Cmd, error)) (_ ReconnectingPTY, retErr error) {
 	var rpty ReconnectingPTY
 	sendConnected := make(chan ReconnectingPTY, 1)
 	// On store, reserve this ID to prevent multiple concurrent new connections.
-	waitReady, ok := s.reconnectingPTYs.LoadOrStore(msg.ID, sendConnected)
+	waitReady, ok := s.reconnectingPTYs.LoadOrStore(id, sendConnected)
 	if ok {
 		close(sendConnected) // Unused.
 		connLogger.Debug(ctx, "connecting to existing reconnecting pty")
 		c, ok := waitReady.(chan ReconnectingPTY)
 		if !ok {
-			return xerrors.Errorf("found invalid type in reconnecting pty map: %T", waitReady)
+			return nil, xerrors.Errorf("found invalid type in reconnecting pty map: %T", waitReady)
 		}
 		rpty, ok = <-c
 		if !ok || rpty == nil {
-			return xerrors.Errorf("reconnecting pty closed before connection")
+			return nil, xerrors.Errorf("reconnecting pty closed before connection")
 		}
 		c <- rpty // Put it back for the next reconnect.
 	} else {
@@ -153,22 +214,22 @@ func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Co
 		connected := false
 		defer func() {
 			if !connected && retErr != nil {
-				s.reconnectingPTYs.Delete(msg.ID)
+				s.reconnectingPTYs.Delete(id)
 				close(sendConnected)
 			}
 		}()
 
-		// Empty command will default to the users shell!
-		cmd, err := s.commandCreator.CreateCommand(ctx, msg.Command, nil)
+		cmd, err := createCommand()
 		if err != nil {
 			s.errorsTotal.WithLabelValues("create_command").Add(1)
-			return xerrors.Errorf("create command: %w", err)
+			return nil, xerrors.Errorf("create command: %w", err)
 		}
 
 		rpty = New(ctx, cmd, &Options{
-			Timeout: s.timeout,
-			Metrics: s.errorsTotal,
-		}, logger.With(slog.F("message_id", msg.ID)))
+			Timeout:     s.timeout,
+			Metrics:     s.errorsTotal,
+			BackendType: backendType,
+		}, s.logger.With(slog.F("message_id", id)))
 
 		done := make(chan struct{})
 		go func() {
@@ -181,11 +242,14 @@ func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Co
 
 		go func() {
 			rpty.Wait()
-			s.reconnectingPTYs.Delete(msg.ID)
+			s.reconnectingPTYs.Delete(id)
 		}()
 
 		connected = true
 		sendConnected <- rpty
 	}
-	return rpty.Attach(ctx, connectionID, conn, msg.Height, msg.Width, connLogger)
+	if buffered, ok := rpty.(*bufferedReconnectingPTY); ok {
+		return rpty, buffered.attach(ctx, connectionID, conn, offset, height, width, connLogger)
+	}
+	return rpty, rpty.Attach(ctx, connectionID, conn, height, width, connLogger)
 }
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agentservices"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// HandleServices returns the services supervised by the agent along with
+// their live state.
+func (a *agent) HandleServices(rw http.ResponseWriter, r *http.Request) {
+	httpapi.Write(r.Context(), rw, http.StatusOK, a.serviceSupervisor.Services())
+}
+
+func (a *agent) HandleServiceRestart(rw http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.serviceSupervisor.Restart(name)
+	if err != nil {
+		writeServiceError(rw, r, name, err)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, wirtualsdk.Response{
+		Message: fmt.Sprintf("Restarting service %q.", name),
+	})
+}
+
+func (a *agent) HandleServiceLogs(rw http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	logs, err := a.serviceSupervisor.Logs(name)
+	if err != nil {
+		writeServiceError(rw, r, name, err)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(logs)
+}
+
+func writeServiceError(rw http.ResponseWriter, r *http.Request, name string, err error) {
+	if errors.Is(err, agentservices.ErrServiceNotFound) {
+		httpapi.Write(r.Context(), rw, http.StatusNotFound, wirtualsdk.Response{
+			Message: fmt.Sprintf("Service %q not found.", name),
+		})
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusInternalServerError, wirtualsdk.Response{
+		Message: fmt.Sprintf("Failed to access service %q.", name),
+		Detail:  err.Error(),
+	})
+}
@@ -0,0 +1,265 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentupdate"
+	"github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/buildinfo"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/agentsdk"
+)
+
+// The environment variables an agent that updated itself passes to the new
+// version, so it carries on where the old version left off instead of
+// starting the workspace again, and installs itself in place of the old
+// version.
+const (
+	EnvAgentUpdatedFrom      = "WIRTUAL_AGENT_UPDATED_FROM"
+	EnvAgentUpdatedLifecycle = "WIRTUAL_AGENT_UPDATED_LIFECYCLE"
+	EnvAgentUpdateInstall    = "WIRTUAL_AGENT_UPDATE_INSTALL"
+)
+
+// updateIdleCheckInterval is how often the agent checks whether the
+// workspace is idle while an update waits to be installed.
+const updateIdleCheckInterval = 10 * time.Second
+
+// takeUpdatedEnv returns the version and lifecycle state passed by the agent
+// that updated itself to this version, and the executable to install this
+// version as. It removes them from the environment so they aren't inherited
+// by the processes the agent starts.
+func takeUpdatedEnv() (string, wirtualsdk.WorkspaceAgentLifecycle, string) {
+	version := os.Getenv(EnvAgentUpdatedFrom)
+	lifecycle := wirtualsdk.WorkspaceAgentLifecycle(os.Getenv(EnvAgentUpdatedLifecycle))
+	install := os.Getenv(EnvAgentUpdateInstall)
+	_ = os.Unsetenv(EnvAgentUpdatedFrom)
+	_ = os.Unsetenv(EnvAgentUpdatedLifecycle)
+	_ = os.Unsetenv(EnvAgentUpdateInstall)
+	if version == "" {
+		return "", "", ""
+	}
+	if !slices.Contains([]wirtualsdk.WorkspaceAgentLifecycle{
+		wirtualsdk.WorkspaceAgentLifecycleReady,
+		wirtualsdk.WorkspaceAgentLifecycleStartError,
+		wirtualsdk.WorkspaceAgentLifecycleStartTimeout,
+	}, lifecycle) {
+		lifecycle = wirtualsdk.WorkspaceAgentLifecycleReady
+	}
+	return version, lifecycle, install
+}
+
+// resumeAfterUpdate starts an agent that replaced an older version of itself.
+// The older version already ran the startup scripts, so only the cron
+// scripts and the services, which were stopped for the update, are started.
+func (a *agent) resumeAfterUpdate(ctx context.Context, aAPI proto.DRPCAgentClient24, manifest agentsdk.Manifest) error {
+	a.logger.Info(ctx, "agent was updated",
+		slog.F("from", a.updatedFrom),
+		slog.F("to", buildinfo.Version()),
+	)
+	a.logSender.Enqueue(agentsdk.ExternalLogSourceID, agentsdk.Log{
+		CreatedAt: dbtime.Now(),
+		Output:    fmt.Sprintf("Agent updated from %s to %s", a.updatedFrom, buildinfo.Version()),
+		Level:     wirtualsdk.LogLevelInfo,
+	})
+	if a.updateInstall != "" {
+		a.installSelf(ctx)
+	}
+
+	err := a.scriptRunner.Init(manifest.Scripts, aAPI.ScriptCompleted)
+	if err != nil {
+		return xerrors.Errorf("init script runner: %w", err)
+	}
+	a.setLifecycle(a.updatedLifecycle)
+	a.scriptRunner.StartCron()
+	err = a.serviceSupervisor.Start(manifest.Services)
+	if err != nil {
+		a.logger.Warn(ctx, "start services failed", slog.Error(err))
+	}
+	// Containers kept running through the update, so they're only picked
+	// up again.
+	err = a.devcontainers.Start(manifest.Directory)
+	if err != nil {
+		a.logger.Warn(ctx, "start dev containers failed", slog.Error(err))
+	}
+	return nil
+}
+
+// installSelf replaces the executable of the version that updated to this
+// one. The new version only installs itself once it's running, so a binary
+// that fails to run never replaces a working agent.
+func (a *agent) installSelf(ctx context.Context) {
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err == nil {
+		_, err = agentupdate.Install(executable, a.updateInstall)
+	}
+	if err != nil {
+		a.logger.Warn(ctx, "install agent update", slog.F("path", a.updateInstall), slog.Error(err))
+	}
+}
+
+// handleAgentUpdate installs the update offered in the manifest, unless the
+// agent doesn't trust updates or the update fails verification, for example
+// because it isn't newer than the running version.
+func (a *agent) handleAgentUpdate(ctx context.Context, update *agentsdk.AgentUpdate) {
+	if update == nil || a.updatePublicKey == nil {
+		return
+	}
+	// The executable of a running process can't be replaced on Windows.
+	if runtime.GOOS == "windows" {
+		return
+	}
+	err := agentupdate.Verify(*update, a.updatePublicKey, buildinfo.Version())
+	if err != nil {
+		if !errors.Is(err, agentupdate.ErrNotNewer) {
+			a.logger.Warn(ctx, "reject agent update", slog.F("version", update.Version), slog.Error(err))
+		}
+		return
+	}
+	if !a.updating.CompareAndSwap(false, true) {
+		return
+	}
+	err = a.trackGoroutine(func() {
+		defer a.updating.Store(false)
+		err := a.installUpdate(a.gracefulCtx, *update)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			a.logger.Error(ctx, "install agent update", slog.F("version", update.Version), slog.Error(err))
+			a.logSender.Enqueue(agentsdk.ExternalLogSourceID, agentsdk.Log{
+				CreatedAt: dbtime.Now(),
+				Output:    fmt.Sprintf("Failed to update the agent to %s: %s", update.Version, err),
+				Level:     wirtualsdk.LogLevelError,
+			})
+		}
+	})
+	if err != nil {
+		a.updating.Store(false)
+	}
+}
+
+// installUpdate downloads the update, waits for the workspace to be idle and
+// replaces the agent process with the new version. The process keeps its
+// PID, so the processes started by the agent are unaffected, and the new
+// version connects with the same tailnet address as it's derived from the
+// agent ID. The downloaded binary is run in place, and only replaces the
+// executable once it's running. It only returns if the update fails.
+func (a *agent) installUpdate(ctx context.Context, update agentsdk.AgentUpdate) error {
+	logger := a.logger.Named("update").With(slog.F("version", update.Version))
+
+	executable, err := os.Executable()
+	if err != nil {
+		return xerrors.Errorf("find executable: %w", err)
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return xerrors.Errorf("resolve executable: %w", err)
+	}
+
+	logger.Info(ctx, "downloading agent update", slog.F("url", update.URL))
+	downloaded, err := agentupdate.Download(ctx, a.updateHTTPClient, update, a.updatePublicKey, buildinfo.Version(), filepath.Dir(executable))
+	if errors.Is(err, os.ErrPermission) {
+		// The executable can't be replaced, so the new version is run from
+		// the temporary directory instead.
+		downloaded, err = agentupdate.Download(ctx, a.updateHTTPClient, update, a.updatePublicKey, buildinfo.Version(), a.tempDir)
+	}
+	if err != nil {
+		return xerrors.Errorf("download: %w", err)
+	}
+	defer func() {
+		// The download is only kept if the agent was replaced with it.
+		_ = os.Remove(downloaded)
+	}()
+	err = agentupdate.Check(ctx, downloaded, update.Version)
+	if err != nil {
+		return xerrors.Errorf("check: %w", err)
+	}
+
+	logger.Info(ctx, "waiting for the workspace to be idle to install the agent update")
+	err = a.waitForUpdateIdle(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.logSender.Enqueue(agentsdk.ExternalLogSourceID, agentsdk.Log{
+		CreatedAt: dbtime.Now(),
+		Output:    fmt.Sprintf("Updating the agent from %s to %s", buildinfo.Version(), update.Version),
+		Level:     wirtualsdk.LogLevelInfo,
+	})
+	a.logSender.Flush(agentsdk.ExternalLogSourceID)
+	flushCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	_ = a.logSender.WaitUntilEmpty(flushCtx)
+	cancel()
+
+	// Services would be started twice if they outlived the old version.
+	a.serviceSupervisor.Stop()
+
+	env := append(os.Environ(),
+		EnvAgentUpdatedFrom+"="+buildinfo.Version(),
+		EnvAgentUpdatedLifecycle+"="+string(a.lifecycle()),
+	)
+	if filepath.Dir(downloaded) == filepath.Dir(executable) {
+		env = append(env, EnvAgentUpdateInstall+"="+executable)
+	}
+	logger.Info(ctx, "executing agent update", slog.F("path", downloaded))
+	//nolint:gosec // The binary was verified by agentupdate.Download.
+	err = syscall.Exec(downloaded, os.Args, env)
+	// The executable is untouched, so this version keeps running with the
+	// services it stopped.
+	if startErr := a.serviceSupervisor.Start(a.manifest.Load().Services); startErr != nil {
+		logger.Warn(ctx, "restart services after failed update", slog.Error(startErr))
+	}
+	return xerrors.Errorf("exec %s: %w", downloaded, err)
+}
+
+// waitForUpdateIdle waits until the startup scripts have finished and there
+// are no SSH sessions or terminals, as their connections don't survive the
+// agent being replaced.
+func (a *agent) waitForUpdateIdle(ctx context.Context) error {
+	ticker := time.NewTicker(updateIdleCheckInterval)
+	defer ticker.Stop()
+	for {
+		if a.updateIdle() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *agent) updateIdle() bool {
+	switch a.lifecycle() {
+	case wirtualsdk.WorkspaceAgentLifecycleReady,
+		wirtualsdk.WorkspaceAgentLifecycleStartError,
+		wirtualsdk.WorkspaceAgentLifecycleStartTimeout:
+	default:
+		return false
+	}
+	stats := a.sshServer.ConnStats()
+	if stats.Sessions > 0 || stats.VSCode > 0 || stats.JetBrains > 0 {
+		return false
+	}
+	return a.reconnectingPTYServer.ConnCount() == 0 && a.reconnectingPTYServer.PTYCount() == 0
+}
+
+// lifecycle returns the current lifecycle state of the agent.
+func (a *agent) lifecycle() wirtualsdk.WorkspaceAgentLifecycle {
+	a.lifecycleMu.RLock()
+	defer a.lifecycleMu.RUnlock()
+	return a.lifecycleStates[len(a.lifecycleStates)-1].State
+}
@@ -7,7 +7,10 @@ import (
 	"errors"
 	"io"
 	"log"
+	"path"
 	"strings"
+
+	"golang.org/x/xerrors"
 )
 
 // CreateTarFromZip converts the given zipReader to a tar archive.
@@ -113,3 +116,34 @@ func WriteZip(w io.Writer, tarReader *tar.Reader, maxSize int64) error {
 	}
 	return nil // don't need to flush as we call `writer.Close()`
 }
+
+// ReadTarFiles reads the regular files of the given tarReader into memory,
+// keyed by their path. Reading fails when the files are larger than maxSize
+// in total.
+func ReadTarFiles(tarReader *tar.Reader, maxSize int64) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	var total int64
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		total += header.Size
+		if total > maxSize {
+			return nil, xerrors.Errorf("archive is larger than %d bytes", maxSize)
+		}
+		var buf bytes.Buffer
+		_, err = io.CopyN(&buf, tarReader, header.Size)
+		if err != nil {
+			return nil, err
+		}
+		files[strings.TrimPrefix(path.Clean(header.Name), "./")] = buf.Bytes()
+	}
+	return files, nil
+}
@@ -164,3 +164,27 @@ func assertExtractedFiles(t *testing.T, dir string, checkModePerm bool) {
 		return nil
 	})
 }
+
+func TestReadTarFiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		tarBytes := archivetest.TestTarFileBytes()
+
+		files, err := archive.ReadTarFiles(tar.NewReader(bytes.NewReader(tarBytes)), int64(len(tarBytes)))
+		require.NoError(t, err)
+		require.Equal(t, map[string][]byte{
+			"test/hello.txt":     []byte("hello"),
+			"test/dir/world.txt": []byte("world"),
+		}, files)
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		t.Parallel()
+		tarBytes := archivetest.TestTarFileBytes()
+
+		_, err := archive.ReadTarFiles(tar.NewReader(bytes.NewReader(tarBytes)), 8)
+		require.ErrorContains(t, err, "archive is larger than 8 bytes")
+	})
+}
@@ -2,6 +2,8 @@ package cli
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net/http"
@@ -50,6 +52,10 @@ func (r *RootCmd) workspaceAgent() *serpent.Command {
 		slogJSONPath        string
 		slogStackdriverPath string
 		blockFileTransfer   bool
+		reportTopProcesses  int64
+		updatePublicKey     string
+		devcontainerFolders []string
+		logFiles            []string
 		agentHeaderCommand  string
 		agentHeader         []string
 	)
@@ -309,6 +315,34 @@ func (r *RootCmd) workspaceAgent() *serpent.Command {
 				)
 			}
 
+			cgroups, err := agentexec.SetupCgroups()
+			if err != nil {
+				// Limits are best-effort, the workspace is still usable
+				// without them.
+				logger.Error(ctx, "set up cgroup limits", slog.Error(err))
+			} else if cgroups != nil {
+				for _, class := range cgroups.Classes() {
+					limits, _ := cgroups.Limits(class)
+					dir, _ := cgroups.Dir(class)
+					logger.Info(ctx, "cgroup limits enabled",
+						slog.F("class", class),
+						slog.F("cgroup", dir),
+						slog.F("limits", limits),
+					)
+				}
+			}
+
+			var updateKey ed25519.PublicKey
+			if updatePublicKey != "" {
+				updateKey, err = base64.StdEncoding.DecodeString(strings.TrimSpace(updatePublicKey))
+				if err != nil {
+					return xerrors.Errorf("decode update public key: %w", err)
+				}
+				if len(updateKey) != ed25519.PublicKeySize {
+					return xerrors.Errorf("update public key is %d bytes, expected an ed25519 public key of %d bytes", len(updateKey), ed25519.PublicKeySize)
+				}
+			}
+
 			agnt := agent.New(agent.Options{
 				Client:            client,
 				Logger:            logger,
@@ -333,6 +367,14 @@ func (r *RootCmd) workspaceAgent() *serpent.Command {
 
 				PrometheusRegistry: prometheusRegistry,
 				BlockFileTransfer:  blockFileTransfer,
+				ReportTopProcesses: int(reportTopProcesses),
+				Cgroups:            cgroups,
+				UpdatePublicKey:    updateKey,
+				// Binaries take longer to download than the timeout of
+				// the API client allows.
+				UpdateHTTPClient:    &http.Client{Transport: client.SDK.HTTPClient.Transport},
+				DevcontainerFolders: devcontainerFolders,
+				LogFiles:            logFiles,
 			})
 
 			promHandler := agent.PrometheusMetricsHandler(prometheusRegistry, logger)
@@ -455,6 +497,32 @@ func (r *RootCmd) workspaceAgent() *serpent.Command {
 			Description: fmt.Sprintf("Block file transfer using known applications: %s.", strings.Join(agentssh.BlockedFileTransferCommands, ",")),
 			Value:       serpent.BoolOf(&blockFileTransfer),
 		},
+		{
+			Flag:        "report-top-processes",
+			Default:     "0",
+			Env:         "WIRTUAL_AGENT_REPORT_TOP_PROCESSES",
+			Description: "The number of busiest processes whose CPU and memory usage is reported with the agent stats. Set to 0 to disable.",
+			Value:       serpent.Int64Of(&reportTopProcesses),
+		},
+		{
+			Flag:        "update-public-key",
+			Default:     "",
+			Env:         "WIRTUAL_AGENT_UPDATE_PUBLIC_KEY",
+			Description: "The base64-encoded ed25519 public key that signs agent updates. Updates offered by the deployment are only installed if this is set.",
+			Value:       serpent.StringOf(&updatePublicKey),
+		},
+		{
+			Flag:        "devcontainer-folders",
+			Env:         "WIRTUAL_AGENT_DEVCONTAINER_FOLDERS",
+			Description: "Folders searched for devcontainer.json files, whose dev containers are started once the startup scripts have run. Relative folders are resolved against the agent directory and glob patterns are allowed.",
+			Value:       serpent.StringArrayOf(&devcontainerFolders),
+		},
+		{
+			Flag:        "log-files",
+			Env:         "WIRTUAL_AGENT_LOG_FILES",
+			Description: "Glob patterns of log files that are forwarded as agent logs, each file as its own log source. Relative patterns are resolved against the agent directory.",
+			Value:       serpent.StringArrayOf(&logFiles),
+		},
 	}
 
 	return cmd
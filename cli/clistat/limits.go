@@ -0,0 +1,153 @@
+package clistat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+const (
+	cgroupV2Mount  = "/sys/fs/cgroup"
+	procSelfCgroup = "/proc/self/cgroup"
+)
+
+// CgroupLimits are the cgroup v2 limits that apply to the current process.
+// A limit is nil if it's unlimited. Limits set on a parent cgroup apply to
+// its children, so the lowest limit of the hierarchy is reported.
+type CgroupLimits struct {
+	// Cgroup is the cgroup of the current process.
+	Cgroup string `json:"cgroup"`
+	// CPUWeight is the share of CPU time the cgroup gets when the CPU is
+	// contended, relative to its siblings.
+	CPUWeight *int64 `json:"cpu_weight"`
+	// CPUMax is the number of CPUs the cgroup may use.
+	CPUMax *float64 `json:"cpu_max"`
+	// MemoryMax is the memory the cgroup may use in bytes.
+	MemoryMax *int64 `json:"memory_max_bytes"`
+	// PIDsMax is the number of processes the cgroup may run.
+	PIDsMax *int64 `json:"pids_max"`
+}
+
+func (l *CgroupLimits) String() string {
+	if l == nil {
+		return "-"
+	}
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "cgroup:     %s\n", l.Cgroup)
+	if l.CPUWeight != nil {
+		_, _ = fmt.Fprintf(&sb, "cpu weight: %d\n", *l.CPUWeight)
+	} else {
+		_, _ = sb.WriteString("cpu weight: default\n")
+	}
+	if l.CPUMax != nil {
+		_, _ = fmt.Fprintf(&sb, "cpu:        %s cores\n", humanizeFloat(*l.CPUMax))
+	} else {
+		_, _ = sb.WriteString("cpu:        unlimited\n")
+	}
+	if l.MemoryMax != nil {
+		_, _ = fmt.Fprintf(&sb, "memory:     %s\n", humanize.IBytes(uint64(*l.MemoryMax)))
+	} else {
+		_, _ = sb.WriteString("memory:     unlimited\n")
+	}
+	if l.PIDsMax != nil {
+		_, _ = fmt.Fprintf(&sb, "processes:  %d", *l.PIDsMax)
+	} else {
+		_, _ = sb.WriteString("processes:  unlimited")
+	}
+	return sb.String()
+}
+
+// CgroupLimits returns the cgroup v2 limits of the current process. Only
+// cgroup v2 is supported.
+func (s *Statter) CgroupLimits() (*CgroupLimits, error) {
+	cgroup, err := s.selfCgroupV2()
+	if err != nil {
+		return nil, err
+	}
+	limits := &CgroupLimits{Cgroup: cgroup}
+
+	// Walk up from the cgroup of the process to the root of the hierarchy.
+	dir := path.Join(cgroupV2Mount, cgroup)
+	for {
+		if limits.CPUWeight == nil {
+			// Weights are relative to siblings, so only the weight of the
+			// closest cgroup that has one is meaningful.
+			if weight, err := readInt64(s.fs, path.Join(dir, "cpu.weight")); err == nil {
+				limits.CPUWeight = &weight
+			}
+		}
+		if cpus, ok := s.readCPUMax(path.Join(dir, "cpu.max")); ok && (limits.CPUMax == nil || cpus < *limits.CPUMax) {
+			limits.CPUMax = &cpus
+		}
+		if limit, ok := s.readMax(path.Join(dir, "memory.max")); ok && (limits.MemoryMax == nil || limit < *limits.MemoryMax) {
+			limits.MemoryMax = &limit
+		}
+		if limit, ok := s.readMax(path.Join(dir, "pids.max")); ok && (limits.PIDsMax == nil || limit < *limits.PIDsMax) {
+			limits.PIDsMax = &limit
+		}
+		if dir == cgroupV2Mount {
+			break
+		}
+		dir = path.Dir(dir)
+	}
+	return limits, nil
+}
+
+func (s *Statter) selfCgroupV2() (string, error) {
+	data, err := afero.ReadFile(s.fs, procSelfCgroup)
+	if err != nil {
+		return "", xerrors.Errorf("read %s: %w", procSelfCgroup, err)
+	}
+	scn := bufio.NewScanner(bytes.NewReader(data))
+	for scn.Scan() {
+		// The cgroup v2 hierarchy has ID 0 and no controllers.
+		if cgroup, ok := strings.CutPrefix(scn.Text(), "0::"); ok {
+			return cgroup, nil
+		}
+	}
+	return "", xerrors.New("cgroup v2 is not in use")
+}
+
+// readMax reads a limit that is either a number or "max". It returns false
+// if the file doesn't exist or there is no limit.
+func (s *Statter) readMax(file string) (int64, bool) {
+	data, err := afero.ReadFile(s.fs, file)
+	if err != nil {
+		return 0, false
+	}
+	limit, err := strconv.ParseInt(string(bytes.TrimSpace(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return limit, true
+}
+
+// readCPUMax reads the number of CPUs allowed by a cpu.max file, which
+// contains the quota and the period in microseconds.
+func (s *Statter) readCPUMax(file string) (float64, bool) {
+	data, err := afero.ReadFile(s.fs, file)
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return 0, false
+	}
+	quota, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		// The quota is "max".
+		return 0, false
+	}
+	period, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || period <= 0 {
+		return 0, false
+	}
+	return float64(quota) / float64(period), true
+}
@@ -0,0 +1,255 @@
+package clistat
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/user"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+const (
+	procDir = "/proc"
+	// clockTicksPerSecond is USER_HZ, the unit of the CPU times in
+	// /proc/[pid]/stat. It is 100 on every platform Linux supports.
+	clockTicksPerSecond = 100.0
+)
+
+// Process is a snapshot of the resources used by a single process.
+type Process struct {
+	PID     int32
+	Name    string
+	Cmdline string
+	User    string
+	// CPU is the percentage of one core used over the sample interval, so it
+	// can exceed 100 for processes with several busy threads.
+	CPU float64
+	// RSS is the resident set size in bytes.
+	RSS uint64
+	// ReadBytes and WriteBytes are the bytes read from and written to storage
+	// since the process started. They are zero if the process can't be
+	// inspected.
+	ReadBytes  uint64
+	WriteBytes uint64
+	// Cgroup is the cgroup v2 path of the process, or its first cgroup v1
+	// path.
+	Cgroup string
+}
+
+// Processes returns the processes using the most CPU, then the most memory.
+// CPU usage is measured by taking two samples of each process, one sample
+// interval apart. At most limit processes are returned, or all of them if
+// limit is zero. Only Linux is supported.
+func (s *Statter) Processes(limit int) ([]Process, error) {
+	pids, err := s.listPIDs()
+	if err != nil {
+		return nil, err
+	}
+	before := make(map[int32]uint64, len(pids))
+	for _, pid := range pids {
+		stat, err := s.readProcStat(pid)
+		if err != nil {
+			// The process exited.
+			continue
+		}
+		before[pid] = stat.cpuTicks
+	}
+
+	s.wait(s.sampleInterval)
+
+	pids, err = s.listPIDs()
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[string]string)
+	pageSize := uint64(os.Getpagesize())
+	procs := make([]Process, 0, len(pids))
+	for _, pid := range pids {
+		stat, err := s.readProcStat(pid)
+		if err != nil {
+			continue
+		}
+		proc := Process{
+			PID:  pid,
+			Name: stat.name,
+			RSS:  stat.rssPages * pageSize,
+		}
+		// Processes that started during the sample interval have no usage
+		// to compare against, so they're reported as idle.
+		if prev, ok := before[pid]; ok && stat.cpuTicks >= prev {
+			proc.CPU = float64(stat.cpuTicks-prev) / clockTicksPerSecond / s.sampleInterval.Seconds() * 100
+		}
+		proc.Cmdline = s.readProcCmdline(pid)
+		proc.User = s.readProcUser(pid, usernames)
+		proc.ReadBytes, proc.WriteBytes = s.readProcIO(pid)
+		proc.Cgroup = s.readProcCgroup(pid)
+		procs = append(procs, proc)
+	}
+
+	slices.SortFunc(procs, func(a, b Process) int {
+		switch {
+		case a.CPU != b.CPU:
+			if a.CPU > b.CPU {
+				return -1
+			}
+			return 1
+		case a.RSS != b.RSS:
+			if a.RSS > b.RSS {
+				return -1
+			}
+			return 1
+		default:
+			return int(a.PID - b.PID)
+		}
+	})
+	if limit > 0 && len(procs) > limit {
+		procs = procs[:limit]
+	}
+	return procs, nil
+}
+
+func (s *Statter) listPIDs() ([]int32, error) {
+	entries, err := afero.ReadDir(s.fs, procDir)
+	if err != nil {
+		return nil, xerrors.Errorf("read %s: %w", procDir, err)
+	}
+	pids := make([]int32, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, int32(pid))
+	}
+	return pids, nil
+}
+
+type procStat struct {
+	name     string
+	cpuTicks uint64
+	rssPages uint64
+}
+
+// readProcStat parses /proc/[pid]/stat, see proc(5).
+func (s *Statter) readProcStat(pid int32) (procStat, error) {
+	file := procPath(pid, "stat")
+	data, err := afero.ReadFile(s.fs, file)
+	if err != nil {
+		return procStat{}, xerrors.Errorf("read %s: %w", file, err)
+	}
+
+	// The name is in parentheses and may itself contain spaces and
+	// parentheses, so the fields after it are found from the last ")".
+	start := bytes.IndexByte(data, '(')
+	end := bytes.LastIndexByte(data, ')')
+	if start < 0 || end < start {
+		return procStat{}, xerrors.Errorf("parse %s: no process name", file)
+	}
+	// fields[0] is the state, the third field of the file.
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 22 {
+		return procStat{}, xerrors.Errorf("parse %s: expected at least 24 fields", file)
+	}
+	var values [3]uint64
+	for i, idx := range []int{11, 12, 21} { // utime, stime, rss
+		values[i], err = strconv.ParseUint(fields[idx], 10, 64)
+		if err != nil {
+			return procStat{}, xerrors.Errorf("parse %s: %w", file, err)
+		}
+	}
+	return procStat{
+		name:     string(data[start+1 : end]),
+		cpuTicks: values[0] + values[1],
+		rssPages: values[2],
+	}, nil
+}
+
+func (s *Statter) readProcCmdline(pid int32) string {
+	data, err := afero.ReadFile(s.fs, procPath(pid, "cmdline"))
+	if err != nil {
+		return ""
+	}
+	// Arguments are separated and terminated by NUL bytes.
+	return strings.TrimSpace(string(bytes.ReplaceAll(data, []byte{0}, []byte{' '})))
+}
+
+// readProcUser returns the name of the real user of a process, or its UID if
+// the user can't be looked up. Lookups are cached in usernames.
+func (s *Statter) readProcUser(pid int32, usernames map[string]string) string {
+	data, err := afero.ReadFile(s.fs, procPath(pid, "status"))
+	if err != nil {
+		return ""
+	}
+	var uid string
+	scn := bufio.NewScanner(bytes.NewReader(data))
+	for scn.Scan() {
+		fields := strings.Fields(scn.Text())
+		if len(fields) > 1 && fields[0] == "Uid:" {
+			uid = fields[1]
+			break
+		}
+	}
+	if uid == "" {
+		return ""
+	}
+	if name, ok := usernames[uid]; ok {
+		return name
+	}
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	usernames[uid] = name
+	return name
+}
+
+func (s *Statter) readProcIO(pid int32) (readBytes, writeBytes uint64) {
+	// /proc/[pid]/io is only readable by the owner of the process.
+	file := procPath(pid, "io")
+	read, err := readInt64Prefix(s.fs, file, "read_bytes:")
+	if err != nil {
+		return 0, 0
+	}
+	write, err := readInt64Prefix(s.fs, file, "write_bytes:")
+	if err != nil {
+		return 0, 0
+	}
+	return uint64(read), uint64(write)
+}
+
+func (s *Statter) readProcCgroup(pid int32) string {
+	data, err := afero.ReadFile(s.fs, procPath(pid, "cgroup"))
+	if err != nil {
+		return ""
+	}
+	// Each line is hierarchy-ID:controllers:path. The cgroup v2 hierarchy
+	// has ID 0 and no controllers.
+	var first string
+	scn := bufio.NewScanner(bytes.NewReader(data))
+	for scn.Scan() {
+		parts := strings.SplitN(scn.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			return parts[2]
+		}
+		if first == "" {
+			first = parts[2]
+		}
+	}
+	return first
+}
+
+func procPath(pid int32, name string) string {
+	return path.Join(procDir, strconv.FormatInt(int64(pid), 10), name)
+}
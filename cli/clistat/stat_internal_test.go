@@ -1,6 +1,8 @@
 package clistat
 
 import (
+	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -276,6 +278,127 @@ func TestStatter(t *testing.T) {
 	})
 }
 
+func TestStatterProcesses(t *testing.T) {
+	t.Parallel()
+
+	statLine := func(pid int, comm string, utime, stime, rss int) string {
+		return fmt.Sprintf("%d (%s) S 1 1 1 0 -1 4194560 100 0 0 0 %d %d 0 0 20 0 1 0 100 1000000 %d", pid, comm, utime, stime, rss)
+	}
+	fs := initFS(t, map[string]string{
+		"/proc/10/stat":    statLine(10, "node (server)", 100, 20, 2000),
+		"/proc/10/cmdline": "node\x00server.js\x00",
+		"/proc/10/status":  "Name:\tnode\nUid:\t4294967290\t4294967290\t4294967290\t4294967290",
+		"/proc/10/io":      "rchar: 1\nwchar: 2\nread_bytes: 4096\nwrite_bytes: 8192",
+		"/proc/10/cgroup":  "0::/user.slice/node",
+		"/proc/20/stat":    statLine(20, "sh", 5, 5, 100),
+		"/proc/20/cgroup":  "12:memory:/docker/abc\n11:cpu,cpuacct:/docker/abc",
+		"/proc/self/stat":  statLine(10, "node (server)", 100, 20, 2000),
+	})
+	fakeWait := func(time.Duration) {
+		mungeFS(t, fs, "/proc/10/stat", statLine(10, "node (server)", 140, 30, 3000))
+		mungeFS(t, fs, "/proc/20/stat", statLine(20, "sh", 6, 5, 100))
+		// Started during the sample interval.
+		mungeFS(t, fs, "/proc/30/stat", statLine(30, "make", 50, 0, 500))
+	}
+	s, err := New(WithFS(fs))
+	require.NoError(t, err)
+	s.wait = fakeWait
+
+	procs, err := s.Processes(0)
+	require.NoError(t, err)
+	require.Len(t, procs, 3)
+	pageSize := uint64(os.Getpagesize())
+
+	assert.Equal(t, int32(10), procs[0].PID)
+	assert.Equal(t, "node (server)", procs[0].Name)
+	assert.Equal(t, "node server.js", procs[0].Cmdline)
+	assert.Equal(t, "4294967290", procs[0].User)
+	// 50 ticks over 100ms.
+	assert.InDelta(t, 500.0, procs[0].CPU, 0.001)
+	assert.Equal(t, 3000*pageSize, procs[0].RSS)
+	assert.Equal(t, uint64(4096), procs[0].ReadBytes)
+	assert.Equal(t, uint64(8192), procs[0].WriteBytes)
+	assert.Equal(t, "/user.slice/node", procs[0].Cgroup)
+
+	assert.Equal(t, int32(20), procs[1].PID)
+	assert.InDelta(t, 10.0, procs[1].CPU, 0.001)
+	assert.Equal(t, "/docker/abc", procs[1].Cgroup)
+	assert.Empty(t, procs[1].User)
+
+	assert.Equal(t, int32(30), procs[2].PID)
+	assert.Zero(t, procs[2].CPU)
+	assert.Equal(t, 500*pageSize, procs[2].RSS)
+
+	t.Run("Limit", func(t *testing.T) {
+		t.Parallel()
+		procs, err := s.Processes(1)
+		require.NoError(t, err)
+		require.Len(t, procs, 1)
+		assert.Equal(t, int32(10), procs[0].PID)
+	})
+}
+
+func TestStatterCgroupLimits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Limited", func(t *testing.T) {
+		t.Parallel()
+		fs := initFS(t, map[string]string{
+			"/proc/self/cgroup":             "0::/ssh",
+			"/sys/fs/cgroup/cpu.max":        "400000 100000",
+			"/sys/fs/cgroup/memory.max":     "8589934592",
+			"/sys/fs/cgroup/pids.max":       "max",
+			"/sys/fs/cgroup/ssh/cpu.weight": "50",
+			"/sys/fs/cgroup/ssh/cpu.max":    "max 100000",
+			"/sys/fs/cgroup/ssh/memory.max": "4294967296",
+			"/sys/fs/cgroup/ssh/pids.max":   "1000",
+		})
+		s, err := New(WithFS(fs))
+		require.NoError(t, err)
+		limits, err := s.CgroupLimits()
+		require.NoError(t, err)
+		assert.Equal(t, "/ssh", limits.Cgroup)
+		require.NotNil(t, limits.CPUWeight)
+		assert.Equal(t, int64(50), *limits.CPUWeight)
+		// The limit of the parent applies.
+		require.NotNil(t, limits.CPUMax)
+		assert.Equal(t, 4.0, *limits.CPUMax)
+		require.NotNil(t, limits.MemoryMax)
+		assert.Equal(t, int64(4294967296), *limits.MemoryMax)
+		require.NotNil(t, limits.PIDsMax)
+		assert.Equal(t, int64(1000), *limits.PIDsMax)
+		assert.Equal(t, "cgroup:     /ssh\ncpu weight: 50\ncpu:        4 cores\nmemory:     4.0 GiB\nprocesses:  1000", limits.String())
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		t.Parallel()
+		fs := initFS(t, map[string]string{
+			"/proc/self/cgroup":         "0::/",
+			"/sys/fs/cgroup/cpu.max":    "max 100000",
+			"/sys/fs/cgroup/memory.max": "max",
+		})
+		s, err := New(WithFS(fs))
+		require.NoError(t, err)
+		limits, err := s.CgroupLimits()
+		require.NoError(t, err)
+		assert.Nil(t, limits.CPUWeight)
+		assert.Nil(t, limits.CPUMax)
+		assert.Nil(t, limits.MemoryMax)
+		assert.Nil(t, limits.PIDsMax)
+	})
+
+	t.Run("CgroupV1", func(t *testing.T) {
+		t.Parallel()
+		fs := initFS(t, map[string]string{
+			"/proc/self/cgroup": "12:memory:/docker/abc",
+		})
+		s, err := New(WithFS(fs))
+		require.NoError(t, err)
+		_, err = s.CgroupLimits()
+		require.ErrorContains(t, err, "cgroup v2 is not in use")
+	})
+}
+
 func TestIsContainerized(t *testing.T) {
 	t.Parallel()
 
@@ -71,6 +71,7 @@ func ProvisionerJob(ctx context.Context, wr io.Writer, opts ProvisionerJobOption
 		currentStage          = ProvisioningStateQueued
 		currentStageStartedAt = time.Now().UTC()
 		currentQueuePos       = -1
+		currentQueueWait      time.Duration
 
 		errChan  = make(chan error, 1)
 		job      wirtualsdk.ProvisionerJob
@@ -89,6 +90,9 @@ func ProvisionerJob(ctx context.Context, wr io.Writer, opts ProvisionerJobOption
 			} else {
 				queuePos = fmt.Sprintf("position: %d", currentQueuePos)
 			}
+			if currentQueueWait > 0 {
+				queuePos += ", ~" + queueWaitDisplay(currentQueueWait) + " wait"
+			}
 
 			out = pretty.Sprintf(DefaultStyles.Warn, "%s (%s)", currentStage, queuePos)
 		}
@@ -126,6 +130,7 @@ func ProvisionerJob(ctx context.Context, wr io.Writer, opts ProvisionerJobOption
 			initialState := currentQueuePos == -1
 
 			currentQueuePos = job.QueuePosition
+			currentQueueWait = time.Duration(job.QueueEstimatedWaitMillis) * time.Millisecond
 			// Print an update when the queue position changes, but:
 			//   - not initially, because the stage is printed at startup
 			//   - not when we're first in the queue, because it's redundant
@@ -300,3 +305,12 @@ func (s *stageWriter) flushLogs() {
 	}
 	s.logBuf.Reset()
 }
+
+// queueWaitDisplay formats an estimated queue wait. Estimates are rough, so
+// anything above a minute is rounded to whole minutes.
+func queueWaitDisplay(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Round(time.Second).Seconds()))
+	}
+	return fmt.Sprintf("%dm", int(d.Round(time.Minute).Minutes()))
+}
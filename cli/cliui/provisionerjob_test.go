@@ -104,6 +104,7 @@ func TestProvisionerJob(t *testing.T) {
 		tests := []struct {
 			name     string
 			queuePos int
+			waitMs   int64
 			expected string
 		}{
 			{
@@ -121,6 +122,12 @@ func TestProvisionerJob(t *testing.T) {
 				queuePos: 4,
 				expected: fmt.Sprintf(`%s %s$`, stage, regexp.QuoteMeta("(position: 4)")),
 			},
+			{
+				name:     "estimated wait",
+				queuePos: 3,
+				waitMs:   150_000,
+				expected: fmt.Sprintf(`%s %s$`, stage, regexp.QuoteMeta("(position: 3, ~3m wait)")),
+			},
 		}
 
 		for _, tc := range tests {
@@ -133,6 +140,7 @@ func TestProvisionerJob(t *testing.T) {
 				test.JobMutex.Lock()
 				test.Job.QueuePosition = tc.queuePos
 				test.Job.QueueSize = tc.queuePos
+				test.Job.QueueEstimatedWaitMillis = tc.waitMs
 				test.JobMutex.Unlock()
 
 				ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
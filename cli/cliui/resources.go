@@ -20,6 +20,9 @@ type WorkspaceResourcesOptions struct {
 	HideAccess     bool
 	Title          string
 	ServerVersion  string
+	// ShowAppHealth adds a row for each app with a health check below its
+	// agent.
+	ShowAppHealth bool
 }
 
 // WorkspaceResources displays the connection status and tree-view of provided resources.
@@ -112,6 +115,10 @@ func WorkspaceResources(writer io.Writer, resources []wirtualsdk.WorkspaceResour
 				row = append(row, sshCommand)
 			}
 			tableWriter.AppendRow(row)
+
+			if options.ShowAppHealth && !options.HideAgentState {
+				appendAppHealthRows(tableWriter, agent, pipe == "└")
+			}
 		}
 		tableWriter.AppendSeparator()
 	}
@@ -150,6 +157,54 @@ func renderAgentHealth(agent wirtualsdk.WorkspaceAgent) string {
 	return pretty.Sprint(DefaultStyles.Error, "✘ "+agent.Health.Reason)
 }
 
+// appendAppHealthRows adds a row for each app of the agent that has a health
+// check, nested below the agent in the tree.
+func appendAppHealthRows(tableWriter table.Writer, agent wirtualsdk.WorkspaceAgent, lastAgent bool) {
+	apps := make([]wirtualsdk.WorkspaceApp, 0, len(agent.Apps))
+	for _, app := range agent.Apps {
+		if app.Health == wirtualsdk.WorkspaceAppHealthDisabled || app.Health == "" {
+			continue
+		}
+		apps = append(apps, app)
+	}
+	indent := "│  "
+	if lastAgent {
+		indent = "   "
+	}
+	for index, app := range apps {
+		pipe := "├"
+		if index == len(apps)-1 {
+			pipe = "└"
+		}
+		name := app.DisplayName
+		if name == "" {
+			name = app.Slug
+		}
+		tableWriter.AppendRow(table.Row{
+			fmt.Sprintf("%s%s─ %s", indent, pipe, name),
+			"",
+			renderAppHealth(app),
+			"",
+		})
+	}
+}
+
+func renderAppHealth(app wirtualsdk.WorkspaceApp) string {
+	switch app.Health {
+	case wirtualsdk.WorkspaceAppHealthHealthy:
+		return pretty.Sprint(DefaultStyles.Keyword, "✔ healthy")
+	case wirtualsdk.WorkspaceAppHealthInitializing:
+		return pretty.Sprint(DefaultStyles.Warn, "⦾ initializing")
+	case wirtualsdk.WorkspaceAppHealthUnhealthy:
+		if app.HealthReason != "" {
+			return pretty.Sprint(DefaultStyles.Error, "✘ unhealthy: "+app.HealthReason)
+		}
+		return pretty.Sprint(DefaultStyles.Error, "✘ unhealthy")
+	default:
+		return pretty.Sprint(DefaultStyles.Placeholder, string(app.Health))
+	}
+}
+
 func renderAgentVersion(agentVersion, serverVersion string) string {
 	if agentVersion == "" {
 		agentVersion = "(unknown)"
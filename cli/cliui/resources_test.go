@@ -110,4 +110,46 @@ func TestWorkspaceResources(t *testing.T) {
 		ptty.ExpectMatch("coder ssh dev.postgres")
 		<-done
 	})
+
+	t.Run("AppHealth", func(t *testing.T) {
+		t.Parallel()
+		ptty := ptytest.New(t)
+		done := make(chan struct{})
+		go func() {
+			err := cliui.WorkspaceResources(ptty.Output(), []wirtualsdk.WorkspaceResource{{
+				Type:       "google_compute_instance",
+				Name:       "dev",
+				Transition: wirtualsdk.WorkspaceTransitionStart,
+				Agents: []wirtualsdk.WorkspaceAgent{{
+					Name:            "dev",
+					Status:          wirtualsdk.WorkspaceAgentConnected,
+					LifecycleState:  wirtualsdk.WorkspaceAgentLifecycleReady,
+					Architecture:    "amd64",
+					OperatingSystem: "linux",
+					Health:          wirtualsdk.WorkspaceAgentHealth{Healthy: true},
+					Apps: []wirtualsdk.WorkspaceApp{{
+						Slug:   "code-server",
+						Health: wirtualsdk.WorkspaceAppHealthHealthy,
+					}, {
+						Slug:   "no-healthcheck",
+						Health: wirtualsdk.WorkspaceAppHealthDisabled,
+					}, {
+						Slug:         "database",
+						Health:       wirtualsdk.WorkspaceAppHealthUnhealthy,
+						HealthReason: "dial tcp 127.0.0.1:5432: connect: connection refused",
+					}},
+				}},
+			}}, cliui.WorkspaceResourcesOptions{
+				WorkspaceName: "example",
+				ShowAppHealth: true,
+			})
+			assert.NoError(t, err)
+			close(done)
+		}()
+		ptty.ExpectMatch("├─ code-server")
+		ptty.ExpectMatch("healthy")
+		ptty.ExpectMatch("└─ database")
+		ptty.ExpectMatch("unhealthy: dial tcp 127.0.0.1:5432: connect: connection refused")
+		<-done
+	})
 }
@@ -52,6 +52,13 @@ func (r Root) DotfilesURL() File {
 	return File(filepath.Join(string(r), "dotfilesurl"))
 }
 
+// Proxy caches the workspace proxy selected by latency, so it does not have to
+// be measured on every command.
+func (r Root) Proxy() File {
+	r.mustNotEmpty()
+	return File(filepath.Join(string(r), "proxy"))
+}
+
 func (r Root) PostgresPath() string {
 	r.mustNotEmpty()
 	return filepath.Join(string(r), "postgres")
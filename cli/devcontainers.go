@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/workspacesdk"
+)
+
+func (r *RootCmd) devcontainers() *serpent.Command {
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "devcontainers",
+		Short:       "Manage the dev containers of a workspace",
+		Long: "Dev containers are defined by devcontainer.json files in the folders the agent searches, and are started by the agent. Connect to one with `coder ssh workspace.container`.\n" + FormatExamples(
+			Example{
+				Description: "List the dev containers of a workspace",
+				Command:     "coder devcontainers ls my-workspace",
+			},
+			Example{
+				Description: "Rebuild a dev container after changing its config",
+				Command:     "coder devcontainers rebuild my-workspace my-project",
+			},
+			Example{
+				Description: "Open a shell in a dev container",
+				Command:     "coder ssh my-workspace.my-project",
+			},
+		),
+		Aliases: []string{"devcontainer"},
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.listDevcontainers(),
+			r.devcontainerAction("start", "Start a dev container, creating its container if it doesn't exist", "Starting", true, (*workspacesdk.AgentConn).StartDevcontainer),
+			r.devcontainerAction("stop", "Stop the container of a dev container", "Stopped", false, (*workspacesdk.AgentConn).StopDevcontainer),
+			r.devcontainerAction("rebuild", "Replace the container of a dev container with one built from its current config", "Rebuilding", true, (*workspacesdk.AgentConn).RebuildDevcontainer),
+			r.devcontainerLogs(),
+		},
+	}
+	return cmd
+}
+
+type devcontainerListRow struct {
+	// For JSON format:
+	wirtualsdk.WorkspaceAgentDevcontainer `table:"-"`
+
+	// For table format:
+	Name            string `json:"-" table:"name,default_sort"`
+	Agent           string `json:"-" table:"agent"`
+	State           string `json:"-" table:"state"`
+	WorkspaceFolder string `json:"-" table:"workspace folder"`
+	Image           string `json:"-" table:"image"`
+	Since           string `json:"-" table:"since"`
+	Error           string `json:"-" table:"error"`
+}
+
+func devcontainerListRowFromDevcontainer(now time.Time, agent string, devcontainer wirtualsdk.WorkspaceAgentDevcontainer) devcontainerListRow {
+	since := "-"
+	if devcontainer.StateChangedAt != nil {
+		since = durationDisplay(now.Sub(*devcontainer.StateChangedAt))
+	}
+	return devcontainerListRow{
+		WorkspaceAgentDevcontainer: devcontainer,
+		Name:                       devcontainer.Name,
+		Agent:                      agent,
+		State:                      string(devcontainer.State),
+		WorkspaceFolder:            devcontainer.WorkspaceFolder,
+		Image:                      devcontainer.Image,
+		Since:                      since,
+		Error:                      devcontainer.Error,
+	}
+}
+
+func (r *RootCmd) listDevcontainers() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]devcontainerListRow{}, []string{"name", "agent", "state", "workspace folder", "since"}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:     "list <workspace>",
+		Aliases: []string{"ls"},
+		Short:   "List the dev containers of a workspace and the state of their containers",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+			workspace, err := namedWorkspace(ctx, client, inv.Args[0])
+			if err != nil {
+				return err
+			}
+
+			// The state is the one last reported by each agent, so dev
+			// containers of disconnected agents are listed too.
+			now := time.Now()
+			rows := []devcontainerListRow{}
+			for _, resource := range workspace.LatestBuild.Resources {
+				for _, agent := range resource.Agents {
+					res, err := client.WorkspaceAgentDevcontainers(ctx, agent.ID)
+					if err != nil {
+						return xerrors.Errorf("list dev containers of agent %q: %w", agent.Name, err)
+					}
+					for _, devcontainer := range res.Devcontainers {
+						rows = append(rows, devcontainerListRowFromDevcontainer(now, agent.Name, devcontainer))
+					}
+				}
+			}
+			if len(rows) == 0 {
+				cliui.Infof(inv.Stderr, "No dev containers found.")
+				return nil
+			}
+
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+// devcontainerAction returns a command that runs action on the dev
+// container named by the second argument. Background actions return once
+// the agent has started working on the container, so the command points at
+// the logs.
+func (r *RootCmd) devcontainerAction(use, short, verb string, background bool, action func(*workspacesdk.AgentConn, context.Context, string) error) *serpent.Command {
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   use + " <workspace> <devcontainer>",
+		Short: short,
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			conn, err := r.dialConnectedAgent(ctx, inv, client)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			name := inv.Args[1]
+			err = action(conn, ctx, name)
+			if err != nil {
+				return xerrors.Errorf("%s dev container %q: %w", use, name, err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "%s dev container %s\n", verb, pretty.Sprint(cliui.DefaultStyles.Keyword, name))
+			if background {
+				_, _ = fmt.Fprintf(inv.Stdout, "Follow the progress with %s\n", pretty.Sprint(cliui.DefaultStyles.Code, fmt.Sprintf("coder devcontainers logs %s %s", inv.Args[0], name)))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) devcontainerLogs() *serpent.Command {
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "logs <workspace> <devcontainer>",
+		Short: "Show the output of the last build and start of a dev container",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			conn, err := r.dialConnectedAgent(ctx, inv, client)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			name := inv.Args[1]
+			logs, err := conn.DevcontainerLogs(ctx, name)
+			if err != nil {
+				return xerrors.Errorf("get logs for dev container %q: %w", name, err)
+			}
+			_, err = inv.Stdout.Write(logs)
+			return err
+		},
+	}
+	return cmd
+}
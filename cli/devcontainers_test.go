@@ -0,0 +1,50 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agenttest"
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+)
+
+func TestDevcontainers(t *testing.T) {
+	t.Parallel()
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t)
+	_ = agenttest.New(t, client.URL, agentToken)
+	_ = wirtualdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	t.Run("ListEmpty", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		inv, root := clitest.New(t, "devcontainers", "ls", workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		var stderr bytes.Buffer
+		inv.Stderr = &stderr
+		require.NoError(t, inv.WithContext(ctx).Run())
+		require.Contains(t, stderr.String(), "No dev containers found.")
+	})
+
+	t.Run("LogsNotFound", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		inv, root := clitest.New(t, "devcontainers", "logs", workspace.Name, "missing")
+		clitest.SetupConfig(t, client, root)
+		err := inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("SSHNotFound", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		inv, root := clitest.New(t, "ssh", workspace.Name+".missing")
+		clitest.SetupConfig(t, client, root)
+		err := inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, `no agent or dev container named "missing"`)
+	})
+}
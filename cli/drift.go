@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func (r *RootCmd) drift() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			drift, ok := data.(wirtualsdk.WorkspaceDrift)
+			if !ok {
+				return nil, xerrors.Errorf("expected WorkspaceDrift, got %T", data)
+			}
+			return formatDrift(drift), nil
+		}),
+		cliui.JSONFormat(),
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "drift <workspace>",
+		Short:       "Show the resources of a workspace that changed outside of its builds.",
+		Long: "Drift is detected periodically while a workspace is running, by comparing the resources in its Terraform state with the real infrastructure.\n" + FormatExamples(
+			Example{
+				Description: "Show the drift of a workspace",
+				Command:     "coder drift my-workspace",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			if err != nil {
+				return err
+			}
+			drift, err := client.WorkspaceDrift(inv.Context(), workspace.ID)
+			if err != nil {
+				return xerrors.Errorf("get workspace drift: %w", err)
+			}
+
+			out, err := formatter.Format(inv.Context(), drift)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func formatDrift(drift wirtualsdk.WorkspaceDrift) string {
+	if drift.CheckedAt == nil {
+		return fmt.Sprintf("Build %d hasn't been checked for drift yet.", drift.BuildNumber)
+	}
+	if len(drift.Resources) == 0 {
+		return fmt.Sprintf("No drift was detected in build %d (checked %s).", drift.BuildNumber, drift.CheckedAt.Format("2006-01-02 15:04:05"))
+	}
+	var sb strings.Builder
+	for _, resource := range drift.Resources {
+		if resource.Action == wirtualsdk.WorkspaceDriftActionDelete {
+			_, _ = fmt.Fprintln(&sb, pretty.Sprint(cliui.DefaultStyles.Error, "- "+resource.Address))
+			continue
+		}
+		_, _ = fmt.Fprintln(&sb, pretty.Sprint(cliui.DefaultStyles.Warn, "~ "+resource.Address))
+		for _, attribute := range resource.Attributes {
+			if attribute.Sensitive {
+				_, _ = fmt.Fprintf(&sb, "    %s: %s\n", attribute.Name, cliui.Placeholder("(sensitive)"))
+				continue
+			}
+			before, after := attribute.Before, attribute.After
+			if before == "" {
+				before = "null"
+			}
+			if after == "" {
+				after = "null"
+			}
+			_, _ = fmt.Fprintf(&sb, "    %s: %s → %s\n", attribute.Name, before, after)
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,119 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbfake"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbgen"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/provisionerdserver"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestDrift(t *testing.T) {
+	t.Parallel()
+
+	client, store := wirtualdtest.NewWithDatabase(t, nil)
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	member, memberUser := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+	t.Run("NotChecked", func(t *testing.T) {
+		t.Parallel()
+		r := dbfake.WorkspaceBuild(t, store, database.WorkspaceTable{
+			OrganizationID: owner.OrganizationID,
+			OwnerID:        memberUser.ID,
+		}).Do()
+
+		inv, root := clitest.New(t, "drift", r.Workspace.Name)
+		clitest.SetupConfig(t, member, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "hasn't been checked for drift yet")
+	})
+
+	t.Run("Drifted", func(t *testing.T) {
+		t.Parallel()
+		r := dbfake.WorkspaceBuild(t, store, database.WorkspaceTable{
+			OrganizationID: owner.OrganizationID,
+			OwnerID:        memberUser.ID,
+		}).Do()
+
+		resources, err := json.Marshal([]wirtualsdk.WorkspaceDriftResource{{
+			Address: "docker_container.workspace",
+			Type:    "docker_container",
+			Action:  wirtualsdk.WorkspaceDriftActionUpdate,
+			Attributes: []wirtualsdk.WorkspaceDriftAttribute{{
+				Name:   "memory",
+				Before: "512",
+				After:  "1024",
+			}, {
+				Name:      "env",
+				Sensitive: true,
+			}},
+		}, {
+			Address: "docker_volume.home",
+			Type:    "docker_volume",
+			Action:  wirtualsdk.WorkspaceDriftActionDelete,
+		}})
+		require.NoError(t, err)
+		input, err := json.Marshal(provisionerdserver.WorkspaceDriftCheckJob{
+			WorkspaceBuildID: r.Build.ID,
+		})
+		require.NoError(t, err)
+		job := dbgen.ProvisionerJob(t, store, nil, database.ProvisionerJob{
+			OrganizationID: owner.OrganizationID,
+			InitiatorID:    memberUser.ID,
+			Provisioner:    database.ProvisionerTypeEcho,
+			Type:           database.ProvisionerJobTypeWorkspaceDriftCheck,
+			Input:          input,
+		})
+		// nolint:gocritic // Unit test
+		ctx := dbauthz.AsSystemRestricted(context.Background())
+		err = store.UpsertWorkspaceDriftCheck(ctx, database.UpsertWorkspaceDriftCheckParams{
+			WorkspaceBuildID: r.Build.ID,
+			JobID:            job.ID,
+			CreatedAt:        dbtime.Now(),
+		})
+		require.NoError(t, err)
+		_, err = store.UpdateWorkspaceDriftByJobID(ctx, database.UpdateWorkspaceDriftByJobIDParams{
+			JobID:     job.ID,
+			CheckedAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+			Resources: resources,
+		})
+		require.NoError(t, err)
+
+		inv, root := clitest.New(t, "drift", r.Workspace.Name)
+		clitest.SetupConfig(t, member, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err = inv.Run()
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "~ docker_container.workspace")
+		require.Contains(t, out.String(), "memory: 512 → 1024")
+		require.Contains(t, out.String(), "env: (sensitive)")
+		require.Contains(t, out.String(), "- docker_volume.home")
+
+		inv, root = clitest.New(t, "drift", r.Workspace.Name, "--output", "json")
+		clitest.SetupConfig(t, member, root)
+		out.Reset()
+		inv.Stdout = &out
+		err = inv.Run()
+		require.NoError(t, err)
+		var drift wirtualsdk.WorkspaceDrift
+		require.NoError(t, json.Unmarshal(out.Bytes(), &drift))
+		require.Equal(t, r.Build.ID, drift.BuildID)
+		require.Len(t, drift.Resources, 2)
+	})
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+const (
+	// execTimedOutExitCode is the exit code of commands that timed out, the
+	// same as the one of timeout(1).
+	execTimedOutExitCode = 124
+	// execFailedExitCode is the exit code when the command couldn't be run,
+	// like the one of SSH when the connection fails.
+	execFailedExitCode = 255
+)
+
+func (r *RootCmd) exec() *serpent.Command {
+	var (
+		env     []string
+		dir     string
+		timeout time.Duration
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "exec <workspace> -- <command> [args...]",
+		Short:       "Run a command in a workspace without SSH",
+		Long: "The command is run by the shell of the workspace user, with the same environment and permissions as over SSH, and exits with its exit code. Stdout and stderr are kept apart, and no terminal is allocated.\n" + FormatExamples(
+			Example{
+				Description: "Pull the latest changes of a repository",
+				Command:     "coder exec my-workspace --dir src/repo -- git pull",
+			},
+			Example{
+				Description: "Run a command in a specific agent of a workspace",
+				Command:     "coder exec my-workspace.main --timeout 5m -- make test",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireRangeArgs(2, -1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+
+			req := wirtualsdk.WorkspaceAgentExecRequest{
+				Command:          inv.Args[1:],
+				WorkingDirectory: dir,
+				TimeoutMillis:    timeout.Milliseconds(),
+			}
+			if len(env) > 0 {
+				req.Env = make(map[string]string, len(env))
+				for _, kv := range env {
+					k, v, ok := strings.Cut(kv, "=")
+					if !ok || k == "" {
+						return xerrors.Errorf("invalid environment variable %q, must be in the form KEY=VALUE", kv)
+					}
+					req.Env[k] = v
+				}
+			}
+
+			_, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, false, inv.Args[0])
+			if err != nil {
+				return err
+			}
+
+			result, err := client.WorkspaceAgentExec(ctx, workspaceAgent.ID, req, inv.Stdout, inv.Stderr)
+			if err != nil {
+				return ExitError(execFailedExitCode, xerrors.Errorf("run command: %w", err))
+			}
+			switch {
+			case result.Error != "":
+				return ExitError(execFailedExitCode, xerrors.Errorf("run command: %s", result.Error))
+			case result.TimedOut:
+				return ExitError(execTimedOutExitCode, xerrors.Errorf("command timed out after %s", timeout))
+			case result.ExitCode < 0:
+				return ExitError(execFailedExitCode, xerrors.New("command was killed before it exited"))
+			case result.ExitCode > 0:
+				return ExitError(result.ExitCode, nil)
+			}
+			return nil
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:          "env",
+			FlagShorthand: "e",
+			Description:   "Set environment variables for the command, in the form KEY=VALUE.",
+			Value:         serpent.StringArrayOf(&env),
+		},
+		{
+			Flag:          "dir",
+			FlagShorthand: "w",
+			Description:   "The directory to run the command in. Relative paths are resolved against the directory of the agent.",
+			Value:         serpent.StringOf(&dir),
+		},
+		{
+			Flag:        "timeout",
+			Description: "Kill the command if it runs for longer than this duration.",
+			Value:       serpent.DurationOf(&timeout),
+		},
+	}
+	return cmd
+}
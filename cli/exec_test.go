@@ -0,0 +1,70 @@
+package cli_test
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agenttest"
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbfake"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+)
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are run with sh")
+	}
+
+	client, db := wirtualdtest.NewWithDatabase(t, nil)
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	member, user := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        user.ID,
+	}).WithAgent().Do()
+	_ = agenttest.New(t, client.URL, r.AgentToken)
+	_ = wirtualdtest.AwaitWorkspaceAgents(t, client, r.Workspace.ID)
+
+	run := func(t *testing.T, args ...string) (string, string, error) {
+		t.Helper()
+		inv, root := clitest.New(t, append([]string{"exec", r.Workspace.Name}, args...)...)
+		clitest.SetupConfig(t, member, root)
+		var stdout, stderr bytes.Buffer
+		inv.Stdout = &stdout
+		inv.Stderr = &stderr
+		err := inv.WithContext(testutil.Context(t, testutil.WaitLong)).Run()
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		stdout, stderr, err := run(t, "--env", "NAME=world", "--", "sh", "-c", `echo "hello $NAME"; echo oops >&2`)
+		require.NoError(t, err)
+		require.Equal(t, "hello world\n", stdout)
+		require.Equal(t, "oops\n", stderr)
+	})
+
+	t.Run("ExitCode", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := run(t, "--", "sh", "-c", "exit 2")
+		require.ErrorContains(t, err, "exit code 2")
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := run(t, "--timeout", "100ms", "--", "sleep", "30")
+		require.ErrorContains(t, err, "exit code 124")
+	})
+
+	t.Run("InvalidEnv", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := run(t, "--env", "NAME", "--", "true")
+		require.ErrorContains(t, err, "must be in the form KEY=VALUE")
+	})
+}
@@ -60,6 +60,12 @@ func (r *RootCmd) logout() *serpent.Command {
 				errors = append(errors, xerrors.Errorf("remove organization file: %w", err))
 			}
 
+			err = config.Proxy().Delete()
+			// If the proxy configuration file is absent, we still proceed
+			if err != nil && !os.IsNotExist(err) {
+				errors = append(errors, xerrors.Errorf("remove proxy file: %w", err))
+			}
+
 			if len(errors) > 0 {
 				var errorStringBuilder strings.Builder
 				for _, err := range errors {
@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+const (
+	// buildLogSource is the name of the source selecting the build logs.
+	buildLogSource = "build"
+	// agentLogsPageSize is the number of agent logs fetched per request
+	// when the logs aren't followed.
+	agentLogsPageSize = 1000
+)
+
+func (r *RootCmd) logs() *serpent.Command {
+	var (
+		sources []string
+		follow  bool
+		since   time.Duration
+		level   string
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "logs <workspace>",
+		Short:       "Show the build and agent logs of a workspace",
+		Long: "Agent logs include the output of scripts and the log files forwarded by the agent. Select sources by the names shown in the dashboard, or \"build\" for the build logs.\n" + FormatExamples(
+			Example{
+				Description: "Follow the logs of a workspace as it starts",
+				Command:     "coder logs my-workspace --follow",
+			},
+			Example{
+				Description: "Show the warnings and errors of the last ten minutes",
+				Command:     "coder logs my-workspace --since 10m --level warn",
+			},
+			Example{
+				Description: "Show the output of a startup script",
+				Command:     `coder logs my-workspace --source "Startup Script"`,
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			workspace, err := namedWorkspace(ctx, client, inv.Args[0])
+			if err != nil {
+				return err
+			}
+
+			filter := logsFilter{
+				sources: make(map[string]bool),
+				level:   wirtualsdk.LogLevel(level),
+			}
+			for _, source := range sources {
+				filter.sources[strings.ToLower(source)] = true
+			}
+			if since > 0 {
+				filter.since = time.Now().Add(-since)
+			}
+			// The agents of a build that's still running aren't known yet,
+			// so their sources can only be checked once it's done.
+			build := workspace.LatestBuild
+			if !build.Job.Status.Active() {
+				err = filter.checkSources(workspace)
+				if err != nil {
+					return err
+				}
+			}
+
+			out := &logsWriter{w: inv.Stdout}
+			if filter.includes(buildLogSource) {
+				err = writeBuildLogs(ctx, client, build, follow, filter, out)
+				if err != nil {
+					return err
+				}
+				if follow && build.Job.Status.Active() {
+					// Agents are created by the build.
+					workspace, err = client.Workspace(ctx, workspace.ID)
+					if err != nil {
+						return xerrors.Errorf("get workspace: %w", err)
+					}
+				}
+			}
+
+			var agents []wirtualsdk.WorkspaceAgent
+			for _, resource := range workspace.LatestBuild.Resources {
+				agents = append(agents, resource.Agents...)
+			}
+			out.agentPrefix = len(agents) > 1
+			group, ctx := errgroup.WithContext(ctx)
+			for _, agent := range agents {
+				sourceIDs, ok := filter.agentSourceIDs(agent)
+				if !ok {
+					continue
+				}
+				req := wirtualsdk.WorkspaceAgentLogsRequest{
+					Follow:    follow,
+					SourceIDs: sourceIDs,
+					Level:     filter.level,
+					Since:     filter.since,
+				}
+				if !follow {
+					// Without following, the logs are written one agent
+					// after the other.
+					err = writeAgentLogs(ctx, client, agent, req, out)
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				group.Go(func() error {
+					return writeAgentLogs(ctx, client, agent, req, out)
+				})
+			}
+			return group.Wait()
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:          "source",
+			FlagShorthand: "s",
+			Description:   `Only show the logs of these sources. Agent log sources are selected by name or ID, and "build" selects the build logs.`,
+			Value:         serpent.StringArrayOf(&sources),
+		},
+		{
+			Flag:          "follow",
+			FlagShorthand: "f",
+			Description:   "Keep showing new logs until the workspace is built again.",
+			Value:         serpent.BoolOf(&follow),
+		},
+		{
+			Flag:        "since",
+			Description: "Only show logs newer than a relative duration like 10m or 1h.",
+			Value:       serpent.DurationOf(&since),
+		},
+		{
+			Flag:        "level",
+			Description: "Only show logs of this level or higher.",
+			Value: serpent.EnumOf(&level,
+				string(wirtualsdk.LogLevelTrace),
+				string(wirtualsdk.LogLevelDebug),
+				string(wirtualsdk.LogLevelInfo),
+				string(wirtualsdk.LogLevelWarn),
+				string(wirtualsdk.LogLevelError),
+			),
+		},
+	}
+	return cmd
+}
+
+// logLevels are ordered by severity.
+var logLevels = []wirtualsdk.LogLevel{
+	wirtualsdk.LogLevelTrace,
+	wirtualsdk.LogLevelDebug,
+	wirtualsdk.LogLevelInfo,
+	wirtualsdk.LogLevelWarn,
+	wirtualsdk.LogLevelError,
+}
+
+type logsFilter struct {
+	// sources are the lowercased names or IDs of the selected sources. All
+	// sources are selected when it's empty.
+	sources map[string]bool
+	level   wirtualsdk.LogLevel
+	since   time.Time
+}
+
+func (f logsFilter) includes(nameOrID ...string) bool {
+	if len(f.sources) == 0 {
+		return true
+	}
+	for _, s := range nameOrID {
+		if f.sources[strings.ToLower(s)] {
+			return true
+		}
+	}
+	return false
+}
+
+// agentSourceIDs returns the IDs of the selected log sources of the agent,
+// and whether any of its logs are selected. A nil slice selects all.
+func (f logsFilter) agentSourceIDs(agent wirtualsdk.WorkspaceAgent) ([]uuid.UUID, bool) {
+	if len(f.sources) == 0 {
+		return nil, true
+	}
+	var ids []uuid.UUID
+	for _, source := range agent.LogSources {
+		if f.includes(source.DisplayName, source.ID.String()) {
+			ids = append(ids, source.ID)
+		}
+	}
+	return ids, len(ids) > 0
+}
+
+// checkSources returns an error naming the available sources if a selected
+// source doesn't exist.
+func (f logsFilter) checkSources(workspace wirtualsdk.Workspace) error {
+	found := map[string]bool{buildLogSource: true}
+	available := []string{fmt.Sprintf("%q", buildLogSource)}
+	for _, resource := range workspace.LatestBuild.Resources {
+		for _, agent := range resource.Agents {
+			for _, source := range agent.LogSources {
+				found[strings.ToLower(source.DisplayName)] = true
+				found[source.ID.String()] = true
+				available = append(available, fmt.Sprintf("%q", source.DisplayName))
+			}
+		}
+	}
+	for source := range f.sources {
+		if !found[source] {
+			sort.Strings(available[1:])
+			return xerrors.Errorf("no log source named %q, the available sources are: %s", source, strings.Join(slices.Compact(available), ", "))
+		}
+	}
+	return nil
+}
+
+func (f logsFilter) match(createdAt time.Time, level wirtualsdk.LogLevel) bool {
+	if !f.since.IsZero() && !createdAt.After(f.since) {
+		return false
+	}
+	return f.level == "" || slices.Index(logLevels, level) >= slices.Index(logLevels, f.level)
+}
+
+func writeBuildLogs(ctx context.Context, client *wirtualsdk.Client, build wirtualsdk.WorkspaceBuild, follow bool, filter logsFilter, out *logsWriter) error {
+	write := func(log wirtualsdk.ProvisionerJobLog) {
+		if filter.match(log.CreatedAt, log.Level) {
+			out.write("", buildLogSource, log.CreatedAt, log.Level, log.Output)
+		}
+	}
+	if !follow {
+		logs, err := client.WorkspaceBuildLogs(ctx, build.ID)
+		if err != nil {
+			return xerrors.Errorf("get build logs: %w", err)
+		}
+		for _, log := range logs {
+			write(log)
+		}
+		return nil
+	}
+
+	// The stream ends once the build is done.
+	logs, closer, err := client.WorkspaceBuildLogsAfter(ctx, build.ID, 0)
+	if err != nil {
+		return xerrors.Errorf("follow build logs: %w", err)
+	}
+	defer closer.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case log, ok := <-logs:
+			if !ok {
+				return nil
+			}
+			write(log)
+		}
+	}
+}
+
+func writeAgentLogs(ctx context.Context, client *wirtualsdk.Client, agent wirtualsdk.WorkspaceAgent, req wirtualsdk.WorkspaceAgentLogsRequest, out *logsWriter) error {
+	sourceNames := make(map[uuid.UUID]string, len(agent.LogSources))
+	for _, source := range agent.LogSources {
+		sourceNames[source.ID] = source.DisplayName
+	}
+	write := func(logs []wirtualsdk.WorkspaceAgentLog) {
+		for _, log := range logs {
+			name, ok := sourceNames[log.SourceID]
+			if !ok {
+				// The source was created after the agent was fetched,
+				// like the ones of log files found later.
+				name = log.SourceID.String()[:8]
+			}
+			out.write(agent.Name, name, log.CreatedAt, log.Level, log.Output)
+		}
+	}
+
+	if !req.Follow {
+		req.Limit = agentLogsPageSize
+	}
+	for {
+		logChunks, closer, err := client.WorkspaceAgentLogs(ctx, agent.ID, req)
+		if err != nil {
+			return xerrors.Errorf("get logs of agent %q: %w", agent.Name, err)
+		}
+		var last []wirtualsdk.WorkspaceAgentLog
+		for logs := range logChunks {
+			write(logs)
+			last = logs
+		}
+		_ = closer.Close()
+		// Followed logs end when the agent is no longer part of the latest
+		// build.
+		if req.Follow || len(last) < agentLogsPageSize {
+			return nil
+		}
+		req.After = last[len(last)-1].ID
+	}
+}
+
+// logsWriter writes log lines of several sources, which may be streamed
+// concurrently.
+type logsWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+	// agentPrefix prefixes sources with their agent, for workspaces with
+	// several agents.
+	agentPrefix bool
+}
+
+func (l *logsWriter) write(agent, source string, createdAt time.Time, level wirtualsdk.LogLevel, output string) {
+	if agent != "" && l.agentPrefix {
+		source = agent + "/" + source
+	}
+	var style pretty.Style
+	switch level {
+	case wirtualsdk.LogLevelTrace, wirtualsdk.LogLevelDebug:
+		style = cliui.DefaultStyles.Placeholder
+	case wirtualsdk.LogLevelWarn:
+		style = cliui.DefaultStyles.Warn
+	case wirtualsdk.LogLevelError:
+		style = cliui.DefaultStyles.Error
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = fmt.Fprintf(l.w, "%s %s %s\n",
+		pretty.Sprint(cliui.DefaultStyles.DateTimeStamp, createdAt.Local().Format("2006-01-02 15:04:05.000Z07:00")),
+		pretty.Sprint(cliui.DefaultStyles.Keyword, "["+source+"]"),
+		pretty.Sprint(style, output),
+	)
+}
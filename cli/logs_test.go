@@ -0,0 +1,145 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbfake"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbgen"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+)
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	client, db := wirtualdtest.NewWithDatabase(t, nil)
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	member, user := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        user.ID,
+	}).WithAgent().Do()
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	// nolint:gocritic // Unit test
+	dbCtx := dbauthz.AsSystemRestricted(ctx)
+	agents, err := db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(dbCtx, r.Workspace.ID)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+
+	now := dbtime.Now()
+	_, err = db.InsertProvisionerJobLogs(dbCtx, database.InsertProvisionerJobLogsParams{
+		JobID:     r.Build.JobID,
+		CreatedAt: []time.Time{now.Add(-time.Hour), now.Add(-time.Hour)},
+		Source:    []database.LogSource{database.LogSourceProvisionerDaemon, database.LogSourceProvisionerDaemon},
+		Level:     []database.LogLevel{database.LogLevelInfo, database.LogLevelWarn},
+		Stage:     []string{"provision", "provision"},
+		Output:    []string{"terraform apply", "deprecated attribute"},
+	})
+	require.NoError(t, err)
+	script := dbgen.WorkspaceAgentLogSource(t, db, database.WorkspaceAgentLogSource{
+		WorkspaceAgentID: agents[0].ID,
+		DisplayName:      "Startup Script",
+	})
+	file := dbgen.WorkspaceAgentLogSource(t, db, database.WorkspaceAgentLogSource{
+		WorkspaceAgentID: agents[0].ID,
+		DisplayName:      "/var/log/app.log",
+	})
+	_, err = db.InsertWorkspaceAgentLogs(dbCtx, database.InsertWorkspaceAgentLogsParams{
+		AgentID:      agents[0].ID,
+		CreatedAt:    now.Add(-time.Hour),
+		Output:       []string{"installing tools", "tool not found"},
+		Level:        []database.LogLevel{database.LogLevelInfo, database.LogLevelError},
+		LogSourceID:  script.ID,
+		OutputLength: 30,
+	})
+	require.NoError(t, err)
+	_, err = db.InsertWorkspaceAgentLogs(dbCtx, database.InsertWorkspaceAgentLogsParams{
+		AgentID:      agents[0].ID,
+		CreatedAt:    now,
+		Output:       []string{"listening on :8080"},
+		Level:        []database.LogLevel{database.LogLevelInfo},
+		LogSourceID:  file.ID,
+		OutputLength: 18,
+	})
+	require.NoError(t, err)
+
+	run := func(t *testing.T, args ...string) ([]string, error) {
+		t.Helper()
+		inv, root := clitest.New(t, append([]string{"logs", r.Workspace.Name}, args...)...)
+		clitest.SetupConfig(t, member, root)
+		var stdout bytes.Buffer
+		inv.Stdout = &stdout
+		err := inv.WithContext(ctx).Run()
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			// Drop the timestamp.
+			_, line, _ = strings.Cut(line, " ")
+			_, line, _ = strings.Cut(line, " ")
+			lines = append(lines, line)
+		}
+		return lines, err
+	}
+
+	t.Run("All", func(t *testing.T) {
+		t.Parallel()
+		lines, err := run(t)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"[build] terraform apply",
+			"[build] deprecated attribute",
+			"[Startup Script] installing tools",
+			"[Startup Script] tool not found",
+			"[/var/log/app.log] listening on :8080",
+		}, lines)
+	})
+
+	t.Run("Level", func(t *testing.T) {
+		t.Parallel()
+		lines, err := run(t, "--level", "warn")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"[build] deprecated attribute",
+			"[Startup Script] tool not found",
+		}, lines)
+	})
+
+	t.Run("Source", func(t *testing.T) {
+		t.Parallel()
+		lines, err := run(t, "--source", "startup script", "--source", file.ID.String())
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"[Startup Script] installing tools",
+			"[Startup Script] tool not found",
+			"[/var/log/app.log] listening on :8080",
+		}, lines)
+	})
+
+	t.Run("Since", func(t *testing.T) {
+		t.Parallel()
+		lines, err := run(t, "--since", "10m")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"[/var/log/app.log] listening on :8080",
+		}, lines)
+	})
+
+	t.Run("UnknownSource", func(t *testing.T) {
+		t.Parallel()
+		_, err := run(t, "--source", "missing")
+		require.ErrorContains(t, err, `no log source named "missing"`)
+		require.ErrorContains(t, err, `"Startup Script"`)
+	})
+}
@@ -25,6 +25,7 @@ func (r *RootCmd) open() *serpent.Command {
 			return inv.Command.HelpHandler(inv)
 		},
 		Children: []*serpent.Command{
+			r.openApp(),
 			r.openVSCode(),
 		},
 	}
@@ -211,6 +212,119 @@ func (r *RootCmd) openVSCode() *serpent.Command {
 	return cmd
 }
 
+func (r *RootCmd) openApp() *serpent.Command {
+	var testOpenError bool
+
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "app <workspace> <app slug>",
+		Short:       "Open a workspace application",
+		Long: "The application is opened through the workspace proxy selected with --proxy, " +
+			"or the proxy with the lowest latency by default.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			workspace, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, true, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace and agent: %w", err)
+			}
+
+			slug := inv.Args[1]
+			var (
+				app   wirtualsdk.WorkspaceApp
+				found bool
+				slugs []string
+			)
+			for _, a := range workspaceAgent.Apps {
+				if a.Slug == slug {
+					app = a
+					found = true
+					break
+				}
+				slugs = append(slugs, a.Slug)
+			}
+			if !found {
+				if len(slugs) == 0 {
+					return xerrors.Errorf("agent %q has no apps", workspaceAgent.Name)
+				}
+				return xerrors.Errorf("app %q not found, available apps: %s", slug, strings.Join(slugs, ", "))
+			}
+
+			var region wirtualsdk.Region
+			if !app.External {
+				region, err = r.workspaceProxy(ctx, client)
+				if err != nil {
+					return xerrors.Errorf("select workspace proxy: %w", err)
+				}
+			}
+			appURL, err := proxyAppURL(region, workspace, workspaceAgent, app)
+			if err != nil {
+				return err
+			}
+
+			if inv.Environ.Get("CODER") == "true" {
+				_, _ = fmt.Fprintf(inv.Stderr, "Opening %s is not supported inside a workspace, please open the following URL on your local machine instead:\n\n", app.Slug)
+				_, _ = fmt.Fprintf(inv.Stdout, "%s\n", appURL)
+				return nil
+			}
+			_, _ = fmt.Fprintf(inv.Stderr, "Opening %s\n", appURL)
+
+			if !testOpenError {
+				err = open.Run(appURL)
+			} else {
+				err = xerrors.New("test.open-error")
+			}
+			if err != nil {
+				_, _ = fmt.Fprintf(inv.Stderr, "Could not automatically open %s: %s\n", app.Slug, err)
+				_, _ = fmt.Fprintf(inv.Stderr, "Please open the following URL instead:\n\n")
+				_, _ = fmt.Fprintf(inv.Stdout, "%s\n", appURL)
+			}
+			return nil
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "test.open-error",
+			Description: "Don't run the open command.",
+			Value:       serpent.BoolOf(&testOpenError),
+			Hidden:      true, // This is for testing!
+		},
+	}
+
+	return cmd
+}
+
+// proxyAppURL returns the URL of the app when served by the given workspace
+// proxy. External apps are opened directly.
+func proxyAppURL(region wirtualsdk.Region, workspace wirtualsdk.Workspace, agent wirtualsdk.WorkspaceAgent, app wirtualsdk.WorkspaceApp) (string, error) {
+	if app.External {
+		return app.URL, nil
+	}
+	base, err := url.Parse(region.PathAppURL)
+	if err != nil {
+		return "", xerrors.Errorf("parse proxy url: %w", err)
+	}
+	if app.Subdomain {
+		if region.WildcardHostname == "" || app.SubdomainName == "" {
+			return "", xerrors.Errorf("app %q is served on a subdomain, but workspace proxy %q has no wildcard hostname", app.Slug, region.Name)
+		}
+		u := &url.URL{
+			Scheme: base.Scheme,
+			Host:   strings.Replace(region.WildcardHostname, "*", app.SubdomainName, 1),
+			Path:   "/",
+		}
+		return u.String(), nil
+	}
+	return base.JoinPath("@"+workspace.OwnerName, workspace.Name+"."+agent.Name, "apps", app.Slug).String() + "/", nil
+}
+
 // waitForAgentCond uses the watch workspace API to update the agent information
 // until the condition is met.
 func waitForAgentCond(ctx context.Context, client *wirtualsdk.Client, workspace wirtualsdk.Workspace, workspaceAgent wirtualsdk.WorkspaceAgent, cond func(wirtualsdk.WorkspaceAgent) bool) (wirtualsdk.Workspace, wirtualsdk.WorkspaceAgent, error) {
@@ -24,6 +24,7 @@ import (
 	"github.com/coder/serpent"
 	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
 	"github.com/onchainengineering/hmi-wirtual/cli/cliutil"
+	"github.com/onchainengineering/hmi-wirtual/tailnet"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/util/ptr"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/healthsdk"
@@ -86,7 +87,19 @@ func (r *RootCmd) ping() *serpent.Command {
 		pingNum          int64
 		pingTimeout      time.Duration
 		pingWait         time.Duration
+		diagnose         bool
+		stunAddrs        []string
 		appearanceConfig wirtualsdk.AppearanceConfig
+		formatter        = cliui.NewOutputFormatter(
+			cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+				d, ok := data.(*tailnet.ConnDiagnostics)
+				if !ok {
+					return nil, xerrors.Errorf("expected type %T, got %T", d, data)
+				}
+				return formatConnDiagnostics(d), nil
+			}),
+			cliui.JSONFormat(),
+		)
 	)
 
 	client := new(wirtualsdk.Client)
@@ -196,6 +209,32 @@ func (r *RootCmd) ping() *serpent.Command {
 			spin.Stop()
 			cliui.PeerDiagnostics(inv.Stderr, diags)
 			connDiags.Write(inv.Stderr)
+
+			if diagnose {
+				probes := int(pingNum)
+				if probes <= 0 {
+					probes = 10
+				}
+				spin.Suffix = pretty.Sprint(cliui.DefaultStyles.Keyword, fmt.Sprintf(" Measuring connection quality over %d probes...", probes))
+				spin.Start()
+				report, err := conn.Diagnose(notifyCtx, tailnet.DiagnoseOptions{
+					Probes:        probes,
+					ProbeInterval: pingWait,
+					ProbeTimeout:  pingTimeout,
+					STUNAddrs:     stunAddrs,
+				})
+				spin.Stop()
+				if err != nil {
+					return xerrors.Errorf("diagnose connection: %w", err)
+				}
+				out, err := formatter.Format(inv.Context(), report)
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(inv.Stdout, out)
+				return err
+			}
+
 			results := &pingSummary{
 				Workspace: workspaceName,
 			}
@@ -319,7 +358,18 @@ func (r *RootCmd) ping() *serpent.Command {
 			Description:   "Specifies the number of pings to perform. By default, pings will continue until interrupted.",
 			Value:         serpent.Int64Of(&pingNum),
 		},
+		{
+			Flag:        "diagnose",
+			Description: "Measure latency, jitter and packet loss over --num probes (default 10), probe the path MTU and detect the NAT type, then print recommendations instead of pinging continuously.",
+			Value:       serpent.BoolOf(&diagnose),
+		},
+		{
+			Flag:        "stun-addr",
+			Description: "Additional STUN servers (host:port) used to detect the NAT type with --diagnose.",
+			Value:       serpent.StringArrayOf(&stunAddrs),
+		},
 	}
+	formatter.AttachOptions(&cmd.Options)
 	return cmd
 }
 
@@ -349,3 +399,44 @@ func isPrivateEndpoint(endpoint string) bool {
 	}
 	return ip.Addr().IsPrivate()
 }
+
+func formatConnDiagnostics(d *tailnet.ConnDiagnostics) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, pretty.Sprint(cliui.DefaultStyles.Keyword, "Connection quality"))
+	path := "proxied via DERP"
+	if d.Direct {
+		path = fmt.Sprintf("direct (p2p) via %s", d.Endpoint)
+	}
+	_, _ = fmt.Fprintf(&sb, "  Path:         %s\n", path)
+	_, _ = fmt.Fprintf(&sb, "  Probes:       %d sent, %d received, %.1f%% loss\n", d.Probes, d.Received, d.Loss*100)
+	if d.Received > 0 {
+		_, _ = fmt.Fprintf(&sb, "  Latency:      min %s, avg %s, max %s\n",
+			d.MinLatency.Round(time.Millisecond), d.AvgLatency.Round(time.Millisecond), d.MaxLatency.Round(time.Millisecond))
+		_, _ = fmt.Fprintf(&sb, "  Jitter:       %s\n", d.Jitter.Round(time.Microsecond*100))
+	}
+	switch {
+	case d.MTUProbeError != "":
+		_, _ = fmt.Fprintf(&sb, "  Path MTU:     unknown (%s)\n", d.MTUProbeError)
+	case d.PathMTU > 0:
+		_, _ = fmt.Fprintf(&sb, "  Path MTU:     %d bytes (tunnel MTU %d)\n", d.PathMTU, d.TunnelMTU)
+	}
+	if d.NetcheckError != "" {
+		_, _ = fmt.Fprintf(&sb, "  NAT type:     unknown (%s)\n", d.NetcheckError)
+	} else {
+		_, _ = fmt.Fprintf(&sb, "  NAT type:     %s\n", d.NATType)
+	}
+
+	_, _ = fmt.Fprintln(&sb)
+	if len(d.Recommendations) == 0 {
+		_, _ = fmt.Fprint(&sb, "✔ No connection problems detected")
+		return sb.String()
+	}
+	_, _ = fmt.Fprintln(&sb, pretty.Sprint(cliui.DefaultStyles.Keyword, "Recommendations"))
+	for i, rec := range d.Recommendations {
+		if i > 0 {
+			_, _ = fmt.Fprintln(&sb)
+		}
+		_, _ = fmt.Fprintf(&sb, " - %s", rec)
+	}
+	return sb.String()
+}
@@ -37,6 +37,7 @@ func (r *RootCmd) portForward() *serpent.Command {
 	var (
 		tcpForwards      []string // <port>:<port>
 		udpForwards      []string // <port>:<port>
+		autoForward      bool
 		disableAutostart bool
 		appearanceConfig wirtualsdk.AppearanceConfig
 	)
@@ -66,6 +67,10 @@ func (r *RootCmd) portForward() *serpent.Command {
 				Description: "Port forward specifying the local address to bind to",
 				Command:     "coder port-forward <workspace> --tcp 1.2.3.4:8080:8080",
 			},
+			Example{
+				Description: "Automatically port forward HTTP ports as they start listening in the workspace",
+				Command:     "coder port-forward <workspace> --auto",
+			},
 		),
 		Middleware: serpent.Chain(
 			serpent.RequireNArgs(1),
@@ -80,7 +85,7 @@ func (r *RootCmd) portForward() *serpent.Command {
 			if err != nil {
 				return xerrors.Errorf("parse port-forward specs: %w", err)
 			}
-			if len(specs) == 0 {
+			if len(specs) == 0 && !autoForward {
 				return xerrors.New("no port-forwards requested")
 			}
 
@@ -121,6 +126,13 @@ func (r *RootCmd) portForward() *serpent.Command {
 			if !r.disableNetworkTelemetry {
 				opts.EnableTelemetry = true
 			}
+			// The proxy only sets the preferred DERP region, so failing to
+			// select one shouldn't prevent port-forwarding.
+			proxy, err := r.connectWorkspaceProxy(ctx, client)
+			if err != nil {
+				logger.Warn(ctx, "failed to select workspace proxy", slog.Error(err))
+			}
+			opts.PreferredDERPRegionCode = proxyDERPRegionCode(proxy)
 			conn, err := workspacesdk.New(client).DialAgent(ctx, workspaceAgent.ID, opts)
 			if err != nil {
 				return err
@@ -131,6 +143,7 @@ func (r *RootCmd) portForward() *serpent.Command {
 			var (
 				wg                = new(sync.WaitGroup)
 				listeners         = make([]net.Listener, 0, len(specs)*2)
+				autoForwarder     = newAutoPortForwarder(ctx, inv, conn, wg, specs, logger)
 				closeAllListeners = func() {
 					logger.Debug(ctx, "closing all listeners")
 					for _, l := range listeners {
@@ -139,6 +152,7 @@ func (r *RootCmd) portForward() *serpent.Command {
 						}
 						_ = l.Close()
 					}
+					autoForwarder.close()
 				}
 			)
 			defer closeAllListeners()
@@ -192,6 +206,13 @@ func (r *RootCmd) portForward() *serpent.Command {
 
 			conn.AwaitReachable(ctx)
 			logger.Debug(ctx, "read to accept connections to forward")
+			if autoForward {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					autoForwarder.run()
+				}()
+			}
 			_, _ = fmt.Fprintln(inv.Stderr, "Ready!")
 			wg.Wait()
 			return closeErr
@@ -212,6 +233,12 @@ func (r *RootCmd) portForward() *serpent.Command {
 			Description: "Forward UDP port(s) from the workspace to the local machine. The UDP connection has TCP-like semantics to support stateful UDP protocols.",
 			Value:       serpent.StringArrayOf(&udpForwards),
 		},
+		{
+			Flag:        "auto",
+			Env:         "WIRTUAL_PORT_FORWARD_AUTO",
+			Description: "Automatically forward HTTP and HTTPS ports to the same port on the local machine as they start listening in the workspace, and stop forwarding them when they close.",
+			Value:       serpent.BoolOf(&autoForward),
+		},
 		sshDisableAutostartOption(serpent.BoolOf(&disableAutostart)),
 	}
 
@@ -285,6 +312,151 @@ func listenAndPortForward(
 	return l, nil
 }
 
+// autoPortForwarder forwards HTTP ports as they start listening in the
+// workspace.
+type autoPortForwarder struct {
+	ctx    context.Context
+	inv    *serpent.Invocation
+	conn   *workspacesdk.AgentConn
+	wg     *sync.WaitGroup
+	logger slog.Logger
+	// explicit holds the workspace ports that are already forwarded by
+	// flags, which are left alone.
+	explicit map[uint16]struct{}
+
+	mu        sync.Mutex
+	closed    bool
+	listeners map[uint16][]net.Listener
+}
+
+func newAutoPortForwarder(ctx context.Context, inv *serpent.Invocation, conn *workspacesdk.AgentConn, wg *sync.WaitGroup, specs []portForwardSpec, logger slog.Logger) *autoPortForwarder {
+	explicit := make(map[uint16]struct{}, len(specs))
+	for _, spec := range specs {
+		if spec.network == "tcp" {
+			explicit[spec.dialPort] = struct{}{}
+		}
+	}
+	return &autoPortForwarder{
+		ctx:       ctx,
+		inv:       inv,
+		conn:      conn,
+		wg:        wg,
+		logger:    logger.Named("auto"),
+		explicit:  explicit,
+		listeners: make(map[uint16][]net.Listener),
+	}
+}
+
+// run forwards ports as the agent reports them until the context is canceled
+// or the watch fails. Older agents can't report ports, in which case only the
+// ports requested by flags are forwarded.
+func (f *autoPortForwarder) run() {
+	// Only ports that may be forwarded are probed for their protocol.
+	skip := make([]uint16, 0, len(f.explicit))
+	for port := range f.explicit {
+		skip = append(skip, port)
+	}
+	events, errs := f.conn.WatchListeningPorts(f.ctx, workspacesdk.WatchListeningPortsOptions{
+		ProbeProtocols: true,
+		SkipProbe:      skip,
+	})
+	for {
+		select {
+		case <-f.ctx.Done():
+			return
+		case err := <-errs:
+			if f.ctx.Err() == nil {
+				_, _ = fmt.Fprintf(f.inv.Stderr, "Stopped automatic port forwarding: %v\n", err)
+			}
+			return
+		case event := <-events:
+			switch event.Type {
+			case wirtualsdk.WorkspaceAgentListeningPortOpened:
+				f.open(event.Port)
+			case wirtualsdk.WorkspaceAgentListeningPortClosed:
+				f.stop(event.Port)
+			}
+		}
+	}
+}
+
+func (f *autoPortForwarder) open(port wirtualsdk.WorkspaceAgentListeningPort) {
+	if port.Network != "tcp" || port.Protocol == "" {
+		return
+	}
+	if _, ok := f.explicit[port.Port]; ok {
+		return
+	}
+	if _, ok := workspacesdk.AgentIgnoredListeningPorts[port.Port]; ok {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	if _, ok := f.listeners[port.Port]; ok {
+		return
+	}
+
+	spec := portForwardSpec{
+		network:    "tcp",
+		listenHost: ipv4Loopback,
+		listenPort: port.Port,
+		dialPort:   port.Port,
+	}
+	l, err := listenAndPortForward(f.ctx, f.inv, f.conn, f.wg, spec, f.logger)
+	if err != nil {
+		// The port is probably in use locally, which shouldn't stop the
+		// other ports from being forwarded.
+		_, _ = fmt.Fprintf(f.inv.Stderr, "Failed to automatically forward port %d: %v\n", port.Port, err)
+		return
+	}
+	listeners := []net.Listener{l}
+	spec6 := spec
+	spec6.listenHost = ipv6Loopback
+	l6, err := listenAndPortForward(f.ctx, f.inv, f.conn, f.wg, spec6, f.logger)
+	if err != nil {
+		f.logger.Info(f.ctx, "failed to opportunistically listen on IPv6", slog.F("spec", spec6), slog.Error(err))
+	} else {
+		listeners = append(listeners, l6)
+	}
+	f.listeners[port.Port] = listeners
+
+	if port.ProcessName != "" {
+		_, _ = fmt.Fprintf(f.inv.Stderr, "Port %d is served by %s (PID %d), open %s://localhost:%d\n",
+			port.Port, port.ProcessName, port.PID, port.Protocol, port.Port)
+	}
+}
+
+func (f *autoPortForwarder) stop(port wirtualsdk.WorkspaceAgentListeningPort) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	listeners, ok := f.listeners[port.Port]
+	if !ok {
+		return
+	}
+	delete(f.listeners, port.Port)
+	for _, l := range listeners {
+		_ = l.Close()
+	}
+	_, _ = fmt.Fprintf(f.inv.Stderr, "Stopped forwarding port %d, it's no longer listening in the workspace\n", port.Port)
+}
+
+// close stops forwarding all ports, and any ports that open afterwards.
+func (f *autoPortForwarder) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	for _, listeners := range f.listeners {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}
+	f.listeners = map[uint16][]net.Listener{}
+}
+
 type portForwardSpec struct {
 	network              string // tcp, udp
 	listenHost           netip.Addr
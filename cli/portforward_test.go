@@ -169,7 +169,7 @@ func TestPortForward(t *testing.T) {
 			errC := make(chan error)
 			go func() {
 				err := inv.WithContext(ctx).Run()
-				t.Logf("command complete; err=%s", err.Error())
+				t.Logf("command complete; err=%v", err)
 				errC <- err
 			}()
 			pty.ExpectMatchContext(ctx, "Ready!")
@@ -354,7 +354,7 @@ func TestPortForward(t *testing.T) {
 		errC := make(chan error)
 		go func() {
 			err := inv.WithContext(ctx).Run()
-			t.Logf("command complete; err=%s", err.Error())
+			t.Logf("command complete; err=%v", err)
 			errC <- err
 		}()
 		pty.ExpectMatchContext(ctx, "Ready!")
@@ -406,6 +406,7 @@ func runAgent(t *testing.T, client *wirtualsdk.Client, owner uuid.UUID, db datab
 // Returns the listener and the listen port.
 func setupTestListener(t *testing.T, l net.Listener) string {
 	t.Helper()
+	require.NotNil(t, l, "remote listener")
 
 	// Wait for listener to completely exit before releasing.
 	done := make(chan struct{})
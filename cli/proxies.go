@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/cli/config"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+const (
+	// proxyAuto selects the healthy workspace proxy with the lowest latency.
+	proxyAuto = "auto"
+	// primaryProxyName is the name of the region served by the deployment
+	// itself.
+	primaryProxyName = "primary"
+)
+
+var (
+	// proxySelectionTTL is how long a proxy selected by latency is cached for.
+	// Clients move between networks, so it must not be cached forever.
+	proxySelectionTTL = time.Hour
+	// proxyLatencyTimeout bounds how long a single proxy is measured for.
+	proxyLatencyTimeout = 5 * time.Second
+	// proxyLatencySamples is the number of requests made to each proxy. The
+	// lowest latency is used, so connection setup doesn't skew the result.
+	proxyLatencySamples = 3
+)
+
+// proxySelection is the workspace proxy selection cached in the config
+// directory.
+type proxySelection struct {
+	// DeploymentURL is the deployment the selection was made for, so logging
+	// in to another deployment invalidates it.
+	DeploymentURL string        `json:"deployment_url"`
+	Name          string        `json:"name"`
+	Latency       time.Duration `json:"latency"`
+	SelectedAt    time.Time     `json:"selected_at"`
+}
+
+// proxyLatency is the measured latency of a region.
+type proxyLatency struct {
+	Region  wirtualsdk.Region
+	Latency time.Duration
+	Err     error
+}
+
+// measureProxyLatencies measures the latency to the latency check endpoint of
+// every healthy region concurrently. Unhealthy regions are skipped and
+// reported with an error.
+func measureProxyLatencies(ctx context.Context, httpClient *http.Client, regions []wirtualsdk.Region) []proxyLatency {
+	latencies := make([]proxyLatency, len(regions))
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		latencies[i].Region = region
+		if !region.Healthy {
+			latencies[i].Err = xerrors.New("proxy is unhealthy")
+			continue
+		}
+		wg.Add(1)
+		go func(i int, region wirtualsdk.Region) {
+			defer wg.Done()
+			latencies[i].Latency, latencies[i].Err = measureProxyLatency(ctx, httpClient, region)
+		}(i, region)
+	}
+	wg.Wait()
+	return latencies
+}
+
+func measureProxyLatency(ctx context.Context, httpClient *http.Client, region wirtualsdk.Region) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, proxyLatencyTimeout)
+	defer cancel()
+
+	u, err := url.Parse(region.PathAppURL)
+	if err != nil {
+		return 0, xerrors.Errorf("parse proxy url: %w", err)
+	}
+	u = u.JoinPath("/latency-check")
+
+	var best time.Duration
+	for i := 0; i < proxyLatencySamples; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return 0, xerrors.Errorf("create request: %w", err)
+		}
+		start := time.Now()
+		res, err := httpClient.Do(req)
+		if err != nil {
+			return 0, xerrors.Errorf("request latency check: %w", err)
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		latency := time.Since(start)
+		if res.StatusCode != http.StatusOK {
+			return 0, xerrors.Errorf("latency check returned status %d", res.StatusCode)
+		}
+		if best == 0 || latency < best {
+			best = latency
+		}
+	}
+	return best, nil
+}
+
+// fastestProxy returns the region with the lowest measured latency. The
+// primary region is returned if no region could be measured.
+func fastestProxy(latencies []proxyLatency) (proxyLatency, bool) {
+	var (
+		best  proxyLatency
+		found bool
+	)
+	for _, l := range latencies {
+		if l.Err != nil {
+			continue
+		}
+		if !found || l.Latency < best.Latency {
+			best = l
+			found = true
+		}
+	}
+	if found {
+		return best, true
+	}
+	for _, l := range latencies {
+		if l.Region.Name == primaryProxyName {
+			return l, true
+		}
+	}
+	return proxyLatency{}, false
+}
+
+// findProxy returns the region with the given name.
+func findProxy(regions []wirtualsdk.Region, name string) (wirtualsdk.Region, bool) {
+	for _, region := range regions {
+		if strings.EqualFold(region.Name, name) {
+			return region, true
+		}
+	}
+	return wirtualsdk.Region{}, false
+}
+
+// proxyDERPRegionCode returns the DERP region code of the relay embedded in
+// the given workspace proxy. The primary region has no preferred DERP region,
+// since its relays are already in the DERP map.
+func proxyDERPRegionCode(region wirtualsdk.Region) string {
+	if region.Name == "" || region.Name == primaryProxyName {
+		return ""
+	}
+	return "coder_" + strings.ToLower(region.Name)
+}
+
+// workspaceProxy returns the region to route app and port-forward traffic
+// through. It honors the --proxy flag, and otherwise uses the cached
+// lowest-latency proxy, measuring the latencies again if the cache is stale.
+func (r *RootCmd) workspaceProxy(ctx context.Context, client *wirtualsdk.Client) (wirtualsdk.Region, error) {
+	regions, region, ok, err := r.selectedWorkspaceProxy(ctx, client)
+	if err != nil || ok {
+		return region, err
+	}
+	return selectFastestProxy(ctx, client, r.createConfig(), regions)
+}
+
+// connectWorkspaceProxy is like workspaceProxy, but never waits for latency
+// measurements, so it can be used on the path of connecting to an agent.
+// Without a fresh cached selection no region is returned, and the latencies
+// are measured in the background for the next connection.
+func (r *RootCmd) connectWorkspaceProxy(ctx context.Context, client *wirtualsdk.Client) (wirtualsdk.Region, error) {
+	regions, region, ok, err := r.selectedWorkspaceProxy(ctx, client)
+	if err != nil || ok {
+		return region, err
+	}
+	go func() {
+		_, _ = selectFastestProxy(ctx, client, r.createConfig(), regions)
+	}()
+	return wirtualsdk.Region{}, nil
+}
+
+// selectedWorkspaceProxy returns the proxy chosen with the --proxy flag or the
+// cached selection. ok is false if latencies must be measured to select one.
+func (r *RootCmd) selectedWorkspaceProxy(ctx context.Context, client *wirtualsdk.Client) (regions []wirtualsdk.Region, region wirtualsdk.Region, ok bool, err error) {
+	regions, err = client.Regions(ctx)
+	if err != nil {
+		return nil, wirtualsdk.Region{}, false, xerrors.Errorf("get regions: %w", err)
+	}
+
+	if r.proxy != "" && r.proxy != proxyAuto {
+		flagged, found := findProxy(regions, r.proxy)
+		if !found {
+			return nil, wirtualsdk.Region{}, false, xerrors.Errorf("workspace proxy %q not found", r.proxy)
+		}
+		if !flagged.Healthy {
+			return nil, wirtualsdk.Region{}, false, xerrors.Errorf("workspace proxy %q is unhealthy", r.proxy)
+		}
+		return regions, flagged, true, nil
+	}
+
+	if selection, cached := readProxySelection(r.createConfig(), client.URL); cached {
+		if selected, found := findProxy(regions, selection.Name); found && selected.Healthy {
+			return regions, selected, true, nil
+		}
+	}
+	return regions, wirtualsdk.Region{}, false, nil
+}
+
+// selectFastestProxy measures the latencies of the regions and caches the
+// fastest one as the selection.
+func selectFastestProxy(ctx context.Context, client *wirtualsdk.Client, conf config.Root, regions []wirtualsdk.Region) (wirtualsdk.Region, error) {
+	best, ok := fastestProxy(measureProxyLatencies(ctx, client.HTTPClient, regions))
+	if !ok {
+		return wirtualsdk.Region{}, xerrors.New("no workspace proxies available")
+	}
+	// Failing to cache the selection only means it is measured again next
+	// time, so the error is ignored.
+	_ = writeProxySelection(conf, proxySelection{
+		DeploymentURL: client.URL.String(),
+		Name:          best.Region.Name,
+		Latency:       best.Latency,
+		SelectedAt:    time.Now(),
+	})
+	return best.Region, nil
+}
+
+func readProxySelection(conf config.Root, deploymentURL *url.URL) (proxySelection, bool) {
+	raw, err := conf.Proxy().Read()
+	if err != nil {
+		return proxySelection{}, false
+	}
+	var selection proxySelection
+	if err := json.Unmarshal([]byte(raw), &selection); err != nil {
+		return proxySelection{}, false
+	}
+	if selection.DeploymentURL != deploymentURL.String() || time.Since(selection.SelectedAt) > proxySelectionTTL {
+		return proxySelection{}, false
+	}
+	return selection, true
+}
+
+func writeProxySelection(conf config.Root, selection proxySelection) error {
+	raw, err := json.Marshal(selection)
+	if err != nil {
+		return xerrors.Errorf("marshal proxy selection: %w", err)
+	}
+	return conf.Proxy().Write(string(raw))
+}
+
+func (r *RootCmd) proxies() *serpent.Command {
+	cmd := &serpent.Command{
+		Use:   "proxies",
+		Short: "Manage the workspace proxy used by the CLI",
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.proxiesList(),
+		},
+	}
+	return cmd
+}
+
+// proxyListRow is the type provided to the OutputFormatter.
+type proxyListRow struct {
+	// For JSON format:
+	wirtualsdk.Region `table:"-"`
+	LatencyMS         float64 `json:"latency_ms,omitempty" table:"-"`
+	LatencyError      string  `json:"latency_error,omitempty" table:"-"`
+	Selected          bool    `json:"selected" table:"selected"`
+
+	// For table format:
+	ProxyName   string `json:"-" table:"name,default_sort"`
+	DisplayName string `json:"-" table:"display name"`
+	URL         string `json:"-" table:"url"`
+	IsHealthy   bool   `json:"-" table:"healthy"`
+	Latency     string `json:"-" table:"latency"`
+}
+
+func proxyListRowFromLatency(l proxyLatency, selected string) proxyListRow {
+	row := proxyListRow{
+		Region:      l.Region,
+		Selected:    l.Region.Name == selected,
+		ProxyName:   l.Region.Name,
+		DisplayName: l.Region.DisplayName,
+		URL:         l.Region.PathAppURL,
+		IsHealthy:   l.Region.Healthy,
+	}
+	if l.Err != nil {
+		row.LatencyError = l.Err.Error()
+		row.Latency = "-"
+		return row
+	}
+	row.LatencyMS = float64(l.Latency) / float64(time.Millisecond)
+	row.Latency = fmt.Sprintf("%dms", l.Latency.Milliseconds())
+	return row
+}
+
+func (r *RootCmd) proxiesList() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]proxyListRow{}, []string{"selected", "name", "display name", "healthy", "latency"}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List workspace proxies and the latency to them",
+		Long: "Measures the latency to every healthy workspace proxy. The selected proxy " +
+			"is used for app and port-forward traffic; change it with --proxy.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+			regions, err := client.Regions(ctx)
+			if err != nil {
+				return xerrors.Errorf("get regions: %w", err)
+			}
+
+			latencies := measureProxyLatencies(ctx, client.HTTPClient, regions)
+			var selected string
+			if r.proxy != "" && r.proxy != proxyAuto {
+				if region, ok := findProxy(regions, r.proxy); ok {
+					selected = region.Name
+				}
+			} else if best, ok := fastestProxy(latencies); ok {
+				selected = best.Region.Name
+				// Listing measures all proxies anyway, so refresh the cached
+				// selection.
+				_ = writeProxySelection(r.createConfig(), proxySelection{
+					DeploymentURL: client.URL.String(),
+					Name:          best.Region.Name,
+					Latency:       best.Latency,
+					SelectedAt:    time.Now(),
+				})
+			}
+
+			rows := make([]proxyListRow, 0, len(latencies))
+			for _, l := range latencies {
+				rows = append(rows, proxyListRowFromLatency(l, selected))
+			}
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/onchainengineering/hmi-wirtual/cli/config"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestMeasureProxyLatencies(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latency-check" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	regions := []wirtualsdk.Region{
+		{Name: "primary", Healthy: true, PathAppURL: srv.URL},
+		{Name: "broken", Healthy: true, PathAppURL: srv.URL + "/broken"},
+		{Name: "unhealthy", Healthy: false, PathAppURL: srv.URL},
+	}
+	latencies := measureProxyLatencies(context.Background(), srv.Client(), regions)
+	require.Len(t, latencies, 3)
+	require.NoError(t, latencies[0].Err)
+	require.Positive(t, latencies[0].Latency)
+	require.ErrorContains(t, latencies[1].Err, "status 404")
+	require.ErrorContains(t, latencies[2].Err, "unhealthy")
+}
+
+func TestFastestProxy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Fastest", func(t *testing.T) {
+		t.Parallel()
+		best, ok := fastestProxy([]proxyLatency{
+			{Region: wirtualsdk.Region{Name: "primary"}, Latency: 80 * time.Millisecond},
+			{Region: wirtualsdk.Region{Name: "sydney"}, Latency: 10 * time.Millisecond},
+			{Region: wirtualsdk.Region{Name: "london"}, Err: xerrors.New("timeout")},
+		})
+		require.True(t, ok)
+		require.Equal(t, "sydney", best.Region.Name)
+	})
+
+	t.Run("FallbackToPrimary", func(t *testing.T) {
+		t.Parallel()
+		best, ok := fastestProxy([]proxyLatency{
+			{Region: wirtualsdk.Region{Name: "sydney"}, Err: xerrors.New("timeout")},
+			{Region: wirtualsdk.Region{Name: "primary"}, Err: xerrors.New("timeout")},
+		})
+		require.True(t, ok)
+		require.Equal(t, "primary", best.Region.Name)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		t.Parallel()
+		_, ok := fastestProxy(nil)
+		require.False(t, ok)
+	})
+}
+
+func TestProxySelectionCache(t *testing.T) {
+	t.Parallel()
+
+	conf := config.Root(filepath.Join(t.TempDir(), "config"))
+	deploymentURL, err := url.Parse("https://dev.coder.com")
+	require.NoError(t, err)
+
+	_, ok := readProxySelection(conf, deploymentURL)
+	require.False(t, ok, "no selection cached yet")
+
+	err = writeProxySelection(conf, proxySelection{
+		DeploymentURL: deploymentURL.String(),
+		Name:          "sydney",
+		SelectedAt:    time.Now(),
+	})
+	require.NoError(t, err)
+	selection, ok := readProxySelection(conf, deploymentURL)
+	require.True(t, ok)
+	require.Equal(t, "sydney", selection.Name)
+
+	otherURL, err := url.Parse("https://other.coder.com")
+	require.NoError(t, err)
+	_, ok = readProxySelection(conf, otherURL)
+	require.False(t, ok, "selection is for another deployment")
+
+	err = writeProxySelection(conf, proxySelection{
+		DeploymentURL: deploymentURL.String(),
+		Name:          "sydney",
+		SelectedAt:    time.Now().Add(-2 * proxySelectionTTL),
+	})
+	require.NoError(t, err)
+	_, ok = readProxySelection(conf, deploymentURL)
+	require.False(t, ok, "selection is stale")
+}
+
+func TestConnectWorkspaceProxy(t *testing.T) {
+	t.Parallel()
+
+	// The latency check blocks until released, like an unreachable proxy.
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/regions":
+			_ = json.NewEncoder(w).Encode(wirtualsdk.RegionsResponse[wirtualsdk.Region]{
+				Regions: []wirtualsdk.Region{{Name: "sydney", Healthy: true, PathAppURL: "http://" + r.Host}},
+			})
+		case "/latency-check":
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	serverURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := wirtualsdk.New(serverURL)
+	r := &RootCmd{globalConfig: filepath.Join(t.TempDir(), "config")}
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	region, err := r.connectWorkspaceProxy(ctx, client)
+	require.NoError(t, err)
+	require.Empty(t, region.Name, "no region without a cached selection")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		_, ok := readProxySelection(r.createConfig(), serverURL)
+		return ok
+	}, testutil.WaitShort, testutil.IntervalFast, "selection is cached in the background")
+
+	region, err = r.connectWorkspaceProxy(ctx, client)
+	require.NoError(t, err)
+	require.Equal(t, "sydney", region.Name)
+}
+
+func TestProxyDERPRegionCode(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, proxyDERPRegionCode(wirtualsdk.Region{}))
+	require.Empty(t, proxyDERPRegionCode(wirtualsdk.Region{Name: "primary"}))
+	require.Equal(t, "coder_sydney", proxyDERPRegionCode(wirtualsdk.Region{Name: "Sydney"}))
+}
+
+func TestProxyAppURL(t *testing.T) {
+	t.Parallel()
+
+	region := wirtualsdk.Region{
+		Name:             "sydney",
+		PathAppURL:       "https://sydney.coder.com",
+		WildcardHostname: "*.sydney.coder.com",
+	}
+	workspace := wirtualsdk.Workspace{OwnerName: "alice", Name: "dev"}
+	agent := wirtualsdk.WorkspaceAgent{Name: "main"}
+
+	for _, tc := range []struct {
+		name    string
+		region  wirtualsdk.Region
+		app     wirtualsdk.WorkspaceApp
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "Path",
+			region: region,
+			app:    wirtualsdk.WorkspaceApp{Slug: "code-server"},
+			want:   "https://sydney.coder.com/@alice/dev.main/apps/code-server/",
+		},
+		{
+			name:   "Subdomain",
+			region: region,
+			app:    wirtualsdk.WorkspaceApp{Slug: "code-server", Subdomain: true, SubdomainName: "code-server--main--dev--alice"},
+			want:   "https://code-server--main--dev--alice.sydney.coder.com/",
+		},
+		{
+			name:    "SubdomainWithoutWildcard",
+			region:  wirtualsdk.Region{Name: "primary", PathAppURL: "https://coder.com"},
+			app:     wirtualsdk.WorkspaceApp{Slug: "code-server", Subdomain: true, SubdomainName: "code-server--main--dev--alice"},
+			wantErr: "no wildcard hostname",
+		},
+		{
+			name: "External",
+			app:  wirtualsdk.WorkspaceApp{Slug: "docs", External: true, URL: "https://coder.com/docs"},
+			want: "https://coder.com/docs",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := proxyAppURL(tc.region, workspace, agent, tc.app)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -66,6 +66,7 @@ const (
 	varVerbose                 = "verbose"
 	varDisableDirect           = "disable-direct-connections"
 	varDisableNetworkTelemetry = "disable-network-telemetry"
+	varProxy                   = "proxy"
 
 	notLoggedInMessage = "You are not logged in. Try logging in using 'coder login <url>'."
 
@@ -91,6 +92,7 @@ func (r *RootCmd) CoreSubcommands() []*serpent.Command {
 		r.notifications(),
 		r.organizations(),
 		r.portForward(),
+		r.proxies(),
 		r.publickey(),
 		r.resetPassword(),
 		r.state(),
@@ -104,19 +106,25 @@ func (r *RootCmd) CoreSubcommands() []*serpent.Command {
 		r.configSSH(),
 		r.create(),
 		r.deleteWorkspace(),
+		r.devcontainers(),
+		r.drift(),
+		r.exec(),
 		r.favorite(),
 		r.list(),
+		r.logs(),
 		r.open(),
 		r.ping(),
 		r.rename(),
 		r.restart(),
 		r.schedules(),
+		r.services(),
 		r.show(),
 		r.speedtest(),
 		r.ssh(),
 		r.start(),
 		r.stat(),
 		r.stop(),
+		r.top(),
 		r.unfavorite(),
 		r.update(),
 		r.whoami(),
@@ -456,6 +464,14 @@ func (r *RootCmd) Command(subcommands []*serpent.Command) (*serpent.Command, err
 			Value:       serpent.BoolOf(&r.disableNetworkTelemetry),
 			Group:       globalGroup,
 		},
+		{
+			Flag:        varProxy,
+			Env:         "WIRTUAL_PROXY",
+			Description: `Name of the workspace proxy to use for apps and port forwarding. "auto" selects the healthy proxy with the lowest latency.`,
+			Default:     proxyAuto,
+			Value:       serpent.StringOf(&r.proxy),
+			Group:       globalGroup,
+		},
 		{
 			Flag:        "debug-http",
 			Description: "Debug wirtualsdk HTTP requests.",
@@ -501,6 +517,7 @@ type RootCmd struct {
 	versionFlag    bool
 	disableDirect  bool
 	debugHTTP      bool
+	proxy          string
 
 	disableNetworkTelemetry bool
 	noVersionCheck          bool
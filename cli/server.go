@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"math/big"
 	"net"
 	"net/http"
@@ -72,6 +73,7 @@ import (
 	"github.com/onchainengineering/hmi-wirtual/cli/config"
 	"github.com/onchainengineering/hmi-wirtual/cryptorand"
 	"github.com/onchainengineering/hmi-wirtual/provisioner/echo"
+	"github.com/onchainengineering/hmi-wirtual/provisioner/exec"
 	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform"
 	"github.com/onchainengineering/hmi-wirtual/provisionerd"
 	"github.com/onchainengineering/hmi-wirtual/provisionerd/proto"
@@ -89,8 +91,10 @@ import (
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/migrations"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/pubsub"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/devtunnel"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/drift"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/externalauth"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/gitsshkey"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/gitsync"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpmw"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/notifications"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/oauthpki"
@@ -583,6 +587,16 @@ func (r *RootCmd) Server(newAPI func(context.Context, *wirtuald.Options) (*wirtu
 				return xerrors.Errorf("parse ssh config options %q: %w", vals.SSHConfig.SSHConfigOptions.String(), err)
 			}
 
+			if vals.Provisioner.JobRetries.Value() < 0 || vals.Provisioner.JobRetries.Value() > math.MaxInt32 {
+				return xerrors.Errorf("provisioner job retries must be between 0 and %d", math.MaxInt32)
+			}
+			// Builds carry the rules to the provisioners, so invalid rules
+			// are rejected here instead of failing every build.
+			_, err = terraform.ParseRetryableErrorRules(vals.Provisioner.RetryableErrors.Value())
+			if err != nil {
+				return xerrors.Errorf("parse provisioner retryable errors: %w", err)
+			}
+
 			options := &wirtuald.Options{
 				AccessURL:                   vals.AccessURL.Value(),
 				AppHostname:                 appHostname,
@@ -956,7 +970,7 @@ func (r *RootCmd) Server(newAPI func(context.Context, *wirtuald.Options) (*wirtu
 			defer shutdownConns()
 
 			// Ensures that old database entries are cleaned up over time!
-			purger := dbpurge.New(ctx, logger.Named("dbpurge"), options.Database, quartz.NewReal())
+			purger := dbpurge.New(ctx, logger.Named("dbpurge"), options.Database, options.DeploymentValues, quartz.NewReal())
 			defer purger.Close()
 
 			// Updates workspace usage
@@ -1037,6 +1051,24 @@ func (r *RootCmd) Server(newAPI func(context.Context, *wirtuald.Options) (*wirtu
 			hangDetector.Start()
 			defer hangDetector.Close()
 
+			if interval := vals.Provisioner.DriftCheckInterval.Value(); interval > 0 {
+				// Builds are checked once per interval, but the ticker runs
+				// more often so new builds don't wait a full interval.
+				driftTicker := time.NewTicker(min(interval, time.Minute))
+				defer driftTicker.Stop()
+				driftDetector := drift.New(ctx, options.Database, options.Pubsub, logger.Named("drift"), driftTicker.C, interval)
+				driftDetector.Start()
+				defer driftDetector.Close()
+			}
+
+			// Repositories are polled once per interval, but the ticker runs
+			// more often so webhooks and manual syncs are picked up quickly.
+			gitSyncTicker := time.NewTicker(10 * time.Second)
+			defer gitSyncTicker.Stop()
+			gitSyncer := gitsync.New(ctx, options.Database, options.Pubsub, logger.Named("gitsync"), options.ExternalAuthConfigs, gitSyncTicker.C, gitsync.DefaultPollInterval)
+			gitSyncer.Start()
+			defer gitSyncer.Close()
+
 			waitForProvisionerJobs := false
 			// Currently there is no way to ask the server to shut
 			// itself down, so any exit signal will result in a non-zero
@@ -1345,6 +1377,17 @@ func newProvisionerDaemon(
 	provisionerTypes = slice.Unique(provisionerTypes)
 	provisionerLogger := logger.Named(fmt.Sprintf("provisionerd-%s", name))
 
+	tfEngine, err := terraform.ParseEngine(cfg.Provisioner.TerraformEngine)
+	if err != nil {
+		return nil, err
+	}
+	// Like external provisioners, built-in provisioners running an engine
+	// other than Terraform advertise it with a tag.
+	var provisionerTags map[string]string
+	if tfEngine != terraform.EngineTerraform {
+		provisionerTags = map[string]string{provisionersdk.TagEngine: string(tfEngine)}
+	}
+
 	// Populate the connector with the supported types.
 	connector := provisionerd.LocalProvisioners{}
 	for _, provisionerType := range provisionerTypes {
@@ -1405,6 +1448,7 @@ func newProvisionerDaemon(
 					},
 					CachePath: tfDir,
 					Tracer:    tracer,
+					Engine:    tfEngine,
 				})
 				if err != nil && !xerrors.Is(err, context.Canceled) {
 					select {
@@ -1415,6 +1459,35 @@ func newProvisionerDaemon(
 			}()
 
 			connector[string(database.ProvisionerTypeTerraform)] = sdkproto.NewDRPCProvisionerClient(terraformClient)
+		case wirtualsdk.ProvisionerTypeExec:
+			execClient, execServer := drpc.MemTransportPipe()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-ctx.Done()
+				_ = execClient.Close()
+				_ = execServer.Close()
+			}()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer cancel()
+
+				err := exec.Serve(ctx, &exec.ServeOptions{
+					ServeOptions: &provisionersdk.ServeOptions{
+						Listener:      execServer,
+						Logger:        provisionerLogger.Named("exec"),
+						WorkDirectory: workDir,
+					},
+				})
+				if err != nil && !xerrors.Is(err, context.Canceled) {
+					select {
+					case errCh <- err:
+					default:
+					}
+				}
+			}()
+			connector[string(database.ProvisionerTypeExec)] = sdkproto.NewDRPCProvisionerClient(execClient)
 		default:
 			return nil, xerrors.Errorf("unknown provisioner type %q", provisionerType)
 		}
@@ -1423,7 +1496,7 @@ func newProvisionerDaemon(
 	return provisionerd.New(func(dialCtx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
 		// This debounces calls to listen every second. Read the comment
 		// in provisionerdserver.go to learn more!
-		return coderAPI.CreateInMemoryProvisionerDaemon(dialCtx, name, provisionerTypes)
+		return coderAPI.CreateInMemoryTaggedProvisionerDaemon(dialCtx, name, provisionerTypes, provisionerTags)
 	}, &provisionerd.Options{
 		Logger:              provisionerLogger,
 		UpdateInterval:      time.Second,
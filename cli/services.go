@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/workspacesdk"
+)
+
+func (r *RootCmd) services() *serpent.Command {
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "services",
+		Short:       "Manage long-running services supervised by a workspace agent",
+		Long: "Services are declared with coder_service and kept running by the agent.\n" + FormatExamples(
+			Example{
+				Description: "List the services of a workspace",
+				Command:     "coder services ls my-workspace",
+			},
+			Example{
+				Description: "Restart a service",
+				Command:     "coder services restart my-workspace web",
+			},
+			Example{
+				Description: "Show the recent output of a service",
+				Command:     "coder services logs my-workspace web",
+			},
+		),
+		Aliases: []string{"service"},
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.listServices(),
+			r.restartService(),
+			r.serviceLogs(),
+		},
+	}
+	return cmd
+}
+
+type serviceListRow struct {
+	// For JSON format:
+	wirtualsdk.WorkspaceAgentService `table:"-"`
+
+	// For table format:
+	Name          string `json:"-" table:"name,default_sort"`
+	State         string `json:"-" table:"state"`
+	RestartPolicy string `json:"-" table:"restart policy"`
+	Restarts      int32  `json:"-" table:"restarts"`
+	ExitCode      int32  `json:"-" table:"exit code"`
+	Since         string `json:"-" table:"since"`
+	Message       string `json:"-" table:"message"`
+}
+
+func serviceListRowFromService(now time.Time, service wirtualsdk.WorkspaceAgentService) serviceListRow {
+	since := "-"
+	if service.StateChangedAt != nil {
+		since = durationDisplay(now.Sub(*service.StateChangedAt))
+	}
+	return serviceListRow{
+		WorkspaceAgentService: service,
+		Name:                  service.Name,
+		State:                 string(service.State),
+		RestartPolicy:         string(service.RestartPolicy),
+		Restarts:              service.RestartCount,
+		ExitCode:              service.ExitCode,
+		Since:                 since,
+		Message:               service.StatusMessage,
+	}
+}
+
+func (r *RootCmd) listServices() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]serviceListRow{}, []string{"name", "state", "restart policy", "restarts", "exit code", "since"}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:     "list <workspace>",
+		Aliases: []string{"ls"},
+		Short:   "List the services of a workspace and the state last reported by the agent",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+			_, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, false, inv.Args[0])
+			if err != nil {
+				return err
+			}
+
+			services, err := client.WorkspaceAgentServices(ctx, workspaceAgent.ID)
+			if err != nil {
+				return xerrors.Errorf("list services: %w", err)
+			}
+			if len(services) == 0 {
+				cliui.Infof(inv.Stderr, "No services found.")
+				return nil
+			}
+
+			now := time.Now()
+			rows := make([]serviceListRow, 0, len(services))
+			for _, service := range services {
+				rows = append(rows, serviceListRowFromService(now, service))
+			}
+
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) restartService() *serpent.Command {
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "restart <workspace> <service>",
+		Short: "Restart a service, skipping any pending backoff",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			conn, err := r.dialConnectedAgent(ctx, inv, client)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			name := inv.Args[1]
+			err = conn.RestartService(ctx, name)
+			if err != nil {
+				return xerrors.Errorf("restart service %q: %w", name, err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Restarting service %s\n", pretty.Sprint(cliui.DefaultStyles.Keyword, name))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) serviceLogs() *serpent.Command {
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "logs <workspace> <service>",
+		Short: "Show the recent output of a service",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			conn, err := r.dialConnectedAgent(ctx, inv, client)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			name := inv.Args[1]
+			logs, err := conn.ServiceLogs(ctx, name)
+			if err != nil {
+				return xerrors.Errorf("get logs for service %q: %w", name, err)
+			}
+			_, err = inv.Stdout.Write(logs)
+			return err
+		},
+	}
+	return cmd
+}
+
+// dialConnectedAgent connects to the agent of the workspace named by the
+// first argument. Managing services and dev containers happens on the agent
+// itself, so it must be connected.
+func (r *RootCmd) dialConnectedAgent(ctx context.Context, inv *serpent.Invocation, client *wirtualsdk.Client) (*workspacesdk.AgentConn, error) {
+	_, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, false, inv.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	if workspaceAgent.Status != wirtualsdk.WorkspaceAgentConnected {
+		return nil, xerrors.Errorf("agent %q is %s, it must be connected", workspaceAgent.Name, workspaceAgent.Status)
+	}
+
+	opts := &workspacesdk.DialAgentOptions{}
+	if r.verbose {
+		opts.Logger = inv.Logger.AppendSinks(sloghuman.Sink(inv.Stderr)).Leveled(slog.LevelDebug)
+	}
+	if r.disableDirect {
+		opts.BlockEndpoints = true
+	}
+	conn, err := workspacesdk.New(client).DialAgent(ctx, workspaceAgent.ID, opts)
+	if err != nil {
+		return nil, xerrors.Errorf("dial agent: %w", err)
+	}
+	if !conn.AwaitReachable(ctx) {
+		_ = conn.Close()
+		return nil, xerrors.New("agent is not reachable")
+	}
+	return conn, nil
+}
@@ -0,0 +1,91 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agenttest"
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// The subtests share a single agent and must run in order, as the last one
+// restarts the service.
+// nolint:tparallel
+func TestServices(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep is not available on Windows")
+	}
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t, func(agents []*proto.Agent) []*proto.Agent {
+		agents[0].Services = []*proto.Service{{
+			Name:          "web",
+			Command:       "echo hello from web; sleep 300",
+			RestartPolicy: proto.ServiceRestartPolicy_ALWAYS,
+		}}
+		return agents
+	})
+	_ = agenttest.New(t, client.URL, agentToken)
+	resources := wirtualdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+	agentID := resources[0].Agents[0].ID
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	testutil.Eventually(ctx, t, func(ctx context.Context) bool {
+		services, err := client.WorkspaceAgentServices(ctx, agentID)
+		return err == nil && len(services) == 1 && services[0].State == wirtualsdk.WorkspaceAgentServiceRunning
+	}, testutil.IntervalMedium)
+
+	t.Run("List", func(t *testing.T) {
+		inv, root := clitest.New(t, "services", "ls", workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		require.NoError(t, inv.WithContext(ctx).Run())
+		require.Contains(t, out.String(), "web")
+		require.Contains(t, out.String(), "running")
+		require.Contains(t, out.String(), "always")
+	})
+
+	t.Run("Logs", func(t *testing.T) {
+		testutil.Eventually(ctx, t, func(ctx context.Context) bool {
+			inv, root := clitest.New(t, "services", "logs", workspace.Name, "web")
+			clitest.SetupConfig(t, client, root)
+			var out bytes.Buffer
+			inv.Stdout = &out
+			err := inv.WithContext(ctx).Run()
+			return err == nil && strings.Contains(out.String(), "hello from web")
+		}, testutil.IntervalMedium)
+	})
+
+	t.Run("LogsNotFound", func(t *testing.T) {
+		inv, root := clitest.New(t, "services", "logs", workspace.Name, "missing")
+		clitest.SetupConfig(t, client, root)
+		err := inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("Restart", func(t *testing.T) {
+		inv, root := clitest.New(t, "services", "restart", workspace.Name, "web")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		require.NoError(t, inv.WithContext(ctx).Run())
+		require.Contains(t, out.String(), "Restarting service")
+
+		testutil.Eventually(ctx, t, func(ctx context.Context) bool {
+			services, err := client.WorkspaceAgentServices(ctx, agentID)
+			return err == nil && len(services) == 1 &&
+				services[0].RestartCount == 1 &&
+				services[0].State == wirtualsdk.WorkspaceAgentServiceRunning
+		}, testutil.IntervalMedium)
+	})
+}
@@ -29,6 +29,7 @@ func (r *RootCmd) show() *serpent.Command {
 			return cliui.WorkspaceResources(inv.Stdout, workspace.LatestBuild.Resources, cliui.WorkspaceResourcesOptions{
 				WorkspaceName: workspace.Name,
 				ServerVersion: buildInfo.Version,
+				ShowAppHealth: true,
 			})
 		},
 	}
@@ -13,8 +13,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gen2brain/beeep"
@@ -29,9 +31,11 @@ import (
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/pretty"
 	"github.com/coder/quartz"
 	"github.com/coder/retry"
 	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/agent/agentssh"
 	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
 	"github.com/onchainengineering/hmi-wirtual/cli/cliutil"
 	"github.com/onchainengineering/hmi-wirtual/cryptorand"
@@ -67,6 +71,7 @@ func (r *RootCmd) ssh() *serpent.Command {
 		env              []string
 		usageApp         string
 		disableAutostart bool
+		disableResume    bool
 		appearanceConfig wirtualsdk.AppearanceConfig
 	)
 	client := new(wirtualsdk.Client)
@@ -180,10 +185,15 @@ func (r *RootCmd) ssh() *serpent.Command {
 				parsedEnv = append(parsedEnv, [2]string{k, v})
 			}
 
-			workspace, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, !disableAutostart, inv.Args[0])
+			workspace, workspaceAgent, container, err := getWorkspaceAgentAndDevcontainer(ctx, inv, client, !disableAutostart, inv.Args[0])
 			if err != nil {
 				return err
 			}
+			if container != "" && stdio {
+				// The raw SSH connection is set up by the client, which
+				// must ask for the dev container itself.
+				return xerrors.Errorf("dev containers can't be selected with --stdio, add \"SetEnv %s=%s\" to the SSH config of the host instead", agentssh.MagicSessionContainerEnvironmentVariable, container)
+			}
 
 			// Select the startup script behavior based on template configuration or flags.
 			var wait bool
@@ -248,11 +258,18 @@ func (r *RootCmd) ssh() *serpent.Command {
 			if r.disableDirect {
 				_, _ = fmt.Fprintln(inv.Stderr, "Direct connections disabled.")
 			}
+			// The proxy only sets the preferred DERP region, so failing to
+			// select one shouldn't prevent connecting.
+			proxy, err := r.connectWorkspaceProxy(ctx, client)
+			if err != nil {
+				logger.Warn(ctx, "failed to select workspace proxy", slog.Error(err))
+			}
 			conn, err := workspacesdk.New(client).
 				DialAgent(ctx, workspaceAgent.ID, &workspacesdk.DialAgentOptions{
-					Logger:          logger,
-					BlockEndpoints:  r.disableDirect,
-					EnableTelemetry: !r.disableNetworkTelemetry,
+					Logger:                  logger,
+					BlockEndpoints:          r.disableDirect,
+					EnableTelemetry:         !r.disableNetworkTelemetry,
+					PreferredDERPRegionCode: proxyDERPRegionCode(proxy),
 				})
 			if err != nil {
 				return xerrors.Errorf("dial agent: %w", err)
@@ -296,6 +313,12 @@ func (r *RootCmd) ssh() *serpent.Command {
 				return nil
 			}
 
+			// Resuming re-attaches to the same shell on the agent after the
+			// connection is lost. Forwards are bound to the original SSH
+			// connection, so sessions using them are not resumable.
+			resume := !disableResume && !forwardGPG && len(remoteForwards) == 0
+			sessionID := uuid.New()
+
 			sshClient, err := conn.SSHClient(ctx)
 			if err != nil {
 				return xerrors.Errorf("ssh client: %w", err)
@@ -303,13 +326,8 @@ func (r *RootCmd) ssh() *serpent.Command {
 			if err = stack.push("ssh client", sshClient); err != nil {
 				return err
 			}
-
-			sshSession, err := sshClient.NewSession()
-			if err != nil {
-				return xerrors.Errorf("ssh session: %w", err)
-			}
-			if err = stack.push("sshSession", sshSession); err != nil {
-				return err
+			if resume {
+				go sshKeepalive(ctx, logger, sshClient)
 			}
 
 			wg.Add(1)
@@ -330,15 +348,12 @@ func (r *RootCmd) ssh() *serpent.Command {
 			if identityAgent == "" {
 				identityAgent = os.Getenv("SSH_AUTH_SOCK")
 			}
-			if forwardAgent && identityAgent != "" {
+			forwardIdentityAgent := forwardAgent && identityAgent != ""
+			if forwardIdentityAgent {
 				err = gosshagent.ForwardToRemote(sshClient, identityAgent)
 				if err != nil {
 					return xerrors.Errorf("forward agent: %w", err)
 				}
-				err = gosshagent.RequestAgentForwarding(sshSession)
-				if err != nil {
-					return xerrors.Errorf("request agent forwarding failed: %w", err)
-				}
 			}
 
 			if forwardGPG {
@@ -376,6 +391,7 @@ func (r *RootCmd) ssh() *serpent.Command {
 				}
 			}
 
+			var currentSession atomic.Pointer[gossh.Session]
 			stdinFile, validIn := inv.Stdin.(*os.File)
 			stdoutFile, validOut := inv.Stdout.(*os.File)
 			if validIn && validOut && isatty.IsTerminal(stdinFile.Fd()) && isatty.IsTerminal(stdoutFile.Fd()) {
@@ -406,44 +422,163 @@ func (r *RootCmd) ssh() *serpent.Command {
 						if err != nil {
 							continue
 						}
-						_ = sshSession.WindowChange(height, width)
+						if sshSession := currentSession.Load(); sshSession != nil {
+							_ = sshSession.WindowChange(height, width)
+						}
 					}
 				}()
 			}
 
-			for _, kv := range parsedEnv {
-				if err := sshSession.Setenv(kv[0], kv[1]); err != nil {
-					return xerrors.Errorf("setenv: %w", err)
-				}
+			var (
+				stdin  *resumableStdin
+				stdout *countingWriter
+			)
+			if resume {
+				stdin = newResumableStdin(inv.Stdin)
+				stdout = &countingWriter{w: inv.Stdout}
 			}
+			// startSession starts a shell on sshClient. The returned channel
+			// is closed once the session has ended.
+			startSession := func(sshClient *gossh.Client) (*gossh.Session, chan struct{}, error) {
+				sshSession, err := sshClient.NewSession()
+				if err != nil {
+					return nil, nil, xerrors.Errorf("ssh session: %w", err)
+				}
+				if err = stack.push("sshSession", sshSession); err != nil {
+					return nil, nil, err
+				}
 
-			err = sshSession.RequestPty("xterm-256color", 128, 128, gossh.TerminalModes{})
-			if err != nil {
-				return xerrors.Errorf("request pty: %w", err)
+				if forwardIdentityAgent {
+					err = gosshagent.RequestAgentForwarding(sshSession)
+					if err != nil {
+						return nil, nil, xerrors.Errorf("request agent forwarding failed: %w", err)
+					}
+				}
+
+				for _, kv := range parsedEnv {
+					if err := sshSession.Setenv(kv[0], kv[1]); err != nil {
+						return nil, nil, xerrors.Errorf("setenv: %w", err)
+					}
+				}
+				if resume {
+					err = sshSession.Setenv(agentssh.MagicSessionIDEnvironmentVariable, sessionID.String())
+					if err != nil {
+						return nil, nil, xerrors.Errorf("setenv: %w", err)
+					}
+					// Only the output that was missed is replayed.
+					if offset := stdout.n.Load(); offset > 0 {
+						err = sshSession.Setenv(agentssh.MagicSessionOffsetEnvironmentVariable, strconv.FormatInt(offset, 10))
+						if err != nil {
+							return nil, nil, xerrors.Errorf("setenv: %w", err)
+						}
+					}
+				}
+				if container != "" {
+					err = sshSession.Setenv(agentssh.MagicSessionContainerEnvironmentVariable, container)
+					if err != nil {
+						return nil, nil, xerrors.Errorf("setenv: %w", err)
+					}
+				}
+
+				err = sshSession.RequestPty("xterm-256color", 128, 128, gossh.TerminalModes{})
+				if err != nil {
+					return nil, nil, xerrors.Errorf("request pty: %w", err)
+				}
+
+				sessionDone := make(chan struct{})
+				if resume {
+					stdinPipe, err := sshSession.StdinPipe()
+					if err != nil {
+						return nil, nil, xerrors.Errorf("stdin pipe: %w", err)
+					}
+					go stdin.pipeTo(stdinPipe, sessionDone)
+					sshSession.Stdout = stdout
+				} else {
+					sshSession.Stdin = inv.Stdin
+					sshSession.Stdout = inv.Stdout
+				}
+				sshSession.Stderr = inv.Stderr
+
+				err = sshSession.Shell()
+				if err != nil {
+					return nil, nil, xerrors.Errorf("start shell: %w", err)
+				}
+				currentSession.Store(sshSession)
+
+				if validOut {
+					// Set initial window size.
+					width, height, err := term.GetSize(int(stdoutFile.Fd()))
+					if err == nil {
+						_ = sshSession.WindowChange(height, width)
+					}
+				}
+				return sshSession, sessionDone, nil
 			}
 
-			sshSession.Stdin = inv.Stdin
-			sshSession.Stdout = inv.Stdout
-			sshSession.Stderr = inv.Stderr
+			// reconnect waits for the agent to become reachable again and
+			// starts a new session attached to the same shell.
+			reconnect := func() (*gossh.Session, chan struct{}, error) {
+				resumeCtx, resumeCancel := context.WithTimeout(ctx, sshResumeTimeout)
+				defer resumeCancel()
+				var lastErr error
+				for r := retry.New(250*time.Millisecond, 5*time.Second); r.Wait(resumeCtx); {
+					if stack.isClosed() {
+						return nil, nil, xerrors.New("connection closed")
+					}
+					sshClient, err := conn.SSHClient(resumeCtx)
+					if err != nil {
+						lastErr = err
+						logger.Debug(ctx, "failed to reconnect ssh client", slog.Error(err))
+						continue
+					}
+					if err = stack.push("ssh client", sshClient); err != nil {
+						return nil, nil, err
+					}
+					if forwardIdentityAgent {
+						err = gosshagent.ForwardToRemote(sshClient, identityAgent)
+						if err != nil {
+							return nil, nil, xerrors.Errorf("forward agent: %w", err)
+						}
+					}
+					sshSession, sessionDone, err := startSession(sshClient)
+					if err != nil {
+						lastErr = err
+						logger.Debug(ctx, "failed to resume ssh session", slog.Error(err))
+						_ = sshClient.Close()
+						continue
+					}
+					go sshKeepalive(ctx, logger, sshClient)
+					return sshSession, sessionDone, nil
+				}
+				if lastErr == nil {
+					lastErr = resumeCtx.Err()
+				}
+				return nil, nil, xerrors.Errorf("resume session: %w", lastErr)
+			}
 
-			err = sshSession.Shell()
+			sshSession, sessionDone, err := startSession(sshClient)
 			if err != nil {
-				return xerrors.Errorf("start shell: %w", err)
+				return err
 			}
 
 			// Put cancel at the top of the defer stack to initiate
 			// shutdown of services.
 			defer cancel()
 
-			if validOut {
-				// Set initial window size.
-				width, height, err := term.GetSize(int(stdoutFile.Fd()))
-				if err == nil {
-					_ = sshSession.WindowChange(height, width)
+			for {
+				err = sshSession.Wait()
+				close(sessionDone)
+				if !resume || ctx.Err() != nil || stack.isClosed() || !isSSHConnectionLost(err) {
+					break
+				}
+				logger.Info(ctx, "ssh connection lost, resuming session", slog.Error(err))
+				_, _ = fmt.Fprint(inv.Stderr, "\r\n"+pretty.Sprint(cliui.DefaultStyles.Warn, "Connection lost, reconnecting...")+"\r\n")
+				sshSession, sessionDone, err = reconnect()
+				if err != nil {
+					return ExitError(255, xerrors.Errorf("SSH connection ended unexpectedly: %w", err))
 				}
+				_, _ = fmt.Fprint(inv.Stderr, pretty.Sprint(cliui.DefaultStyles.Keyword, "Reconnected.")+"\r\n")
 			}
-
-			err = sshSession.Wait()
 			conn.SendDisconnectedTelemetry()
 			if err != nil {
 				if exitErr := (&gossh.ExitError{}); errors.As(err, &exitErr) {
@@ -541,6 +676,12 @@ func (r *RootCmd) ssh() *serpent.Command {
 			Hidden:      true,
 		},
 		sshDisableAutostartOption(serpent.BoolOf(&disableAutostart)),
+		{
+			Flag:        "disable-resume",
+			Description: "Disable resuming the session when the connection is lost, e.g. after switching networks. Sessions using --forward-gpg or --remote-forward are never resumed.",
+			Env:         "WIRTUAL_SSH_DISABLE_RESUME",
+			Value:       serpent.BoolOf(&disableResume),
+		},
 	}
 	return cmd
 }
@@ -700,6 +841,48 @@ func getWorkspaceAndAgent(ctx context.Context, inv *serpent.Invocation, client *
 	return workspace, workspaceAgent, nil
 }
 
+// getWorkspaceAgentAndDevcontainer is like getWorkspaceAndAgent, but the
+// input may also name a dev container, as `workspace.agent.container` or
+// `workspace.container`. The latter is looked up in the dev containers of
+// the connected agents if no agent has the name.
+func getWorkspaceAgentAndDevcontainer(ctx context.Context, inv *serpent.Invocation, client *wirtualsdk.Client, autostart bool, input string) (wirtualsdk.Workspace, wirtualsdk.WorkspaceAgent, string, error) { //nolint:revive
+	parts := strings.Split(input, ".")
+	container := ""
+	if len(parts) == 3 {
+		input, container = parts[0]+"."+parts[1], parts[2]
+	}
+	workspace, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, autostart, input)
+	if len(parts) != 2 || !xerrors.Is(err, errAgentNotFound) {
+		return workspace, workspaceAgent, container, err
+	}
+
+	workspace, _, err = getWorkspaceAndAgent(ctx, inv, client, autostart, parts[0])
+	if err != nil {
+		return wirtualsdk.Workspace{}, wirtualsdk.WorkspaceAgent{}, "", err
+	}
+	for _, resource := range workspace.LatestBuild.Resources {
+		for _, agent := range resource.Agents {
+			if agent.Status != wirtualsdk.WorkspaceAgentConnected {
+				continue
+			}
+			res, err := client.WorkspaceAgentDevcontainers(ctx, agent.ID)
+			if err != nil {
+				continue
+			}
+			for _, devcontainer := range res.Devcontainers {
+				if devcontainer.Name == parts[1] {
+					return workspace, agent, devcontainer.Name, nil
+				}
+			}
+		}
+	}
+	return wirtualsdk.Workspace{}, wirtualsdk.WorkspaceAgent{}, "", xerrors.Errorf("no agent or dev container named %q found in workspace %q", parts[1], workspace.Name)
+}
+
+// errAgentNotFound is returned when the workspace has no agent with the
+// requested name.
+var errAgentNotFound = xerrors.New("agent not found")
+
 func getWorkspaceAgent(workspace wirtualsdk.Workspace, agentName string) (workspaceAgent wirtualsdk.WorkspaceAgent, err error) {
 	resources := workspace.LatestBuild.Resources
 
@@ -719,7 +902,7 @@ func getWorkspaceAgent(workspace wirtualsdk.Workspace, agentName string) (worksp
 			break
 		}
 		if workspaceAgent.ID == uuid.Nil {
-			return wirtualsdk.WorkspaceAgent{}, xerrors.Errorf("agent not found by name %q", agentName)
+			return wirtualsdk.WorkspaceAgent{}, xerrors.Errorf("%w by name %q", errAgentNotFound, agentName)
 		}
 	}
 	if workspaceAgent.ID == uuid.Nil {
@@ -1023,6 +1206,12 @@ func (c *closerStack) close(err error) {
 	}
 }
 
+func (c *closerStack) isClosed() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.closed
+}
+
 func (c *closerStack) push(name string, closer io.Closer) error {
 	c.Lock()
 	if c.closed {
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+
+	"cdr.dev/slog"
+)
+
+var (
+	// sshKeepaliveInterval is how often a keepalive is sent on resumable
+	// sessions to detect dead connections.
+	sshKeepaliveInterval = 5 * time.Second
+	// sshKeepaliveTimeout is how long to wait for a keepalive response before
+	// considering the connection lost.
+	sshKeepaliveTimeout = 15 * time.Second
+	// sshResumeTimeout is how long to attempt to resume a session for. It
+	// matches the default time the agent keeps a detached shell alive.
+	sshResumeTimeout = 5 * time.Minute
+)
+
+// sshKeepalive periodically sends keepalive requests on client and closes it
+// if a request fails or goes unanswered for sshKeepaliveTimeout. A TCP
+// connection over a changed network path can otherwise hang for minutes
+// before returning an error.
+func sshKeepalive(ctx context.Context, logger slog.Logger, client *gossh.Client) {
+	ticker := time.NewTicker(sshKeepaliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+			errCh <- err
+		}()
+		timer := time.NewTimer(sshKeepaliveTimeout)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case err := <-errCh:
+			timer.Stop()
+			if err == nil {
+				continue
+			}
+			logger.Debug(ctx, "ssh keepalive failed", slog.Error(err))
+		case <-timer.C:
+			logger.Debug(ctx, "ssh keepalive timed out")
+		}
+		_ = client.Close()
+		return
+	}
+}
+
+// isSSHConnectionLost returns whether a session ended because the
+// connection was lost, rather than because the remote command exited.
+func isSSHConnectionLost(err error) bool {
+	if err == nil {
+		return false
+	}
+	var exitErr *gossh.ExitError
+	return !errors.As(err, &exitErr)
+}
+
+// resumableStdin forwards stdin to the current SSH session. Input is read by a
+// single goroutine, so no keystrokes are lost to a session whose connection
+// died; a chunk that failed to write is sent to the next session instead.
+type resumableStdin struct {
+	mu      sync.Mutex
+	pending []byte
+	input   chan []byte
+}
+
+func newResumableStdin(r io.Reader) *resumableStdin {
+	s := &resumableStdin{input: make(chan []byte)}
+	go func() {
+		defer close(s.input)
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := r.Read(buf)
+			if n > 0 {
+				s.input <- buf[:n]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// pipeTo copies input to w until stdin is closed, a write fails, or done is
+// closed. When stdin is closed, w is closed as well.
+func (s *resumableStdin) pipeTo(w io.WriteCloser, done <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending != nil {
+		if _, err := w.Write(s.pending); err != nil {
+			return
+		}
+		s.pending = nil
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case b, ok := <-s.input:
+			if !ok {
+				_ = w.Close()
+				return
+			}
+			if _, err := w.Write(b); err != nil {
+				s.pending = b
+				return
+			}
+		}
+	}
+}
+
+// countingWriter counts the bytes written to w. A resumed session continues
+// the output of the remote shell from there.
+type countingWriter struct {
+	w io.Writer
+	n atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
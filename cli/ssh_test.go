@@ -1010,6 +1010,48 @@ func TestSSH(t *testing.T) {
 		pty.WriteLine("exit")
 	})
 
+	t.Run("Resume", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Test not supported on windows")
+		}
+
+		t.Parallel()
+
+		client, workspace, agentToken := setupWorkspaceForAgent(t)
+		// With a timeout this short, the agent closes SSH connections without
+		// ending their sessions, as if the network dropped them.
+		_ = agenttest.New(t, client.URL, agentToken, func(o *agent.Options) {
+			o.SSHMaxTimeout = 2 * time.Second
+		})
+		wirtualdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+		inv, root := clitest.New(t, "ssh", workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t).Attach(inv)
+		inv.Stderr = pty.Output()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitSuperLong)
+		defer cancel()
+
+		cmdDone := tGo(t, func() {
+			err := inv.WithContext(ctx).Run()
+			// The exit status of the shell is kept across resumes.
+			assert.EqualError(t, err, "exit code 3")
+		})
+
+		pty.WriteLine("x=$((40+2)); echo marker-$x")
+		pty.ExpectMatchContext(ctx, "marker-42")
+		pty.ExpectMatchContext(ctx, "Reconnected.")
+
+		// The same shell keeps running, and the output that was already
+		// received isn't replayed.
+		pty.WriteLine("echo resumed-$x")
+		pty.ExpectNoMatchBefore(ctx, "marker-42", "resumed-42")
+
+		pty.WriteLine("exit 3")
+		<-cmdDone
+	})
+
 	t.Run("RemoteForwardUnixSocket", func(t *testing.T) {
 		if runtime.GOOS == "windows" {
 			t.Skip("Test not supported on windows")
@@ -49,6 +49,7 @@ func (r *RootCmd) stat() *serpent.Command {
 			r.statCPU(fs),
 			r.statMem(fs),
 			r.statDisk(fs),
+			r.statLimits(fs),
 		},
 		Handler: func(inv *serpent.Invocation) error {
 			var sr statsRow
@@ -283,6 +284,34 @@ func (*RootCmd) statDisk(fs afero.Fs) *serpent.Command {
 	return cmd
 }
 
+func (*RootCmd) statLimits(fs afero.Fs) *serpent.Command {
+	var (
+		st        *clistat.Statter
+		formatter = cliui.NewOutputFormatter(cliui.TextFormat(), cliui.JSONFormat())
+	)
+	cmd := &serpent.Command{
+		Use:        "limits",
+		Short:      "Show the cgroup limits of the current session.",
+		Long:       "Limits set by the template on SSH sessions, terminals and scripts are included. Only cgroup v2 is supported.",
+		Middleware: initStatterMW(&st, fs),
+		Handler: func(inv *serpent.Invocation) error {
+			limits, err := st.CgroupLimits()
+			if err != nil {
+				return err
+			}
+			out, err := formatter.Format(inv.Context(), limits)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
 type statsRow struct {
 	HostCPU         *clistat.Result `json:"host_cpu" table:"host cpu,default_sort"`
 	HostMemory      *clistat.Result `json:"host_memory" table:"host memory"`
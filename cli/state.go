@@ -5,7 +5,13 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/pretty"
 	"github.com/coder/serpent"
 	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
@@ -21,6 +27,9 @@ func (r *RootCmd) state() *serpent.Command {
 		Children: []*serpent.Command{
 			r.statePull(),
 			r.statePush(),
+			r.stateHistory(),
+			r.stateDiff(),
+			r.stateRestore(),
 		},
 	}
 	return cmd
@@ -139,3 +148,192 @@ func (r *RootCmd) statePush() *serpent.Command {
 	}
 	return cmd
 }
+
+type workspaceStateRow struct {
+	// For json format:
+	State wirtualsdk.WorkspaceState `table:"-"`
+
+	// For table format:
+	Build      int32                          `json:"-" table:"build"`
+	CreatedAt  time.Time                      `json:"-" table:"created at,default_sort"`
+	Transition wirtualsdk.WorkspaceTransition `json:"-" table:"transition"`
+	Initiator  string                         `json:"-" table:"initiator"`
+	Resources  string                         `json:"-" table:"resources"`
+	Size       string                         `json:"-" table:"size"`
+}
+
+func (r *RootCmd) stateHistory() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]workspaceStateRow{}, []string{"build", "created at", "transition", "initiator", "resources", "size"}),
+		cliui.JSONFormat(),
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "history <workspace>",
+		Short: "List the Terraform states stored by the builds of a workspace.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			if err != nil {
+				return err
+			}
+			states, err := client.WorkspaceStates(inv.Context(), workspace.ID)
+			if err != nil {
+				return xerrors.Errorf("get workspace states: %w", err)
+			}
+
+			rows := make([]workspaceStateRow, 0, len(states))
+			for _, state := range states {
+				row := workspaceStateRow{
+					State:      state,
+					Build:      state.BuildNumber,
+					CreatedAt:  state.CreatedAt,
+					Transition: state.Transition,
+					Initiator:  state.InitiatorName,
+					Resources:  "-",
+					Size:       humanize.Bytes(uint64(state.Size)),
+				}
+				if state.ResourceCount != nil {
+					row.Resources = strconv.Itoa(*state.ResourceCount)
+				}
+				if state.Size == 0 {
+					row.Size = cliui.Placeholder("removed")
+				}
+				rows = append(rows, row)
+			}
+
+			out, err := formatter.Format(inv.Context(), rows)
+			if err != nil {
+				return xerrors.Errorf("render table: %w", err)
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) stateDiff() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			diff, ok := data.(wirtualsdk.WorkspaceStateDiff)
+			if !ok {
+				return nil, xerrors.Errorf("expected WorkspaceStateDiff, got %T", data)
+			}
+			return formatStateDiff(diff), nil
+		}),
+		cliui.JSONFormat(),
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "diff <workspace> <from-build> <to-build>",
+		Short: "Compare the Terraform states of two builds of a workspace.",
+		Long: "Only the names of changed attributes are shown, since their values may be sensitive.\n" + FormatExamples(
+			Example{
+				Description: "Compare the states of builds 3 and 4 of a workspace",
+				Command:     "coder state diff my-workspace 3 4",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(3),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			from, err := strconv.ParseInt(inv.Args[1], 10, 32)
+			if err != nil {
+				return xerrors.Errorf("parse build number %q: %w", inv.Args[1], err)
+			}
+			to, err := strconv.ParseInt(inv.Args[2], 10, 32)
+			if err != nil {
+				return xerrors.Errorf("parse build number %q: %w", inv.Args[2], err)
+			}
+			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			if err != nil {
+				return err
+			}
+			diff, err := client.WorkspaceStateDiff(inv.Context(), workspace.ID, int32(from), int32(to))
+			if err != nil {
+				return xerrors.Errorf("compare workspace states: %w", err)
+			}
+
+			out, err := formatter.Format(inv.Context(), diff)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func formatStateDiff(diff wirtualsdk.WorkspaceStateDiff) string {
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		return fmt.Sprintf("The states of build %d and build %d have the same resources.", diff.FromBuildNumber, diff.ToBuildNumber)
+	}
+	var sb strings.Builder
+	for _, address := range diff.Added {
+		_, _ = fmt.Fprintln(&sb, cliui.Keyword("+ "+address))
+	}
+	for _, address := range diff.Removed {
+		_, _ = fmt.Fprintln(&sb, pretty.Sprint(cliui.DefaultStyles.Error, "- "+address))
+	}
+	for _, change := range diff.Changed {
+		_, _ = fmt.Fprintf(&sb, "%s (%s)\n", pretty.Sprint(cliui.DefaultStyles.Warn, "~ "+change.Address), strings.Join(change.Attributes, ", "))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (r *RootCmd) stateRestore() *serpent.Command {
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "restore <workspace> <build>",
+		Short: "Restore the Terraform state of an earlier build of a workspace.",
+		Long: "The new build repeats the latest build of the workspace with the restored state.\n" + FormatExamples(
+			Example{
+				Description: "Restore the state of build 3 of a workspace",
+				Command:     "coder state restore my-workspace 3",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			buildNumber, err := strconv.ParseInt(inv.Args[1], 10, 32)
+			if err != nil {
+				return xerrors.Errorf("parse build number %q: %w", inv.Args[1], err)
+			}
+			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			if err != nil {
+				return err
+			}
+
+			_, err = cliui.Prompt(inv, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Restore the state of build %d of %s?", buildNumber, workspace.FullName()),
+				IsConfirm: true,
+				Default:   cliui.ConfirmNo,
+			})
+			if err != nil {
+				return err
+			}
+
+			build, err := client.RestoreWorkspaceState(inv.Context(), workspace.ID, wirtualsdk.RestoreWorkspaceStateRequest{
+				BuildNumber: int32(buildNumber),
+			})
+			if err != nil {
+				return err
+			}
+			return cliui.WorkspaceBuild(inv.Context(), inv.Stderr, client, build.ID)
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		cliui.SkipPromptOption(),
+	}
+	return cmd
+}
@@ -2,6 +2,7 @@ package cli_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,6 +19,7 @@ import (
 	"github.com/onchainengineering/hmi-wirtual/provisioner/echo"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/rbac"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
 )
 
 func TestStatePull(t *testing.T) {
@@ -159,3 +161,88 @@ func TestStatePush(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestStateHistory(t *testing.T) {
+	t.Parallel()
+	client, store := wirtualdtest.NewWithDatabase(t, nil)
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	templateAdmin, taUser := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID, rbac.RoleTemplateAdmin())
+	r := dbfake.WorkspaceBuild(t, store, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        taUser.ID,
+	}).
+		Seed(database.WorkspaceBuild{ProvisionerState: []byte(testStateV1)}).
+		Do()
+	_ = dbfake.WorkspaceBuild(t, store, r.Workspace).
+		Seed(database.WorkspaceBuild{BuildNumber: 2, ProvisionerState: []byte(testStateV2)}).
+		Do()
+
+	t.Run("History", func(t *testing.T) {
+		t.Parallel()
+		inv, root := clitest.New(t, "state", "history", r.Workspace.Name, "--output", "json")
+		var out bytes.Buffer
+		inv.Stdout = &out
+		clitest.SetupConfig(t, templateAdmin, root)
+		err := inv.Run()
+		require.NoError(t, err)
+
+		var rows []struct {
+			State wirtualsdk.WorkspaceState
+		}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &rows))
+		require.Len(t, rows, 2)
+		counts := map[int32]int{}
+		for _, row := range rows {
+			require.NotNil(t, row.State.ResourceCount)
+			counts[row.State.BuildNumber] = *row.State.ResourceCount
+		}
+		require.Equal(t, map[int32]int{1: 1, 2: 2}, counts)
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		t.Parallel()
+		inv, root := clitest.New(t, "state", "diff", r.Workspace.Name, "1", "2")
+		var out bytes.Buffer
+		inv.Stdout = &out
+		clitest.SetupConfig(t, templateAdmin, root)
+		err := inv.Run()
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "+ null_resource.extra")
+		require.Contains(t, out.String(), "~ null_resource.dev (triggers)")
+	})
+}
+
+const testStateV1 = `{
+  "version": 4,
+  "terraform_version": "1.9.8",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "null_resource",
+      "name": "dev",
+      "provider": "provider[\"registry.terraform.io/hashicorp/null\"]",
+      "instances": [{"schema_version": 0, "attributes": {"id": "1", "triggers": {"a": "1"}}}]
+    }
+  ]
+}`
+
+const testStateV2 = `{
+  "version": 4,
+  "terraform_version": "1.9.8",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "null_resource",
+      "name": "dev",
+      "provider": "provider[\"registry.terraform.io/hashicorp/null\"]",
+      "instances": [{"schema_version": 0, "attributes": {"id": "1", "triggers": {"a": "2"}}}]
+    },
+    {
+      "mode": "managed",
+      "type": "null_resource",
+      "name": "extra",
+      "provider": "provider[\"registry.terraform.io/hashicorp/null\"]",
+      "instances": [{"schema_version": 0, "attributes": {"id": "2"}}]
+    }
+  ]
+}`
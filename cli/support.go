@@ -274,6 +274,7 @@ func writeBundle(src *support.Bundle, dest *zip.Writer) error {
 		"agent/manifest.json":             src.Agent.Manifest,
 		"agent/peer_diagnostics.json":     src.Agent.PeerDiagnostics,
 		"agent/ping_result.json":          src.Agent.PingResult,
+		"agent/conn_diagnostics.json":     src.Agent.ConnDiagnostics,
 		"deployment/buildinfo.json":       src.Deployment.BuildInfo,
 		"deployment/config.json":          src.Deployment.Config,
 		"deployment/experiments.json":     src.Deployment.Experiments,
@@ -359,6 +359,14 @@ func assertBundleContents(t *testing.T, path string, wantWorkspace bool, wantAge
 				continue
 			}
 			require.NotEmpty(t, v, "ping result should not be empty")
+		case "agent/conn_diagnostics.json":
+			var v *tailnet.ConnDiagnostics
+			decodeJSONFromZip(t, f, &v)
+			if !wantAgent {
+				require.Empty(t, v, "expected connection diagnostics to be empty")
+				continue
+			}
+			require.NotEmpty(t, v, "connection diagnostics should not be empty")
 		case "agent/prometheus.txt":
 			bs := readBytesFromZip(t, f)
 			if !wantAgent {
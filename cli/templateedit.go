@@ -11,6 +11,7 @@ import (
 	"github.com/coder/serpent"
 
 	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/util/ptr"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
 )
 
@@ -35,6 +36,7 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 		requireActiveVersion           bool
 		deprecationMessage             string
 		disableEveryone                bool
+		jobTimeout                     time.Duration
 		orgContext                     = NewOrganizationContext()
 	)
 	client := new(wirtualsdk.Client)
@@ -166,6 +168,11 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 				disableEveryoneGroup = disableEveryone
 			}
 
+			var jobTimeoutMillis *int64
+			if userSetOption(inv, "job-timeout") {
+				jobTimeoutMillis = ptr.Ref(jobTimeout.Milliseconds())
+			}
+
 			req := wirtualsdk.UpdateTemplateMeta{
 				Name:               name,
 				DisplayName:        displayName,
@@ -189,6 +196,7 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 				RequireActiveVersion:           requireActiveVersion,
 				DeprecationMessage:             deprecated,
 				DisableEveryoneGroupAccess:     disableEveryoneGroup,
+				JobTimeoutMillis:               jobTimeoutMillis,
 			}
 
 			_, err = client.UpdateTemplateMeta(inv.Context(), template.ID, req)
@@ -294,6 +302,11 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 			Value:       serpent.BoolOf(&requireActiveVersion),
 			Default:     "false",
 		},
+		{
+			Flag:        "job-timeout",
+			Description: "Specify the maximum duration of a workspace build. Builds that run longer are canceled and fail. Set to 0 to remove the timeout.",
+			Value:       serpent.DurationOf(&jobTimeout),
+		},
 		{
 			Flag: "private",
 			Description: "Disable the default behavior of granting template access to the 'everyone' group. " +
@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func (r *RootCmd) templateGitSource() *serpent.Command {
+	cmd := &serpent.Command{
+		Use:   "git-source",
+		Short: "Sync a template from a git repository.",
+		Long: "New versions of the template are created from new commits to the repository. The repository is polled for new commits, and is synced immediately when a push webhook is received.\n" + FormatExamples(
+			Example{
+				Description: "Sync a template from the main branch of a repository",
+				Command:     "coder templates git-source set my-template https://github.com/example/templates.git --path docker --ref main --external-auth-provider github --auto-promote",
+			},
+			Example{
+				Description: "Show the sync status of a template",
+				Command:     "coder templates git-source show my-template",
+			},
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.templateGitSourceShow(),
+			r.templateGitSourceSet(),
+			r.templateGitSourceSync(),
+			r.templateGitSourceRemove(),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) templateGitSourceShow() *serpent.Command {
+	orgContext := NewOrganizationContext()
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			source, ok := data.(wirtualsdk.TemplateGitSource)
+			if !ok {
+				return nil, xerrors.Errorf("expected wirtualsdk.TemplateGitSource, got %T", data)
+			}
+			return formatTemplateGitSource(source), nil
+		}),
+		cliui.JSONFormat(),
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "show <template>",
+		Short: "Show the git repository of a template and the status of its last sync.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			organization, err := orgContext.Selected(inv, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			source, err := client.TemplateGitSource(inv.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("get template git source: %w", err)
+			}
+
+			out, err := formatter.Format(inv.Context(), source)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	orgContext.AttachOptions(cmd)
+	return cmd
+}
+
+func (r *RootCmd) templateGitSourceSet() *serpent.Command {
+	var (
+		orgContext           = NewOrganizationContext()
+		path                 string
+		ref                  string
+		externalAuthProvider string
+		deployKey            bool
+		knownHostsFile       string
+		autoPromote          bool
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "set <template> <repo-url>",
+		Short: "Sync a template from a git repository.",
+		Long:  "Replaces the git repository of the template if it's already synced. The deploy key and webhook secret are kept.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			organization, err := orgContext.Selected(inv, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			var knownHosts []byte
+			if knownHostsFile != "" {
+				knownHosts, err = os.ReadFile(knownHostsFile)
+				if err != nil {
+					return xerrors.Errorf("read known hosts: %w", err)
+				}
+			}
+			source, err := client.UpdateTemplateGitSource(inv.Context(), template.ID, wirtualsdk.UpdateTemplateGitSourceRequest{
+				RepoURL:                inv.Args[1],
+				Path:                   path,
+				Ref:                    ref,
+				ExternalAuthProviderID: externalAuthProvider,
+				UseDeployKey:           deployKey,
+				KnownHosts:             string(knownHosts),
+				AutoPromote:            autoPromote,
+			})
+			if err != nil {
+				return xerrors.Errorf("update template git source: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Template %s is synced from %s.\n\n",
+				pretty.Sprint(cliui.DefaultStyles.Keyword, template.Name),
+				pretty.Sprint(cliui.DefaultStyles.Code, source.RepoURL),
+			)
+			if source.DeployKey != "" {
+				_, _ = fmt.Fprintf(inv.Stdout, "Add this read-only deploy key to the repository:\n\n  %s\n\n", source.DeployKey)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "To sync on every push, add a webhook for push events:\n\n  URL:    %s\n  Secret: %s\n",
+				source.WebhookURL, source.WebhookSecret,
+			)
+			return nil
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "path",
+			Description: "The directory of the template within the repository.",
+			Value:       serpent.StringOf(&path),
+		},
+		{
+			Flag:        "ref",
+			Description: "The branch or tag to sync. Defaults to the default branch of the repository.",
+			Value:       serpent.StringOf(&ref),
+		},
+		{
+			Flag:        "external-auth-provider",
+			Description: "The ID of the external auth provider to fetch the repository with your credentials.",
+			Value:       serpent.StringOf(&externalAuthProvider),
+		},
+		{
+			Flag:        "deploy-key",
+			Description: "Fetch the repository over SSH with a generated deploy key.",
+			Value:       serpent.BoolOf(&deployKey),
+		},
+		{
+			Flag:        "known-hosts",
+			Description: "A file with the SSH host keys of the repository host, like the output of ssh-keyscan. Required for SSH URLs.",
+			Value:       serpent.StringOf(&knownHostsFile),
+		},
+		{
+			Flag:        "auto-promote",
+			Description: "Promote new template versions to active after a successful dry run.",
+			Value:       serpent.BoolOf(&autoPromote),
+		},
+	}
+	orgContext.AttachOptions(cmd)
+	return cmd
+}
+
+func (r *RootCmd) templateGitSourceSync() *serpent.Command {
+	orgContext := NewOrganizationContext()
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "sync <template>",
+		Short: "Check the git repository of a template for new commits now.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			organization, err := orgContext.Selected(inv, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			err = client.SyncTemplateGitSource(inv.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("sync template git source: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "The repository of template %s will be checked for new commits shortly.\n",
+				pretty.Sprint(cliui.DefaultStyles.Keyword, template.Name),
+			)
+			return nil
+		},
+	}
+	orgContext.AttachOptions(cmd)
+	return cmd
+}
+
+func (r *RootCmd) templateGitSourceRemove() *serpent.Command {
+	orgContext := NewOrganizationContext()
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "remove <template>",
+		Short: "Stop syncing a template from git. Existing versions are kept.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Options: serpent.OptionSet{
+			cliui.SkipPromptOption(),
+		},
+		Handler: func(inv *serpent.Invocation) error {
+			organization, err := orgContext.Selected(inv, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			_, err = cliui.Prompt(inv, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Stop syncing template %s from git?", pretty.Sprint(cliui.DefaultStyles.Code, template.Name)),
+				IsConfirm: true,
+				Default:   cliui.ConfirmNo,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = client.DeleteTemplateGitSource(inv.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("delete template git source: %w", err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Template %s is no longer synced from git.\n",
+				pretty.Sprint(cliui.DefaultStyles.Keyword, template.Name),
+			)
+			return nil
+		},
+	}
+	orgContext.AttachOptions(cmd)
+	return cmd
+}
+
+func formatTemplateGitSource(source wirtualsdk.TemplateGitSource) string {
+	var sb strings.Builder
+	row := func(name, value string) {
+		_, _ = fmt.Fprintf(&sb, "%-16s%s\n", name+":", value)
+	}
+	placeholder := func(value, fallback string) string {
+		if value == "" {
+			return cliui.Placeholder(fallback)
+		}
+		return value
+	}
+
+	row("Repository", source.RepoURL)
+	row("Path", placeholder(source.Path, "(root)"))
+	row("Ref", placeholder(source.Ref, "(default branch)"))
+	switch {
+	case source.ExternalAuthProviderID != "":
+		row("Auth", "external auth "+source.ExternalAuthProviderID)
+	case source.DeployKey != "":
+		row("Auth", "deploy key")
+		row("Deploy key", source.DeployKey)
+	default:
+		row("Auth", cliui.Placeholder("(none)"))
+	}
+	row("Auto promote", fmt.Sprint(source.AutoPromote))
+	row("Webhook URL", source.WebhookURL)
+	row("Webhook secret", source.WebhookSecret)
+
+	status := string(source.Status)
+	switch source.Status {
+	case wirtualsdk.TemplateGitSyncStatusFailed:
+		status = pretty.Sprint(cliui.DefaultStyles.Error, status)
+	case wirtualsdk.TemplateGitSyncStatusSucceeded:
+		status = pretty.Sprint(cliui.DefaultStyles.Keyword, status)
+	}
+	row("Status", status)
+	if source.Error != "" {
+		row("Error", source.Error)
+	}
+	row("Last commit", placeholder(source.LastCommitSHA, "(none)"))
+	if source.LastCheckedAt != nil {
+		row("Last checked", source.LastCheckedAt.Format("2006-01-02 15:04:05"))
+	}
+	if source.LastSyncedAt != nil {
+		row("Last synced", source.LastSyncedAt.Format("2006-01-02 15:04:05"))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
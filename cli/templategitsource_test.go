@@ -0,0 +1,75 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestTemplateGitSource(t *testing.T) {
+	t.Parallel()
+
+	client := wirtualdtest.New(t, &wirtualdtest.Options{IncludeProvisionerDaemon: true})
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	version := wirtualdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+	_ = wirtualdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+	template := wirtualdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+	ctx := testutil.Context(t, testutil.WaitMedium)
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	err := os.WriteFile(knownHosts, []byte("example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\n"), 0o600)
+	require.NoError(t, err)
+
+	inv, root := clitest.New(t, "templates", "git-source", "set", template.Name, "git@example.com:templates.git", "--path", "docker", "--deploy-key", "--known-hosts", knownHosts, "--auto-promote")
+	clitest.SetupConfig(t, client, root)
+	var out bytes.Buffer
+	inv.Stdout = &out
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	source, err := client.TemplateGitSource(ctx, template.ID)
+	require.NoError(t, err)
+	require.Equal(t, "git@example.com:templates.git", source.RepoURL)
+	require.Equal(t, "docker", source.Path)
+	require.True(t, source.AutoPromote)
+	require.Contains(t, out.String(), source.DeployKey)
+	require.Contains(t, out.String(), source.WebhookURL)
+	require.Contains(t, out.String(), source.WebhookSecret)
+
+	inv, root = clitest.New(t, "templates", "git-source", "show", template.Name, "-o", "json")
+	clitest.SetupConfig(t, client, root)
+	out.Reset()
+	inv.Stdout = &out
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	var shown wirtualsdk.TemplateGitSource
+	err = json.Unmarshal(out.Bytes(), &shown)
+	require.NoError(t, err)
+	require.Equal(t, source.TemplateID, shown.TemplateID)
+	require.Equal(t, source.DeployKey, shown.DeployKey)
+
+	inv, root = clitest.New(t, "templates", "git-source", "sync", template.Name)
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	inv, root = clitest.New(t, "templates", "git-source", "remove", template.Name, "--yes")
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	_, err = client.TemplateGitSource(ctx, template.ID)
+	var apiErr *wirtualsdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+}
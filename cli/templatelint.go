@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform/tfparse"
+)
+
+func (*RootCmd) templateLint() *serpent.Command {
+	var (
+		onlyRules []string
+		skipRules []string
+	)
+	allRules := tfparse.LintRules()
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			findings, ok := data.([]tfparse.LintFinding)
+			if !ok {
+				return nil, xerrors.Errorf("expected []tfparse.LintFinding, got %T", data)
+			}
+			return formatLintFindings(findings), nil
+		}),
+		cliui.JSONFormat(),
+		&sarifFormat{rules: allRules},
+	)
+
+	var ruleList strings.Builder
+	for _, rule := range allRules {
+		_, _ = fmt.Fprintf(&ruleList, "  %s (%s)\n      %s\n", rule.ID, rule.Severity, rule.Description)
+	}
+
+	cmd := &serpent.Command{
+		Use:   "lint [directory]",
+		Short: "Check a template for common mistakes without pushing it.",
+		Long: "The template is parsed locally, so no deployment or provisioner is needed. The command fails when the template has errors.\n\nRules:\n" +
+			ruleList.String() + "\n" + FormatExamples(
+			Example{
+				Description: "Lint the template in the current directory",
+				Command:     "coder templates lint",
+			},
+			Example{
+				Description: "Report problems to code scanning tools",
+				Command:     "coder templates lint ./my-template --output sarif > lint.sarif",
+			},
+		),
+		Middleware: serpent.RequireRangeArgs(0, 1),
+		Handler: func(inv *serpent.Invocation) error {
+			directory := "."
+			if len(inv.Args) > 0 {
+				directory = inv.Args[0]
+			}
+
+			rules, err := selectLintRules(allRules, onlyRules, skipRules)
+			if err != nil {
+				return err
+			}
+
+			parser, diags := tfparse.New(directory)
+			if diags.HasErrors() {
+				return xerrors.Errorf("parse template: %w", diags.Err())
+			}
+			findings, err := parser.Lint(inv.Context(), rules)
+			if err != nil {
+				return xerrors.Errorf("lint template: %w", err)
+			}
+
+			out, err := formatter.Format(inv.Context(), findings)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			if err != nil {
+				return err
+			}
+
+			errors := 0
+			for _, finding := range findings {
+				if finding.Severity == tfparse.LintSeverityError {
+					errors++
+				}
+			}
+			if errors > 0 {
+				return xerrors.Errorf("found %d error(s) in the template", errors)
+			}
+			return nil
+		},
+		Options: serpent.OptionSet{
+			{
+				Flag:        "rule",
+				Description: "Only run the given rules.",
+				Value:       serpent.StringArrayOf(&onlyRules),
+			},
+			{
+				Flag:        "skip-rule",
+				Description: "Skip the given rules.",
+				Value:       serpent.StringArrayOf(&skipRules),
+			},
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+// selectLintRules returns the rules to run, given the rules that were
+// explicitly selected or skipped.
+func selectLintRules(all []tfparse.LintRule, only, skip []string) ([]tfparse.LintRule, error) {
+	ids := make([]string, 0, len(all))
+	for _, rule := range all {
+		ids = append(ids, rule.ID)
+	}
+	for _, id := range append(slices.Clone(only), skip...) {
+		if !slices.Contains(ids, id) {
+			return nil, xerrors.Errorf("unknown rule %q, expected one of: %s", id, strings.Join(ids, ", "))
+		}
+	}
+
+	rules := make([]tfparse.LintRule, 0, len(all))
+	for _, rule := range all {
+		if len(only) > 0 && !slices.Contains(only, rule.ID) {
+			continue
+		}
+		if slices.Contains(skip, rule.ID) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func formatLintFindings(findings []tfparse.LintFinding) string {
+	if len(findings) == 0 {
+		return "No problems were found."
+	}
+	var (
+		sb       strings.Builder
+		errors   int
+		warnings int
+	)
+	for _, finding := range findings {
+		style := cliui.DefaultStyles.Warn
+		if finding.Severity == tfparse.LintSeverityError {
+			style = cliui.DefaultStyles.Error
+			errors++
+		} else {
+			warnings++
+		}
+		_, _ = fmt.Fprintf(&sb, "%s:%d:%d: %s %s %s\n",
+			finding.Filename, finding.Line, finding.Column,
+			pretty.Sprint(style, string(finding.Severity)+":"),
+			finding.Message,
+			cliui.Placeholder("("+finding.Rule+")"),
+		)
+	}
+	_, _ = fmt.Fprintf(&sb, "\nFound %d error(s) and %d warning(s).", errors, warnings)
+	return sb.String()
+}
+
+// sarifFormat formats lint findings as a SARIF 2.1.0 log, which code scanning
+// tools understand.
+type sarifFormat struct {
+	rules []tfparse.LintRule
+}
+
+var _ cliui.OutputFormat = &sarifFormat{}
+
+// ID implements OutputFormat.
+func (*sarifFormat) ID() string {
+	return "sarif"
+}
+
+// AttachOptions implements OutputFormat.
+func (*sarifFormat) AttachOptions(_ *serpent.OptionSet) {}
+
+// Format implements OutputFormat.
+func (f *sarifFormat) Format(_ context.Context, data any) (string, error) {
+	findings, ok := data.([]tfparse.LintFinding)
+	if !ok {
+		return "", xerrors.Errorf("expected []tfparse.LintFinding, got %T", data)
+	}
+
+	rules := make([]sarifRule, 0, len(f.rules))
+	for _, rule := range f.rules {
+		rules = append(rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: string(rule.Severity)},
+		})
+	}
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		results = append(results, sarifResult{
+			RuleID:  finding.Rule,
+			Level:   string(finding.Severity),
+			Message: sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: finding.Filename},
+					Region: sarifRegion{
+						StartLine:   finding.Line,
+						StartColumn: finding.Column,
+					},
+				},
+			}},
+		})
+	}
+
+	out, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:           "coder templates lint",
+					InformationURI: "https://coder.com/docs/reference/cli/templates_lint",
+					Rules:          rules,
+				},
+			},
+			Results: results,
+		}},
+	}, "", "  ")
+	if err != nil {
+		return "", xerrors.Errorf("marshal sarif: %w", err)
+	}
+	return string(out), nil
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
@@ -0,0 +1,125 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+)
+
+func TestTemplateLint(t *testing.T) {
+	t.Parallel()
+
+	writeTemplate := func(t *testing.T, content string) string {
+		t.Helper()
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0o600)
+		require.NoError(t, err)
+		return dir
+	}
+
+	t.Run("Clean", func(t *testing.T) {
+		t.Parallel()
+		dir := writeTemplate(t, `
+resource "coder_agent" "main" {
+  os   = "linux"
+  arch = "amd64"
+}
+`)
+		inv, _ := clitest.New(t, "templates", "lint", dir)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "No problems were found.")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+		dir := writeTemplate(t, `
+resource "coder_agent" "main" {
+  os                 = "linux"
+  arch               = "amd64"
+  login_before_ready = false
+}
+resource "coder_app" "code-server" {
+  agent_id = coder_agent.mian.id
+}
+`)
+		inv, _ := clitest.New(t, "templates", "lint", dir)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.ErrorContains(t, err, "found 1 error(s)")
+		require.Contains(t, out.String(), `main.tf:5:3: warning: coder_agent "main" uses the deprecated attribute "login_before_ready"`)
+		require.Contains(t, out.String(), `main.tf:8:14: error: coder_app "code-server" references agent "mian", which isn't declared`)
+
+		// Skipping the rule makes the template pass.
+		inv, _ = clitest.New(t, "templates", "lint", dir, "--skip-rule", "unknown-agent")
+		out.Reset()
+		inv.Stdout = &out
+		err = inv.Run()
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "Found 0 error(s) and 1 warning(s).")
+	})
+
+	t.Run("SARIF", func(t *testing.T) {
+		t.Parallel()
+		dir := writeTemplate(t, `
+resource "coder_agent" "main" {
+  os   = "linux"
+  arch = "amd64"
+}
+resource "coder_app" "code-server" {
+  agent_id = coder_agent.mian.id
+}
+`)
+		inv, _ := clitest.New(t, "templates", "lint", dir, "--output", "sarif")
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.Error(t, err)
+
+		var log struct {
+			Version string `json:"version"`
+			Runs    []struct {
+				Results []struct {
+					RuleID    string `json:"ruleId"`
+					Level     string `json:"level"`
+					Locations []struct {
+						PhysicalLocation struct {
+							ArtifactLocation struct {
+								URI string `json:"uri"`
+							} `json:"artifactLocation"`
+							Region struct {
+								StartLine int `json:"startLine"`
+							} `json:"region"`
+						} `json:"physicalLocation"`
+					} `json:"locations"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &log))
+		require.Equal(t, "2.1.0", log.Version)
+		require.Len(t, log.Runs, 1)
+		require.Len(t, log.Runs[0].Results, 1)
+		result := log.Runs[0].Results[0]
+		require.Equal(t, "unknown-agent", result.RuleID)
+		require.Equal(t, "error", result.Level)
+		require.Equal(t, "main.tf", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		require.Equal(t, 7, result.Locations[0].PhysicalLocation.Region.StartLine)
+	})
+
+	t.Run("UnknownRule", func(t *testing.T) {
+		t.Parallel()
+		dir := writeTemplate(t, "")
+		inv, _ := clitest.New(t, "templates", "lint", dir, "--rule", "does-not-exist")
+		err := inv.Run()
+		require.ErrorContains(t, err, `unknown rule "does-not-exist"`)
+	})
+}
@@ -27,6 +27,7 @@ func (r *RootCmd) templatePush() *serpent.Command {
 	var (
 		versionName          string
 		provisioner          string
+		provisionerType      string
 		workdir              string
 		variablesFile        string
 		commandLineVariables []string
@@ -46,6 +47,9 @@ func (r *RootCmd) templatePush() *serpent.Command {
 		),
 		Handler: func(inv *serpent.Invocation) error {
 			uploadFlags.setWorkdir(workdir)
+			if provisionerType != "" {
+				provisioner = provisionerType
+			}
 
 			organization, err := orgContext.Selected(inv, client)
 			if err != nil {
@@ -80,9 +84,13 @@ func (r *RootCmd) templatePush() *serpent.Command {
 				createTemplate = true
 			}
 
-			err = uploadFlags.checkForLockfile(inv)
-			if err != nil {
-				return xerrors.Errorf("check for lockfile: %w", err)
+			// Templates of the exec provisioner don't use Terraform, so they
+			// have no lockfile.
+			if provisioner != string(wirtualsdk.ProvisionerTypeExec) {
+				err = uploadFlags.checkForLockfile(inv)
+				if err != nil {
+					return xerrors.Errorf("check for lockfile: %w", err)
+				}
 			}
 
 			message := uploadFlags.templateMessage(inv)
@@ -211,6 +219,11 @@ func (r *RootCmd) templatePush() *serpent.Command {
 			Description: "Alias of --variable.",
 			Value:       serpent.StringArrayOf(&commandLineVariables),
 		},
+		{
+			Flag:        "provisioner",
+			Description: "The provisioner that runs the template. Templates of the exec provisioner contain a provision executable instead of Terraform files.",
+			Value:       serpent.EnumOf(&provisionerType, string(wirtualsdk.ProvisionerTypeTerraform), string(wirtualsdk.ProvisionerTypeExec)),
+		},
 		{
 			Flag:        "provisioner-tag",
 			Description: "Specify a set of tags to target provisioner daemons.",
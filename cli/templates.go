@@ -29,9 +29,12 @@ func (r *RootCmd) templates() *serpent.Command {
 		Children: []*serpent.Command{
 			r.templateCreate(),
 			r.templateEdit(),
+			r.templateGitSource(),
 			r.templateInit(),
+			r.templateLint(),
 			r.templateList(),
 			r.templatePush(),
+			r.templateTest(),
 			r.templateVersions(),
 			r.templateDelete(),
 			r.templatePull(),
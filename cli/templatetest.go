@@ -0,0 +1,635 @@
+//go:build !slim
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/provisioner/echo"
+	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
+	sdkproto "github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/drpc"
+)
+
+// templateTestSpecFile is the name of the test spec that is read from the
+// template directory when --spec isn't set.
+const templateTestSpecFile = "template-tests.yaml"
+
+// templateTestSpec describes the test cases of a template.
+type templateTestSpec struct {
+	// Variables are the template variables used by all cases.
+	Variables map[string]string  `yaml:"variables"`
+	Cases     []templateTestCase `yaml:"cases"`
+}
+
+type templateTestCase struct {
+	Name       string            `yaml:"name"`
+	Transition string            `yaml:"transition"`
+	Parameters map[string]string `yaml:"parameters"`
+	// Matrix maps parameter names to lists of values. A case with a matrix
+	// runs once for every combination of the values, in addition to its
+	// parameters.
+	Matrix map[string][]string `yaml:"matrix"`
+	Expect templateTestExpect  `yaml:"expect"`
+}
+
+// templateTestExpect lists what must be planned for a case to pass. When Error
+// is set, the case passes only if parameter validation or the plan fails with
+// an error that contains it.
+type templateTestExpect struct {
+	Error     string   `yaml:"error"`
+	Resources []string `yaml:"resources"`
+	Agents    []string `yaml:"agents"`
+	Apps      []string `yaml:"apps"`
+}
+
+// templateTestResult is the outcome of a single case.
+type templateTestResult struct {
+	Name     string
+	Duration time.Duration
+	// Failure is empty when the case passed.
+	Failure string
+	Logs    []string
+}
+
+func (*RootCmd) templateTest() *serpent.Command {
+	var (
+		specFile    string
+		provisioner string
+		engine      string
+		junitFile   string
+	)
+	cmd := &serpent.Command{
+		Use:   "test [directory]",
+		Short: "Run the test cases of a template without pushing it.",
+		Long: "The test cases are read from " + templateTestSpecFile + " in the template directory. Every case plans a workspace with the given parameter values using a local provisioner, and checks the planned resources, agents and apps, or the validation error. Cases with a matrix run once for every combination of its parameter values. Planning doesn't create any infrastructure, but the providers of the template may need credentials.\n" + FormatExamples(
+			Example{
+				Description: "Run the test cases of the template in the current directory",
+				Command:     "coder templates test",
+			},
+			Example{
+				Description: "Write a JUnit report for CI",
+				Command:     "coder templates test ./my-template --junit-file report.xml",
+			},
+		),
+		Middleware: serpent.RequireRangeArgs(0, 1),
+		Handler: func(inv *serpent.Invocation) error {
+			directory := "."
+			if len(inv.Args) > 0 {
+				directory = inv.Args[0]
+			}
+			if specFile == "" {
+				specFile = filepath.Join(directory, templateTestSpecFile)
+			}
+			spec, err := readTemplateTestSpec(specFile)
+			if err != nil {
+				return err
+			}
+
+			var archive bytes.Buffer
+			err = provisionersdk.Tar(&archive, inv.Logger, directory, provisionersdk.TemplateArchiveLimit)
+			if err != nil {
+				return xerrors.Errorf("archive template: %w", err)
+			}
+
+			tfEngine, err := terraform.ParseEngine(engine)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+			client, closeProvisioner, err := serveTemplateTestProvisioner(ctx, inv, provisioner, tfEngine)
+			if err != nil {
+				return err
+			}
+			defer closeProvisioner()
+
+			runner := &templateTestRunner{
+				client:  client,
+				archive: archive.Bytes(),
+				name:    filepath.Base(absolutePathOrDirectory(directory)),
+			}
+			variables, err := runner.variables(ctx, spec.Variables)
+			if err != nil {
+				return err
+			}
+
+			results := make([]templateTestResult, 0, len(spec.Cases))
+			failed := 0
+			for _, tc := range spec.Cases {
+				result := runner.run(ctx, tc, variables)
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				status := pretty.Sprint(cliui.DefaultStyles.Keyword, "PASS")
+				if result.Failure != "" {
+					status = pretty.Sprint(cliui.DefaultStyles.Error, "FAIL")
+					failed++
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "%s  %s %s\n", status, result.Name,
+					cliui.Placeholder(fmt.Sprintf("(%s)", result.Duration.Round(time.Millisecond))))
+				if result.Failure != "" {
+					_, _ = fmt.Fprintf(inv.Stdout, "      %s\n", result.Failure)
+				}
+				results = append(results, result)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "\n%d case(s), %d passed, %d failed.\n", len(results), len(results)-failed, failed)
+
+			if junitFile != "" {
+				err = writeTemplateTestJUnit(junitFile, runner.name, results)
+				if err != nil {
+					return err
+				}
+			}
+			if failed > 0 {
+				return xerrors.Errorf("%d of %d case(s) failed", failed, len(results))
+			}
+			return nil
+		},
+		Options: serpent.OptionSet{
+			{
+				Flag:        "spec",
+				Description: "Path to the test spec. Defaults to " + templateTestSpecFile + " in the template directory.",
+				Value:       serpent.StringOf(&specFile),
+			},
+			{
+				Flag:        "provisioner",
+				Description: "The provisioner that plans the template. The echo provisioner replays the fixtures in the template directory instead of running Terraform.",
+				Default:     string(wirtualsdk.ProvisionerTypeTerraform),
+				Value:       serpent.EnumOf(&provisioner, string(wirtualsdk.ProvisionerTypeTerraform), string(wirtualsdk.ProvisionerTypeEcho)),
+			},
+			{
+				Flag:        "terraform-engine",
+				Description: "The infrastructure as code engine that the terraform provisioner plans the template with.",
+				Default:     string(terraform.EngineTerraform),
+				Value:       serpent.EnumOf(&engine, string(terraform.EngineTerraform), string(terraform.EngineOpenTofu)),
+			},
+			{
+				Flag:        "junit-file",
+				Description: "Write the results as a JUnit XML report to the given file.",
+				Value:       serpent.StringOf(&junitFile),
+			},
+		},
+	}
+	return cmd
+}
+
+func readTemplateTestSpec(path string) (templateTestSpec, error) {
+	var spec templateTestSpec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return spec, xerrors.Errorf("read test spec: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&spec)
+	if err != nil {
+		return spec, xerrors.Errorf("parse test spec %q: %w", path, err)
+	}
+	if len(spec.Cases) == 0 {
+		return spec, xerrors.Errorf("test spec %q has no cases", path)
+	}
+	var cases []templateTestCase
+	for i, tc := range spec.Cases {
+		if tc.Name == "" {
+			return spec, xerrors.Errorf("case %d has no name", i+1)
+		}
+		expanded, err := expandTemplateTestMatrix(tc)
+		if err != nil {
+			return spec, xerrors.Errorf("case %q: %w", tc.Name, err)
+		}
+		cases = append(cases, expanded...)
+	}
+	spec.Cases = cases
+
+	names := map[string]struct{}{}
+	for _, tc := range spec.Cases {
+		if _, ok := names[tc.Name]; ok {
+			return spec, xerrors.Errorf("case %q is defined more than once", tc.Name)
+		}
+		names[tc.Name] = struct{}{}
+		if _, err := templateTestTransition(tc.Transition); err != nil {
+			return spec, xerrors.Errorf("case %q: %w", tc.Name, err)
+		}
+	}
+	return spec, nil
+}
+
+// expandTemplateTestMatrix returns a case for every combination of the values
+// in the matrix of tc. The names of the cases list their matrix values, e.g.
+// "sizes (cpu=2, region=eu)".
+func expandTemplateTestMatrix(tc templateTestCase) ([]templateTestCase, error) {
+	if len(tc.Matrix) == 0 {
+		return []templateTestCase{tc}, nil
+	}
+	names := make([]string, 0, len(tc.Matrix))
+	for name, values := range tc.Matrix {
+		if len(values) == 0 {
+			return nil, xerrors.Errorf("matrix parameter %q has no values", name)
+		}
+		if _, ok := tc.Parameters[name]; ok {
+			return nil, xerrors.Errorf("parameter %q is set in both the parameters and the matrix", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cases := []templateTestCase{{
+		Name:       tc.Name,
+		Transition: tc.Transition,
+		Parameters: tc.Parameters,
+		Expect:     tc.Expect,
+	}}
+	for _, name := range names {
+		next := make([]templateTestCase, 0, len(cases)*len(tc.Matrix[name]))
+		for _, c := range cases {
+			for _, value := range tc.Matrix[name] {
+				parameters := make(map[string]string, len(c.Parameters)+1)
+				for k, v := range c.Parameters {
+					parameters[k] = v
+				}
+				parameters[name] = value
+				expanded := c
+				expanded.Parameters = parameters
+				next = append(next, expanded)
+			}
+		}
+		cases = next
+	}
+	for i, c := range cases {
+		values := make([]string, 0, len(names))
+		for _, name := range names {
+			values = append(values, name+"="+c.Parameters[name])
+		}
+		cases[i].Name = fmt.Sprintf("%s (%s)", tc.Name, strings.Join(values, ", "))
+	}
+	return cases, nil
+}
+
+func templateTestTransition(transition string) (sdkproto.WorkspaceTransition, error) {
+	switch transition {
+	case "", "start":
+		return sdkproto.WorkspaceTransition_START, nil
+	case "stop":
+		return sdkproto.WorkspaceTransition_STOP, nil
+	case "destroy":
+		return sdkproto.WorkspaceTransition_DESTROY, nil
+	default:
+		return 0, xerrors.Errorf("unknown transition %q, expected one of: start, stop, destroy", transition)
+	}
+}
+
+func absolutePathOrDirectory(directory string) string {
+	abs, err := filepath.Abs(directory)
+	if err != nil {
+		return directory
+	}
+	return abs
+}
+
+// serveTemplateTestProvisioner serves a provisioner in memory, like the
+// built-in provisioners of the server.
+func serveTemplateTestProvisioner(ctx context.Context, inv *serpent.Invocation, provisioner string, engine terraform.Engine) (sdkproto.DRPCProvisionerClient, func(), error) {
+	workDir, err := os.MkdirTemp("", "coder-template-test-")
+	if err != nil {
+		return nil, nil, xerrors.Errorf("create work directory: %w", err)
+	}
+	// Terraform and the providers of templates are cached between runs. The
+	// cache must not be shared with a server on the same machine.
+	cacheDir := filepath.Join(wirtualsdk.DefaultCacheDir(), "template-test")
+	err = os.MkdirAll(cacheDir, 0o700)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("create cache directory: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	client, server := drpc.MemTransportPipe()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		options := &provisionersdk.ServeOptions{
+			Listener:      server,
+			Logger:        inv.Logger.Named(provisioner),
+			WorkDirectory: workDir,
+		}
+		var err error
+		switch provisioner {
+		case string(wirtualsdk.ProvisionerTypeEcho):
+			err = echo.Serve(ctx, options)
+		default:
+			err = terraform.Serve(ctx, &terraform.ServeOptions{
+				ServeOptions: options,
+				CachePath:    cacheDir,
+				Engine:       engine,
+			})
+		}
+		if err != nil && !xerrors.Is(err, context.Canceled) {
+			cliui.Errorf(inv.Stderr, "Serve the %s provisioner: %s", provisioner, err)
+		}
+		// Fail sessions that are opened after the provisioner stopped.
+		_ = server.Close()
+	}()
+
+	return sdkproto.NewDRPCProvisionerClient(client), func() {
+		cancel()
+		_ = client.Close()
+		_ = server.Close()
+		wg.Wait()
+		_ = os.RemoveAll(workDir)
+	}, nil
+}
+
+type templateTestRunner struct {
+	client  sdkproto.DRPCProvisionerClient
+	archive []byte
+	name    string
+}
+
+// session starts a provisioner session for the template, and sends the
+// request. It returns the complete response, and the logs that were
+// streamed before it.
+func (r *templateTestRunner) session(ctx context.Context, req *sdkproto.Request) (*sdkproto.Response, []string, error) {
+	session, err := r.client.Session(ctx)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("open session: %w", err)
+	}
+	defer session.Close()
+
+	err = session.Send(&sdkproto.Request{Type: &sdkproto.Request_Config{Config: &sdkproto.Config{
+		TemplateSourceArchive: r.archive,
+	}}})
+	if err != nil {
+		return nil, nil, xerrors.Errorf("send config: %w", err)
+	}
+	err = session.Send(req)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("send request: %w", err)
+	}
+
+	var logs []string
+	for {
+		msg, err := session.Recv()
+		if err != nil {
+			return nil, logs, xerrors.Errorf("recv: %w", err)
+		}
+		if log := msg.GetLog(); log != nil {
+			logs = append(logs, log.Output)
+			continue
+		}
+		return msg, logs, nil
+	}
+}
+
+// variables parses the template, and returns the values of its variables.
+func (r *templateTestRunner) variables(ctx context.Context, values map[string]string) ([]*sdkproto.VariableValue, error) {
+	resp, _, err := r.session(ctx, &sdkproto.Request{Type: &sdkproto.Request_Parse{Parse: &sdkproto.ParseRequest{}}})
+	if err != nil {
+		return nil, xerrors.Errorf("parse template: %w", err)
+	}
+	parse := resp.GetParse()
+	if parse == nil {
+		return nil, xerrors.Errorf("unexpected response %T to parse", resp.Type)
+	}
+	if parse.Error != "" {
+		return nil, xerrors.Errorf("parse template: %s", parse.Error)
+	}
+
+	var variables []*sdkproto.VariableValue
+	declared := map[string]struct{}{}
+	for _, variable := range parse.TemplateVariables {
+		declared[variable.Name] = struct{}{}
+		value, ok := values[variable.Name]
+		if !ok {
+			if variable.Required {
+				return nil, xerrors.Errorf("template variable %q is required, set it in the variables of the test spec", variable.Name)
+			}
+			value = variable.DefaultValue
+		}
+		variables = append(variables, &sdkproto.VariableValue{
+			Name:      variable.Name,
+			Value:     value,
+			Sensitive: variable.Sensitive,
+		})
+	}
+	for name := range values {
+		if _, ok := declared[name]; !ok {
+			return nil, xerrors.Errorf("template variable %q isn't declared by the template", name)
+		}
+	}
+	return variables, nil
+}
+
+func (r *templateTestRunner) run(ctx context.Context, tc templateTestCase, variables []*sdkproto.VariableValue) templateTestResult {
+	start := time.Now()
+	failure, logs := r.check(ctx, tc, variables)
+	return templateTestResult{
+		Name:     tc.Name,
+		Duration: time.Since(start),
+		Failure:  failure,
+		Logs:     logs,
+	}
+}
+
+// check plans the case, and returns why it failed.
+func (r *templateTestRunner) check(ctx context.Context, tc templateTestCase, variables []*sdkproto.VariableValue) (string, []string) {
+	// The transition was validated when the spec was read.
+	transition, _ := templateTestTransition(tc.Transition)
+	parameterValues := make([]*sdkproto.RichParameterValue, 0, len(tc.Parameters))
+	for name, value := range tc.Parameters {
+		parameterValues = append(parameterValues, &sdkproto.RichParameterValue{Name: name, Value: value})
+	}
+	sort.Slice(parameterValues, func(i, j int) bool {
+		return parameterValues[i].Name < parameterValues[j].Name
+	})
+
+	resp, logs, err := r.session(ctx, &sdkproto.Request{Type: &sdkproto.Request_Plan{Plan: &sdkproto.PlanRequest{
+		Metadata: &sdkproto.Metadata{
+			WorkspaceTransition: transition,
+			WorkspaceName:       "test",
+			WorkspaceOwner:      "test",
+			TemplateName:        r.name,
+		},
+		RichParameterValues: parameterValues,
+		VariableValues:      variables,
+	}}})
+	if err != nil {
+		return err.Error(), logs
+	}
+	plan := resp.GetPlan()
+	if plan == nil {
+		return fmt.Sprintf("unexpected response %T to plan", resp.Type), logs
+	}
+
+	planErr := plan.Error
+	if planErr == "" {
+		if err := validateTemplateTestParameters(plan.Parameters, tc.Parameters); err != nil {
+			planErr = err.Error()
+		}
+	}
+	if tc.Expect.Error != "" {
+		if planErr == "" {
+			return fmt.Sprintf("expected an error that contains %q, but the plan succeeded", tc.Expect.Error), logs
+		}
+		if !strings.Contains(planErr, tc.Expect.Error) {
+			return fmt.Sprintf("expected an error that contains %q, got: %s", tc.Expect.Error, planErr), logs
+		}
+		return "", logs
+	}
+	if planErr != "" {
+		return planErr, logs
+	}
+
+	var resources, agents, apps []string
+	for _, resource := range plan.Resources {
+		resources = append(resources, resource.Type+"."+resource.Name)
+		for _, agent := range resource.Agents {
+			agents = append(agents, agent.Name)
+			for _, app := range agent.Apps {
+				apps = append(apps, app.Slug)
+			}
+		}
+	}
+	var missing []string
+	for _, expected := range []struct {
+		kind     string
+		expected []string
+		actual   []string
+	}{
+		{"resource", tc.Expect.Resources, resources},
+		{"agent", tc.Expect.Agents, agents},
+		{"app", tc.Expect.Apps, apps},
+	} {
+		for _, name := range expected.expected {
+			if !slices.Contains(expected.actual, name) {
+				missing = append(missing, fmt.Sprintf("%s %q", expected.kind, name))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("expected the plan to contain %s; planned resources: %s",
+			strings.Join(missing, ", "), strings.Join(resources, ", ")), logs
+	}
+	return "", logs
+}
+
+// validateTemplateTestParameters validates the parameter values of a case
+// like the server does when a workspace is created.
+func validateTemplateTestParameters(parameters []*sdkproto.RichParameter, values map[string]string) error {
+	richParameters := make([]wirtualsdk.TemplateVersionParameter, 0, len(parameters))
+	for _, parameter := range parameters {
+		options := make([]wirtualsdk.TemplateVersionParameterOption, 0, len(parameter.Options))
+		for _, option := range parameter.Options {
+			options = append(options, wirtualsdk.TemplateVersionParameterOption{
+				Name:        option.Name,
+				Description: option.Description,
+				Value:       option.Value,
+				Icon:        option.Icon,
+			})
+		}
+		richParameters = append(richParameters, wirtualsdk.TemplateVersionParameter{
+			Name:                parameter.Name,
+			DisplayName:         parameter.DisplayName,
+			Type:                parameter.Type,
+			Mutable:             parameter.Mutable,
+			DefaultValue:        parameter.DefaultValue,
+			Options:             options,
+			ValidationError:     parameter.ValidationError,
+			ValidationRegex:     parameter.ValidationRegex,
+			ValidationMin:       parameter.ValidationMin,
+			ValidationMax:       parameter.ValidationMax,
+			ValidationMonotonic: wirtualsdk.ValidationMonotonicOrder(parameter.ValidationMonotonic),
+			Required:            parameter.Required,
+			Ephemeral:           parameter.Ephemeral,
+		})
+	}
+
+	buildParameters := make([]wirtualsdk.WorkspaceBuildParameter, 0, len(values))
+	for name, value := range values {
+		if !slices.ContainsFunc(richParameters, func(p wirtualsdk.TemplateVersionParameter) bool {
+			return p.Name == name
+		}) {
+			return xerrors.Errorf("parameter %q isn't declared by the template", name)
+		}
+		buildParameters = append(buildParameters, wirtualsdk.WorkspaceBuildParameter{Name: name, Value: value})
+	}
+	return wirtualsdk.ValidateNewWorkspaceParameters(richParameters, buildParameters)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeTemplateTestJUnit(path, name string, results []templateTestResult) error {
+	suite := junitTestSuite{
+		Name:  name,
+		Tests: len(results),
+	}
+	var total time.Duration
+	for _, result := range results {
+		total += result.Duration
+		tc := junitTestCase{
+			Name:      result.Name,
+			ClassName: name,
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+			SystemOut: strings.Join(result.Logs, "\n"),
+		}
+		if result.Failure != "" {
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: result.Failure, Text: result.Failure}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total.Seconds())
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("marshal junit report: %w", err)
+	}
+	err = os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0o600)
+	if err != nil {
+		return xerrors.Errorf("write junit report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+//go:build !slim
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandTemplateTestMatrix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NoMatrix", func(t *testing.T) {
+		t.Parallel()
+		tc := templateTestCase{Name: "defaults", Parameters: map[string]string{"region": "us"}}
+		cases, err := expandTemplateTestMatrix(tc)
+		require.NoError(t, err)
+		require.Equal(t, []templateTestCase{tc}, cases)
+	})
+
+	t.Run("CrossProduct", func(t *testing.T) {
+		t.Parallel()
+		cases, err := expandTemplateTestMatrix(templateTestCase{
+			Name:       "sizes",
+			Transition: "stop",
+			Parameters: map[string]string{"image": "ubuntu"},
+			Matrix: map[string][]string{
+				"region": {"us", "eu"},
+				"cpu":    {"2", "4", "8"},
+			},
+			Expect: templateTestExpect{Agents: []string{"main"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, cases, 6)
+
+		names := make([]string, 0, len(cases))
+		for _, tc := range cases {
+			names = append(names, tc.Name)
+			require.Equal(t, "stop", tc.Transition)
+			require.Equal(t, []string{"main"}, tc.Expect.Agents)
+			require.Nil(t, tc.Matrix)
+			require.Len(t, tc.Parameters, 3)
+			require.Equal(t, "ubuntu", tc.Parameters["image"])
+		}
+		require.Equal(t, []string{
+			"sizes (cpu=2, region=us)",
+			"sizes (cpu=2, region=eu)",
+			"sizes (cpu=4, region=us)",
+			"sizes (cpu=4, region=eu)",
+			"sizes (cpu=8, region=us)",
+			"sizes (cpu=8, region=eu)",
+		}, names)
+		require.Equal(t, map[string]string{"image": "ubuntu", "cpu": "4", "region": "eu"}, cases[3].Parameters)
+	})
+
+	t.Run("NoValues", func(t *testing.T) {
+		t.Parallel()
+		_, err := expandTemplateTestMatrix(templateTestCase{
+			Name:   "empty",
+			Matrix: map[string][]string{"region": {}},
+		})
+		require.ErrorContains(t, err, `matrix parameter "region" has no values`)
+	})
+
+	t.Run("ParameterConflict", func(t *testing.T) {
+		t.Parallel()
+		_, err := expandTemplateTestMatrix(templateTestCase{
+			Name:       "conflict",
+			Parameters: map[string]string{"region": "us"},
+			Matrix:     map[string][]string{"region": {"eu"}},
+		})
+		require.ErrorContains(t, err, `parameter "region" is set in both the parameters and the matrix`)
+	})
+}
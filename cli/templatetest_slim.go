@@ -0,0 +1,20 @@
+//go:build slim
+
+package cli
+
+import "github.com/coder/serpent"
+
+func (*RootCmd) templateTest() *serpent.Command {
+	cmd := &serpent.Command{
+		Use:   "test [directory]",
+		Short: "Run the test cases of a template without pushing it.",
+		// We accept RawArgs so all commands and flags are accepted.
+		RawArgs: true,
+		Handler: func(inv *serpent.Invocation) error {
+			SlimUnsupported(inv.Stderr, "templates test")
+			return nil
+		},
+	}
+
+	return cmd
+}
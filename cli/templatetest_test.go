@@ -0,0 +1,165 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+	"github.com/onchainengineering/hmi-wirtual/provisioner/echo"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+)
+
+func TestTemplateTest(t *testing.T) {
+	t.Parallel()
+
+	// prepareTemplate writes echo fixtures that plan a workspace with an
+	// agent and an app, and the given test spec.
+	prepareTemplate := func(t *testing.T, spec string) string {
+		t.Helper()
+		dir := t.TempDir()
+		archive, err := echo.Tar(&echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionPlan: []*proto.Response{{
+				Type: &proto.Response_Plan{Plan: &proto.PlanComplete{
+					Parameters: []*proto.RichParameter{{
+						Name:         "region",
+						Type:         "string",
+						DefaultValue: "us",
+						Options: []*proto.RichParameterOption{
+							{Name: "US", Value: "us"},
+							{Name: "EU", Value: "eu"},
+						},
+					}},
+					Resources: []*proto.Resource{{
+						Type: "docker_container",
+						Name: "workspace",
+						Agents: []*proto.Agent{{
+							Name: "main",
+							Apps: []*proto.App{{Slug: "code-server"}},
+						}},
+					}},
+				}},
+			}},
+			ProvisionApply: echo.ApplyComplete,
+		})
+		require.NoError(t, err)
+		require.NoError(t, provisionersdk.Untar(dir, bytes.NewReader(archive)))
+		// The template archive must contain Terraform files.
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), nil, 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "template-tests.yaml"), []byte(spec), 0o600))
+		return dir
+	}
+
+	t.Run("Pass", func(t *testing.T) {
+		t.Parallel()
+		dir := prepareTemplate(t, `
+cases:
+  - name: defaults
+    expect:
+      resources: [docker_container.workspace]
+      agents: [main]
+      apps: [code-server]
+  - name: eu
+    parameters:
+      region: eu
+  - name: invalid region
+    parameters:
+      region: mars
+    expect:
+      error: "must match one of options"
+`)
+		junit := filepath.Join(t.TempDir(), "report.xml")
+		inv, _ := clitest.New(t, "templates", "test", dir, "--provisioner", "echo", "--junit-file", junit)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "3 case(s), 3 passed, 0 failed.")
+
+		data, err := os.ReadFile(junit)
+		require.NoError(t, err)
+		var report struct {
+			Suites []struct {
+				Tests    int `xml:"tests,attr"`
+				Failures int `xml:"failures,attr"`
+				Cases    []struct {
+					Name string `xml:"name,attr"`
+				} `xml:"testcase"`
+			} `xml:"testsuite"`
+		}
+		require.NoError(t, xml.Unmarshal(data, &report))
+		require.Len(t, report.Suites, 1)
+		require.Equal(t, 3, report.Suites[0].Tests)
+		require.Equal(t, 0, report.Suites[0].Failures)
+		require.Equal(t, "invalid region", report.Suites[0].Cases[2].Name)
+	})
+
+	t.Run("Fail", func(t *testing.T) {
+		t.Parallel()
+		dir := prepareTemplate(t, `
+cases:
+  - name: missing agent
+    expect:
+      agents: [dev]
+  - name: unknown parameter
+    parameters:
+      zone: a
+  - name: unexpected success
+    expect:
+      error: "must match one of options"
+`)
+		inv, _ := clitest.New(t, "templates", "test", dir, "--provisioner", "echo")
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.ErrorContains(t, err, "3 of 3 case(s) failed")
+		require.Contains(t, out.String(), `expected the plan to contain agent "dev"`)
+		require.Contains(t, out.String(), `parameter "zone" isn't declared by the template`)
+		require.Contains(t, out.String(), `expected an error that contains "must match one of options", but the plan succeeded`)
+	})
+
+	t.Run("Matrix", func(t *testing.T) {
+		t.Parallel()
+		dir := prepareTemplate(t, `
+cases:
+  - name: regions
+    matrix:
+      region: [us, eu]
+    expect:
+      agents: [main]
+  - name: invalid regions
+    matrix:
+      region: [mars, venus]
+    expect:
+      error: "must match one of options"
+`)
+		inv, _ := clitest.New(t, "templates", "test", dir, "--provisioner", "echo")
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "regions (region=us)")
+		require.Contains(t, out.String(), "regions (region=eu)")
+		require.Contains(t, out.String(), "invalid regions (region=venus)")
+		require.Contains(t, out.String(), "4 case(s), 4 passed, 0 failed.")
+	})
+
+	t.Run("InvalidSpec", func(t *testing.T) {
+		t.Parallel()
+		dir := prepareTemplate(t, `
+cases:
+  - name: typo
+    expcet:
+      agents: [main]
+`)
+		inv, _ := clitest.New(t, "templates", "test", dir, "--provisioner", "echo")
+		err := inv.Run()
+		require.ErrorContains(t, err, "parse test spec")
+	})
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func (r *RootCmd) templateVersionsDiff() *serpent.Command {
+	var (
+		orgContext = NewOrganizationContext()
+		noFiles    bool
+	)
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			diff, ok := data.(wirtualsdk.TemplateVersionDiff)
+			if !ok {
+				return nil, xerrors.Errorf("expected wirtualsdk.TemplateVersionDiff, got %T", data)
+			}
+			return formatTemplateVersionDiff(diff, !noFiles), nil
+		}),
+		cliui.JSONFormat(),
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "diff <template> <from-version> <to-version>",
+		Short: "Show what changed between two versions of a template.",
+		Long: "Shows a diff of the template source, and of the parameters, variables, workspace tags and resources that were recorded when the versions were imported.\n" + FormatExamples(
+			Example{
+				Description: "Review a version before promoting it",
+				Command:     "coder templates versions diff my-template v1 v2",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(3),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			organization, err := orgContext.Selected(inv, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			from, err := client.TemplateVersionByName(inv.Context(), template.ID, inv.Args[1])
+			if err != nil {
+				return xerrors.Errorf("get template version %q: %w", inv.Args[1], err)
+			}
+			to, err := client.TemplateVersionByName(inv.Context(), template.ID, inv.Args[2])
+			if err != nil {
+				return xerrors.Errorf("get template version %q: %w", inv.Args[2], err)
+			}
+
+			diff, err := client.TemplateVersionDiff(inv.Context(), from.ID, to.ID)
+			if err != nil {
+				return xerrors.Errorf("compare template versions: %w", err)
+			}
+			out, err := formatter.Format(inv.Context(), diff)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+		Options: serpent.OptionSet{
+			{
+				Flag:        "no-files",
+				Description: "Only show the changes of parameters, variables, workspace tags and resources.",
+				Value:       serpent.BoolOf(&noFiles),
+			},
+		},
+	}
+	orgContext.AttachOptions(cmd)
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func formatTemplateVersionDiff(diff wirtualsdk.TemplateVersionDiff, files bool) string {
+	var sb strings.Builder
+	if files {
+		for _, file := range diff.Files {
+			if file.Binary {
+				_, _ = fmt.Fprintf(&sb, "Binary file %s %s\n", file.Path, file.Change)
+				continue
+			}
+			for _, line := range strings.SplitAfter(file.Diff, "\n") {
+				switch {
+				case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+					line = pretty.Sprint(cliui.DefaultStyles.Placeholder, line)
+				case strings.HasPrefix(line, "+"):
+					line = pretty.Sprint(cliui.DefaultStyles.Keyword, line)
+				case strings.HasPrefix(line, "-"):
+					line = pretty.Sprint(cliui.DefaultStyles.Error, line)
+				case strings.HasPrefix(line, "@@"):
+					line = pretty.Sprint(cliui.DefaultStyles.DateTimeStamp, line)
+				}
+				_, _ = sb.WriteString(line)
+			}
+		}
+		if len(diff.Files) > 0 {
+			_, _ = sb.WriteString("\n")
+		}
+	}
+
+	changed := false
+	for _, section := range []struct {
+		title string
+		diff  wirtualsdk.TemplateVersionObjectDiff
+	}{
+		{"Parameters", diff.Parameters},
+		{"Variables", diff.Variables},
+		{"Workspace tags", diff.WorkspaceTags},
+		{"Resources", diff.Resources},
+	} {
+		if len(section.diff.Added)+len(section.diff.Removed)+len(section.diff.Changed) == 0 {
+			continue
+		}
+		changed = true
+		_, _ = fmt.Fprintf(&sb, "%s:\n", pretty.Sprint(cliui.DefaultStyles.Keyword, section.title))
+		for _, name := range section.diff.Added {
+			_, _ = fmt.Fprintf(&sb, "  + %s\n", name)
+		}
+		for _, name := range section.diff.Removed {
+			_, _ = fmt.Fprintf(&sb, "  - %s\n", name)
+		}
+		for _, change := range section.diff.Changed {
+			_, _ = fmt.Fprintf(&sb, "  ~ %s\n", change.Name)
+			for _, field := range change.Fields {
+				_, _ = fmt.Fprintf(&sb, "      %s: %s -> %s\n", field.Name, templateVersionFieldValue(field.From), templateVersionFieldValue(field.To))
+			}
+		}
+	}
+	if !changed && (!files || len(diff.Files) == 0) {
+		return "The versions are identical."
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func templateVersionFieldValue(value string) string {
+	if value == "" {
+		return cliui.Placeholder("(none)")
+	}
+	return value
+}
@@ -1,7 +1,9 @@
 package cli_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
 	"github.com/onchainengineering/hmi-wirtual/pty/ptytest"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/rbac"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
 	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
 )
@@ -123,3 +126,59 @@ func TestTemplateVersionsPromote(t *testing.T) {
 		require.Contains(t, err.Error(), "get template by name")
 	})
 }
+
+func TestTemplateVersionsDiff(t *testing.T) {
+	t.Parallel()
+
+	client := wirtualdtest.New(t, &wirtualdtest.Options{IncludeProvisionerDaemon: true})
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	member, _ := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID, rbac.RoleTemplateAdmin())
+
+	version1 := wirtualdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+	wirtualdtest.AwaitTemplateVersionJobCompleted(t, client, version1.ID)
+	template := wirtualdtest.CreateTemplate(t, client, owner.OrganizationID, version1.ID)
+	version2 := wirtualdtest.CreateTemplateVersion(t, client, owner.OrganizationID, completeWithAgent(), func(ctvr *wirtualsdk.CreateTemplateVersionRequest) {
+		ctvr.TemplateID = template.ID
+	})
+	wirtualdtest.AwaitTemplateVersionJobCompleted(t, client, version2.ID)
+
+	t.Run("Text", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "templates", "versions", "diff", template.Name, version1.Name, version2.Name, "--no-files")
+		clitest.SetupConfig(t, member, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		require.NoError(t, inv.Run())
+		require.Contains(t, out.String(), "Resources:")
+		require.Contains(t, out.String(), "+ compute.main")
+		require.NotContains(t, out.String(), "Binary file")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "templates", "versions", "diff", template.Name, version1.Name, version2.Name, "-o", "json")
+		clitest.SetupConfig(t, member, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		require.NoError(t, inv.Run())
+
+		var diff wirtualsdk.TemplateVersionDiff
+		require.NoError(t, json.Unmarshal(out.Bytes(), &diff))
+		require.Equal(t, version1.ID, diff.FromTemplateVersionID)
+		require.Equal(t, version2.ID, diff.ToTemplateVersionID)
+		require.Equal(t, []string{"compute.main"}, diff.Resources.Added)
+	})
+
+	t.Run("IdenticalVersions", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "templates", "versions", "diff", template.Name, version1.Name, version1.Name)
+		clitest.SetupConfig(t, member, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		require.NoError(t, inv.Run())
+		require.Contains(t, out.String(), "The versions are identical.")
+	})
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/workspacesdk"
+)
+
+type processRow struct {
+	// For JSON format:
+	wirtualsdk.WorkspaceAgentProcess `table:"-"`
+
+	// For table format:
+	PID     int32  `json:"-" table:"pid,nosort"`
+	User    string `json:"-" table:"user"`
+	CPU     string `json:"-" table:"cpu"`
+	Memory  string `json:"-" table:"memory"`
+	Read    string `json:"-" table:"read"`
+	Write   string `json:"-" table:"write"`
+	Cgroup  string `json:"-" table:"cgroup"`
+	Command string `json:"-" table:"command"`
+}
+
+func processRowFromProcess(proc wirtualsdk.WorkspaceAgentProcess) processRow {
+	command := proc.Cmdline
+	if command == "" {
+		// Kernel threads and processes of other users may not expose their
+		// command line.
+		command = "[" + proc.Name + "]"
+	}
+	return processRow{
+		WorkspaceAgentProcess: proc,
+		PID:                   proc.PID,
+		User:                  proc.User,
+		CPU:                   fmt.Sprintf("%.1f%%", proc.CPUPercent),
+		Memory:                humanize.IBytes(proc.MemoryRSSBytes),
+		Read:                  humanize.IBytes(proc.IOReadBytes),
+		Write:                 humanize.IBytes(proc.IOWriteBytes),
+		Cgroup:                proc.Cgroup,
+		Command:               command,
+	}
+}
+
+func (r *RootCmd) top() *serpent.Command {
+	var (
+		limit     int64
+		interval  time.Duration
+		once      bool
+		formatter = cliui.NewOutputFormatter(
+			cliui.TableFormat([]processRow{}, []string{"pid", "user", "cpu", "memory", "read", "write", "command"}),
+			cliui.JSONFormat(),
+		)
+	)
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "top <workspace>",
+		Short:       "Show the processes using the most resources in a workspace",
+		Long: "CPU usage is measured by the agent over a short interval, and memory is the resident set size of each process.\n" + FormatExamples(
+			Example{
+				Description: "Watch the busiest processes of a workspace",
+				Command:     "coder top my-workspace",
+			},
+			Example{
+				Description: "Print the ten busiest processes once, including their cgroups",
+				Command:     "coder top my-workspace --once -n 10 -c pid,cpu,memory,cgroup,command",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			if limit < 0 {
+				return xerrors.New("--limit must not be negative")
+			}
+			if interval <= 0 {
+				return xerrors.New("--interval must be positive")
+			}
+
+			workspace, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, false, inv.Args[0])
+			if err != nil {
+				return err
+			}
+			if workspaceAgent.Status != wirtualsdk.WorkspaceAgentConnected {
+				return xerrors.Errorf("agent %q is %s, it must be connected to list processes", workspaceAgent.Name, workspaceAgent.Status)
+			}
+
+			opts := &workspacesdk.DialAgentOptions{}
+			if r.verbose {
+				opts.Logger = inv.Logger.AppendSinks(sloghuman.Sink(inv.Stderr)).Leveled(slog.LevelDebug)
+			}
+			if r.disableDirect {
+				opts.BlockEndpoints = true
+			}
+			conn, err := workspacesdk.New(client).DialAgent(ctx, workspaceAgent.ID, opts)
+			if err != nil {
+				return xerrors.Errorf("dial agent: %w", err)
+			}
+			defer conn.Close()
+			if !conn.AwaitReachable(ctx) {
+				return xerrors.New("agent is not reachable")
+			}
+
+			// Only redraw in place when a person is watching.
+			refresh := !once && isTTYOut(inv)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				snapshot, err := conn.Processes(ctx, int(limit))
+				if err != nil {
+					return xerrors.Errorf("list processes: %w", err)
+				}
+				rows := make([]processRow, 0, len(snapshot.Processes))
+				for _, proc := range snapshot.Processes {
+					rows = append(rows, processRowFromProcess(proc))
+				}
+				out, err := formatter.Format(ctx, rows)
+				if err != nil {
+					return err
+				}
+
+				var sb strings.Builder
+				if refresh {
+					// Move the cursor home and clear the screen.
+					_, _ = sb.WriteString("\033[H\033[2J")
+					_, _ = fmt.Fprintf(&sb, "%s at %s, refreshing every %s. Press Ctrl+C to exit.\n\n",
+						pretty.Sprint(cliui.DefaultStyles.Keyword, workspace.Name),
+						snapshot.CollectedAt.Local().Format(time.TimeOnly), interval)
+				}
+				_, _ = sb.WriteString(out)
+				_, err = fmt.Fprintln(inv.Stdout, sb.String())
+				if err != nil {
+					return err
+				}
+				if !refresh {
+					return nil
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:          "limit",
+			FlagShorthand: "n",
+			Description:   "The number of processes to show, or 0 to show all of them.",
+			Default:       "20",
+			Value:         serpent.Int64Of(&limit),
+		},
+		{
+			Flag:        "interval",
+			Description: "How often to refresh the processes.",
+			Default:     "2s",
+			Value:       serpent.DurationOf(&interval),
+		},
+		{
+			Flag:        "once",
+			Description: "Print the processes once and exit, instead of refreshing them. This is implied when the output is not a terminal.",
+			Value:       serpent.BoolOf(&once),
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
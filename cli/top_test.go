@@ -0,0 +1,63 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agenttest"
+	"github.com/onchainengineering/hmi-wirtual/cli/clitest"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestTop(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS != "linux" {
+		t.Skip("processes are only listed on Linux")
+	}
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t)
+	_ = agenttest.New(t, client.URL, agentToken)
+	_ = wirtualdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	t.Run("Table", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		inv, root := clitest.New(t, "top", workspace.Name, "--once")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		require.NoError(t, inv.WithContext(ctx).Run())
+		require.Contains(t, out.String(), "PID")
+		require.Contains(t, out.String(), "COMMAND")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		inv, root := clitest.New(t, "top", workspace.Name, "--once", "--limit", "0", "--output", "json")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		require.NoError(t, inv.WithContext(ctx).Run())
+
+		var procs []wirtualsdk.WorkspaceAgentProcess
+		require.NoError(t, json.Unmarshal(out.Bytes(), &procs))
+		// The agent runs in the test process, so it must be listed.
+		pid := int32(os.Getpid())
+		require.True(t, func() bool {
+			for _, proc := range procs {
+				if proc.PID == pid {
+					return true
+				}
+			}
+			return false
+		}(), "test process %d not listed", pid)
+	})
+}
@@ -20,7 +20,7 @@ var AuditActionMap = map[string][]wirtualsdk.AuditAction{
 	"Template":        {wirtualsdk.AuditActionWrite, wirtualsdk.AuditActionDelete},
 	"TemplateVersion": {wirtualsdk.AuditActionCreate, wirtualsdk.AuditActionWrite},
 	"User":            {wirtualsdk.AuditActionCreate, wirtualsdk.AuditActionWrite, wirtualsdk.AuditActionDelete},
-	"Workspace":       {wirtualsdk.AuditActionCreate, wirtualsdk.AuditActionWrite, wirtualsdk.AuditActionDelete},
+	"Workspace":       {wirtualsdk.AuditActionCreate, wirtualsdk.AuditActionWrite, wirtualsdk.AuditActionDelete, wirtualsdk.AuditActionExec},
 	"WorkspaceBuild":  {wirtualsdk.AuditActionStart, wirtualsdk.AuditActionStop},
 	"Group":           {wirtualsdk.AuditActionCreate, wirtualsdk.AuditActionWrite, wirtualsdk.AuditActionDelete},
 	"APIKey":          {wirtualsdk.AuditActionLogin, wirtualsdk.AuditActionLogout, wirtualsdk.AuditActionRegister, wirtualsdk.AuditActionCreate, wirtualsdk.AuditActionDelete},
@@ -111,6 +111,7 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"deprecated":                        ActionTrack,
 		"max_port_sharing_level":            ActionTrack,
 		"activity_bump":                     ActionTrack,
+		"job_timeout":                       ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":                      ActionTrack,
@@ -128,6 +129,8 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"created_by_username":     ActionIgnore,
 		"archived":                ActionTrack,
 		"source_example_id":       ActionIgnore, // Never changes.
+		"git_commit_sha":          ActionIgnore, // Never changes.
+		"git_commit_author":       ActionIgnore, // Never changes.
 	},
 	&database.User{}: {
 		"id":                           ActionTrack,
@@ -247,6 +250,7 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"derp_only":           ActionTrack,
 		"region_id":           ActionTrack,
 		"version":             ActionTrack,
+		"draining":            ActionTrack,
 	},
 	&database.OAuth2ProviderApp{}: {
 		"id":           ActionIgnore,
@@ -24,6 +24,7 @@ import (
 	"github.com/onchainengineering/hmi-wirtual/cli/clilog"
 	"github.com/onchainengineering/hmi-wirtual/cli/cliui"
 	"github.com/onchainengineering/hmi-wirtual/cli/cliutil"
+	"github.com/onchainengineering/hmi-wirtual/provisioner/exec"
 	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform"
 	"github.com/onchainengineering/hmi-wirtual/provisionerd"
 	provisionerdproto "github.com/onchainengineering/hmi-wirtual/provisionerd/proto"
@@ -48,6 +49,8 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 		preSharedKey   string
 		provisionerKey string
 		verbose        bool
+		engine         string
+		serveExec      bool
 
 		prometheusEnable  bool
 		prometheusAddress string
@@ -163,6 +166,20 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				delete(tags, provisionersdk.TagScope)
 			}
 
+			tfEngine, err := terraform.ParseEngine(engine)
+			if err != nil {
+				return err
+			}
+			// Advertise engines other than Terraform so templates can require
+			// them with a tag. Tags of provisioner keys are set on the key.
+			if tfEngine != terraform.EngineTerraform {
+				if _, ok := tags[provisionersdk.TagEngine]; !ok && provisionerKey == "" {
+					logger.Info(ctx, "terraform engine automatically sets tag "+provisionersdk.TagEngine+"="+string(tfEngine))
+					tags[provisionersdk.TagEngine] = string(tfEngine)
+					displayedTags[provisionersdk.TagEngine] = string(tfEngine)
+				}
+			}
+
 			err = os.MkdirAll(cacheDir, 0o700)
 			if err != nil {
 				return xerrors.Errorf("mkdir %q: %w", cacheDir, err)
@@ -191,6 +208,7 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 						WorkDirectory: tempDir,
 					},
 					CachePath: cacheDir,
+					Engine:    tfEngine,
 				})
 				if err != nil && !xerrors.Is(err, context.Canceled) {
 					select {
@@ -200,6 +218,38 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				}
 			}()
 
+			connector := provisionerd.LocalProvisioners{
+				string(database.ProvisionerTypeTerraform): proto.NewDRPCProvisionerClient(terraformClient),
+			}
+			provisioners := []wirtualsdk.ProvisionerType{wirtualsdk.ProvisionerTypeTerraform}
+			if serveExec {
+				execClient, execServer := drpc.MemTransportPipe()
+				go func() {
+					<-ctx.Done()
+					_ = execClient.Close()
+					_ = execServer.Close()
+				}()
+				go func() {
+					defer cancel()
+
+					err := exec.Serve(ctx, &exec.ServeOptions{
+						ServeOptions: &provisionersdk.ServeOptions{
+							Listener:      execServer,
+							Logger:        logger.Named("exec"),
+							WorkDirectory: tempDir,
+						},
+					})
+					if err != nil && !xerrors.Is(err, context.Canceled) {
+						select {
+						case errCh <- err:
+						default:
+						}
+					}
+				}()
+				connector[string(database.ProvisionerTypeExec)] = proto.NewDRPCProvisionerClient(execClient)
+				provisioners = append(provisioners, wirtualsdk.ProvisionerTypeExec)
+			}
+
 			var metrics *provisionerd.Metrics
 			if prometheusEnable {
 				logger.Info(ctx, "starting Prometheus endpoint", slog.F("address", prometheusAddress))
@@ -220,16 +270,11 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 
 			logger.Info(ctx, "starting provisioner daemon", slog.F("tags", displayedTags), slog.F("name", name))
 
-			connector := provisionerd.LocalProvisioners{
-				string(database.ProvisionerTypeTerraform): proto.NewDRPCProvisionerClient(terraformClient),
-			}
 			srv := provisionerd.New(func(ctx context.Context) (provisionerdproto.DRPCProvisionerDaemonClient, error) {
 				return client.ServeProvisionerDaemon(ctx, wirtualsdk.ServeProvisionerDaemonRequest{
-					ID:   uuid.New(),
-					Name: name,
-					Provisioners: []wirtualsdk.ProvisionerType{
-						wirtualsdk.ProvisionerTypeTerraform,
-					},
+					ID:             uuid.New(),
+					Name:           name,
+					Provisioners:   provisioners,
 					Tags:           tags,
 					PreSharedKey:   preSharedKey,
 					Organization:   orgID,
@@ -303,6 +348,20 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 			Description:   "Tags to filter provisioner jobs by.",
 			Value:         serpent.StringArrayOf(&rawTags),
 		},
+		{
+			Flag:        "terraform-engine",
+			Env:         "WIRTUAL_PROVISIONER_DAEMON_TERRAFORM_ENGINE",
+			Description: "The infrastructure as code engine that runs Terraform templates. Daemons running OpenTofu are tagged with engine=opentofu unless the engine tag is set.",
+			Default:     string(terraform.EngineTerraform),
+			Value:       serpent.EnumOf(&engine, string(terraform.EngineTerraform), string(terraform.EngineOpenTofu)),
+		},
+		{
+			Flag:        "exec",
+			Env:         "WIRTUAL_PROVISIONER_DAEMON_EXEC",
+			Description: "Also run templates of the exec provisioner type. Their provision executable runs on the host of this daemon.",
+			Value:       serpent.BoolOf(&serveExec),
+			Default:     "false",
+		},
 		{
 			Flag:        "poll-interval",
 			Env:         "WIRTUAL_PROVISIONERD_POLL_INTERVAL",
@@ -202,6 +202,10 @@ func TestServerDBCrypt(t *testing.T) {
 		require.NoError(t, err, "failed to get git auth links for user %s", usr.ID)
 		require.Empty(t, gitAuthLinks)
 	}
+	// Assert that no deploy keys remain.
+	sources, err := db.GetTemplateGitSourcesWithDeployKeys(ctx)
+	require.NoError(t, err, "failed to get template git sources")
+	require.Empty(t, sources)
 
 	// Validate that the key has been revoked in the database.
 	keys, err = db.GetDBCryptKeys(ctx)
@@ -216,6 +220,7 @@ func TestServerDBCrypt(t *testing.T) {
 func genData(t *testing.T, db database.Store) []database.User {
 	t.Helper()
 	var users []database.User
+	org := dbgen.Organization(t, db, database.Organization{})
 	// Make some users
 	for _, status := range database.AllUserStatusValues() {
 		for _, loginType := range database.AllLoginTypeValues() {
@@ -245,6 +250,16 @@ func genData(t *testing.T, db database.Store) []database.User {
 						OAuthAccessToken:  "access-" + usr.ID.String(),
 						OAuthRefreshToken: "refresh-" + usr.ID.String(),
 					})
+					tpl := dbgen.Template(t, db, database.Template{
+						OrganizationID: org.ID,
+						CreatedBy:      usr.ID,
+					})
+					_ = dbgen.TemplateGitSource(t, db, database.TemplateGitSource{
+						TemplateID:       tpl.ID,
+						UserID:           usr.ID,
+						DeployPrivateKey: "deploy-" + tpl.ID.String(),
+						DeployPublicKey:  "public-" + tpl.ID.String(),
+					})
 				}
 				users = append(users, usr)
 			}
@@ -287,6 +302,15 @@ func requireEncryptedWithCipher(ctx context.Context, t *testing.T, db database.S
 		require.Equal(t, c.HexDigest(), gal.OAuthAccessTokenKeyID.String)
 		require.Equal(t, c.HexDigest(), gal.OAuthRefreshTokenKeyID.String)
 	}
+	sources, err := db.GetTemplateGitSourcesWithDeployKeys(ctx)
+	require.NoError(t, err, "failed to get template git sources")
+	for _, source := range sources {
+		if source.UserID != userID {
+			continue
+		}
+		requireEncryptedEquals(t, c, "deploy-"+source.TemplateID.String(), source.DeployPrivateKey)
+		require.Equal(t, c.HexDigest(), source.DeployPrivateKeyKeyID.String)
+	}
 }
 
 // nullCipher is a dbcrypt.Cipher that does not encrypt or decrypt.
@@ -31,6 +31,7 @@ func (r *RootCmd) workspaceProxy() *serpent.Command {
 			r.proxyServer(),
 			r.createProxy(),
 			r.deleteProxy(),
+			r.drainProxy(),
 			r.listProxies(),
 			r.patchProxy(),
 			r.regenerateProxyToken(),
@@ -229,6 +230,50 @@ func (r *RootCmd) deleteProxy() *serpent.Command {
 	return cmd
 }
 
+func (r *RootCmd) drainProxy() *serpent.Command {
+	var undo bool
+
+	client := new(wirtualsdk.Client)
+	cmd := &serpent.Command{
+		Use:   "drain <name|id>",
+		Short: "Stop routing new connections to a workspace proxy",
+		Long: "Draining removes the proxy from the DERP map and the list of regions, so new " +
+			"connections go elsewhere while existing connections finish. Wait for the active " +
+			"connections in the proxy status to reach zero before stopping it.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+
+			proxy, err := client.UpdateWorkspaceProxyDraining(ctx, inv.Args[0], wirtualsdk.UpdateWorkspaceProxyDrainingRequest{
+				Draining: !undo,
+			})
+			if err != nil {
+				return xerrors.Errorf("update workspace proxy %q: %w", inv.Args[0], err)
+			}
+
+			if undo {
+				_, _ = fmt.Fprintf(inv.Stdout, "Workspace proxy %q is no longer draining\n", proxy.Name)
+				return nil
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Workspace proxy %q is draining, %d connections are active\n",
+				proxy.Name, proxy.Status.Report.Load.ActiveConnections)
+			return nil
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "undo",
+			Description: "Stop draining the proxy, so it is advertised to clients again.",
+			Value:       serpent.BoolOf(&undo),
+		},
+	}
+	return cmd
+}
+
 func (r *RootCmd) createProxy() *serpent.Command {
 	var (
 		proxyName   string
@@ -11,7 +11,8 @@ import (
 )
 
 // Rotate rotates the database encryption keys by re-encrypting all user tokens
-// with the first cipher and revoking all other ciphers.
+// and template git source deploy keys with the first cipher and revoking all
+// other ciphers.
 func Rotate(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Cipher) error {
 	db := database.New(sqlDB)
 	cryptDB, err := New(ctx, db, ciphers...)
@@ -82,6 +83,25 @@ func Rotate(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciphe
 		log.Debug(ctx, "encrypted user tokens", slog.F("user_id", uid), slog.F("current", idx+1), slog.F("cipher", ciphers[0].HexDigest()))
 	}
 
+	sources, err := cryptDB.GetTemplateGitSourcesWithDeployKeys(ctx)
+	if err != nil {
+		return xerrors.Errorf("get template git sources: %w", err)
+	}
+	log.Info(ctx, "encrypting template git source deploy keys", slog.F("source_count", len(sources)))
+	for _, source := range sources {
+		if source.DeployPrivateKeyKeyID.String == ciphers[0].HexDigest() {
+			log.Debug(ctx, "skipping template git source", slog.F("template_id", source.TemplateID), slog.F("cipher", ciphers[0].HexDigest()))
+			continue
+		}
+		if err := cryptDB.UpdateTemplateGitSourceDeployKeyByTemplateID(ctx, database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams{
+			TemplateID:            source.TemplateID,
+			DeployPrivateKey:      source.DeployPrivateKey,
+			DeployPrivateKeyKeyID: sql.NullString{}, // dbcrypt will update as required
+		}); err != nil {
+			return xerrors.Errorf("update template git source template_id=%s: %w", source.TemplateID, err)
+		}
+	}
+
 	// Revoke old keys
 	for _, c := range ciphers[1:] {
 		if err := db.RevokeDBCryptKey(ctx, c.HexDigest()); err != nil {
@@ -93,7 +113,8 @@ func Rotate(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciphe
 	return nil
 }
 
-// Decrypt decrypts all user tokens and revokes all ciphers.
+// Decrypt decrypts all user tokens and template git source deploy keys, and
+// revokes all ciphers.
 func Decrypt(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Cipher) error {
 	db := database.New(sqlDB)
 	cdb, err := New(ctx, db, ciphers...)
@@ -172,6 +193,25 @@ func Decrypt(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciph
 		log.Debug(ctx, "decrypted user tokens", slog.F("user_id", uid), slog.F("current", idx+1), slog.F("cipher", ciphers[0].HexDigest()))
 	}
 
+	sources, err := cryptDB.GetTemplateGitSourcesWithDeployKeys(ctx)
+	if err != nil {
+		return xerrors.Errorf("get template git sources: %w", err)
+	}
+	log.Info(ctx, "decrypting template git source deploy keys", slog.F("source_count", len(sources)))
+	for _, source := range sources {
+		if !source.DeployPrivateKeyKeyID.Valid {
+			log.Debug(ctx, "skipping template git source", slog.F("template_id", source.TemplateID))
+			continue
+		}
+		if err := cryptDB.UpdateTemplateGitSourceDeployKeyByTemplateID(ctx, database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams{
+			TemplateID:            source.TemplateID,
+			DeployPrivateKey:      source.DeployPrivateKey,
+			DeployPrivateKeyKeyID: sql.NullString{}, // we explicitly want to clear the key id
+		}); err != nil {
+			return xerrors.Errorf("update template git source template_id=%s: %w", source.TemplateID, err)
+		}
+	}
+
 	// Revoke _all_ keys
 	for _, c := range ciphers {
 		if err := db.RevokeDBCryptKey(ctx, c.HexDigest()); err != nil {
@@ -192,10 +232,14 @@ DELETE FROM user_links
 DELETE FROM external_auth_links
 	WHERE oauth_access_token_key_id IS NOT NULL
 	OR oauth_refresh_token_key_id IS NOT NULL;
+UPDATE template_git_sources
+	SET deploy_private_key = '', deploy_private_key_key_id = NULL, deploy_public_key = ''
+	WHERE deploy_private_key_key_id IS NOT NULL;
 COMMIT;
 `
 
-// Delete deletes all user tokens and revokes all ciphers.
+// Delete deletes all user tokens and template git source deploy keys, and
+// revokes all ciphers.
 // This is a destructive operation and should only be used
 // as a last resort, for example, if the database encryption key has been
 // lost.
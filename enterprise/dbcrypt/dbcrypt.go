@@ -336,6 +336,86 @@ func (db *dbCrypt) GetCryptoKeysByFeature(ctx context.Context, feature database.
 	return keys, nil
 }
 
+func (db *dbCrypt) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	source, err := db.Store.GetTemplateGitSourceByTemplateID(ctx, templateID)
+	if err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	if err := db.decryptField(&source.DeployPrivateKey, source.DeployPrivateKeyKeyID); err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	return source, nil
+}
+
+func (db *dbCrypt) AcquireTemplateGitSources(ctx context.Context, params database.AcquireTemplateGitSourcesParams) ([]database.TemplateGitSource, error) {
+	sources, err := db.Store.AcquireTemplateGitSources(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.decryptTemplateGitSources(sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func (db *dbCrypt) GetTemplateGitSourcesWithCompletedJobs(ctx context.Context) ([]database.TemplateGitSource, error) {
+	sources, err := db.Store.GetTemplateGitSourcesWithCompletedJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.decryptTemplateGitSources(sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func (db *dbCrypt) GetTemplateGitSourcesWithDeployKeys(ctx context.Context) ([]database.TemplateGitSource, error) {
+	sources, err := db.Store.GetTemplateGitSourcesWithDeployKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.decryptTemplateGitSources(sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func (db *dbCrypt) UpsertTemplateGitSource(ctx context.Context, params database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	// Sources without a deploy key are left empty, so they can be found
+	// without decrypting them.
+	if params.DeployPrivateKey != "" {
+		if err := db.encryptField(&params.DeployPrivateKey, &params.DeployPrivateKeyKeyID); err != nil {
+			return database.TemplateGitSource{}, err
+		}
+	}
+	source, err := db.Store.UpsertTemplateGitSource(ctx, params)
+	if err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	if err := db.decryptField(&source.DeployPrivateKey, source.DeployPrivateKeyKeyID); err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	return source, nil
+}
+
+func (db *dbCrypt) UpdateTemplateGitSourceDeployKeyByTemplateID(ctx context.Context, params database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams) error {
+	if params.DeployPrivateKey != "" {
+		if err := db.encryptField(&params.DeployPrivateKey, &params.DeployPrivateKeyKeyID); err != nil {
+			return err
+		}
+	}
+	return db.Store.UpdateTemplateGitSourceDeployKeyByTemplateID(ctx, params)
+}
+
+func (db *dbCrypt) decryptTemplateGitSources(sources []database.TemplateGitSource) error {
+	for i := range sources {
+		if err := db.decryptField(&sources[i].DeployPrivateKey, sources[i].DeployPrivateKeyKeyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (db *dbCrypt) encryptField(field *string, digest *sql.NullString) error {
 	// If no cipher is loaded, then we can't encrypt anything!
 	if db.ciphers == nil || db.primaryCipherDigest == "" {
@@ -375,6 +375,147 @@ func TestExternalAuthLinks(t *testing.T) {
 	})
 }
 
+func TestTemplateGitSources(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// template creates a template that a git source can reference.
+	template := func(t *testing.T, db database.Store) database.Template {
+		org := dbgen.Organization(t, db, database.Organization{})
+		user := dbgen.User(t, db, database.User{})
+		return dbgen.Template(t, db, database.Template{
+			OrganizationID: org.ID,
+			CreatedBy:      user.ID,
+		})
+	}
+
+	t.Run("UpsertTemplateGitSource", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		tpl := template(t, crypt)
+		source := dbgen.TemplateGitSource(t, crypt, database.TemplateGitSource{
+			TemplateID:       tpl.ID,
+			UserID:           tpl.CreatedBy,
+			DeployPrivateKey: "private",
+		})
+		require.Equal(t, "private", source.DeployPrivateKey)
+
+		source, err := db.GetTemplateGitSourceByTemplateID(ctx, tpl.ID)
+		require.NoError(t, err)
+		requireEncryptedEquals(t, ciphers[0], source.DeployPrivateKey, "private")
+		require.Equal(t, ciphers[0].HexDigest(), source.DeployPrivateKeyKeyID.String)
+	})
+
+	t.Run("UpsertTemplateGitSourceWithoutKey", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, _ := setup(t)
+		tpl := template(t, crypt)
+		_ = dbgen.TemplateGitSource(t, crypt, database.TemplateGitSource{
+			TemplateID: tpl.ID,
+			UserID:     tpl.CreatedBy,
+		})
+
+		source, err := db.GetTemplateGitSourceByTemplateID(ctx, tpl.ID)
+		require.NoError(t, err)
+		require.Empty(t, source.DeployPrivateKey)
+		require.False(t, source.DeployPrivateKeyKeyID.Valid)
+	})
+
+	t.Run("UpdateTemplateGitSourceDeployKeyByTemplateID", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		tpl := template(t, crypt)
+		_ = dbgen.TemplateGitSource(t, db, database.TemplateGitSource{
+			TemplateID:       tpl.ID,
+			UserID:           tpl.CreatedBy,
+			DeployPrivateKey: "plaintext",
+		})
+		err := crypt.UpdateTemplateGitSourceDeployKeyByTemplateID(ctx, database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams{
+			TemplateID:       tpl.ID,
+			DeployPrivateKey: "private",
+		})
+		require.NoError(t, err)
+
+		source, err := db.GetTemplateGitSourceByTemplateID(ctx, tpl.ID)
+		require.NoError(t, err)
+		requireEncryptedEquals(t, ciphers[0], source.DeployPrivateKey, "private")
+		require.Equal(t, ciphers[0].HexDigest(), source.DeployPrivateKeyKeyID.String)
+	})
+
+	t.Run("GetTemplateGitSourceByTemplateID", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("OK", func(t *testing.T) {
+			t.Parallel()
+			_, crypt, _ := setup(t)
+			tpl := template(t, crypt)
+			_ = dbgen.TemplateGitSource(t, crypt, database.TemplateGitSource{
+				TemplateID:       tpl.ID,
+				UserID:           tpl.CreatedBy,
+				DeployPrivateKey: "private",
+			})
+			source, err := crypt.GetTemplateGitSourceByTemplateID(ctx, tpl.ID)
+			require.NoError(t, err)
+			require.Equal(t, "private", source.DeployPrivateKey)
+		})
+
+		t.Run("DecryptErr", func(t *testing.T) {
+			t.Parallel()
+			db, crypt, ciphers := setup(t)
+			tpl := template(t, crypt)
+			_ = dbgen.TemplateGitSource(t, db, database.TemplateGitSource{
+				TemplateID:            tpl.ID,
+				UserID:                tpl.CreatedBy,
+				DeployPrivateKey:      fakeBase64RandomData(t, 32),
+				DeployPrivateKeyKeyID: sql.NullString{String: ciphers[0].HexDigest(), Valid: true},
+			})
+			_, err := crypt.GetTemplateGitSourceByTemplateID(ctx, tpl.ID)
+			require.Error(t, err, "expected an error")
+			var derr *DecryptFailedError
+			require.ErrorAs(t, err, &derr, "expected a decrypt error")
+		})
+	})
+
+	t.Run("GetTemplateGitSourcesWithDeployKeys", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("OK", func(t *testing.T) {
+			t.Parallel()
+			_, crypt, _ := setup(t)
+			tpl := template(t, crypt)
+			_ = dbgen.TemplateGitSource(t, crypt, database.TemplateGitSource{
+				TemplateID:       tpl.ID,
+				UserID:           tpl.CreatedBy,
+				DeployPrivateKey: "private",
+			})
+			_ = dbgen.TemplateGitSource(t, crypt, database.TemplateGitSource{
+				TemplateID: template(t, crypt).ID,
+				UserID:     tpl.CreatedBy,
+			})
+			sources, err := crypt.GetTemplateGitSourcesWithDeployKeys(ctx)
+			require.NoError(t, err)
+			require.Len(t, sources, 1)
+			require.Equal(t, "private", sources[0].DeployPrivateKey)
+		})
+
+		t.Run("DecryptErr", func(t *testing.T) {
+			t.Parallel()
+			db, crypt, ciphers := setup(t)
+			tpl := template(t, crypt)
+			_ = dbgen.TemplateGitSource(t, db, database.TemplateGitSource{
+				TemplateID:            tpl.ID,
+				UserID:                tpl.CreatedBy,
+				DeployPrivateKey:      fakeBase64RandomData(t, 32),
+				DeployPrivateKeyKeyID: sql.NullString{String: ciphers[0].HexDigest(), Valid: true},
+			})
+			_, err := crypt.GetTemplateGitSourcesWithDeployKeys(ctx)
+			require.Error(t, err, "expected an error")
+			var derr *DecryptFailedError
+			require.ErrorAs(t, err, &derr, "expected a decrypt error")
+		})
+	})
+}
+
 func TestCryptoKeys(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -255,6 +255,7 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Get("/", api.workspaceProxy)
 				r.Patch("/", api.patchWorkspaceProxy)
 				r.Delete("/", api.deleteWorkspaceProxy)
+				r.Put("/draining", api.putWorkspaceProxyDraining)
 			})
 		})
 
@@ -1018,6 +1019,10 @@ func derpMapper(logger slog.Logger, proxyHealth *proxyhealth.ProxyHealth) func(*
 				RegionID:      regionID,
 				RegionCode:    regionCode,
 				RegionName:    regionName,
+				// Draining proxies stay in the map so connections relayed
+				// through them keep working, but clients move their home
+				// region elsewhere.
+				Avoid: status.Proxy.Draining,
 				Nodes: []*tailcfg.DERPNode{
 					{
 						Name:      fmt.Sprintf("%da", regionID),
@@ -233,6 +233,8 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 			provisionersMap[wirtualsdk.ProvisionerTypeEcho] = struct{}{}
 		case string(wirtualsdk.ProvisionerTypeTerraform):
 			provisionersMap[wirtualsdk.ProvisionerTypeTerraform] = struct{}{}
+		case string(wirtualsdk.ProvisionerTypeExec):
+			provisionersMap[wirtualsdk.ProvisionerTypeExec] = struct{}{}
 		default:
 			httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
 				Message: fmt.Sprintf("Unknown provisioner type %q", provisioner),
@@ -279,6 +281,8 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 			provisioners = append(provisioners, database.ProvisionerTypeTerraform)
 		case wirtualsdk.ProvisionerTypeEcho:
 			provisioners = append(provisioners, database.ProvisionerTypeEcho)
+		case wirtualsdk.ProvisionerTypeExec:
+			provisioners = append(provisioners, database.ProvisionerTypeExec)
 		}
 	}
 
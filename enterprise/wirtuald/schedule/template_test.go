@@ -241,7 +241,7 @@ func TestTemplateUpdateBuildDeadlines(t *testing.T) {
 			require.NotEmpty(t, wsBuild.ProvisionerState, "provisioner state must not be empty")
 
 			acquiredJob, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-				OrganizationID: job.OrganizationID,
+				OrganizationIDs: []uuid.UUID{job.OrganizationID},
 				StartedAt: sql.NullTime{
 					Time:  buildTime,
 					Valid: true,
@@ -525,7 +525,7 @@ func TestTemplateUpdateBuildDeadlinesSkip(t *testing.T) {
 		}
 
 		acquiredJob, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-			OrganizationID: job.OrganizationID,
+			OrganizationIDs: []uuid.UUID{job.OrganizationID},
 			StartedAt: sql.NullTime{
 				Time:  buildTime,
 				Valid: true,
@@ -73,8 +73,8 @@ func (api *API) fetchRegions(ctx context.Context) (wirtualsdk.RegionsResponse[wi
 
 	regions := make([]wirtualsdk.Region, 0, len(proxies.Regions))
 	for i := range proxies.Regions {
-		// Ignore deleted and DERP-only proxies.
-		if proxies.Regions[i].Deleted || proxies.Regions[i].DerpOnly {
+		// Ignore deleted, DERP-only and draining proxies.
+		if proxies.Regions[i].Deleted || proxies.Regions[i].DerpOnly || proxies.Regions[i].Draining {
 			continue
 		}
 		// Append the inner region data.
@@ -237,6 +237,68 @@ func (api *API) patchPrimaryWorkspaceProxy(req wirtualsdk.PatchWorkspaceProxy, r
 	return updatedProxy, true
 }
 
+// @Summary Update workspace proxy draining
+// @ID update-workspace-proxy-draining
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param workspaceproxy path string true "Proxy ID or name" format(uuid)
+// @Param request body wirtualsdk.UpdateWorkspaceProxyDrainingRequest true "Update workspace proxy draining request"
+// @Success 200 {object} wirtualsdk.WorkspaceProxy
+// @Router /workspaceproxies/{workspaceproxy}/draining [put]
+func (api *API) putWorkspaceProxyDraining(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		proxy             = httpmw.WorkspaceProxyParam(r)
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.WorkspaceProxy](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	aReq.Old = proxy
+	defer commitAudit()
+
+	var req wirtualsdk.UpdateWorkspaceProxyDrainingRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	if proxy.IsPrimary() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: "Cannot drain the primary proxy",
+		})
+		return
+	}
+
+	updatedProxy, err := api.Database.UpdateWorkspaceProxyDraining(ctx, database.UpdateWorkspaceProxyDrainingParams{
+		ID:       proxy.ID,
+		Draining: req.Draining,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	aReq.New = updatedProxy
+	status, ok := api.ProxyHealth.HealthStatus()[updatedProxy.ID]
+	if !ok {
+		status.Status = proxyhealth.Unknown
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertProxy(updatedProxy, status))
+
+	// The DERP map and regions are built from the proxy health cache, so
+	// update it to stop (or resume) advertising the proxy right away.
+	go api.forceWorkspaceProxyHealthUpdate(api.ctx)
+}
+
 // @Summary Delete workspace proxy
 // @ID delete-workspace-proxy
 // @Security CoderSessionToken
@@ -990,6 +1052,7 @@ func convertProxy(p database.WorkspaceProxy, status proxyhealth.ProxyStatus) wir
 		Region:      convertRegion(p, status),
 		DerpEnabled: p.DerpEnabled,
 		DerpOnly:    p.DerpOnly,
+		Draining:    p.Draining,
 		CreatedAt:   p.CreatedAt,
 		UpdatedAt:   p.UpdatedAt,
 		Deleted:     p.Deleted,
@@ -271,6 +271,135 @@ func TestWorkspaceProxyCRUD(t *testing.T) {
 	})
 }
 
+func TestWorkspaceProxyDraining(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		db, pubsub := dbtestutil.NewDB(t)
+		client, closer, api, _ := wirtualdenttest.NewWithAPI(t, &wirtualdenttest.Options{
+			Options: &wirtualdtest.Options{
+				AppHostname: "*.apps.coder.test",
+				Database:    db,
+				Pubsub:      pubsub,
+			},
+			LicenseOptions: &wirtualdenttest.LicenseOptions{
+				Features: license.Features{
+					wirtualsdk.FeatureWorkspaceProxy: 1,
+				},
+			},
+		})
+		t.Cleanup(func() {
+			_ = closer.Close()
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		const proxyName = "draining"
+		_ = wirtualdenttest.NewWorkspaceProxyReplica(t, api, client, &wirtualdenttest.ProxyOptions{
+			Name: proxyName,
+		})
+		require.Eventually(t, func() bool {
+			err := api.ProxyHealth.ForceUpdate(ctx)
+			if !assert.NoError(t, err) {
+				return false
+			}
+			regions, err := client.Regions(ctx)
+			if !assert.NoError(t, err) {
+				return false
+			}
+			return len(regions) == 2 && regions[1].Healthy
+		}, testutil.WaitLong, testutil.IntervalMedium)
+
+		proxyRegionAvoided := func() bool {
+			for _, region := range api.AGPL.DERPMap().Regions {
+				if region.RegionCode == "coder_"+proxyName {
+					return region.Avoid
+				}
+			}
+			require.Fail(t, "proxy region is not in the DERP map")
+			return false
+		}
+		require.False(t, proxyRegionAvoided())
+
+		proxy, err := client.UpdateWorkspaceProxyDraining(ctx, proxyName, wirtualsdk.UpdateWorkspaceProxyDrainingRequest{
+			Draining: true,
+		})
+		require.NoError(t, err)
+		require.True(t, proxy.Draining)
+
+		// The proxy is no longer offered as a region, but stays in the DERP
+		// map so relayed connections aren't dropped.
+		require.NoError(t, api.ProxyHealth.ForceUpdate(ctx))
+		regions, err := client.Regions(ctx)
+		require.NoError(t, err)
+		require.Len(t, regions, 1)
+		require.Equal(t, "primary", regions[0].Name)
+		require.True(t, proxyRegionAvoided())
+
+		proxy, err = client.UpdateWorkspaceProxyDraining(ctx, proxyName, wirtualsdk.UpdateWorkspaceProxyDrainingRequest{
+			Draining: false,
+		})
+		require.NoError(t, err)
+		require.False(t, proxy.Draining)
+
+		require.NoError(t, api.ProxyHealth.ForceUpdate(ctx))
+		regions, err = client.Regions(ctx)
+		require.NoError(t, err)
+		require.Len(t, regions, 2)
+		require.False(t, proxyRegionAvoided())
+	})
+
+	t.Run("Primary", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := wirtualdenttest.New(t, &wirtualdenttest.Options{
+			LicenseOptions: &wirtualdenttest.LicenseOptions{
+				Features: license.Features{
+					wirtualsdk.FeatureWorkspaceProxy: 1,
+				},
+			},
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.UpdateWorkspaceProxyDraining(ctx, "primary", wirtualsdk.UpdateWorkspaceProxyDrainingRequest{
+			Draining: true,
+		})
+		var sdkErr *wirtualsdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+	})
+
+	t.Run("RequiresUpdate", func(t *testing.T) {
+		t.Parallel()
+
+		client, owner := wirtualdenttest.New(t, &wirtualdenttest.Options{
+			LicenseOptions: &wirtualdenttest.LicenseOptions{
+				Features: license.Features{
+					wirtualsdk.FeatureWorkspaceProxy: 1,
+				},
+			},
+		})
+		member, _ := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+		ctx := testutil.Context(t, testutil.WaitLong)
+		proxyRes, err := client.CreateWorkspaceProxy(ctx, wirtualsdk.CreateWorkspaceProxyRequest{
+			Name: testutil.GetRandomName(t),
+		})
+		require.NoError(t, err)
+
+		_, err = member.UpdateWorkspaceProxyDraining(ctx, proxyRes.Proxy.ID.String(), wirtualsdk.UpdateWorkspaceProxyDrainingRequest{
+			Draining: true,
+		})
+		var sdkErr *wirtualsdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+
+		found, err := client.WorkspaceProxyByID(ctx, proxyRes.Proxy.ID)
+		require.NoError(t, err)
+		require.False(t, found.Draining)
+	})
+}
+
 func TestProxyRegisterDeregister(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,170 @@
+package wsproxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// loadSampleInterval is how often the byte rates in load reports are updated.
+const loadSampleInterval = 10 * time.Second
+
+// loadTracker measures the load on the proxy, so it can be included in the
+// health report and used by external autoscalers.
+type loadTracker struct {
+	activeConns atomic.Int64
+	rxBytes     atomic.Int64
+	txBytes     atomic.Int64
+
+	mu          sync.Mutex
+	lastSample  time.Time
+	lastRxBytes int64
+	lastTxBytes int64
+	rxRate      float64
+	txRate      float64
+}
+
+func newLoadTracker() *loadTracker {
+	return &loadTracker{lastSample: time.Now()}
+}
+
+// Run samples the byte counters every loadSampleInterval until ctx is done.
+func (t *loadTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(loadSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.sample(now)
+		}
+	}
+}
+
+func (t *loadTracker) sample(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elapsed := now.Sub(t.lastSample).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	rx, tx := t.rxBytes.Load(), t.txBytes.Load()
+	t.rxRate = float64(rx-t.lastRxBytes) / elapsed
+	t.txRate = float64(tx-t.lastTxBytes) / elapsed
+	t.lastSample = now
+	t.lastRxBytes = rx
+	t.lastTxBytes = tx
+}
+
+// Report returns the current load.
+func (t *loadTracker) Report() wirtualsdk.ProxyLoadReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return wirtualsdk.ProxyLoadReport{
+		ActiveConnections: t.activeConns.Load(),
+		RxBytesPerSecond:  t.rxRate,
+		TxBytesPerSecond:  t.txRate,
+	}
+}
+
+// Middleware counts in-flight requests and the bytes read from and written to
+// clients. Upgraded connections are counted until their handler returns, and
+// their traffic is counted through the hijacked connection.
+func (t *loadTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		t.activeConns.Add(1)
+		defer t.activeConns.Add(-1)
+
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &countingReadCloser{ReadCloser: r.Body, n: &t.rxBytes}
+		}
+		next.ServeHTTP(&countingResponseWriter{ResponseWriter: rw, tracker: t}, r)
+	})
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+type countingResponseWriter struct {
+	http.ResponseWriter
+	tracker *loadTracker
+}
+
+func (w *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.tracker.txBytes.Add(int64(n))
+	return n, err
+}
+
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer.
+func (w *countingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *countingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, xerrors.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+	}
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	// Data already buffered by the server must be read before the
+	// connection, so it isn't lost by replacing the reader. Reading only the
+	// buffered bytes never reads from the connection.
+	buffered := make([]byte, brw.Reader.Buffered())
+	_, _ = io.ReadFull(brw.Reader, buffered)
+	cc := &countingConn{
+		Conn:    conn,
+		tracker: w.tracker,
+		reader:  io.MultiReader(bytes.NewReader(buffered), conn),
+	}
+	return cc, bufio.NewReadWriter(bufio.NewReader(cc), bufio.NewWriter(cc)), nil
+}
+
+type countingConn struct {
+	net.Conn
+	tracker *loadTracker
+	// reader returns data buffered before the connection was hijacked, then
+	// data from the connection.
+	reader io.Reader
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.tracker.rxBytes.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.tracker.txBytes.Add(int64(n))
+	return n, err
+}
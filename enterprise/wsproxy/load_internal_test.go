@@ -0,0 +1,52 @@
+package wsproxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := newLoadTracker()
+	start := tracker.lastSample
+
+	inHandler := make(chan struct{})
+	release := make(chan struct{})
+	handler := tracker.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		close(inHandler)
+		<-release
+		_, _ = rw.Write([]byte("hello"))
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789"))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	<-inHandler
+	require.EqualValues(t, 1, tracker.Report().ActiveConnections)
+	close(release)
+	<-done
+	require.EqualValues(t, 0, tracker.Report().ActiveConnections)
+
+	tracker.sample(start.Add(time.Second))
+	report := tracker.Report()
+	require.Equal(t, 10.0, report.RxBytesPerSecond)
+	require.Equal(t, 5.0, report.TxBytesPerSecond)
+
+	// Rates only cover the bytes since the last sample.
+	tracker.sample(start.Add(2 * time.Second))
+	report = tracker.Report()
+	require.Zero(t, report.RxBytesPerSecond)
+	require.Zero(t, report.TxBytesPerSecond)
+}
@@ -143,6 +143,9 @@ type Server struct {
 	replicaErrMut           sync.Mutex
 	replicaErr              string
 
+	// load is reported in the health report for external autoscalers.
+	load *loadTracker
+
 	// Used for graceful shutdown. Required for the dialer.
 	ctx           context.Context
 	cancel        context.CancelFunc
@@ -236,7 +239,9 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		derpMeshTLSConfig:        meshTLSConfig,
 		apiKeyEncryptionKeycache: encryptionCache,
 		appTokenSigningKeycache:  signingCache,
+		load:                     newLoadTracker(),
 	}
+	go s.load.Run(ctx)
 
 	// Register the workspace proxy with the primary wirtuald instance and start a
 	// goroutine to periodically re-register.
@@ -332,6 +337,7 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 	r.Use(
 		// TODO: @emyrk Should we standardize these in some other package?
 		httpmw.Recover(s.Logger),
+		s.load.Middleware,
 		tracing.StatusWriterMiddleware,
 		tracing.Middleware(s.TracerProvider),
 		httpmw.AttachRequestID,
@@ -597,6 +603,7 @@ func (s *Server) healthReport(rw http.ResponseWriter, r *http.Request) {
 		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, "workspace proxy in middle of shutting down")
 		return
 	}
+	report.Load = s.load.Report()
 
 	// Hit the build info to do basic version checking.
 	primaryBuild, err := s.SDKClient.SDKClient.BuildInfo(ctx)
@@ -0,0 +1,11 @@
+package provisioner
+
+import "regexp"
+
+// DNSRecordNameRegex is the regex used to validate the name of a
+// coder_dns_record resource. It is one or more dot-separated DNS labels,
+// optionally prefixed with a "*." wildcard label, or a lone "*" which matches
+// every name in the workspace. Labels follow the same rules as app slugs.
+//
+// There are test cases for this regex in dnsrecord_test.go.
+var DNSRecordNameRegex = regexp.MustCompile(`^(\*|(\*\.)?[a-z0-9](-?[a-z0-9])*(\.[a-z0-9](-?[a-z0-9])*)*)$`)
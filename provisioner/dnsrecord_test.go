@@ -0,0 +1,58 @@
+package provisioner_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/provisioner"
+)
+
+func TestValidDNSRecordNameRegex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		validStrings := []string{
+			"*",
+			"a",
+			"api",
+			"api-v2",
+			"grafana.metrics",
+			"*.apps",
+			"*.a.b.c",
+			"1-1.2",
+		}
+
+		for _, s := range validStrings {
+			require.True(t, provisioner.DNSRecordNameRegex.MatchString(s), s)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		invalidStrings := []string{
+			"",
+			"**",
+			"*.",
+			"-",
+			"a-",
+			"-a",
+			"a--b",
+			"a.",
+			".a",
+			"a..b",
+			"API",
+			"a.*.b",
+			"*.*.a",
+			"a_b",
+			"a b",
+		}
+
+		for _, s := range invalidStrings {
+			require.False(t, provisioner.DNSRecordNameRegex.MatchString(s), s)
+		}
+	})
+}
@@ -0,0 +1,471 @@
+package exec
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/onchainengineering/hmi-wirtual/provisioner"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+)
+
+// The types in this file are the JSON contract between the exec provisioner
+// and the "provision" executable of a template. The executable is run with the
+// action as its only argument, reads a Request from stdin and writes a
+// ParseResponse or a Response to stdout. Changes must be backwards compatible,
+// since templates are written against this contract.
+
+const (
+	ActionParse = "parse"
+	ActionPlan  = "plan"
+	ActionApply = "apply"
+)
+
+// Request is written to the stdin of the executable.
+type Request struct {
+	Action string `json:"action"`
+	// Transition is one of start, stop or destroy. It is empty for parse.
+	Transition string     `json:"transition,omitempty"`
+	Workspace  *Workspace `json:"workspace,omitempty"`
+	// Parameters maps the name of each rich parameter to its value.
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Variables maps the name of each template variable to its value.
+	Variables map[string]string `json:"variables,omitempty"`
+	// ExternalAuth maps the ID of each external auth provider to the access
+	// token of the workspace owner.
+	ExternalAuth map[string]string `json:"external_auth,omitempty"`
+	// AgentInitScripts maps "<os>_<arch>" to a script that downloads and
+	// starts the agent. The script reads the agent token from the
+	// WIRTUAL_AGENT_TOKEN environment variable.
+	AgentInitScripts map[string]string `json:"agent_init_scripts,omitempty"`
+	// State is the state returned by the last apply of the workspace, if any.
+	State json.RawMessage `json:"state,omitempty"`
+}
+
+// Workspace describes the workspace being built.
+type Workspace struct {
+	ID                   string   `json:"id"`
+	Name                 string   `json:"name"`
+	BuildID              string   `json:"build_id"`
+	AccessURL            string   `json:"access_url"`
+	TemplateID           string   `json:"template_id"`
+	TemplateName         string   `json:"template_name"`
+	TemplateVersion      string   `json:"template_version"`
+	OwnerID              string   `json:"owner_id"`
+	OwnerName            string   `json:"owner_name"`
+	OwnerFullName        string   `json:"owner_full_name"`
+	OwnerEmail           string   `json:"owner_email"`
+	OwnerGroups          []string `json:"owner_groups"`
+	OwnerLoginType       string   `json:"owner_login_type"`
+	OwnerSessionToken    string   `json:"owner_session_token"`
+	OwnerOIDCAccessToken string   `json:"owner_oidc_access_token"`
+	OwnerSSHPublicKey    string   `json:"owner_ssh_public_key"`
+	OwnerSSHPrivateKey   string   `json:"owner_ssh_private_key"`
+}
+
+// ParseResponse is written to stdout by the parse action.
+type ParseResponse struct {
+	Error         string            `json:"error,omitempty"`
+	Variables     []Variable        `json:"variables,omitempty"`
+	WorkspaceTags map[string]string `json:"workspace_tags,omitempty"`
+}
+
+// Variable is a template variable, set by template admins when a template
+// version is created.
+type Variable struct {
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	Type         string `json:"type,omitempty"`
+	DefaultValue string `json:"default_value,omitempty"`
+	Required     bool   `json:"required,omitempty"`
+	Sensitive    bool   `json:"sensitive,omitempty"`
+}
+
+// Response is written to stdout by the plan and apply actions.
+type Response struct {
+	Error        string         `json:"error,omitempty"`
+	Resources    []Resource     `json:"resources,omitempty"`
+	Parameters   []Parameter    `json:"parameters,omitempty"`
+	ExternalAuth []ExternalAuth `json:"external_auth,omitempty"`
+	Timings      []Timing       `json:"timings,omitempty"`
+	// State is stored after an apply and passed to the next build of the
+	// workspace.
+	State json.RawMessage `json:"state,omitempty"`
+}
+
+type Resource struct {
+	Name         string             `json:"name"`
+	Type         string             `json:"type"`
+	Hide         bool               `json:"hide,omitempty"`
+	Icon         string             `json:"icon,omitempty"`
+	InstanceType string             `json:"instance_type,omitempty"`
+	DailyCost    int32              `json:"daily_cost,omitempty"`
+	Metadata     []ResourceMetadata `json:"metadata,omitempty"`
+	Agents       []Agent            `json:"agents,omitempty"`
+}
+
+type ResourceMetadata struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+type Agent struct {
+	// ID is generated when omitted.
+	ID              string            `json:"id,omitempty"`
+	Name            string            `json:"name"`
+	OperatingSystem string            `json:"operating_system"`
+	Architecture    string            `json:"architecture"`
+	Directory       string            `json:"directory,omitempty"`
+	Env             map[string]string `json:"env,omitempty"`
+	// Token authenticates the agent. Either Token or InstanceID must be set
+	// by apply.
+	Token                    string          `json:"token,omitempty"`
+	InstanceID               string          `json:"instance_id,omitempty"`
+	ConnectionTimeoutSeconds int32           `json:"connection_timeout_seconds,omitempty"`
+	TroubleshootingURL       string          `json:"troubleshooting_url,omitempty"`
+	MOTDFile                 string          `json:"motd_file,omitempty"`
+	Order                    int64           `json:"order,omitempty"`
+	Apps                     []App           `json:"apps,omitempty"`
+	Scripts                  []Script        `json:"scripts,omitempty"`
+	Metadata                 []AgentMetadata `json:"metadata,omitempty"`
+	DNSRecords               []string        `json:"dns_records,omitempty"`
+}
+
+type App struct {
+	Slug        string `json:"slug"`
+	DisplayName string `json:"display_name,omitempty"`
+	Command     string `json:"command,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Subdomain   bool   `json:"subdomain,omitempty"`
+	External    bool   `json:"external,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
+	Order       int64  `json:"order,omitempty"`
+	// Share is one of owner, authenticated or public. Defaults to owner.
+	Share       string       `json:"share,omitempty"`
+	Healthcheck *Healthcheck `json:"healthcheck,omitempty"`
+}
+
+type Healthcheck struct {
+	URL       string `json:"url"`
+	Interval  int32  `json:"interval"`
+	Threshold int32  `json:"threshold"`
+}
+
+type Script struct {
+	DisplayName      string `json:"display_name"`
+	Icon             string `json:"icon,omitempty"`
+	Script           string `json:"script"`
+	Cron             string `json:"cron,omitempty"`
+	StartBlocksLogin bool   `json:"start_blocks_login,omitempty"`
+	RunOnStart       bool   `json:"run_on_start,omitempty"`
+	RunOnStop        bool   `json:"run_on_stop,omitempty"`
+	TimeoutSeconds   int32  `json:"timeout_seconds,omitempty"`
+	LogPath          string `json:"log_path,omitempty"`
+}
+
+type AgentMetadata struct {
+	Key         string `json:"key"`
+	DisplayName string `json:"display_name"`
+	Script      string `json:"script"`
+	Interval    int64  `json:"interval"`
+	Timeout     int64  `json:"timeout,omitempty"`
+	Order       int64  `json:"order,omitempty"`
+}
+
+// Parameter is a rich parameter that users set when creating or updating a
+// workspace.
+type Parameter struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Type is one of string, number, bool or list(string). Defaults to
+	// string.
+	Type         string            `json:"type,omitempty"`
+	Mutable      bool              `json:"mutable,omitempty"`
+	DefaultValue string            `json:"default_value,omitempty"`
+	Icon         string            `json:"icon,omitempty"`
+	Options      []ParameterOption `json:"options,omitempty"`
+	Validation   *Validation       `json:"validation,omitempty"`
+	Required     bool              `json:"required,omitempty"`
+	Order        int32             `json:"order,omitempty"`
+	Ephemeral    bool              `json:"ephemeral,omitempty"`
+}
+
+type ParameterOption struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Value       string `json:"value"`
+	Icon        string `json:"icon,omitempty"`
+}
+
+type Validation struct {
+	Regex     string `json:"regex,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Min       *int32 `json:"min,omitempty"`
+	Max       *int32 `json:"max,omitempty"`
+	Monotonic string `json:"monotonic,omitempty"`
+}
+
+type ExternalAuth struct {
+	ID       string `json:"id"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Timing reports how long a step of the executable took. The stage is set to
+// the action that reported it.
+type Timing struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Action   string    `json:"action"`
+	Source   string    `json:"source"`
+	Resource string    `json:"resource"`
+}
+
+// convertResources validates the resources and converts them to their proto
+// equivalents. Agents must be authenticated when requireAuth is set, which is
+// the case for apply.
+func convertResources(resources []Resource, requireAuth bool) ([]*proto.Resource, error) {
+	converted := make([]*proto.Resource, 0, len(resources))
+	agentNames := map[string]struct{}{}
+	for _, r := range resources {
+		if r.Name == "" || r.Type == "" {
+			return nil, xerrors.New("resources must have a name and type")
+		}
+		resource := &proto.Resource{
+			Name:         r.Name,
+			Type:         r.Type,
+			Hide:         r.Hide,
+			Icon:         r.Icon,
+			InstanceType: r.InstanceType,
+			DailyCost:    r.DailyCost,
+		}
+		for _, m := range r.Metadata {
+			resource.Metadata = append(resource.Metadata, &proto.Resource_Metadata{
+				Key:       m.Key,
+				Value:     m.Value,
+				Sensitive: m.Sensitive,
+			})
+		}
+		for _, a := range r.Agents {
+			if _, ok := agentNames[a.Name]; ok {
+				return nil, xerrors.Errorf("duplicate agent name %q", a.Name)
+			}
+			agentNames[a.Name] = struct{}{}
+			agent, err := convertAgent(a, requireAuth)
+			if err != nil {
+				return nil, xerrors.Errorf("agent %q: %w", a.Name, err)
+			}
+			resource.Agents = append(resource.Agents, agent)
+		}
+		converted = append(converted, resource)
+	}
+	return converted, nil
+}
+
+func convertAgent(a Agent, requireAuth bool) (*proto.Agent, error) {
+	if a.Name == "" {
+		return nil, xerrors.New("name is required")
+	}
+	if a.OperatingSystem == "" || a.Architecture == "" {
+		return nil, xerrors.New("operating_system and architecture are required")
+	}
+	id := a.ID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	agent := &proto.Agent{
+		Id:                       id,
+		Name:                     a.Name,
+		Env:                      a.Env,
+		OperatingSystem:          a.OperatingSystem,
+		Architecture:             a.Architecture,
+		Directory:                a.Directory,
+		ConnectionTimeoutSeconds: a.ConnectionTimeoutSeconds,
+		TroubleshootingUrl:       a.TroubleshootingURL,
+		MotdFile:                 a.MOTDFile,
+		Order:                    a.Order,
+		DisplayApps:              provisionersdk.DefaultDisplayApps(),
+	}
+	switch {
+	case a.Token != "" && a.InstanceID != "":
+		return nil, xerrors.New("only one of token and instance_id may be set")
+	case a.Token != "":
+		agent.Auth = &proto.Agent_Token{Token: a.Token}
+	case a.InstanceID != "":
+		agent.Auth = &proto.Agent_InstanceId{InstanceId: a.InstanceID}
+	case requireAuth:
+		return nil, xerrors.New("token or instance_id is required")
+	}
+	for _, app := range a.Apps {
+		if !provisioner.AppSlugRegex.MatchString(app.Slug) {
+			return nil, xerrors.Errorf("app slug %q does not match regex %q", app.Slug, provisioner.AppSlugRegex.String())
+		}
+		sharingLevel, err := convertSharingLevel(app.Share)
+		if err != nil {
+			return nil, xerrors.Errorf("app %q: %w", app.Slug, err)
+		}
+		converted := &proto.App{
+			Slug:         app.Slug,
+			DisplayName:  app.DisplayName,
+			Command:      app.Command,
+			Url:          app.URL,
+			Icon:         app.Icon,
+			Subdomain:    app.Subdomain,
+			External:     app.External,
+			Hidden:       app.Hidden,
+			Order:        app.Order,
+			SharingLevel: sharingLevel,
+		}
+		if app.Healthcheck != nil {
+			converted.Healthcheck = &proto.Healthcheck{
+				Url:       app.Healthcheck.URL,
+				Interval:  app.Healthcheck.Interval,
+				Threshold: app.Healthcheck.Threshold,
+			}
+		}
+		agent.Apps = append(agent.Apps, converted)
+	}
+	for _, s := range a.Scripts {
+		agent.Scripts = append(agent.Scripts, &proto.Script{
+			DisplayName:      s.DisplayName,
+			Icon:             s.Icon,
+			Script:           s.Script,
+			Cron:             s.Cron,
+			StartBlocksLogin: s.StartBlocksLogin,
+			RunOnStart:       s.RunOnStart,
+			RunOnStop:        s.RunOnStop,
+			TimeoutSeconds:   s.TimeoutSeconds,
+			LogPath:          s.LogPath,
+		})
+	}
+	for _, m := range a.Metadata {
+		agent.Metadata = append(agent.Metadata, &proto.Agent_Metadata{
+			Key:         m.Key,
+			DisplayName: m.DisplayName,
+			Script:      m.Script,
+			Interval:    m.Interval,
+			Timeout:     m.Timeout,
+			Order:       m.Order,
+		})
+	}
+	for _, name := range a.DNSRecords {
+		if !provisioner.DNSRecordNameRegex.MatchString(name) {
+			return nil, xerrors.Errorf("dns record %q does not match regex %q", name, provisioner.DNSRecordNameRegex.String())
+		}
+		agent.DnsRecords = append(agent.DnsRecords, &proto.DNSRecord{Name: name})
+	}
+	return agent, nil
+}
+
+func convertSharingLevel(share string) (proto.AppSharingLevel, error) {
+	switch strings.ToLower(share) {
+	case "", "owner":
+		return proto.AppSharingLevel_OWNER, nil
+	case "authenticated":
+		return proto.AppSharingLevel_AUTHENTICATED, nil
+	case "public":
+		return proto.AppSharingLevel_PUBLIC, nil
+	default:
+		return 0, xerrors.Errorf("invalid share %q, must be one of owner, authenticated or public", share)
+	}
+}
+
+func convertParameters(params []Parameter) ([]*proto.RichParameter, error) {
+	converted := make([]*proto.RichParameter, 0, len(params))
+	names := map[string]struct{}{}
+	for _, p := range params {
+		if p.Name == "" {
+			return nil, xerrors.New("parameters must have a name")
+		}
+		if _, ok := names[p.Name]; ok {
+			return nil, xerrors.Errorf("duplicate parameter name %q", p.Name)
+		}
+		names[p.Name] = struct{}{}
+		typ := p.Type
+		if typ == "" {
+			typ = "string"
+		}
+		param := &proto.RichParameter{
+			Name:         p.Name,
+			DisplayName:  p.DisplayName,
+			Description:  p.Description,
+			Type:         typ,
+			Mutable:      p.Mutable,
+			DefaultValue: p.DefaultValue,
+			Icon:         p.Icon,
+			Required:     p.Required,
+			Order:        p.Order,
+			Ephemeral:    p.Ephemeral,
+		}
+		for _, o := range p.Options {
+			param.Options = append(param.Options, &proto.RichParameterOption{
+				Name:        o.Name,
+				Description: o.Description,
+				Value:       o.Value,
+				Icon:        o.Icon,
+			})
+		}
+		if v := p.Validation; v != nil {
+			param.ValidationRegex = v.Regex
+			param.ValidationError = v.Error
+			param.ValidationMin = v.Min
+			param.ValidationMax = v.Max
+			param.ValidationMonotonic = v.Monotonic
+		}
+		converted = append(converted, param)
+	}
+	return converted, nil
+}
+
+func convertExternalAuth(providers []ExternalAuth) []*proto.ExternalAuthProviderResource {
+	converted := make([]*proto.ExternalAuthProviderResource, 0, len(providers))
+	for _, p := range providers {
+		converted = append(converted, &proto.ExternalAuthProviderResource{
+			Id:       p.ID,
+			Optional: p.Optional,
+		})
+	}
+	return converted
+}
+
+func convertTimings(timings []Timing, stage string) []*proto.Timing {
+	converted := make([]*proto.Timing, 0, len(timings))
+	for _, t := range timings {
+		converted = append(converted, &proto.Timing{
+			Start:    timestamppb.New(t.Start),
+			End:      timestamppb.New(t.End),
+			Action:   t.Action,
+			Source:   t.Source,
+			Resource: t.Resource,
+			Stage:    stage,
+			State:    proto.TimingState_COMPLETED,
+		})
+	}
+	return converted
+}
+
+func convertVariables(variables []Variable) []*proto.TemplateVariable {
+	converted := make([]*proto.TemplateVariable, 0, len(variables))
+	for _, v := range variables {
+		typ := v.Type
+		if typ == "" {
+			typ = "string"
+		}
+		converted = append(converted, &proto.TemplateVariable{
+			Name:         v.Name,
+			Description:  v.Description,
+			Type:         typ,
+			DefaultValue: v.DefaultValue,
+			Required:     v.Required,
+			Sensitive:    v.Sensitive,
+		})
+	}
+	return converted
+}
@@ -0,0 +1,367 @@
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"cdr.dev/slog"
+
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+)
+
+// planRequestFile stores the request of a plan in the session directory, so
+// that the following apply receives the same parameters and variables.
+const planRequestFile = ".exec-plan.json"
+
+// Parse runs the executable with the parse action to discover the template
+// variables and workspace tags.
+func (s *server) Parse(sess *provisionersdk.Session, _ *proto.ParseRequest, canceledOrComplete <-chan struct{}) *proto.ParseComplete {
+	ctx, cancel := s.setupContext(sess.Context(), canceledOrComplete)
+	defer cancel()
+
+	var resp ParseResponse
+	err := s.run(ctx, sess, &Request{Action: ActionParse}, &resp)
+	if resp.Error != "" {
+		return provisionersdk.ParseErrorf("%s", resp.Error)
+	}
+	if err != nil {
+		return provisionersdk.ParseErrorf("%s", err)
+	}
+
+	readme, err := os.ReadFile(filepath.Join(sess.WorkDirectory, "README.md"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return provisionersdk.ParseErrorf("read readme: %s", err)
+	}
+	return &proto.ParseComplete{
+		TemplateVariables: convertVariables(resp.Variables),
+		Readme:            readme,
+		WorkspaceTags:     resp.WorkspaceTags,
+	}
+}
+
+// Plan runs the executable with the plan action. The executable must not
+// change any infrastructure when planning.
+func (s *server) Plan(sess *provisionersdk.Session, request *proto.PlanRequest, canceledOrComplete <-chan struct{}) *proto.PlanComplete {
+	// Executables have no way to report drift, so refresh-only plans never
+	// find any.
+	if request.RefreshOnly {
+		sess.ProvisionLog(proto.LogLevel_INFO, "The exec provisioner doesn't support drift detection")
+		return &proto.PlanComplete{Engine: &proto.Engine{Name: "exec"}}
+	}
+
+	ctx, cancel := s.setupContext(sess.Context(), canceledOrComplete)
+	defer cancel()
+
+	req, err := newRequest(ActionPlan, sess.Config, request)
+	if err != nil {
+		return provisionersdk.PlanErrorf("%s", err)
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return provisionersdk.PlanErrorf("marshal request: %s", err)
+	}
+	// The request contains secrets, so it's only readable by the owner.
+	err = os.WriteFile(filepath.Join(sess.WorkDirectory, planRequestFile), data, 0o600)
+	if err != nil {
+		return provisionersdk.PlanErrorf("write plan request: %s", err)
+	}
+
+	var resp Response
+	start := time.Now()
+	err = s.run(ctx, sess, req, &resp)
+	if resp.Error != "" {
+		return provisionersdk.PlanErrorf("%s", resp.Error)
+	}
+	if err != nil {
+		return provisionersdk.PlanErrorf("%s", err)
+	}
+	stage := string(database.ProvisionerJobTimingStagePlan)
+	timings := append(convertTimings(resp.Timings, stage), runTiming(ActionPlan, stage, start))
+
+	resources, err := convertResources(resp.Resources, false)
+	if err != nil {
+		return provisionersdk.PlanErrorf("invalid resources: %s", err)
+	}
+	parameters, err := convertParameters(resp.Parameters)
+	if err != nil {
+		return provisionersdk.PlanErrorf("invalid parameters: %s", err)
+	}
+	return &proto.PlanComplete{
+		Resources:             resources,
+		Parameters:            parameters,
+		ExternalAuthProviders: convertExternalAuth(resp.ExternalAuth),
+		Timings:               timings,
+		Engine:                &proto.Engine{Name: "exec"},
+	}
+}
+
+// Apply runs the executable with the apply action, using the request of the
+// plan that preceded it in the session.
+func (s *server) Apply(sess *provisionersdk.Session, request *proto.ApplyRequest, canceledOrComplete <-chan struct{}) *proto.ApplyComplete {
+	ctx, cancel := s.setupContext(sess.Context(), canceledOrComplete)
+	defer cancel()
+
+	data, err := os.ReadFile(filepath.Join(sess.WorkDirectory, planRequestFile))
+	if err != nil {
+		return provisionersdk.ApplyErrorf("read plan request: %s", err)
+	}
+	var req Request
+	err = json.Unmarshal(data, &req)
+	if err != nil {
+		return provisionersdk.ApplyErrorf("unmarshal plan request: %s", err)
+	}
+	// The metadata of the apply takes precedence, since it may carry fresher
+	// tokens than the plan did.
+	applyReq, err := newRequest(ActionApply, sess.Config, &proto.PlanRequest{Metadata: request.Metadata})
+	if err != nil {
+		return provisionersdk.ApplyErrorf("%s", err)
+	}
+	req.Action = ActionApply
+	req.Transition = applyReq.Transition
+	req.Workspace = applyReq.Workspace
+	req.State = applyReq.State
+
+	var resp Response
+	start := time.Now()
+	err = s.run(ctx, sess, &req, &resp)
+	if resp.Error == "" && err != nil {
+		resp.Error = err.Error()
+	}
+	if resp.Error != "" {
+		// The executable may have changed infrastructure before failing, so
+		// the state it reported is kept.
+		return &proto.ApplyComplete{
+			State: resp.State,
+			Error: resp.Error,
+		}
+	}
+	stage := string(database.ProvisionerJobTimingStageApply)
+	timings := append(convertTimings(resp.Timings, stage), runTiming(ActionApply, stage, start))
+
+	resources, err := convertResources(resp.Resources, true)
+	if err != nil {
+		return &proto.ApplyComplete{
+			State: resp.State,
+			Error: xerrors.Errorf("invalid resources: %w", err).Error(),
+		}
+	}
+	parameters, err := convertParameters(resp.Parameters)
+	if err != nil {
+		return &proto.ApplyComplete{
+			State: resp.State,
+			Error: xerrors.Errorf("invalid parameters: %w", err).Error(),
+		}
+	}
+	return &proto.ApplyComplete{
+		State:                 resp.State,
+		Resources:             resources,
+		Parameters:            parameters,
+		ExternalAuthProviders: convertExternalAuth(resp.ExternalAuth),
+		Timings:               timings,
+	}
+}
+
+// setupContext returns a context that is canceled when the provision is
+// canceled or complete.
+func (*server) setupContext(parent context.Context, canceledOrComplete <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-canceledOrComplete:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// run executes the executable with req on stdin and decodes its stdout into
+// resp. Output on stderr is streamed to the provisioner logs. When ctx is
+// canceled the executable is interrupted and killed if it hasn't exited after
+// the exit timeout.
+func (s *server) run(ctx context.Context, sess *provisionersdk.Session, req *Request, resp any) error {
+	path := filepath.Join(sess.WorkDirectory, ExecutableName)
+	info, err := os.Stat(path)
+	if err != nil {
+		return xerrors.Errorf("template must contain an executable named %q: %w", ExecutableName, err)
+	}
+	// Archives created on Windows don't carry the executable bit.
+	if runtime.GOOS != "windows" && info.Mode()&0o100 == 0 {
+		err = os.Chmod(path, info.Mode()|0o100)
+		if err != nil {
+			return xerrors.Errorf("make %q executable: %w", ExecutableName, err)
+		}
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return xerrors.Errorf("marshal request: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	stderr, done := logWriter(sess)
+	cmd := osexec.CommandContext(ctx, path, req.Action)
+	cmd.Dir = sess.WorkDirectory
+	cmd.Env = safeEnviron()
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	cmd.Cancel = func() error {
+		s.logger.Debug(ctx, "interrupting executable", slog.F("action", req.Action))
+		if runtime.GOOS == "windows" {
+			// Interrupts aren't supported by Windows.
+			return cmd.Process.Kill()
+		}
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = s.exitTimeout
+
+	runErr := cmd.Run()
+	_ = stderr.Close()
+	<-done
+
+	// A failed executable may still report an error or state, so stdout is
+	// decoded regardless of the exit status.
+	var decodeErr error
+	if len(bytes.TrimSpace(stdout.Bytes())) > 0 {
+		decodeErr = json.Unmarshal(stdout.Bytes(), resp)
+	}
+	if runErr != nil {
+		if ctx.Err() != nil {
+			return xerrors.Errorf("%s canceled: %w", req.Action, runErr)
+		}
+		return xerrors.Errorf("%s failed: %w", req.Action, runErr)
+	}
+	if decodeErr != nil {
+		return xerrors.Errorf("decode %s response: %w", req.Action, decodeErr)
+	}
+	return nil
+}
+
+func runTiming(action, stage string, start time.Time) *proto.Timing {
+	return &proto.Timing{
+		Start:    timestamppb.New(start),
+		End:      timestamppb.Now(),
+		Action:   action,
+		Source:   "exec",
+		Resource: ExecutableName,
+		Stage:    stage,
+		State:    proto.TimingState_COMPLETED,
+	}
+}
+
+// newRequest converts a plan request to the request written to the
+// executable.
+func newRequest(action string, config *proto.Config, request *proto.PlanRequest) (*Request, error) {
+	metadata := request.GetMetadata()
+	req := &Request{
+		Action:     action,
+		Transition: strings.ToLower(metadata.GetWorkspaceTransition().String()),
+		Workspace: &Workspace{
+			ID:                   metadata.GetWorkspaceId(),
+			Name:                 metadata.GetWorkspaceName(),
+			BuildID:              metadata.GetWorkspaceBuildId(),
+			AccessURL:            metadata.GetCoderUrl(),
+			TemplateID:           metadata.GetTemplateId(),
+			TemplateName:         metadata.GetTemplateName(),
+			TemplateVersion:      metadata.GetTemplateVersion(),
+			OwnerID:              metadata.GetWorkspaceOwnerId(),
+			OwnerName:            metadata.GetWorkspaceOwner(),
+			OwnerFullName:        metadata.GetWorkspaceOwnerName(),
+			OwnerEmail:           metadata.GetWorkspaceOwnerEmail(),
+			OwnerGroups:          metadata.GetWorkspaceOwnerGroups(),
+			OwnerLoginType:       metadata.GetWorkspaceOwnerLoginType(),
+			OwnerSessionToken:    metadata.GetWorkspaceOwnerSessionToken(),
+			OwnerOIDCAccessToken: metadata.GetWorkspaceOwnerOidcAccessToken(),
+			OwnerSSHPublicKey:    metadata.GetWorkspaceOwnerSshPublicKey(),
+			OwnerSSHPrivateKey:   metadata.GetWorkspaceOwnerSshPrivateKey(),
+		},
+		Parameters:       map[string]string{},
+		Variables:        map[string]string{},
+		ExternalAuth:     map[string]string{},
+		AgentInitScripts: agentInitScripts(metadata.GetCoderUrl()),
+	}
+	for _, p := range request.GetRichParameterValues() {
+		req.Parameters[p.Name] = p.Value
+	}
+	for _, v := range request.GetVariableValues() {
+		req.Variables[v.Name] = v.Value
+	}
+	for _, p := range request.GetExternalAuthProviders() {
+		req.ExternalAuth[p.Id] = p.AccessToken
+	}
+	if state := config.GetState(); len(state) > 0 {
+		if !json.Valid(state) {
+			return nil, xerrors.New("workspace state is not valid JSON")
+		}
+		req.State = state
+	}
+	return req, nil
+}
+
+// agentInitScripts returns the agent init scripts keyed by "<os>_<arch>".
+// The substitutions done by the Terraform provider are done here instead.
+func agentInitScripts(accessURL string) map[string]string {
+	if !strings.HasSuffix(accessURL, "/") {
+		accessURL += "/"
+	}
+	scripts := map[string]string{}
+	for key, script := range provisionersdk.AgentScriptEnv() {
+		script = strings.ReplaceAll(script, "${ACCESS_URL}", accessURL)
+		script = strings.ReplaceAll(script, "${AUTH_TYPE}", "token")
+		scripts[strings.TrimPrefix(key, "WIRTUAL_AGENT_SCRIPT_")] = script
+	}
+	return scripts
+}
+
+// logWriter returns a WriteCloser that logs each line written to it. Lines
+// prefixed with a level, e.g. "WARN: ", are logged at that level. The returned
+// channel is closed once the writer is closed and all lines were logged.
+func logWriter(sink *provisionersdk.Session) (io.WriteCloser, <-chan struct{}) {
+	r, w := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			level, line := parseLogLine(scanner.Text())
+			sink.ProvisionLog(level, line)
+		}
+		// Drain the pipe in case the line was too long for the scanner, so
+		// the executable doesn't block on writes.
+		_, _ = io.Copy(io.Discard, r)
+	}()
+	return w, done
+}
+
+var logLevelPrefixes = map[string]proto.LogLevel{
+	"TRACE": proto.LogLevel_TRACE,
+	"DEBUG": proto.LogLevel_DEBUG,
+	"INFO":  proto.LogLevel_INFO,
+	"WARN":  proto.LogLevel_WARN,
+	"ERROR": proto.LogLevel_ERROR,
+}
+
+func parseLogLine(line string) (proto.LogLevel, string) {
+	prefix, rest, ok := strings.Cut(line, ":")
+	if ok {
+		if level, ok := logLevelPrefixes[prefix]; ok {
+			return level, strings.TrimPrefix(rest, " ")
+		}
+	}
+	return proto.LogLevel_INFO, line
+}
@@ -0,0 +1,255 @@
+//go:build linux || darwin
+
+package exec_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/provisioner/exec"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk/drpc"
+)
+
+func setupProvisioner(t *testing.T) (context.Context, proto.DRPCProvisionerClient) {
+	client, server := drpc.MemTransportPipe()
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	serverErr := make(chan error, 1)
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+		cancelFunc()
+		err := <-serverErr
+		if !errors.Is(err, context.Canceled) {
+			assert.NoError(t, err)
+		}
+	})
+	go func() {
+		serverErr <- exec.Serve(ctx, &exec.ServeOptions{
+			ServeOptions: &provisionersdk.ServeOptions{
+				Listener:      server,
+				Logger:        testutil.Logger(t),
+				WorkDirectory: t.TempDir(),
+			},
+		})
+	}()
+	return ctx, proto.NewDRPCProvisionerClient(client)
+}
+
+// session starts a session for a template whose provision executable is
+// script.
+func session(ctx context.Context, t *testing.T, client proto.DRPCProvisionerClient, script string, state []byte) proto.DRPCProvisioner_SessionClient {
+	t.Helper()
+	sess, err := client.Session(ctx)
+	require.NoError(t, err)
+	err = sess.Send(&proto.Request{Type: &proto.Request_Config{Config: &proto.Config{
+		TemplateSourceArchive: testutil.CreateTar(t, map[string]string{
+			exec.ExecutableName: "#!/bin/sh\n" + script,
+			"README.md":         "# Hello",
+		}),
+		State: state,
+	}}})
+	require.NoError(t, err)
+	return sess
+}
+
+// recv returns the response of the session and the logs that preceded it.
+func recv(t *testing.T, sess proto.DRPCProvisioner_SessionClient) (*proto.Response, []*proto.Log) {
+	t.Helper()
+	var logs []*proto.Log
+	for {
+		msg, err := sess.Recv()
+		require.NoError(t, err)
+		if log := msg.GetLog(); log != nil {
+			logs = append(logs, log)
+			continue
+		}
+		return msg, logs
+	}
+}
+
+func TestProvision_Parse(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := setupProvisioner(t)
+	sess := session(ctx, t, client, `
+echo "parsing" >&2
+cat <<EOF
+{"variables": [{"name": "region", "default_value": "eu"}], "workspace_tags": {"cluster": "a"}}
+EOF
+`, nil)
+	err := sess.Send(&proto.Request{Type: &proto.Request_Parse{Parse: &proto.ParseRequest{}}})
+	require.NoError(t, err)
+
+	msg, logs := recv(t, sess)
+	parse := msg.GetParse()
+	require.NotNil(t, parse)
+	require.Empty(t, parse.Error)
+	require.Len(t, parse.TemplateVariables, 1)
+	require.Equal(t, "region", parse.TemplateVariables[0].Name)
+	require.Equal(t, "string", parse.TemplateVariables[0].Type)
+	require.Equal(t, "eu", parse.TemplateVariables[0].DefaultValue)
+	require.Equal(t, map[string]string{"cluster": "a"}, parse.WorkspaceTags)
+	require.Equal(t, "# Hello", string(parse.Readme))
+	require.Len(t, logs, 1)
+	require.Equal(t, "parsing", logs[0].Output)
+}
+
+// nolint:paralleltest // Uses t.Setenv.
+func TestProvision_PlanApply(t *testing.T) {
+	requestPath := filepath.Join(t.TempDir(), "request.json")
+	t.Setenv("EXEC_TEST_REQUEST", requestPath)
+
+	ctx, client := setupProvisioner(t)
+	sess := session(ctx, t, client, `
+cat > "$EXEC_TEST_REQUEST"
+echo "WARN: careful" >&2
+cat <<EOF
+{
+  "resources": [{
+    "name": "dev",
+    "type": "container",
+    "agents": [{
+      "name": "main",
+      "operating_system": "linux",
+      "architecture": "amd64",
+      "token": "secret",
+      "apps": [{"slug": "web", "url": "http://localhost:8080", "share": "authenticated"}]
+    }]
+  }],
+  "parameters": [{"name": "cpu", "type": "number", "default_value": "2"}],
+  "state": {"action": "$1"}
+}
+EOF
+`, []byte(`{"container": "abc"}`))
+
+	err := sess.Send(&proto.Request{Type: &proto.Request_Plan{Plan: &proto.PlanRequest{
+		Metadata: &proto.Metadata{
+			WorkspaceTransition: proto.WorkspaceTransition_START,
+			WorkspaceName:       "ws",
+			CoderUrl:            "https://example.com",
+		},
+		RichParameterValues: []*proto.RichParameterValue{{Name: "cpu", Value: "4"}},
+		VariableValues:      []*proto.VariableValue{{Name: "region", Value: "eu"}},
+	}}})
+	require.NoError(t, err)
+	msg, logs := recv(t, sess)
+	plan := msg.GetPlan()
+	require.NotNil(t, plan)
+	require.Empty(t, plan.Error)
+	require.Len(t, plan.Resources, 1)
+	require.Len(t, plan.Resources[0].Agents, 1)
+	agent := plan.Resources[0].Agents[0]
+	require.NotEmpty(t, agent.Id)
+	require.Equal(t, "secret", agent.GetToken())
+	require.Equal(t, proto.AppSharingLevel_AUTHENTICATED, agent.Apps[0].SharingLevel)
+	require.True(t, agent.DisplayApps.Vscode)
+	require.Len(t, plan.Parameters, 1)
+	require.Equal(t, "number", plan.Parameters[0].Type)
+	require.Equal(t, "exec", plan.Engine.GetName())
+	require.NotEmpty(t, plan.Timings)
+	require.Len(t, logs, 1)
+	require.Equal(t, proto.LogLevel_WARN, logs[0].Level)
+	require.Equal(t, "careful", logs[0].Output)
+
+	var req exec.Request
+	data, err := os.ReadFile(requestPath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &req))
+	require.Equal(t, exec.ActionPlan, req.Action)
+	require.Equal(t, "start", req.Transition)
+	require.Equal(t, "ws", req.Workspace.Name)
+	require.Equal(t, map[string]string{"cpu": "4"}, req.Parameters)
+	require.Equal(t, map[string]string{"region": "eu"}, req.Variables)
+	require.JSONEq(t, `{"container": "abc"}`, string(req.State))
+	require.Contains(t, req.AgentInitScripts, "linux_amd64")
+	require.Contains(t, req.AgentInitScripts["linux_amd64"], "https://example.com/bin/")
+
+	err = sess.Send(&proto.Request{Type: &proto.Request_Apply{Apply: &proto.ApplyRequest{
+		Metadata: &proto.Metadata{
+			WorkspaceTransition: proto.WorkspaceTransition_START,
+			WorkspaceName:       "ws",
+		},
+	}}})
+	require.NoError(t, err)
+	msg, _ = recv(t, sess)
+	apply := msg.GetApply()
+	require.NotNil(t, apply)
+	require.Empty(t, apply.Error)
+	require.Len(t, apply.Resources, 1)
+	require.JSONEq(t, `{"action": "apply"}`, string(apply.State))
+
+	// Apply reuses the parameters of the plan.
+	data, err = os.ReadFile(requestPath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &req))
+	require.Equal(t, exec.ActionApply, req.Action)
+	require.Equal(t, map[string]string{"cpu": "4"}, req.Parameters)
+}
+
+func TestProvision_ApplyFailureKeepsState(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := setupProvisioner(t)
+	sess := session(ctx, t, client, `
+if [ "$1" = "apply" ]; then
+  echo '{"state": {"partial": true}}'
+  exit 1
+fi
+`, nil)
+	err := sess.Send(&proto.Request{Type: &proto.Request_Plan{Plan: &proto.PlanRequest{}}})
+	require.NoError(t, err)
+	msg, _ := recv(t, sess)
+	require.Empty(t, msg.GetPlan().GetError())
+
+	err = sess.Send(&proto.Request{Type: &proto.Request_Apply{Apply: &proto.ApplyRequest{}}})
+	require.NoError(t, err)
+	msg, _ = recv(t, sess)
+	apply := msg.GetApply()
+	require.NotNil(t, apply)
+	require.Contains(t, apply.Error, "exit status 1")
+	require.JSONEq(t, `{"partial": true}`, string(apply.State))
+}
+
+func TestProvision_InvalidResponse(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		output  string
+		wantErr string
+	}{
+		"ReportedError": {
+			output:  `{"error": "quota exceeded"}`,
+			wantErr: "quota exceeded",
+		},
+		"InvalidJSON": {
+			output:  `not json`,
+			wantErr: "decode plan response",
+		},
+		"InvalidAppSlug": {
+			output:  `{"resources": [{"name": "a", "type": "b", "agents": [{"name": "main", "operating_system": "linux", "architecture": "amd64", "apps": [{"slug": "Not Valid"}]}]}]}`,
+			wantErr: "does not match regex",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, client := setupProvisioner(t)
+			sess := session(ctx, t, client, "cat <<'EOF'\n"+tc.output+"\nEOF\n", nil)
+			err := sess.Send(&proto.Request{Type: &proto.Request_Plan{Plan: &proto.PlanRequest{}}})
+			require.NoError(t, err)
+			msg, _ := recv(t, sess)
+			require.True(t, strings.Contains(msg.GetPlan().GetError(), tc.wantErr), msg.GetPlan().GetError())
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package exec
+
+import (
+	"os"
+	"strings"
+)
+
+// safeEnviron wraps os.Environ but removes WIRTUAL_ environment variables, so
+// secrets of the provisioner daemon like the Postgres connection string are
+// never passed to template code.
+func safeEnviron() []string {
+	env := os.Environ()
+	strippedEnv := make([]string, 0, len(env))
+	for _, e := range env {
+		if strings.HasPrefix(e, "WIRTUAL_") {
+			continue
+		}
+		strippedEnv = append(strippedEnv, e)
+	}
+	return strippedEnv
+}
@@ -0,0 +1,45 @@
+// Package exec implements a provisioner that delegates to an executable in
+// the template archive, so templates can be written in any language without
+// an infrastructure as code tool. See contract.go for the protocol.
+package exec
+
+import (
+	"context"
+	"time"
+
+	"cdr.dev/slog"
+
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/unhanger"
+)
+
+// ExecutableName is the name of the executable at the root of the template
+// archive that is run for each provisioner action.
+const ExecutableName = "provision"
+
+type ServeOptions struct {
+	*provisionersdk.ServeOptions
+
+	// ExitTimeout defines how long we will wait for the executable to exit
+	// (cleanly) after it was interrupted because the provision was canceled.
+	//
+	// Default value: 3 minutes (unhanger.HungJobExitTimeout).
+	ExitTimeout time.Duration
+}
+
+// Serve starts a dRPC server on the provided transport speaking the exec
+// provisioner.
+func Serve(ctx context.Context, options *ServeOptions) error {
+	if options.ExitTimeout == 0 {
+		options.ExitTimeout = unhanger.HungJobExitTimeout
+	}
+	return provisionersdk.Serve(ctx, &server{
+		logger:      options.Logger,
+		exitTimeout: options.ExitTimeout,
+	}, options.ServeOptions)
+}
+
+type server struct {
+	logger      slog.Logger
+	exitTimeout time.Duration
+}
@@ -3,12 +3,70 @@ package terraform
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
 	tfjson "github.com/hashicorp/terraform-json"
+	"golang.org/x/xerrors"
 )
 
+// DefaultRetryableErrors match the errors of cloud provider rate limits and
+// transient network failures. They are used when a session is not configured
+// with its own rules.
+var DefaultRetryableErrors = []string{
+	`(?i)rate ?limit`,
+	`(?i)too many requests`,
+	`(?i)throttl`,
+	`(?i)request limit exceeded`,
+	`(?i)service unavailable`,
+	`(?i)connection reset by peer`,
+	`(?i)i/o timeout`,
+	`(?i)tls handshake timeout`,
+	`(?i)error acquiring the state lock`,
+}
+
+// RetryableErrorRules classify the failures of plans and applies. A failure
+// is retryable if one of the rules matches its error or the summary or detail
+// of one of its error diagnostics, and fatal otherwise.
+type RetryableErrorRules []*regexp.Regexp
+
+// ParseRetryableErrorRules compiles the regular expressions of the rules.
+func ParseRetryableErrorRules(patterns []string) (RetryableErrorRules, error) {
+	rules := make(RetryableErrorRules, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, xerrors.Errorf("parse retryable error %q: %w", pattern, err)
+		}
+		rules = append(rules, re)
+	}
+	return rules, nil
+}
+
+// Retryable returns whether the failure is likely to go away when the plan or
+// apply is run again. Warnings never make a failure retryable.
+func (rules RetryableErrorRules) Retryable(err error, diags []*tfjson.Diagnostic) bool {
+	messages := make([]string, 0, len(diags)*2+1)
+	if err != nil {
+		messages = append(messages, err.Error())
+	}
+	for _, diag := range diags {
+		if diag.Severity != tfjson.DiagnosticSeverityError {
+			continue
+		}
+		messages = append(messages, diag.Summary, diag.Detail)
+	}
+	for _, rule := range rules {
+		for _, message := range messages {
+			if rule.MatchString(message) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // This implementation bases on the original Terraform formatter, which unfortunately is internal:
 // https://github.com/hashicorp/terraform/blob/6b35927cf0988262739a5f0acea4790ae58a16d3/internal/command/format/diagnostic.go#L125
 
@@ -7,6 +7,7 @@ import (
 
 	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform"
 )
@@ -61,3 +62,91 @@ func TestFormatDiagnostic(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryableErrorRules(t *testing.T) {
+	t.Parallel()
+
+	defaults, err := terraform.ParseRetryableErrorRules(terraform.DefaultRetryableErrors)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		rules     terraform.RetryableErrorRules
+		err       error
+		diags     []*tfjson.Diagnostic
+		retryable bool
+	}{
+		"RateLimitDiagnostic": {
+			rules: defaults,
+			err:   xerrors.New("terraform apply: exit status 1"),
+			diags: []*tfjson.Diagnostic{{
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  "creating EC2 Instance",
+				Detail:   "api error RequestLimitExceeded: Request limit exceeded.",
+			}},
+			retryable: true,
+		},
+		"FatalDiagnostic": {
+			rules: defaults,
+			err:   xerrors.New("terraform plan: exit status 1"),
+			diags: []*tfjson.Diagnostic{{
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  "Unsupported attribute",
+				Detail:   "This object has no argument, nested block, or exported attribute named \"foobar\".",
+			}},
+		},
+		"WarningIgnored": {
+			rules: defaults,
+			err:   xerrors.New("terraform plan: exit status 1"),
+			diags: []*tfjson.Diagnostic{{
+				Severity: tfjson.DiagnosticSeverityWarning,
+				Summary:  "Rate limited",
+			}, {
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  "Invalid reference",
+			}},
+		},
+		"Error": {
+			rules:     defaults,
+			err:       xerrors.New("read tcp 10.0.0.2:443: connection reset by peer"),
+			retryable: true,
+		},
+		"CustomRules": {
+			rules: mustParseRetryableErrorRules(t, `(?i)quota exceeded for quota metric`),
+			err:   xerrors.New("terraform apply: exit status 1"),
+			diags: []*tfjson.Diagnostic{{
+				Severity: tfjson.DiagnosticSeverityError,
+				Summary:  "Error creating instance",
+				Detail:   "googleapi: Error 429: Quota exceeded for quota metric 'Queries'",
+			}},
+			retryable: true,
+		},
+		"NoRules": {
+			err: xerrors.New("Too Many Requests"),
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.retryable, tc.rules.Retryable(tc.err, tc.diags))
+		})
+	}
+
+	t.Run("InvalidRule", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := terraform.ParseRetryableErrorRules([]string{`(unclosed`})
+		require.Error(t, err)
+	})
+}
+
+func mustParseRetryableErrorRules(t *testing.T, patterns ...string) terraform.RetryableErrorRules {
+	t.Helper()
+
+	rules, err := terraform.ParseRetryableErrorRules(patterns)
+	require.NoError(t, err)
+	return rules
+}
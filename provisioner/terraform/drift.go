@@ -0,0 +1,135 @@
+package terraform
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"golang.org/x/xerrors"
+
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+)
+
+const (
+	driftActionUpdate = "update"
+	driftActionDelete = "delete"
+)
+
+// convertDrift converts the resource drift of a plan, which lists the managed
+// resources that were changed outside of Terraform. The values of sensitive
+// attributes are omitted.
+func convertDrift(changes []*tfjson.ResourceChange) ([]*proto.ResourceDrift, error) {
+	drift := make([]*proto.ResourceDrift, 0, len(changes))
+	for _, change := range changes {
+		if change.Mode != tfjson.ManagedResourceMode || change.Change == nil {
+			continue
+		}
+		var action string
+		switch {
+		case change.Change.Actions.Delete():
+			action = driftActionDelete
+		case change.Change.Actions.Update():
+			action = driftActionUpdate
+		default:
+			continue
+		}
+
+		resource := &proto.ResourceDrift{
+			Address: change.Address,
+			Type:    change.Type,
+			Action:  action,
+		}
+		if action == driftActionUpdate {
+			attributes, err := driftAttributes(change.Change)
+			if err != nil {
+				return nil, xerrors.Errorf("resource %q: %w", change.Address, err)
+			}
+			resource.Attributes = attributes
+		}
+		drift = append(drift, resource)
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		return drift[i].Address < drift[j].Address
+	})
+	return drift, nil
+}
+
+// driftAttributes returns the top-level attributes whose values differ before
+// and after the change.
+func driftAttributes(change *tfjson.Change) ([]*proto.ResourceDrift_Attribute, error) {
+	before, _ := change.Before.(map[string]interface{})
+	after, _ := change.After.(map[string]interface{})
+	beforeSensitive, _ := change.BeforeSensitive.(map[string]interface{})
+	afterSensitive, _ := change.AfterSensitive.(map[string]interface{})
+
+	names := map[string]struct{}{}
+	for name := range before {
+		names[name] = struct{}{}
+	}
+	for name := range after {
+		names[name] = struct{}{}
+	}
+
+	attributes := make([]*proto.ResourceDrift_Attribute, 0, len(names))
+	for name := range names {
+		if reflect.DeepEqual(before[name], after[name]) {
+			continue
+		}
+		attribute := &proto.ResourceDrift_Attribute{
+			Name:      name,
+			Sensitive: isSensitive(beforeSensitive[name]) || isSensitive(afterSensitive[name]),
+		}
+		if !attribute.Sensitive {
+			var err error
+			attribute.Before, err = marshalDriftValue(before, name)
+			if err != nil {
+				return nil, err
+			}
+			attribute.After, err = marshalDriftValue(after, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		attributes = append(attributes, attribute)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Name < attributes[j].Name
+	})
+	return attributes, nil
+}
+
+// isSensitive reports whether any part of a value is marked as sensitive.
+// Terraform marks sensitive values with true, and nested values with maps or
+// lists of markers.
+func isSensitive(marker interface{}) bool {
+	switch m := marker.(type) {
+	case bool:
+		return m
+	case map[string]interface{}:
+		for _, v := range m {
+			if isSensitive(v) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range m {
+			if isSensitive(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func marshalDriftValue(values map[string]interface{}, name string) (string, error) {
+	value, ok := values[name]
+	if !ok {
+		return "", nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", xerrors.Errorf("marshal attribute %q: %w", name, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,80 @@
+package terraform
+
+import (
+	"encoding/json"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+)
+
+func TestConvertDrift(t *testing.T) {
+	t.Parallel()
+
+	var plan tfjson.Plan
+	err := json.Unmarshal([]byte(`{
+  "format_version": "1.2",
+  "terraform_version": "1.9.8",
+  "resource_drift": [
+    {
+      "address": "docker_container.workspace[0]",
+      "mode": "managed",
+      "type": "docker_container",
+      "name": "workspace",
+      "index": 0,
+      "change": {
+        "actions": ["update"],
+        "before": {"memory": 2048, "image": "ubuntu", "env": ["A=1"], "labels": {"a": "b"}},
+        "after": {"memory": 4096, "image": "ubuntu", "env": ["A=2"], "labels": {"a": "c"}},
+        "before_sensitive": {"env": [true]},
+        "after_sensitive": {"env": [true]}
+      }
+    },
+    {
+      "address": "docker_volume.home",
+      "mode": "managed",
+      "type": "docker_volume",
+      "name": "home",
+      "change": {
+        "actions": ["delete"],
+        "before": {"name": "home"},
+        "after": null
+      }
+    },
+    {
+      "address": "data.coder_workspace.me",
+      "mode": "data",
+      "type": "coder_workspace",
+      "name": "me",
+      "change": {
+        "actions": ["update"],
+        "before": {"name": "a"},
+        "after": {"name": "b"}
+      }
+    }
+  ]
+}`), &plan)
+	require.NoError(t, err)
+
+	drift, err := convertDrift(plan.ResourceDrift)
+	require.NoError(t, err)
+	require.Len(t, drift, 2)
+
+	require.Equal(t, "docker_container.workspace[0]", drift[0].Address)
+	require.Equal(t, "docker_container", drift[0].Type)
+	require.Equal(t, driftActionUpdate, drift[0].Action)
+	require.Len(t, drift[0].Attributes, 3)
+	require.Equal(t, &proto.ResourceDrift_Attribute{Name: "env", Sensitive: true}, drift[0].Attributes[0])
+	require.Equal(t, "labels", drift[0].Attributes[1].Name)
+	require.Equal(t, `{"a":"b"}`, drift[0].Attributes[1].Before)
+	require.Equal(t, `{"a":"c"}`, drift[0].Attributes[1].After)
+	require.Equal(t, "memory", drift[0].Attributes[2].Name)
+	require.Equal(t, "2048", drift[0].Attributes[2].Before)
+	require.Equal(t, "4096", drift[0].Attributes[2].After)
+
+	require.Equal(t, "docker_volume.home", drift[1].Address)
+	require.Equal(t, driftActionDelete, drift[1].Action)
+	require.Empty(t, drift[1].Attributes)
+}
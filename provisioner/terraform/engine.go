@@ -0,0 +1,144 @@
+package terraform
+
+import (
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	tfjson "github.com/hashicorp/terraform-json"
+	"golang.org/x/xerrors"
+)
+
+// Engine is the infrastructure as code tool that runs templates. OpenTofu is
+// a fork of Terraform that is compatible with Terraform configurations, so both
+// are served by this provisioner.
+type Engine string
+
+const (
+	EngineTerraform Engine = "terraform"
+	EngineOpenTofu  Engine = "opentofu"
+)
+
+// Engines lists the supported engines.
+var Engines = []Engine{EngineTerraform, EngineOpenTofu}
+
+var (
+	// OpenTofuVersion is the version of OpenTofu used internally when
+	// OpenTofu is not available on the system.
+	OpenTofuVersion = version.Must(version.NewVersion("1.8.5"))
+
+	minOpenTofuVersion = version.Must(version.NewVersion("1.6.0"))
+	maxOpenTofuVersion = version.Must(version.NewVersion("1.8.9")) // use .9 to automatically allow patch releases
+)
+
+// ParseEngine returns the engine with the given name. An empty name is
+// Terraform.
+func ParseEngine(name string) (Engine, error) {
+	if name == "" {
+		return EngineTerraform, nil
+	}
+	for _, e := range Engines {
+		if string(e) == name {
+			return e, nil
+		}
+	}
+	return "", xerrors.Errorf("unknown engine %q, must be one of %v", name, Engines)
+}
+
+// DisplayName is the human-readable name of the engine used in logs and
+// errors.
+func (e Engine) DisplayName() string {
+	if e == EngineOpenTofu {
+		return "OpenTofu"
+	}
+	return "Terraform"
+}
+
+// BinaryName is the name of the engine's executable.
+func (e Engine) BinaryName() string {
+	if e == EngineOpenTofu {
+		return "tofu"
+	}
+	return "terraform"
+}
+
+// DefaultVersion is the version installed when no usable binary is found.
+func (e Engine) DefaultVersion() *version.Version {
+	if e == EngineOpenTofu {
+		return OpenTofuVersion
+	}
+	return TerraformVersion
+}
+
+// MinVersion is the oldest supported version of the engine.
+func (e Engine) MinVersion() *version.Version {
+	if e == EngineOpenTofu {
+		return minOpenTofuVersion
+	}
+	return minTerraformVersion
+}
+
+// MaxVersion is the version from which the engine is untested.
+func (e Engine) MaxVersion() *version.Version {
+	if e == EngineOpenTofu {
+		return maxOpenTofuVersion
+	}
+	return maxTerraformVersion
+}
+
+const (
+	terraformRegistry = "registry.terraform.io/"
+	openTofuRegistry  = "registry.opentofu.org/"
+)
+
+// normalizeProviderName rewrites OpenTofu registry addresses to their
+// Terraform equivalents, so that resources look the same regardless of the
+// engine that produced them.
+func normalizeProviderName(name string) string {
+	if rest, ok := strings.CutPrefix(name, openTofuRegistry); ok {
+		return terraformRegistry + rest
+	}
+	return name
+}
+
+func normalizeStateModule(m *tfjson.StateModule) {
+	if m == nil {
+		return
+	}
+	for _, r := range m.Resources {
+		r.ProviderName = normalizeProviderName(r.ProviderName)
+	}
+	for _, c := range m.ChildModules {
+		normalizeStateModule(c)
+	}
+}
+
+func normalizeStateValues(v *tfjson.StateValues) {
+	if v == nil {
+		return
+	}
+	normalizeStateModule(v.RootModule)
+}
+
+// normalizeOpenTofuPlan makes the JSON plan of OpenTofu look like one produced
+// by Terraform.
+func normalizeOpenTofuPlan(p *tfjson.Plan) {
+	if p == nil {
+		return
+	}
+	normalizeStateValues(p.PlannedValues)
+	if p.PriorState != nil {
+		normalizeStateValues(p.PriorState.Values)
+	}
+	for _, rc := range p.ResourceChanges {
+		rc.ProviderName = normalizeProviderName(rc.ProviderName)
+	}
+}
+
+// normalizeOpenTofuState makes the JSON state of OpenTofu look like one
+// produced by Terraform.
+func normalizeOpenTofuState(s *tfjson.State) {
+	if s == nil {
+		return
+	}
+	normalizeStateValues(s.Values)
+}
@@ -0,0 +1,71 @@
+package terraform
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEngine(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]Engine{
+		"":          EngineTerraform,
+		"terraform": EngineTerraform,
+		"opentofu":  EngineOpenTofu,
+	} {
+		got, err := ParseEngine(name)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err := ParseEngine("pulumi")
+	require.Error(t, err)
+}
+
+func TestNormalizeOpenTofuPlan(t *testing.T) {
+	t.Parallel()
+
+	plan := &tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{
+			RootModule: &tfjson.StateModule{
+				Resources: []*tfjson.StateResource{{
+					Address:      "coder_agent.main",
+					ProviderName: "registry.opentofu.org/coder/coder",
+				}},
+				ChildModules: []*tfjson.StateModule{{
+					Resources: []*tfjson.StateResource{{
+						Address:      "module.example.null_resource.a",
+						ProviderName: "registry.opentofu.org/hashicorp/null",
+					}},
+				}},
+			},
+		},
+		PriorState: &tfjson.State{
+			Values: &tfjson.StateValues{
+				RootModule: &tfjson.StateModule{
+					Resources: []*tfjson.StateResource{{
+						Address:      "data.coder_parameter.region",
+						ProviderName: "registry.opentofu.org/coder/coder",
+					}},
+				},
+			},
+		},
+		ResourceChanges: []*tfjson.ResourceChange{{
+			Address:      "coder_agent.main",
+			ProviderName: "registry.opentofu.org/coder/coder",
+		}, {
+			Address:      "example_thing.main",
+			ProviderName: "example.com/acme/thing",
+		}},
+	}
+	normalizeOpenTofuPlan(plan)
+
+	require.Equal(t, "registry.terraform.io/coder/coder", plan.PlannedValues.RootModule.Resources[0].ProviderName)
+	require.Equal(t, "registry.terraform.io/hashicorp/null", plan.PlannedValues.RootModule.ChildModules[0].Resources[0].ProviderName)
+	require.Equal(t, "registry.terraform.io/coder/coder", plan.PriorState.Values.RootModule.Resources[0].ProviderName)
+	require.Equal(t, "registry.terraform.io/coder/coder", plan.ResourceChanges[0].ProviderName)
+	// Providers from other registries are left alone.
+	require.Equal(t, "example.com/acme/thing", plan.ResourceChanges[1].ProviderName)
+}
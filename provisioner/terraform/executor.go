@@ -33,12 +33,17 @@ type executor struct {
 	logger     slog.Logger
 	server     *server
 	mut        *sync.Mutex
+	engine     Engine
 	binaryPath string
 	// cachePath and workdir must not be used by multiple processes at once.
 	cachePath string
 	workdir   string
 	// used to capture execution times at various stages
 	timings *timingAggregator
+	// errorDiagnostics are reported by Terraform while planning or applying.
+	// They are written by the log reader, which is done by the time plan and
+	// apply return.
+	errorDiagnostics []*tfjson.Diagnostic
 }
 
 func (e *executor) basicEnv() []string {
@@ -155,18 +160,21 @@ func (e *executor) execParseJSON(ctx, killCtx context.Context, args, env []strin
 	return nil
 }
 
-func (e *executor) checkMinVersion(ctx context.Context) error {
+// checkMinVersion returns the version of the engine, or an error if it is
+// older than the minimum supported version.
+func (e *executor) checkMinVersion(ctx context.Context) (*version.Version, error) {
 	v, err := e.version(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !v.GreaterThanOrEqual(minTerraformVersion) {
-		return xerrors.Errorf(
-			"terraform version %q is too old. required >= %q",
+	if !v.GreaterThanOrEqual(e.engine.MinVersion()) {
+		return v, xerrors.Errorf(
+			"%s version %q is too old. required >= %q",
+			e.engine,
 			v.String(),
-			minTerraformVersion.String())
+			e.engine.MinVersion().String())
 	}
-	return nil
+	return v, nil
 }
 
 // version doesn't need the lock because it doesn't read or write to any state.
@@ -254,7 +262,9 @@ func getStateFilePath(workdir string) string {
 }
 
 // revive:disable-next-line:flag-parameter
-func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr logSink, destroy bool) (*proto.PlanComplete, error) {
+// plan runs a plan. A refresh-only plan doesn't propose any changes; it
+// reports the resources that were changed outside of Terraform instead.
+func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr logSink, destroy, refreshOnly bool) (*proto.PlanComplete, error) {
 	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
 	defer span.End()
 
@@ -273,6 +283,9 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 	if destroy {
 		args = append(args, "-destroy")
 	}
+	if refreshOnly {
+		args = append(args, "-refresh-only")
+	}
 	for _, variable := range vars {
 		args = append(args, "-var", variable)
 	}
@@ -291,6 +304,21 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 		return nil, xerrors.Errorf("terraform plan: %w", err)
 	}
 
+	if refreshOnly {
+		plan, err := e.showPlan(ctx, killCtx, planfilePath)
+		if err != nil {
+			return nil, xerrors.Errorf("show terraform plan file: %w", err)
+		}
+		drift, err := convertDrift(plan.ResourceDrift)
+		if err != nil {
+			return nil, xerrors.Errorf("convert drift: %w", err)
+		}
+		return &proto.PlanComplete{
+			Drift:   drift,
+			Timings: e.timings.aggregate(),
+		}, nil
+	}
+
 	// Capture the duration of the call to `terraform graph`.
 	graphTimings := newTimingAggregator(database.ProvisionerJobTimingStageGraph)
 	graphTimings.ingest(createGraphTimingsEvent(timingGraphStart))
@@ -371,6 +399,9 @@ func (e *executor) showPlan(ctx, killCtx context.Context, planfilePath string) (
 	args := []string{"show", "-json", "-no-color", planfilePath}
 	p := new(tfjson.Plan)
 	err := e.execParseJSON(ctx, killCtx, args, e.basicEnv(), p)
+	if e.engine == EngineOpenTofu {
+		normalizeOpenTofuPlan(p)
+	}
 	return p, err
 }
 
@@ -388,7 +419,8 @@ func (e *executor) graph(ctx, killCtx context.Context) (string, error) {
 		return "", err
 	}
 	args := []string{"graph"}
-	if ver.GreaterThanOrEqual(version170) {
+	// Every supported version of OpenTofu accepts the graph type.
+	if e.engine == EngineOpenTofu || ver.GreaterThanOrEqual(version170) {
 		args = append(args, "-type=plan")
 	}
 	var out strings.Builder
@@ -504,6 +536,9 @@ func (e *executor) state(ctx, killCtx context.Context) (*tfjson.State, error) {
 	if err != nil {
 		return nil, xerrors.Errorf("terraform show state: %w", err)
 	}
+	if e.engine == EngineOpenTofu {
+		normalizeOpenTofuState(state)
+	}
 	return state, nil
 }
 
@@ -616,6 +651,9 @@ func (e *executor) provisionReadAndLog(sink logSink, r io.Reader, done chan<- an
 		if log.Diagnostic == nil {
 			continue
 		}
+		if log.Diagnostic.Severity == tfjson.DiagnosticSeverityError {
+			e.errorDiagnostics = append(e.errorDiagnostics, log.Diagnostic)
+		}
 		logLevel = convertTerraformLogLevel(string(log.Diagnostic.Severity), sink)
 		for _, diagLine := range strings.Split(FormatDiagnostic(log.Diagnostic), "\n") {
 			sink.ProvisionLog(logLevel, diagLine)
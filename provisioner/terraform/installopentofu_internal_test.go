@@ -0,0 +1,127 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+)
+
+// nolint:paralleltest // Overrides the package level release URL.
+func TestInstallOpenTofu(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake release archive only contains a unix binary.")
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("tofu")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("#!/bin/sh\nexit 1\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	sum := sha256.Sum256(archive.Bytes())
+
+	signingKey, err := openpgp.NewEntity("OpenTofu", "", "core@opentofu.org", nil)
+	require.NoError(t, err)
+	otherKey, err := openpgp.NewEntity("Someone", "", "someone@example.com", nil)
+	require.NoError(t, err)
+	var armoredKey bytes.Buffer
+	aw, err := armor.Encode(&armoredKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, signingKey.Serialize(aw))
+	require.NoError(t, aw.Close())
+
+	// Version 1.8.5 is published with the correct checksum, 1.8.6 with a bad
+	// one and 1.8.7 with checksums signed by an unknown key.
+	archiveName := func(ver string) string {
+		return fmt.Sprintf("tofu_%s_%s_%s.zip", ver, runtime.GOOS, runtime.GOARCH)
+	}
+	sums := map[string]string{
+		"1.8.5": fmt.Sprintf("0000  tofu_1.8.5_plan9_amd64.zip\n%s  %s\n", hex.EncodeToString(sum[:]), archiveName("1.8.5")),
+		"1.8.6": fmt.Sprintf("0000  %s\n", archiveName("1.8.6")),
+		"1.8.7": fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), archiveName("1.8.7")),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/opentofu.asc" {
+			_, _ = rw.Write(armoredKey.Bytes())
+			return
+		}
+		for ver, verSums := range sums {
+			switch r.URL.Path {
+			case fmt.Sprintf("/v%s/tofu_%s_SHA256SUMS", ver, ver):
+				_, _ = rw.Write([]byte(verSums))
+				return
+			case fmt.Sprintf("/v%s/tofu_%s_SHA256SUMS.gpgsig", ver, ver):
+				signer := signingKey
+				if ver == "1.8.7" {
+					signer = otherKey
+				}
+				_ = openpgp.DetachSign(rw, signer, bytes.NewReader([]byte(verSums)), nil)
+				return
+			case fmt.Sprintf("/v%s/%s", ver, archiveName(ver)):
+				_, _ = rw.Write(archive.Bytes())
+				return
+			}
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	prevURL, prevKeyURL, prevFingerprint := openTofuReleasesURL, openTofuSigningKeyURL, openTofuSigningKeyFingerprint
+	openTofuReleasesURL = srv.URL
+	openTofuSigningKeyURL = srv.URL + "/opentofu.asc"
+	openTofuSigningKeyFingerprint = hex.EncodeToString(signingKey.PrimaryKey.Fingerprint)
+	t.Cleanup(func() {
+		openTofuReleasesURL, openTofuSigningKeyURL, openTofuSigningKeyFingerprint = prevURL, prevKeyURL, prevFingerprint
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := testutil.Context(t, testutil.WaitShort)
+		dir := t.TempDir()
+		binPath, err := InstallOpenTofu(ctx, testutil.Logger(t), dir, version.Must(version.NewVersion("1.8.5")))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "tofu"), binPath)
+		info, err := os.Stat(binPath)
+		require.NoError(t, err)
+		require.NotZero(t, info.Mode()&0o100, "binary must be executable")
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := InstallOpenTofu(ctx, testutil.Logger(t), t.TempDir(), version.Must(version.NewVersion("1.8.6")))
+		require.ErrorContains(t, err, "checksum mismatch")
+	})
+
+	t.Run("UnknownSigner", func(t *testing.T) {
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := InstallOpenTofu(ctx, testutil.Logger(t), t.TempDir(), version.Must(version.NewVersion("1.8.7")))
+		require.ErrorContains(t, err, "verify checksums signature")
+	})
+
+	t.Run("UntrustedKey", func(t *testing.T) {
+		prevFingerprint := openTofuSigningKeyFingerprint
+		openTofuSigningKeyFingerprint = hex.EncodeToString(otherKey.PrimaryKey.Fingerprint)
+		t.Cleanup(func() {
+			openTofuSigningKeyFingerprint = prevFingerprint
+		})
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := InstallOpenTofu(ctx, testutil.Logger(t), t.TempDir(), version.Must(version.NewVersion("1.8.5")))
+		require.ErrorContains(t, err, "not found")
+	})
+}
@@ -69,15 +69,26 @@ func (s *server) setupContexts(parent context.Context, canceledOrComplete <-chan
 
 func (s *server) Plan(
 	sess *provisionersdk.Session, request *proto.PlanRequest, canceledOrComplete <-chan struct{},
-) *proto.PlanComplete {
+) (resp *proto.PlanComplete) {
 	ctx, span := s.startTrace(sess.Context(), tracing.FuncName())
 	defer span.End()
 	ctx, cancel, killCtx, kill := s.setupContexts(ctx, canceledOrComplete)
 	defer cancel()
 	defer kill()
 
+	// Report the engine that ran the plan, even when it fails, so it is
+	// recorded on the job.
+	engine := &proto.Engine{Name: string(s.engine)}
+	defer func() {
+		resp.Engine = engine
+	}()
+
 	e := s.executor(sess.WorkDirectory, database.ProvisionerJobTimingStagePlan)
-	if err := e.checkMinVersion(ctx); err != nil {
+	ver, err := e.checkMinVersion(ctx)
+	if ver != nil {
+		engine.Version = ver.String()
+	}
+	if err != nil {
 		return provisionersdk.PlanErrorf(err.Error())
 	}
 	logTerraformEnvVars(sess)
@@ -100,7 +111,7 @@ func (s *server) Plan(
 		}
 	}
 
-	err := CleanStaleTerraformPlugins(sess.Context(), s.cachePath, afero.NewOsFs(), time.Now(), s.logger)
+	err = CleanStaleTerraformPlugins(sess.Context(), s.cachePath, afero.NewOsFs(), time.Now(), s.logger)
 	if err != nil {
 		return provisionersdk.PlanErrorf("unable to clean stale Terraform plugins: %s", err)
 	}
@@ -163,12 +174,18 @@ func (s *server) Plan(
 		return provisionersdk.PlanErrorf("plan vars: %s", err)
 	}
 
-	resp, err := e.plan(
+	resp, err = e.plan(
 		ctx, killCtx, env, vars, sess,
 		request.Metadata.GetWorkspaceTransition() == proto.WorkspaceTransition_DESTROY,
+		request.RefreshOnly,
 	)
 	if err != nil {
-		return provisionersdk.PlanErrorf(err.Error())
+		resp = provisionersdk.PlanErrorf(err.Error())
+		// The timings of failed plans are kept so that retries of the plan
+		// show up in the timeline of the build.
+		resp.Timings = append(initTimings.aggregate(), e.timings.aggregate()...)
+		resp.Retryable = s.retryable(sess, e, err)
+		return resp
 	}
 
 	// Prepend init timings since they occur prior to plan timings.
@@ -180,15 +197,25 @@ func (s *server) Plan(
 
 func (s *server) Apply(
 	sess *provisionersdk.Session, request *proto.ApplyRequest, canceledOrComplete <-chan struct{},
-) *proto.ApplyComplete {
+) (resp *proto.ApplyComplete) {
 	ctx, span := s.startTrace(sess.Context(), tracing.FuncName())
 	defer span.End()
 	ctx, cancel, killCtx, kill := s.setupContexts(ctx, canceledOrComplete)
 	defer cancel()
 	defer kill()
 
+	// Like Plan, report the engine that ran the apply even when it fails.
+	engine := &proto.Engine{Name: string(s.engine)}
+	defer func() {
+		resp.Engine = engine
+	}()
+
 	e := s.executor(sess.WorkDirectory, database.ProvisionerJobTimingStageApply)
-	if err := e.checkMinVersion(ctx); err != nil {
+	ver, err := e.checkMinVersion(ctx)
+	if ver != nil {
+		engine.Version = ver.String()
+	}
+	if err != nil {
 		return provisionersdk.ApplyErrorf(err.Error())
 	}
 	logTerraformEnvVars(sess)
@@ -209,7 +236,7 @@ func (s *server) Apply(
 	if err != nil {
 		return provisionersdk.ApplyErrorf("provision env: %s", err)
 	}
-	resp, err := e.apply(
+	resp, err = e.apply(
 		ctx, killCtx, env, sess,
 	)
 	if err != nil {
@@ -218,13 +245,29 @@ func (s *server) Apply(
 		// In this case, we return Complete with an explicit error message.
 		stateData, _ := os.ReadFile(statefilePath)
 		return &proto.ApplyComplete{
-			State: stateData,
-			Error: errorMessage,
+			State:     stateData,
+			Error:     errorMessage,
+			Retryable: s.retryable(sess, e, err),
 		}
 	}
 	return resp
 }
 
+// retryable classifies a failed plan or apply with the retryable error rules
+// of the session.
+func (s *server) retryable(sess *provisionersdk.Session, e *executor, err error) bool {
+	patterns := sess.Config.GetRetryableErrors()
+	if len(patterns) == 0 {
+		patterns = DefaultRetryableErrors
+	}
+	rules, perr := ParseRetryableErrorRules(patterns)
+	if perr != nil {
+		s.logger.Warn(sess.Context(), "invalid retryable error rules", slog.Error(perr))
+		return false
+	}
+	return rules.Retryable(err, e.errorDiagnostics)
+}
+
 func planVars(plan *proto.PlanRequest) ([]string, error) {
 	vars := []string{}
 	for _, variable := range plan.VariableValues {
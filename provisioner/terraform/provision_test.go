@@ -810,6 +810,7 @@ func TestProvision(t *testing.T) {
 			resp := provision(planRequest)
 			planComplete := resp.GetPlan()
 			require.NotNil(t, planComplete)
+			require.Equal(t, string(terraform.EngineTerraform), planComplete.GetEngine().GetName())
 
 			if testCase.ErrorContains != "" {
 				require.Contains(t, planComplete.GetError(), testCase.ErrorContains)
@@ -845,6 +846,7 @@ func TestProvision(t *testing.T) {
 				}}})
 				applyComplete := resp.GetApply()
 				require.NotNil(t, applyComplete)
+				require.Equal(t, string(terraform.EngineTerraform), applyComplete.GetEngine().GetName())
 
 				if testCase.Response != nil {
 					normalizeResources(applyComplete.Resources)
@@ -3,6 +3,8 @@ package terraform
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/awalterschulze/gographviz"
@@ -92,6 +94,26 @@ type agentEnvAttributes struct {
 	Value   string `mapstructure:"value"`
 }
 
+type agentDNSRecordAttributes struct {
+	AgentID string `mapstructure:"agent_id"`
+	Name    string `mapstructure:"name"`
+	Port    int32  `mapstructure:"port"`
+}
+
+type agentServiceAttributes struct {
+	AgentID     string            `mapstructure:"agent_id"`
+	Name        string            `mapstructure:"name"`
+	DisplayName string            `mapstructure:"display_name"`
+	Command     string            `mapstructure:"command"`
+	Dir         string            `mapstructure:"dir"`
+	Env         map[string]string `mapstructure:"env"`
+	// Restart is one of "always", "on-failure" or "never".
+	Restart                  string                     `mapstructure:"restart"`
+	RestartBackoffSeconds    int32                      `mapstructure:"restart_backoff"`
+	MaxRestartBackoffSeconds int32                      `mapstructure:"max_restart_backoff"`
+	Healthcheck              []appHealthcheckAttributes `mapstructure:"healthcheck"`
+}
+
 type agentScriptAttributes struct {
 	AgentID          string `mapstructure:"agent_id"`
 	DisplayName      string `mapstructure:"display_name"`
@@ -103,13 +125,27 @@ type agentScriptAttributes struct {
 	RunOnStart       bool   `mapstructure:"run_on_start"`
 	RunOnStop        bool   `mapstructure:"run_on_stop"`
 	TimeoutSeconds   int32  `mapstructure:"timeout"`
+	// Ready is the optional "ready" block, which holds the condition that
+	// must be met before scripts depending on this one are started.
+	Ready []agentScriptReadinessAttributes `mapstructure:"ready"`
+}
+
+type agentScriptReadinessAttributes struct {
+	Port           int32  `mapstructure:"port"`
+	File           string `mapstructure:"file"`
+	TimeoutSeconds int32  `mapstructure:"timeout"`
 }
 
 // A mapping of attributes on the "healthcheck" resource.
 type appHealthcheckAttributes struct {
-	URL       string `mapstructure:"url"`
-	Interval  int32  `mapstructure:"interval"`
-	Threshold int32  `mapstructure:"threshold"`
+	URL              string            `mapstructure:"url"`
+	Interval         int32             `mapstructure:"interval"`
+	Threshold        int32             `mapstructure:"threshold"`
+	TCP              string            `mapstructure:"tcp"`
+	Command          string            `mapstructure:"command"`
+	ExpectedStatuses []int32           `mapstructure:"expected_statuses"`
+	BodyRegex        string            `mapstructure:"body_regex"`
+	Headers          map[string]string `mapstructure:"headers"`
 }
 
 // A mapping of attributes on the "coder_metadata" resource.
@@ -413,13 +449,9 @@ func ConvertState(ctx context.Context, modules []*tfjson.StateModule, rawGraph s
 			}
 			appSlugs[attrs.Slug] = struct{}{}
 
-			var healthcheck *proto.Healthcheck
-			if len(attrs.Healthcheck) != 0 {
-				healthcheck = &proto.Healthcheck{
-					Url:       attrs.Healthcheck[0].URL,
-					Interval:  attrs.Healthcheck[0].Interval,
-					Threshold: attrs.Healthcheck[0].Threshold,
-				}
+			healthcheck, err := appHealthcheck(attrs.Healthcheck)
+			if err != nil {
+				return nil, xerrors.Errorf("app %q healthcheck: %w", attrs.Slug, err)
 			}
 
 			sharingLevel := proto.AppSharingLevel_OWNER
@@ -484,6 +516,115 @@ func ConvertState(ctx context.Context, modules []*tfjson.StateModule, rawGraph s
 		}
 	}
 
+	// Associate DNS records with agents.
+	for _, resources := range tfResourcesByLabel {
+		for _, resource := range resources {
+			if resource.Type != "coder_dns_record" {
+				continue
+			}
+			var attrs agentDNSRecordAttributes
+			err = mapstructure.Decode(resource.AttributeValues, &attrs)
+			if err != nil {
+				return nil, xerrors.Errorf("decode dns record attributes: %w", err)
+			}
+			if !provisioner.DNSRecordNameRegex.MatchString(attrs.Name) {
+				return nil, xerrors.Errorf("invalid dns record name %q, it must be a DNS name relative to the workspace, optionally starting with a \"*.\" wildcard", attrs.Name)
+			}
+			if attrs.Port < 0 || attrs.Port > 65535 {
+				return nil, xerrors.Errorf("invalid port %d of dns record %q, it must be between 1 and 65535 if set", attrs.Port, attrs.Name)
+			}
+			for _, agents := range resourceAgents {
+				for _, agent := range agents {
+					// Find agents with the matching ID and associate them!
+					if !dependsOnAgent(graph, agent, attrs.AgentID, resource) {
+						continue
+					}
+					agent.DnsRecords = append(agent.DnsRecords, &proto.DNSRecord{
+						Name: attrs.Name,
+						Port: attrs.Port,
+					})
+				}
+			}
+		}
+	}
+
+	// Associate services with agents.
+	for _, resources := range tfResourcesByLabel {
+		for _, resource := range resources {
+			if resource.Type != "coder_service" {
+				continue
+			}
+			var attrs agentServiceAttributes
+			err = mapstructure.Decode(resource.AttributeValues, &attrs)
+			if err != nil {
+				return nil, xerrors.Errorf("decode service attributes: %w", err)
+			}
+			if attrs.Name == "" {
+				attrs.Name = resource.Name
+			}
+			if attrs.DisplayName == "" {
+				attrs.DisplayName = attrs.Name
+			}
+			if !provisioner.AppSlugRegex.MatchString(attrs.Name) {
+				return nil, xerrors.Errorf("invalid service name %q, it must be lowercase alphanumeric with hyphens", attrs.Name)
+			}
+
+			var restartPolicy proto.ServiceRestartPolicy
+			switch strings.ToLower(attrs.Restart) {
+			case "", "always":
+				restartPolicy = proto.ServiceRestartPolicy_ALWAYS
+			case "on-failure":
+				restartPolicy = proto.ServiceRestartPolicy_ON_FAILURE
+			case "never":
+				restartPolicy = proto.ServiceRestartPolicy_NEVER
+			default:
+				return nil, xerrors.Errorf("invalid restart policy %q for service %q, must be one of \"always\", \"on-failure\" or \"never\"", attrs.Restart, attrs.Name)
+			}
+
+			envNames := make([]string, 0, len(attrs.Env))
+			for name := range attrs.Env {
+				envNames = append(envNames, name)
+			}
+			sort.Strings(envNames)
+			env := make([]*proto.Env, 0, len(envNames))
+			for _, name := range envNames {
+				env = append(env, &proto.Env{
+					Name:  name,
+					Value: attrs.Env[name],
+				})
+			}
+
+			var healthcheck *proto.Healthcheck
+			if len(attrs.Healthcheck) != 0 {
+				healthcheck = &proto.Healthcheck{
+					Url:       attrs.Healthcheck[0].URL,
+					Interval:  attrs.Healthcheck[0].Interval,
+					Threshold: attrs.Healthcheck[0].Threshold,
+				}
+			}
+
+			for _, agents := range resourceAgents {
+				for _, agent := range agents {
+					// Find agents with the matching ID and associate them!
+					if !dependsOnAgent(graph, agent, attrs.AgentID, resource) {
+						continue
+					}
+					agent.Services = append(agent.Services, &proto.Service{
+						Name:                     attrs.Name,
+						DisplayName:              attrs.DisplayName,
+						Command:                  attrs.Command,
+						Dir:                      attrs.Dir,
+						Env:                      env,
+						RestartPolicy:            restartPolicy,
+						RestartBackoffSeconds:    attrs.RestartBackoffSeconds,
+						MaxRestartBackoffSeconds: attrs.MaxRestartBackoffSeconds,
+						Healthcheck:              healthcheck,
+					})
+				}
+			}
+		}
+	}
+
 	// Associate scripts with agents.
 	for _, resources := range tfResourcesByLabel {
 		for _, resource := range resources {
@@ -495,6 +636,18 @@ func ConvertState(ctx context.Context, modules []*tfjson.StateModule, rawGraph s
 			if err != nil {
 				return nil, xerrors.Errorf("decode script attributes: %w", err)
 			}
+			var ready *proto.ScriptReadiness
+			if len(attrs.Ready) != 0 {
+				if attrs.Ready[0].Port < 0 || attrs.Ready[0].Port > 65535 {
+					return nil, xerrors.Errorf("invalid ready port %d for %q", attrs.Ready[0].Port, resource.Address)
+				}
+				ready = &proto.ScriptReadiness{
+					Port:           attrs.Ready[0].Port,
+					File:           attrs.Ready[0].File,
+					TimeoutSeconds: attrs.Ready[0].TimeoutSeconds,
+				}
+			}
+			dependsOn := scriptDependencies(graph, tfResourcesByLabel, resource)
 			for _, agents := range resourceAgents {
 				for _, agent := range agents {
 					// Find agents with the matching ID and associate them!
@@ -511,11 +664,33 @@ func ConvertState(ctx context.Context, modules []*tfjson.StateModule, rawGraph s
 						RunOnStart:       attrs.RunOnStart,
 						RunOnStop:        attrs.RunOnStop,
 						TimeoutSeconds:   attrs.TimeoutSeconds,
+						Address:          resource.Address,
+						DependsOn:        dependsOn,
+						Ready:            ready,
 					})
 				}
 			}
 		}
 	}
+	// A script can only wait for scripts run by the same agent, so drop
+	// dependencies on scripts of other agents.
+	for _, agents := range resourceAgents {
+		for _, agent := range agents {
+			addresses := map[string]struct{}{}
+			for _, script := range agent.Scripts {
+				addresses[script.Address] = struct{}{}
+			}
+			for _, script := range agent.Scripts {
+				var dependsOn []string
+				for _, address := range script.DependsOn {
+					if _, ok := addresses[address]; ok {
+						dependsOn = append(dependsOn, address)
+					}
+				}
+				script.DependsOn = dependsOn
+			}
+		}
+	}
 
 	// Associate metadata blocks with resources.
 	resourceMetadata := map[string][]*proto.Resource_Metadata{}
@@ -596,7 +771,7 @@ func ConvertState(ctx context.Context, modules []*tfjson.StateModule, rawGraph s
 			if resource.Mode == tfjson.DataResourceMode {
 				continue
 			}
-			if resource.Type == "coder_script" || resource.Type == "coder_agent" || resource.Type == "coder_agent_instance" || resource.Type == "coder_app" || resource.Type == "coder_metadata" {
+			if resource.Type == "coder_script" || resource.Type == "coder_dns_record" || resource.Type == "coder_service" || resource.Type == "coder_agent" || resource.Type == "coder_agent_instance" || resource.Type == "coder_app" || resource.Type == "coder_metadata" {
 				continue
 			}
 			label := convertAddressToLabel(resource.Address)
@@ -788,6 +963,50 @@ func convertAddressToModulePath(address string) (string, error) {
 	return addr.ModulePath.String(), nil
 }
 
+// appHealthcheck converts the healthcheck block of a coder_app. An app is
+// checked with at most one of an HTTP request, a TCP connection or a command.
+func appHealthcheck(attrs []appHealthcheckAttributes) (*proto.Healthcheck, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	hc := attrs[0]
+
+	checks := 0
+	for _, check := range []string{hc.URL, hc.TCP, hc.Command} {
+		if check != "" {
+			checks++
+		}
+	}
+	if checks > 1 {
+		return nil, xerrors.New("only one of url, tcp and command can be set")
+	}
+	if hc.URL == "" && (len(hc.ExpectedStatuses) > 0 || hc.BodyRegex != "" || len(hc.Headers) > 0) {
+		return nil, xerrors.New("expected_statuses, body_regex and headers require url")
+	}
+	for _, status := range hc.ExpectedStatuses {
+		if status < 100 || status > 599 {
+			return nil, xerrors.Errorf("invalid expected status %d", status)
+		}
+	}
+	if hc.BodyRegex != "" {
+		_, err := regexp.Compile(hc.BodyRegex)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid body_regex: %w", err)
+		}
+	}
+
+	return &proto.Healthcheck{
+		Url:              hc.URL,
+		Interval:         hc.Interval,
+		Threshold:        hc.Threshold,
+		Tcp:              hc.TCP,
+		Command:          hc.Command,
+		ExpectedStatuses: hc.ExpectedStatuses,
+		BodyRegex:        hc.BodyRegex,
+		Headers:          hc.Headers,
+	}, nil
+}
+
 func dependsOnAgent(graph *gographviz.Graph, agent *proto.Agent, resourceAgentID string, resource *tfjson.StateResource) bool {
 	// Plan: we need to find if there is edge between the agent and the resource.
 	if agent.Id == "" && resourceAgentID == "" {
@@ -812,6 +1031,38 @@ func dependsOnAgent(graph *gographviz.Graph, agent *proto.Agent, resourceAgentID
 	return agent.Id == resourceAgentID
 }
 
+// scriptDependencies returns the addresses of the coder_script resources that
+// the script depends on, either through depends_on or by referencing them.
+func scriptDependencies(graph *gographviz.Graph, tfResourcesByLabel map[string]map[string]*tfjson.StateResource, resource *tfjson.StateResource) []string {
+	label := convertAddressToLabel(resource.Address)
+	var dependsOn []string
+	for src, dsts := range graph.Edges.SrcToDsts {
+		if graphNodeLabel(graph, src) != label {
+			continue
+		}
+		for dst := range dsts {
+			for address, dependency := range tfResourcesByLabel[graphNodeLabel(graph, dst)] {
+				if dependency.Type != "coder_script" || address == resource.Address {
+					continue
+				}
+				dependsOn = append(dependsOn, address)
+			}
+		}
+	}
+	sort.Strings(dependsOn)
+	return dependsOn
+}
+
+// graphNodeLabel returns the unquoted label of a node in the graph, which is
+// the resource address without the count specifier.
+func graphNodeLabel(graph *gographviz.Graph, name string) string {
+	node, ok := graph.Nodes.Lookup[name]
+	if !ok {
+		return ""
+	}
+	return strings.Trim(node.Attrs["label"], `"`)
+}
+
 type graphResource struct {
 	Label string
 	Depth uint
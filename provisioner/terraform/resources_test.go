@@ -342,11 +342,13 @@ func TestConvertResources(t *testing.T) {
 							DisplayName: "Foobar Script 1",
 							Script:      "echo foobar 1",
 							RunOnStart:  true,
+							Address:     "coder_script.script1",
 						},
 						{
 							DisplayName: "Foobar Script 2",
 							Script:      "echo foobar 2",
 							RunOnStart:  true,
+							Address:     "coder_script.script2",
 						},
 					},
 					Auth:                     &proto.Agent_Token{},
@@ -365,6 +367,7 @@ func TestConvertResources(t *testing.T) {
 							DisplayName: "Foobar Script 3",
 							Script:      "echo foobar 3",
 							RunOnStart:  true,
+							Address:     "coder_script.script3",
 						},
 					},
 					Auth:                     &proto.Agent_Token{},
@@ -1087,6 +1090,392 @@ func TestInstanceIDAssociation(t *testing.T) {
 	}
 }
 
+func TestDNSRecordAssociation(t *testing.T) {
+	t.Parallel()
+
+	convert := func(t *testing.T, records ...map[string]interface{}) (*terraform.State, error) {
+		ctx, logger := ctxAndLogger(t)
+		resources := []*tfjson.StateResource{{
+			Address: "coder_agent.dev",
+			Type:    "coder_agent",
+			Name:    "dev",
+			Mode:    tfjson.ManagedResourceMode,
+			AttributeValues: map[string]interface{}{
+				"id":   "agent-id",
+				"arch": "amd64",
+				"auth": "token",
+			},
+		}, {
+			Address:   "null_resource.dev",
+			Type:      "null_resource",
+			Name:      "dev",
+			Mode:      tfjson.ManagedResourceMode,
+			DependsOn: []string{"coder_agent.dev"},
+		}}
+		for i, record := range records {
+			resourceName := fmt.Sprintf("record%d", i)
+			record["agent_id"] = "agent-id"
+			resources = append(resources, &tfjson.StateResource{
+				Address:         "coder_dns_record." + resourceName,
+				Type:            "coder_dns_record",
+				Name:            resourceName,
+				Mode:            tfjson.ManagedResourceMode,
+				AttributeValues: record,
+			})
+		}
+		return terraform.ConvertState(ctx, []*tfjson.StateModule{{
+			Resources: resources,
+			// This is manually created to join the edges.
+		}}, `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] coder_agent.dev" [label = "coder_agent.dev", shape = "box"]
+		"[root] null_resource.dev" [label = "null_resource.dev", shape = "box"]
+		"[root] null_resource.dev" -> "[root] coder_agent.dev"
+	}
+}
+`, logger)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+		state, err := convert(t,
+			map[string]interface{}{"name": "api", "port": float64(8080)},
+			map[string]interface{}{"name": "*.apps"},
+		)
+		require.NoError(t, err)
+		// coder_dns_record is not a resource of its own.
+		require.Len(t, state.Resources, 1)
+		require.Len(t, state.Resources[0].Agents, 1)
+		ports := map[string]int32{}
+		for _, record := range state.Resources[0].Agents[0].DnsRecords {
+			ports[record.Name] = record.Port
+		}
+		require.Equal(t, map[string]int32{"api": 8080, "*.apps": 0}, ports)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := convert(t, map[string]interface{}{"name": "not_valid"})
+		require.ErrorContains(t, err, "invalid dns record name")
+	})
+
+	t.Run("InvalidPort", func(t *testing.T) {
+		t.Parallel()
+		_, err := convert(t, map[string]interface{}{"name": "api", "port": float64(70000)})
+		require.ErrorContains(t, err, "invalid port 70000")
+	})
+}
+
+func TestServiceAssociation(t *testing.T) {
+	t.Parallel()
+
+	convert := func(t *testing.T, attrs map[string]interface{}) (*terraform.State, error) {
+		ctx, logger := ctxAndLogger(t)
+		attrs["agent_id"] = "agent-id"
+		return terraform.ConvertState(ctx, []*tfjson.StateModule{{
+			Resources: []*tfjson.StateResource{{
+				Address: "coder_agent.dev",
+				Type:    "coder_agent",
+				Name:    "dev",
+				Mode:    tfjson.ManagedResourceMode,
+				AttributeValues: map[string]interface{}{
+					"id":   "agent-id",
+					"arch": "amd64",
+					"auth": "token",
+				},
+			}, {
+				Address:   "null_resource.dev",
+				Type:      "null_resource",
+				Name:      "dev",
+				Mode:      tfjson.ManagedResourceMode,
+				DependsOn: []string{"coder_agent.dev"},
+			}, {
+				Address:         "coder_service.web",
+				Type:            "coder_service",
+				Name:            "web",
+				Mode:            tfjson.ManagedResourceMode,
+				AttributeValues: attrs,
+			}},
+			// This is manually created to join the edges.
+		}}, `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] coder_agent.dev" [label = "coder_agent.dev", shape = "box"]
+		"[root] null_resource.dev" [label = "null_resource.dev", shape = "box"]
+		"[root] null_resource.dev" -> "[root] coder_agent.dev"
+	}
+}
+`, logger)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+		state, err := convert(t, map[string]interface{}{
+			"command":         "npm run dev",
+			"dir":             "/home/coder/app",
+			"env":             map[string]interface{}{"PORT": "3000", "HOST": "0.0.0.0"},
+			"restart":         "on-failure",
+			"restart_backoff": 2,
+			"healthcheck": []interface{}{map[string]interface{}{
+				"url":       "http://localhost:3000",
+				"interval":  5,
+				"threshold": 3,
+			}},
+		})
+		require.NoError(t, err)
+		// coder_service is not a resource of its own.
+		require.Len(t, state.Resources, 1)
+		require.Len(t, state.Resources[0].Agents, 1)
+		require.Len(t, state.Resources[0].Agents[0].Services, 1)
+		service := state.Resources[0].Agents[0].Services[0]
+		require.Equal(t, "web", service.Name)
+		require.Equal(t, "web", service.DisplayName)
+		require.Equal(t, "npm run dev", service.Command)
+		require.Equal(t, "/home/coder/app", service.Dir)
+		require.Equal(t, proto.ServiceRestartPolicy_ON_FAILURE, service.RestartPolicy)
+		require.EqualValues(t, 2, service.RestartBackoffSeconds)
+		require.Equal(t, "http://localhost:3000", service.Healthcheck.GetUrl())
+		require.Equal(t, []*proto.Env{
+			{Name: "HOST", Value: "0.0.0.0"},
+			{Name: "PORT", Value: "3000"},
+		}, service.Env)
+	})
+
+	t.Run("InvalidRestartPolicy", func(t *testing.T) {
+		t.Parallel()
+		_, err := convert(t, map[string]interface{}{
+			"command": "sleep infinity",
+			"restart": "sometimes",
+		})
+		require.ErrorContains(t, err, "invalid restart policy")
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		t.Parallel()
+		_, err := convert(t, map[string]interface{}{
+			"name":    "Not_Valid",
+			"command": "sleep infinity",
+		})
+		require.ErrorContains(t, err, "invalid service name")
+	})
+}
+
+func TestAppHealthcheck(t *testing.T) {
+	t.Parallel()
+
+	convert := func(t *testing.T, healthcheck map[string]interface{}) (*terraform.State, error) {
+		ctx, logger := ctxAndLogger(t)
+		return terraform.ConvertState(ctx, []*tfjson.StateModule{{
+			Resources: []*tfjson.StateResource{{
+				Address: "coder_agent.dev",
+				Type:    "coder_agent",
+				Name:    "dev",
+				Mode:    tfjson.ManagedResourceMode,
+				AttributeValues: map[string]interface{}{
+					"id":   "agent-id",
+					"arch": "amd64",
+					"auth": "token",
+				},
+			}, {
+				Address:   "null_resource.dev",
+				Type:      "null_resource",
+				Name:      "dev",
+				Mode:      tfjson.ManagedResourceMode,
+				DependsOn: []string{"coder_agent.dev"},
+			}, {
+				Address: "coder_app.db",
+				Type:    "coder_app",
+				Name:    "db",
+				Mode:    tfjson.ManagedResourceMode,
+				AttributeValues: map[string]interface{}{
+					"agent_id":    "agent-id",
+					"slug":        "db",
+					"healthcheck": []interface{}{healthcheck},
+				},
+			}},
+			// This is manually created to join the edges.
+		}}, `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] coder_agent.dev" [label = "coder_agent.dev", shape = "box"]
+		"[root] null_resource.dev" [label = "null_resource.dev", shape = "box"]
+		"[root] null_resource.dev" -> "[root] coder_agent.dev"
+	}
+}
+`, logger)
+	}
+
+	t.Run("TCP", func(t *testing.T) {
+		t.Parallel()
+		state, err := convert(t, map[string]interface{}{
+			"tcp":       "localhost:5432",
+			"interval":  5,
+			"threshold": 3,
+		})
+		require.NoError(t, err)
+		require.Len(t, state.Resources[0].Agents[0].Apps, 1)
+		healthcheck := state.Resources[0].Agents[0].Apps[0].Healthcheck
+		require.Equal(t, "localhost:5432", healthcheck.GetTcp())
+		require.EqualValues(t, 5, healthcheck.GetInterval())
+		require.EqualValues(t, 3, healthcheck.GetThreshold())
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		t.Parallel()
+		state, err := convert(t, map[string]interface{}{
+			"url":               "http://localhost:8080/healthz",
+			"interval":          5,
+			"threshold":         3,
+			"expected_statuses": []interface{}{200, 204},
+			"body_regex":        `"status":\s*"ok"`,
+			"headers":           map[string]interface{}{"Authorization": "Bearer token"},
+		})
+		require.NoError(t, err)
+		healthcheck := state.Resources[0].Agents[0].Apps[0].Healthcheck
+		require.Equal(t, []int32{200, 204}, healthcheck.GetExpectedStatuses())
+		require.Equal(t, `"status":\s*"ok"`, healthcheck.GetBodyRegex())
+		require.Equal(t, map[string]string{"Authorization": "Bearer token"}, healthcheck.GetHeaders())
+	})
+
+	t.Run("MultipleChecks", func(t *testing.T) {
+		t.Parallel()
+		_, err := convert(t, map[string]interface{}{
+			"url":     "http://localhost:8080/healthz",
+			"command": "pg_isready",
+		})
+		require.ErrorContains(t, err, "only one of url, tcp and command can be set")
+	})
+
+	t.Run("HTTPOptionsWithoutURL", func(t *testing.T) {
+		t.Parallel()
+		_, err := convert(t, map[string]interface{}{
+			"tcp":        "localhost:5432",
+			"body_regex": "ok",
+		})
+		require.ErrorContains(t, err, "require url")
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		t.Parallel()
+		_, err := convert(t, map[string]interface{}{
+			"url":               "http://localhost:8080/healthz",
+			"expected_statuses": []interface{}{999},
+		})
+		require.ErrorContains(t, err, "invalid expected status 999")
+	})
+
+	t.Run("InvalidBodyRegex", func(t *testing.T) {
+		t.Parallel()
+		_, err := convert(t, map[string]interface{}{
+			"url":        "http://localhost:8080/healthz",
+			"body_regex": "(",
+		})
+		require.ErrorContains(t, err, "invalid body_regex")
+	})
+}
+
+func TestScriptDependencies(t *testing.T) {
+	t.Parallel()
+	ctx, logger := ctxAndLogger(t)
+
+	script := func(name, agentID string, attrs map[string]interface{}) *tfjson.StateResource {
+		if attrs == nil {
+			attrs = map[string]interface{}{}
+		}
+		attrs["agent_id"] = agentID
+		attrs["display_name"] = name
+		attrs["script"] = "echo " + name
+		attrs["run_on_start"] = true
+		return &tfjson.StateResource{
+			Address:         "coder_script." + name,
+			Type:            "coder_script",
+			Name:            name,
+			Mode:            tfjson.ManagedResourceMode,
+			AttributeValues: attrs,
+		}
+	}
+	state, err := terraform.ConvertState(ctx, []*tfjson.StateModule{{
+		Resources: []*tfjson.StateResource{{
+			Address: "coder_agent.dev",
+			Type:    "coder_agent",
+			Name:    "dev",
+			Mode:    tfjson.ManagedResourceMode,
+			AttributeValues: map[string]interface{}{
+				"id":   "dev-id",
+				"arch": "amd64",
+				"auth": "token",
+			},
+		}, {
+			Address: "coder_agent.other",
+			Type:    "coder_agent",
+			Name:    "other",
+			Mode:    tfjson.ManagedResourceMode,
+			AttributeValues: map[string]interface{}{
+				"id":   "other-id",
+				"arch": "amd64",
+				"auth": "token",
+			},
+		}, {
+			Address:   "null_resource.dev",
+			Type:      "null_resource",
+			Name:      "dev",
+			Mode:      tfjson.ManagedResourceMode,
+			DependsOn: []string{"coder_agent.dev", "coder_agent.other"},
+		},
+			script("database", "dev-id", map[string]interface{}{
+				"ready": []interface{}{map[string]interface{}{
+					"port":    5432,
+					"timeout": 30,
+				}},
+			}),
+			script("migrate", "dev-id", nil),
+			script("seed", "dev-id", nil),
+			script("unrelated", "other-id", nil),
+		},
+		// This is manually created to join the edges.
+	}}, `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] coder_agent.dev" [label = "coder_agent.dev", shape = "box"]
+		"[root] coder_agent.other" [label = "coder_agent.other", shape = "box"]
+		"[root] null_resource.dev" [label = "null_resource.dev", shape = "box"]
+		"[root] coder_script.database" [label = "coder_script.database", shape = "box"]
+		"[root] coder_script.migrate" [label = "coder_script.migrate", shape = "box"]
+		"[root] coder_script.seed" [label = "coder_script.seed", shape = "box"]
+		"[root] coder_script.unrelated" [label = "coder_script.unrelated", shape = "box"]
+		"[root] null_resource.dev" -> "[root] coder_agent.dev"
+		"[root] null_resource.dev" -> "[root] coder_agent.other"
+		"[root] coder_script.migrate" -> "[root] coder_script.database"
+		"[root] coder_script.seed" -> "[root] coder_script.migrate"
+		"[root] coder_script.seed" -> "[root] coder_script.unrelated"
+	}
+}
+`, logger)
+	require.NoError(t, err)
+	require.Len(t, state.Resources, 1)
+	require.Len(t, state.Resources[0].Agents, 2)
+
+	scripts := map[string]*proto.Script{}
+	for _, agent := range state.Resources[0].Agents {
+		for _, script := range agent.Scripts {
+			scripts[script.Address] = script
+		}
+	}
+	require.Len(t, scripts, 4)
+	require.Empty(t, scripts["coder_script.database"].DependsOn)
+	require.EqualValues(t, 5432, scripts["coder_script.database"].Ready.GetPort())
+	require.EqualValues(t, 30, scripts["coder_script.database"].Ready.GetTimeoutSeconds())
+	require.Equal(t, []string{"coder_script.database"}, scripts["coder_script.migrate"].DependsOn)
+	require.Nil(t, scripts["coder_script.migrate"].Ready)
+	// The dependency on a script of another agent is dropped.
+	require.Equal(t, []string{"coder_script.migrate"}, scripts["coder_script.seed"].DependsOn)
+}
+
 // sortResource ensures resources appear in a consistent ordering
 // to prevent tests from flaking.
 func sortResources(resources []*proto.Resource) {
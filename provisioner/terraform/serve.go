@@ -21,7 +21,10 @@ import (
 type ServeOptions struct {
 	*provisionersdk.ServeOptions
 
-	// BinaryPath specifies the "terraform" binary to use.
+	// Engine is the infrastructure as code tool to run. Defaults to
+	// Terraform.
+	Engine Engine
+	// BinaryPath specifies the "terraform" or "tofu" binary to use.
 	// If omitted, the $PATH will attempt to find it.
 	BinaryPath string
 	// CachePath must not be used by multiple processes at once.
@@ -41,10 +44,10 @@ type ServeOptions struct {
 	ExitTimeout time.Duration
 }
 
-func absoluteBinaryPath(ctx context.Context, logger slog.Logger) (string, error) {
-	binaryPath, err := safeexec.LookPath("terraform")
+func absoluteBinaryPath(ctx context.Context, logger slog.Logger, engine Engine) (string, error) {
+	binaryPath, err := safeexec.LookPath(engine.BinaryName())
 	if err != nil {
-		return "", xerrors.Errorf("Terraform binary not found: %w", err)
+		return "", xerrors.Errorf("%s binary not found: %w", engine.DisplayName(), err)
 	}
 
 	// If the "coder" binary is in the same directory as
@@ -54,32 +57,32 @@ func absoluteBinaryPath(ctx context.Context, logger slog.Logger) (string, error)
 	// to execute this properly!
 	absoluteBinary, err := filepath.Abs(binaryPath)
 	if err != nil {
-		return "", xerrors.Errorf("Terraform binary absolute path not found: %w", err)
+		return "", xerrors.Errorf("%s binary absolute path not found: %w", engine.DisplayName(), err)
 	}
 
-	// Checking the installed version of Terraform.
+	// Checking the installed version of the engine.
 	installedVersion, err := versionFromBinaryPath(ctx, absoluteBinary)
 	if err != nil {
-		return "", xerrors.Errorf("Terraform binary get version failed: %w", err)
+		return "", xerrors.Errorf("%s binary get version failed: %w", engine.DisplayName(), err)
 	}
 
-	logger.Info(ctx, "detected terraform version",
+	logger.Info(ctx, "detected "+string(engine)+" version",
 		slog.F("installed_version", installedVersion.String()),
-		slog.F("min_version", minTerraformVersion.String()),
-		slog.F("max_version", maxTerraformVersion.String()))
+		slog.F("min_version", engine.MinVersion().String()),
+		slog.F("max_version", engine.MaxVersion().String()))
 
-	if installedVersion.LessThan(minTerraformVersion) {
-		logger.Warn(ctx, "installed terraform version too old, will download known good version to cache")
+	if installedVersion.LessThan(engine.MinVersion()) {
+		logger.Warn(ctx, "installed "+string(engine)+" version too old, will download known good version to cache")
 		return "", terraformMinorVersionMismatch
 	}
 
 	// Warn if the installed version is newer than what we've decided is the max.
 	// We used to ignore it and download our own version but this makes it easier
 	// to test out newer versions of Terraform.
-	if installedVersion.GreaterThanOrEqual(maxTerraformVersion) {
-		logger.Warn(ctx, "installed terraform version newer than expected, you may experience bugs",
+	if installedVersion.GreaterThanOrEqual(engine.MaxVersion()) {
+		logger.Warn(ctx, "installed "+string(engine)+" version newer than expected, you may experience bugs",
 			slog.F("installed_version", installedVersion.String()),
-			slog.F("max_version", maxTerraformVersion.String()))
+			slog.F("max_version", engine.MaxVersion().String()))
 	}
 
 	return absoluteBinary, nil
@@ -87,8 +90,11 @@ func absoluteBinaryPath(ctx context.Context, logger slog.Logger) (string, error)
 
 // Serve starts a dRPC server on the provided transport speaking Terraform provisioner.
 func Serve(ctx context.Context, options *ServeOptions) error {
+	if options.Engine == "" {
+		options.Engine = EngineTerraform
+	}
 	if options.BinaryPath == "" {
-		absoluteBinary, err := absoluteBinaryPath(ctx, options.Logger)
+		absoluteBinary, err := absoluteBinaryPath(ctx, options.Logger, options.Engine)
 		if err != nil {
 			// This is an early exit to prevent extra execution in case the context is canceled.
 			// It generally happens in unit tests since this method is asynchronous and
@@ -97,12 +103,17 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 				return xerrors.Errorf("absolute binary context canceled: %w", err)
 			}
 
-			options.Logger.Warn(ctx, "no usable terraform binary found, downloading to cache dir",
-				slog.F("terraform_version", TerraformVersion.String()),
+			options.Logger.Warn(ctx, "no usable "+string(options.Engine)+" binary found, downloading to cache dir",
+				slog.F("engine", options.Engine),
+				slog.F("version", options.Engine.DefaultVersion().String()),
 				slog.F("cache_dir", options.CachePath))
-			binPath, err := Install(ctx, options.Logger, options.CachePath, TerraformVersion)
+			install := Install
+			if options.Engine == EngineOpenTofu {
+				install = InstallOpenTofu
+			}
+			binPath, err := install(ctx, options.Logger, options.CachePath, options.Engine.DefaultVersion())
 			if err != nil {
-				return xerrors.Errorf("install terraform: %w", err)
+				return xerrors.Errorf("install %s: %w", options.Engine, err)
 			}
 			options.BinaryPath = binPath
 		} else {
@@ -117,6 +128,7 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 	}
 	return provisionersdk.Serve(ctx, &server{
 		execMut:     &sync.Mutex{},
+		engine:      options.Engine,
 		binaryPath:  options.BinaryPath,
 		cachePath:   options.CachePath,
 		logger:      options.Logger,
@@ -127,6 +139,7 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 
 type server struct {
 	execMut     *sync.Mutex
+	engine      Engine
 	binaryPath  string
 	cachePath   string
 	logger      slog.Logger
@@ -144,6 +157,7 @@ func (s *server) executor(workdir string, stage database.ProvisionerJobTimingSta
 	return &executor{
 		server:     s,
 		mut:        s.execMut,
+		engine:     s.engine,
 		binaryPath: s.binaryPath,
 		cachePath:  s.cachePath,
 		workdir:    workdir,
@@ -18,6 +18,7 @@ import (
 func Test_absoluteBinaryPath(t *testing.T) {
 	tests := []struct {
 		name             string
+		engine           Engine
 		terraformVersion string
 		expectedErr      error
 	}{
@@ -41,6 +42,18 @@ func Test_absoluteBinaryPath(t *testing.T) {
 			terraformVersion: "9.9.9",
 			expectedErr:      nil,
 		},
+		{
+			name:             "TestOpenTofuCorrectVersion",
+			engine:           EngineOpenTofu,
+			terraformVersion: "1.8.2",
+			expectedErr:      nil,
+		},
+		{
+			name:             "TestOpenTofuOldVersion",
+			engine:           EngineOpenTofu,
+			terraformVersion: "1.5.7",
+			expectedErr:      terraformMinorVersionMismatch,
+		},
 		{
 			name:             "TestMalformedVersion",
 			terraformVersion: "version",
@@ -55,6 +68,10 @@ func Test_absoluteBinaryPath(t *testing.T) {
 			}
 
 			log := testutil.Logger(t)
+			engine := tt.engine
+			if engine == "" {
+				engine = EngineTerraform
+			}
 			// Create a temp dir with the binary
 			tempDir := t.TempDir()
 			terraformBinaryOutput := fmt.Sprintf(`#!/bin/sh
@@ -69,7 +86,7 @@ func Test_absoluteBinaryPath(t *testing.T) {
 
 			// #nosec
 			err := os.WriteFile(
-				filepath.Join(tempDir, "terraform"),
+				filepath.Join(tempDir, engine.BinaryName()),
 				[]byte(terraformBinaryOutput),
 				0o770,
 			)
@@ -81,11 +98,11 @@ func Test_absoluteBinaryPath(t *testing.T) {
 
 			var expectedAbsoluteBinary string
 			if tt.expectedErr == nil {
-				expectedAbsoluteBinary = filepath.Join(tempDir, "terraform")
+				expectedAbsoluteBinary = filepath.Join(tempDir, engine.BinaryName())
 			}
 
 			ctx := testutil.Context(t, testutil.WaitShort)
-			actualAbsoluteBinary, actualErr := absoluteBinaryPath(ctx, log)
+			actualAbsoluteBinary, actualErr := absoluteBinaryPath(ctx, log, engine)
 
 			require.Equal(t, expectedAbsoluteBinary, actualAbsoluteBinary)
 			if tt.expectedErr == nil {
@@ -0,0 +1,370 @@
+package tfparse
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/xerrors"
+
+	"github.com/onchainengineering/hmi-wirtual/provisioner"
+)
+
+// LintSeverity is the severity of a LintFinding.
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+)
+
+// LintRule is a static check of a template.
+type LintRule struct {
+	ID          string
+	Description string
+	Severity    LintSeverity
+	check       func(ctx context.Context, p *Parser) ([]LintFinding, error)
+}
+
+// LintFinding is a problem found by a LintRule. Filename is relative to the
+// directory of the module.
+type LintFinding struct {
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+	Filename string       `json:"filename"`
+	Line     int          `json:"line"`
+	Column   int          `json:"column"`
+}
+
+// LintRules returns all rules, in the order they're documented.
+func LintRules() []LintRule {
+	return []LintRule{
+		{
+			ID:          "immutable-parameter-default",
+			Description: "Immutable parameters should have a default value.",
+			Severity:    LintSeverityWarning,
+			check:       lintImmutableParameterDefault,
+		},
+		{
+			ID:          "workspace-tags",
+			Description: "Workspace tags must evaluate to non-empty values from default values.",
+			Severity:    LintSeverityError,
+			check:       lintWorkspaceTags,
+		},
+		{
+			ID:          "startup-script-timeout",
+			Description: "Scripts that run on start should have a timeout.",
+			Severity:    LintSeverityWarning,
+			check:       lintStartupScriptTimeout,
+		},
+		{
+			ID:          "unknown-agent",
+			Description: "Apps, scripts and environment variables must use a declared agent.",
+			Severity:    LintSeverityError,
+			check:       lintUnknownAgent,
+		},
+		{
+			ID:          "app-slug",
+			Description: "App slugs must be valid and unique within the template.",
+			Severity:    LintSeverityError,
+			check:       lintAppSlug,
+		},
+		{
+			ID:          "deprecated-attribute",
+			Description: "Deprecated attributes should be replaced.",
+			Severity:    LintSeverityWarning,
+			check:       lintDeprecatedAttribute,
+		},
+	}
+}
+
+// Lint runs the given rules against the module and returns the findings
+// sorted by their position.
+func (p *Parser) Lint(ctx context.Context, rules []LintRule) ([]LintFinding, error) {
+	findings := []LintFinding{}
+	for _, rule := range rules {
+		found, err := rule.check(ctx, p)
+		if err != nil {
+			return nil, xerrors.Errorf("rule %s: %w", rule.ID, err)
+		}
+		for _, finding := range found {
+			finding.Rule = rule.ID
+			finding.Severity = rule.Severity
+			if rel, err := filepath.Rel(p.workdir, finding.Filename); err == nil {
+				finding.Filename = filepath.ToSlash(rel)
+			}
+			findings = append(findings, finding)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return findings, nil
+}
+
+func newLintFinding(rng hcl.Range, format string, args ...any) LintFinding {
+	return LintFinding{
+		Message:  fmt.Sprintf(format, args...),
+		Filename: rng.Filename,
+		Line:     rng.Start.Line,
+		Column:   rng.Start.Column,
+	}
+}
+
+func lintImmutableParameterDefault(_ context.Context, p *Parser) ([]LintFinding, error) {
+	blocks, err := p.blocks("data", "coder_parameter")
+	if err != nil {
+		return nil, err
+	}
+	var findings []LintFinding
+	for _, block := range blocks {
+		if _, ok := block.Body.Attributes["default"]; ok {
+			continue
+		}
+		// Parameters are immutable unless told otherwise.
+		mutable := false
+		if attr, ok := block.Body.Attributes["mutable"]; ok {
+			val, ok := literalValue(attr, cty.Bool)
+			if !ok {
+				continue
+			}
+			mutable = val.True()
+		}
+		if mutable {
+			continue
+		}
+		findings = append(findings, newLintFinding(block.DefRange(),
+			"parameter %q is immutable and has no default, so it must be set when a workspace is created and can never be changed", block.Labels[1]))
+	}
+	return findings, nil
+}
+
+func lintWorkspaceTags(ctx context.Context, p *Parser) ([]LintFinding, error) {
+	blocks, err := p.blocks("data", "coder_workspace_tags")
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	_, err = p.WorkspaceTagDefaults(ctx)
+	if err != nil {
+		return []LintFinding{newLintFinding(blocks[0].DefRange(), "%s", err.Error())}, nil
+	}
+	return nil, nil
+}
+
+func lintStartupScriptTimeout(_ context.Context, p *Parser) ([]LintFinding, error) {
+	agents, err := p.blocks("resource", "coder_agent")
+	if err != nil {
+		return nil, err
+	}
+	var findings []LintFinding
+	for _, agent := range agents {
+		script, ok := agent.Body.Attributes["startup_script"]
+		if !ok {
+			continue
+		}
+		if _, ok := agent.Body.Attributes["startup_script_timeout"]; ok {
+			continue
+		}
+		findings = append(findings, newLintFinding(script.SrcRange,
+			"agent %q has a startup script without a timeout", agent.Labels[1]))
+	}
+
+	scripts, err := p.blocks("resource", "coder_script")
+	if err != nil {
+		return nil, err
+	}
+	for _, script := range scripts {
+		attr, ok := script.Body.Attributes["run_on_start"]
+		if !ok {
+			continue
+		}
+		if val, ok := literalValue(attr, cty.Bool); !ok || val.False() {
+			continue
+		}
+		if _, ok := script.Body.Attributes["timeout"]; ok {
+			continue
+		}
+		findings = append(findings, newLintFinding(script.DefRange(),
+			"script %q runs on start without a timeout", script.Labels[1]))
+	}
+	return findings, nil
+}
+
+func lintUnknownAgent(_ context.Context, p *Parser) ([]LintFinding, error) {
+	agents := map[string]struct{}{}
+	for _, resource := range p.module.ManagedResources {
+		if resource.Type == "coder_agent" {
+			agents[resource.Name] = struct{}{}
+		}
+	}
+
+	var findings []LintFinding
+	for _, typ := range []string{"coder_app", "coder_script", "coder_env"} {
+		blocks, err := p.blocks("resource", typ)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			attr, ok := block.Body.Attributes["agent_id"]
+			if !ok {
+				continue
+			}
+			for _, traversal := range attr.Expr.Variables() {
+				if traversal.RootName() != "coder_agent" || len(traversal) < 2 {
+					continue
+				}
+				step, ok := traversal[1].(hcl.TraverseAttr)
+				if !ok {
+					continue
+				}
+				if _, ok := agents[step.Name]; ok {
+					continue
+				}
+				findings = append(findings, newLintFinding(traversal.SourceRange(),
+					"%s %q references agent %q, which isn't declared", typ, block.Labels[1], step.Name))
+			}
+		}
+	}
+	return findings, nil
+}
+
+func lintAppSlug(_ context.Context, p *Parser) ([]LintFinding, error) {
+	apps, err := p.blocks("resource", "coder_app")
+	if err != nil {
+		return nil, err
+	}
+	var findings []LintFinding
+	// Maps slugs to the name of the first app that uses them.
+	slugs := map[string]string{}
+	for _, app := range apps {
+		// The slug defaults to the name of the resource.
+		slug := app.Labels[1]
+		rng := app.DefRange()
+		if attr, ok := app.Body.Attributes["slug"]; ok {
+			val, ok := literalValue(attr, cty.String)
+			if !ok {
+				continue
+			}
+			slug = val.AsString()
+			rng = attr.SrcRange
+		}
+		if !provisioner.AppSlugRegex.MatchString(slug) {
+			findings = append(findings, newLintFinding(rng,
+				"app %q has an invalid slug %q; slugs may only contain lowercase letters, numbers and single hyphens", app.Labels[1], slug))
+			continue
+		}
+		if other, ok := slugs[slug]; ok {
+			findings = append(findings, newLintFinding(rng,
+				"app %q has the slug %q, which is already used by app %q", app.Labels[1], slug, other))
+			continue
+		}
+		slugs[slug] = app.Labels[1]
+	}
+	return findings, nil
+}
+
+// deprecatedAttributes lists the deprecated attributes of blocks, and the
+// attributes that replace them.
+var deprecatedAttributes = []struct {
+	kind        string
+	typ         string
+	attribute   string
+	replacement string
+}{
+	{"resource", "coder_agent", "login_before_ready", "startup_script_behavior"},
+	{"resource", "coder_app", "name", "display_name"},
+	{"resource", "coder_app", "relative_path", "subdomain"},
+	{"data", "coder_parameter", "legacy_variable", ""},
+	{"data", "coder_parameter", "legacy_variable_name", ""},
+}
+
+func lintDeprecatedAttribute(_ context.Context, p *Parser) ([]LintFinding, error) {
+	var findings []LintFinding
+	for _, deprecated := range deprecatedAttributes {
+		blocks, err := p.blocks(deprecated.kind, deprecated.typ)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			attr, ok := block.Body.Attributes[deprecated.attribute]
+			if !ok {
+				continue
+			}
+			message := fmt.Sprintf("%s %q uses the deprecated attribute %q", deprecated.typ, block.Labels[1], deprecated.attribute)
+			if deprecated.replacement != "" {
+				message += fmt.Sprintf(", use %q instead", deprecated.replacement)
+			}
+			findings = append(findings, newLintFinding(attr.SrcRange, "%s", message))
+		}
+	}
+	return findings, nil
+}
+
+// blocks returns the blocks of the given kind ("resource" or "data") and
+// type, sorted by their position.
+func (p *Parser) blocks(kind, typ string) ([]*hclsyntax.Block, error) {
+	resources := p.module.ManagedResources
+	if kind == "data" {
+		resources = p.module.DataResources
+	}
+	var matching []*tfconfig.Resource
+	for _, resource := range resources {
+		if resource.Type == typ {
+			matching = append(matching, resource)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return compareSourcePos(matching[i].Pos, matching[j].Pos)
+	})
+
+	var blocks []*hclsyntax.Block
+	for _, resource := range matching {
+		// Only native syntax can be inspected, JSON files are skipped.
+		if filepath.Ext(resource.Pos.Filename) != ".tf" {
+			continue
+		}
+		// NOTE: hclparse.Parser will cache multiple successive calls to parse the same file.
+		file, diags := p.underlying.ParseHCLFile(resource.Pos.Filename)
+		if diags.HasErrors() {
+			return nil, xerrors.Errorf("can't parse the resource file %q: %s", resource.Pos.Filename, diags.Error())
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type == kind && slices.Equal(block.Labels, []string{typ, resource.Name}) {
+				blocks = append(blocks, block)
+				break
+			}
+		}
+	}
+	return blocks, nil
+}
+
+// literalValue returns the value of an attribute when it's a literal of the
+// given type.
+func literalValue(attr *hclsyntax.Attribute, typ cty.Type) (cty.Value, bool) {
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || !val.Type().Equals(typ) {
+		return cty.NilVal, false
+	}
+	return val, true
+}
@@ -0,0 +1,216 @@
+package tfparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform/tfparse"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	type finding struct {
+		rule string
+		line int
+	}
+	for _, tc := range []struct {
+		name     string
+		files    map[string]string
+		expected []finding
+	}{
+		{
+			name: "clean",
+			files: map[string]string{
+				"main.tf": `
+resource "coder_agent" "main" {
+  os                     = "linux"
+  arch                   = "amd64"
+  startup_script         = "echo hi"
+  startup_script_timeout = 180
+}
+resource "coder_app" "code-server" {
+  agent_id     = coder_agent.main.id
+  slug         = "code-server"
+  display_name = "code-server"
+}
+data "coder_parameter" "region" {
+  name    = "region"
+  type    = "string"
+  default = "us"
+}
+data "coder_parameter" "size" {
+  name    = "size"
+  mutable = true
+}
+`,
+			},
+		},
+		{
+			name: "immutable parameter without default",
+			files: map[string]string{
+				"main.tf": `
+data "coder_parameter" "region" {
+  name    = "region"
+  mutable = false
+}
+data "coder_parameter" "zone" {
+  name = "zone"
+}
+data "coder_parameter" "size" {
+  name    = "size"
+  default = "large"
+}
+`,
+			},
+			expected: []finding{
+				{rule: "immutable-parameter-default", line: 2},
+				{rule: "immutable-parameter-default", line: 6},
+			},
+		},
+		{
+			name: "invalid workspace tags",
+			files: map[string]string{
+				"main.tf": `
+data "coder_parameter" "region" {
+  name = "region"
+  type = "string"
+}
+data "coder_workspace_tags" "tags" {
+  tags = {
+    "region" = data.coder_parameter.region.value
+  }
+}
+`,
+			},
+			expected: []finding{
+				{rule: "immutable-parameter-default", line: 2},
+				{rule: "workspace-tags", line: 6},
+			},
+		},
+		{
+			name: "scripts without timeouts",
+			files: map[string]string{
+				"main.tf": `
+resource "coder_agent" "main" {
+  os             = "linux"
+  arch           = "amd64"
+  startup_script = "echo hi"
+}
+resource "coder_script" "dotfiles" {
+  agent_id     = coder_agent.main.id
+  display_name = "Dotfiles"
+  script       = "coder dotfiles"
+  run_on_start = true
+}
+resource "coder_script" "cleanup" {
+  agent_id     = coder_agent.main.id
+  display_name = "Cleanup"
+  script       = "rm -rf /tmp/*"
+  run_on_stop  = true
+}
+`,
+			},
+			expected: []finding{
+				{rule: "startup-script-timeout", line: 5},
+				{rule: "startup-script-timeout", line: 7},
+			},
+		},
+		{
+			name: "unknown agent",
+			files: map[string]string{
+				"main.tf": `
+resource "coder_agent" "main" {
+  os   = "linux"
+  arch = "amd64"
+}
+resource "coder_app" "code-server" {
+  agent_id = coder_agent.mian.id
+  slug     = "code-server"
+}
+`,
+			},
+			expected: []finding{
+				{rule: "unknown-agent", line: 7},
+			},
+		},
+		{
+			name: "app slugs",
+			files: map[string]string{
+				"main.tf": `
+resource "coder_agent" "main" {
+  os   = "linux"
+  arch = "amd64"
+}
+resource "coder_app" "code-server" {
+  agent_id = coder_agent.main.id
+}
+resource "coder_app" "vscode" {
+  agent_id = coder_agent.main.id
+  slug     = "code-server"
+}
+resource "coder_app" "jupyter" {
+  agent_id = coder_agent.main.id
+  slug     = "Jupyter--Lab"
+}
+`,
+			},
+			expected: []finding{
+				{rule: "app-slug", line: 11},
+				{rule: "app-slug", line: 15},
+			},
+		},
+		{
+			name: "deprecated attributes",
+			files: map[string]string{
+				"main.tf": `
+resource "coder_agent" "main" {
+  os                 = "linux"
+  arch               = "amd64"
+  login_before_ready = false
+}
+resource "coder_app" "code-server" {
+  agent_id = coder_agent.main.id
+  slug     = "code-server"
+  name     = "code-server"
+}
+`,
+			},
+			expected: []finding{
+				{rule: "deprecated-attribute", line: 5},
+				{rule: "deprecated-attribute", line: 10},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := testutil.Context(t, testutil.WaitShort)
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+				require.NoError(t, err)
+			}
+			parser, diags := tfparse.New(dir, tfparse.WithLogger(testutil.Logger(t)))
+			require.NoError(t, diags.Err())
+			findings, err := parser.Lint(ctx, tfparse.LintRules())
+			require.NoError(t, err)
+
+			actual := []finding{}
+			for _, f := range findings {
+				require.Equal(t, "main.tf", f.Filename)
+				require.NotEmpty(t, f.Message)
+				actual = append(actual, finding{rule: f.Rule, line: f.Line})
+			}
+			expected := tc.expected
+			if expected == nil {
+				expected = []finding{}
+			}
+			require.Equal(t, expected, actual)
+		})
+	}
+}
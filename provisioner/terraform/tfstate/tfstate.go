@@ -0,0 +1,248 @@
+// Package tfstate reads the Terraform state that is stored with every
+// workspace build, and compares the resources of two states.
+package tfstate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"golang.org/x/xerrors"
+)
+
+// rawState is version 4 of the Terraform state file format, which is what
+// the provisioner stores for every workspace build.
+type rawState struct {
+	Version          int           `json:"version"`
+	TerraformVersion string        `json:"terraform_version"`
+	Resources        []rawResource `json:"resources"`
+}
+
+type rawResource struct {
+	Module    string        `json:"module"`
+	Mode      string        `json:"mode"`
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Provider  string        `json:"provider"`
+	Instances []rawInstance `json:"instances"`
+}
+
+type rawInstance struct {
+	IndexKey            interface{}            `json:"index_key"`
+	Status              string                 `json:"status"`
+	Deposed             string                 `json:"deposed"`
+	SchemaVersion       uint64                 `json:"schema_version"`
+	Attributes          map[string]interface{} `json:"attributes"`
+	SensitiveAttributes json.RawMessage        `json:"sensitive_attributes"`
+	Dependencies        []string               `json:"dependencies"`
+}
+
+// Parse reads a Terraform state. It accepts both the state file written by
+// Terraform and the output of "terraform show -json".
+func Parse(data []byte) (*tfjson.State, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, xerrors.New("state is empty")
+	}
+
+	var header struct {
+		FormatVersion string `json:"format_version"`
+		Version       int    `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, xerrors.Errorf("decode state: %w", err)
+	}
+	if header.FormatVersion != "" {
+		state := &tfjson.State{}
+		if err := state.UnmarshalJSON(data); err != nil {
+			return nil, xerrors.Errorf("decode state: %w", err)
+		}
+		return state, nil
+	}
+	if header.Version != 4 {
+		return nil, xerrors.Errorf("unsupported state version %d", header.Version)
+	}
+
+	var raw rawState
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, xerrors.Errorf("decode state: %w", err)
+	}
+
+	root := &tfjson.StateModule{}
+	modules := map[string]*tfjson.StateModule{"": root}
+	var module func(address string) *tfjson.StateModule
+	module = func(address string) *tfjson.StateModule {
+		if m, ok := modules[address]; ok {
+			return m
+		}
+		m := &tfjson.StateModule{Address: address}
+		modules[address] = m
+		parent := module(parentModule(address))
+		parent.ChildModules = append(parent.ChildModules, m)
+		return m
+	}
+
+	for _, resource := range raw.Resources {
+		m := module(resource.Module)
+		for _, instance := range resource.Instances {
+			r := &tfjson.StateResource{
+				Mode:            tfjson.ResourceMode(resource.Mode),
+				Type:            resource.Type,
+				Name:            resource.Name,
+				Index:           instance.IndexKey,
+				ProviderName:    providerName(resource.Provider),
+				SchemaVersion:   instance.SchemaVersion,
+				AttributeValues: instance.Attributes,
+				SensitiveValues: instance.SensitiveAttributes,
+				DependsOn:       instance.Dependencies,
+				Tainted:         instance.Status == "tainted",
+				DeposedKey:      instance.Deposed,
+			}
+			r.Address = address(resource.Module, r)
+			m.Resources = append(m.Resources, r)
+		}
+	}
+
+	return &tfjson.State{
+		TerraformVersion: raw.TerraformVersion,
+		Values: &tfjson.StateValues{
+			RootModule: root,
+		},
+	}, nil
+}
+
+// Resources returns the resources of every module in the state, sorted by
+// address. Data sources are omitted, since they aren't managed by the build.
+func Resources(state *tfjson.State) []*tfjson.StateResource {
+	if state == nil || state.Values == nil || state.Values.RootModule == nil {
+		return nil
+	}
+	var resources []*tfjson.StateResource
+	var walk func(m *tfjson.StateModule)
+	walk = func(m *tfjson.StateModule) {
+		for _, r := range m.Resources {
+			if r.Mode == tfjson.DataResourceMode || r.DeposedKey != "" {
+				continue
+			}
+			resources = append(resources, r)
+		}
+		for _, child := range m.ChildModules {
+			walk(child)
+		}
+	}
+	walk(state.Values.RootModule)
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Address < resources[j].Address
+	})
+	return resources
+}
+
+// ResourceChange is a resource that exists in both states of a diff, but
+// with different attributes.
+type ResourceChange struct {
+	Address string
+	// Attributes are the names of the top-level attributes that changed.
+	// Their values are left out, since they may contain secrets.
+	Attributes []string
+}
+
+// Difference lists the resources that differ between two states.
+type Difference struct {
+	Added   []string
+	Removed []string
+	Changed []ResourceChange
+}
+
+// Diff compares the resources of two states. Either state may be nil, in
+// which case it's treated as empty.
+func Diff(from, to *tfjson.State) Difference {
+	fromResources := map[string]*tfjson.StateResource{}
+	for _, r := range Resources(from) {
+		fromResources[r.Address] = r
+	}
+
+	var diff Difference
+	for _, r := range Resources(to) {
+		old, ok := fromResources[r.Address]
+		if !ok {
+			diff.Added = append(diff.Added, r.Address)
+			continue
+		}
+		delete(fromResources, r.Address)
+		if attributes := changedAttributes(old.AttributeValues, r.AttributeValues); len(attributes) > 0 {
+			diff.Changed = append(diff.Changed, ResourceChange{
+				Address:    r.Address,
+				Attributes: attributes,
+			})
+		}
+	}
+	for address := range fromResources {
+		diff.Removed = append(diff.Removed, address)
+	}
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+func changedAttributes(from, to map[string]interface{}) []string {
+	var changed []string
+	for key, value := range to {
+		if old, ok := from[key]; !ok || !reflect.DeepEqual(old, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range from {
+		if _, ok := to[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// address returns the absolute address of a resource, e.g.
+// "module.dev.docker_container.workspace[0]".
+func address(module string, r *tfjson.StateResource) string {
+	var b strings.Builder
+	if module != "" {
+		_, _ = b.WriteString(module + ".")
+	}
+	if r.Mode == tfjson.DataResourceMode {
+		_, _ = b.WriteString("data.")
+	}
+	_, _ = b.WriteString(r.Type + "." + r.Name)
+	switch index := r.Index.(type) {
+	case nil:
+	case string:
+		_, _ = fmt.Fprintf(&b, "[%q]", index)
+	case float64:
+		_, _ = fmt.Fprintf(&b, "[%d]", int64(index))
+	default:
+		_, _ = fmt.Fprintf(&b, "[%v]", index)
+	}
+	return b.String()
+}
+
+// parentModule returns the address of the module that contains the module at
+// address. For "module.a.module.b" it returns "module.a".
+func parentModule(address string) string {
+	i := strings.LastIndex(address, ".module.")
+	if i < 0 {
+		return ""
+	}
+	return address[:i]
+}
+
+// providerName strips the configuration syntax of a provider address, turning
+// `module.a.provider["registry.terraform.io/coder/coder"]` into
+// "registry.terraform.io/coder/coder".
+func providerName(provider string) string {
+	if i := strings.Index(provider, "provider["); i >= 0 {
+		provider = provider[i+len("provider["):]
+	}
+	provider = strings.TrimSuffix(provider, "]")
+	return strings.Trim(provider, `"`)
+}
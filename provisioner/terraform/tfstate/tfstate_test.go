@@ -0,0 +1,162 @@
+package tfstate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform/tfstate"
+)
+
+const stateV1 = `{
+  "version": 4,
+  "terraform_version": "1.9.8",
+  "serial": 3,
+  "lineage": "abc",
+  "outputs": {},
+  "resources": [
+    {
+      "mode": "data",
+      "type": "coder_workspace",
+      "name": "me",
+      "provider": "provider[\"registry.terraform.io/coder/coder\"]",
+      "instances": [{"schema_version": 0, "attributes": {"name": "dev"}}]
+    },
+    {
+      "mode": "managed",
+      "type": "coder_agent",
+      "name": "main",
+      "provider": "provider[\"registry.terraform.io/coder/coder\"]",
+      "instances": [{"schema_version": 1, "attributes": {"arch": "amd64", "token": "secret"}}]
+    },
+    {
+      "module": "module.disk",
+      "mode": "managed",
+      "type": "docker_volume",
+      "name": "home",
+      "provider": "module.disk.provider[\"registry.terraform.io/kreuzwerker/docker\"]",
+      "instances": [
+        {"index_key": 0, "schema_version": 0, "attributes": {"size": 10}},
+        {"index_key": 1, "schema_version": 0, "attributes": {"size": 10}}
+      ]
+    }
+  ]
+}`
+
+const stateV2 = `{
+  "version": 4,
+  "terraform_version": "1.9.8",
+  "serial": 4,
+  "lineage": "abc",
+  "outputs": {},
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "coder_agent",
+      "name": "main",
+      "provider": "provider[\"registry.terraform.io/coder/coder\"]",
+      "instances": [{"schema_version": 1, "attributes": {"arch": "arm64", "token": "secret"}}]
+    },
+    {
+      "module": "module.disk",
+      "mode": "managed",
+      "type": "docker_volume",
+      "name": "home",
+      "provider": "module.disk.provider[\"registry.terraform.io/kreuzwerker/docker\"]",
+      "instances": [
+        {"index_key": 0, "schema_version": 0, "attributes": {"size": 10}}
+      ]
+    },
+    {
+      "module": "module.disk.module.backup",
+      "mode": "managed",
+      "type": "docker_volume",
+      "name": "backup",
+      "provider": "module.disk.provider[\"registry.terraform.io/kreuzwerker/docker\"]",
+      "instances": [
+        {"index_key": "daily", "schema_version": 0, "attributes": {"size": 5}}
+      ]
+    }
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("StateFile", func(t *testing.T) {
+		t.Parallel()
+
+		state, err := tfstate.Parse([]byte(stateV2))
+		require.NoError(t, err)
+		require.Equal(t, "1.9.8", state.TerraformVersion)
+
+		root := state.Values.RootModule
+		require.Len(t, root.Resources, 1)
+		require.Equal(t, "coder_agent.main", root.Resources[0].Address)
+		require.Equal(t, "registry.terraform.io/coder/coder", root.Resources[0].ProviderName)
+		require.Len(t, root.ChildModules, 1)
+		require.Equal(t, "module.disk", root.ChildModules[0].Address)
+		require.Len(t, root.ChildModules[0].ChildModules, 1)
+		require.Equal(t, "module.disk.module.backup", root.ChildModules[0].ChildModules[0].Address)
+
+		var addresses []string
+		for _, r := range tfstate.Resources(state) {
+			addresses = append(addresses, r.Address)
+		}
+		require.Equal(t, []string{
+			"coder_agent.main",
+			"module.disk.docker_volume.home[0]",
+			`module.disk.module.backup.docker_volume.backup["daily"]`,
+		}, addresses)
+	})
+
+	t.Run("ShowJSON", func(t *testing.T) {
+		t.Parallel()
+
+		state, err := tfstate.Parse([]byte(`{
+  "format_version": "1.0",
+  "terraform_version": "1.9.8",
+  "values": {
+    "root_module": {
+      "resources": [
+        {"address": "coder_agent.main", "mode": "managed", "type": "coder_agent", "name": "main", "values": {}}
+      ]
+    }
+  }
+}`))
+		require.NoError(t, err)
+		require.Len(t, tfstate.Resources(state), 1)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := tfstate.Parse(nil)
+		require.ErrorContains(t, err, "empty")
+		_, err = tfstate.Parse([]byte(`{"container": "abc"}`))
+		require.ErrorContains(t, err, "unsupported state version")
+	})
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	from, err := tfstate.Parse([]byte(stateV1))
+	require.NoError(t, err)
+	to, err := tfstate.Parse([]byte(stateV2))
+	require.NoError(t, err)
+
+	diff := tfstate.Diff(from, to)
+	require.Equal(t, []string{`module.disk.module.backup.docker_volume.backup["daily"]`}, diff.Added)
+	require.Equal(t, []string{"module.disk.docker_volume.home[1]"}, diff.Removed)
+	require.Equal(t, []tfstate.ResourceChange{{
+		Address:    "coder_agent.main",
+		Attributes: []string{"arch"},
+	}}, diff.Changed)
+
+	// Comparing with an empty state adds every resource.
+	diff = tfstate.Diff(nil, from)
+	require.Len(t, diff.Added, 3)
+	require.Empty(t, diff.Removed)
+	require.Empty(t, diff.Changed)
+}
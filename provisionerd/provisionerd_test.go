@@ -23,6 +23,7 @@ import (
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/onchainengineering/hmi-wirtual/provisionerd"
 	"github.com/onchainengineering/hmi-wirtual/provisionerd/proto"
+	"github.com/onchainengineering/hmi-wirtual/provisionerd/runner"
 	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
 	sdkproto "github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
 	"github.com/onchainengineering/hmi-wirtual/testutil"
@@ -444,6 +445,273 @@ func TestProvisionerd(t *testing.T) {
 		assert.True(t, didComplete.Load(), "should complete the job")
 	})
 
+	t.Run("WorkspaceBuildRetry", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			applies     atomic.Int32
+			didComplete atomic.Bool
+			retryLogged atomic.Bool
+			acq         = newAcquireOne(t, &proto.AcquiredJob{
+				JobId:       "test",
+				Provisioner: "someprovisioner",
+				TemplateSourceArchive: testutil.CreateTar(t, map[string]string{
+					"test.txt": "content",
+				}),
+				MaxRetries:     2,
+				RetryBackoffMs: 1,
+				Type: &proto.AcquiredJob_WorkspaceBuild_{
+					WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+						Metadata: &sdkproto.Metadata{},
+						State:    []byte("initial"),
+					},
+				},
+			})
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJobWithCancel: acq.acquireWithCancel,
+				updateJob: func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
+					for _, log := range update.Logs {
+						if log.Stage == "Retrying (attempt 2)" {
+							retryLogged.Store(true)
+						}
+					}
+					return &proto.UpdateJobResponse{}, nil
+				},
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					didComplete.Store(true)
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.LocalProvisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				plan: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.PlanRequest,
+					_ <-chan struct{},
+				) *sdkproto.PlanComplete {
+					return &sdkproto.PlanComplete{}
+				},
+				apply: func(
+					s *provisionersdk.Session,
+					_ *sdkproto.ApplyRequest,
+					_ <-chan struct{},
+				) *sdkproto.ApplyComplete {
+					if applies.Add(1) == 1 {
+						assert.Equal(t, []byte("initial"), s.Config.State)
+						return &sdkproto.ApplyComplete{
+							State:     []byte("partial"),
+							Error:     "rate limit exceeded",
+							Retryable: true,
+						}
+					}
+					// The retry must start from the state of the failed attempt.
+					assert.Equal(t, []byte("partial"), s.Config.State)
+					return &sdkproto.ApplyComplete{}
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(acq.complete, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		assert.EqualValues(t, 2, applies.Load(), "should apply twice")
+		assert.True(t, retryLogged.Load(), "should log the retry")
+		assert.True(t, didComplete.Load(), "should complete the job")
+	})
+
+	t.Run("WorkspaceBuildFatalNotRetried", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			applies atomic.Int32
+			didFail atomic.Bool
+			acq     = newAcquireOne(t, &proto.AcquiredJob{
+				JobId:       "test",
+				Provisioner: "someprovisioner",
+				TemplateSourceArchive: testutil.CreateTar(t, map[string]string{
+					"test.txt": "content",
+				}),
+				MaxRetries:     2,
+				RetryBackoffMs: 1,
+				Type: &proto.AcquiredJob_WorkspaceBuild_{
+					WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+						Metadata: &sdkproto.Metadata{},
+					},
+				},
+			})
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJobWithCancel: acq.acquireWithCancel,
+				updateJob:            noopUpdateJob,
+				failJob: func(ctx context.Context, job *proto.FailedJob) (*proto.Empty, error) {
+					assert.Equal(t, "invalid configuration", job.Error)
+					didFail.Store(true)
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.LocalProvisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				plan: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.PlanRequest,
+					_ <-chan struct{},
+				) *sdkproto.PlanComplete {
+					return &sdkproto.PlanComplete{}
+				},
+				apply: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.ApplyRequest,
+					_ <-chan struct{},
+				) *sdkproto.ApplyComplete {
+					applies.Add(1)
+					return &sdkproto.ApplyComplete{
+						Error: "invalid configuration",
+					}
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(acq.complete, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		assert.EqualValues(t, 1, applies.Load(), "should not retry")
+		assert.True(t, didFail.Load(), "should fail the job")
+	})
+
+	t.Run("WorkspaceBuildTimeout", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			failed = make(chan *proto.FailedJob, 1)
+			acq    = newAcquireOne(t, &proto.AcquiredJob{
+				JobId:       "test",
+				Provisioner: "someprovisioner",
+				TemplateSourceArchive: testutil.CreateTar(t, map[string]string{
+					"test.txt": "content",
+				}),
+				TimeoutMs: 100,
+				Type: &proto.AcquiredJob_WorkspaceBuild_{
+					WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+						Metadata: &sdkproto.Metadata{},
+					},
+				},
+			})
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJobWithCancel: acq.acquireWithCancel,
+				updateJob:            noopUpdateJob,
+				failJob: func(ctx context.Context, job *proto.FailedJob) (*proto.Empty, error) {
+					failed <- job
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.LocalProvisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				plan: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.PlanRequest,
+					canceledOrComplete <-chan struct{},
+				) *sdkproto.PlanComplete {
+					<-canceledOrComplete
+					return &sdkproto.PlanComplete{
+						Error: "canceled",
+					}
+				},
+				apply: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.ApplyRequest,
+					_ <-chan struct{},
+				) *sdkproto.ApplyComplete {
+					t.Error("timed out job should not apply")
+					return &sdkproto.ApplyComplete{}
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(acq.complete, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		job := testutil.RequireRecvCtx(testutil.Context(t, testutil.WaitShort), t, failed)
+		require.Equal(t, runner.JobTimeoutErrorCode, job.ErrorCode)
+		require.Contains(t, job.Error, "timed out")
+	})
+
+	t.Run("WorkspaceDriftCheck", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			completed = make(chan *proto.CompletedJob, 1)
+			acq       = newAcquireOne(t, &proto.AcquiredJob{
+				JobId:       "test",
+				Provisioner: "someprovisioner",
+				TemplateSourceArchive: testutil.CreateTar(t, map[string]string{
+					"test.txt": "content",
+				}),
+				Type: &proto.AcquiredJob_WorkspaceDriftCheck_{
+					WorkspaceDriftCheck: &proto.AcquiredJob_WorkspaceDriftCheck{
+						Metadata: &sdkproto.Metadata{},
+						State:    []byte("state"),
+					},
+				},
+			})
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJobWithCancel: acq.acquireWithCancel,
+				updateJob:            noopUpdateJob,
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					completed <- job
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.LocalProvisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				plan: func(
+					s *provisionersdk.Session,
+					r *sdkproto.PlanRequest,
+					_ <-chan struct{},
+				) *sdkproto.PlanComplete {
+					assert.True(t, r.RefreshOnly, "plan should be refresh-only")
+					assert.Equal(t, []byte("state"), s.Config.State)
+					return &sdkproto.PlanComplete{
+						Drift: []*sdkproto.ResourceDrift{{
+							Address: "null_resource.dev",
+							Action:  "update",
+						}},
+					}
+				},
+				apply: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.ApplyRequest,
+					_ <-chan struct{},
+				) *sdkproto.ApplyComplete {
+					t.Error("drift check should not apply")
+					return &sdkproto.ApplyComplete{}
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(acq.complete, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		job := testutil.RequireRecvCtx(testutil.Context(t, testutil.WaitShort), t, completed)
+		drift := job.GetWorkspaceDriftCheck().GetDrift()
+		require.Len(t, drift, 1)
+		require.Equal(t, "null_resource.dev", drift[0].Address)
+	})
+
 	t.Run("WorkspaceBuildQuotaExceeded", func(t *testing.T) {
 		t.Parallel()
 		done := make(chan struct{})
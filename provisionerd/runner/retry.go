@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onchainengineering/hmi-wirtual/provisionerd/proto"
+	sdkproto "github.com/onchainengineering/hmi-wirtual/provisionersdk/proto"
+)
+
+// maxRetryBackoff caps the exponential backoff between retries.
+const maxRetryBackoff = 10 * time.Minute
+
+// retryBackoff returns the delay before the retry that follows the failed
+// attempt. The delay doubles with every attempt.
+func (r *Runner) retryBackoff(attempt int) time.Duration {
+	backoff := time.Duration(r.job.GetRetryBackoffMs()) * time.Millisecond
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// waitRetry waits for the backoff to pass. It returns false if the job is
+// canceled in the meantime.
+func (r *Runner) waitRetry(ctx context.Context, backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.notCanceled.Done():
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// resetSession replaces the session with the provisioner, so that a retry
+// starts from a clean working directory with the given config.
+func (r *Runner) resetSession(ctx context.Context, config *sdkproto.Config) *proto.FailedJob {
+	_ = r.session.Close()
+	session, err := r.provisioner.Session(ctx)
+	if err != nil {
+		return r.failedWorkspaceBuildf("open session: %s", err)
+	}
+	r.session = session
+	return r.configure(config)
+}
+
+// attemptStage tells the logs of retries apart from the first attempt.
+func attemptStage(stage string, attempt int) string {
+	return fmt.Sprintf("%s (attempt %d)", stage, attempt)
+}
@@ -32,6 +32,8 @@ const (
 
 	RequiredTemplateVariablesErrorCode = "REQUIRED_TEMPLATE_VARIABLES"
 	requiredTemplateVariablesErrorText = "required template variables"
+
+	JobTimeoutErrorCode = "JOB_TIMEOUT"
 )
 
 var errorCodes = map[string]string{
@@ -56,6 +58,12 @@ type Runner struct {
 
 	// session is the provisioning session with the (possibly remote) provisioner
 	session sdkproto.DRPCProvisioner_SessionClient
+	// engine is reported by the provisioner when a plan or apply completes,
+	// and is attached to the terminal message of the job.
+	engine *sdkproto.Engine
+	// timedOut is set when the job is canceled for running longer than the
+	// timeout of its template.
+	timedOut atomic.Bool
 	// closed when the Runner is finished sending any updates/failed/complete.
 	done chan struct{}
 	// active as long as we are not canceled
@@ -199,6 +207,7 @@ func (r *Runner) Run() {
 
 	go r.doCleanFinish(ctx)
 	go r.heartbeatRoutine(ctx)
+	go r.timeoutRoutine(ctx)
 	for r.failedJob == nil && r.completedJob == nil {
 		r.cond.Wait()
 	}
@@ -370,9 +379,18 @@ func (r *Runner) doCleanFinish(ctx context.Context) {
 		defer span.End()
 
 		if failedJob != nil {
+			if r.timedOut.Load() {
+				// The failure was caused by the cancellation.
+				failedJob.Error = r.timeoutError()
+				failedJob.ErrorCode = JobTimeoutErrorCode
+			}
+			failedJob.Engine = r.engine
 			r.setFail(failedJob)
 			return
 		}
+		if completedJob != nil {
+			completedJob.Engine = r.engine
+		}
 		r.setComplete(completedJob)
 	}()
 
@@ -382,7 +400,10 @@ func (r *Runner) doCleanFinish(ctx context.Context) {
 		failedJob = r.failedJobf("open session: %s", err)
 		return
 	}
-	defer r.session.Close()
+	defer func() {
+		// Retries replace the session, so the latest one is closed.
+		_ = r.session.Close()
+	}()
 
 	defer func() {
 		ctx, span := r.startTrace(ctx, tracing.FuncName())
@@ -434,6 +455,14 @@ func (r *Runner) do(ctx context.Context) (*proto.CompletedJob, *proto.FailedJob)
 			slog.F("variable_values", redactVariableValues(jobType.WorkspaceBuild.VariableValues)),
 		)
 		return r.runWorkspaceBuild(ctx)
+	case *proto.AcquiredJob_WorkspaceDriftCheck_:
+		r.logger.Debug(context.Background(), "acquired job is workspace drift check",
+			slog.F("workspace_name", jobType.WorkspaceDriftCheck.Metadata.GetWorkspaceName()),
+			slog.F("state_length", len(jobType.WorkspaceDriftCheck.State)),
+			slog.F("rich_parameter_values", jobType.WorkspaceDriftCheck.RichParameterValues),
+			slog.F("variable_values", redactVariableValues(jobType.WorkspaceDriftCheck.VariableValues)),
+		)
+		return r.runWorkspaceDriftCheck(ctx)
 	default:
 		return nil, r.failedJobf("unknown job type %q; ensure your provisioner daemon is up-to-date",
 			reflect.TypeOf(r.job.Type).String())
@@ -500,6 +529,57 @@ func (r *Runner) heartbeatRoutine(ctx context.Context) {
 	}
 }
 
+// timeoutRoutine gracefully cancels the job once it runs longer than the
+// timeout of its template, and fails it if the cancellation doesn't finish in
+// time.
+func (r *Runner) timeoutRoutine(ctx context.Context) {
+	timeout := time.Duration(r.job.GetTimeoutMs()) * time.Millisecond
+	if timeout <= 0 {
+		return
+	}
+	ctx, span := r.startTrace(ctx, tracing.FuncName())
+	defer span.End()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-r.notCanceled.Done():
+		return
+	}
+
+	r.logger.Warn(ctx, "job timed out; attempting graceful cancellation", slog.F("timeout", timeout))
+	r.timedOut.Store(true)
+	r.queueLog(ctx, &proto.Log{
+		Source:    proto.LogSource_PROVISIONER_DAEMON,
+		Level:     sdkproto.LogLevel_ERROR,
+		Stage:     "Timed out",
+		Output:    fmt.Sprintf("The job ran longer than the timeout of %s and is being canceled.", timeout),
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	r.flushQueuedLogs(ctx)
+	r.Cancel()
+
+	forceTimer := time.NewTimer(r.forceCancelInterval)
+	defer forceTimer.Stop()
+	select {
+	case <-forceTimer.C:
+		r.logger.Debug(ctx, "cancel after timeout timed out")
+		failedJob := r.failedJobf("%s", r.timeoutError())
+		failedJob.ErrorCode = JobTimeoutErrorCode
+		err := r.Fail(ctx, failedJob)
+		if err != nil {
+			r.logger.Warn(ctx, "failed to call FailJob", slog.Error(err))
+		}
+	case <-r.Done():
+	case <-r.notStopped.Done():
+	}
+}
+
+func (r *Runner) timeoutError() string {
+	return fmt.Sprintf("job timed out after %s", time.Duration(r.job.GetTimeoutMs())*time.Millisecond)
+}
+
 func (r *Runner) runTemplateImport(ctx context.Context) (*proto.CompletedJob, *proto.FailedJob) {
 	ctx, span := r.startTrace(ctx, tracing.FuncName())
 	defer span.End()
@@ -717,6 +797,9 @@ func (r *Runner) runTemplateImportProvisionWithRichParameters(
 			})
 		case *sdkproto.Response_Plan:
 			c := msgType.Plan
+			if c.Engine != nil {
+				r.engine = c.Engine
+			}
 			if c.Error != "" {
 				r.logger.Info(context.Background(), "dry-run provision failure",
 					slog.F("error", c.Error),
@@ -808,7 +891,10 @@ func (r *Runner) buildWorkspace(ctx context.Context, stage string, req *sdkproto
 ) {
 	// use the notStopped so that if we attempt to gracefully cancel, the stream
 	// will still be available for us to send the cancel to the provisioner
-	err := r.session.Send(req)
+	// Retries replace the session, so the cancel goroutine holds on to the
+	// session of this request.
+	session := r.session
+	err := session.Send(req)
 	if err != nil {
 		return nil, r.failedWorkspaceBuildf("start provision: %s", err)
 	}
@@ -821,7 +907,7 @@ func (r *Runner) buildWorkspace(ctx context.Context, stage string, req *sdkproto
 		case <-r.notStopped.Done():
 			return
 		case <-r.notCanceled.Done():
-			_ = r.session.Send(&sdkproto.Request{
+			_ = session.Send(&sdkproto.Request{
 				Type: &sdkproto.Request_Cancel{
 					Cancel: &sdkproto.CancelRequest{},
 				},
@@ -830,7 +916,7 @@ func (r *Runner) buildWorkspace(ctx context.Context, stage string, req *sdkproto
 	}()
 
 	for {
-		msg, err := r.session.Recv()
+		msg, err := session.Recv()
 		if err != nil {
 			return nil, r.failedWorkspaceBuildf("recv workspace provision: %s", err)
 		}
@@ -928,16 +1014,86 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 		applyStage = "Destroying workspace"
 	}
 
-	failedJob := r.configure(&sdkproto.Config{
-		TemplateSourceArchive: r.job.GetTemplateSourceArchive(),
-		State:                 r.job.GetWorkspaceBuild().State,
-		ProvisionerLogLevel:   r.job.GetWorkspaceBuild().LogLevel,
-	})
+	state := r.job.GetWorkspaceBuild().State
+	failedJob := r.configure(r.workspaceBuildConfig(state))
 	if failedJob != nil {
 		return nil, failedJob
 	}
 
-	resp, failed := r.buildWorkspace(ctx, "Planning infrastructure", &sdkproto.Request{
+	// The timings of all attempts are reported, so that retries show up in
+	// the timeline of the build.
+	var timings []*sdkproto.Timing
+	for attempt := 1; ; attempt++ {
+		attemptCtx, attemptSpan := r.startTrace(ctx, "workspaceBuildAttempt", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+		))
+		completed, failed, retryable := r.runWorkspaceBuildAttempt(attemptCtx, attempt, applyStage, commitQuota)
+		attemptSpan.End()
+		if completed != nil {
+			build := completed.GetWorkspaceBuild()
+			build.Timings = append(timings, build.Timings...)
+			return completed, nil
+		}
+		if build := failed.GetWorkspaceBuild(); build != nil {
+			timings = append(timings, build.Timings...)
+			build.Timings = timings
+			// A failed apply may have changed the infrastructure, so
+			// the next attempt continues from its state.
+			if build.State != nil {
+				state = build.State
+			} else if attempt > 1 {
+				build.State = state
+			}
+		}
+		if !retryable || attempt > int(r.job.GetMaxRetries()) {
+			return nil, failed
+		}
+
+		backoff := r.retryBackoff(attempt)
+		r.logger.Warn(ctx, "workspace build attempt failed with a retryable error",
+			slog.F("attempt", attempt),
+			slog.F("backoff", backoff),
+			slog.F("error", failed.Error),
+		)
+		r.queueLog(ctx, &proto.Log{
+			Source:    proto.LogSource_PROVISIONER_DAEMON,
+			Level:     sdkproto.LogLevel_WARN,
+			Stage:     attemptStage("Retrying", attempt+1),
+			Output:    fmt.Sprintf("Attempt %d of %d failed with a retryable error. Retrying in %s.", attempt, r.job.GetMaxRetries()+1, backoff),
+			CreatedAt: time.Now().UnixMilli(),
+		})
+		r.flushQueuedLogs(ctx)
+		if !r.waitRetry(ctx, backoff) {
+			return nil, failed
+		}
+		failed = r.resetSession(ctx, r.workspaceBuildConfig(state))
+		if failed != nil {
+			return nil, failed
+		}
+	}
+}
+
+func (r *Runner) workspaceBuildConfig(state []byte) *sdkproto.Config {
+	return &sdkproto.Config{
+		TemplateSourceArchive: r.job.GetTemplateSourceArchive(),
+		State:                 state,
+		ProvisionerLogLevel:   r.job.GetWorkspaceBuild().LogLevel,
+		RetryableErrors:       r.job.GetRetryableErrors(),
+	}
+}
+
+// runWorkspaceBuildAttempt plans and applies a workspace build once. It
+// returns whether the failure of the attempt is worth retrying.
+func (r *Runner) runWorkspaceBuildAttempt(ctx context.Context, attempt int, applyStage string, commitQuota bool) (
+	*proto.CompletedJob, *proto.FailedJob, bool,
+) {
+	planStage := "Planning infrastructure"
+	if attempt > 1 {
+		planStage = attemptStage(planStage, attempt)
+		applyStage = attemptStage(applyStage, attempt)
+	}
+
+	resp, failed := r.buildWorkspace(ctx, planStage, &sdkproto.Request{
 		Type: &sdkproto.Request_Plan{
 			Plan: &sdkproto.PlanRequest{
 				Metadata:              r.job.GetWorkspaceBuild().Metadata,
@@ -948,24 +1104,30 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 		},
 	})
 	if failed != nil {
-		return nil, failed
+		return nil, failed, false
 	}
 	planComplete := resp.GetPlan()
 	if planComplete == nil {
-		return nil, r.failedWorkspaceBuildf("invalid message type %T received from provisioner", resp.Type)
+		return nil, r.failedWorkspaceBuildf("invalid message type %T received from provisioner", resp.Type), false
+	}
+	if planComplete.Engine != nil {
+		r.engine = planComplete.Engine
 	}
 	if planComplete.Error != "" {
 		r.logger.Warn(context.Background(), "plan request failed",
 			slog.F("error", planComplete.Error),
+			slog.F("retryable", planComplete.Retryable),
 		)
 
 		return nil, &proto.FailedJob{
 			JobId: r.job.JobId,
 			Error: planComplete.Error,
 			Type: &proto.FailedJob_WorkspaceBuild_{
-				WorkspaceBuild: &proto.FailedJob_WorkspaceBuild{},
+				WorkspaceBuild: &proto.FailedJob_WorkspaceBuild{
+					Timings: planComplete.Timings,
+				},
 			},
-		}
+		}, planComplete.Retryable
 	}
 
 	r.logger.Info(context.Background(), "plan request successful",
@@ -977,7 +1139,7 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 		failed = r.commitQuota(ctx, planComplete.Resources)
 		r.flushQueuedLogs(ctx)
 		if failed != nil {
-			return nil, failed
+			return nil, failed, false
 		}
 	}
 
@@ -996,11 +1158,15 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 		},
 	})
 	if failed != nil {
-		return nil, failed
+		return nil, failed, false
 	}
 	applyComplete := resp.GetApply()
 	if applyComplete == nil {
-		return nil, r.failedWorkspaceBuildf("invalid message type %T received from provisioner", resp.Type)
+		return nil, r.failedWorkspaceBuildf("invalid message type %T received from provisioner", resp.Type), false
+	}
+
+	if applyComplete.Engine != nil {
+		r.engine = applyComplete.Engine
 	}
 
 	// Prepend the plan timings (since they occurred first).
@@ -1009,6 +1175,7 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 	if applyComplete.Error != "" {
 		r.logger.Warn(context.Background(), "apply failed; updating state",
 			slog.F("error", applyComplete.Error),
+			slog.F("retryable", applyComplete.Retryable),
 			slog.F("state_len", len(applyComplete.State)),
 		)
 
@@ -1021,7 +1188,7 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 					Timings: applyComplete.Timings,
 				},
 			},
-		}
+		}, applyComplete.Retryable
 	}
 
 	r.logger.Info(context.Background(), "apply successful",
@@ -1044,6 +1211,70 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 				Modules: planComplete.Modules,
 			},
 		},
+	}, nil, false
+}
+
+// runWorkspaceDriftCheck runs a refresh-only plan with the state of a
+// workspace build to find the resources that were changed outside of builds.
+// Nothing is applied.
+func (r *Runner) runWorkspaceDriftCheck(ctx context.Context) (*proto.CompletedJob, *proto.FailedJob) {
+	ctx, span := r.startTrace(ctx, tracing.FuncName())
+	defer span.End()
+
+	job := r.job.GetWorkspaceDriftCheck()
+	failedJob := r.configure(&sdkproto.Config{
+		TemplateSourceArchive: r.job.GetTemplateSourceArchive(),
+		State:                 job.State,
+	})
+	if failedJob != nil {
+		return nil, failedJob
+	}
+
+	resp, failed := r.buildWorkspace(ctx, "Detecting drift", &sdkproto.Request{
+		Type: &sdkproto.Request_Plan{
+			Plan: &sdkproto.PlanRequest{
+				Metadata:              job.Metadata,
+				RichParameterValues:   job.RichParameterValues,
+				VariableValues:        job.VariableValues,
+				ExternalAuthProviders: job.ExternalAuthProviders,
+				RefreshOnly:           true,
+			},
+		},
+	})
+	if failed != nil {
+		// The failure must not be mistaken for the failure of a build.
+		failed.Type = &proto.FailedJob_WorkspaceDriftCheck_{
+			WorkspaceDriftCheck: &proto.FailedJob_WorkspaceDriftCheck{},
+		}
+		return nil, failed
+	}
+	planComplete := resp.GetPlan()
+	if planComplete == nil {
+		return nil, r.failedWorkspaceDriftCheckf("invalid message type %T received from provisioner", resp.Type)
+	}
+	if planComplete.Engine != nil {
+		r.engine = planComplete.Engine
+	}
+	if planComplete.Error != "" {
+		r.logger.Warn(context.Background(), "refresh-only plan request failed",
+			slog.F("error", planComplete.Error),
+		)
+		return nil, r.failedWorkspaceDriftCheckf("%s", planComplete.Error)
+	}
+
+	r.logger.Info(context.Background(), "drift check successful",
+		slog.F("drifted_resource_count", len(planComplete.Drift)),
+	)
+	r.flushQueuedLogs(ctx)
+
+	return &proto.CompletedJob{
+		JobId: r.job.JobId,
+		Type: &proto.CompletedJob_WorkspaceDriftCheck_{
+			WorkspaceDriftCheck: &proto.CompletedJob_WorkspaceDriftCheck{
+				Drift:   planComplete.Drift,
+				Timings: planComplete.Timings,
+			},
+		},
 	}, nil
 }
 
@@ -1066,6 +1297,14 @@ func (r *Runner) failedWorkspaceBuildf(format string, args ...interface{}) *prot
 	return failedJob
 }
 
+func (r *Runner) failedWorkspaceDriftCheckf(format string, args ...interface{}) *proto.FailedJob {
+	failedJob := r.failedJobf(format, args...)
+	failedJob.Type = &proto.FailedJob_WorkspaceDriftCheck_{
+		WorkspaceDriftCheck: &proto.FailedJob_WorkspaceDriftCheck{},
+	}
+	return failedJob
+}
+
 func (r *Runner) failedJobf(format string, args ...interface{}) *proto.FailedJob {
 	message := fmt.Sprintf(format, args...)
 	var code string
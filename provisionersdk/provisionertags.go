@@ -5,6 +5,9 @@ import "github.com/google/uuid"
 const (
 	TagScope = "scope"
 	TagOwner = "owner"
+	// TagEngine is set by provisioner daemons that run an infrastructure as
+	// code engine other than Terraform, so templates can target them.
+	TagEngine = "engine"
 
 	ScopeUser         = "user"
 	ScopeOrganization = "organization"
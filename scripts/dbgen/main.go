@@ -639,6 +639,8 @@ func nameFromSnakeCase(s string) string {
 			ret += "Index"
 		case "api":
 			ret += "API"
+		case "dns":
+			ret += "DNS"
 		case "uuid":
 			ret += "UUID"
 		case "gitsshkeys":
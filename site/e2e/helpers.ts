@@ -567,6 +567,8 @@ const createTemplateVersionTar = async (
 						id: randomUUID(),
 						metadata: [],
 						extraEnvs: [],
+						dnsRecords: [],
+						services: [],
 						scripts: [],
 						motdFile: "",
 						name: "dev",
@@ -622,6 +624,7 @@ const createTemplateVersionTar = async (
 			parameters: [],
 			externalAuthProviders: [],
 			timings: [],
+			retryable: false,
 			...response.apply,
 		} as ApplyComplete;
 		response.apply.resources = response.apply.resources?.map(fillResource);
@@ -639,6 +642,8 @@ const createTemplateVersionTar = async (
 			externalAuthProviders: [],
 			timings: [],
 			modules: [],
+			drift: [],
+			retryable: false,
 			...response.plan,
 		} as PlanComplete;
 		response.plan.resources = response.plan.resources?.map(fillResource);
@@ -22,6 +22,13 @@ export enum AppSharingLevel {
 	UNRECOGNIZED = -1,
 }
 
+export enum ServiceRestartPolicy {
+	ALWAYS = 0,
+	ON_FAILURE = 1,
+	NEVER = 2,
+	UNRECOGNIZED = -1,
+}
+
 /** WorkspaceTransition is the desired outcome of a build */
 export enum WorkspaceTransition {
 	START = 0,
@@ -137,6 +144,8 @@ export interface Agent {
 	scripts: Script[];
 	extraEnvs: Env[];
 	order: number;
+	dnsRecords: DNSRecord[];
+	services: Service[];
 }
 
 export interface Agent_Metadata {
@@ -166,6 +175,17 @@ export interface Env {
 	value: string;
 }
 
+/**
+ * DNSRecord is a name served for the agent by the VPN resolver. The name is
+ * relative to the workspace, so "api" resolves as api.<workspace>.coder. A
+ * leading "*." label makes it a wildcard. If port is set, the name resolves to
+ * an address of its own where port 80 is forwarded to that port of the agent.
+ */
+export interface DNSRecord {
+	name: string;
+	port: number;
+}
+
 /** Script represents a script to be run on the workspace. */
 export interface Script {
 	displayName: string;
@@ -177,6 +197,36 @@ export interface Script {
 	runOnStop: boolean;
 	timeoutSeconds: number;
 	logPath: string;
+	/**
+	 * address is the Terraform address of the coder_script resource. It is
+	 * unique per template and referenced by depends_on of other scripts.
+	 */
+	address: string;
+	dependsOn: string[];
+	ready: ScriptReadiness | undefined;
+}
+
+/**
+ * ScriptReadiness is a condition that must hold before the dependents of a
+ * script are started. All conditions that are set must hold.
+ */
+export interface ScriptReadiness {
+	port: number;
+	file: string;
+	timeoutSeconds: number;
+}
+
+/** Service represents a long-running process supervised by the agent. */
+export interface Service {
+	name: string;
+	displayName: string;
+	command: string;
+	dir: string;
+	env: Env[];
+	restartPolicy: ServiceRestartPolicy;
+	restartBackoffSeconds: number;
+	maxRestartBackoffSeconds: number;
+	healthcheck: Healthcheck | undefined;
 }
 
 /** App represents a dev-accessible application on the workspace. */
@@ -203,6 +253,26 @@ export interface Healthcheck {
 	url: string;
 	interval: number;
 	threshold: number;
+	/** tcp is a host:port that must accept connections, instead of url. */
+	tcp: string;
+	/**
+	 * command is run in the workspace instead of requesting url, and must
+	 * exit with code zero.
+	 */
+	command: string;
+	/**
+	 * expected_statuses are the HTTP status codes that count as healthy. Any
+	 * status below 500 is healthy when empty.
+	 */
+	expectedStatuses: number[];
+	/** body_regex must match the HTTP response body. */
+	bodyRegex: string;
+	headers: { [key: string]: string };
+}
+
+export interface Healthcheck_HeadersEntry {
+	key: string;
+	value: string;
 }
 
 /** Resource represents created infrastructure. */
@@ -260,6 +330,11 @@ export interface Config {
 	/** state is the provisioner state (if any) */
 	state: Uint8Array;
 	provisionerLogLevel: string;
+	/**
+	 * retryable_errors are regular expressions that match the errors of
+	 * failed plans and applies that are worth running again.
+	 */
+	retryableErrors: string[];
 }
 
 /** ParseRequest consumes source-code to produce inputs. */
@@ -284,6 +359,43 @@ export interface PlanRequest {
 	richParameterValues: RichParameterValue[];
 	variableValues: VariableValue[];
 	externalAuthProviders: ExternalAuthProvider[];
+	/**
+	 * refresh_only plans without proposing any changes. The plan reports the
+	 * resources that were changed outside of the provisioner instead.
+	 */
+	refreshOnly: boolean;
+}
+
+/**
+ * Engine is the infrastructure as code tool that a provisioner runs, e.g.
+ * terraform or opentofu.
+ */
+export interface Engine {
+	name: string;
+	version: string;
+}
+
+/** ResourceDrift is a resource that was changed outside of the provisioner. */
+export interface ResourceDrift {
+	address: string;
+	type: string;
+	/**
+	 * action is "update" when the resource was modified, or "delete" when it
+	 * no longer exists.
+	 */
+	action: string;
+	attributes: ResourceDrift_Attribute[];
+}
+
+export interface ResourceDrift_Attribute {
+	name: string;
+	/**
+	 * before and after are JSON encoded. They are empty when the
+	 * attribute is sensitive.
+	 */
+	before: string;
+	after: string;
+	sensitive: boolean;
 }
 
 /** PlanComplete indicates a request to plan completed. */
@@ -294,6 +406,13 @@ export interface PlanComplete {
 	externalAuthProviders: ExternalAuthProviderResource[];
 	timings: Timing[];
 	modules: Module[];
+	engine: Engine | undefined;
+	drift: ResourceDrift[];
+	/**
+	 * retryable is set when the error is transient, e.g. a rate limit of a
+	 * cloud provider, and the plan is likely to succeed when run again.
+	 */
+	retryable: boolean;
 }
 
 /**
@@ -312,6 +431,12 @@ export interface ApplyComplete {
 	parameters: RichParameter[];
 	externalAuthProviders: ExternalAuthProviderResource[];
 	timings: Timing[];
+	/**
+	 * retryable is set when the error is transient and the apply is likely
+	 * to succeed when run again.
+	 */
+	retryable: boolean;
+	engine: Engine | undefined;
 }
 
 export interface Timing {
@@ -599,6 +724,12 @@ export const Agent = {
 		if (message.order !== 0) {
 			writer.uint32(184).int64(message.order);
 		}
+		for (const v of message.dnsRecords) {
+			DNSRecord.encode(v!, writer.uint32(194).fork()).ldelim();
+		}
+		for (const v of message.services) {
+			Service.encode(v!, writer.uint32(202).fork()).ldelim();
+		}
 		return writer;
 	},
 };
@@ -681,6 +812,21 @@ export const Env = {
 	},
 };
 
+export const DNSRecord = {
+	encode(
+		message: DNSRecord,
+		writer: _m0.Writer = _m0.Writer.create(),
+	): _m0.Writer {
+		if (message.name !== "") {
+			writer.uint32(10).string(message.name);
+		}
+		if (message.port !== 0) {
+			writer.uint32(16).int32(message.port);
+		}
+		return writer;
+	},
+};
+
 export const Script = {
 	encode(
 		message: Script,
@@ -713,6 +859,72 @@ export const Script = {
 		if (message.logPath !== "") {
 			writer.uint32(74).string(message.logPath);
 		}
+		if (message.address !== "") {
+			writer.uint32(82).string(message.address);
+		}
+		for (const v of message.dependsOn) {
+			writer.uint32(90).string(v!);
+		}
+		if (message.ready !== undefined) {
+			ScriptReadiness.encode(message.ready, writer.uint32(98).fork()).ldelim();
+		}
+		return writer;
+	},
+};
+
+export const ScriptReadiness = {
+	encode(
+		message: ScriptReadiness,
+		writer: _m0.Writer = _m0.Writer.create(),
+	): _m0.Writer {
+		if (message.port !== 0) {
+			writer.uint32(8).int32(message.port);
+		}
+		if (message.file !== "") {
+			writer.uint32(18).string(message.file);
+		}
+		if (message.timeoutSeconds !== 0) {
+			writer.uint32(24).int32(message.timeoutSeconds);
+		}
+		return writer;
+	},
+};
+
+export const Service = {
+	encode(
+		message: Service,
+		writer: _m0.Writer = _m0.Writer.create(),
+	): _m0.Writer {
+		if (message.name !== "") {
+			writer.uint32(10).string(message.name);
+		}
+		if (message.displayName !== "") {
+			writer.uint32(18).string(message.displayName);
+		}
+		if (message.command !== "") {
+			writer.uint32(26).string(message.command);
+		}
+		if (message.dir !== "") {
+			writer.uint32(34).string(message.dir);
+		}
+		for (const v of message.env) {
+			Env.encode(v!, writer.uint32(42).fork()).ldelim();
+		}
+		if (message.restartPolicy !== 0) {
+			writer.uint32(48).int32(message.restartPolicy);
+		}
+		if (message.restartBackoffSeconds !== 0) {
+			writer.uint32(56).int32(message.restartBackoffSeconds);
+		}
+		if (message.maxRestartBackoffSeconds !== 0) {
+			writer.uint32(64).int32(message.maxRestartBackoffSeconds);
+		}
+		if (message.healthcheck !== undefined) {
+			Healthcheck.encode(
+				message.healthcheck,
+				writer.uint32(74).fork(),
+			).ldelim();
+		}
 		return writer;
 	},
 };
@@ -773,6 +985,41 @@ export const Healthcheck = {
 		if (message.threshold !== 0) {
 			writer.uint32(24).int32(message.threshold);
 		}
+		if (message.tcp !== "") {
+			writer.uint32(34).string(message.tcp);
+		}
+		if (message.command !== "") {
+			writer.uint32(42).string(message.command);
+		}
+		writer.uint32(50).fork();
+		for (const v of message.expectedStatuses) {
+			writer.int32(v);
+		}
+		writer.ldelim();
+		if (message.bodyRegex !== "") {
+			writer.uint32(58).string(message.bodyRegex);
+		}
+		Object.entries(message.headers).forEach(([key, value]) => {
+			Healthcheck_HeadersEntry.encode(
+				{ key: key as any, value },
+				writer.uint32(66).fork(),
+			).ldelim();
+		});
+		return writer;
+	},
+};
+
+export const Healthcheck_HeadersEntry = {
+	encode(
+		message: Healthcheck_HeadersEntry,
+		writer: _m0.Writer = _m0.Writer.create(),
+	): _m0.Writer {
+		if (message.key !== "") {
+			writer.uint32(10).string(message.key);
+		}
+		if (message.value !== "") {
+			writer.uint32(18).string(message.value);
+		}
 		return writer;
 	},
 };
@@ -929,6 +1176,9 @@ export const Config = {
 		if (message.provisionerLogLevel !== "") {
 			writer.uint32(26).string(message.provisionerLogLevel);
 		}
+		for (const v of message.retryableErrors) {
+			writer.uint32(34).string(v!);
+		}
 		return writer;
 	},
 };
@@ -998,6 +1248,66 @@ export const PlanRequest = {
 		for (const v of message.externalAuthProviders) {
 			ExternalAuthProvider.encode(v!, writer.uint32(34).fork()).ldelim();
 		}
+		if (message.refreshOnly === true) {
+			writer.uint32(40).bool(message.refreshOnly);
+		}
+		return writer;
+	},
+};
+
+export const Engine = {
+	encode(
+		message: Engine,
+		writer: _m0.Writer = _m0.Writer.create(),
+	): _m0.Writer {
+		if (message.name !== "") {
+			writer.uint32(10).string(message.name);
+		}
+		if (message.version !== "") {
+			writer.uint32(18).string(message.version);
+		}
+		return writer;
+	},
+};
+
+export const ResourceDrift = {
+	encode(
+		message: ResourceDrift,
+		writer: _m0.Writer = _m0.Writer.create(),
+	): _m0.Writer {
+		if (message.address !== "") {
+			writer.uint32(10).string(message.address);
+		}
+		if (message.type !== "") {
+			writer.uint32(18).string(message.type);
+		}
+		if (message.action !== "") {
+			writer.uint32(26).string(message.action);
+		}
+		for (const v of message.attributes) {
+			ResourceDrift_Attribute.encode(v!, writer.uint32(34).fork()).ldelim();
+		}
+		return writer;
+	},
+};
+
+export const ResourceDrift_Attribute = {
+	encode(
+		message: ResourceDrift_Attribute,
+		writer: _m0.Writer = _m0.Writer.create(),
+	): _m0.Writer {
+		if (message.name !== "") {
+			writer.uint32(10).string(message.name);
+		}
+		if (message.before !== "") {
+			writer.uint32(18).string(message.before);
+		}
+		if (message.after !== "") {
+			writer.uint32(26).string(message.after);
+		}
+		if (message.sensitive === true) {
+			writer.uint32(32).bool(message.sensitive);
+		}
 		return writer;
 	},
 };
@@ -1028,6 +1338,15 @@ export const PlanComplete = {
 		for (const v of message.modules) {
 			Module.encode(v!, writer.uint32(58).fork()).ldelim();
 		}
+		if (message.engine !== undefined) {
+			Engine.encode(message.engine, writer.uint32(66).fork()).ldelim();
+		}
+		for (const v of message.drift) {
+			ResourceDrift.encode(v!, writer.uint32(74).fork()).ldelim();
+		}
+		if (message.retryable === true) {
+			writer.uint32(80).bool(message.retryable);
+		}
 		return writer;
 	},
 };
@@ -1070,6 +1389,12 @@ export const ApplyComplete = {
 		for (const v of message.timings) {
 			Timing.encode(v!, writer.uint32(50).fork()).ldelim();
 		}
+		if (message.retryable === true) {
+			writer.uint32(56).bool(message.retryable);
+		}
+		if (message.engine !== undefined) {
+			Engine.encode(message.engine, writer.uint32(66).fork()).ldelim();
+		}
 		return writer;
 	},
 };
@@ -332,7 +332,7 @@ type RestartWorkspaceParameters = Readonly<{
 
 export type DeleteWorkspaceOptions = Pick<
 	TypesGen.CreateWorkspaceBuildRequest,
-	"log_level" | "orphan"
+	"log_level" | "orphan" | "priority"
 >;
 
 export type DeploymentConfig = Readonly<{
@@ -985,6 +985,46 @@ class ApiMethods {
 		return response.data;
 	};
 
+	/**
+	 * @returns null if the template isn't synced from git
+	 */
+	getTemplateGitSource = async (
+		templateId: string,
+	): Promise<TypesGen.TemplateGitSource | null> => {
+		try {
+			const response = await this.axios.get<TypesGen.TemplateGitSource>(
+				`/api/v2/templates/${templateId}/git-source`,
+			);
+
+			return response.data;
+		} catch (error) {
+			if (isAxiosError(error) && error.response?.status === 404) {
+				return null;
+			}
+			throw error;
+		}
+	};
+
+	updateTemplateGitSource = async (
+		templateId: string,
+		data: TypesGen.UpdateTemplateGitSourceRequest,
+	): Promise<TypesGen.TemplateGitSource> => {
+		const response = await this.axios.put<TypesGen.TemplateGitSource>(
+			`/api/v2/templates/${templateId}/git-source`,
+			data,
+		);
+
+		return response.data;
+	};
+
+	deleteTemplateGitSource = async (templateId: string): Promise<void> => {
+		await this.axios.delete(`/api/v2/templates/${templateId}/git-source`);
+	};
+
+	syncTemplateGitSource = async (templateId: string): Promise<void> => {
+		await this.axios.post(`/api/v2/templates/${templateId}/git-source/sync`);
+	};
+
 	getWorkspace = async (
 		workspaceId: string,
 		params?: TypesGen.WorkspaceOptions,
@@ -1076,22 +1116,26 @@ class ApiMethods {
 		templateVersionId: string,
 		logLevel?: TypesGen.ProvisionerLogLevel,
 		buildParameters?: TypesGen.WorkspaceBuildParameter[],
+		priority?: TypesGen.ProvisionerJobPriority,
 	) => {
 		return this.postWorkspaceBuild(workspaceId, {
 			transition: "start",
 			template_version_id: templateVersionId,
 			log_level: logLevel,
 			rich_parameter_values: buildParameters,
+			priority,
 		});
 	};
 
 	stopWorkspace = (
 		workspaceId: string,
 		logLevel?: TypesGen.ProvisionerLogLevel,
+		priority?: TypesGen.ProvisionerJobPriority,
 	) => {
 		return this.postWorkspaceBuild(workspaceId, {
 			transition: "stop",
 			log_level: logLevel,
+			priority,
 		});
 	};
 
@@ -5,8 +5,10 @@ import type {
 	ProvisionerJob,
 	ProvisionerJobStatus,
 	Template,
+	TemplateGitSource,
 	TemplateRole,
 	TemplateVersion,
+	UpdateTemplateGitSourceRequest,
 	UsersRequest,
 } from "api/typesGenerated";
 import type { MutationOptions, QueryClient, QueryOptions } from "react-query";
@@ -199,6 +201,56 @@ export const updateActiveTemplateVersion = (
 	};
 };
 
+const templateGitSourceKey = (templateId: string) => [
+	"template",
+	templateId,
+	"gitSource",
+];
+
+export const templateGitSource = (templateId: string) => {
+	return {
+		queryKey: templateGitSourceKey(templateId),
+		queryFn: () => API.getTemplateGitSource(templateId),
+	};
+};
+
+export const updateTemplateGitSource = (
+	templateId: string,
+	queryClient: QueryClient,
+) => {
+	return {
+		mutationFn: (request: UpdateTemplateGitSourceRequest) =>
+			API.updateTemplateGitSource(templateId, request),
+		onSuccess: (source: TemplateGitSource) => {
+			queryClient.setQueryData(templateGitSourceKey(templateId), source);
+		},
+	};
+};
+
+export const deleteTemplateGitSource = (
+	templateId: string,
+	queryClient: QueryClient,
+) => {
+	return {
+		mutationFn: () => API.deleteTemplateGitSource(templateId),
+		onSuccess: () => {
+			queryClient.setQueryData(templateGitSourceKey(templateId), null);
+		},
+	};
+};
+
+export const syncTemplateGitSource = (
+	templateId: string,
+	queryClient: QueryClient,
+) => {
+	return {
+		mutationFn: () => API.syncTemplateGitSource(templateId),
+		onSuccess: async () => {
+			await queryClient.invalidateQueries(templateGitSourceKey(templateId));
+		},
+	};
+};
+
 export const templaceACLAvailable = (
 	templateId: string,
 	options: UsersRequest,
@@ -352,6 +352,7 @@ export interface CreateWorkspaceBuildRequest {
 	readonly orphan?: boolean;
 	readonly rich_parameter_values?: Readonly<Array<WorkspaceBuildParameter>>;
 	readonly log_level?: ProvisionerLogLevel;
+	readonly priority?: ProvisionerJobPriority;
 }
 
 // From wirtualsdk/workspaceproxy.go
@@ -496,6 +497,7 @@ export interface DeploymentValues {
 	readonly metrics_cache_refresh_interval?: number;
 	readonly agent_stat_refresh_interval?: number;
 	readonly agent_fallback_troubleshooting_url?: string;
+	readonly agent_auto_update?: boolean;
 	readonly browser_only?: boolean;
 	readonly scim_api_key?: string;
 	readonly external_token_encryption_keys?: string[];
@@ -689,6 +691,11 @@ export interface Healthcheck {
 	readonly url: string;
 	readonly interval: number;
 	readonly threshold: number;
+	readonly tcp?: string;
+	readonly command?: string;
+	readonly expected_statuses?: readonly number[];
+	readonly body_regex?: string;
+	readonly headers?: Record<string, string>;
 }
 
 // From wirtualsdk/deployment.go
@@ -1064,6 +1071,12 @@ export interface ProvisionerConfig {
 	readonly daemon_poll_jitter: number;
 	readonly force_cancel_interval: number;
 	readonly daemon_psk: string;
+	readonly state_retention: number;
+	readonly drift_check_interval: number;
+	readonly job_retries: number;
+	readonly job_retry_backoff: number;
+	readonly retryable_errors: string[];
+	readonly terraform_engine: string;
 }
 
 // From wirtualsdk/provisionerdaemons.go
@@ -1095,6 +1108,10 @@ export interface ProvisionerJob {
 	readonly tags: Record<string, string>;
 	readonly queue_position: number;
 	readonly queue_size: number;
+	readonly queue_estimated_wait_ms: number;
+	readonly priority: ProvisionerJobPriority;
+	readonly engine?: string;
+	readonly engine_version?: string;
 }
 
 // From wirtualsdk/provisionerdaemons.go
@@ -1140,6 +1157,14 @@ export interface ProvisionerTiming {
 export interface ProxyHealthReport {
 	readonly errors: Readonly<Array<string>>;
 	readonly warnings: Readonly<Array<string>>;
+	readonly load: ProxyLoadReport;
+}
+
+// From wirtualsdk/workspaceproxy.go
+export interface ProxyLoadReport {
+	readonly active_connections: number;
+	readonly rx_bytes_per_second: number;
+	readonly tx_bytes_per_second: number;
 }
 
 // From wirtualsdk/workspaces.go
@@ -1216,6 +1241,11 @@ export interface Response {
 	readonly validations?: Readonly<Array<ValidationError>>;
 }
 
+// From wirtualsdk/workspacestates.go
+export interface RestoreWorkspaceStateRequest {
+	readonly build_number: number;
+}
+
 // From wirtualsdk/roles.go
 export interface Role {
 	readonly name: string;
@@ -1347,6 +1377,7 @@ export interface Template {
 	readonly time_til_dormant_autodelete_ms: number;
 	readonly require_active_version: boolean;
 	readonly max_port_share_level: WorkspaceAgentPortShareLevel;
+	readonly job_timeout_ms: number;
 }
 
 // From wirtualsdk/templates.go
@@ -1396,6 +1427,29 @@ export interface TemplateFilter {
 	readonly q?: string;
 }
 
+// From wirtualsdk/templategitsources.go
+export interface TemplateGitSource {
+	readonly template_id: string;
+	readonly repo_url: string;
+	readonly path: string;
+	readonly ref: string;
+	readonly external_auth_provider_id?: string;
+	readonly deploy_key?: string;
+	readonly known_hosts?: string;
+	readonly auto_promote: boolean;
+	readonly webhook_url: string;
+	readonly webhook_secret: string;
+	readonly user_id: string;
+	readonly created_at: string;
+	readonly updated_at: string;
+	readonly status: TemplateGitSyncStatus;
+	readonly error?: string;
+	readonly last_checked_at?: string;
+	readonly last_synced_at?: string;
+	readonly last_commit_sha?: string;
+	readonly template_version_id?: string;
+}
+
 // From wirtualsdk/templates.go
 export interface TemplateGroup extends Group {
 	readonly role: TemplateRole;
@@ -1470,10 +1524,23 @@ export interface TemplateVersion {
 	readonly readme: string;
 	readonly created_by: MinimalUser;
 	readonly archived: boolean;
+	readonly git_commit_sha?: string;
+	readonly git_commit_author?: string;
 	readonly warnings?: Readonly<Array<TemplateVersionWarning>>;
 	readonly matched_provisioners?: MatchedProvisioners;
 }
 
+// From wirtualsdk/templateversions.go
+export interface TemplateVersionDiff {
+	readonly from_template_version_id: string;
+	readonly to_template_version_id: string;
+	readonly files: Readonly<Array<TemplateVersionFileDiff>>;
+	readonly parameters: TemplateVersionObjectDiff;
+	readonly variables: TemplateVersionObjectDiff;
+	readonly workspace_tags: TemplateVersionObjectDiff;
+	readonly resources: TemplateVersionObjectDiff;
+}
+
 // From wirtualsdk/templateversions.go
 export interface TemplateVersionExternalAuth {
 	readonly id: string;
@@ -1485,6 +1552,34 @@ export interface TemplateVersionExternalAuth {
 	readonly optional?: boolean;
 }
 
+// From wirtualsdk/templateversions.go
+export interface TemplateVersionFieldChange {
+	readonly name: string;
+	readonly from: string;
+	readonly to: string;
+}
+
+// From wirtualsdk/templateversions.go
+export interface TemplateVersionFileDiff {
+	readonly path: string;
+	readonly change: TemplateVersionFileChange;
+	readonly binary: boolean;
+	readonly diff: string;
+}
+
+// From wirtualsdk/templateversions.go
+export interface TemplateVersionObjectChange {
+	readonly name: string;
+	readonly fields: Readonly<Array<TemplateVersionFieldChange>>;
+}
+
+// From wirtualsdk/templateversions.go
+export interface TemplateVersionObjectDiff {
+	readonly added: Readonly<Array<string>>;
+	readonly removed: Readonly<Array<string>>;
+	readonly changed: Readonly<Array<TemplateVersionObjectChange>>;
+}
+
 // From wirtualsdk/templateversions.go
 export interface TemplateVersionParameter {
 	readonly name: string;
@@ -1598,6 +1693,17 @@ export interface UpdateTemplateACL {
 	readonly group_perms?: Record<string, TemplateRole>;
 }
 
+// From wirtualsdk/templategitsources.go
+export interface UpdateTemplateGitSourceRequest {
+	readonly repo_url: string;
+	readonly path?: string;
+	readonly ref?: string;
+	readonly external_auth_provider_id?: string;
+	readonly use_deploy_key?: boolean;
+	readonly known_hosts?: string;
+	readonly auto_promote?: boolean;
+}
+
 // From wirtualsdk/templates.go
 export interface UpdateTemplateMeta {
 	readonly name?: string;
@@ -1620,6 +1726,7 @@ export interface UpdateTemplateMeta {
 	readonly deprecation_message?: string;
 	readonly disable_everyone_group_access: boolean;
 	readonly max_port_share_level?: WorkspaceAgentPortShareLevel;
+	readonly job_timeout_ms?: number;
 }
 
 // From wirtualsdk/users.go
@@ -1664,6 +1771,11 @@ export interface UpdateWorkspaceDormancy {
 	readonly dormant: boolean;
 }
 
+// From wirtualsdk/workspaceproxy.go
+export interface UpdateWorkspaceProxyDrainingRequest {
+	readonly draining: boolean;
+}
+
 // From wirtualsdk/workspaceproxy.go
 export interface UpdateWorkspaceProxyResponse {
 	readonly proxy: WorkspaceProxy;
@@ -1847,6 +1959,7 @@ export interface Workspace {
 	readonly automatic_updates: AutomaticUpdates;
 	readonly allow_renames: boolean;
 	readonly favorite: boolean;
+	readonly has_drift: boolean;
 }
 
 // From wirtualsdk/workspaceagents.go
@@ -1885,6 +1998,45 @@ export interface WorkspaceAgent {
 	readonly startup_script_behavior: WorkspaceAgentStartupScriptBehavior;
 }
 
+// From wirtualsdk/workspaceagents.go
+export interface WorkspaceAgentDevcontainer {
+	readonly name: string;
+	readonly workspace_folder: string;
+	readonly config_path: string;
+	readonly state: WorkspaceAgentDevcontainerState;
+	readonly container_id?: string;
+	readonly image?: string;
+	readonly error?: string;
+	readonly state_changed_at?: string;
+}
+
+// From wirtualsdk/workspaceagents.go
+export interface WorkspaceAgentDevcontainersResponse {
+	readonly devcontainers: Readonly<Array<WorkspaceAgentDevcontainer>>;
+}
+
+// From wirtualsdk/workspaceagentexec.go
+export interface WorkspaceAgentExecEvent {
+	readonly type: WorkspaceAgentExecEventType;
+	readonly output?: string;
+	readonly result?: WorkspaceAgentExecResult;
+}
+
+// From wirtualsdk/workspaceagentexec.go
+export interface WorkspaceAgentExecRequest {
+	readonly command: Readonly<Array<string>>;
+	readonly env?: Record<string, string>;
+	readonly working_directory?: string;
+	readonly timeout_ms?: number;
+}
+
+// From wirtualsdk/workspaceagentexec.go
+export interface WorkspaceAgentExecResult {
+	readonly exit_code: number;
+	readonly timed_out: boolean;
+	readonly error?: string;
+}
+
 // From wirtualsdk/workspaceagents.go
 export interface WorkspaceAgentHealth {
 	readonly healthy: boolean;
@@ -1896,6 +2048,16 @@ export interface WorkspaceAgentListeningPort {
 	readonly process_name: string;
 	readonly network: string;
 	readonly port: number;
+	readonly pid?: number;
+	readonly cmdline?: string;
+	readonly user?: string;
+	readonly protocol?: WorkspaceAgentPortShareProtocol;
+}
+
+// From wirtualsdk/workspaceagents.go
+export interface WorkspaceAgentListeningPortEvent {
+	readonly type: WorkspaceAgentListeningPortEventType;
+	readonly port: WorkspaceAgentListeningPort;
 }
 
 // From wirtualsdk/workspaceagents.go
@@ -1921,6 +2083,16 @@ export interface WorkspaceAgentLogSource {
 	readonly icon: string;
 }
 
+// From wirtualsdk/workspaceagents.go
+export interface WorkspaceAgentLogsRequest {
+	readonly after?: number;
+	readonly follow?: boolean;
+	readonly source?: Readonly<Array<string>>;
+	readonly level?: LogLevel;
+	readonly since?: string;
+	readonly limit?: number;
+}
+
 // From wirtualsdk/workspaceagents.go
 export interface WorkspaceAgentMetadata {
 	readonly result: WorkspaceAgentMetadataResult;
@@ -1958,6 +2130,25 @@ export interface WorkspaceAgentPortShares {
 	readonly shares: Readonly<Array<WorkspaceAgentPortShare>>;
 }
 
+// From wirtualsdk/workspaceagents.go
+export interface WorkspaceAgentProcess {
+	readonly pid: number;
+	readonly name: string;
+	readonly cmdline: string;
+	readonly user: string;
+	readonly cpu_percent: number;
+	readonly memory_rss_bytes: number;
+	readonly io_read_bytes: number;
+	readonly io_write_bytes: number;
+	readonly cgroup: string;
+}
+
+// From wirtualsdk/workspaceagents.go
+export interface WorkspaceAgentProcessesResponse {
+	readonly collected_at: string;
+	readonly processes: Readonly<Array<WorkspaceAgentProcess>>;
+}
+
 // From wirtualsdk/workspaceagents.go
 export interface WorkspaceAgentScript {
 	readonly id: string;
@@ -1970,6 +2161,34 @@ export interface WorkspaceAgentScript {
 	readonly start_blocks_login: boolean;
 	readonly timeout: number;
 	readonly display_name: string;
+	readonly depends_on?: readonly string[];
+	readonly ready?: WorkspaceAgentScriptReadiness;
+}
+
+// From wirtualsdk/workspaceagents.go
+export interface WorkspaceAgentScriptReadiness {
+	readonly port?: number;
+	readonly file?: string;
+	readonly timeout?: number;
+}
+
+// From wirtualsdk/workspaceagents.go
+export interface WorkspaceAgentService {
+	readonly id: string;
+	readonly name: string;
+	readonly display_name: string;
+	readonly command: string;
+	readonly directory: string;
+	readonly env: Record<string, string>;
+	readonly restart_policy: WorkspaceAgentServiceRestartPolicy;
+	readonly restart_backoff: number;
+	readonly max_restart_backoff: number;
+	readonly healthcheck: Healthcheck;
+	readonly state: WorkspaceAgentServiceState;
+	readonly restart_count: number;
+	readonly exit_code: number;
+	readonly status_message?: string;
+	readonly state_changed_at?: string;
 }
 
 // From wirtualsdk/workspaceapps.go
@@ -1986,6 +2205,7 @@ export interface WorkspaceApp {
 	readonly sharing_level: WorkspaceAppSharingLevel;
 	readonly healthcheck: Healthcheck;
 	readonly health: WorkspaceAppHealth;
+	readonly health_reason?: string;
 	readonly hidden: boolean;
 }
 
@@ -2050,6 +2270,30 @@ export interface WorkspaceDeploymentStats {
 	readonly tx_bytes: number;
 }
 
+// From wirtualsdk/workspacedrift.go
+export interface WorkspaceDrift {
+	readonly build_id: string;
+	readonly build_number: number;
+	readonly checked_at?: string;
+	readonly resources: Readonly<Array<WorkspaceDriftResource>>;
+}
+
+// From wirtualsdk/workspacedrift.go
+export interface WorkspaceDriftAttribute {
+	readonly name: string;
+	readonly before?: string;
+	readonly after?: string;
+	readonly sensitive: boolean;
+}
+
+// From wirtualsdk/workspacedrift.go
+export interface WorkspaceDriftResource {
+	readonly address: string;
+	readonly type: string;
+	readonly action: WorkspaceDriftAction;
+	readonly attributes: Readonly<Array<WorkspaceDriftAttribute>>;
+}
+
 // From wirtualsdk/workspaces.go
 export interface WorkspaceFilter {
 	readonly q?: string;
@@ -2070,6 +2314,7 @@ export interface WorkspaceOptions {
 export interface WorkspaceProxy extends Region {
 	readonly derp_enabled: boolean;
 	readonly derp_only: boolean;
+	readonly draining: boolean;
 	readonly status?: WorkspaceProxyStatus;
 	readonly created_at: string;
 	readonly updated_at: string;
@@ -2118,6 +2363,32 @@ export interface WorkspaceResourceMetadata {
 	readonly sensitive: boolean;
 }
 
+// From wirtualsdk/workspacestates.go
+export interface WorkspaceState {
+	readonly build_id: string;
+	readonly build_number: number;
+	readonly transition: WorkspaceTransition;
+	readonly created_at: string;
+	readonly initiator_name: string;
+	readonly size: number;
+	readonly resource_count?: number;
+}
+
+// From wirtualsdk/workspacestates.go
+export interface WorkspaceStateDiff {
+	readonly from_build_number: number;
+	readonly to_build_number: number;
+	readonly added: Readonly<Array<string>>;
+	readonly removed: Readonly<Array<string>>;
+	readonly changed: Readonly<Array<WorkspaceStateResourceChange>>;
+}
+
+// From wirtualsdk/workspacestates.go
+export interface WorkspaceStateResourceChange {
+	readonly address: string;
+	readonly attributes: Readonly<Array<string>>;
+}
+
 // From wirtualsdk/workspaces.go
 export interface WorkspacesRequest extends Pagination {
 	readonly q?: string;
@@ -2138,8 +2409,8 @@ export type AgentSubsystem = "envbox" | "envbuilder" | "exectrace"
 export const AgentSubsystems: AgentSubsystem[] = ["envbox", "envbuilder", "exectrace"]
 
 // From wirtualsdk/audit.go
-export type AuditAction = "create" | "delete" | "login" | "logout" | "register" | "request_password_reset" | "start" | "stop" | "write"
-export const AuditActions: AuditAction[] = ["create", "delete", "login", "logout", "register", "request_password_reset", "start", "stop", "write"]
+export type AuditAction = "create" | "delete" | "exec" | "login" | "logout" | "register" | "request_password_reset" | "start" | "stop" | "write"
+export const AuditActions: AuditAction[] = ["create", "delete", "exec", "login", "logout", "register", "request_password_reset", "start", "stop", "write"]
 
 // From wirtualsdk/workspaces.go
 export type AutomaticUpdates = "always" | "never"
@@ -2186,8 +2457,8 @@ export type InsightsReportInterval = "day" | "week"
 export const InsightsReportIntervals: InsightsReportInterval[] = ["day", "week"]
 
 // From wirtualsdk/provisionerdaemons.go
-export type JobErrorCode = "REQUIRED_TEMPLATE_VARIABLES"
-export const JobErrorCodes: JobErrorCode[] = ["REQUIRED_TEMPLATE_VARIABLES"]
+export type JobErrorCode = "JOB_TIMEOUT" | "REQUIRED_TEMPLATE_VARIABLES"
+export const JobErrorCodes: JobErrorCode[] = ["JOB_TIMEOUT", "REQUIRED_TEMPLATE_VARIABLES"]
 
 // From wirtualsdk/provisionerdaemons.go
 export type LogLevel = "debug" | "error" | "info" | "trace" | "warn"
@@ -2213,6 +2484,10 @@ export const OAuth2ProviderResponseTypes: OAuth2ProviderResponseType[] = ["code"
 export type PostgresAuth = "awsiamrds" | "password"
 export const PostgresAuths: PostgresAuth[] = ["awsiamrds", "password"]
 
+// From wirtualsdk/provisionerdaemons.go
+export type ProvisionerJobPriority = "autostart" | "bulk" | "interactive"
+export const ProvisionerJobPriorities: ProvisionerJobPriority[] = ["autostart", "bulk", "interactive"]
+
 // From wirtualsdk/provisionerdaemons.go
 export type ProvisionerJobStatus = "canceled" | "canceling" | "failed" | "pending" | "running" | "succeeded" | "unknown"
 export const ProvisionerJobStatuses: ProvisionerJobStatus[] = ["canceled", "canceling", "failed", "pending", "running", "succeeded", "unknown"]
@@ -2226,8 +2501,8 @@ export type ProvisionerStorageMethod = "file"
 export const ProvisionerStorageMethods: ProvisionerStorageMethod[] = ["file"]
 
 // From wirtualsdk/organizations.go
-export type ProvisionerType = "echo" | "terraform"
-export const ProvisionerTypes: ProvisionerType[] = ["echo", "terraform"]
+export type ProvisionerType = "echo" | "exec" | "terraform"
+export const ProvisionerTypes: ProvisionerType[] = ["echo", "exec", "terraform"]
 
 // From wirtualsdk/workspaceproxy.go
 export type ProxyHealthStatus = "ok" | "unhealthy" | "unreachable" | "unregistered"
@@ -2253,6 +2528,10 @@ export const ServerSentEventTypes: ServerSentEventType[] = ["data", "error", "pi
 export type TemplateAppsType = "app" | "builtin"
 export const TemplateAppsTypes: TemplateAppsType[] = ["app", "builtin"]
 
+// From wirtualsdk/templategitsources.go
+export type TemplateGitSyncStatus = "dry_run" | "failed" | "importing" | "pending" | "succeeded"
+export const TemplateGitSyncStatuses: TemplateGitSyncStatus[] = ["dry_run", "failed", "importing", "pending", "succeeded"]
+
 // From wirtualsdk/insights.go
 export type TemplateInsightsSection = "interval_reports" | "report"
 export const TemplateInsightsSections: TemplateInsightsSection[] = ["interval_reports", "report"]
@@ -2261,6 +2540,10 @@ export const TemplateInsightsSections: TemplateInsightsSection[] = ["interval_re
 export type TemplateRole = "" | "admin" | "use"
 export const TemplateRoles: TemplateRole[] = ["", "admin", "use"]
 
+// From wirtualsdk/templateversions.go
+export type TemplateVersionFileChange = "added" | "modified" | "removed"
+export const TemplateVersionFileChanges: TemplateVersionFileChange[] = ["added", "modified", "removed"]
+
 // From wirtualsdk/templateversions.go
 export type TemplateVersionWarning = "UNSUPPORTED_WORKSPACES"
 export const TemplateVersionWarnings: TemplateVersionWarning[] = ["UNSUPPORTED_WORKSPACES"]
@@ -2281,10 +2564,22 @@ export const UserStatuses: UserStatus[] = ["active", "dormant", "suspended"]
 export type ValidationMonotonicOrder = "decreasing" | "increasing"
 export const ValidationMonotonicOrders: ValidationMonotonicOrder[] = ["decreasing", "increasing"]
 
+// From wirtualsdk/workspaceagents.go
+export type WorkspaceAgentDevcontainerState = "failed" | "running" | "starting" | "stopped"
+export const WorkspaceAgentDevcontainerStates: WorkspaceAgentDevcontainerState[] = ["failed", "running", "starting", "stopped"]
+
+// From wirtualsdk/workspaceagentexec.go
+export type WorkspaceAgentExecEventType = "exit" | "stderr" | "stdout"
+export const WorkspaceAgentExecEventTypes: WorkspaceAgentExecEventType[] = ["exit", "stderr", "stdout"]
+
 // From wirtualsdk/workspaceagents.go
 export type WorkspaceAgentLifecycle = "created" | "off" | "ready" | "shutdown_error" | "shutdown_timeout" | "shutting_down" | "start_error" | "start_timeout" | "starting"
 export const WorkspaceAgentLifecycles: WorkspaceAgentLifecycle[] = ["created", "off", "ready", "shutdown_error", "shutdown_timeout", "shutting_down", "start_error", "start_timeout", "starting"]
 
+// From wirtualsdk/workspaceagents.go
+export type WorkspaceAgentListeningPortEventType = "closed" | "opened"
+export const WorkspaceAgentListeningPortEventTypes: WorkspaceAgentListeningPortEventType[] = ["closed", "opened"]
+
 // From wirtualsdk/workspaceagentportshare.go
 export type WorkspaceAgentPortShareLevel = "authenticated" | "owner" | "public"
 export const WorkspaceAgentPortShareLevels: WorkspaceAgentPortShareLevel[] = ["authenticated", "owner", "public"]
@@ -2293,6 +2588,14 @@ export const WorkspaceAgentPortShareLevels: WorkspaceAgentPortShareLevel[] = ["a
 export type WorkspaceAgentPortShareProtocol = "http" | "https"
 export const WorkspaceAgentPortShareProtocols: WorkspaceAgentPortShareProtocol[] = ["http", "https"]
 
+// From wirtualsdk/workspaceagents.go
+export type WorkspaceAgentServiceRestartPolicy = "always" | "never" | "on-failure"
+export const WorkspaceAgentServiceRestartPolicys: WorkspaceAgentServiceRestartPolicy[] = ["always", "never", "on-failure"]
+
+// From wirtualsdk/workspaceagents.go
+export type WorkspaceAgentServiceState = "backoff" | "exited" | "failed" | "pending" | "running" | "starting" | "unhealthy"
+export const WorkspaceAgentServiceStates: WorkspaceAgentServiceState[] = ["backoff", "exited", "failed", "pending", "running", "starting", "unhealthy"]
+
 // From wirtualsdk/workspaceagents.go
 export type WorkspaceAgentStartupScriptBehavior = "blocking" | "non-blocking"
 export const WorkspaceAgentStartupScriptBehaviors: WorkspaceAgentStartupScriptBehavior[] = ["blocking", "non-blocking"]
@@ -2309,6 +2612,10 @@ export const WorkspaceAppHealths: WorkspaceAppHealth[] = ["disabled", "healthy",
 export type WorkspaceAppSharingLevel = "authenticated" | "owner" | "public"
 export const WorkspaceAppSharingLevels: WorkspaceAppSharingLevel[] = ["authenticated", "owner", "public"]
 
+// From wirtualsdk/workspacedrift.go
+export type WorkspaceDriftAction = "delete" | "update"
+export const WorkspaceDriftActions: WorkspaceDriftAction[] = ["delete", "update"]
+
 // From wirtualsdk/workspacebuilds.go
 export type WorkspaceStatus = "canceled" | "canceling" | "deleted" | "deleting" | "failed" | "pending" | "running" | "starting" | "stopped" | "stopping"
 export const WorkspaceStatuses: WorkspaceStatus[] = ["canceled", "canceling", "deleted", "deleting", "failed", "pending", "running", "starting", "stopped", "stopping"]
@@ -134,6 +134,7 @@ export const HealthUnhealthy: Story = {
 		app: {
 			...MockWorkspaceApp,
 			health: "unhealthy",
+			health_reason: "unexpected status code 503",
 		},
 		agent: MockWorkspaceAgent,
 	},
@@ -90,7 +90,9 @@ export const AppLink: FC<AppLinkProps> = ({ app, workspace, agent }) => {
 	}
 	if (app.health === "unhealthy") {
 		icon = <ErrorOutlineIcon css={{ color: theme.palette.warning.light }} />;
-		primaryTooltip = "Unhealthy";
+		primaryTooltip = app.health_reason
+			? `Unhealthy: ${app.health_reason}`
+			: "Unhealthy";
 	}
 	if (!appsHost && app.subdomain) {
 		canClick = false;
@@ -1,6 +1,7 @@
 import VariablesIcon from "@mui/icons-material/CodeOutlined";
 import SecurityIcon from "@mui/icons-material/LockOutlined";
 import GeneralIcon from "@mui/icons-material/SettingsOutlined";
+import GitSourceIcon from "@mui/icons-material/SourceOutlined";
 import ScheduleIcon from "@mui/icons-material/TimerOutlined";
 import type { Template } from "api/typesGenerated";
 import { ExternalAvatar } from "components/Avatar/Avatar";
@@ -44,6 +45,9 @@ export const Sidebar: FC<SidebarProps> = ({ template }) => {
 			<SidebarNavItem href="schedule" icon={ScheduleIcon}>
 				Schedule
 			</SidebarNavItem>
+			<SidebarNavItem href="git-source" icon={GitSourceIcon}>
+				Git source
+			</SidebarNavItem>
 		</BaseSidebar>
 	);
 };
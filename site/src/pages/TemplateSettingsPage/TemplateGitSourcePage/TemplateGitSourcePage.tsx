@@ -0,0 +1,101 @@
+import {
+	deleteTemplateGitSource,
+	syncTemplateGitSource,
+	templateGitSource,
+	updateTemplateGitSource,
+} from "api/queries/templates";
+import { ErrorAlert } from "components/Alert/ErrorAlert";
+import { ConfirmDialog } from "components/Dialogs/ConfirmDialog/ConfirmDialog";
+import { displayError, displaySuccess } from "components/GlobalSnackbar/utils";
+import { Loader } from "components/Loader/Loader";
+import { linkToTemplate, useLinks } from "modules/navigation";
+import { type FC, useState } from "react";
+import { Helmet } from "react-helmet-async";
+import { useMutation, useQuery, useQueryClient } from "react-query";
+import { useNavigate } from "react-router-dom";
+import { pageTitle } from "utils/page";
+import { useTemplateSettings } from "../TemplateSettingsLayout";
+import { TemplateGitSourcePageView } from "./TemplateGitSourcePageView";
+
+export const TemplateGitSourcePage: FC = () => {
+	const getLink = useLinks();
+	const { template } = useTemplateSettings();
+	const navigate = useNavigate();
+	const queryClient = useQueryClient();
+	const [isDisconnecting, setIsDisconnecting] = useState(false);
+
+	const sourceQuery = useQuery(templateGitSource(template.id));
+	const updateMutation = useMutation(
+		updateTemplateGitSource(template.id, queryClient),
+	);
+	const syncMutation = useMutation(
+		syncTemplateGitSource(template.id, queryClient),
+	);
+	const deleteMutation = useMutation(
+		deleteTemplateGitSource(template.id, queryClient),
+	);
+
+	if (sourceQuery.error) {
+		return <ErrorAlert error={sourceQuery.error} />;
+	}
+
+	if (sourceQuery.data === undefined) {
+		return <Loader />;
+	}
+
+	return (
+		<>
+			<Helmet>
+				<title>{pageTitle(template.name, "Git source")}</title>
+			</Helmet>
+
+			<TemplateGitSourcePageView
+				source={sourceQuery.data}
+				isSubmitting={updateMutation.isLoading}
+				isSyncing={syncMutation.isLoading}
+				error={updateMutation.error}
+				onCancel={() => {
+					navigate(
+						getLink(linkToTemplate(template.organization_name, template.name)),
+					);
+				}}
+				onSubmit={async (request) => {
+					await updateMutation.mutateAsync(request);
+					displaySuccess("Git source updated successfully");
+				}}
+				onSync={async () => {
+					try {
+						await syncMutation.mutateAsync();
+						displaySuccess(
+							"The repository will be checked for new commits shortly",
+						);
+					} catch {
+						displayError("Failed to sync the repository");
+					}
+				}}
+				onDisconnect={() => setIsDisconnecting(true)}
+			/>
+
+			<ConfirmDialog
+				type="delete"
+				open={isDisconnecting}
+				title="Disconnect repository"
+				confirmText="Disconnect"
+				confirmLoading={deleteMutation.isLoading}
+				description="New commits will no longer create versions of this template. Existing versions are kept."
+				onClose={() => setIsDisconnecting(false)}
+				onConfirm={async () => {
+					try {
+						await deleteMutation.mutateAsync();
+						setIsDisconnecting(false);
+						displaySuccess("Repository disconnected");
+					} catch {
+						displayError("Failed to disconnect the repository");
+					}
+				}}
+			/>
+		</>
+	);
+};
+
+export default TemplateGitSourcePage;
@@ -0,0 +1,80 @@
+import { action } from "@storybook/addon-actions";
+import type { Meta, StoryObj } from "@storybook/react";
+import type { TemplateGitSource } from "api/typesGenerated";
+import { MockTemplate, mockApiError } from "testHelpers/entities";
+import { TemplateGitSourcePageView } from "./TemplateGitSourcePageView";
+
+const source: TemplateGitSource = {
+	template_id: MockTemplate.id,
+	repo_url: "git@github.com:example/templates.git",
+	path: "docker",
+	ref: "main",
+	deploy_key: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKhp2a7fuRl4tRWtDMlfcrXR4W6v5qDJpdm3UnV1Ns2O",
+	known_hosts: "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+	auto_promote: true,
+	webhook_url: `https://coder.example.com/api/v2/templategitsources/${MockTemplate.id}/webhook`,
+	webhook_secret: "ZtNbc3yd4vyVwNuvc0sHC0GmjjHWSnqj",
+	user_id: MockTemplate.created_by_id,
+	created_at: "2024-05-01T12:00:00Z",
+	updated_at: "2024-05-01T12:00:00Z",
+	status: "succeeded",
+	last_checked_at: "2024-05-02T12:00:00Z",
+	last_synced_at: "2024-05-02T12:00:00Z",
+	last_commit_sha: "1b9b2c3f5d8e4a6b7c8d9e0f1a2b3c4d5e6f7a8b",
+};
+
+const meta: Meta<typeof TemplateGitSourcePageView> = {
+	title: "pages/TemplateSettingsPage/TemplateGitSourcePageView",
+	component: TemplateGitSourcePageView,
+	args: {
+		onSubmit: action("onSubmit"),
+		onCancel: action("onCancel"),
+		onSync: action("onSync"),
+		onDisconnect: action("onDisconnect"),
+		isSubmitting: false,
+		isSyncing: false,
+	},
+};
+
+export default meta;
+type Story = StoryObj<typeof TemplateGitSourcePageView>;
+
+export const NotConnected: Story = {
+	args: {
+		source: null,
+	},
+};
+
+export const Synced: Story = {
+	args: {
+		source,
+	},
+};
+
+export const SyncFailed: Story = {
+	args: {
+		source: {
+			...source,
+			status: "failed",
+			error: 'path "docker" is not a directory',
+		},
+	},
+};
+
+export const WithError: Story = {
+	args: {
+		source,
+		error: mockApiError({
+			message: "Invalid request",
+			validations: [
+				{
+					field: "path",
+					detail: "Path must be relative to the root of the repository.",
+				},
+			],
+		}),
+		initialTouched: {
+			path: true,
+		},
+	},
+};
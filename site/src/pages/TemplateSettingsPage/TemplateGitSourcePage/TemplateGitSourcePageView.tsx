@@ -0,0 +1,310 @@
+import Button from "@mui/material/Button";
+import Checkbox from "@mui/material/Checkbox";
+import FormControlLabel from "@mui/material/FormControlLabel";
+import MenuItem from "@mui/material/MenuItem";
+import TextField from "@mui/material/TextField";
+import type {
+	TemplateGitSource,
+	TemplateGitSyncStatus,
+	UpdateTemplateGitSourceRequest,
+} from "api/typesGenerated";
+import { Alert } from "components/Alert/Alert";
+import { CodeExample } from "components/CodeExample/CodeExample";
+import {
+	FormFields,
+	FormFooter,
+	FormSection,
+	HorizontalForm,
+} from "components/Form/Form";
+import { PageHeader, PageHeaderTitle } from "components/PageHeader/PageHeader";
+import { Stack } from "components/Stack/Stack";
+import {
+	StackLabel,
+	StackLabelHelperText,
+} from "components/StackLabel/StackLabel";
+import { Stats, StatsItem } from "components/Stats/Stats";
+import { type FormikTouched, useFormik } from "formik";
+import type { FC } from "react";
+import { createDayString } from "utils/createDayString";
+import { getFormHelpers, onChangeTrimmed } from "utils/formUtils";
+import * as Yup from "yup";
+
+type AuthMethod = "none" | "external_auth" | "deploy_key";
+
+export interface TemplateGitSourceFormValues {
+	repo_url: string;
+	path: string;
+	ref: string;
+	auth: AuthMethod;
+	external_auth_provider_id: string;
+	known_hosts: string;
+	auto_promote: boolean;
+}
+
+const isSSHURL = (url: string) => url !== "" && !url.startsWith("https://");
+
+const validationSchema = Yup.object({
+	repo_url: Yup.string().trim().required("Repository URL is required"),
+	path: Yup.string()
+		.trim()
+		.test(
+			"relative",
+			"Path must be relative to the root of the repository",
+			(value) => !value?.startsWith("/") && !value?.split("/").includes(".."),
+		),
+	external_auth_provider_id: Yup.string().when("auth", {
+		is: "external_auth",
+		then: (schema) => schema.trim().required("Provider ID is required"),
+	}),
+	known_hosts: Yup.string().when("repo_url", {
+		is: isSSHURL,
+		then: (schema) =>
+			schema.trim().required("Known hosts are required for SSH URLs"),
+	}),
+});
+
+const statusLabels: Record<TemplateGitSyncStatus, string> = {
+	pending: "Pending",
+	importing: "Importing",
+	dry_run: "Dry run",
+	succeeded: "Succeeded",
+	failed: "Failed",
+};
+
+export interface TemplateGitSourcePageViewProps {
+	source: TemplateGitSource | null;
+	onSubmit: (request: UpdateTemplateGitSourceRequest) => void;
+	onCancel: () => void;
+	onSync: () => void;
+	onDisconnect: () => void;
+	isSubmitting: boolean;
+	isSyncing: boolean;
+	error?: unknown;
+	// Helpful to show field errors on Storybook
+	initialTouched?: FormikTouched<TemplateGitSourceFormValues>;
+}
+
+export const TemplateGitSourcePageView: FC<TemplateGitSourcePageViewProps> = ({
+	source,
+	onSubmit,
+	onCancel,
+	onSync,
+	onDisconnect,
+	isSubmitting,
+	isSyncing,
+	error,
+	initialTouched,
+}) => {
+	const form = useFormik<TemplateGitSourceFormValues>({
+		initialValues: {
+			repo_url: source?.repo_url ?? "",
+			path: source?.path ?? "",
+			ref: source?.ref ?? "",
+			auth: source?.external_auth_provider_id
+				? "external_auth"
+				: source?.deploy_key
+					? "deploy_key"
+					: "none",
+			external_auth_provider_id: source?.external_auth_provider_id ?? "",
+			known_hosts: source?.known_hosts ?? "",
+			auto_promote: source?.auto_promote ?? false,
+		},
+		enableReinitialize: true,
+		validationSchema,
+		onSubmit: (values) => {
+			onSubmit({
+				repo_url: values.repo_url,
+				path: values.path,
+				ref: values.ref,
+				external_auth_provider_id:
+					values.auth === "external_auth"
+						? values.external_auth_provider_id
+						: "",
+				use_deploy_key: values.auth === "deploy_key",
+				known_hosts: isSSHURL(values.repo_url) ? values.known_hosts : "",
+				auto_promote: values.auto_promote,
+			});
+		},
+		initialTouched,
+	});
+	const getFieldHelpers = getFormHelpers(form, error);
+
+	return (
+		<>
+			<PageHeader
+				css={{ paddingTop: 0 }}
+				actions={
+					source && (
+						<Stack direction="row" spacing={1}>
+							<Button size="small" disabled={isSyncing} onClick={onSync}>
+								Sync now
+							</Button>
+							<Button size="small" color="error" onClick={onDisconnect}>
+								Disconnect
+							</Button>
+						</Stack>
+					)
+				}
+			>
+				<PageHeaderTitle>Git source</PageHeaderTitle>
+			</PageHeader>
+
+			{source ? (
+				<Stack css={{ marginBottom: 64 }}>
+					<Stats aria-label="Sync status">
+						<StatsItem label="Status" value={statusLabels[source.status]} />
+						<StatsItem
+							label="Last commit"
+							value={source.last_commit_sha?.slice(0, 12) ?? "None"}
+						/>
+						<StatsItem
+							label="Last checked"
+							value={
+								source.last_checked_at
+									? createDayString(source.last_checked_at)
+									: "Never"
+							}
+						/>
+						<StatsItem
+							label="Last synced"
+							value={
+								source.last_synced_at
+									? createDayString(source.last_synced_at)
+									: "Never"
+							}
+						/>
+					</Stats>
+					{source.error && <Alert severity="error">{source.error}</Alert>}
+				</Stack>
+			) : (
+				<Alert severity="info" css={{ marginBottom: 64 }}>
+					This template isn&apos;t synced from git. New versions of the
+					template are created from new commits once a repository is
+					connected.
+				</Alert>
+			)}
+
+			<HorizontalForm onSubmit={form.handleSubmit} aria-label="Git source">
+				<FormSection
+					title="Repository"
+					description="The repository is polled for new commits. The path is the directory of the template within the repository."
+				>
+					<FormFields>
+						<TextField
+							{...getFieldHelpers("repo_url")}
+							disabled={isSubmitting}
+							onChange={onChangeTrimmed(form)}
+							autoFocus
+							fullWidth
+							label="Repository URL"
+							placeholder="https://github.com/example/templates.git"
+						/>
+						<TextField
+							{...getFieldHelpers("path")}
+							disabled={isSubmitting}
+							onChange={onChangeTrimmed(form)}
+							fullWidth
+							label="Path"
+						/>
+						<TextField
+							{...getFieldHelpers("ref", {
+								helperText: "Defaults to the default branch of the repository.",
+							})}
+							disabled={isSubmitting}
+							onChange={onChangeTrimmed(form)}
+							fullWidth
+							label="Branch or tag"
+						/>
+						{isSSHURL(form.values.repo_url) && (
+							<TextField
+								{...getFieldHelpers("known_hosts", {
+									helperText:
+										"The SSH host keys of the repository host, like the output of ssh-keyscan. Only these keys are trusted.",
+								})}
+								disabled={isSubmitting}
+								fullWidth
+								multiline
+								minRows={2}
+								label="Known hosts"
+							/>
+						)}
+					</FormFields>
+				</FormSection>
+
+				<FormSection
+					title="Authentication"
+					description="Private repositories are fetched with the external auth credentials of the user that saves this form, or over SSH with a read-only deploy key."
+				>
+					<FormFields>
+						<TextField
+							{...getFieldHelpers("auth")}
+							disabled={isSubmitting}
+							fullWidth
+							select
+							label="Method"
+						>
+							<MenuItem value="none">None</MenuItem>
+							<MenuItem value="external_auth">External auth</MenuItem>
+							<MenuItem value="deploy_key">Deploy key</MenuItem>
+						</TextField>
+						{form.values.auth === "external_auth" && (
+							<TextField
+								{...getFieldHelpers("external_auth_provider_id")}
+								disabled={isSubmitting}
+								onChange={onChangeTrimmed(form)}
+								fullWidth
+								label="External auth provider ID"
+							/>
+						)}
+						{form.values.auth === "deploy_key" && source?.deploy_key && (
+							<CodeExample secret={false} code={source.deploy_key} />
+						)}
+					</FormFields>
+				</FormSection>
+
+				<FormSection
+					title="Promotion"
+					description="New versions can be promoted automatically once they are imported."
+				>
+					<FormFields>
+						<FormControlLabel
+							control={
+								<Checkbox
+									size="small"
+									id="auto_promote"
+									name="auto_promote"
+									disabled={isSubmitting}
+									checked={form.values.auto_promote}
+									onChange={form.handleChange}
+								/>
+							}
+							label={
+								<StackLabel>
+									Promote new versions automatically.
+									<StackLabelHelperText>
+										New versions are dry run first, and are only promoted if
+										the dry run succeeds.
+									</StackLabelHelperText>
+								</StackLabel>
+							}
+						/>
+					</FormFields>
+				</FormSection>
+
+				{source && (
+					<FormSection
+						title="Webhook"
+						description="Add a webhook for push events to the repository to sync new commits immediately. GitHub signs the payload with the secret, and GitLab sends it as the token."
+					>
+						<FormFields>
+							<CodeExample secret={false} code={source.webhook_url} />
+							<CodeExample code={source.webhook_secret} />
+						</FormFields>
+					</FormSection>
+				)}
+
+				<FormFooter onCancel={onCancel} isLoading={isSubmitting} />
+			</HorizontalForm>
+		</>
+	);
+};
@@ -55,6 +55,8 @@ const TerminalPage: FC = () => {
 	// a round-trip, and must be a UUIDv4.
 	const reconnectionToken = searchParams.get("reconnect") ?? uuidv4();
 	const command = searchParams.get("command") || undefined;
+	// Opens the terminal in a dev container of the agent.
+	const container = searchParams.get("container") || undefined;
 	// The workspace name is in the format:
 	// <workspace name>[.<agent name>]
 	const workspaceNameParts = params.workspace?.split(".");
@@ -234,6 +236,7 @@ const TerminalPage: FC = () => {
 			command,
 			terminal.rows,
 			terminal.cols,
+			container,
 		)
 			.then((url) => {
 				if (disposed) {
@@ -296,6 +299,7 @@ const TerminalPage: FC = () => {
 		};
 	}, [
 		command,
+		container,
 		proxy.preferredPathAppURL,
 		reconnectionToken,
 		terminal,
@@ -82,8 +82,12 @@ describe("WorkspacesPage", () => {
 		await waitFor(() => {
 			expect(deleteWorkspace).toHaveBeenCalledTimes(2);
 		});
-		expect(deleteWorkspace).toHaveBeenCalledWith(workspaces[0].id);
-		expect(deleteWorkspace).toHaveBeenCalledWith(workspaces[1].id);
+		expect(deleteWorkspace).toHaveBeenCalledWith(workspaces[0].id, {
+			priority: "bulk",
+		});
+		expect(deleteWorkspace).toHaveBeenCalledWith(workspaces[1].id, {
+			priority: "bulk",
+		});
 	});
 
 	describe("batch update", () => {
@@ -270,8 +274,16 @@ describe("WorkspacesPage", () => {
 		await waitFor(() => {
 			expect(stopWorkspace).toHaveBeenCalledTimes(2);
 		});
-		expect(stopWorkspace).toHaveBeenCalledWith(workspaces[0].id);
-		expect(stopWorkspace).toHaveBeenCalledWith(workspaces[1].id);
+		expect(stopWorkspace).toHaveBeenCalledWith(
+			workspaces[0].id,
+			undefined,
+			"bulk",
+		);
+		expect(stopWorkspace).toHaveBeenCalledWith(
+			workspaces[1].id,
+			undefined,
+			"bulk",
+		);
 	});
 
 	it("starts only the stopped and selected workspaces", async () => {
@@ -300,10 +312,16 @@ describe("WorkspacesPage", () => {
 		expect(startWorkspace).toHaveBeenCalledWith(
 			workspaces[0].id,
 			MockStoppedWorkspace.latest_build.template_version_id,
+			undefined,
+			undefined,
+			"bulk",
 		);
 		expect(startWorkspace).toHaveBeenCalledWith(
 			workspaces[1].id,
 			MockStoppedWorkspace.latest_build.template_version_id,
+			undefined,
+			undefined,
+			"bulk",
 		);
 	});
 });
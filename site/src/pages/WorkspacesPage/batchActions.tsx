@@ -14,7 +14,13 @@ export function useBatchActions(options: UseBatchActionsProps) {
 		mutationFn: (workspaces: readonly Workspace[]) => {
 			return Promise.all(
 				workspaces.map((w) =>
-					API.startWorkspace(w.id, w.latest_build.template_version_id),
+					API.startWorkspace(
+						w.id,
+						w.latest_build.template_version_id,
+						undefined,
+						undefined,
+						"bulk",
+					),
 				),
 			);
 		},
@@ -26,7 +32,9 @@ export function useBatchActions(options: UseBatchActionsProps) {
 
 	const stopAllMutation = useMutation({
 		mutationFn: (workspaces: readonly Workspace[]) => {
-			return Promise.all(workspaces.map((w) => API.stopWorkspace(w.id)));
+			return Promise.all(
+				workspaces.map((w) => API.stopWorkspace(w.id, undefined, "bulk")),
+			);
 		},
 		onSuccess,
 		onError: () => {
@@ -36,7 +44,11 @@ export function useBatchActions(options: UseBatchActionsProps) {
 
 	const deleteAllMutation = useMutation({
 		mutationFn: (workspaces: readonly Workspace[]) => {
-			return Promise.all(workspaces.map((w) => API.deleteWorkspace(w.id)));
+			return Promise.all(
+				workspaces.map((w) =>
+					API.deleteWorkspace(w.id, { priority: "bulk" }),
+				),
+			);
 		},
 		onSuccess,
 		onError: () => {
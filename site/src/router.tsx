@@ -204,6 +204,12 @@ const TemplateVersionsPage = lazy(
 	() =>
 		import("./pages/TemplatePage/TemplateVersionsPage/TemplateVersionsPage"),
 );
+const TemplateGitSourcePage = lazy(
+	() =>
+		import(
+			"./pages/TemplateSettingsPage/TemplateGitSourcePage/TemplateGitSourcePage"
+		),
+);
 const TemplateSchedulePage = lazy(
 	() =>
 		import(
@@ -330,6 +336,7 @@ const templateRouter = () => {
 					<Route path="permissions" element={<TemplatePermissionsPage />} />
 					<Route path="variables" element={<TemplateVariablesPage />} />
 					<Route path="schedule" element={<TemplateSchedulePage />} />
+					<Route path="git-source" element={<TemplateGitSourcePage />} />
 				</Route>
 
 				<Route path="versions">
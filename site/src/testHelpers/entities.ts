@@ -644,6 +644,8 @@ export const MockProvisionerJob: TypesGen.ProvisionerJob = {
 	},
 	queue_position: 0,
 	queue_size: 0,
+	queue_estimated_wait_ms: 0,
+	priority: "interactive",
 };
 
 export const MockFailedProvisionerJob: TypesGen.ProvisionerJob = {
@@ -668,6 +670,7 @@ export const MockPendingProvisionerJob: TypesGen.ProvisionerJob = {
 	status: "pending",
 	queue_position: 2,
 	queue_size: 4,
+	queue_estimated_wait_ms: 90000,
 };
 export const MockTemplateVersion: TypesGen.TemplateVersion = {
 	id: "test-template-version",
@@ -779,6 +782,7 @@ export const MockTemplate: TypesGen.Template = {
 	deprecated: false,
 	deprecation_message: "",
 	max_port_share_level: "public",
+	job_timeout_ms: 0,
 };
 
 export const MockTemplateVersionFiles: TemplateVersionFiles = {
@@ -1322,6 +1326,7 @@ export const MockWorkspace: TypesGen.Workspace = {
 	automatic_updates: "never",
 	allow_renames: true,
 	favorite: false,
+	has_drift: false,
 };
 
 export const MockFavoriteWorkspace: TypesGen.Workspace = {
@@ -7,11 +7,15 @@ export const terminalWebsocketUrl = async (
 	command: string | undefined,
 	height: number,
 	width: number,
+	container?: string,
 ): Promise<string> => {
 	const query = new URLSearchParams({ reconnect });
 	if (command) {
 		query.set("command", command);
 	}
+	if (container) {
+		query.set("container", container);
+	}
 	query.set("height", height.toString());
 	query.set("width", width.toString());
 
@@ -78,6 +78,7 @@ type Agent struct {
 	Manifest            *agentsdk.Manifest                               `json:"manifest"`
 	PeerDiagnostics     *tailnet.PeerDiagnostics                         `json:"peer_diagnostics"`
 	PingResult          *ipnstate.PingResult                             `json:"ping_result"`
+	ConnDiagnostics     *tailnet.ConnDiagnostics                         `json:"conn_diagnostics"`
 	Prometheus          []byte                                           `json:"prometheus"`
 	StartupLogs         []wirtualsdk.WorkspaceAgentLog                   `json:"startup_logs"`
 }
@@ -408,6 +409,15 @@ func connectedAgentInfo(ctx context.Context, client *wirtualsdk.Client, log slog
 		return nil
 	})
 
+	eg.Go(func() error {
+		diags, err := conn.Diagnose(ctx, tailnet.DiagnoseOptions{})
+		if err != nil {
+			return xerrors.Errorf("diagnose agent connection: %w", err)
+		}
+		a.ConnDiagnostics = diags
+		return nil
+	})
+
 	eg.Go(func() error {
 		pds := conn.GetPeerDiagnostics()
 		a.PeerDiagnostics = &pds
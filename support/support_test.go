@@ -84,6 +84,7 @@ func TestRun(t *testing.T) {
 		assertNotNilNotEmpty(t, bun.Agent.ClientMagicsockHTML, "client magicsock should be present")
 		assertNotNilNotEmpty(t, bun.Agent.PeerDiagnostics, "agent peer diagnostics should be present")
 		assertNotNilNotEmpty(t, bun.Agent.PingResult, "agent ping result should be present")
+		assertNotNilNotEmpty(t, bun.Agent.ConnDiagnostics, "agent connection diagnostics should be present")
 		assertNotNilNotEmpty(t, bun.Agent.Prometheus, "agent prometheus metrics should be present")
 		assertNotNilNotEmpty(t, bun.Agent.StartupLogs, "agent startup logs should be present")
 		assertNotNilNotEmpty(t, bun.Logs, "bundle logs should be present")
@@ -38,6 +38,9 @@ const lostTimeout = 15 * time.Minute
 // records.
 const WirtualDNSSuffix = "coder."
 
+// coderServiceReverseZone is the reverse DNS zone of CoderServicePrefix.
+const coderServiceReverseZone = "b.2.4.a.a.7.2.6.0.6.d.f.ip6.arpa."
+
 // engineConfigurable is the subset of wgengine.Engine that we use for configuration.
 //
 // This allows us to test configuration code without faking the whole interface.
@@ -72,6 +75,7 @@ type configMaps struct {
 	engine         engineConfigurable
 	static         netmap.NetworkMap
 	hosts          map[dnsname.FQDN][]netip.Addr
+	dnsResolver    string
 	peers          map[uuid.UUID]*peerLifecycle
 	addresses      []netip.Prefix
 	derpMap        *tailcfg.DERPMap
@@ -164,10 +168,11 @@ func (c *configMaps) configLoop() {
 		if c.netmapDirty {
 			nm := c.netMapLocked()
 			hosts := c.hostsLocked()
+			resolver := c.dnsResolver
 			actions = append(actions, func() {
 				c.logger.Debug(context.Background(), "updating engine network map", slog.F("network_map", nm))
 				c.engine.SetNetworkMap(nm)
-				c.reconfig(nm, hosts)
+				c.reconfig(nm, hosts, resolver)
 			})
 		}
 		if c.filterDirty {
@@ -288,6 +293,20 @@ func (c *configMaps) setHosts(hosts map[dnsname.FQDN][]netip.Addr) {
 	c.Broadcast()
 }
 
+// setDNSResolver sets the URL of a DNS-over-HTTP resolver that answers
+// queries under the Coder DNS suffix that don't match any of the hosts. An
+// empty URL means such queries get NXDOMAIN.
+func (c *configMaps) setDNSResolver(url string) {
+	c.L.Lock()
+	defer c.L.Unlock()
+	if c.dnsResolver == url {
+		return
+	}
+	c.dnsResolver = url
+	c.netmapDirty = true
+	c.Broadcast()
+}
+
 // setBlockEndpoints sets whether we should block configuring endpoints we learn
 // from peers.  It triggers a configuration of the engine if the value changes.
 // nolint: revive
@@ -332,13 +351,31 @@ func (c *configMaps) derpMapLocked() *tailcfg.DERPMap {
 // reconfig computes the correct wireguard config and calls the engine.Reconfig
 // with the config we have.  It is not intended for this to be called outside of
 // the updateLoop()
-func (c *configMaps) reconfig(nm *netmap.NetworkMap, hosts map[dnsname.FQDN][]netip.Addr) {
+func (c *configMaps) reconfig(nm *netmap.NetworkMap, hosts map[dnsname.FQDN][]netip.Addr, resolver string) {
 	dnsCfg := &dns.Config{}
-	if len(hosts) > 0 {
+	if len(hosts) > 0 || resolver != "" {
+		// Hosts are nil until they are first set, but a config with only a
+		// resolver should look the same whether or not they were.
+		if hosts == nil {
+			hosts = make(map[dnsname.FQDN][]netip.Addr)
+		}
 		dnsCfg.Hosts = hosts
 		dnsCfg.OnlyIPv6 = true
+		// A nil resolver makes the suffix local, so names that aren't hosts
+		// get NXDOMAIN. Otherwise, they are forwarded to the resolver.
+		var resolvers []*dnstype.Resolver
+		if resolver != "" {
+			resolvers = []*dnstype.Resolver{{Addr: resolver}}
+		}
 		dnsCfg.Routes = map[dnsname.FQDN][]*dnstype.Resolver{
-			WirtualDNSSuffix: nil,
+			WirtualDNSSuffix: resolvers,
+		}
+		if resolver != "" {
+			// With a single set of resolvers and split DNS, the OS would be
+			// given the resolver directly, and skip quad-100. Our resolver is
+			// DNS-over-HTTP on localhost, which the OS can't use, so we add a
+			// second, local route to keep the queries going through us.
+			dnsCfg.Routes[coderServiceReverseZone] = nil
 		}
 	}
 	cfg, err := nmcfg.WGCfg(nm, Logger(c.logger.Named("net.wgconfig")), netmap.AllowSingleHosts, "")
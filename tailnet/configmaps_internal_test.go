@@ -1248,6 +1248,50 @@ func TestConfigMaps_addRemoveHosts(t *testing.T) {
 	_ = testutil.RequireRecvCtx(ctx, t, done)
 }
 
+func TestConfigMaps_setDNSResolver(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	logger := testutil.Logger(t)
+	fEng := newFakeEngineConfigurable()
+	nodePrivateKey := key.NewNode()
+	nodeID := tailcfg.NodeID(5)
+	discoKey := key.NewDisco()
+	uut := newConfigMaps(logger, fEng, nodeID, nodePrivateKey, discoKey.Public())
+	defer uut.close()
+
+	// WHEN: we set a resolver
+	uut.setDNSResolver("http://127.0.0.1:1234/dns-query")
+
+	// THEN: the suffix is forwarded to the resolver, and the reverse zone is
+	// local, so queries keep going through quad-100
+	_ = testutil.RequireRecvCtx(ctx, t, fEng.setNetworkMap)
+	req := testutil.RequireRecvCtx(ctx, t, fEng.reconfig)
+	require.Equal(t, req.dnsCfg, &dns.Config{
+		Routes: map[dnsname.FQDN][]*dnstype.Resolver{
+			WirtualDNSSuffix:        {{Addr: "http://127.0.0.1:1234/dns-query"}},
+			coderServiceReverseZone: nil,
+		},
+		Hosts:    map[dnsname.FQDN][]netip.Addr{},
+		OnlyIPv6: true,
+	})
+
+	// WHEN: we remove the resolver
+	uut.setDNSResolver("")
+	_ = testutil.RequireRecvCtx(ctx, t, fEng.setNetworkMap)
+	req = testutil.RequireRecvCtx(ctx, t, fEng.reconfig)
+
+	// THEN: the engine is reconfigured with an empty config
+	require.Equal(t, req.dnsCfg, &dns.Config{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		uut.close()
+	}()
+	_ = testutil.RequireRecvCtx(ctx, t, done)
+}
+
 func newTestNode(id int) *Node {
 	return &Node{
 		ID:            tailcfg.NodeID(id),
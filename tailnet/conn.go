@@ -51,6 +51,7 @@ const (
 	WorkspaceAgentSSHPort             = 1
 	WorkspaceAgentReconnectingPTYPort = 2
 	WorkspaceAgentSpeedtestPort       = 3
+	WorkspaceAgentMTUProbePort        = 5
 )
 
 // EnvMagicsockDebugLogging enables super-verbose logging for the magicsock
@@ -287,15 +288,16 @@ func NewConn(options *Options) (conn *Conn, err error) {
 		wireguardRouter: &router.Config{
 			LocalAddrs: options.Addresses,
 		},
-		wireguardEngine: wireguardEngine,
-		configMaps:      cfgMaps,
-		nodeUpdater:     nodeUp,
-		telemetrySink:   options.TelemetrySink,
-		dnsConfigurator: options.DNSConfigurator,
-		telemetryStore:  telemetryStore,
-		createdAt:       time.Now(),
-		watchCtx:        ctx,
-		watchCancel:     ctxCancel,
+		wireguardEngine:  wireguardEngine,
+		configMaps:       cfgMaps,
+		nodeUpdater:      nodeUp,
+		telemetrySink:    options.TelemetrySink,
+		dnsConfigurator:  options.DNSConfigurator,
+		wildcardResolver: newWildcardResolver(),
+		telemetryStore:   telemetryStore,
+		createdAt:        time.Now(),
+		watchCtx:         ctx,
+		watchCancel:      ctxCancel,
 	}
 	defer func() {
 		if err != nil {
@@ -387,6 +389,13 @@ func (p ServicePrefix) AsNetip() netip.Prefix {
 	return netip.PrefixFrom(netip.AddrFrom16(out), 48)
 }
 
+// DNSRecordAddr returns the address of an agent's DNS record with a port. It's
+// derived from the agent ID and the record name, so clients, the agent and the
+// coordinator agree on it without exchanging it.
+func DNSRecordAddr(agentID uuid.UUID, name string) netip.Addr {
+	return CoderServicePrefix.AddrFromUUID(uuid.NewSHA1(agentID, []byte(name)))
+}
+
 // Conn is an actively listening Wireguard connection.
 type Conn struct {
 	// Unique ID used for telemetry.
@@ -405,6 +414,7 @@ type Conn struct {
 	wireguardRouter  *router.Config
 	wireguardEngine  wgengine.Engine
 	dnsConfigurator  dns.OSConfigurator
+	wildcardResolver *wildcardResolver
 	listeners        map[listenKey]*listener
 	clientType       proto.TelemetryEvent_ClientType
 	createdAt        time.Time
@@ -451,12 +461,19 @@ func (c *Conn) SetAddresses(ips []netip.Prefix) error {
 	return nil
 }
 
-// SetDNSHosts replaces the map of DNS hosts for the connection.
+// SetDNSHosts replaces the map of DNS hosts for the connection. Hosts with a
+// leading "*." label are wildcards, and match any name below their parent.
 func (c *Conn) SetDNSHosts(hosts map[dnsname.FQDN][]netip.Addr) error {
 	if c.dnsConfigurator == nil {
 		return xerrors.New("no DNSConfigurator set")
 	}
-	c.configMaps.setHosts(hosts)
+	exact, wildcards := splitWildcardHosts(hosts)
+	resolver, err := c.wildcardResolver.setWildcards(wildcards)
+	if err != nil {
+		return xerrors.Errorf("set wildcard DNS hosts: %w", err)
+	}
+	c.configMaps.setHosts(exact)
+	c.configMaps.setDNSResolver(resolver)
 	return nil
 }
 
@@ -647,6 +664,7 @@ func (c *Conn) Close() error {
 	c.logger.Debug(context.Background(), "closed netstack")
 	_ = c.wireguardMonitor.Close()
 	_ = c.dialer.Close()
+	_ = c.wildcardResolver.close()
 	// Stops internals, e.g. tunDevice, magicConn and dnsManager.
 	c.wireguardEngine.Close()
 
@@ -679,12 +697,21 @@ func (c *Conn) Node() *Node {
 // This and below is taken _mostly_ verbatim from Tailscale:
 // https://github.com/tailscale/tailscale/blob/c88bd53b1b7b2fcf7ba302f2e53dd1ce8c32dad4/tsnet/tsnet.go#L459-L494
 
-// Listen listens for connections only on the Tailscale network.
+// Listen listens for connections only on the Tailscale network. Listeners
+// with a host only accept connections to that address, and take precedence
+// over listeners without one.
 func (c *Conn) Listen(network, addr string) (net.Listener, error) {
 	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, xerrors.Errorf("tailnet: split host port for listen: %w", err)
 	}
+	if host != "" {
+		ip, err := netip.ParseAddr(host)
+		if err != nil {
+			return nil, xerrors.Errorf("tailnet: parse listen address: %w", err)
+		}
+		host = ip.String()
+	}
 	lk := listenKey{network, host, port}
 	ln := &listener{
 		s:    c,
@@ -724,7 +751,10 @@ func (c *Conn) DialContextUDP(ctx context.Context, ipp netip.AddrPort) (*gonet.U
 func (c *Conn) forwardTCP(src, dst netip.AddrPort) (handler func(net.Conn), opts []tcpip.SettableSocketOption, intercept bool) {
 	logger := c.logger.Named("tcp").With(slog.F("src", src.String()), slog.F("dst", dst.String()))
 	c.mutex.Lock()
-	ln, ok := c.listeners[listenKey{"tcp", "", fmt.Sprint(dst.Port())}]
+	ln, ok := c.listeners[listenKey{"tcp", dst.Addr().String(), fmt.Sprint(dst.Port())}]
+	if !ok {
+		ln, ok = c.listeners[listenKey{"tcp", "", fmt.Sprint(dst.Port())}]
+	}
 	c.mutex.Unlock()
 	if !ok {
 		return nil, nil, false
@@ -893,12 +893,35 @@ func (w workspace) addAllDNSNames(names map[dnsname.FQDN][]netip.Addr, owner str
 			names[fqdn] = []netip.Addr{CoderServicePrefix.AddrFromUUID(a.id)}
 		}
 	}
+	// DNS records from the template are relative to the workspace, e.g. "api"
+	// is served as api.myws.coder. Wildcard records keep their leading "*."
+	// label, which the DNSHostsSetter must handle. Records with a port have an
+	// address of their own, where the agent forwards port 80 to the port.
+	for _, a := range w.agents {
+		for _, record := range a.dnsRecords {
+			fqdn, err := dnsname.ToFQDN(fmt.Sprintf("%s.%s.coder.", record.name, w.name))
+			if err != nil {
+				return err
+			}
+			addr := CoderServicePrefix.AddrFromUUID(a.id)
+			if record.port != 0 {
+				addr = DNSRecordAddr(a.id, record.name)
+			}
+			names[fqdn] = []netip.Addr{addr}
+		}
+	}
 	return nil
 }
 
 type agent struct {
-	id   uuid.UUID
+	id         uuid.UUID
+	name       string
+	dnsRecords []dnsRecord
+}
+
+type dnsRecord struct {
 	name string
+	port int32
 }
 
 func (t *tunnelAllWorkspaceUpdatesController) New(client WorkspaceUpdatesClient) CloserWaiter {
@@ -1039,6 +1062,9 @@ func (t *tunnelUpdater) handleUpdate(update *proto.WorkspaceUpdate) error {
 			return xerrors.Errorf("failed to parse workspace ID: %w", err)
 		}
 		a := agent{name: ua.Name, id: agentID}
+		for _, record := range ua.DnsRecords {
+			a.dnsRecords = append(a.dnsRecords, dnsRecord{name: record.Name, port: record.Port})
+		}
 		err = t.upsertAgent(workspaceID, a)
 		if err != nil {
 			return xerrors.Errorf("failed to upsert agent: %w", err)
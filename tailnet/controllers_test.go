@@ -1615,6 +1615,52 @@ func TestTunnelAllWorkspaceUpdatesController_DeleteAgent(t *testing.T) {
 	testutil.RequireSendCtx(ctx, t, dnsCall.err, nil)
 }
 
+func TestTunnelAllWorkspaceUpdatesController_DNSRecords(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitShort)
+	logger := testutil.Logger(t)
+
+	fDNS := newFakeDNSSetter(ctx, t)
+	coordC, updateC := setupConnectedAllWorkspaceUpdatesController(ctx, t, logger,
+		tailnet.WithDNS(fDNS, "testy"))
+
+	w1ID := testUUID(1)
+	w1a1ID := testUUID(1, 1)
+	initUp := &proto.WorkspaceUpdate{
+		UpsertedWorkspaces: []*proto.Workspace{
+			{Id: w1ID[:], Name: "w1"},
+		},
+		UpsertedAgents: []*proto.Agent{
+			{Id: w1a1ID[:], Name: "w1a1", WorkspaceId: w1ID[:], DnsRecords: []*proto.Agent_DNSRecord{
+				{Name: "api"},
+				{Name: "*.apps"},
+				{Name: "web", Port: 3000},
+			}},
+		},
+	}
+
+	upRecvCall := testutil.RequireRecvCtx(ctx, t, updateC.recv)
+	testutil.RequireSendCtx(ctx, t, upRecvCall.resp, initUp)
+
+	coordCall := testutil.RequireRecvCtx(ctx, t, coordC.reqs)
+	require.Equal(t, w1a1ID[:], coordCall.req.GetAddTunnel().GetId())
+	testutil.RequireSendCtx(ctx, t, coordCall.err, nil)
+
+	// Records are served relative to the workspace. Records with a port have
+	// an address of their own.
+	expectedDNS := map[dnsname.FQDN][]netip.Addr{
+		"w1a1.w1.testy.coder.": {netip.MustParseAddr("fd60:627a:a42b:0101::")},
+		"w1a1.w1.me.coder.":    {netip.MustParseAddr("fd60:627a:a42b:0101::")},
+		"w1.coder.":            {netip.MustParseAddr("fd60:627a:a42b:0101::")},
+		"api.w1.coder.":        {netip.MustParseAddr("fd60:627a:a42b:0101::")},
+		"*.apps.w1.coder.":     {netip.MustParseAddr("fd60:627a:a42b:0101::")},
+		"web.w1.coder.":        {tailnet.DNSRecordAddr(w1a1ID, "web")},
+	}
+	dnsCall := testutil.RequireRecvCtx(ctx, t, fDNS.calls)
+	require.Equal(t, expectedDNS, dnsCall.hosts)
+	testutil.RequireSendCtx(ctx, t, dnsCall.err, nil)
+}
+
 func TestTunnelAllWorkspaceUpdatesController_DNSError(t *testing.T) {
 	t.Parallel()
 	ctx := testutil.Context(t, testutil.WaitShort)
@@ -0,0 +1,413 @@
+package tailnet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/netip"
+	"time"
+
+	"golang.org/x/xerrors"
+	"tailscale.com/net/netcheck"
+	"tailscale.com/net/tstun"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+)
+
+const (
+	// mtuProbeMinSize is the smallest tunnel packet we attempt to send when
+	// probing the path MTU. Every IPv4 host must accept 576 byte datagrams.
+	mtuProbeMinSize = 576
+	// mtuProbePrecision is the granularity, in bytes, of the path MTU
+	// binary search.
+	mtuProbePrecision = 8
+	// WireguardOverhead is the number of bytes added to every tunnel packet
+	// when it's sent over the underlying network: 40 bytes for an IPv6
+	// header, 8 bytes for UDP and 32 bytes for WireGuard.
+	WireguardOverhead = 80
+
+	// diagnosticsLossThreshold is the packet loss ratio above which we
+	// recommend investigating the network.
+	diagnosticsLossThreshold = 0.05
+	// diagnosticsJitterThreshold is the jitter above which interactive
+	// sessions start to feel sluggish.
+	diagnosticsJitterThreshold = 30 * time.Millisecond
+)
+
+// NATType is a coarse classification of the NAT between this client and the
+// internet, determined using STUN.
+type NATType string
+
+const (
+	NATTypeUnknown NATType = "unknown"
+	// NATTypeNone means the client has a public IP address.
+	NATTypeNone NATType = "none"
+	// NATTypeEasy means the NAT uses endpoint-independent mapping, so direct
+	// connections can usually be established.
+	NATTypeEasy NATType = "easy"
+	// NATTypeHard means the NAT maps the same local port to a different
+	// public port per destination (e.g. symmetric NAT), so direct
+	// connections are unlikely unless the peer has an easy NAT.
+	NATTypeHard NATType = "hard"
+	// NATTypeUDPBlocked means no STUN responses were received over UDP.
+	NATTypeUDPBlocked NATType = "udp_blocked"
+)
+
+// DiagnoseOptions configures Conn.Diagnose.
+type DiagnoseOptions struct {
+	// Probes is the number of pings used to measure latency, jitter and
+	// loss. Defaults to 10.
+	Probes int
+	// ProbeInterval is the time to wait between pings. Defaults to 200ms.
+	ProbeInterval time.Duration
+	// ProbeTimeout is how long a single ping or MTU probe may take before
+	// it's considered lost. Defaults to 5s.
+	ProbeTimeout time.Duration
+	// MTUProbePort is the TCP port on the peer that echoes MTU probes (see
+	// ServeMTUProbe). If zero, path MTU discovery is skipped.
+	MTUProbePort uint16
+	// STUNAddrs are additional STUN servers used for NAT detection. They are
+	// added to the current DERP map using STUNRegions.
+	STUNAddrs []string
+}
+
+// ConnDiagnostics is the result of Conn.Diagnose.
+type ConnDiagnostics struct {
+	Probes     int           `json:"probes"`
+	Received   int           `json:"received"`
+	Loss       float64       `json:"loss"`
+	MinLatency time.Duration `json:"min_latency"`
+	AvgLatency time.Duration `json:"avg_latency"`
+	MaxLatency time.Duration `json:"max_latency"`
+	// Jitter is the mean absolute difference between consecutive round trip
+	// times, as described in RFC 3550.
+	Jitter       time.Duration `json:"jitter"`
+	Direct       bool          `json:"direct"`
+	Endpoint     string        `json:"endpoint,omitempty"`
+	DERPRegionID int           `json:"derp_region_id,omitempty"`
+
+	// TunnelMTU is the MTU of the tailnet interface.
+	TunnelMTU int `json:"tunnel_mtu"`
+	// PathMTU is the largest tunnel packet that reached the peer and back.
+	// The underlying network must carry PathMTU + WireguardOverhead bytes.
+	// Zero if path MTU discovery was skipped or failed.
+	PathMTU       int    `json:"path_mtu"`
+	MTUProbeError string `json:"mtu_probe_error,omitempty"`
+
+	NATType       NATType `json:"nat_type"`
+	UDP           bool    `json:"udp"`
+	GlobalV4      string  `json:"global_v4,omitempty"`
+	GlobalV6      string  `json:"global_v6,omitempty"`
+	NetcheckError string  `json:"netcheck_error,omitempty"`
+
+	Recommendations []string `json:"recommendations"`
+}
+
+// Diagnose measures the quality of the connection to the peer with the given
+// IP: latency, jitter and loss over a number of pings, the path MTU, and the
+// type of NAT this client is behind.
+func (c *Conn) Diagnose(ctx context.Context, ip netip.Addr, opts DiagnoseOptions) (*ConnDiagnostics, error) {
+	if opts.Probes <= 0 {
+		opts.Probes = 10
+	}
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = 200 * time.Millisecond
+	}
+	if opts.ProbeTimeout <= 0 {
+		opts.ProbeTimeout = 5 * time.Second
+	}
+
+	d := &ConnDiagnostics{
+		Probes:    opts.Probes,
+		TunnelMTU: int(tstun.DefaultMTU()),
+		NATType:   NATTypeUnknown,
+	}
+
+	rtts := make([]time.Duration, 0, opts.Probes)
+	for i := 0; i < opts.Probes; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(opts.ProbeInterval):
+			}
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, opts.ProbeTimeout)
+		dur, p2p, pr, err := c.Ping(pingCtx, ip)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			c.logger.Debug(ctx, "diagnostic ping failed", slog.F("probe", i), slog.Error(err))
+			continue
+		}
+		rtts = append(rtts, dur)
+		d.Direct = p2p
+		d.Endpoint = pr.Endpoint
+		d.DERPRegionID = pr.DERPRegionID
+	}
+	d.summarizeLatency(rtts)
+
+	if opts.MTUProbePort != 0 {
+		pathMTU, err := c.probePathMTU(ctx, netip.AddrPortFrom(ip, opts.MTUProbePort), opts.ProbeTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			d.MTUProbeError = err.Error()
+		}
+		d.PathMTU = pathMTU
+	}
+
+	report, err := c.netcheck(ctx, opts.STUNAddrs)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		d.NetcheckError = err.Error()
+	}
+	if report != nil {
+		d.UDP = report.UDP
+		d.GlobalV4 = report.GlobalV4
+		d.GlobalV6 = report.GlobalV6
+		d.NATType = classifyNAT(report, localAddrs())
+	}
+
+	d.Recommendations = d.recommendations()
+	return d, nil
+}
+
+func (d *ConnDiagnostics) summarizeLatency(rtts []time.Duration) {
+	d.Received = len(rtts)
+	if d.Probes > 0 {
+		d.Loss = float64(d.Probes-d.Received) / float64(d.Probes)
+	}
+	if len(rtts) == 0 {
+		return
+	}
+	var (
+		sum       time.Duration
+		jitterSum time.Duration
+	)
+	d.MinLatency = rtts[0]
+	d.MaxLatency = rtts[0]
+	for i, rtt := range rtts {
+		sum += rtt
+		if rtt < d.MinLatency {
+			d.MinLatency = rtt
+		}
+		if rtt > d.MaxLatency {
+			d.MaxLatency = rtt
+		}
+		if i > 0 {
+			diff := rtt - rtts[i-1]
+			if diff < 0 {
+				diff = -diff
+			}
+			jitterSum += diff
+		}
+	}
+	d.AvgLatency = sum / time.Duration(len(rtts))
+	if len(rtts) > 1 {
+		d.Jitter = jitterSum / time.Duration(len(rtts)-1)
+	}
+}
+
+func (d *ConnDiagnostics) recommendations() []string {
+	recs := []string{}
+	if d.Received == 0 {
+		recs = append(recs, "No pings were answered. Check that the workspace is running and that the agent is connected.")
+		return recs
+	}
+	if d.Loss > diagnosticsLossThreshold {
+		recs = append(recs, fmt.Sprintf(
+			"%.0f%% of pings were lost. Packet loss causes stalls in interactive sessions; try a wired connection or a less congested network.",
+			math.Round(d.Loss*100)))
+	}
+	if d.Jitter > diagnosticsJitterThreshold {
+		recs = append(recs, fmt.Sprintf(
+			"Latency varies by %s between pings. High jitter usually points to Wi-Fi interference or a saturated uplink.",
+			d.Jitter.Round(time.Millisecond)))
+	}
+	if d.PathMTU > 0 && d.PathMTU < d.TunnelMTU {
+		recs = append(recs, fmt.Sprintf(
+			"Packets larger than %d bytes are dropped on the path to the workspace, which makes large transfers such as SSH output freeze. "+
+				"Make sure your network carries UDP packets of at least %d bytes (check the MTU of any VPN or tunnel interface), "+
+				"or disable direct connections to use a DERP relay instead.",
+			d.PathMTU, d.TunnelMTU+WireguardOverhead))
+	}
+	if !d.Direct {
+		switch d.NATType {
+		case NATTypeUDPBlocked:
+			recs = append(recs, "Outbound UDP appears to be blocked, so connections are always relayed through DERP. Allow outbound UDP to establish direct connections.")
+		case NATTypeHard:
+			recs = append(recs, "You are behind a NAT that maps ports differently per destination (hard NAT), which prevents direct connections. "+
+				"Forwarding a UDP port to this machine or using a network with an easier NAT will allow direct connections.")
+		}
+	}
+	return recs
+}
+
+// classifyNAT determines the type of NAT from a netcheck report. localAddrs
+// are the addresses assigned to the local interfaces, used to detect whether
+// the client has a public IP.
+func classifyNAT(report *netcheck.Report, localAddrs []netip.Addr) NATType {
+	if !report.UDP {
+		return NATTypeUDPBlocked
+	}
+	if report.GlobalV4 != "" {
+		global, err := netip.ParseAddrPort(report.GlobalV4)
+		if err == nil {
+			for _, addr := range localAddrs {
+				if addr == global.Addr() {
+					return NATTypeNone
+				}
+			}
+		}
+	}
+	varies, ok := report.MappingVariesByDestIP.Get()
+	if !ok {
+		return NATTypeUnknown
+	}
+	if varies {
+		return NATTypeHard
+	}
+	return NATTypeEasy
+}
+
+func localAddrs() []netip.Addr {
+	ifAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	addrs := make([]netip.Addr, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		prefix, err := netip.ParsePrefix(ifAddr.String())
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, prefix.Addr())
+	}
+	return addrs
+}
+
+// netcheck runs a netcheck against the STUN servers in the current DERP map,
+// plus any additional STUN addresses provided.
+func (c *Conn) netcheck(ctx context.Context, stunAddrs []string) (*netcheck.Report, error) {
+	derpMap := c.DERPMap()
+	if derpMap == nil {
+		derpMap = &tailcfg.DERPMap{}
+	}
+	derpMap = derpMap.Clone()
+	if derpMap.Regions == nil {
+		derpMap.Regions = map[int]*tailcfg.DERPRegion{}
+	}
+	if len(stunAddrs) > 0 {
+		var maxRegionID int
+		for id := range derpMap.Regions {
+			if id > maxRegionID {
+				maxRegionID = id
+			}
+		}
+		stunRegions, err := STUNRegions(maxRegionID, stunAddrs)
+		if err != nil {
+			return nil, xerrors.Errorf("create stun regions: %w", err)
+		}
+		for _, region := range stunRegions {
+			derpMap.Regions[region.RegionID] = region
+		}
+	}
+	if len(derpMap.Regions) == 0 {
+		return nil, xerrors.New("no STUN servers available")
+	}
+
+	nc := &netcheck.Client{
+		Logf: Logger(c.logger.Named("netcheck")),
+	}
+	report, err := nc.GetReport(ctx, derpMap)
+	if err != nil {
+		return nil, xerrors.Errorf("get netcheck report: %w", err)
+	}
+	return report, nil
+}
+
+// probePathMTU finds the largest tunnel packet that makes it to the peer and
+// back by sending single TCP segments of varying size to the MTU probe echo
+// server at ipp. Each probe uses a new connection so that a dropped segment
+// does not hold up the following probes with retransmissions.
+func (c *Conn) probePathMTU(ctx context.Context, ipp netip.AddrPort, timeout time.Duration) (int, error) {
+	lo, hi := mtuProbeMinSize, int(tstun.DefaultMTU())
+	ok, err := c.mtuProbe(ctx, ipp, hi, timeout)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		return hi, nil
+	}
+	ok, err = c.mtuProbe(ctx, ipp, lo, timeout)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, xerrors.Errorf("no MTU probes succeeded, even at %d bytes", lo)
+	}
+	for hi-lo > mtuProbePrecision {
+		mid := (lo + hi) / 2
+		ok, err := c.mtuProbe(ctx, ipp, mid, timeout)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// mtuProbe sends a single tunnel packet of the given size to the echo server
+// and reports whether the echo arrived before the timeout. An error is only
+// returned if the probe could not be attempted at all.
+func (c *Conn) mtuProbe(ctx context.Context, ipp netip.AddrPort, size int, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := c.DialContextTCP(ctx, ipp)
+	if err != nil {
+		return false, xerrors.Errorf("dial mtu probe server: %w", err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	// IP and TCP headers count towards the tunnel packet size.
+	headers := 40 + 20
+	if ipp.Addr().Is4() {
+		headers = 20 + 20
+	}
+	payload := make([]byte, size-headers)
+	if _, err := conn.Write(payload); err != nil {
+		return false, nil
+	}
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// ServeMTUProbe echoes everything read from conn back to the sender. It's
+// the server side of the path MTU probes sent by Conn.Diagnose.
+func ServeMTUProbe(conn net.Conn) error {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Minute))
+	_, err := io.Copy(conn, conn)
+	if err != nil && !xerrors.Is(err, io.EOF) && !xerrors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
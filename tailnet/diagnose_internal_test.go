@@ -0,0 +1,119 @@
+package tailnet
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tailscale.com/net/netcheck"
+	"tailscale.com/types/opt"
+)
+
+func TestConnDiagnostics_SummarizeLatency(t *testing.T) {
+	t.Parallel()
+
+	d := &ConnDiagnostics{Probes: 5}
+	d.summarizeLatency([]time.Duration{
+		10 * time.Millisecond,
+		30 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+	})
+	require.Equal(t, 4, d.Received)
+	require.InDelta(t, 0.2, d.Loss, 0.0001)
+	require.Equal(t, 10*time.Millisecond, d.MinLatency)
+	require.Equal(t, 25*time.Millisecond, d.AvgLatency)
+	require.Equal(t, 40*time.Millisecond, d.MaxLatency)
+	// |30-10| + |20-30| + |40-20| = 50ms over 3 intervals.
+	require.Equal(t, 50*time.Millisecond/3, d.Jitter)
+}
+
+func TestConnDiagnostics_Recommendations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Healthy", func(t *testing.T) {
+		t.Parallel()
+		d := &ConnDiagnostics{
+			Probes:    10,
+			Received:  10,
+			Jitter:    time.Millisecond,
+			Direct:    true,
+			TunnelMTU: 1280,
+			PathMTU:   1280,
+			NATType:   NATTypeEasy,
+		}
+		require.Empty(t, d.recommendations())
+	})
+
+	t.Run("NoResponses", func(t *testing.T) {
+		t.Parallel()
+		d := &ConnDiagnostics{Probes: 10, Loss: 1}
+		recs := d.recommendations()
+		require.Len(t, recs, 1)
+		require.Contains(t, recs[0], "No pings were answered")
+	})
+
+	t.Run("Problems", func(t *testing.T) {
+		t.Parallel()
+		d := &ConnDiagnostics{
+			Probes:    10,
+			Received:  8,
+			Loss:      0.2,
+			Jitter:    50 * time.Millisecond,
+			TunnelMTU: 1280,
+			PathMTU:   1200,
+			NATType:   NATTypeHard,
+		}
+		recs := d.recommendations()
+		require.Len(t, recs, 4)
+		require.Contains(t, recs[0], "20% of pings were lost")
+		require.Contains(t, recs[1], "50ms")
+		require.Contains(t, recs[2], "larger than 1200 bytes")
+		require.Contains(t, recs[2], "at least 1360 bytes")
+		require.Contains(t, recs[3], "hard NAT")
+	})
+}
+
+func TestClassifyNAT(t *testing.T) {
+	t.Parallel()
+
+	local := []netip.Addr{netip.MustParseAddr("192.168.1.2"), netip.MustParseAddr("203.0.113.7")}
+	for _, tc := range []struct {
+		name   string
+		report *netcheck.Report
+		want   NATType
+	}{
+		{
+			name:   "UDPBlocked",
+			report: &netcheck.Report{},
+			want:   NATTypeUDPBlocked,
+		},
+		{
+			name:   "PublicIP",
+			report: &netcheck.Report{UDP: true, GlobalV4: "203.0.113.7:41641", MappingVariesByDestIP: opt.Bool("false")},
+			want:   NATTypeNone,
+		},
+		{
+			name:   "Easy",
+			report: &netcheck.Report{UDP: true, GlobalV4: "198.51.100.1:41641", MappingVariesByDestIP: opt.Bool("false")},
+			want:   NATTypeEasy,
+		},
+		{
+			name:   "Hard",
+			report: &netcheck.Report{UDP: true, GlobalV4: "198.51.100.1:41641", MappingVariesByDestIP: opt.Bool("true")},
+			want:   NATTypeHard,
+		},
+		{
+			name:   "Unknown",
+			report: &netcheck.Report{UDP: true, GlobalV4: "198.51.100.1:41641"},
+			want:   NATTypeUnknown,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.want, classifyNAT(tc.report, local))
+		})
+	}
+}
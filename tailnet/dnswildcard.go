@@ -0,0 +1,186 @@
+package tailnet
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/xerrors"
+	"tailscale.com/util/dnsname"
+)
+
+const dnsMessageContentType = "application/dns-message"
+
+// wildcardResolver answers queries for wildcard DNS records, like
+// *.myws.coder. The tailscale resolver only supports exact host matches, so
+// when there are wildcard records we route the Coder DNS suffix to this
+// resolver over DNS-over-HTTP. Exact hosts are still answered by the tailscale
+// resolver before any query is forwarded here.
+//
+// The forwarder sends http:// resolvers through the dialer's PeerAPI client.
+// Its sockets are only bound to the tunnel interface with the ts_macext build
+// tag, which we don't use, so it reaches the resolver on the loopback address.
+// Builds with that tag would need the resolver to listen on the tunnel.
+type wildcardResolver struct {
+	mu sync.Mutex
+	// suffixes maps the parent of each wildcard, e.g. myws.coder., to the
+	// addresses it resolves to.
+	suffixes map[dnsname.FQDN][]netip.Addr
+	listener net.Listener
+	server   *http.Server
+}
+
+func newWildcardResolver() *wildcardResolver {
+	return &wildcardResolver{
+		suffixes: make(map[dnsname.FQDN][]netip.Addr),
+	}
+}
+
+// splitWildcardHosts splits hosts into exact hosts and wildcard suffixes.
+func splitWildcardHosts(hosts map[dnsname.FQDN][]netip.Addr) (exact, wildcards map[dnsname.FQDN][]netip.Addr) {
+	exact = make(map[dnsname.FQDN][]netip.Addr)
+	wildcards = make(map[dnsname.FQDN][]netip.Addr)
+	for name, addrs := range hosts {
+		suffix, ok := strings.CutPrefix(string(name), "*.")
+		if !ok {
+			exact[name] = addrs
+			continue
+		}
+		wildcards[dnsname.FQDN(suffix)] = addrs
+	}
+	return exact, wildcards
+}
+
+// setWildcards replaces the wildcard records. It returns the URL of the
+// resolver, or an empty string if there are no wildcards.
+func (r *wildcardResolver) setWildcards(wildcards map[dnsname.FQDN][]netip.Addr) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.suffixes = wildcards
+	if len(wildcards) == 0 {
+		return "", nil
+	}
+	if r.listener == nil {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return "", xerrors.Errorf("listen for wildcard DNS: %w", err)
+		}
+		r.listener = ln
+		r.server = &http.Server{
+			Handler:           r,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			_ = r.server.Serve(ln)
+		}()
+	}
+	return "http://" + r.listener.Addr().String() + "/dns-query", nil
+}
+
+// lookup returns the addresses for name from the longest matching wildcard.
+// A wildcard only matches strict subdomains of its parent.
+func (r *wildcardResolver) lookup(name dnsname.FQDN) ([]netip.Addr, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var (
+		best  dnsname.FQDN
+		addrs []netip.Addr
+	)
+	for suffix, a := range r.suffixes {
+		if name == suffix || !suffix.Contains(name) {
+			continue
+		}
+		if len(suffix) > len(best) {
+			best = suffix
+			addrs = a
+		}
+	}
+	return addrs, best != ""
+}
+
+func (r *wildcardResolver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != dnsMessageContentType {
+		http.Error(rw, "expected a POST of "+dnsMessageContentType, http.StatusBadRequest)
+		return
+	}
+	packet, err := io.ReadAll(io.LimitReader(req.Body, 64<<10))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res, err := r.respond(packet)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rw.Header().Set("Content-Type", dnsMessageContentType)
+	_, _ = rw.Write(res)
+}
+
+// respond builds the response to a single-question DNS query. Like the
+// tailscale resolver with OnlyIPv6 set, we only answer with IPv6 addresses.
+func (r *wildcardResolver) respond(packet []byte) ([]byte, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(packet)
+	if err != nil {
+		return nil, xerrors.Errorf("parse header: %w", err)
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return nil, xerrors.Errorf("parse question: %w", err)
+	}
+
+	header.Response = true
+	header.Authoritative = true
+	header.RecursionAvailable = true
+	name, err := dnsname.ToFQDN(strings.ToLower(question.Name.String()))
+	if err != nil {
+		return nil, xerrors.Errorf("invalid name %q: %w", question.Name.String(), err)
+	}
+	addrs, ok := r.lookup(name)
+	if !ok {
+		header.RCode = dnsmessage.RCodeNameError
+	}
+
+	builder := dnsmessage.NewBuilder(nil, header)
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+	if err := builder.StartAnswers(); err != nil {
+		return nil, err
+	}
+	if question.Type == dnsmessage.TypeAAAA {
+		for _, addr := range addrs {
+			if !addr.Is6() {
+				continue
+			}
+			err := builder.AAAAResource(dnsmessage.ResourceHeader{
+				Name:  question.Name,
+				Class: dnsmessage.ClassINET,
+				TTL:   600,
+			}, dnsmessage.AAAAResource{AAAA: addr.As16()})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return builder.Finish()
+}
+
+func (r *wildcardResolver) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.server == nil {
+		return nil
+	}
+	return r.server.Close()
+}
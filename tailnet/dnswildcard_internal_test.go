@@ -0,0 +1,108 @@
+package tailnet
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+	"tailscale.com/util/dnsname"
+)
+
+func TestSplitWildcardHosts(t *testing.T) {
+	t.Parallel()
+
+	addr := netip.MustParseAddr("fd60:627a:a42b:0101::")
+	exact, wildcards := splitWildcardHosts(map[dnsname.FQDN][]netip.Addr{
+		"api.myws.coder.":    {addr},
+		"*.apps.myws.coder.": {addr},
+		"*.myws.coder.":      {addr},
+	})
+	require.Equal(t, map[dnsname.FQDN][]netip.Addr{
+		"api.myws.coder.": {addr},
+	}, exact)
+	require.Equal(t, map[dnsname.FQDN][]netip.Addr{
+		"apps.myws.coder.": {addr},
+		"myws.coder.":      {addr},
+	}, wildcards)
+}
+
+func TestWildcardResolver(t *testing.T) {
+	t.Parallel()
+
+	wsAddr := netip.MustParseAddr("fd60:627a:a42b:0101::")
+	appsAddr := netip.MustParseAddr("fd60:627a:a42b:0102::")
+	r := newWildcardResolver()
+	t.Cleanup(func() {
+		_ = r.close()
+	})
+
+	url, err := r.setWildcards(map[dnsname.FQDN][]netip.Addr{
+		"myws.coder.":      {wsAddr},
+		"apps.myws.coder.": {appsAddr},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, url)
+
+	query := func(t *testing.T, name string, typ dnsmessage.Type) dnsmessage.Message {
+		t.Helper()
+		q := dnsmessage.Message{
+			Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+			Questions: []dnsmessage.Question{{
+				Name:  dnsmessage.MustNewName(name),
+				Type:  typ,
+				Class: dnsmessage.ClassINET,
+			}},
+		}
+		packet, err := q.Pack()
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(packet))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", dnsMessageContentType)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, dnsMessageContentType, res.Header.Get("Content-Type"))
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		var m dnsmessage.Message
+		require.NoError(t, m.Unpack(body))
+		require.EqualValues(t, 42, m.Header.ID)
+		return m
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		t.Parallel()
+		m := query(t, "grafana.myws.coder.", dnsmessage.TypeAAAA)
+		require.Equal(t, dnsmessage.RCodeSuccess, m.Header.RCode)
+		require.Len(t, m.Answers, 1)
+		require.Equal(t, wsAddr.As16(), m.Answers[0].Body.(*dnsmessage.AAAAResource).AAAA)
+	})
+
+	t.Run("LongestSuffix", func(t *testing.T) {
+		t.Parallel()
+		m := query(t, "A.Apps.MyWS.coder.", dnsmessage.TypeAAAA)
+		require.Equal(t, dnsmessage.RCodeSuccess, m.Header.RCode)
+		require.Len(t, m.Answers, 1)
+		require.Equal(t, appsAddr.As16(), m.Answers[0].Body.(*dnsmessage.AAAAResource).AAAA)
+	})
+
+	t.Run("IPv4", func(t *testing.T) {
+		t.Parallel()
+		m := query(t, "grafana.myws.coder.", dnsmessage.TypeA)
+		require.Equal(t, dnsmessage.RCodeSuccess, m.Header.RCode)
+		require.Empty(t, m.Answers)
+	})
+
+	t.Run("NotSubdomain", func(t *testing.T) {
+		t.Parallel()
+		m := query(t, "myws.coder.", dnsmessage.TypeAAAA)
+		require.Equal(t, dnsmessage.RCodeNameError, m.Header.RCode)
+		m = query(t, "other.coder.", dnsmessage.TypeAAAA)
+		require.Equal(t, dnsmessage.RCodeNameError, m.Header.RCode)
+	})
+}
@@ -38,9 +38,27 @@ import (
 //     shipped in Coder v2.16.0, but we forgot to increment the API version. If
 //     you dial for API v2.2, you MAY be connected to a server that supports
 //     ScriptCompleted, but be prepared to process "unsupported" errors.)
+//
+// API v2.4:
+//   - Added support for agent-supervised services via the services field of
+//     the Manifest and the BatchUpdateServiceStatuses RPC on the Agent API.
+//   - Added script dependencies and readiness conditions to
+//     WorkspaceAgentScript in the Manifest, and the SKIPPED timing status to
+//     ScriptCompleted on the Agent API.
+//   - Added TCP, command, and HTTP status, body, and header options to
+//     WorkspaceApp.Healthcheck in the Manifest, and the failure reason to
+//     BatchUpdateAppHealths on the Agent API.
+//   - Added agent self-updates via the agent_update field of the Manifest.
+//   - Added the BatchCreateLogSources RPC on the Agent API, so agents can
+//     forward local log files as log sources.
+//   - Added DNS records with a port via the dns_records field of the
+//     Manifest, and the dns_records field of Agent on the Tailnet API
+//     WorkspaceUpdates RPC.
+//   - Added the BatchUpdateDevcontainerStatuses RPC on the Agent API, so the
+//     state of dev containers is known while the agent is unreachable.
 const (
 	CurrentMajor = 2
-	CurrentMinor = 3
+	CurrentMinor = 4
 )
 
 var CurrentVersion = apiversion.New(CurrentMajor, CurrentMinor)
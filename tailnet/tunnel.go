@@ -3,6 +3,7 @@ package tailnet
 import (
 	"context"
 	"net/netip"
+	"slices"
 
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
@@ -46,6 +47,9 @@ func (c ClientCoordinateeAuth) Authorize(_ context.Context, req *proto.Coordinat
 // AgentCoordinateeAuth disallows all tunnels, since agents are not allowed to initiate their own tunnels
 type AgentCoordinateeAuth struct {
 	ID uuid.UUID
+	// DNSRecordAddrs are the addresses of the agent's DNS records with a
+	// port, which the agent serves in addition to its own addresses.
+	DNSRecordAddrs []netip.Addr
 }
 
 func (a AgentCoordinateeAuth) Authorize(_ context.Context, req *proto.CoordinateRequest) error {
@@ -66,7 +70,8 @@ func (a AgentCoordinateeAuth) Authorize(_ context.Context, req *proto.Coordinate
 
 			if TailscaleServicePrefix.AddrFromUUID(a.ID).Compare(pre.Addr()) != 0 &&
 				CoderServicePrefix.AddrFromUUID(a.ID).Compare(pre.Addr()) != 0 &&
-				legacyWorkspaceAgentIP.Compare(pre.Addr()) != 0 {
+				legacyWorkspaceAgentIP.Compare(pre.Addr()) != 0 &&
+				!slices.Contains(a.DNSRecordAddrs, pre.Addr()) {
 				return xerrors.Errorf("invalid node address, got %s", pre.Addr().String())
 			}
 		}
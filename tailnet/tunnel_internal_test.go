@@ -1,10 +1,14 @@
 package tailnet
 
 import (
+	"context"
+	"net/netip"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/tailnet/proto"
 )
 
 func TestTunnelStore_Bidir(t *testing.T) {
@@ -58,3 +62,26 @@ func TestTunnelStore_TunnelExists(t *testing.T) {
 	require.False(t, uut.tunnelExists(p1, p2))
 	require.False(t, uut.tunnelExists(p2, p1))
 }
+
+func TestAgentCoordinateeAuth_DNSRecordAddrs(t *testing.T) {
+	t.Parallel()
+	agentID := uuid.UUID{1}
+	recordAddr := DNSRecordAddr(agentID, "api")
+	updateSelf := func(addrs ...netip.Addr) *proto.CoordinateRequest {
+		node := &proto.Node{}
+		for _, addr := range addrs {
+			node.Addresses = append(node.Addresses, netip.PrefixFrom(addr, 128).String())
+		}
+		return &proto.CoordinateRequest{UpdateSelf: &proto.CoordinateRequest_UpdateSelf{Node: node}}
+	}
+
+	uut := AgentCoordinateeAuth{ID: agentID, DNSRecordAddrs: []netip.Addr{recordAddr}}
+	require.NoError(t, uut.Authorize(context.Background(), updateSelf(
+		CoderServicePrefix.AddrFromUUID(agentID), recordAddr)))
+	require.Error(t, uut.Authorize(context.Background(), updateSelf(
+		DNSRecordAddr(agentID, "other"))))
+
+	// Without the record, its address is rejected.
+	uut = AgentCoordinateeAuth{ID: agentID}
+	require.Error(t, uut.Authorize(context.Background(), updateSelf(recordAddr)))
+}
@@ -1,6 +1,10 @@
 package vpn
 
-import "tailscale.com/net/dns"
+import (
+	"strings"
+
+	"tailscale.com/net/dns"
+)
 
 func NewDNSConfigurator(t *Tunnel) dns.OSConfigurator {
 	return &dnsManager{tunnel: t}
@@ -45,14 +49,28 @@ func convertDNSConfig(cfg dns.OSConfig) *NetworkSettingsRequest_DNSSettings {
 		searchDomains = append(searchDomains, domain.WithoutTrailingDot())
 	}
 	matchDomains := make([]string, 0, len(cfg.MatchDomains))
+	noSearch := false
 	for _, domain := range cfg.MatchDomains {
 		matchDomains = append(matchDomains, domain.WithoutTrailingDot())
+		// Reverse DNS zones are routed to us when there are wildcard DNS
+		// records, but they must not be searched. In that case, match domains
+		// aren't searched, and the others are added to the search domains.
+		if strings.HasSuffix(string(domain), ".arpa.") {
+			noSearch = true
+		}
+	}
+	if noSearch {
+		for _, domain := range cfg.MatchDomains {
+			if !strings.HasSuffix(string(domain), ".arpa.") {
+				searchDomains = append(searchDomains, domain.WithoutTrailingDot())
+			}
+		}
 	}
 	return &NetworkSettingsRequest_DNSSettings{
 		Servers:              servers,
 		SearchDomains:        searchDomains,
 		DomainName:           "coder",
 		MatchDomains:         matchDomains,
-		MatchDomainsNoSearch: false,
+		MatchDomainsNoSearch: noSearch,
 	}
 }
@@ -40,6 +40,25 @@ func TestConvertDNSConfig(t *testing.T) {
 				MatchDomainsNoSearch: false,
 			},
 		},
+		{
+			name: "ReverseZone",
+			input: dns.OSConfig{
+				Nameservers: []netip.Addr{
+					netip.MustParseAddr("fd7a:115c:a1e0::53"),
+				},
+				MatchDomains: []dnsname.FQDN{
+					"b.2.4.a.a.7.2.6.0.6.d.f.ip6.arpa.",
+					"coder.",
+				},
+			},
+			expected: &NetworkSettingsRequest_DNSSettings{
+				Servers:              []string{"fd7a:115c:a1e0::53"},
+				SearchDomains:        []string{"coder"},
+				DomainName:           "coder",
+				MatchDomains:         []string{"b.2.4.a.a.7.2.6.0.6.d.f.ip6.arpa", "coder"},
+				MatchDomainsNoSearch: true,
+			},
+		},
 		{
 			name: "Empty",
 			input: dns.OSConfig{
@@ -44,6 +44,8 @@ type API struct {
 	*MetadataAPI
 	*LogsAPI
 	*ScriptsAPI
+	*ServicesAPI
+	*DevcontainersAPI
 	*tailnet.DRPCService
 
 	mu sync.Mutex
@@ -78,6 +80,7 @@ type Options struct {
 	Experiments               wirtualsdk.Experiments
 
 	UpdateAgentMetricsFn func(ctx context.Context, labels prometheusmetrics.AgentMetricLabels, metrics []*agentproto.Stats_Metric)
+	AgentUpdateFn        func(ctx context.Context, agent database.WorkspaceAgent) *agentproto.AgentUpdate
 }
 
 func New(opts Options) *API {
@@ -96,6 +99,7 @@ func New(opts Options) *API {
 		Database:                 opts.Database,
 		DerpMapFn:                opts.DerpMapFn,
 		WorkspaceID:              opts.WorkspaceID,
+		AgentUpdateFn:            opts.AgentUpdateFn,
 	}
 
 	api.AnnouncementBannerAPI = &AnnouncementBannerAPI{
@@ -145,6 +149,20 @@ func New(opts Options) *API {
 		Database: opts.Database,
 	}
 
+	api.ServicesAPI = &ServicesAPI{
+		AgentFn:                  api.agent,
+		Database:                 opts.Database,
+		Log:                      opts.Log,
+		PublishWorkspaceUpdateFn: api.publishWorkspaceUpdate,
+	}
+
+	api.DevcontainersAPI = &DevcontainersAPI{
+		AgentFn:                  api.agent,
+		Database:                 opts.Database,
+		Log:                      opts.Log,
+		PublishWorkspaceUpdateFn: api.publishWorkspaceUpdate,
+	}
+
 	api.DRPCService = &tailnet.DRPCService{
 		CoordPtr:                opts.TailnetCoordinator,
 		Logger:                  opts.Log,
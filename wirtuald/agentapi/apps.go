@@ -59,7 +59,7 @@ func (a *AppsAPI) BatchUpdateAppHealths(ctx context.Context, req *agentproto.Bat
 			return nil, xerrors.Errorf("workspace app ID %q not found", updateID)
 		}
 
-		if old.HealthcheckUrl == "" {
+		if old.HealthcheckUrl == "" && old.HealthcheckTcp == "" && old.HealthcheckCommand == "" {
 			return nil, xerrors.Errorf("workspace app %q (%q) does not have healthchecks enabled", updateID, old.Slug)
 		}
 
@@ -78,18 +78,20 @@ func (a *AppsAPI) BatchUpdateAppHealths(ctx context.Context, req *agentproto.Bat
 		}
 
 		// Don't bother updating if the value hasn't changed.
-		if old.Health == newHealth {
+		if old.Health == newHealth && old.HealthReason == update.Reason {
 			continue
 		}
 		old.Health = newHealth
+		old.HealthReason = update.Reason
 
 		newApps = append(newApps, *old)
 	}
 
 	for _, app := range newApps {
 		err = a.Database.UpdateWorkspaceAppHealthByID(ctx, database.UpdateWorkspaceAppHealthByIDParams{
-			ID:     app.ID,
-			Health: app.Health,
+			ID:           app.ID,
+			Health:       app.Health,
+			HealthReason: app.HealthReason,
 		})
 		if err != nil {
 			return nil, xerrors.Errorf("update workspace app health for app %q (%q): %w", err, app.ID, app.Slug)
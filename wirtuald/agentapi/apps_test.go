@@ -36,7 +36,7 @@ func TestBatchUpdateAppHealths(t *testing.T) {
 			AgentID:        agent.ID,
 			Slug:           "code-server-2",
 			DisplayName:    "code-server 2",
-			HealthcheckUrl: "http://localhost:3001",
+			HealthcheckTcp: "localhost:3001",
 			Health:         database.WorkspaceAppHealthHealthy,
 		}
 	)
@@ -51,8 +51,9 @@ func TestBatchUpdateAppHealths(t *testing.T) {
 			Health: database.WorkspaceAppHealthHealthy,
 		}).Return(nil)
 		dbM.EXPECT().UpdateWorkspaceAppHealthByID(gomock.Any(), database.UpdateWorkspaceAppHealthByIDParams{
-			ID:     app2.ID,
-			Health: database.WorkspaceAppHealthUnhealthy,
+			ID:           app2.ID,
+			Health:       database.WorkspaceAppHealthUnhealthy,
+			HealthReason: "dial tcp 127.0.0.1:3001: connect: connection refused",
 		}).Return(nil)
 
 		publishCalled := false
@@ -78,6 +79,7 @@ func TestBatchUpdateAppHealths(t *testing.T) {
 				{
 					Id:     app2.ID[:],
 					Health: agentproto.AppHealth_UNHEALTHY,
+					Reason: "dial tcp 127.0.0.1:3001: connect: connection refused",
 				},
 			},
 		})
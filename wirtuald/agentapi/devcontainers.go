@@ -0,0 +1,93 @@
+package agentapi
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	agentproto "github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wspubsub"
+)
+
+type DevcontainersAPI struct {
+	AgentFn                  func(context.Context) (database.WorkspaceAgent, error)
+	Database                 database.Store
+	Log                      slog.Logger
+	PublishWorkspaceUpdateFn func(context.Context, *database.WorkspaceAgent, wspubsub.WorkspaceEventKind) error
+
+	TimeNowFn func() time.Time // defaults to dbtime.Now()
+}
+
+func (a *DevcontainersAPI) now() time.Time {
+	if a.TimeNowFn != nil {
+		return a.TimeNowFn()
+	}
+	return dbtime.Now()
+}
+
+func (a *DevcontainersAPI) BatchUpdateDevcontainerStatuses(ctx context.Context, req *agentproto.BatchUpdateDevcontainerStatusesRequest) (*agentproto.BatchUpdateDevcontainerStatusesResponse, error) {
+	workspaceAgent, err := a.AgentFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Log.Debug(ctx, "got batch dev container status update",
+		slog.F("agent_id", workspaceAgent.ID.String()),
+		slog.F("statuses", len(req.Statuses)),
+	)
+
+	if len(req.Statuses) == 0 {
+		return &agentproto.BatchUpdateDevcontainerStatusesResponse{}, nil
+	}
+
+	now := a.now()
+	for _, status := range req.Statuses {
+		var state database.WorkspaceAgentDevcontainerState
+		switch status.State {
+		case agentproto.DevcontainerStatus_STOPPED:
+			state = database.WorkspaceAgentDevcontainerStateStopped
+		case agentproto.DevcontainerStatus_STARTING:
+			state = database.WorkspaceAgentDevcontainerStateStarting
+		case agentproto.DevcontainerStatus_RUNNING:
+			state = database.WorkspaceAgentDevcontainerStateRunning
+		case agentproto.DevcontainerStatus_FAILED:
+			state = database.WorkspaceAgentDevcontainerStateFailed
+		default:
+			return nil, xerrors.Errorf("unknown state %q for dev container %q", status.State, status.Name)
+		}
+
+		var changedAt sql.NullTime
+		if status.ChangedAt != nil {
+			changedAt = sql.NullTime{Time: status.ChangedAt.AsTime(), Valid: true}
+		}
+
+		err = a.Database.UpsertWorkspaceAgentDevcontainer(ctx, database.UpsertWorkspaceAgentDevcontainerParams{
+			WorkspaceAgentID: workspaceAgent.ID,
+			Name:             status.Name,
+			WorkspaceFolder:  status.WorkspaceFolder,
+			ConfigPath:       status.ConfigPath,
+			State:            state,
+			ContainerID:      status.ContainerId,
+			Image:            status.Image,
+			Error:            status.Error,
+			StateChangedAt:   changedAt,
+			UpdatedAt:        now,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("upsert workspace agent dev container %q: %w", status.Name, err)
+		}
+	}
+
+	if a.PublishWorkspaceUpdateFn != nil {
+		err = a.PublishWorkspaceUpdateFn(ctx, &workspaceAgent, wspubsub.WorkspaceEventKindAgentDevcontainerUpdate)
+		if err != nil {
+			return nil, xerrors.Errorf("publish workspace update: %w", err)
+		}
+	}
+	return &agentproto.BatchUpdateDevcontainerStatusesResponse{}, nil
+}
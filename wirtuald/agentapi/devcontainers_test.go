@@ -0,0 +1,130 @@
+package agentapi_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	agentproto "github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/agentapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbmock"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wspubsub"
+)
+
+func TestBatchUpdateDevcontainerStatuses(t *testing.T) {
+	t.Parallel()
+
+	agent := database.WorkspaceAgent{
+		ID: uuid.New(),
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			changedAt = dbtime.Now().Add(-time.Minute)
+			now       = dbtime.Now()
+		)
+
+		dbM := dbmock.NewMockStore(gomock.NewController(t))
+		dbM.EXPECT().UpsertWorkspaceAgentDevcontainer(gomock.Any(), database.UpsertWorkspaceAgentDevcontainerParams{
+			WorkspaceAgentID: agent.ID,
+			Name:             "project",
+			WorkspaceFolder:  "/home/coder/project",
+			ConfigPath:       "/home/coder/project/.devcontainer/devcontainer.json",
+			State:            database.WorkspaceAgentDevcontainerStateFailed,
+			ContainerID:      "abc123",
+			Image:            "ubuntu",
+			Error:            "exit status 1",
+			StateChangedAt:   sql.NullTime{Time: changedAt, Valid: true},
+			UpdatedAt:        now,
+		}).Return(nil)
+
+		var publishedKind wspubsub.WorkspaceEventKind
+		api := &agentapi.DevcontainersAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database: dbM,
+			Log:      testutil.Logger(t),
+			PublishWorkspaceUpdateFn: func(_ context.Context, _ *database.WorkspaceAgent, kind wspubsub.WorkspaceEventKind) error {
+				publishedKind = kind
+				return nil
+			},
+			TimeNowFn: func() time.Time {
+				return now
+			},
+		}
+
+		resp, err := api.BatchUpdateDevcontainerStatuses(context.Background(), &agentproto.BatchUpdateDevcontainerStatusesRequest{
+			Statuses: []*agentproto.DevcontainerStatus{
+				{
+					Name:            "project",
+					WorkspaceFolder: "/home/coder/project",
+					ConfigPath:      "/home/coder/project/.devcontainer/devcontainer.json",
+					State:           agentproto.DevcontainerStatus_FAILED,
+					ContainerId:     "abc123",
+					Image:           "ubuntu",
+					Error:           "exit status 1",
+					ChangedAt:       timestamppb.New(changedAt),
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &agentproto.BatchUpdateDevcontainerStatusesResponse{}, resp)
+		require.Equal(t, wspubsub.WorkspaceEventKindAgentDevcontainerUpdate, publishedKind)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		publishCalled := false
+		api := &agentapi.DevcontainersAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database: dbmock.NewMockStore(gomock.NewController(t)),
+			Log:      testutil.Logger(t),
+			PublishWorkspaceUpdateFn: func(context.Context, *database.WorkspaceAgent, wspubsub.WorkspaceEventKind) error {
+				publishCalled = true
+				return nil
+			},
+		}
+
+		resp, err := api.BatchUpdateDevcontainerStatuses(context.Background(), &agentproto.BatchUpdateDevcontainerStatusesRequest{})
+		require.NoError(t, err)
+		require.Equal(t, &agentproto.BatchUpdateDevcontainerStatusesResponse{}, resp)
+		require.False(t, publishCalled)
+	})
+
+	t.Run("UnknownState", func(t *testing.T) {
+		t.Parallel()
+
+		api := &agentapi.DevcontainersAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database: dbmock.NewMockStore(gomock.NewController(t)),
+			Log:      testutil.Logger(t),
+		}
+
+		_, err := api.BatchUpdateDevcontainerStatuses(context.Background(), &agentproto.BatchUpdateDevcontainerStatusesRequest{
+			Statuses: []*agentproto.DevcontainerStatus{
+				{
+					Name:  "project",
+					State: agentproto.DevcontainerStatus_STATE_UNSPECIFIED,
+				},
+			},
+		})
+		require.ErrorContains(t, err, "unknown state")
+	})
+}
@@ -152,3 +152,51 @@ func (a *LogsAPI) BatchCreateLogs(ctx context.Context, req *agentproto.BatchCrea
 
 	return &agentproto.BatchCreateLogsResponse{}, nil
 }
+
+func (a *LogsAPI) BatchCreateLogSources(ctx context.Context, req *agentproto.BatchCreateLogSourcesRequest) (*agentproto.BatchCreateLogSourcesResponse, error) {
+	workspaceAgent, err := a.AgentFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.LogSources) == 0 {
+		return &agentproto.BatchCreateLogSourcesResponse{}, nil
+	}
+
+	// Agents create their log sources again whenever they reconnect, so
+	// sources that already exist are skipped.
+	existing, err := a.Database.GetWorkspaceAgentLogSourcesByAgentIDs(ctx, []uuid.UUID{workspaceAgent.ID})
+	if err != nil {
+		return nil, xerrors.Errorf("get workspace agent log sources: %w", err)
+	}
+	existingIDs := make(map[uuid.UUID]struct{}, len(existing))
+	for _, source := range existing {
+		existingIDs[source.ID] = struct{}{}
+	}
+
+	params := database.InsertWorkspaceAgentLogSourcesParams{
+		WorkspaceAgentID: workspaceAgent.ID,
+		CreatedAt:        a.now(),
+	}
+	for _, source := range req.LogSources {
+		id, err := uuid.FromBytes(source.Id)
+		if err != nil {
+			return nil, xerrors.Errorf("parse log source ID %q: %w", source.Id, err)
+		}
+		if _, ok := existingIDs[id]; ok {
+			continue
+		}
+		existingIDs[id] = struct{}{}
+		params.ID = append(params.ID, id)
+		params.DisplayName = append(params.DisplayName, source.DisplayName)
+		params.Icon = append(params.Icon, source.Icon)
+	}
+	if len(params.ID) == 0 {
+		return &agentproto.BatchCreateLogSourcesResponse{}, nil
+	}
+
+	_, err = a.Database.InsertWorkspaceAgentLogSources(ctx, params)
+	if err != nil && !database.IsUniqueViolation(err, database.UniqueWorkspaceAgentLogSourcesPkey) {
+		return nil, xerrors.Errorf("insert workspace agent log sources: %w", err)
+	}
+	return &agentproto.BatchCreateLogSourcesResponse{}, nil
+}
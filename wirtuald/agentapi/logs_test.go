@@ -427,3 +427,97 @@ func TestBatchCreateLogs(t *testing.T) {
 		require.False(t, publishWorkspaceAgentLogsUpdateCalled)
 	})
 }
+
+func TestBatchCreateLogSources(t *testing.T) {
+	t.Parallel()
+
+	agent := database.WorkspaceAgent{
+		ID: uuid.New(),
+	}
+	existing := database.WorkspaceAgentLogSource{
+		WorkspaceAgentID: agent.ID,
+		CreatedAt:        dbtime.Now(),
+		ID:               uuid.New(),
+		DisplayName:      "/var/log/app.log",
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		dbM := dbmock.NewMockStore(gomock.NewController(t))
+		now := dbtime.Now()
+		api := &agentapi.LogsAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database:  dbM,
+			Log:       testutil.Logger(t),
+			TimeNowFn: func() time.Time { return now },
+		}
+
+		// Only the source that doesn't exist yet is inserted.
+		newID := uuid.New()
+		dbM.EXPECT().GetWorkspaceAgentLogSourcesByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return([]database.WorkspaceAgentLogSource{existing}, nil)
+		dbM.EXPECT().InsertWorkspaceAgentLogSources(gomock.Any(), database.InsertWorkspaceAgentLogSourcesParams{
+			WorkspaceAgentID: agent.ID,
+			CreatedAt:        now,
+			ID:               []uuid.UUID{newID},
+			DisplayName:      []string{"/var/log/db.log"},
+			Icon:             []string{"/emojis/1f4c4.png"},
+		}).Return([]database.WorkspaceAgentLogSource{}, nil)
+
+		resp, err := api.BatchCreateLogSources(context.Background(), &agentproto.BatchCreateLogSourcesRequest{
+			LogSources: []*agentproto.LogSource{
+				{Id: existing.ID[:], DisplayName: existing.DisplayName, Icon: "/emojis/1f4c4.png"},
+				{Id: newID[:], DisplayName: "/var/log/db.log", Icon: "/emojis/1f4c4.png"},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &agentproto.BatchCreateLogSourcesResponse{}, resp)
+	})
+
+	t.Run("AllExist", func(t *testing.T) {
+		t.Parallel()
+
+		dbM := dbmock.NewMockStore(gomock.NewController(t))
+		api := &agentapi.LogsAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database: dbM,
+			Log:      testutil.Logger(t),
+		}
+
+		dbM.EXPECT().GetWorkspaceAgentLogSourcesByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return([]database.WorkspaceAgentLogSource{existing}, nil)
+
+		resp, err := api.BatchCreateLogSources(context.Background(), &agentproto.BatchCreateLogSourcesRequest{
+			LogSources: []*agentproto.LogSource{
+				{Id: existing.ID[:], DisplayName: existing.DisplayName},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &agentproto.BatchCreateLogSourcesResponse{}, resp)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		t.Parallel()
+
+		dbM := dbmock.NewMockStore(gomock.NewController(t))
+		api := &agentapi.LogsAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database: dbM,
+			Log:      testutil.Logger(t),
+		}
+
+		dbM.EXPECT().GetWorkspaceAgentLogSourcesByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return(nil, nil)
+
+		_, err := api.BatchCreateLogSources(context.Background(), &agentproto.BatchCreateLogSourcesRequest{
+			LogSources: []*agentproto.LogSource{
+				{Id: []byte("invalid"), DisplayName: "invalid"},
+			},
+		})
+		require.ErrorContains(t, err, "parse log source ID")
+	})
+}
@@ -3,6 +3,7 @@ package agentapi
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"net/url"
 	"strings"
 	"time"
@@ -34,6 +35,8 @@ type ManifestAPI struct {
 	AgentFn   func(context.Context) (database.WorkspaceAgent, error)
 	Database  database.Store
 	DerpMapFn func() *tailcfg.DERPMap
+	// AgentUpdateFn returns the update offered to the agent, if any.
+	AgentUpdateFn func(context.Context, database.WorkspaceAgent) *agentproto.AgentUpdate
 }
 
 func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifestRequest) (*agentproto.Manifest, error) {
@@ -42,11 +45,13 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 		return nil, err
 	}
 	var (
-		dbApps    []database.WorkspaceApp
-		scripts   []database.WorkspaceAgentScript
-		metadata  []database.WorkspaceAgentMetadatum
-		workspace database.Workspace
-		owner     database.User
+		dbApps     []database.WorkspaceApp
+		scripts    []database.WorkspaceAgentScript
+		services   []database.WorkspaceAgentService
+		metadata   []database.WorkspaceAgentMetadatum
+		dnsRecords []database.WorkspaceAgentDNSRecord
+		workspace  database.Workspace
+		owner      database.User
 	)
 
 	var eg errgroup.Group
@@ -62,6 +67,16 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 		scripts, err = a.Database.GetWorkspaceAgentScriptsByAgentIDs(dbauthz.AsSystemRestricted(ctx), []uuid.UUID{workspaceAgent.ID})
 		return err
 	})
+	eg.Go(func() (err error) {
+		// nolint:gocritic // This is necessary to fetch agent services!
+		services, err = a.Database.GetWorkspaceAgentServicesByAgentIDs(dbauthz.AsSystemRestricted(ctx), []uuid.UUID{workspaceAgent.ID})
+		return err
+	})
+	eg.Go(func() (err error) {
+		// nolint:gocritic // This is necessary to fetch agent DNS records!
+		dnsRecords, err = a.Database.GetWorkspaceAgentDNSRecordsByAgentIDs(dbauthz.AsSystemRestricted(ctx), []uuid.UUID{workspaceAgent.ID})
+		return err
+	})
 	eg.Go(func() (err error) {
 		metadata, err = a.Database.GetWorkspaceAgentMetadata(ctx, database.GetWorkspaceAgentMetadataParams{
 			WorkspaceAgentID: workspaceAgent.ID,
@@ -111,6 +126,16 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 		return nil, xerrors.Errorf("converting workspace apps: %w", err)
 	}
 
+	protoServices, err := dbAgentServicesToProto(services)
+	if err != nil {
+		return nil, xerrors.Errorf("converting workspace agent services: %w", err)
+	}
+
+	var agentUpdate *agentproto.AgentUpdate
+	if a.AgentUpdateFn != nil {
+		agentUpdate = a.AgentUpdateFn(ctx, workspaceAgent)
+	}
+
 	return &agentproto.Manifest{
 		AgentId:                  workspaceAgent.ID[:],
 		AgentName:                workspaceAgent.Name,
@@ -127,8 +152,12 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 
 		DerpMap:  tailnet.DERPMapToProto(a.DerpMapFn()),
 		Scripts:  dbAgentScriptsToProto(scripts),
+		Services: protoServices,
 		Apps:     apps,
 		Metadata: dbAgentMetadataToProtoDescription(metadata),
+
+		AgentUpdate: agentUpdate,
+		DnsRecords:  dbAgentDNSRecordsToProto(dnsRecords),
 	}, nil
 }
 
@@ -145,6 +174,22 @@ func vscodeProxyURI(app appurl.ApplicationURL, accessURL *url.URL, appHost strin
 	return accessURL.Scheme + "://" + strings.ReplaceAll(appHost, "*", app.String())
 }
 
+// dbAgentDNSRecordsToProto returns the records with a port, which are the only
+// ones the agent has to serve.
+func dbAgentDNSRecordsToProto(records []database.WorkspaceAgentDNSRecord) []*agentproto.WorkspaceAgentDNSRecord {
+	var ret []*agentproto.WorkspaceAgentDNSRecord
+	for _, record := range records {
+		if record.Port == 0 {
+			continue
+		}
+		ret = append(ret, &agentproto.WorkspaceAgentDNSRecord{
+			Name: record.Name,
+			Port: record.Port,
+		})
+	}
+	return ret
+}
+
 func dbAgentMetadataToProtoDescription(metadata []database.WorkspaceAgentMetadatum) []*agentproto.WorkspaceAgentMetadata_Description {
 	ret := make([]*agentproto.WorkspaceAgentMetadata_Description, len(metadata))
 	for i, metadatum := range metadata {
@@ -172,6 +217,18 @@ func dbAgentScriptsToProto(scripts []database.WorkspaceAgentScript) []*agentprot
 }
 
 func dbAgentScriptToProto(script database.WorkspaceAgentScript) *agentproto.WorkspaceAgentScript {
+	var dependsOn [][]byte
+	for _, dependency := range script.DependsOn {
+		dependsOn = append(dependsOn, dependency[:])
+	}
+	var ready *agentproto.WorkspaceAgentScript_Readiness
+	if script.ReadyPort != 0 || script.ReadyFile != "" {
+		ready = &agentproto.WorkspaceAgentScript_Readiness{
+			Port:    script.ReadyPort,
+			File:    script.ReadyFile,
+			Timeout: durationpb.New(time.Duration(script.ReadyTimeoutSeconds) * time.Second),
+		}
+	}
 	return &agentproto.WorkspaceAgentScript{
 		Id:               script.ID[:],
 		LogSourceId:      script.LogSourceID[:],
@@ -182,7 +239,54 @@ func dbAgentScriptToProto(script database.WorkspaceAgentScript) *agentproto.Work
 		RunOnStop:        script.RunOnStop,
 		StartBlocksLogin: script.StartBlocksLogin,
 		Timeout:          durationpb.New(time.Duration(script.TimeoutSeconds) * time.Second),
+		DisplayName:      script.DisplayName,
+		DependsOn:        dependsOn,
+		Ready:            ready,
+	}
+}
+
+func dbAgentServicesToProto(services []database.WorkspaceAgentService) ([]*agentproto.WorkspaceAgentService, error) {
+	ret := make([]*agentproto.WorkspaceAgentService, len(services))
+	for i, service := range services {
+		var err error
+		ret[i], err = dbAgentServiceToProto(service)
+		if err != nil {
+			return nil, xerrors.Errorf("parse service %v (%q): %w", i, service.Name, err)
+		}
+	}
+	return ret, nil
+}
+
+func dbAgentServiceToProto(service database.WorkspaceAgentService) (*agentproto.WorkspaceAgentService, error) {
+	restartPolicyRaw, ok := agentproto.WorkspaceAgentService_RestartPolicy_value[strings.ToUpper(strings.ReplaceAll(string(service.RestartPolicy), "-", "_"))]
+	if !ok {
+		return nil, xerrors.Errorf("unknown restart policy: %q", service.RestartPolicy)
+	}
+
+	var env map[string]string
+	if len(service.Env) > 0 {
+		err := json.Unmarshal(service.Env, &env)
+		if err != nil {
+			return nil, xerrors.Errorf("unmarshal env: %w", err)
+		}
 	}
+
+	return &agentproto.WorkspaceAgentService{
+		Id:                service.ID[:],
+		Name:              service.Name,
+		DisplayName:       service.DisplayName,
+		Command:           service.Command,
+		Directory:         service.Directory,
+		Env:               env,
+		RestartPolicy:     agentproto.WorkspaceAgentService_RestartPolicy(restartPolicyRaw),
+		RestartBackoff:    durationpb.New(time.Duration(service.RestartBackoffSeconds) * time.Second),
+		MaxRestartBackoff: durationpb.New(time.Duration(service.MaxRestartBackoffSeconds) * time.Second),
+		Healthcheck: &agentproto.WorkspaceApp_Healthcheck{
+			Url:       service.HealthcheckUrl,
+			Interval:  durationpb.New(time.Duration(service.HealthcheckInterval) * time.Second),
+			Threshold: service.HealthcheckThreshold,
+		},
+	}, nil
 }
 
 func dbAppsToProto(dbApps []database.WorkspaceApp, agent database.WorkspaceAgent, ownerName string, workspace database.Workspace) ([]*agentproto.WorkspaceApp, error) {
@@ -208,6 +312,14 @@ func dbAppToProto(dbApp database.WorkspaceApp, agent database.WorkspaceAgent, ow
 		return nil, xerrors.Errorf("unknown app health: %q", dbApp.SharingLevel)
 	}
 
+	var headers map[string]string
+	if len(dbApp.HealthcheckHeaders) > 0 {
+		err := json.Unmarshal(dbApp.HealthcheckHeaders, &headers)
+		if err != nil {
+			return nil, xerrors.Errorf("unmarshal healthcheck headers: %w", err)
+		}
+	}
+
 	return &agentproto.WorkspaceApp{
 		Id:            dbApp.ID[:],
 		Url:           dbApp.Url.String,
@@ -220,9 +332,14 @@ func dbAppToProto(dbApp database.WorkspaceApp, agent database.WorkspaceAgent, ow
 		SubdomainName: db2sdk.AppSubdomain(dbApp, agent.Name, workspace.Name, ownerName),
 		SharingLevel:  agentproto.WorkspaceApp_SharingLevel(sharingLevelRaw),
 		Healthcheck: &agentproto.WorkspaceApp_Healthcheck{
-			Url:       dbApp.HealthcheckUrl,
-			Interval:  durationpb.New(time.Duration(dbApp.HealthcheckInterval) * time.Second),
-			Threshold: dbApp.HealthcheckThreshold,
+			Url:              dbApp.HealthcheckUrl,
+			Interval:         durationpb.New(time.Duration(dbApp.HealthcheckInterval) * time.Second),
+			Threshold:        dbApp.HealthcheckThreshold,
+			Tcp:              dbApp.HealthcheckTcp,
+			Command:          dbApp.HealthcheckCommand,
+			ExpectedStatuses: dbApp.HealthcheckExpectedStatuses,
+			BodyRegex:        dbApp.HealthcheckBodyRegex,
+			Headers:          headers,
 		},
 		Health: agentproto.WorkspaceApp_Health(healthRaw),
 		Hidden: dbApp.Hidden,
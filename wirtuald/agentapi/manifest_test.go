@@ -62,19 +62,22 @@ func TestGetManifest(t *testing.T) {
 		}
 		apps = []database.WorkspaceApp{
 			{
-				ID:                   uuid.New(),
-				Url:                  sql.NullString{String: "http://localhost:1234", Valid: true},
-				External:             false,
-				Slug:                 "cool-app-1",
-				DisplayName:          "app 1",
-				Command:              sql.NullString{String: "cool command", Valid: true},
-				Icon:                 "/icon.png",
-				Subdomain:            true,
-				SharingLevel:         database.AppSharingLevelAuthenticated,
-				Health:               database.WorkspaceAppHealthHealthy,
-				HealthcheckUrl:       "http://localhost:1234/health",
-				HealthcheckInterval:  10,
-				HealthcheckThreshold: 3,
+				ID:                          uuid.New(),
+				Url:                         sql.NullString{String: "http://localhost:1234", Valid: true},
+				External:                    false,
+				Slug:                        "cool-app-1",
+				DisplayName:                 "app 1",
+				Command:                     sql.NullString{String: "cool command", Valid: true},
+				Icon:                        "/icon.png",
+				Subdomain:                   true,
+				SharingLevel:                database.AppSharingLevelAuthenticated,
+				Health:                      database.WorkspaceAppHealthHealthy,
+				HealthcheckUrl:              "http://localhost:1234/health",
+				HealthcheckInterval:         10,
+				HealthcheckThreshold:        3,
+				HealthcheckExpectedStatuses: []int32{200},
+				HealthcheckBodyRegex:        "ok",
+				HealthcheckHeaders:          json.RawMessage(`{"Authorization":"Bearer token"}`),
 			},
 			{
 				ID:           uuid.New(),
@@ -106,9 +109,10 @@ func TestGetManifest(t *testing.T) {
 				Hidden:               true,
 			},
 		}
-		scripts = []database.WorkspaceAgentScript{
+		firstScriptID = uuid.New()
+		scripts       = []database.WorkspaceAgentScript{
 			{
-				ID:               uuid.New(),
+				ID:               firstScriptID,
 				WorkspaceAgentID: agent.ID,
 				LogSourceID:      uuid.New(),
 				LogPath:          "/cool/log/path/1",
@@ -120,16 +124,36 @@ func TestGetManifest(t *testing.T) {
 				TimeoutSeconds:   60,
 			},
 			{
-				ID:               uuid.New(),
-				WorkspaceAgentID: agent.ID,
-				LogSourceID:      uuid.New(),
-				LogPath:          "/cool/log/path/2",
-				Script:           "cool script 2",
-				Cron:             "",
-				StartBlocksLogin: false,
-				RunOnStart:       false,
-				RunOnStop:        true,
-				TimeoutSeconds:   30,
+				ID:                  uuid.New(),
+				WorkspaceAgentID:    agent.ID,
+				LogSourceID:         uuid.New(),
+				LogPath:             "/cool/log/path/2",
+				Script:              "cool script 2",
+				Cron:                "",
+				StartBlocksLogin:    false,
+				RunOnStart:          false,
+				RunOnStop:           true,
+				TimeoutSeconds:      30,
+				DependsOn:           []uuid.UUID{firstScriptID},
+				ReadyPort:           8080,
+				ReadyTimeoutSeconds: 10,
+			},
+		}
+		services = []database.WorkspaceAgentService{
+			{
+				ID:                       uuid.New(),
+				WorkspaceAgentID:         agent.ID,
+				Name:                     "web",
+				DisplayName:              "Web",
+				Command:                  "npm run dev",
+				Directory:                "/home/coder/app",
+				Env:                      []byte(`{"PORT":"3000"}`),
+				RestartPolicy:            database.WorkspaceAgentServiceRestartPolicyOnFailure,
+				RestartBackoffSeconds:    2,
+				MaxRestartBackoffSeconds: 60,
+				HealthcheckUrl:           "http://localhost:3000",
+				HealthcheckInterval:      5,
+				HealthcheckThreshold:     3,
 			},
 		}
 		metadata = []database.WorkspaceAgentMetadatum{
@@ -181,9 +205,12 @@ func TestGetManifest(t *testing.T) {
 				SubdomainName: fmt.Sprintf("%s--%s--%s--%s", apps[0].Slug, agent.Name, workspace.Name, owner.Username),
 				SharingLevel:  agentproto.WorkspaceApp_AUTHENTICATED,
 				Healthcheck: &agentproto.WorkspaceApp_Healthcheck{
-					Url:       apps[0].HealthcheckUrl,
-					Interval:  durationpb.New(time.Duration(apps[0].HealthcheckInterval) * time.Second),
-					Threshold: apps[0].HealthcheckThreshold,
+					Url:              apps[0].HealthcheckUrl,
+					Interval:         durationpb.New(time.Duration(apps[0].HealthcheckInterval) * time.Second),
+					Threshold:        apps[0].HealthcheckThreshold,
+					ExpectedStatuses: []int32{200},
+					BodyRegex:        "ok",
+					Headers:          map[string]string{"Authorization": "Bearer token"},
 				},
 				Health: agentproto.WorkspaceApp_HEALTHY,
 				Hidden: false,
@@ -249,8 +276,39 @@ func TestGetManifest(t *testing.T) {
 				RunOnStop:        scripts[1].RunOnStop,
 				StartBlocksLogin: scripts[1].StartBlocksLogin,
 				Timeout:          durationpb.New(time.Duration(scripts[1].TimeoutSeconds) * time.Second),
+				DependsOn:        [][]byte{scripts[0].ID[:]},
+				Ready: &agentproto.WorkspaceAgentScript_Readiness{
+					Port:    scripts[1].ReadyPort,
+					Timeout: durationpb.New(time.Duration(scripts[1].ReadyTimeoutSeconds) * time.Second),
+				},
 			},
 		}
+		protoServices = []*agentproto.WorkspaceAgentService{
+			{
+				Id:                services[0].ID[:],
+				Name:              services[0].Name,
+				DisplayName:       services[0].DisplayName,
+				Command:           services[0].Command,
+				Directory:         services[0].Directory,
+				Env:               map[string]string{"PORT": "3000"},
+				RestartPolicy:     agentproto.WorkspaceAgentService_ON_FAILURE,
+				RestartBackoff:    durationpb.New(2 * time.Second),
+				MaxRestartBackoff: durationpb.New(time.Minute),
+				Healthcheck: &agentproto.WorkspaceApp_Healthcheck{
+					Url:       services[0].HealthcheckUrl,
+					Interval:  durationpb.New(5 * time.Second),
+					Threshold: services[0].HealthcheckThreshold,
+				},
+			},
+		}
+		dnsRecords = []database.WorkspaceAgentDNSRecord{
+			{WorkspaceAgentID: agent.ID, Name: "api", Port: 8080},
+			// Records without a port are not served by the agent.
+			{WorkspaceAgentID: agent.ID, Name: "*.apps"},
+		}
+		protoDNSRecords = []*agentproto.WorkspaceAgentDNSRecord{
+			{Name: "api", Port: 8080},
+		}
 		protoMetadata = []*agentproto.WorkspaceAgentMetadata_Description{
 			{
 				DisplayName: metadata[0].DisplayName,
@@ -295,6 +353,8 @@ func TestGetManifest(t *testing.T) {
 
 		mDB.EXPECT().GetWorkspaceAppsByAgentID(gomock.Any(), agent.ID).Return(apps, nil)
 		mDB.EXPECT().GetWorkspaceAgentScriptsByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return(scripts, nil)
+		mDB.EXPECT().GetWorkspaceAgentServicesByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return(services, nil)
+		mDB.EXPECT().GetWorkspaceAgentDNSRecordsByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return(dnsRecords, nil)
 		mDB.EXPECT().GetWorkspaceAgentMetadata(gomock.Any(), database.GetWorkspaceAgentMetadataParams{
 			WorkspaceAgentID: agent.ID,
 			Keys:             nil, // all
@@ -323,8 +383,11 @@ func TestGetManifest(t *testing.T) {
 			// did for apps and metadata.
 			DerpMap:  tailnet.DERPMapToProto(derpMapFn()),
 			Scripts:  protoScripts,
+			Services: protoServices,
 			Apps:     protoApps,
 			Metadata: protoMetadata,
+
+			DnsRecords: protoDNSRecords,
 		}
 
 		// Log got and expected with spew.
@@ -360,6 +423,8 @@ func TestGetManifest(t *testing.T) {
 
 		mDB.EXPECT().GetWorkspaceAppsByAgentID(gomock.Any(), agent.ID).Return(apps, nil)
 		mDB.EXPECT().GetWorkspaceAgentScriptsByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return(scripts, nil)
+		mDB.EXPECT().GetWorkspaceAgentServicesByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return(services, nil)
+		mDB.EXPECT().GetWorkspaceAgentDNSRecordsByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return(nil, nil)
 		mDB.EXPECT().GetWorkspaceAgentMetadata(gomock.Any(), database.GetWorkspaceAgentMetadataParams{
 			WorkspaceAgentID: agent.ID,
 			Keys:             nil, // all
@@ -388,6 +453,7 @@ func TestGetManifest(t *testing.T) {
 			// did for apps and metadata.
 			DerpMap:  tailnet.DERPMapToProto(derpMapFn()),
 			Scripts:  protoScripts,
+			Services: protoServices,
 			Apps:     protoApps,
 			Metadata: protoMetadata,
 		}
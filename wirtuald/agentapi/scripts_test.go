@@ -73,6 +73,16 @@ func TestScriptCompleted(t *testing.T) {
 				ExitCode: 1,
 			},
 		},
+		{
+			scriptID: uuid.New(),
+			timing: &agentproto.Timing{
+				Stage:    agentproto.Timing_START,
+				Start:    timestamppb.New(dbtime.Now()),
+				End:      timestamppb.New(dbtime.Now()),
+				Status:   agentproto.Timing_SKIPPED,
+				ExitCode: 0,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -120,6 +130,8 @@ func protoScriptTimingStatusToDatabase(stage agentproto.Timing_Status) database.
 		dbStatus = database.WorkspaceAgentScriptTimingStatusTimedOut
 	case agentproto.Timing_PIPES_LEFT_OPEN:
 		dbStatus = database.WorkspaceAgentScriptTimingStatusPipesLeftOpen
+	case agentproto.Timing_SKIPPED:
+		dbStatus = database.WorkspaceAgentScriptTimingStatusSkipped
 	}
 	return dbStatus
 }
@@ -0,0 +1,88 @@
+package agentapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	agentproto "github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wspubsub"
+)
+
+type ServicesAPI struct {
+	AgentFn                  func(context.Context) (database.WorkspaceAgent, error)
+	Database                 database.Store
+	Log                      slog.Logger
+	PublishWorkspaceUpdateFn func(context.Context, *database.WorkspaceAgent, wspubsub.WorkspaceEventKind) error
+}
+
+func (a *ServicesAPI) BatchUpdateServiceStatuses(ctx context.Context, req *agentproto.BatchUpdateServiceStatusesRequest) (*agentproto.BatchUpdateServiceStatusesResponse, error) {
+	workspaceAgent, err := a.AgentFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Log.Debug(ctx, "got batch service status update",
+		slog.F("agent_id", workspaceAgent.ID.String()),
+		slog.F("statuses", len(req.Statuses)),
+	)
+
+	if len(req.Statuses) == 0 {
+		return &agentproto.BatchUpdateServiceStatusesResponse{}, nil
+	}
+
+	for _, status := range req.Statuses {
+		serviceID, err := uuid.FromBytes(status.Id)
+		if err != nil {
+			return nil, xerrors.Errorf("parse workspace agent service ID %q: %w", status.Id, err)
+		}
+
+		var state database.WorkspaceAgentServiceState
+		switch status.State {
+		case agentproto.ServiceStatus_STARTING:
+			state = database.WorkspaceAgentServiceStateStarting
+		case agentproto.ServiceStatus_RUNNING:
+			state = database.WorkspaceAgentServiceStateRunning
+		case agentproto.ServiceStatus_UNHEALTHY:
+			state = database.WorkspaceAgentServiceStateUnhealthy
+		case agentproto.ServiceStatus_BACKOFF:
+			state = database.WorkspaceAgentServiceStateBackoff
+		case agentproto.ServiceStatus_EXITED:
+			state = database.WorkspaceAgentServiceStateExited
+		case agentproto.ServiceStatus_FAILED:
+			state = database.WorkspaceAgentServiceStateFailed
+		default:
+			return nil, xerrors.Errorf("unknown state %q for service %q", status.State, serviceID)
+		}
+
+		var changedAt sql.NullTime
+		if status.ChangedAt != nil {
+			changedAt = sql.NullTime{Time: status.ChangedAt.AsTime(), Valid: true}
+		}
+
+		err = a.Database.UpdateWorkspaceAgentServiceStatusByID(ctx, database.UpdateWorkspaceAgentServiceStatusByIDParams{
+			ID:               serviceID,
+			WorkspaceAgentID: workspaceAgent.ID,
+			State:            state,
+			RestartCount:     status.RestartCount,
+			ExitCode:         status.ExitCode,
+			StatusMessage:    status.Message,
+			StateChangedAt:   changedAt,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("update workspace agent service status for service %q: %w", serviceID, err)
+		}
+	}
+
+	if a.PublishWorkspaceUpdateFn != nil {
+		err = a.PublishWorkspaceUpdateFn(ctx, &workspaceAgent, wspubsub.WorkspaceEventKindAgentServiceUpdate)
+		if err != nil {
+			return nil, xerrors.Errorf("publish workspace update: %w", err)
+		}
+	}
+	return &agentproto.BatchUpdateServiceStatusesResponse{}, nil
+}
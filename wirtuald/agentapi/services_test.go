@@ -0,0 +1,124 @@
+package agentapi_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	agentproto "github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/agentapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbmock"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wspubsub"
+)
+
+func TestBatchUpdateServiceStatuses(t *testing.T) {
+	t.Parallel()
+
+	agent := database.WorkspaceAgent{
+		ID: uuid.New(),
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			serviceID = uuid.New()
+			now       = dbtime.Now()
+		)
+
+		dbM := dbmock.NewMockStore(gomock.NewController(t))
+		dbM.EXPECT().UpdateWorkspaceAgentServiceStatusByID(gomock.Any(), database.UpdateWorkspaceAgentServiceStatusByIDParams{
+			ID:               serviceID,
+			WorkspaceAgentID: agent.ID,
+			State:            database.WorkspaceAgentServiceStateBackoff,
+			RestartCount:     2,
+			ExitCode:         1,
+			StatusMessage:    "exit status 1",
+			StateChangedAt:   sql.NullTime{Time: now, Valid: true},
+		}).Return(nil)
+
+		var publishedKind wspubsub.WorkspaceEventKind
+		api := &agentapi.ServicesAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database: dbM,
+			Log:      testutil.Logger(t),
+			PublishWorkspaceUpdateFn: func(_ context.Context, _ *database.WorkspaceAgent, kind wspubsub.WorkspaceEventKind) error {
+				publishedKind = kind
+				return nil
+			},
+		}
+
+		resp, err := api.BatchUpdateServiceStatuses(context.Background(), &agentproto.BatchUpdateServiceStatusesRequest{
+			Statuses: []*agentproto.ServiceStatus{
+				{
+					Id:           serviceID[:],
+					State:        agentproto.ServiceStatus_BACKOFF,
+					RestartCount: 2,
+					ExitCode:     1,
+					Message:      "exit status 1",
+					ChangedAt:    timestamppb.New(now),
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &agentproto.BatchUpdateServiceStatusesResponse{}, resp)
+		require.Equal(t, wspubsub.WorkspaceEventKindAgentServiceUpdate, publishedKind)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		publishCalled := false
+		api := &agentapi.ServicesAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database: dbmock.NewMockStore(gomock.NewController(t)),
+			Log:      testutil.Logger(t),
+			PublishWorkspaceUpdateFn: func(context.Context, *database.WorkspaceAgent, wspubsub.WorkspaceEventKind) error {
+				publishCalled = true
+				return nil
+			},
+		}
+
+		resp, err := api.BatchUpdateServiceStatuses(context.Background(), &agentproto.BatchUpdateServiceStatusesRequest{})
+		require.NoError(t, err)
+		require.Equal(t, &agentproto.BatchUpdateServiceStatusesResponse{}, resp)
+		require.False(t, publishCalled)
+	})
+
+	t.Run("UnknownState", func(t *testing.T) {
+		t.Parallel()
+
+		api := &agentapi.ServicesAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			Database: dbmock.NewMockStore(gomock.NewController(t)),
+			Log:      testutil.Logger(t),
+		}
+
+		id := uuid.New()
+		_, err := api.BatchUpdateServiceStatuses(context.Background(), &agentproto.BatchUpdateServiceStatusesRequest{
+			Statuses: []*agentproto.ServiceStatus{
+				{
+					Id:        id[:],
+					State:     agentproto.ServiceStatus_STATE_UNSPECIFIED,
+					ChangedAt: timestamppb.New(time.Now()),
+				},
+			},
+		})
+		require.ErrorContains(t, err, "unknown state")
+	})
+}
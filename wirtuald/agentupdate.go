@@ -0,0 +1,125 @@
+package wirtuald
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	agentproto "github.com/onchainengineering/hmi-wirtual/agent/proto"
+	"github.com/onchainengineering/hmi-wirtual/buildinfo"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+)
+
+// agentUpdate returns the update offered to an agent older than the server,
+// or nil if the deployment doesn't offer updates or the binary for the
+// platform of the agent isn't signed.
+func (api *API) agentUpdate(ctx context.Context, agent database.WorkspaceAgent) *agentproto.AgentUpdate {
+	if !api.DeploymentValues.AgentAutoUpdate.Value() {
+		return nil
+	}
+	version := buildinfo.Version()
+	if buildinfo.IsDev() || !semver.IsValid(agent.Version) || semver.Compare(agent.Version, version) >= 0 {
+		return nil
+	}
+	// Agents on Windows can't replace themselves while running.
+	if agent.OperatingSystem == "windows" {
+		return nil
+	}
+
+	name := fmt.Sprintf("coder-%s-%s", agent.OperatingSystem, agent.Architecture)
+	binary, err := api.agentBinaries.get(name)
+	if err != nil {
+		api.Logger.Debug(ctx, "agent update unavailable",
+			slog.F("binary", name),
+			slog.F("agent_version", agent.Version),
+			slog.Error(err),
+		)
+		return nil
+	}
+	return &agentproto.AgentUpdate{
+		Version:   version,
+		Os:        agent.OperatingSystem,
+		Arch:      agent.Architecture,
+		Url:       api.AccessURL.JoinPath("bin", name).String(),
+		Sha256:    binary.sha256,
+		Signature: binary.signature,
+	}
+}
+
+// agentBinaries reads the checksums and signatures of the agent binaries
+// served from /bin. The signature of a binary is read from a file with the
+// same name and a .sig extension, which holds the base64-encoded ed25519
+// signature of the manifest of the binary, see agentupdate.Manifest.
+type agentBinaries struct {
+	binFS http.FileSystem
+
+	mu       sync.Mutex
+	binaries map[string]agentBinary
+}
+
+type agentBinary struct {
+	sha256    []byte
+	signature []byte
+}
+
+func newAgentBinaries(binFS http.FileSystem) *agentBinaries {
+	return &agentBinaries{
+		binFS:    binFS,
+		binaries: map[string]agentBinary{},
+	}
+}
+
+func (b *agentBinaries) get(name string) (agentBinary, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if binary, ok := b.binaries[name]; ok {
+		return binary, nil
+	}
+
+	// The signature is read first, as hashing is only worth it for signed
+	// binaries.
+	sigFile, err := b.binFS.Open(name + ".sig")
+	if err != nil {
+		return agentBinary{}, xerrors.Errorf("open signature: %w", err)
+	}
+	defer sigFile.Close()
+	encoded, err := io.ReadAll(io.LimitReader(sigFile, 1024))
+	if err != nil {
+		return agentBinary{}, xerrors.Errorf("read signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return agentBinary{}, xerrors.Errorf("decode signature: %w", err)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return agentBinary{}, xerrors.Errorf("signature is %d bytes, expected %d", len(signature), ed25519.SignatureSize)
+	}
+
+	file, err := b.binFS.Open(name)
+	if err != nil {
+		return agentBinary{}, xerrors.Errorf("open binary: %w", err)
+	}
+	defer file.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return agentBinary{}, xerrors.Errorf("hash binary: %w", err)
+	}
+
+	binary := agentBinary{
+		sha256:    h.Sum(nil),
+		signature: signature,
+	}
+	b.binaries[name] = binary
+	return binary, nil
+}
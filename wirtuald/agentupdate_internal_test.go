@@ -0,0 +1,48 @@
+package wirtuald
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/agent/agentupdate"
+)
+
+func TestAgentBinaries(t *testing.T) {
+	t.Parallel()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	binary := []byte("agent binary")
+	sum := sha256.Sum256(binary)
+	signature := ed25519.Sign(privateKey, agentupdate.Manifest("v2.1.0", "linux", "amd64", sum[:]))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "coder-linux-amd64"), binary, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "coder-linux-amd64.sig"), []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "coder-linux-arm64"), binary, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "coder-darwin-arm64"), binary, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "coder-darwin-arm64.sig"), []byte("not a signature"), 0o600))
+
+	binaries := newAgentBinaries(http.Dir(dir))
+
+	got, err := binaries.get("coder-linux-amd64")
+	require.NoError(t, err)
+	require.Equal(t, sum[:], got.sha256)
+	require.Equal(t, signature, got.signature)
+
+	// Unsigned binaries aren't offered as updates.
+	_, err = binaries.get("coder-linux-arm64")
+	require.ErrorContains(t, err, "open signature")
+	_, err = binaries.get("coder-darwin-arm64")
+	require.ErrorContains(t, err, "decode signature")
+	_, err = binaries.get("coder-windows-amd64.exe")
+	require.Error(t, err)
+}
@@ -3991,6 +3991,36 @@ const docTemplate = `{
                 }
             }
         },
+        "/templategitsources/{template}/webhook": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Receive template git source webhook",
+                "operationId": "receive-template-git-source-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/templates": {
             "get": {
                 "security": [
@@ -4304,6 +4334,148 @@ const docTemplate = `{
                 }
             }
         },
+        "/templates/{template}/git-source": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template git source",
+                "operationId": "get-template-git-source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateGitSource"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update template git source",
+                "operationId": "update-template-git-source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update template git source request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateGitSourceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateGitSource"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Delete template git source",
+                "operationId": "delete-template-git-source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/templates/{template}/git-source/sync": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Sync template from git",
+                "operationId": "sync-template-from-git",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/templates/{template}/versions": {
             "get": {
                 "security": [
@@ -4654,6 +4826,49 @@ const docTemplate = `{
                 }
             }
         },
+        "/templateversions/{templateversion}/diff/{target}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Compare template versions",
+                "operationId": "compare-template-versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID to compare from",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID to compare to",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersionDiff"
+                        }
+                    }
+                }
+            }
+        },
         "/templateversions/{templateversion}/dry-run": {
             "post": {
                 "security": [
@@ -7298,7 +7513,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/listening-ports": {
+        "/workspaceagents/{workspaceagent}/devcontainers": {
             "get": {
                 "security": [
                     {
@@ -7311,8 +7526,8 @@ const docTemplate = `{
                 "tags": [
                     "Agents"
                 ],
-                "summary": "Get listening ports for workspace agent",
-                "operationId": "get-listening-ports-for-workspace-agent",
+                "summary": "Get dev containers for workspace agent",
+                "operationId": "get-dev-containers-for-workspace-agent",
                 "parameters": [
                     {
                         "type": "string",
@@ -7327,27 +7542,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceAgentListeningPortsResponse"
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentDevcontainersResponse"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/logs": {
-            "get": {
+        "/workspaceagents/{workspaceagent}/exec": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Agents"
                 ],
-                "summary": "Get logs by workspace agent",
-                "operationId": "get-logs-by-workspace-agent",
+                "summary": "Run command in workspace agent",
+                "operationId": "run-command-in-workspace-agent",
                 "parameters": [
                     {
                         "type": "string",
@@ -7358,55 +7576,40 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Before log id",
-                        "name": "before",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "After log id",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Follow log stream",
-                        "name": "follow",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Disable compression for WebSocket connection",
-                        "name": "no_compression",
-                        "in": "query"
+                        "description": "Command to run",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentExecRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceAgentLog"
-                            }
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentExecEvent"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/pty": {
+        "/workspaceagents/{workspaceagent}/listening-ports": {
             "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Agents"
                 ],
-                "summary": "Open PTY to workspace agent",
-                "operationId": "open-pty-to-workspace-agent",
+                "summary": "Get listening ports for workspace agent",
+                "operationId": "get-listening-ports-for-workspace-agent",
                 "parameters": [
                     {
                         "type": "string",
@@ -7418,13 +7621,16 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentListeningPortsResponse"
+                        }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/startup-logs": {
+        "/workspaceagents/{workspaceagent}/logs": {
             "get": {
                 "security": [
                     {
@@ -7437,8 +7643,8 @@ const docTemplate = `{
                 "tags": [
                     "Agents"
                 ],
-                "summary": "Removed: Get logs by workspace agent",
-                "operationId": "removed-get-logs-by-workspace-agent",
+                "summary": "Get logs by workspace agent",
+                "operationId": "get-logs-by-workspace-agent",
                 "parameters": [
                     {
                         "type": "string",
@@ -7471,10 +7677,175 @@ const docTemplate = `{
                         "description": "Disable compression for WebSocket connection",
                         "name": "no_compression",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only return logs of these log sources",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "trace",
+                            "debug",
+                            "info",
+                            "warn",
+                            "error"
+                        ],
+                        "type": "string",
+                        "description": "Minimum log level",
+                        "name": "level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Only return logs created after this time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of logs to return, ignored when following",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceAgentLog"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/pty": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Open PTY to workspace agent",
+                "operationId": "open-pty-to-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/services": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get services for workspace agent",
+                "operationId": "get-services-for-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceAgentService"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/startup-logs": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Removed: Get logs by workspace agent",
+                "operationId": "removed-get-logs-by-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before log id",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After log id",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Disable compression for WebSocket connection",
+                        "name": "no_compression",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
@@ -8541,6 +8912,41 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/{workspace}/drift": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Get workspace drift",
+                "operationId": "get-workspace-drift",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceDrift"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{workspace}/extend": {
             "put": {
                 "security": [
@@ -8798,7 +9204,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/workspaces/{workspace}/timings": {
+        "/workspaces/{workspace}/states": {
             "get": {
                 "security": [
                     {
@@ -8811,8 +9217,8 @@ const docTemplate = `{
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Get workspace timings by ID",
-                "operationId": "get-workspace-timings-by-id",
+                "summary": "Get workspace state history",
+                "operationId": "get-workspace-state-history",
                 "parameters": [
                     {
                         "type": "string",
@@ -8827,27 +9233,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceBuildTimings"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceState"
+                            }
                         }
                     }
                 }
             }
         },
-        "/workspaces/{workspace}/ttl": {
-            "put": {
+        "/workspaces/{workspace}/states/diff": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Update workspace TTL by ID",
-                "operationId": "update-workspace-ttl-by-id",
+                "summary": "Compare workspace states",
+                "operationId": "compare-workspace-states",
                 "parameters": [
                     {
                         "type": "string",
@@ -8858,23 +9267,31 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Workspace TTL update request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateWorkspaceTTLRequest"
-                        }
+                        "type": "integer",
+                        "description": "Build number of the state to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Build number of the state to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceStateDiff"
+                        }
                     }
                 }
             }
         },
-        "/workspaces/{workspace}/usage": {
+        "/workspaces/{workspace}/states/restore": {
             "post": {
                 "security": [
                     {
@@ -8884,11 +9301,14 @@ const docTemplate = `{
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Post Workspace Usage by ID",
-                "operationId": "post-workspace-usage-by-id",
+                "summary": "Restore workspace state",
+                "operationId": "restore-workspace-state",
                 "parameters": [
                     {
                         "type": "string",
@@ -8899,22 +9319,26 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Post workspace usage request",
+                        "description": "Restore workspace state request",
                         "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.PostWorkspaceUsageRequest"
+                            "$ref": "#/definitions/codersdk.RestoreWorkspaceStateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                        }
                     }
                 }
             }
         },
-        "/workspaces/{workspace}/watch": {
+        "/workspaces/{workspace}/timings": {
             "get": {
                 "security": [
                     {
@@ -8922,13 +9346,13 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Watch workspace by ID",
-                "operationId": "watch-workspace-by-id",
+                "summary": "Get workspace timings by ID",
+                "operationId": "get-workspace-timings-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -8943,22 +9367,138 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.WorkspaceBuildTimings"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "agentsdk.AWSInstanceIdentityToken": {
-            "type": "object",
-            "required": [
-                "document",
-                "signature"
-            ],
-            "properties": {
-                "document": {
+        },
+        "/workspaces/{workspace}/ttl": {
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Update workspace TTL by ID",
+                "operationId": "update-workspace-ttl-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Workspace TTL update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateWorkspaceTTLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/workspaces/{workspace}/usage": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Post Workspace Usage by ID",
+                "operationId": "post-workspace-usage-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Post workspace usage request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.PostWorkspaceUsageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/workspaces/{workspace}/watch": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Watch workspace by ID",
+                "operationId": "watch-workspace-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "agentsdk.AWSInstanceIdentityToken": {
+            "type": "object",
+            "required": [
+                "document",
+                "signature"
+            ],
+            "properties": {
+                "document": {
                     "type": "string"
                 },
                 "signature": {
@@ -9431,7 +9971,8 @@ const docTemplate = `{
                 "login",
                 "logout",
                 "register",
-                "request_password_reset"
+                "request_password_reset",
+                "exec"
             ],
             "x-enum-varnames": [
                 "AuditActionCreate",
@@ -9442,7 +9983,8 @@ const docTemplate = `{
                 "AuditActionLogin",
                 "AuditActionLogout",
                 "AuditActionRegister",
-                "AuditActionRequestPasswordReset"
+                "AuditActionRequestPasswordReset",
+                "AuditActionExec"
             ]
         },
         "codersdk.AuditDiff": {
@@ -10032,7 +10574,8 @@ const docTemplate = `{
                     "type": "string",
                     "enum": [
                         "terraform",
-                        "echo"
+                        "echo",
+                        "exec"
                     ]
                 },
                 "storage_method": {
@@ -10221,6 +10764,18 @@ const docTemplate = `{
                     "description": "Orphan may be set for the Destroy transition.",
                     "type": "boolean"
                 },
+                "priority": {
+                    "description": "Priority of the provisioner job of the build (\"interactive\" if empty).\nBulk operations should use \"bulk\" so interactive builds of other users\nare acquired first.",
+                    "enum": [
+                        "interactive",
+                        "bulk"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobPriority"
+                        }
+                    ]
+                },
                 "rich_parameter_values": {
                     "description": "ParameterValues are optional. It will write params to the 'workspace' scope.\nThis will overwrite any existing parameters with the same name.\nThis will not delete old params not included in this list.",
                     "type": "array",
@@ -10550,6 +11105,9 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "agent_auto_update": {
+                    "type": "boolean"
+                },
                 "agent_fallback_troubleshooting_url": {
                     "$ref": "#/definitions/serpent.URL"
                 },
@@ -11159,10 +11717,36 @@ const docTemplate = `{
         "codersdk.Healthcheck": {
             "type": "object",
             "properties": {
+                "body_regex": {
+                    "description": "BodyRegex is a regular expression the HTTP response body must match.",
+                    "type": "string"
+                },
+                "command": {
+                    "description": "Command specifies a command that must exit with code zero, instead of\nrequesting URL. It runs in the workspace like agent scripts do.",
+                    "type": "string"
+                },
+                "expected_statuses": {
+                    "description": "ExpectedStatuses are the HTTP status codes that count as healthy. Any\nstatus below 500 is healthy when empty.",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "type": "array"
+                },
+                "headers": {
+                    "description": "Headers are added to the HTTP request.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "interval": {
                     "description": "Interval specifies the seconds between each health check.",
                     "type": "integer"
                 },
+                "tcp": {
+                    "description": "TCP specifies a host:port that must accept connections, instead of\nrequesting URL.",
+                    "type": "string"
+                },
                 "threshold": {
                     "description": "Threshold specifies the number of consecutive failed health checks before returning \"unhealthy\".",
                     "type": "integer"
@@ -11248,10 +11832,12 @@ const docTemplate = `{
         "codersdk.JobErrorCode": {
             "type": "string",
             "enum": [
-                "REQUIRED_TEMPLATE_VARIABLES"
+                "REQUIRED_TEMPLATE_VARIABLES",
+                "JOB_TIMEOUT"
             ],
             "x-enum-varnames": [
-                "RequiredTemplateVariables"
+                "RequiredTemplateVariables",
+                "JobTimeout"
             ]
         },
         "codersdk.License": {
@@ -12219,8 +12805,29 @@ const docTemplate = `{
                     "description": "Daemons is the number of built-in terraform provisioners.",
                     "type": "integer"
                 },
+                "drift_check_interval": {
+                    "type": "integer"
+                },
                 "force_cancel_interval": {
                     "type": "integer"
+                },
+                "job_retries": {
+                    "type": "integer"
+                },
+                "job_retry_backoff": {
+                    "type": "integer"
+                },
+                "retryable_errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "state_retention": {
+                    "type": "integer"
+                },
+                "terraform_engine": {
+                    "type": "string"
                 }
             }
         },
@@ -12285,12 +12892,19 @@ const docTemplate = `{
                     "type": "string",
                     "format": "date-time"
                 },
+                "engine": {
+                    "type": "string"
+                },
+                "engine_version": {
+                    "type": "string"
+                },
                 "error": {
                     "type": "string"
                 },
                 "error_code": {
                     "enum": [
-                        "REQUIRED_TEMPLATE_VARIABLES"
+                        "REQUIRED_TEMPLATE_VARIABLES",
+                        "JOB_TIMEOUT"
                     ],
                     "allOf": [
                         {
@@ -12306,6 +12920,22 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "priority": {
+                    "enum": [
+                        "interactive",
+                        "autostart",
+                        "bulk"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobPriority"
+                        }
+                    ]
+                },
+                "queue_estimated_wait_ms": {
+                    "description": "QueueEstimatedWaitMillis is a rough estimate of how long a pending\njob waits before a provisioner daemon acquires it. It's zero when\nthere are no recent jobs to estimate from.",
+                    "type": "integer"
+                },
                 "queue_position": {
                     "type": "integer"
                 },
@@ -12378,6 +13008,19 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ProvisionerJobPriority": {
+            "type": "string",
+            "enum": [
+                "interactive",
+                "autostart",
+                "bulk"
+            ],
+            "x-enum-varnames": [
+                "ProvisionerJobPriorityInteractive",
+                "ProvisionerJobPriorityAutostart",
+                "ProvisionerJobPriorityBulk"
+            ]
+        },
         "codersdk.ProvisionerJobStatus": {
             "type": "string",
             "enum": [
@@ -12893,6 +13536,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.RestoreWorkspaceStateRequest": {
+            "type": "object",
+            "required": [
+                "build_number"
+            ],
+            "properties": {
+                "build_number": {
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.Role": {
             "type": "object",
             "properties": {
@@ -13181,6 +13835,10 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "job_timeout_ms": {
+                    "description": "JobTimeoutMillis is the maximum duration of a workspace build. Builds\nthat run longer are canceled. 0 means builds never time out.",
+                    "type": "integer"
+                },
                 "max_port_share_level": {
                     "$ref": "#/definitions/codersdk.WorkspaceAgentPortShareLevel"
                 },
@@ -13355,72 +14013,175 @@ const docTemplate = `{
                 }
             }
         },
-        "codersdk.TemplateInsightsIntervalReport": {
+        "codersdk.TemplateGitSource": {
             "type": "object",
             "properties": {
-                "active_users": {
-                    "type": "integer",
-                    "example": 14
+                "auto_promote": {
+                    "type": "boolean"
                 },
-                "end_time": {
+                "created_at": {
                     "type": "string",
                     "format": "date-time"
                 },
-                "interval": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/codersdk.InsightsReportInterval"
-                        }
-                    ],
-                    "example": "week"
+                "deploy_key": {
+                    "description": "DeployKey is the public SSH key to add as a read-only deploy key to the\nrepository. It's empty when the repository is fetched with external\nauth.",
+                    "type": "string"
                 },
-                "start_time": {
-                    "type": "string",
-                    "format": "date-time"
+                "error": {
+                    "type": "string"
                 },
-                "template_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string",
-                        "format": "uuid"
-                    }
-                }
-            }
-        },
-        "codersdk.TemplateInsightsReport": {
-            "type": "object",
-            "properties": {
-                "active_users": {
-                    "type": "integer",
-                    "example": 22
+                "external_auth_provider_id": {
+                    "type": "string"
                 },
-                "apps_usage": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/codersdk.TemplateAppUsage"
-                    }
+                "known_hosts": {
+                    "description": "KnownHosts are the SSH host keys that the repository host is verified\nwith.",
+                    "type": "string"
                 },
-                "end_time": {
+                "last_checked_at": {
                     "type": "string",
                     "format": "date-time"
                 },
-                "parameters_usage": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/codersdk.TemplateParameterUsage"
-                    }
+                "last_commit_sha": {
+                    "type": "string"
                 },
-                "start_time": {
+                "last_synced_at": {
                     "type": "string",
                     "format": "date-time"
                 },
-                "template_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string",
-                        "format": "uuid"
-                    }
-                }
+                "path": {
+                    "description": "Path is the directory of the template within the repository.",
+                    "type": "string"
+                },
+                "ref": {
+                    "description": "Ref is the branch or tag to sync. Empty means the default branch.",
+                    "type": "string"
+                },
+                "repo_url": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "importing",
+                        "dry_run",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateGitSyncStatus"
+                        }
+                    ]
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "user_id": {
+                    "description": "UserID is the user that synced template versions are created by.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "webhook_secret": {
+                    "description": "WebhookSecret verifies the signature of GitHub webhooks and the token of\nGitLab webhooks.",
+                    "type": "string"
+                },
+                "webhook_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateGitSyncStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "importing",
+                "dry_run",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "TemplateGitSyncStatusPending",
+                "TemplateGitSyncStatusImporting",
+                "TemplateGitSyncStatusDryRun",
+                "TemplateGitSyncStatusSucceeded",
+                "TemplateGitSyncStatusFailed"
+            ]
+        },
+        "codersdk.TemplateInsightsIntervalReport": {
+            "type": "object",
+            "properties": {
+                "active_users": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "interval": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.InsightsReportInterval"
+                        }
+                    ],
+                    "example": "week"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                }
+            }
+        },
+        "codersdk.TemplateInsightsReport": {
+            "type": "object",
+            "properties": {
+                "active_users": {
+                    "type": "integer",
+                    "example": 22
+                },
+                "apps_usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateAppUsage"
+                    }
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "parameters_usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateParameterUsage"
+                    }
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                }
             }
         },
         "codersdk.TemplateInsightsResponse": {
@@ -13592,6 +14353,13 @@ const docTemplate = `{
                 "created_by": {
                     "$ref": "#/definitions/codersdk.MinimalUser"
                 },
+                "git_commit_author": {
+                    "type": "string"
+                },
+                "git_commit_sha": {
+                    "description": "GitCommitSHA and GitCommitAuthor are set when the version was synced\nfrom the git source of the template.",
+                    "type": "string"
+                },
                 "id": {
                     "type": "string",
                     "format": "uuid"
@@ -13634,6 +14402,42 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateVersionDiff": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionFileDiff"
+                    }
+                },
+                "from_template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "parameters": {
+                    "$ref": "#/definitions/codersdk.TemplateVersionObjectDiff"
+                },
+                "resources": {
+                    "description": "Resources are the resources that were planned when the versions were\nimported, keyed by \"type.name\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateVersionObjectDiff"
+                        }
+                    ]
+                },
+                "to_template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "variables": {
+                    "$ref": "#/definitions/codersdk.TemplateVersionObjectDiff"
+                },
+                "workspace_tags": {
+                    "$ref": "#/definitions/codersdk.TemplateVersionObjectDiff"
+                }
+            }
+        },
         "codersdk.TemplateVersionExternalAuth": {
             "type": "object",
             "properties": {
@@ -13660,6 +14464,98 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateVersionFieldChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateVersionFileChange": {
+            "type": "string",
+            "enum": [
+                "added",
+                "removed",
+                "modified"
+            ],
+            "x-enum-varnames": [
+                "TemplateVersionFileChangeAdded",
+                "TemplateVersionFileChangeRemoved",
+                "TemplateVersionFileChangeModified"
+            ]
+        },
+        "codersdk.TemplateVersionFileDiff": {
+            "type": "object",
+            "properties": {
+                "binary": {
+                    "description": "Binary files have no diff.",
+                    "type": "boolean"
+                },
+                "change": {
+                    "enum": [
+                        "added",
+                        "removed",
+                        "modified"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateVersionFileChange"
+                        }
+                    ]
+                },
+                "diff": {
+                    "description": "Diff is a unified diff of the file.",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateVersionObjectChange": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionFieldChange"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateVersionObjectDiff": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionObjectChange"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "codersdk.TemplateVersionParameter": {
             "type": "object",
             "properties": {
@@ -13957,6 +14853,39 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateTemplateGitSourceRequest": {
+            "type": "object",
+            "required": [
+                "repo_url"
+            ],
+            "properties": {
+                "auto_promote": {
+                    "description": "AutoPromote promotes new template versions to active after a successful\ndry run.",
+                    "type": "boolean"
+                },
+                "external_auth_provider_id": {
+                    "description": "ExternalAuthProviderID fetches the repository with the external auth\nlink of the user. It can't be used with UseDeployKey.",
+                    "type": "string"
+                },
+                "known_hosts": {
+                    "description": "KnownHosts are the SSH host keys of the repository host, in the\nknown_hosts format like the output of ssh-keyscan. They are required for\nSSH URLs.",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "ref": {
+                    "type": "string"
+                },
+                "repo_url": {
+                    "type": "string"
+                },
+                "use_deploy_key": {
+                    "description": "UseDeployKey fetches the repository over SSH with a generated key. The\nkey is kept when the source is updated.",
+                    "type": "boolean"
+                }
+            }
+        },
         "codersdk.UpdateUserAppearanceSettingsRequest": {
             "type": "object",
             "required": [
@@ -14478,6 +15407,10 @@ const docTemplate = `{
                 "favorite": {
                     "type": "boolean"
                 },
+                "has_drift": {
+                    "description": "HasDrift is true when the latest drift check of the running build found\nresources that were changed outside of the builds of the workspace.",
+                    "type": "boolean"
+                },
                 "health": {
                     "description": "Health shows the health of the workspace and information about\nwhat is causing an unhealthy status.",
                     "allOf": [
@@ -14694,34 +15627,197 @@ const docTemplate = `{
                 }
             }
         },
-        "codersdk.WorkspaceAgentHealth": {
+        "codersdk.WorkspaceAgentDevcontainer": {
             "type": "object",
             "properties": {
-                "healthy": {
-                    "description": "Healthy is true if the agent is healthy.",
-                    "type": "boolean",
-                    "example": false
+                "config_path": {
+                    "type": "string"
                 },
-                "reason": {
-                    "description": "Reason is a human-readable explanation of the agent's health. It is empty if Healthy is true.",
-                    "type": "string",
-                    "example": "agent has lost connection"
-                }
-            }
-        },
-        "codersdk.WorkspaceAgentLifecycle": {
-            "type": "string",
-            "enum": [
-                "created",
-                "starting",
-                "start_timeout",
-                "start_error",
-                "ready",
-                "shutting_down",
-                "shutdown_timeout",
-                "shutdown_error",
-                "off"
-            ],
+                "container_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name identifies the dev container, for example to connect to it with\n` + "`" + `coder ssh workspace.name` + "`" + `.",
+                    "type": "string"
+                },
+                "state": {
+                    "enum": [
+                        "stopped",
+                        "starting",
+                        "running",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentDevcontainerState"
+                        }
+                    ]
+                },
+                "state_changed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "workspace_folder": {
+                    "description": "WorkspaceFolder is the folder of the workspace that holds the config\nand is mounted in the container.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentDevcontainerState": {
+            "type": "string",
+            "enum": [
+                "stopped",
+                "starting",
+                "running",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "WorkspaceAgentDevcontainerFailed": "WorkspaceAgentDevcontainerFailed means building or starting the\ncontainer failed, see the error for why.",
+                "WorkspaceAgentDevcontainerStopped": "WorkspaceAgentDevcontainerStopped means the dev container has no\nrunning container."
+            },
+            "x-enum-varnames": [
+                "WorkspaceAgentDevcontainerStopped",
+                "WorkspaceAgentDevcontainerStarting",
+                "WorkspaceAgentDevcontainerRunning",
+                "WorkspaceAgentDevcontainerFailed"
+            ]
+        },
+        "codersdk.WorkspaceAgentDevcontainersResponse": {
+            "type": "object",
+            "properties": {
+                "devcontainers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceAgentDevcontainer"
+                    }
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentExecEvent": {
+            "type": "object",
+            "properties": {
+                "output": {
+                    "description": "Output is set for stdout and stderr events.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "result": {
+                    "description": "Result is set for exit events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentExecResult"
+                        }
+                    ]
+                },
+                "type": {
+                    "enum": [
+                        "stdout",
+                        "stderr",
+                        "exit"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentExecEventType"
+                        }
+                    ]
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentExecEventType": {
+            "type": "string",
+            "enum": [
+                "stdout",
+                "stderr",
+                "exit"
+            ],
+            "x-enum-comments": {
+                "WorkspaceAgentExecEventExit": "WorkspaceAgentExecEventExit is the last event, sent once the command\nexited or couldn't be run."
+            },
+            "x-enum-varnames": [
+                "WorkspaceAgentExecEventStdout",
+                "WorkspaceAgentExecEventStderr",
+                "WorkspaceAgentExecEventExit"
+            ]
+        },
+        "codersdk.WorkspaceAgentExecRequest": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "description": "Command is the program and its arguments. It's run by the shell of the\nworkspace user, with the same environment as commands run over SSH.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "env": {
+                    "description": "Env are environment variables set in addition to the environment of\nthe agent.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "timeout_ms": {
+                    "description": "TimeoutMillis kills the command when it runs for longer. Zero means the\ncommand runs until the request is canceled.",
+                    "type": "integer"
+                },
+                "working_directory": {
+                    "description": "WorkingDirectory defaults to the directory of the agent. Relative\npaths are resolved against it.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentExecResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is set when the command couldn't be run at all.",
+                    "type": "string"
+                },
+                "exit_code": {
+                    "description": "ExitCode is -1 when the command didn't exit by itself, like when it was\nkilled after its timeout.",
+                    "type": "integer"
+                },
+                "timed_out": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentHealth": {
+            "type": "object",
+            "properties": {
+                "healthy": {
+                    "description": "Healthy is true if the agent is healthy.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "reason": {
+                    "description": "Reason is a human-readable explanation of the agent's health. It is empty if Healthy is true.",
+                    "type": "string",
+                    "example": "agent has lost connection"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentLifecycle": {
+            "type": "string",
+            "enum": [
+                "created",
+                "starting",
+                "start_timeout",
+                "start_error",
+                "ready",
+                "shutting_down",
+                "shutdown_timeout",
+                "shutdown_error",
+                "off"
+            ],
             "x-enum-varnames": [
                 "WorkspaceAgentLifecycleCreated",
                 "WorkspaceAgentLifecycleStarting",
@@ -14737,6 +15833,9 @@ const docTemplate = `{
         "codersdk.WorkspaceAgentListeningPort": {
             "type": "object",
             "properties": {
+                "cmdline": {
+                    "type": "string"
+                },
                 "network": {
                     "description": "only \"tcp\" at the moment",
                     "type": "string"
@@ -14744,9 +15843,28 @@ const docTemplate = `{
                 "port": {
                     "type": "integer"
                 },
+                "pid": {
+                    "description": "PID, Cmdline and User describe the process listening on the port. They\nare empty when the agent can't inspect the process.",
+                    "type": "integer"
+                },
                 "process_name": {
                     "description": "may be empty",
                     "type": "string"
+                },
+                "protocol": {
+                    "description": "Protocol is the protocol the port appears to serve. It's only probed\nfor watches that ask for it, and is empty otherwise or when the port\nserves neither HTTP nor HTTPS.",
+                    "enum": [
+                        "http",
+                        "https"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentPortShareProtocol"
+                        }
+                    ]
+                },
+                "user": {
+                    "type": "string"
                 }
             }
         },
@@ -14886,6 +16004,14 @@ const docTemplate = `{
                 "cron": {
                     "type": "string"
                 },
+                "depends_on": {
+                    "description": "DependsOn are the IDs of the scripts of the same agent that must\nsucceed, or become ready, before this script is started.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
                 "display_name": {
                     "type": "string"
                 },
@@ -14900,6 +16026,14 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "ready": {
+                    "description": "Ready is the condition that must be met before the dependents of this\nscript are started. Without it, they start once the script exits\nsuccessfully.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentScriptReadiness"
+                        }
+                    ]
+                },
                 "run_on_start": {
                     "type": "boolean"
                 },
@@ -14917,6 +16051,134 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceAgentScriptReadiness": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "description": "File is a path that must exist.",
+                    "type": "string"
+                },
+                "port": {
+                    "description": "Port is a local TCP port that must accept connections.",
+                    "type": "integer"
+                },
+                "timeout": {
+                    "description": "Timeout is how long the agent waits for the condition. Zero uses the\nagent default.",
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentService": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "type": "string"
+                },
+                "directory": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "healthcheck": {
+                    "$ref": "#/definitions/codersdk.Healthcheck"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "max_restart_backoff": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restart_backoff": {
+                    "type": "integer"
+                },
+                "restart_count": {
+                    "type": "integer"
+                },
+                "restart_policy": {
+                    "enum": [
+                        "always",
+                        "on-failure",
+                        "never"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentServiceRestartPolicy"
+                        }
+                    ]
+                },
+                "state": {
+                    "enum": [
+                        "pending",
+                        "starting",
+                        "running",
+                        "unhealthy",
+                        "backoff",
+                        "exited",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentServiceState"
+                        }
+                    ]
+                },
+                "state_changed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "status_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentServiceRestartPolicy": {
+            "type": "string",
+            "enum": [
+                "always",
+                "on-failure",
+                "never"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceAgentServiceRestartAlways",
+                "WorkspaceAgentServiceRestartOnFailure",
+                "WorkspaceAgentServiceRestartNever"
+            ]
+        },
+        "codersdk.WorkspaceAgentServiceState": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "starting",
+                "running",
+                "unhealthy",
+                "backoff",
+                "exited",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceAgentServicePending",
+                "WorkspaceAgentServiceStarting",
+                "WorkspaceAgentServiceRunning",
+                "WorkspaceAgentServiceUnhealthy",
+                "WorkspaceAgentServiceBackoff",
+                "WorkspaceAgentServiceExited",
+                "WorkspaceAgentServiceFailed"
+            ]
+        },
         "codersdk.WorkspaceAgentStartupScriptBehavior": {
             "type": "string",
             "enum": [
@@ -14960,6 +16222,10 @@ const docTemplate = `{
                 "health": {
                     "$ref": "#/definitions/codersdk.WorkspaceAppHealth"
                 },
+                "health_reason": {
+                    "description": "HealthReason is why the last health check failed. It is empty unless\nthe app is unhealthy.",
+                    "type": "string"
+                },
                 "healthcheck": {
                     "description": "Healthcheck specifies the configuration for checking app health.",
                     "allOf": [
@@ -15226,6 +16492,85 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceDrift": {
+            "type": "object",
+            "properties": {
+                "build_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "build_number": {
+                    "type": "integer"
+                },
+                "checked_at": {
+                    "description": "CheckedAt is when the latest check completed. It's nil when the build\nhasn't been checked yet.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceDriftResource"
+                    }
+                }
+            }
+        },
+        "codersdk.WorkspaceDriftAction": {
+            "type": "string",
+            "enum": [
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceDriftActionUpdate",
+                "WorkspaceDriftActionDelete"
+            ]
+        },
+        "codersdk.WorkspaceDriftAttribute": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sensitive": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "codersdk.WorkspaceDriftResource": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "enum": [
+                        "update",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceDriftAction"
+                        }
+                    ]
+                },
+                "address": {
+                    "type": "string"
+                },
+                "attributes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceDriftAttribute"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.WorkspaceHealth": {
             "type": "object",
             "properties": {
@@ -15402,6 +16747,88 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceState": {
+            "type": "object",
+            "properties": {
+                "build_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "build_number": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "initiator_name": {
+                    "type": "string"
+                },
+                "resource_count": {
+                    "description": "ResourceCount is the number of resources in the state. It's omitted\nwhen the state isn't a Terraform state.",
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "Size is the size of the state in bytes. It's zero when the state has\nbeen removed by the retention policy.",
+                    "type": "integer"
+                },
+                "transition": {
+                    "enum": [
+                        "start",
+                        "stop",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceTransition"
+                        }
+                    ]
+                }
+            }
+        },
+        "codersdk.WorkspaceStateDiff": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceStateResourceChange"
+                    }
+                },
+                "from_build_number": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to_build_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.WorkspaceStateResourceChange": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "attributes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "codersdk.WorkspaceStatus": {
             "type": "string",
             "enum": [
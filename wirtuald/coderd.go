@@ -575,6 +575,7 @@ func New(options *Options) *API {
 		DeploymentID:          api.DeploymentID,
 		Telemetry:             api.Telemetry.Enabled(),
 	}
+	api.agentBinaries = newAgentBinaries(binFS)
 	api.SiteHandler = site.New(&site.Options{
 		BinFS:             binFS,
 		BinHashes:         binHashes,
@@ -1023,6 +1024,12 @@ func New(options *Options) *API {
 				r.Get("/", api.template)
 				r.Delete("/", api.deleteTemplate)
 				r.Patch("/", api.patchTemplateMeta)
+				r.Route("/git-source", func(r chi.Router) {
+					r.Get("/", api.templateGitSource)
+					r.Put("/", api.putTemplateGitSource)
+					r.Delete("/", api.deleteTemplateGitSource)
+					r.Post("/sync", api.postTemplateGitSourceSync)
+				})
 				r.Route("/versions", func(r chi.Router) {
 					r.Post("/archive", api.postArchiveTemplateVersions)
 					r.Get("/", api.templateVersionsByTemplate)
@@ -1031,6 +1038,8 @@ func New(options *Options) *API {
 				})
 			})
 		})
+		// Git hosts authenticate webhooks with the secret of the source.
+		r.Post("/templategitsources/{template}/webhook", api.postTemplateGitSourceWebhook)
 		r.Route("/templateversions/{templateversion}", func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
@@ -1050,6 +1059,7 @@ func New(options *Options) *API {
 			r.Get("/variables", api.templateVersionVariables)
 			r.Get("/resources", api.templateVersionResources)
 			r.Get("/logs", api.templateVersionLogs)
+			r.Get("/diff/{target}", api.templateVersionDiff)
 			r.Route("/dry-run", func(r chi.Router) {
 				r.Post("/", api.postTemplateVersionDryRun)
 				r.Get("/{jobID}", api.templateVersionDryRun)
@@ -1200,6 +1210,9 @@ func New(options *Options) *API {
 				r.Get("/startup-logs", api.workspaceAgentLogsDeprecated)
 				r.Get("/logs", api.workspaceAgentLogs)
 				r.Get("/listening-ports", api.workspaceAgentListeningPorts)
+				r.Get("/devcontainers", api.workspaceAgentDevcontainers)
+				r.Post("/exec", api.workspaceAgentExec)
+				r.Get("/services", api.workspaceAgentServices)
 				r.Get("/connection", api.workspaceAgentConnection)
 				r.Get("/coordinate", api.workspaceAgentClientCoordinate)
 
@@ -1241,6 +1254,12 @@ func New(options *Options) *API {
 					r.Delete("/", api.deleteWorkspaceAgentPortShare)
 				})
 				r.Get("/timings", api.workspaceTimings)
+				r.Get("/drift", api.workspaceDrift)
+				r.Route("/states", func(r chi.Router) {
+					r.Get("/", api.workspaceStates)
+					r.Get("/diff", api.workspaceStateDiff)
+					r.Post("/restore", api.postWorkspaceStateRestore)
+				})
 			})
 		})
 		r.Route("/workspacebuilds/{workspacebuild}", func(r chi.Router) {
@@ -1492,6 +1511,9 @@ type API struct {
 	healthCheckCache atomic.Pointer[healthsdk.HealthcheckReport]
 
 	statsReporter *workspacestats.Reporter
+	// agentBinaries checksums and signatures the agent binaries offered
+	// to agents as updates.
+	agentBinaries *agentBinaries
 
 	Acquirer *provisionerdserver.Acquirer
 	// dbRolluper rolls up template usage stats from raw agent and app
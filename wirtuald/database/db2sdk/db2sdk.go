@@ -511,18 +511,92 @@ func Apps(dbApps []database.WorkspaceApp, agent database.WorkspaceAgent, ownerNa
 			Subdomain:     dbApp.Subdomain,
 			SubdomainName: AppSubdomain(dbApp, agent.Name, workspace.Name, ownerName),
 			SharingLevel:  wirtualsdk.WorkspaceAppSharingLevel(dbApp.SharingLevel),
+			// Headers may carry credentials, so they are only sent to the
+			// agent.
 			Healthcheck: wirtualsdk.Healthcheck{
-				URL:       dbApp.HealthcheckUrl,
-				Interval:  dbApp.HealthcheckInterval,
-				Threshold: dbApp.HealthcheckThreshold,
+				URL:              dbApp.HealthcheckUrl,
+				Interval:         dbApp.HealthcheckInterval,
+				Threshold:        dbApp.HealthcheckThreshold,
+				TCP:              dbApp.HealthcheckTcp,
+				Command:          dbApp.HealthcheckCommand,
+				ExpectedStatuses: dbApp.HealthcheckExpectedStatuses,
+				BodyRegex:        dbApp.HealthcheckBodyRegex,
 			},
-			Health: wirtualsdk.WorkspaceAppHealth(dbApp.Health),
-			Hidden: dbApp.Hidden,
+			Health:       wirtualsdk.WorkspaceAppHealth(dbApp.Health),
+			HealthReason: dbApp.HealthReason,
+			Hidden:       dbApp.Hidden,
 		})
 	}
 	return apps
 }
 
+func WorkspaceAgentServices(dbServices []database.WorkspaceAgentService) ([]wirtualsdk.WorkspaceAgentService, error) {
+	services := make([]wirtualsdk.WorkspaceAgentService, 0, len(dbServices))
+	for _, dbService := range dbServices {
+		service, err := WorkspaceAgentService(dbService)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+func WorkspaceAgentService(dbService database.WorkspaceAgentService) (wirtualsdk.WorkspaceAgentService, error) {
+	env := map[string]string{}
+	if len(dbService.Env) > 0 {
+		err := json.Unmarshal(dbService.Env, &env)
+		if err != nil {
+			return wirtualsdk.WorkspaceAgentService{}, xerrors.Errorf("unmarshal env for service %q: %w", dbService.Name, err)
+		}
+	}
+
+	service := wirtualsdk.WorkspaceAgentService{
+		ID:                dbService.ID,
+		Name:              dbService.Name,
+		DisplayName:       dbService.DisplayName,
+		Command:           dbService.Command,
+		Directory:         dbService.Directory,
+		Env:               env,
+		RestartPolicy:     wirtualsdk.WorkspaceAgentServiceRestartPolicy(dbService.RestartPolicy),
+		RestartBackoff:    time.Duration(dbService.RestartBackoffSeconds) * time.Second,
+		MaxRestartBackoff: time.Duration(dbService.MaxRestartBackoffSeconds) * time.Second,
+		Healthcheck: wirtualsdk.Healthcheck{
+			URL:       dbService.HealthcheckUrl,
+			Interval:  dbService.HealthcheckInterval,
+			Threshold: dbService.HealthcheckThreshold,
+		},
+		State:         wirtualsdk.WorkspaceAgentServiceState(dbService.State),
+		RestartCount:  dbService.RestartCount,
+		ExitCode:      dbService.ExitCode,
+		StatusMessage: dbService.StatusMessage,
+	}
+	if dbService.StateChangedAt.Valid {
+		service.StateChangedAt = &dbService.StateChangedAt.Time
+	}
+	return service, nil
+}
+
+func WorkspaceAgentDevcontainers(dbDevcontainers []database.WorkspaceAgentDevcontainer) []wirtualsdk.WorkspaceAgentDevcontainer {
+	devcontainers := make([]wirtualsdk.WorkspaceAgentDevcontainer, 0, len(dbDevcontainers))
+	for _, dbDevcontainer := range dbDevcontainers {
+		devcontainer := wirtualsdk.WorkspaceAgentDevcontainer{
+			Name:            dbDevcontainer.Name,
+			WorkspaceFolder: dbDevcontainer.WorkspaceFolder,
+			ConfigPath:      dbDevcontainer.ConfigPath,
+			State:           wirtualsdk.WorkspaceAgentDevcontainerState(dbDevcontainer.State),
+			ContainerID:     dbDevcontainer.ContainerID,
+			Image:           dbDevcontainer.Image,
+			Error:           dbDevcontainer.Error,
+		}
+		if dbDevcontainer.StateChangedAt.Valid {
+			devcontainer.StateChangedAt = &dbDevcontainer.StateChangedAt.Time
+		}
+		devcontainers = append(devcontainers, devcontainer)
+	}
+	return devcontainers
+}
+
 func ProvisionerDaemon(dbDaemon database.ProvisionerDaemon) wirtualsdk.ProvisionerDaemon {
 	result := wirtualsdk.ProvisionerDaemon{
 		ID:             dbDaemon.ID,
@@ -847,6 +847,26 @@ func authorizedTemplateVersionFromJob(ctx context.Context, q *querier, job datab
 	}
 }
 
+// workspaceFromDriftCheckJob returns the workspace that a drift check job
+// belongs to. The caller is responsible for authorizing the workspace.
+// The workspace is found through the build in the job input rather than the
+// drift row, which is only written once the job exists. A job whose build is
+// gone returns sql.ErrNoRows, the same as a workspace build job would.
+func workspaceFromDriftCheckJob(ctx context.Context, db database.Store, job database.ProvisionerJob) (database.Workspace, error) {
+	tmp := struct {
+		WorkspaceBuildID uuid.UUID `json:"workspace_build_id"`
+	}{}
+	err := json.Unmarshal(job.Input, &tmp)
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("drift check unmarshal: %w", err)
+	}
+	build, err := db.GetWorkspaceBuildByID(ctx, tmp.WorkspaceBuildID)
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	return db.GetWorkspaceByID(ctx, build.WorkspaceID)
+}
+
 func (q *querier) authorizeTemplateInsights(ctx context.Context, templateIDs []uuid.UUID) error {
 	// Abort early if can read all template insights, aka admins.
 	// TODO: If we know the org, that would allow org admins to abort early too.
@@ -994,6 +1014,13 @@ func (q *querier) AcquireProvisionerJob(ctx context.Context, arg database.Acquir
 	return q.db.AcquireProvisionerJob(ctx, arg)
 }
 
+func (q *querier) AcquireTemplateGitSources(ctx context.Context, arg database.AcquireTemplateGitSourcesParams) ([]database.TemplateGitSource, error) {
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.AcquireTemplateGitSources(ctx, arg)
+}
+
 func (q *querier) ActivityBumpWorkspace(ctx context.Context, arg database.ActivityBumpWorkspaceParams) error {
 	fetch := func(ctx context.Context, arg database.ActivityBumpWorkspaceParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
@@ -1242,6 +1269,13 @@ func (q *querier) DeleteOldWorkspaceAgentStats(ctx context.Context) error {
 	return q.db.DeleteOldWorkspaceAgentStats(ctx)
 }
 
+func (q *querier) DeleteOldWorkspaceBuildStates(ctx context.Context, threshold time.Time) error {
+	if err := q.authorizeContext(ctx, policy.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteOldWorkspaceBuildStates(ctx, threshold)
+}
+
 func (q *querier) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
 	return deleteQ(q.log, q.auth, q.db.GetOrganizationByID, q.db.DeleteOrganization)(ctx, id)
 }
@@ -1309,6 +1343,17 @@ func (q *querier) DeleteTailnetTunnel(ctx context.Context, arg database.DeleteTa
 	return q.db.DeleteTailnetTunnel(ctx, arg)
 }
 
+func (q *querier) DeleteTemplateGitSource(ctx context.Context, templateID uuid.UUID) error {
+	template, err := q.db.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, template); err != nil {
+		return err
+	}
+	return q.db.DeleteTemplateGitSource(ctx, templateID)
+}
+
 func (q *querier) DeleteWorkspaceAgentPortShare(ctx context.Context, arg database.DeleteWorkspaceAgentPortShareParams) error {
 	w, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
 	if err != nil {
@@ -1914,6 +1959,15 @@ func (q *querier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (data
 		if err != nil {
 			return database.ProvisionerJob{}, err
 		}
+	case database.ProvisionerJobTypeWorkspaceDriftCheck:
+		// If we can read the workspace, we can read its drift checks.
+		workspace, err := workspaceFromDriftCheckJob(ctx, q.db, job)
+		if err != nil {
+			return database.ProvisionerJob{}, err
+		}
+		if err := q.authorizeContext(ctx, policy.ActionRead, workspace); err != nil {
+			return database.ProvisionerJob{}, err
+		}
 	default:
 		return database.ProvisionerJob{}, xerrors.Errorf("unknown job type: %q", job.Type)
 	}
@@ -2082,6 +2136,38 @@ func (q *querier) GetTemplateDAUs(ctx context.Context, arg database.GetTemplateD
 	return q.db.GetTemplateDAUs(ctx, arg)
 }
 
+func (q *querier) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err == nil {
+		// The webhook handler reads the source to verify the request before
+		// it knows who sent it.
+		return q.db.GetTemplateGitSourceByTemplateID(ctx, templateID)
+	}
+	// An actor is authorized to read the git source if they are authorized to
+	// update the template, because it contains the deploy key and webhook secret.
+	template, err := q.db.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, template); err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	return q.db.GetTemplateGitSourceByTemplateID(ctx, templateID)
+}
+
+func (q *querier) GetTemplateGitSourcesWithCompletedJobs(ctx context.Context) ([]database.TemplateGitSource, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateGitSourcesWithCompletedJobs(ctx)
+}
+
+func (q *querier) GetTemplateGitSourcesWithDeployKeys(ctx context.Context) ([]database.TemplateGitSource, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateGitSourcesWithDeployKeys(ctx)
+}
+
 func (q *querier) GetTemplateInsights(ctx context.Context, arg database.GetTemplateInsightsParams) (database.GetTemplateInsightsRow, error) {
 	if err := q.authorizeTemplateInsights(ctx, arg.TemplateIDs); err != nil {
 		return database.GetTemplateInsightsRow{}, err
@@ -2446,6 +2532,21 @@ func (q *querier) GetWorkspaceAgentByInstanceID(ctx context.Context, authInstanc
 	return agent, nil
 }
 
+func (q *querier) GetWorkspaceAgentDNSRecordsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentDNSRecord, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceAgentDNSRecordsByAgentIDs(ctx, ids)
+}
+
+func (q *querier) GetWorkspaceAgentDevcontainersByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentDevcontainer, error) {
+	_, err := q.GetWorkspaceAgentByID(ctx, workspaceAgentID)
+	if err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceAgentDevcontainersByAgentID(ctx, workspaceAgentID)
+}
+
 func (q *querier) GetWorkspaceAgentLifecycleStateByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceAgentLifecycleStateByIDRow, error) {
 	_, err := q.GetWorkspaceAgentByID(ctx, id)
 	if err != nil {
@@ -2461,6 +2562,14 @@ func (q *querier) GetWorkspaceAgentLogSourcesByAgentIDs(ctx context.Context, ids
 	return q.db.GetWorkspaceAgentLogSourcesByAgentIDs(ctx, ids)
 }
 
+func (q *querier) GetWorkspaceAgentLogs(ctx context.Context, arg database.GetWorkspaceAgentLogsParams) ([]database.WorkspaceAgentLog, error) {
+	_, err := q.GetWorkspaceAgentByID(ctx, arg.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceAgentLogs(ctx, arg)
+}
+
 func (q *querier) GetWorkspaceAgentLogsAfter(ctx context.Context, arg database.GetWorkspaceAgentLogsAfterParams) ([]database.WorkspaceAgentLog, error) {
 	_, err := q.GetWorkspaceAgentByID(ctx, arg.AgentID)
 	if err != nil {
@@ -2511,6 +2620,13 @@ func (q *querier) GetWorkspaceAgentScriptsByAgentIDs(ctx context.Context, ids []
 	return q.db.GetWorkspaceAgentScriptsByAgentIDs(ctx, ids)
 }
 
+func (q *querier) GetWorkspaceAgentServicesByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentService, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceAgentServicesByAgentIDs(ctx, ids)
+}
+
 func (q *querier) GetWorkspaceAgentStats(ctx context.Context, createdAfter time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
 	return q.db.GetWorkspaceAgentStats(ctx, createdAfter)
 }
@@ -2650,6 +2766,13 @@ func (q *querier) GetWorkspaceBuildsCreatedAfter(ctx context.Context, createdAt
 	return q.db.GetWorkspaceBuildsCreatedAfter(ctx, createdAt)
 }
 
+func (q *querier) GetWorkspaceBuildsForDriftCheck(ctx context.Context, checkedBefore time.Time) ([]database.GetWorkspaceBuildsForDriftCheckRow, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceBuildsForDriftCheck(ctx, checkedBefore)
+}
+
 func (q *querier) GetWorkspaceByAgentID(ctx context.Context, agentID uuid.UUID) (database.Workspace, error) {
 	return fetch(q.log, q.auth, q.db.GetWorkspaceByAgentID)(ctx, agentID)
 }
@@ -2666,6 +2789,24 @@ func (q *querier) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspaceApp
 	return fetch(q.log, q.auth, q.db.GetWorkspaceByWorkspaceAppID)(ctx, workspaceAppID)
 }
 
+func (q *querier) GetWorkspaceDriftByBuildID(ctx context.Context, workspaceBuildID uuid.UUID) (database.WorkspaceDrift, error) {
+	// Authorized call to get the workspace build. If we can read the build,
+	// we can read its drift.
+	if _, err := q.GetWorkspaceBuildByID(ctx, workspaceBuildID); err != nil {
+		return database.WorkspaceDrift{}, err
+	}
+	return q.db.GetWorkspaceDriftByBuildID(ctx, workspaceBuildID)
+}
+
+// GetWorkspaceDriftByBuildIDs is only used for workspace data. The workspaces
+// are already fetched.
+func (q *querier) GetWorkspaceDriftByBuildIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceDrift, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceDriftByBuildIDs(ctx, ids)
+}
+
 func (q *querier) GetWorkspaceModulesByJobID(ctx context.Context, jobID uuid.UUID) ([]database.WorkspaceModule, error) {
 	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
 		return nil, err
@@ -2767,6 +2908,12 @@ func (q *querier) GetWorkspaceResourcesByJobID(ctx context.Context, jobID uuid.U
 			return nil, err
 		}
 		obj = workspace
+	case database.ProvisionerJobTypeWorkspaceDriftCheck:
+		workspace, err := workspaceFromDriftCheckJob(ctx, q.db, job)
+		if err != nil {
+			return nil, err
+		}
+		obj = workspace
 	default:
 		return nil, xerrors.Errorf("unknown job type: %s", job.Type)
 	}
@@ -3119,6 +3266,13 @@ func (q *querier) InsertWorkspaceAgent(ctx context.Context, arg database.InsertW
 	return q.db.InsertWorkspaceAgent(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceAgentDNSRecords(ctx context.Context, arg database.InsertWorkspaceAgentDNSRecordsParams) ([]database.WorkspaceAgentDNSRecord, error) {
+	if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
+		return []database.WorkspaceAgentDNSRecord{}, err
+	}
+	return q.db.InsertWorkspaceAgentDNSRecords(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceAgentLogSources(ctx context.Context, arg database.InsertWorkspaceAgentLogSourcesParams) ([]database.WorkspaceAgentLogSource, error) {
 	// TODO: This is used by the agent, should we have an rbac check here?
 	return q.db.InsertWorkspaceAgentLogSources(ctx, arg)
@@ -3153,6 +3307,13 @@ func (q *querier) InsertWorkspaceAgentScripts(ctx context.Context, arg database.
 	return q.db.InsertWorkspaceAgentScripts(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceAgentService(ctx context.Context, arg database.InsertWorkspaceAgentServiceParams) (database.WorkspaceAgentService, error) {
+	if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
+		return database.WorkspaceAgentService{}, err
+	}
+	return q.db.InsertWorkspaceAgentService(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceAgentStats(ctx context.Context, arg database.InsertWorkspaceAgentStatsParams) error {
 	if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
 		return err
@@ -3353,6 +3514,17 @@ func (q *querier) RemoveUserFromGroups(ctx context.Context, arg database.RemoveU
 	return q.db.RemoveUserFromGroups(ctx, arg)
 }
 
+func (q *querier) RequestTemplateGitSourceSync(ctx context.Context, arg database.RequestTemplateGitSourceSyncParams) error {
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err == nil {
+		// Verified webhooks request a sync as the system.
+		return q.db.RequestTemplateGitSourceSync(ctx, arg)
+	}
+	fetch := func(ctx context.Context, arg database.RequestTemplateGitSourceSyncParams) (database.Template, error) {
+		return q.db.GetTemplateByID(ctx, arg.TemplateID)
+	}
+	return update(q.log, q.auth, fetch, q.db.RequestTemplateGitSourceSync)(ctx, arg)
+}
+
 func (q *querier) RevokeDBCryptKey(ctx context.Context, activeKeyDigest string) error {
 	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
 		return err
@@ -3533,6 +3705,13 @@ func (q *querier) UpdateProvisionerJobByID(ctx context.Context, arg database.Upd
 	return q.db.UpdateProvisionerJobByID(ctx, arg)
 }
 
+func (q *querier) UpdateProvisionerJobEngineByID(ctx context.Context, arg database.UpdateProvisionerJobEngineByIDParams) error {
+	// if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
+	// return err
+	// }
+	return q.db.UpdateProvisionerJobEngineByID(ctx, arg)
+}
+
 func (q *querier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg database.UpdateProvisionerJobWithCancelByIDParams) error {
 	job, err := q.db.GetProvisionerJobByID(ctx, arg.ID)
 	if err != nil {
@@ -3594,6 +3773,15 @@ func (q *querier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg da
 				return err
 			}
 		}
+	case database.ProvisionerJobTypeWorkspaceDriftCheck:
+		workspace, err := workspaceFromDriftCheckJob(ctx, q.db, job)
+		if err != nil {
+			return err
+		}
+		err = q.authorizeContext(ctx, policy.ActionUpdate, workspace)
+		if err != nil {
+			return err
+		}
 	default:
 		return xerrors.Errorf("unknown job type: %q", job.Type)
 	}
@@ -3650,6 +3838,20 @@ func (q *querier) UpdateTemplateDeletedByID(ctx context.Context, arg database.Up
 	return q.SoftDeleteTemplateByID(ctx, arg.ID)
 }
 
+func (q *querier) UpdateTemplateGitSourceDeployKeyByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams) error {
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateTemplateGitSourceDeployKeyByTemplateID(ctx, arg)
+}
+
+func (q *querier) UpdateTemplateGitSourceSyncByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateTemplateGitSourceSyncByTemplateID(ctx, arg)
+}
+
 func (q *querier) UpdateTemplateMetaByID(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) error {
 	fetch := func(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 		return q.db.GetTemplateByID(ctx, arg.ID)
@@ -3949,6 +4151,26 @@ func (q *querier) UpdateWorkspaceAgentMetadata(ctx context.Context, arg database
 	return q.db.UpdateWorkspaceAgentMetadata(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceAgentScriptDependsOnByID(ctx context.Context, arg database.UpdateWorkspaceAgentScriptDependsOnByIDParams) error {
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateWorkspaceAgentScriptDependsOnByID(ctx, arg)
+}
+
+func (q *querier) UpdateWorkspaceAgentServiceStatusByID(ctx context.Context, arg database.UpdateWorkspaceAgentServiceStatusByIDParams) error {
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, arg.WorkspaceAgentID)
+	if err != nil {
+		return err
+	}
+
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, workspace); err != nil {
+		return err
+	}
+
+	return q.db.UpdateWorkspaceAgentServiceStatusByID(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	agent, err := q.db.GetWorkspaceAgentByID(ctx, arg.ID)
 	if err != nil {
@@ -4055,6 +4277,13 @@ func (q *querier) UpdateWorkspaceDormantDeletingAt(ctx context.Context, arg data
 	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateWorkspaceDormantDeletingAt)(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceDriftByJobID(ctx context.Context, arg database.UpdateWorkspaceDriftByJobIDParams) (database.WorkspaceDrift, error) {
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.WorkspaceDrift{}, err
+	}
+	return q.db.UpdateWorkspaceDriftByJobID(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.ID)
@@ -4076,6 +4305,13 @@ func (q *querier) UpdateWorkspaceProxyDeleted(ctx context.Context, arg database.
 	return deleteQ(q.log, q.auth, fetch, q.db.UpdateWorkspaceProxyDeleted)(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceProxyDraining(ctx context.Context, arg database.UpdateWorkspaceProxyDrainingParams) (database.WorkspaceProxy, error) {
+	fetch := func(ctx context.Context, arg database.UpdateWorkspaceProxyDrainingParams) (database.WorkspaceProxy, error) {
+		return q.db.GetWorkspaceProxyByID(ctx, arg.ID)
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateWorkspaceProxyDraining)(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceTTL(ctx context.Context, arg database.UpdateWorkspaceTTLParams) error {
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceTTLParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.ID)
@@ -4252,6 +4488,17 @@ func (q *querier) UpsertTailnetTunnel(ctx context.Context, arg database.UpsertTa
 	return q.db.UpsertTailnetTunnel(ctx, arg)
 }
 
+func (q *querier) UpsertTemplateGitSource(ctx context.Context, arg database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, template); err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	return q.db.UpsertTemplateGitSource(ctx, arg)
+}
+
 func (q *querier) UpsertTemplateUsageStats(ctx context.Context) error {
 	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
 		return err
@@ -4259,6 +4506,19 @@ func (q *querier) UpsertTemplateUsageStats(ctx context.Context) error {
 	return q.db.UpsertTemplateUsageStats(ctx)
 }
 
+func (q *querier) UpsertWorkspaceAgentDevcontainer(ctx context.Context, arg database.UpsertWorkspaceAgentDevcontainerParams) error {
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, arg.WorkspaceAgentID)
+	if err != nil {
+		return err
+	}
+
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, workspace); err != nil {
+		return err
+	}
+
+	return q.db.UpsertWorkspaceAgentDevcontainer(ctx, arg)
+}
+
 func (q *querier) UpsertWorkspaceAgentPortShare(ctx context.Context, arg database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
 	if err != nil {
@@ -4273,6 +4533,13 @@ func (q *querier) UpsertWorkspaceAgentPortShare(ctx context.Context, arg databas
 	return q.db.UpsertWorkspaceAgentPortShare(ctx, arg)
 }
 
+func (q *querier) UpsertWorkspaceDriftCheck(ctx context.Context, arg database.UpsertWorkspaceDriftCheckParams) error {
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpsertWorkspaceDriftCheck(ctx, arg)
+}
+
 func (q *querier) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, _ rbac.PreparedAuthorized) ([]database.Template, error) {
 	// TODO Delete this function, all GetTemplates should be authorized. For now just call getTemplates on the authz querier.
 	return q.GetTemplatesWithFilter(ctx, arg)
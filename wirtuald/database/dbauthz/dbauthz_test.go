@@ -815,6 +815,13 @@ func (s *MethodTestSuite) TestWorkspaceProxy() {
 			Deleted: true,
 		}).Asserts(p, policy.ActionDelete)
 	}))
+	s.Run("UpdateWorkspaceProxyDraining", s.Subtest(func(db database.Store, check *expects) {
+		p, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
+		check.Args(database.UpdateWorkspaceProxyDrainingParams{
+			ID:       p.ID,
+			Draining: true,
+		}).Asserts(p, policy.ActionUpdate)
+	}))
 	s.Run("UpdateWorkspaceProxy", s.Subtest(func(db database.Store, check *expects) {
 		p, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
 		check.Args(database.UpdateWorkspaceProxyParams{
@@ -826,6 +833,35 @@ func (s *MethodTestSuite) TestWorkspaceProxy() {
 		p2, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
 		check.Args().Asserts(p1, policy.ActionRead, p2, policy.ActionRead).Returns(slice.New(p1, p2))
 	}))
+	s.Run("GetTemplateGitSourceByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		src := dbgen.TemplateGitSource(s.T(), db, database.TemplateGitSource{TemplateID: t1.ID})
+		check.Args(t1.ID).Asserts(rbac.ResourceSystem, policy.ActionRead, t1, policy.ActionUpdate).Returns(src).
+			// Fail the system resource skip
+			FailSystemObjectChecks()
+	}))
+	s.Run("UpsertTemplateGitSource", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.UpsertTemplateGitSourceParams{
+			TemplateID: t1.ID,
+			RepoURL:    "https://example.com/templates.git",
+		}).Asserts(t1, policy.ActionUpdate)
+	}))
+	s.Run("DeleteTemplateGitSource", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		_ = dbgen.TemplateGitSource(s.T(), db, database.TemplateGitSource{TemplateID: t1.ID})
+		check.Args(t1.ID).Asserts(t1, policy.ActionUpdate).Returns()
+	}))
+	s.Run("RequestTemplateGitSourceSync", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		_ = dbgen.TemplateGitSource(s.T(), db, database.TemplateGitSource{TemplateID: t1.ID})
+		check.Args(database.RequestTemplateGitSourceSyncParams{
+			TemplateID:  t1.ID,
+			RequestedAt: time.Now(),
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate, t1, policy.ActionUpdate).Returns().
+			// Fail the system resource skip
+			FailSystemObjectChecks()
+	}))
 }
 
 func (s *MethodTestSuite) TestTemplate() {
@@ -1534,6 +1570,16 @@ func (s *MethodTestSuite) TestWorkspace() {
 		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
 		check.Args(agt.ID).Asserts(ws, policy.ActionRead).Returns(agt)
 	}))
+	s.Run("GetWorkspaceAgentDevcontainersByAgentID", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
+			TemplateID: tpl.ID,
+		})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(agt.ID).Asserts(ws, policy.ActionRead)
+	}))
 	s.Run("GetWorkspaceAgentLifecycleStateByID", s.Subtest(func(db database.Store, check *expects) {
 		tpl := dbgen.Template(s.T(), db, database.Template{})
 		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
@@ -1597,6 +1643,34 @@ func (s *MethodTestSuite) TestWorkspace() {
 			WorkspaceAgentID: agt.ID,
 		}).Asserts(ws, policy.ActionUpdate).Returns()
 	}))
+	s.Run("UpsertWorkspaceAgentDevcontainer", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
+			TemplateID: tpl.ID,
+		})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.UpsertWorkspaceAgentDevcontainerParams{
+			WorkspaceAgentID: agt.ID,
+			Name:             "project",
+			State:            database.WorkspaceAgentDevcontainerStateRunning,
+		}).Asserts(ws, policy.ActionUpdate).Returns()
+	}))
+	s.Run("UpdateWorkspaceAgentServiceStatusByID", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
+			TemplateID: tpl.ID,
+		})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.UpdateWorkspaceAgentServiceStatusByIDParams{
+			ID:               uuid.New(),
+			WorkspaceAgentID: agt.ID,
+			State:            database.WorkspaceAgentServiceStateRunning,
+		}).Asserts(ws, policy.ActionUpdate).Returns()
+	}))
 	s.Run("UpdateWorkspaceAgentLogOverflowByID", s.Subtest(func(db database.Store, check *expects) {
 		tpl := dbgen.Template(s.T(), db, database.Template{})
 		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
@@ -1625,6 +1699,18 @@ func (s *MethodTestSuite) TestWorkspace() {
 			},
 		}).Asserts(ws, policy.ActionUpdate).Returns()
 	}))
+	s.Run("GetWorkspaceAgentLogs", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
+			TemplateID: tpl.ID,
+		})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.GetWorkspaceAgentLogsParams{
+			AgentID: agt.ID,
+		}).Asserts(ws, policy.ActionRead).Returns([]database.WorkspaceAgentLog{})
+	}))
 	s.Run("GetWorkspaceAgentLogsAfter", s.Subtest(func(db database.Store, check *expects) {
 		tpl := dbgen.Template(s.T(), db, database.Template{})
 		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
@@ -1696,6 +1782,19 @@ func (s *MethodTestSuite) TestWorkspace() {
 		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, BuildNumber: 3})
 		check.Args(database.GetWorkspaceBuildsByWorkspaceIDParams{WorkspaceID: ws.ID}).Asserts(ws, policy.ActionRead) // ordering
 	}))
+	s.Run("GetWorkspaceDriftByBuildID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
+		err := db.UpsertWorkspaceDriftCheck(context.Background(), database.UpsertWorkspaceDriftCheckParams{
+			WorkspaceBuildID: build.ID,
+			JobID:            uuid.New(),
+			CreatedAt:        dbtime.Now(),
+		})
+		require.NoError(s.T(), err)
+		drift, err := db.GetWorkspaceDriftByBuildID(context.Background(), build.ID)
+		require.NoError(s.T(), err)
+		check.Args(build.ID).Asserts(ws, policy.ActionRead).Returns(drift)
+	}))
 	s.Run("GetWorkspaceByAgentID", s.Subtest(func(db database.Store, check *expects) {
 		tpl := dbgen.Template(s.T(), db, database.Template{})
 		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
@@ -2453,6 +2552,49 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts(rbac.ResourceSystem, policy.ActionRead)
 	}))
+	s.Run("GetWorkspaceBuildsForDriftCheck", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+	s.Run("GetWorkspaceDriftByBuildIDs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args([]uuid.UUID{uuid.New()}).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+	s.Run("UpsertWorkspaceDriftCheck", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpsertWorkspaceDriftCheckParams{
+			WorkspaceBuildID: uuid.New(),
+			JobID:            uuid.New(),
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate)
+	}))
+	s.Run("UpdateWorkspaceDriftByJobID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateWorkspaceDriftByJobIDParams{
+			JobID:     uuid.New(),
+			Resources: json.RawMessage("[]"),
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate).Errors(sql.ErrNoRows)
+	}))
+	s.Run("AcquireTemplateGitSources", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.AcquireTemplateGitSourcesParams{
+			Now:           time.Now(),
+			CheckedBefore: time.Now(),
+			LimitOpt:      10,
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate)
+	}))
+	s.Run("GetTemplateGitSourcesWithCompletedJobs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+	s.Run("GetTemplateGitSourcesWithDeployKeys", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+	s.Run("UpdateTemplateGitSourceDeployKeyByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams{
+			TemplateID:       uuid.New(),
+			DeployPrivateKey: "key",
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate)
+	}))
+	s.Run("UpdateTemplateGitSourceSyncByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateTemplateGitSourceSyncByTemplateIDParams{
+			TemplateID: uuid.New(),
+			SyncStatus: database.TemplateGitSyncStatusSucceeded,
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate)
+	}))
 	s.Run("GetWorkspaceAgentsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts(rbac.ResourceSystem, policy.ActionRead)
@@ -2588,7 +2730,7 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{
 			StartedAt: sql.NullTime{Valid: false},
 		})
-		check.Args(database.AcquireProvisionerJobParams{OrganizationID: j.OrganizationID, Types: []database.ProvisionerType{j.Provisioner}, ProvisionerTags: must(json.Marshal(j.Tags))}).
+		check.Args(database.AcquireProvisionerJobParams{OrganizationIDs: []uuid.UUID{j.OrganizationID}, Types: []database.ProvisionerType{j.Provisioner}, ProvisionerTags: must(json.Marshal(j.Tags))}).
 			Asserts( /*rbac.ResourceSystem, policy.ActionUpdate*/ )
 	}))
 	s.Run("UpdateProvisionerJobWithCompleteByID", s.Subtest(func(db database.Store, check *expects) {
@@ -2598,6 +2740,16 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			ID: j.ID,
 		}).Asserts( /*rbac.ResourceSystem, policy.ActionUpdate*/ )
 	}))
+	s.Run("UpdateProvisionerJobEngineByID", s.Subtest(func(db database.Store, check *expects) {
+		// TODO: we need to create a ProvisionerJob resource
+		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{})
+		check.Args(database.UpdateProvisionerJobEngineByIDParams{
+			ID:            j.ID,
+			UpdatedAt:     time.Now(),
+			Engine:        "opentofu",
+			EngineVersion: "1.8.5",
+		}).Asserts( /*rbac.ResourceSystem, policy.ActionUpdate*/ )
+	}))
 	s.Run("UpdateProvisionerJobByID", s.Subtest(func(db database.Store, check *expects) {
 		// TODO: we need to create a ProvisionerJob resource
 		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{})
@@ -2613,6 +2765,7 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		}).Asserts( /*rbac.ResourceSystem, policy.ActionCreate*/ )
 	}))
 	s.Run("InsertProvisionerJobLogs", s.Subtest(func(db database.Store, check *expects) {
@@ -2662,6 +2815,9 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("DeleteOldWorkspaceAgentLogs", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(time.Time{}).Asserts(rbac.ResourceSystem, policy.ActionDelete)
 	}))
+	s.Run("DeleteOldWorkspaceBuildStates", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Time{}).Asserts(rbac.ResourceSystem, policy.ActionDelete)
+	}))
 	s.Run("InsertWorkspaceAgentStats", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(database.InsertWorkspaceAgentStatsParams{}).Asserts(rbac.ResourceSystem, policy.ActionCreate).Errors(errMatchAny)
 	}))
@@ -2678,6 +2834,20 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("InsertWorkspaceAgentScripts", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(database.InsertWorkspaceAgentScriptsParams{}).Asserts(rbac.ResourceSystem, policy.ActionCreate)
 	}))
+	s.Run("UpdateWorkspaceAgentScriptDependsOnByID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateWorkspaceAgentScriptDependsOnByIDParams{
+			ID: uuid.New(),
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate)
+	}))
+	s.Run("InsertWorkspaceAgentDNSRecords", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertWorkspaceAgentDNSRecordsParams{}).Asserts(rbac.ResourceSystem, policy.ActionCreate)
+	}))
+	s.Run("InsertWorkspaceAgentService", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertWorkspaceAgentServiceParams{
+			ID:            uuid.New(),
+			RestartPolicy: database.WorkspaceAgentServiceRestartPolicyAlways,
+		}).Asserts(rbac.ResourceSystem, policy.ActionCreate)
+	}))
 	s.Run("InsertWorkspaceAgentMetadata", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(database.InsertWorkspaceAgentMetadataParams{}).Asserts(rbac.ResourceSystem, policy.ActionCreate)
 	}))
@@ -2802,6 +2972,12 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("GetWorkspaceAgentScriptsByAgentIDs", s.Subtest(func(db database.Store, check *expects) {
 		check.Args([]uuid.UUID{uuid.New()}).Asserts(rbac.ResourceSystem, policy.ActionRead)
 	}))
+	s.Run("GetWorkspaceAgentDNSRecordsByAgentIDs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args([]uuid.UUID{uuid.New()}).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+	s.Run("GetWorkspaceAgentServicesByAgentIDs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args([]uuid.UUID{uuid.New()}).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
 	s.Run("GetWorkspaceAgentLogSourcesByAgentIDs", s.Subtest(func(db database.Store, check *expects) {
 		check.Args([]uuid.UUID{uuid.New()}).Asserts(rbac.ResourceSystem, policy.ActionRead)
 	}))
@@ -177,6 +177,7 @@ func (b WorkspaceBuildBuilder) Do() WorkspaceResponse {
 		Input:          payload,
 		Tags:           map[string]string{},
 		TraceMetadata:  pqtype.NullRawMessage{},
+		Priority:       database.ProvisionerJobPriorityInteractive,
 	})
 	require.NoError(b.t, err, "insert job")
 
@@ -185,7 +186,7 @@ func (b WorkspaceBuildBuilder) Do() WorkspaceResponse {
 		// import job as well
 		for {
 			j, err := b.db.AcquireProvisionerJob(ownerCtx, database.AcquireProvisionerJobParams{
-				OrganizationID: job.OrganizationID,
+				OrganizationIDs: []uuid.UUID{job.OrganizationID},
 				StartedAt: sql.NullTime{
 					Time:  dbtime.Now(),
 					Valid: true,
@@ -191,21 +191,32 @@ func WorkspaceAgent(t testing.TB, db database.Store, orig database.WorkspaceAgen
 
 func WorkspaceAgentScript(t testing.TB, db database.Store, orig database.WorkspaceAgentScript) database.WorkspaceAgentScript {
 	scripts, err := db.InsertWorkspaceAgentScripts(genCtx, database.InsertWorkspaceAgentScriptsParams{
-		WorkspaceAgentID: takeFirst(orig.WorkspaceAgentID, uuid.New()),
-		CreatedAt:        takeFirst(orig.CreatedAt, dbtime.Now()),
-		LogSourceID:      []uuid.UUID{takeFirst(orig.LogSourceID, uuid.New())},
-		LogPath:          []string{takeFirst(orig.LogPath, "")},
-		Script:           []string{takeFirst(orig.Script, "")},
-		Cron:             []string{takeFirst(orig.Cron, "")},
-		StartBlocksLogin: []bool{takeFirst(orig.StartBlocksLogin, false)},
-		RunOnStart:       []bool{takeFirst(orig.RunOnStart, false)},
-		RunOnStop:        []bool{takeFirst(orig.RunOnStop, false)},
-		TimeoutSeconds:   []int32{takeFirst(orig.TimeoutSeconds, 0)},
-		DisplayName:      []string{takeFirst(orig.DisplayName, "")},
-		ID:               []uuid.UUID{takeFirst(orig.ID, uuid.New())},
+		WorkspaceAgentID:    takeFirst(orig.WorkspaceAgentID, uuid.New()),
+		CreatedAt:           takeFirst(orig.CreatedAt, dbtime.Now()),
+		LogSourceID:         []uuid.UUID{takeFirst(orig.LogSourceID, uuid.New())},
+		LogPath:             []string{takeFirst(orig.LogPath, "")},
+		Script:              []string{takeFirst(orig.Script, "")},
+		Cron:                []string{takeFirst(orig.Cron, "")},
+		StartBlocksLogin:    []bool{takeFirst(orig.StartBlocksLogin, false)},
+		RunOnStart:          []bool{takeFirst(orig.RunOnStart, false)},
+		RunOnStop:           []bool{takeFirst(orig.RunOnStop, false)},
+		TimeoutSeconds:      []int32{takeFirst(orig.TimeoutSeconds, 0)},
+		DisplayName:         []string{takeFirst(orig.DisplayName, "")},
+		ID:                  []uuid.UUID{takeFirst(orig.ID, uuid.New())},
+		ReadyPort:           []int32{takeFirst(orig.ReadyPort, 0)},
+		ReadyFile:           []string{takeFirst(orig.ReadyFile, "")},
+		ReadyTimeoutSeconds: []int32{takeFirst(orig.ReadyTimeoutSeconds, 0)},
 	})
 	require.NoError(t, err, "insert workspace agent script")
 	require.NotEmpty(t, scripts, "insert workspace agent script returned no scripts")
+	if len(orig.DependsOn) > 0 {
+		err = db.UpdateWorkspaceAgentScriptDependsOnByID(genCtx, database.UpdateWorkspaceAgentScriptDependsOnByIDParams{
+			ID:        scripts[0].ID,
+			DependsOn: orig.DependsOn,
+		})
+		require.NoError(t, err, "update workspace agent script depends on")
+		scripts[0].DependsOn = orig.DependsOn
+	}
 	return scripts[0]
 }
 
@@ -536,6 +547,7 @@ func ProvisionerJob(t testing.TB, db database.Store, ps pubsub.Pubsub, orig data
 		Input:          takeFirstSlice(orig.Input, []byte("{}")),
 		Tags:           orig.Tags,
 		TraceMetadata:  pqtype.NullRawMessage{},
+		Priority:       takeFirst(orig.Priority, database.ProvisionerJobPriorityInteractive),
 	})
 	require.NoError(t, err, "insert job")
 	if ps != nil {
@@ -545,7 +557,7 @@ func ProvisionerJob(t testing.TB, db database.Store, ps pubsub.Pubsub, orig data
 	if !orig.StartedAt.Time.IsZero() {
 		job, err = db.AcquireProvisionerJob(genCtx, database.AcquireProvisionerJobParams{
 			StartedAt:       orig.StartedAt,
-			OrganizationID:  job.OrganizationID,
+			OrganizationIDs: []uuid.UUID{job.OrganizationID},
 			Types:           []database.ProvisionerType{database.ProvisionerTypeEcho},
 			ProvisionerTags: must(json.Marshal(orig.Tags)),
 			WorkerID:        uuid.NullUUID{},
@@ -594,6 +606,10 @@ func ProvisionerKey(t testing.TB, db database.Store, orig database.ProvisionerKe
 }
 
 func WorkspaceApp(t testing.TB, db database.Store, orig database.WorkspaceApp) database.WorkspaceApp {
+	healthcheckURL := orig.HealthcheckUrl
+	if healthcheckURL == "" && orig.HealthcheckTcp == "" && orig.HealthcheckCommand == "" {
+		healthcheckURL = "https://localhost:8000"
+	}
 	resource, err := db.InsertWorkspaceApp(genCtx, database.InsertWorkspaceAppParams{
 		ID:          takeFirst(orig.ID, uuid.New()),
 		CreatedAt:   takeFirst(orig.CreatedAt, dbtime.Now()),
@@ -609,15 +625,20 @@ func WorkspaceApp(t testing.TB, db database.Store, orig database.WorkspaceApp) d
 			String: takeFirst(orig.Url.String),
 			Valid:  orig.Url.Valid,
 		},
-		External:             orig.External,
-		Subdomain:            orig.Subdomain,
-		SharingLevel:         takeFirst(orig.SharingLevel, database.AppSharingLevelOwner),
-		HealthcheckUrl:       takeFirst(orig.HealthcheckUrl, "https://localhost:8000"),
-		HealthcheckInterval:  takeFirst(orig.HealthcheckInterval, 60),
-		HealthcheckThreshold: takeFirst(orig.HealthcheckThreshold, 60),
-		Health:               takeFirst(orig.Health, database.WorkspaceAppHealthHealthy),
-		DisplayOrder:         takeFirst(orig.DisplayOrder, 1),
-		Hidden:               orig.Hidden,
+		External:                    orig.External,
+		Subdomain:                   orig.Subdomain,
+		SharingLevel:                takeFirst(orig.SharingLevel, database.AppSharingLevelOwner),
+		HealthcheckUrl:              healthcheckURL,
+		HealthcheckInterval:         takeFirst(orig.HealthcheckInterval, 60),
+		HealthcheckThreshold:        takeFirst(orig.HealthcheckThreshold, 60),
+		HealthcheckTcp:              orig.HealthcheckTcp,
+		HealthcheckCommand:          orig.HealthcheckCommand,
+		HealthcheckExpectedStatuses: takeFirstSlice(orig.HealthcheckExpectedStatuses, []int32{}),
+		HealthcheckBodyRegex:        orig.HealthcheckBodyRegex,
+		HealthcheckHeaders:          takeFirstSlice(orig.HealthcheckHeaders, []byte("{}")),
+		Health:                      takeFirst(orig.Health, database.WorkspaceAppHealthHealthy),
+		DisplayOrder:                takeFirst(orig.DisplayOrder, 1),
+		Hidden:                      orig.Hidden,
 	})
 	require.NoError(t, err, "insert app")
 	return resource
@@ -799,6 +820,8 @@ func TemplateVersion(t testing.TB, db database.Store, orig database.TemplateVers
 			JobID:           takeFirst(orig.JobID, uuid.New()),
 			CreatedBy:       takeFirst(orig.CreatedBy, uuid.New()),
 			SourceExampleID: takeFirst(orig.SourceExampleID, sql.NullString{}),
+			GitCommitSHA:    orig.GitCommitSHA,
+			GitCommitAuthor: orig.GitCommitAuthor,
 		})
 		if err != nil {
 			return err
@@ -815,6 +838,26 @@ func TemplateVersion(t testing.TB, db database.Store, orig database.TemplateVers
 	return version
 }
 
+func TemplateGitSource(t testing.TB, db database.Store, orig database.TemplateGitSource) database.TemplateGitSource {
+	source, err := db.UpsertTemplateGitSource(genCtx, database.UpsertTemplateGitSourceParams{
+		TemplateID:             takeFirst(orig.TemplateID, uuid.New()),
+		UserID:                 takeFirst(orig.UserID, uuid.New()),
+		UpdatedAt:              takeFirst(orig.UpdatedAt, dbtime.Now()),
+		RepoURL:                takeFirst(orig.RepoURL, "https://example.com/"+testutil.GetRandomName(t)+".git"),
+		Path:                   orig.Path,
+		Ref:                    orig.Ref,
+		ExternalAuthProviderID: orig.ExternalAuthProviderID,
+		DeployPrivateKey:       orig.DeployPrivateKey,
+		DeployPrivateKeyKeyID:  orig.DeployPrivateKeyKeyID,
+		DeployPublicKey:        orig.DeployPublicKey,
+		KnownHosts:             orig.KnownHosts,
+		WebhookSecret:          takeFirst(orig.WebhookSecret, testutil.GetRandomName(t)),
+		AutoPromote:            orig.AutoPromote,
+	})
+	require.NoError(t, err, "insert template git source")
+	return source
+}
+
 func TemplateVersionVariable(t testing.TB, db database.Store, orig database.TemplateVersionVariable) database.TemplateVersionVariable {
 	version, err := db.InsertTemplateVersionVariable(genCtx, database.InsertTemplateVersionVariableParams{
 		TemplateVersionID: takeFirst(orig.TemplateVersionID, uuid.New()),
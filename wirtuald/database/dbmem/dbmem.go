@@ -212,6 +212,7 @@ type data struct {
 	provisionerJobs                 []database.ProvisionerJob
 	provisionerKeys                 []database.ProvisionerKey
 	replicas                        []database.Replica
+	templateGitSources              []database.TemplateGitSource
 	templateVersions                []database.TemplateVersionTable
 	templateVersionParameters       []database.TemplateVersionParameter
 	templateVersionVariables        []database.TemplateVersionVariable
@@ -219,18 +220,22 @@ type data struct {
 	templates                       []database.TemplateTable
 	templateUsageStats              []database.TemplateUsageStat
 	workspaceAgents                 []database.WorkspaceAgent
+	workspaceAgentDevcontainers     []database.WorkspaceAgentDevcontainer
+	workspaceAgentDNSRecords        []database.WorkspaceAgentDNSRecord
 	workspaceAgentMetadata          []database.WorkspaceAgentMetadatum
 	workspaceAgentLogs              []database.WorkspaceAgentLog
 	workspaceAgentLogSources        []database.WorkspaceAgentLogSource
 	workspaceAgentPortShares        []database.WorkspaceAgentPortShare
 	workspaceAgentScriptTimings     []database.WorkspaceAgentScriptTiming
 	workspaceAgentScripts           []database.WorkspaceAgentScript
+	workspaceAgentServices          []database.WorkspaceAgentService
 	workspaceAgentStats             []database.WorkspaceAgentStat
 	workspaceApps                   []database.WorkspaceApp
 	workspaceAppStatsLastInsertID   int64
 	workspaceAppStats               []database.WorkspaceAppStat
 	workspaceBuilds                 []database.WorkspaceBuild
 	workspaceBuildParameters        []database.WorkspaceBuildParameter
+	workspaceDrift                  []database.WorkspaceDrift
 	workspaceResourceMetadata       []database.WorkspaceResourceMetadatum
 	workspaceResources              []database.WorkspaceResource
 	workspaceModules                []database.WorkspaceModule
@@ -943,6 +948,27 @@ func minTime(t, u time.Time) time.Time {
 	return u
 }
 
+func provisionerJobPriorityRank(priority database.ProvisionerJobPriority) int {
+	return slices.Index(database.AllProvisionerJobPriorityValues(), priority)
+}
+
+// countRunningProvisionerJobsNoLock returns the running jobs of the
+// organization and of the initiator.
+func (q *FakeQuerier) countRunningProvisionerJobsNoLock(organizationID, initiatorID uuid.UUID) (orgRunning int, initiatorRunning int) {
+	for _, job := range q.provisionerJobs {
+		if !job.StartedAt.Valid || job.CompletedAt.Valid {
+			continue
+		}
+		if job.OrganizationID == organizationID {
+			orgRunning++
+		}
+		if job.InitiatorID == initiatorID {
+			initiatorRunning++
+		}
+	}
+	return orgRunning, initiatorRunning
+}
+
 func provisionerJobStatus(j database.ProvisionerJob) database.ProvisionerJobStatus {
 	if isNotNull(j.CompletedAt) {
 		if j.Error.String != "" {
@@ -1177,8 +1203,10 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
+	acquire := -1
+	acquireOrgRunning, acquireRunning := 0, 0
 	for index, provisionerJob := range q.provisionerJobs {
-		if provisionerJob.OrganizationID != arg.OrganizationID {
+		if !slices.Contains(arg.OrganizationIDs, provisionerJob.OrganizationID) {
 			continue
 		}
 		if provisionerJob.StartedAt.Valid {
@@ -1214,18 +1242,89 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 		if !tagsSubset(provisionerJob.Tags, tags) {
 			continue
 		}
-		provisionerJob.StartedAt = arg.StartedAt
-		provisionerJob.UpdatedAt = arg.StartedAt.Time
-		provisionerJob.WorkerID = arg.WorkerID
-		provisionerJob.JobStatus = provisionerJobStatus(provisionerJob)
-		q.provisionerJobs[index] = provisionerJob
-		// clone the Tags before returning, since maps are reference types and
-		// we don't want the caller to be able to mutate the map we have inside
-		// dbmem!
-		provisionerJob.Tags = maps.Clone(provisionerJob.Tags)
-		return provisionerJob, nil
+		// Order by priority, then by the running jobs of the organization,
+		// then by the running jobs of the initiator, then by creation time.
+		orgRunning, running := q.countRunningProvisionerJobsNoLock(provisionerJob.OrganizationID, provisionerJob.InitiatorID)
+		if acquire != -1 {
+			current := q.provisionerJobs[acquire]
+			rank, currentRank := provisionerJobPriorityRank(provisionerJob.Priority), provisionerJobPriorityRank(current.Priority)
+			if rank != currentRank {
+				if rank > currentRank {
+					continue
+				}
+			} else if orgRunning != acquireOrgRunning {
+				if orgRunning > acquireOrgRunning {
+					continue
+				}
+			} else if running >= acquireRunning {
+				continue
+			}
+		}
+		acquire, acquireOrgRunning, acquireRunning = index, orgRunning, running
 	}
-	return database.ProvisionerJob{}, sql.ErrNoRows
+	if acquire == -1 {
+		return database.ProvisionerJob{}, sql.ErrNoRows
+	}
+
+	provisionerJob := q.provisionerJobs[acquire]
+	provisionerJob.StartedAt = arg.StartedAt
+	provisionerJob.UpdatedAt = arg.StartedAt.Time
+	provisionerJob.WorkerID = arg.WorkerID
+	provisionerJob.JobStatus = provisionerJobStatus(provisionerJob)
+	q.provisionerJobs[acquire] = provisionerJob
+	// clone the Tags before returning, since maps are reference types and
+	// we don't want the caller to be able to mutate the map we have inside
+	// dbmem!
+	provisionerJob.Tags = maps.Clone(provisionerJob.Tags)
+	return provisionerJob, nil
+}
+
+func (q *FakeQuerier) AcquireTemplateGitSources(_ context.Context, arg database.AcquireTemplateGitSourcesParams) ([]database.TemplateGitSource, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var indexes []int
+	for i, source := range q.templateGitSources {
+		if source.JobID.Valid {
+			continue
+		}
+		if !source.SyncRequestedAt.Valid && source.LastCheckedAt.Valid && !source.LastCheckedAt.Time.Before(arg.CheckedBefore) {
+			continue
+		}
+		template, err := q.getTemplateByIDNoLock(context.Background(), source.TemplateID)
+		if err != nil || template.Deleted {
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		sa, sb := q.templateGitSources[indexes[a]], q.templateGitSources[indexes[b]]
+		if sa.SyncRequestedAt.Valid != sb.SyncRequestedAt.Valid {
+			return sa.SyncRequestedAt.Valid
+		}
+		if sa.SyncRequestedAt.Valid && !sa.SyncRequestedAt.Time.Equal(sb.SyncRequestedAt.Time) {
+			return sa.SyncRequestedAt.Time.Before(sb.SyncRequestedAt.Time)
+		}
+		if sa.LastCheckedAt.Valid != sb.LastCheckedAt.Valid {
+			return !sa.LastCheckedAt.Valid
+		}
+		return sa.LastCheckedAt.Time.Before(sb.LastCheckedAt.Time)
+	})
+	if len(indexes) > int(arg.LimitOpt) {
+		indexes = indexes[:arg.LimitOpt]
+	}
+
+	sources := make([]database.TemplateGitSource, 0, len(indexes))
+	for _, i := range indexes {
+		q.templateGitSources[i].LastCheckedAt = sql.NullTime{Time: arg.Now, Valid: true}
+		q.templateGitSources[i].SyncRequestedAt = sql.NullTime{}
+		sources = append(sources, q.templateGitSources[i])
+	}
+	return sources, nil
 }
 
 func (q *FakeQuerier) ActivityBumpWorkspace(ctx context.Context, arg database.ActivityBumpWorkspaceParams) error {
@@ -2019,6 +2118,24 @@ func (q *FakeQuerier) DeleteOldWorkspaceAgentStats(_ context.Context) error {
 	return nil
 }
 
+func (q *FakeQuerier) DeleteOldWorkspaceBuildStates(_ context.Context, threshold time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	latestBuilds := make(map[uuid.UUID]int32)
+	for _, wb := range q.workspaceBuilds {
+		if latestBuilds[wb.WorkspaceID] < wb.BuildNumber {
+			latestBuilds[wb.WorkspaceID] = wb.BuildNumber
+		}
+	}
+	for i, wb := range q.workspaceBuilds {
+		if wb.BuildNumber < latestBuilds[wb.WorkspaceID] && wb.CreatedAt.Before(threshold) {
+			q.workspaceBuilds[i].ProvisionerState = nil
+		}
+	}
+	return nil
+}
+
 func (q *FakeQuerier) DeleteOrganization(_ context.Context, id uuid.UUID) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2125,6 +2242,19 @@ func (*FakeQuerier) DeleteTailnetTunnel(_ context.Context, arg database.DeleteTa
 	return database.DeleteTailnetTunnelRow{}, ErrUnimplemented
 }
 
+func (q *FakeQuerier) DeleteTemplateGitSource(_ context.Context, templateID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, source := range q.templateGitSources {
+		if source.TemplateID == templateID {
+			q.templateGitSources = append(q.templateGitSources[:i], q.templateGitSources[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 func (q *FakeQuerier) DeleteWorkspaceAgentPortShare(_ context.Context, arg database.DeleteWorkspaceAgentPortShareParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -3709,38 +3839,65 @@ func (q *FakeQuerier) GetProvisionerJobsByIDsWithQueuePosition(_ context.Context
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	now := dbtime.Now()
 	jobs := make([]database.GetProvisionerJobsByIDsWithQueuePositionRow, 0)
-	queuePosition := int64(1)
 	for _, job := range q.provisionerJobs {
-		for _, id := range ids {
-			if id == job.ID {
-				// clone the Tags before appending, since maps are reference types and
-				// we don't want the caller to be able to mutate the map we have inside
-				// dbmem!
-				job.Tags = maps.Clone(job.Tags)
-				job := database.GetProvisionerJobsByIDsWithQueuePositionRow{
-					ProvisionerJob: job,
-				}
-				if !job.ProvisionerJob.StartedAt.Valid {
-					job.QueuePosition = queuePosition
+		if !slices.Contains(ids, job.ID) {
+			continue
+		}
+		// clone the Tags before appending, since maps are reference types and
+		// we don't want the caller to be able to mutate the map we have inside
+		// dbmem!
+		job.Tags = maps.Clone(job.Tags)
+		row := database.GetProvisionerJobsByIDsWithQueuePositionRow{
+			ProvisionerJob: job,
+		}
+
+		var (
+			durations time.Duration
+			completed int64
+		)
+		for _, other := range q.provisionerJobs {
+			if other.OrganizationID != job.OrganizationID {
+				continue
+			}
+			if !other.StartedAt.Valid {
+				row.QueueSize++
+				if !job.StartedAt.Valid && !provisionerJobQueuedAfter(other, job) {
+					row.QueuePosition++
 				}
-				jobs = append(jobs, job)
-				break
+				continue
+			}
+			if other.CompletedAt.Valid && other.CompletedAt.Time.After(now.Add(-24*time.Hour)) {
+				durations += other.CompletedAt.Time.Sub(other.StartedAt.Time)
+				completed++
 			}
 		}
-		if !job.StartedAt.Valid {
-			queuePosition++
-		}
-	}
-	for _, job := range jobs {
-		if !job.ProvisionerJob.StartedAt.Valid {
-			// Set it to the max position!
-			job.QueueSize = queuePosition
+		if row.QueuePosition > 0 && completed > 0 {
+			var daemons int64
+			for _, daemon := range q.provisionerDaemons {
+				if daemon.OrganizationID == job.OrganizationID && daemon.LastSeenAt.Valid && now.Sub(daemon.LastSeenAt.Time) < 90*time.Second {
+					daemons++
+				}
+			}
+			daemons = max(daemons, 1)
+			rounds := (row.QueuePosition + daemons - 1) / daemons
+			row.QueueEstimatedWaitMs = rounds * (durations / time.Duration(completed)).Milliseconds()
 		}
+		jobs = append(jobs, row)
 	}
 	return jobs, nil
 }
 
+// provisionerJobQueuedAfter reports whether a is behind b in the queue.
+func provisionerJobQueuedAfter(a, b database.ProvisionerJob) bool {
+	rankA, rankB := provisionerJobPriorityRank(a.Priority), provisionerJobPriorityRank(b.Priority)
+	if rankA != rankB {
+		return rankA > rankB
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
 func (q *FakeQuerier) GetProvisionerJobsCreatedAfter(_ context.Context, after time.Time) ([]database.ProvisionerJob, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -4482,6 +4639,49 @@ func (q *FakeQuerier) GetTemplateDAUs(_ context.Context, arg database.GetTemplat
 	return rs, nil
 }
 
+func (q *FakeQuerier) GetTemplateGitSourceByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, source := range q.templateGitSources {
+		if source.TemplateID == templateID {
+			return source, nil
+		}
+	}
+	return database.TemplateGitSource{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetTemplateGitSourcesWithCompletedJobs(ctx context.Context) ([]database.TemplateGitSource, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var sources []database.TemplateGitSource
+	for _, source := range q.templateGitSources {
+		if !source.JobID.Valid {
+			continue
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, source.JobID.UUID)
+		if err != nil || !job.CompletedAt.Valid {
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func (q *FakeQuerier) GetTemplateGitSourcesWithDeployKeys(_ context.Context) ([]database.TemplateGitSource, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var sources []database.TemplateGitSource
+	for _, source := range q.templateGitSources {
+		if source.DeployPrivateKey != "" {
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
 func (q *FakeQuerier) GetTemplateInsights(_ context.Context, arg database.GetTemplateInsightsParams) (database.GetTemplateInsightsRow, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -5787,6 +5987,35 @@ func (q *FakeQuerier) GetWorkspaceAgentByInstanceID(_ context.Context, instanceI
 	return database.WorkspaceAgent{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetWorkspaceAgentDNSRecordsByAgentIDs(_ context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentDNSRecord, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	records := make([]database.WorkspaceAgentDNSRecord, 0)
+	for _, record := range q.workspaceAgentDNSRecords {
+		if slices.Contains(ids, record.WorkspaceAgentID) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (q *FakeQuerier) GetWorkspaceAgentDevcontainersByAgentID(_ context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentDevcontainer, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	devcontainers := make([]database.WorkspaceAgentDevcontainer, 0)
+	for _, devcontainer := range q.workspaceAgentDevcontainers {
+		if devcontainer.WorkspaceAgentID == workspaceAgentID {
+			devcontainers = append(devcontainers, devcontainer)
+		}
+	}
+	slices.SortFunc(devcontainers, func(a, b database.WorkspaceAgentDevcontainer) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return devcontainers, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceAgentLifecycleStateByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceAgentLifecycleStateByIDRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -5818,6 +6047,39 @@ func (q *FakeQuerier) GetWorkspaceAgentLogSourcesByAgentIDs(_ context.Context, i
 	return logSources, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceAgentLogs(_ context.Context, arg database.GetWorkspaceAgentLogsParams) ([]database.WorkspaceAgentLog, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	logs := []database.WorkspaceAgentLog{}
+	for _, log := range q.workspaceAgentLogs {
+		if log.AgentID != arg.AgentID {
+			continue
+		}
+		if log.ID <= arg.AfterID {
+			continue
+		}
+		if !arg.CreatedAfter.IsZero() && !log.CreatedAt.After(arg.CreatedAfter) {
+			continue
+		}
+		if len(arg.LogSourceIDs) > 0 && !slices.Contains(arg.LogSourceIDs, log.LogSourceID) {
+			continue
+		}
+		if len(arg.Levels) > 0 && !slices.Contains(arg.Levels, log.Level) {
+			continue
+		}
+		logs = append(logs, log)
+		if arg.LimitOpt > 0 && len(logs) >= int(arg.LimitOpt) {
+			break
+		}
+	}
+	return logs, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceAgentLogsAfter(_ context.Context, arg database.GetWorkspaceAgentLogsAfterParams) ([]database.WorkspaceAgentLog, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -5963,6 +6225,22 @@ func (q *FakeQuerier) GetWorkspaceAgentScriptsByAgentIDs(_ context.Context, ids
 	return q.getWorkspaceAgentScriptsByAgentIDsNoLock(ids)
 }
 
+func (q *FakeQuerier) GetWorkspaceAgentServicesByAgentIDs(_ context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentService, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	services := make([]database.WorkspaceAgentService, 0)
+	for _, service := range q.workspaceAgentServices {
+		if slices.Contains(ids, service.WorkspaceAgentID) {
+			services = append(services, service)
+		}
+	}
+	slices.SortFunc(services, func(a, b database.WorkspaceAgentService) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return services, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceAgentStats(_ context.Context, createdAfter time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -6605,6 +6883,62 @@ func (q *FakeQuerier) GetWorkspaceBuildsCreatedAfter(_ context.Context, after ti
 	return workspaceBuilds, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceBuildsForDriftCheck(ctx context.Context, checkedBefore time.Time) ([]database.GetWorkspaceBuildsForDriftCheckRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetWorkspaceBuildsForDriftCheckRow, 0)
+	for _, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.DormantAt.Valid {
+			continue
+		}
+		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+		if err != nil {
+			continue
+		}
+		if build.Transition != database.WorkspaceTransitionStart {
+			continue
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+		if err != nil {
+			return nil, err
+		}
+		if provisionerJobStatus(job) != database.ProvisionerJobStatusSucceeded {
+			continue
+		}
+
+		checked := false
+		for _, drift := range q.workspaceDrift {
+			if drift.WorkspaceBuildID != build.ID {
+				continue
+			}
+			if !drift.CreatedAt.Before(checkedBefore) {
+				checked = true
+				break
+			}
+			// Never start a check while the previous one is still running.
+			driftJob, err := q.getProvisionerJobByIDNoLock(ctx, drift.JobID)
+			checked = err != nil || !driftJob.CompletedAt.Valid
+			break
+		}
+		if checked {
+			continue
+		}
+
+		rows = append(rows, database.GetWorkspaceBuildsForDriftCheckRow{
+			WorkspaceBuildID: build.ID,
+			WorkspaceID:      workspace.ID,
+			OwnerID:          workspace.OwnerID,
+			OrganizationID:   job.OrganizationID,
+			Provisioner:      job.Provisioner,
+			StorageMethod:    job.StorageMethod,
+			FileID:           job.FileID,
+			Tags:             job.Tags,
+		})
+	}
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceByAgentID(ctx context.Context, agentID uuid.UUID) (database.Workspace, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -6673,6 +7007,31 @@ func (q *FakeQuerier) GetWorkspaceByWorkspaceAppID(_ context.Context, workspaceA
 	return database.Workspace{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetWorkspaceDriftByBuildID(_ context.Context, workspaceBuildID uuid.UUID) (database.WorkspaceDrift, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, drift := range q.workspaceDrift {
+		if drift.WorkspaceBuildID == workspaceBuildID {
+			return drift, nil
+		}
+	}
+	return database.WorkspaceDrift{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetWorkspaceDriftByBuildIDs(_ context.Context, ids []uuid.UUID) ([]database.WorkspaceDrift, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	drift := make([]database.WorkspaceDrift, 0)
+	for _, d := range q.workspaceDrift {
+		if slices.Contains(ids, d.WorkspaceBuildID) {
+			drift = append(drift, d)
+		}
+	}
+	return drift, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceModulesByJobID(_ context.Context, jobID uuid.UUID) ([]database.WorkspaceModule, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -7538,6 +7897,7 @@ func (q *FakeQuerier) InsertProvisionerJob(_ context.Context, arg database.Inser
 		Input:          arg.Input,
 		Tags:           maps.Clone(arg.Tags),
 		TraceMetadata:  arg.TraceMetadata,
+		Priority:       arg.Priority,
 	}
 	job.JobStatus = provisionerJobStatus(job)
 	q.provisionerJobs = append(q.provisionerJobs, job)
@@ -7710,6 +8070,8 @@ func (q *FakeQuerier) InsertTemplateVersion(_ context.Context, arg database.Inse
 		JobID:           arg.JobID,
 		CreatedBy:       arg.CreatedBy,
 		SourceExampleID: arg.SourceExampleID,
+		GitCommitSHA:    arg.GitCommitSHA,
+		GitCommitAuthor: arg.GitCommitAuthor,
 	}
 	q.templateVersions = append(q.templateVersions, version)
 	return nil
@@ -7966,6 +8328,33 @@ func (q *FakeQuerier) InsertWorkspaceAgent(_ context.Context, arg database.Inser
 	return agent, nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceAgentDNSRecords(_ context.Context, arg database.InsertWorkspaceAgentDNSRecordsParams) ([]database.WorkspaceAgentDNSRecord, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	records := make([]database.WorkspaceAgentDNSRecord, 0, len(arg.Name))
+	for i, name := range arg.Name {
+		for _, existing := range q.workspaceAgentDNSRecords {
+			if existing.WorkspaceAgentID == arg.WorkspaceAgentID && existing.Name == name {
+				return nil, errUniqueConstraint
+			}
+		}
+		records = append(records, database.WorkspaceAgentDNSRecord{
+			WorkspaceAgentID: arg.WorkspaceAgentID,
+			Name:             name,
+			CreatedAt:        arg.CreatedAt,
+			Port:             arg.Port[i],
+		})
+	}
+	q.workspaceAgentDNSRecords = append(q.workspaceAgentDNSRecords, records...)
+	return records, nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceAgentLogSources(_ context.Context, arg database.InsertWorkspaceAgentLogSourcesParams) ([]database.WorkspaceAgentLogSource, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -8081,17 +8470,21 @@ func (q *FakeQuerier) InsertWorkspaceAgentScripts(_ context.Context, arg databas
 	scripts := make([]database.WorkspaceAgentScript, 0)
 	for index, source := range arg.LogSourceID {
 		script := database.WorkspaceAgentScript{
-			LogSourceID:      source,
-			WorkspaceAgentID: arg.WorkspaceAgentID,
-			ID:               arg.ID[index],
-			LogPath:          arg.LogPath[index],
-			Script:           arg.Script[index],
-			Cron:             arg.Cron[index],
-			StartBlocksLogin: arg.StartBlocksLogin[index],
-			RunOnStart:       arg.RunOnStart[index],
-			RunOnStop:        arg.RunOnStop[index],
-			TimeoutSeconds:   arg.TimeoutSeconds[index],
-			CreatedAt:        arg.CreatedAt,
+			LogSourceID:         source,
+			WorkspaceAgentID:    arg.WorkspaceAgentID,
+			ID:                  arg.ID[index],
+			LogPath:             arg.LogPath[index],
+			Script:              arg.Script[index],
+			Cron:                arg.Cron[index],
+			StartBlocksLogin:    arg.StartBlocksLogin[index],
+			RunOnStart:          arg.RunOnStart[index],
+			RunOnStop:           arg.RunOnStop[index],
+			TimeoutSeconds:      arg.TimeoutSeconds[index],
+			CreatedAt:           arg.CreatedAt,
+			DependsOn:           []uuid.UUID{},
+			ReadyPort:           arg.ReadyPort[index],
+			ReadyFile:           arg.ReadyFile[index],
+			ReadyTimeoutSeconds: arg.ReadyTimeoutSeconds[index],
 		}
 		scripts = append(scripts, script)
 	}
@@ -8099,6 +8492,42 @@ func (q *FakeQuerier) InsertWorkspaceAgentScripts(_ context.Context, arg databas
 	return scripts, nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceAgentService(_ context.Context, arg database.InsertWorkspaceAgentServiceParams) (database.WorkspaceAgentService, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.WorkspaceAgentService{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, service := range q.workspaceAgentServices {
+		if service.WorkspaceAgentID == arg.WorkspaceAgentID && service.Name == arg.Name {
+			return database.WorkspaceAgentService{}, errUniqueConstraint
+		}
+	}
+
+	service := database.WorkspaceAgentService{
+		ID:                       arg.ID,
+		WorkspaceAgentID:         arg.WorkspaceAgentID,
+		CreatedAt:                arg.CreatedAt,
+		Name:                     arg.Name,
+		DisplayName:              arg.DisplayName,
+		Command:                  arg.Command,
+		Directory:                arg.Directory,
+		Env:                      arg.Env,
+		RestartPolicy:            arg.RestartPolicy,
+		RestartBackoffSeconds:    arg.RestartBackoffSeconds,
+		MaxRestartBackoffSeconds: arg.MaxRestartBackoffSeconds,
+		HealthcheckUrl:           arg.HealthcheckUrl,
+		HealthcheckInterval:      arg.HealthcheckInterval,
+		HealthcheckThreshold:     arg.HealthcheckThreshold,
+		State:                    database.WorkspaceAgentServiceStatePending,
+	}
+	q.workspaceAgentServices = append(q.workspaceAgentServices, service)
+	return service, nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceAgentStats(_ context.Context, arg database.InsertWorkspaceAgentStatsParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -8157,23 +8586,28 @@ func (q *FakeQuerier) InsertWorkspaceApp(_ context.Context, arg database.InsertW
 
 	// nolint:gosimple
 	workspaceApp := database.WorkspaceApp{
-		ID:                   arg.ID,
-		AgentID:              arg.AgentID,
-		CreatedAt:            arg.CreatedAt,
-		Slug:                 arg.Slug,
-		DisplayName:          arg.DisplayName,
-		Icon:                 arg.Icon,
-		Command:              arg.Command,
-		Url:                  arg.Url,
-		External:             arg.External,
-		Subdomain:            arg.Subdomain,
-		SharingLevel:         arg.SharingLevel,
-		HealthcheckUrl:       arg.HealthcheckUrl,
-		HealthcheckInterval:  arg.HealthcheckInterval,
-		HealthcheckThreshold: arg.HealthcheckThreshold,
-		Health:               arg.Health,
-		Hidden:               arg.Hidden,
-		DisplayOrder:         arg.DisplayOrder,
+		ID:                          arg.ID,
+		AgentID:                     arg.AgentID,
+		CreatedAt:                   arg.CreatedAt,
+		Slug:                        arg.Slug,
+		DisplayName:                 arg.DisplayName,
+		Icon:                        arg.Icon,
+		Command:                     arg.Command,
+		Url:                         arg.Url,
+		External:                    arg.External,
+		Subdomain:                   arg.Subdomain,
+		SharingLevel:                arg.SharingLevel,
+		HealthcheckUrl:              arg.HealthcheckUrl,
+		HealthcheckInterval:         arg.HealthcheckInterval,
+		HealthcheckThreshold:        arg.HealthcheckThreshold,
+		Health:                      arg.Health,
+		Hidden:                      arg.Hidden,
+		DisplayOrder:                arg.DisplayOrder,
+		HealthcheckTcp:              arg.HealthcheckTcp,
+		HealthcheckCommand:          arg.HealthcheckCommand,
+		HealthcheckExpectedStatuses: arg.HealthcheckExpectedStatuses,
+		HealthcheckBodyRegex:        arg.HealthcheckBodyRegex,
+		HealthcheckHeaders:          arg.HealthcheckHeaders,
 	}
 	q.workspaceApps = append(q.workspaceApps, workspaceApp)
 	return workspaceApp, nil
@@ -8623,6 +9057,23 @@ func (q *FakeQuerier) RemoveUserFromGroups(_ context.Context, arg database.Remov
 	return removed, nil
 }
 
+func (q *FakeQuerier) RequestTemplateGitSourceSync(_ context.Context, arg database.RequestTemplateGitSourceSyncParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, source := range q.templateGitSources {
+		if source.TemplateID == arg.TemplateID {
+			q.templateGitSources[i].SyncRequestedAt = sql.NullTime{Time: arg.RequestedAt, Valid: true}
+			return nil
+		}
+	}
+	return nil
+}
+
 func (q *FakeQuerier) RevokeDBCryptKey(_ context.Context, activeKeyDigest string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -9031,6 +9482,27 @@ func (q *FakeQuerier) UpdateProvisionerJobByID(_ context.Context, arg database.U
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateProvisionerJobEngineByID(_ context.Context, arg database.UpdateProvisionerJobEngineByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, job := range q.provisionerJobs {
+		if arg.ID != job.ID {
+			continue
+		}
+		job.UpdatedAt = arg.UpdatedAt
+		job.Engine = arg.Engine
+		job.EngineVersion = arg.EngineVersion
+		q.provisionerJobs[index] = job
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateProvisionerJobWithCancelByID(_ context.Context, arg database.UpdateProvisionerJobWithCancelByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -9188,6 +9660,50 @@ func (q *FakeQuerier) UpdateTemplateDeletedByID(_ context.Context, arg database.
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateTemplateGitSourceDeployKeyByTemplateID(_ context.Context, arg database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, source := range q.templateGitSources {
+		if source.TemplateID != arg.TemplateID {
+			continue
+		}
+		source.DeployPrivateKey = arg.DeployPrivateKey
+		source.DeployPrivateKeyKeyID = arg.DeployPrivateKeyKeyID
+		q.templateGitSources[i] = source
+		return nil
+	}
+	return nil
+}
+
+func (q *FakeQuerier) UpdateTemplateGitSourceSyncByTemplateID(_ context.Context, arg database.UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, source := range q.templateGitSources {
+		if source.TemplateID != arg.TemplateID {
+			continue
+		}
+		source.SyncStatus = arg.SyncStatus
+		source.SyncError = arg.SyncError
+		source.LastSyncedAt = arg.LastSyncedAt
+		source.LastCommitSHA = arg.LastCommitSHA
+		source.TemplateVersionID = arg.TemplateVersionID
+		source.JobID = arg.JobID
+		q.templateGitSources[i] = source
+		return nil
+	}
+	return nil
+}
+
 func (q *FakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.UpdateTemplateMetaByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -9208,6 +9724,7 @@ func (q *FakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.Upd
 		tpl.GroupACL = arg.GroupACL
 		tpl.AllowUserCancelWorkspaceJobs = arg.AllowUserCancelWorkspaceJobs
 		tpl.MaxPortSharingLevel = arg.MaxPortSharingLevel
+		tpl.JobTimeout = arg.JobTimeout
 		q.templates[idx] = tpl
 		return nil
 	}
@@ -9776,6 +10293,50 @@ func (q *FakeQuerier) UpdateWorkspaceAgentMetadata(_ context.Context, arg databa
 	return nil
 }
 
+func (q *FakeQuerier) UpdateWorkspaceAgentScriptDependsOnByID(_ context.Context, arg database.UpdateWorkspaceAgentScriptDependsOnByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, script := range q.workspaceAgentScripts {
+		if script.ID != arg.ID {
+			continue
+		}
+		script.DependsOn = arg.DependsOn
+		q.workspaceAgentScripts[i] = script
+		return nil
+	}
+
+	return nil
+}
+
+func (q *FakeQuerier) UpdateWorkspaceAgentServiceStatusByID(_ context.Context, arg database.UpdateWorkspaceAgentServiceStatusByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, service := range q.workspaceAgentServices {
+		if service.ID != arg.ID || service.WorkspaceAgentID != arg.WorkspaceAgentID {
+			continue
+		}
+		service.State = arg.State
+		service.RestartCount = arg.RestartCount
+		service.ExitCode = arg.ExitCode
+		service.StatusMessage = arg.StatusMessage
+		service.StateChangedAt = arg.StateChangedAt
+		q.workspaceAgentServices[i] = service
+		return nil
+	}
+
+	return nil
+}
+
 func (q *FakeQuerier) UpdateWorkspaceAgentStartupByID(_ context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -9829,6 +10390,7 @@ func (q *FakeQuerier) UpdateWorkspaceAppHealthByID(_ context.Context, arg databa
 			continue
 		}
 		app.Health = arg.Health
+		app.HealthReason = arg.HealthReason
 		q.workspaceApps[index] = app
 		return nil
 	}
@@ -9997,6 +10559,27 @@ func (q *FakeQuerier) UpdateWorkspaceDormantDeletingAt(_ context.Context, arg da
 	return database.WorkspaceTable{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateWorkspaceDriftByJobID(_ context.Context, arg database.UpdateWorkspaceDriftByJobIDParams) (database.WorkspaceDrift, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.WorkspaceDrift{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for idx, drift := range q.workspaceDrift {
+		if drift.JobID != arg.JobID {
+			continue
+		}
+		drift.CheckedAt = arg.CheckedAt
+		drift.Resources = arg.Resources
+		q.workspaceDrift[idx] = drift
+		return drift, nil
+	}
+	return database.WorkspaceDrift{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateWorkspaceLastUsedAt(_ context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -10057,6 +10640,21 @@ func (q *FakeQuerier) UpdateWorkspaceProxyDeleted(_ context.Context, arg databas
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateWorkspaceProxyDraining(_ context.Context, arg database.UpdateWorkspaceProxyDrainingParams) (database.WorkspaceProxy, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, p := range q.workspaceProxies {
+		if p.ID == arg.ID {
+			p.Draining = arg.Draining
+			p.UpdatedAt = dbtime.Now()
+			q.workspaceProxies[i] = p
+			return p, nil
+		}
+	}
+	return database.WorkspaceProxy{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateWorkspaceTTL(_ context.Context, arg database.UpdateWorkspaceTTLParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -10335,6 +10933,60 @@ func (*FakeQuerier) UpsertTailnetTunnel(_ context.Context, arg database.UpsertTa
 	return database.TailnetTunnel{}, ErrUnimplemented
 }
 
+func (q *FakeQuerier) UpsertTemplateGitSource(_ context.Context, arg database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateGitSource{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, source := range q.templateGitSources {
+		if source.TemplateID != arg.TemplateID {
+			continue
+		}
+		if source.RepoURL != arg.RepoURL || source.Path != arg.Path || source.Ref != arg.Ref {
+			source.LastCommitSHA = ""
+		}
+		source.UserID = arg.UserID
+		source.UpdatedAt = arg.UpdatedAt
+		source.RepoURL = arg.RepoURL
+		source.Path = arg.Path
+		source.Ref = arg.Ref
+		source.ExternalAuthProviderID = arg.ExternalAuthProviderID
+		source.DeployPrivateKey = arg.DeployPrivateKey
+		source.DeployPrivateKeyKeyID = arg.DeployPrivateKeyKeyID
+		source.DeployPublicKey = arg.DeployPublicKey
+		source.KnownHosts = arg.KnownHosts
+		source.WebhookSecret = arg.WebhookSecret
+		source.AutoPromote = arg.AutoPromote
+		source.SyncRequestedAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
+		q.templateGitSources[i] = source
+		return source, nil
+	}
+
+	source := database.TemplateGitSource{
+		TemplateID:             arg.TemplateID,
+		UserID:                 arg.UserID,
+		CreatedAt:              arg.UpdatedAt,
+		UpdatedAt:              arg.UpdatedAt,
+		RepoURL:                arg.RepoURL,
+		Path:                   arg.Path,
+		Ref:                    arg.Ref,
+		ExternalAuthProviderID: arg.ExternalAuthProviderID,
+		DeployPrivateKey:       arg.DeployPrivateKey,
+		DeployPrivateKeyKeyID:  arg.DeployPrivateKeyKeyID,
+		DeployPublicKey:        arg.DeployPublicKey,
+		KnownHosts:             arg.KnownHosts,
+		WebhookSecret:          arg.WebhookSecret,
+		AutoPromote:            arg.AutoPromote,
+		SyncStatus:             database.TemplateGitSyncStatusPending,
+		SyncRequestedAt:        sql.NullTime{Time: arg.UpdatedAt, Valid: true},
+	}
+	q.templateGitSources = append(q.templateGitSources, source)
+	return source, nil
+}
+
 func (q *FakeQuerier) UpsertTemplateUsageStats(ctx context.Context) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -10927,6 +11579,26 @@ TemplateUsageStatsInsertLoop:
 	return nil
 }
 
+func (q *FakeQuerier) UpsertWorkspaceAgentDevcontainer(_ context.Context, arg database.UpsertWorkspaceAgentDevcontainerParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	devcontainer := database.WorkspaceAgentDevcontainer(arg)
+	for i, existing := range q.workspaceAgentDevcontainers {
+		if existing.WorkspaceAgentID == arg.WorkspaceAgentID && existing.Name == arg.Name {
+			q.workspaceAgentDevcontainers[i] = devcontainer
+			return nil
+		}
+	}
+	q.workspaceAgentDevcontainers = append(q.workspaceAgentDevcontainers, devcontainer)
+	return nil
+}
+
 func (q *FakeQuerier) UpsertWorkspaceAgentPortShare(_ context.Context, arg database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -10958,6 +11630,33 @@ func (q *FakeQuerier) UpsertWorkspaceAgentPortShare(_ context.Context, arg datab
 	return psl, nil
 }
 
+func (q *FakeQuerier) UpsertWorkspaceDriftCheck(_ context.Context, arg database.UpsertWorkspaceDriftCheckParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for idx, drift := range q.workspaceDrift {
+		if drift.WorkspaceBuildID != arg.WorkspaceBuildID {
+			continue
+		}
+		drift.JobID = arg.JobID
+		drift.CreatedAt = arg.CreatedAt
+		q.workspaceDrift[idx] = drift
+		return nil
+	}
+	q.workspaceDrift = append(q.workspaceDrift, database.WorkspaceDrift{
+		WorkspaceBuildID: arg.WorkspaceBuildID,
+		JobID:            arg.JobID,
+		CreatedAt:        arg.CreatedAt,
+		Resources:        json.RawMessage("[]"),
+	})
+	return nil
+}
+
 func (q *FakeQuerier) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, prepared rbac.PreparedAuthorized) ([]database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
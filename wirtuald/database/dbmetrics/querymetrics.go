@@ -98,6 +98,13 @@ func (m queryMetricsStore) AcquireProvisionerJob(ctx context.Context, arg databa
 	return provisionerJob, err
 }
 
+func (m queryMetricsStore) AcquireTemplateGitSources(ctx context.Context, arg database.AcquireTemplateGitSourcesParams) ([]database.TemplateGitSource, error) {
+	start := time.Now()
+	r0, r1 := m.s.AcquireTemplateGitSources(ctx, arg)
+	m.queryLatencies.WithLabelValues("AcquireTemplateGitSources").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) ActivityBumpWorkspace(ctx context.Context, arg database.ActivityBumpWorkspaceParams) error {
 	start := time.Now()
 	r0 := m.s.ActivityBumpWorkspace(ctx, arg)
@@ -322,6 +329,13 @@ func (m queryMetricsStore) DeleteOldWorkspaceAgentStats(ctx context.Context) err
 	return err
 }
 
+func (m queryMetricsStore) DeleteOldWorkspaceBuildStates(ctx context.Context, threshold time.Time) error {
+	start := time.Now()
+	r0 := m.s.DeleteOldWorkspaceBuildStates(ctx, threshold)
+	m.queryLatencies.WithLabelValues("DeleteOldWorkspaceBuildStates").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
 	start := time.Now()
 	r0 := m.s.DeleteOrganization(ctx, id)
@@ -392,6 +406,13 @@ func (m queryMetricsStore) DeleteTailnetTunnel(ctx context.Context, arg database
 	return r0, r1
 }
 
+func (m queryMetricsStore) DeleteTemplateGitSource(ctx context.Context, templateID uuid.UUID) error {
+	start := time.Now()
+	r0 := m.s.DeleteTemplateGitSource(ctx, templateID)
+	m.queryLatencies.WithLabelValues("DeleteTemplateGitSource").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) DeleteWorkspaceAgentPortShare(ctx context.Context, arg database.DeleteWorkspaceAgentPortShareParams) error {
 	start := time.Now()
 	r0 := m.s.DeleteWorkspaceAgentPortShare(ctx, arg)
@@ -1141,6 +1162,27 @@ func (m queryMetricsStore) GetTemplateDAUs(ctx context.Context, arg database.Get
 	return daus, err
 }
 
+func (m queryMetricsStore) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetTemplateGitSourceByTemplateID(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetTemplateGitSourceByTemplateID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) GetTemplateGitSourcesWithCompletedJobs(ctx context.Context) ([]database.TemplateGitSource, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetTemplateGitSourcesWithCompletedJobs(ctx)
+	m.queryLatencies.WithLabelValues("GetTemplateGitSourcesWithCompletedJobs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) GetTemplateGitSourcesWithDeployKeys(ctx context.Context) ([]database.TemplateGitSource, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetTemplateGitSourcesWithDeployKeys(ctx)
+	m.queryLatencies.WithLabelValues("GetTemplateGitSourcesWithDeployKeys").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetTemplateInsights(ctx context.Context, arg database.GetTemplateInsightsParams) (database.GetTemplateInsightsRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetTemplateInsights(ctx, arg)
@@ -1365,6 +1407,20 @@ func (m queryMetricsStore) GetWorkspaceAgentByInstanceID(ctx context.Context, au
 	return agent, err
 }
 
+func (m queryMetricsStore) GetWorkspaceAgentDNSRecordsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentDNSRecord, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceAgentDNSRecordsByAgentIDs(ctx, ids)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentDNSRecordsByAgentIDs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) GetWorkspaceAgentDevcontainersByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentDevcontainer, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceAgentDevcontainersByAgentID(ctx, workspaceAgentID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentDevcontainersByAgentID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetWorkspaceAgentLifecycleStateByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceAgentLifecycleStateByIDRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetWorkspaceAgentLifecycleStateByID(ctx, id)
@@ -1379,6 +1435,13 @@ func (m queryMetricsStore) GetWorkspaceAgentLogSourcesByAgentIDs(ctx context.Con
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetWorkspaceAgentLogs(ctx context.Context, arg database.GetWorkspaceAgentLogsParams) ([]database.WorkspaceAgentLog, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceAgentLogs(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentLogs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetWorkspaceAgentLogsAfter(ctx context.Context, arg database.GetWorkspaceAgentLogsAfterParams) ([]database.WorkspaceAgentLog, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetWorkspaceAgentLogsAfter(ctx, arg)
@@ -1414,6 +1477,13 @@ func (m queryMetricsStore) GetWorkspaceAgentScriptsByAgentIDs(ctx context.Contex
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetWorkspaceAgentServicesByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentService, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceAgentServicesByAgentIDs(ctx, ids)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentServicesByAgentIDs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetWorkspaceAgentStats(ctx context.Context, createdAt time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
 	start := time.Now()
 	stats, err := m.s.GetWorkspaceAgentStats(ctx, createdAt)
@@ -1540,6 +1610,13 @@ func (m queryMetricsStore) GetWorkspaceBuildsCreatedAfter(ctx context.Context, c
 	return builds, err
 }
 
+func (m queryMetricsStore) GetWorkspaceBuildsForDriftCheck(ctx context.Context, checkedBefore time.Time) ([]database.GetWorkspaceBuildsForDriftCheckRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceBuildsForDriftCheck(ctx, checkedBefore)
+	m.queryLatencies.WithLabelValues("GetWorkspaceBuildsForDriftCheck").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetWorkspaceByAgentID(ctx context.Context, agentID uuid.UUID) (database.Workspace, error) {
 	start := time.Now()
 	workspace, err := m.s.GetWorkspaceByAgentID(ctx, agentID)
@@ -1568,6 +1645,20 @@ func (m queryMetricsStore) GetWorkspaceByWorkspaceAppID(ctx context.Context, wor
 	return workspace, err
 }
 
+func (m queryMetricsStore) GetWorkspaceDriftByBuildID(ctx context.Context, workspaceBuildID uuid.UUID) (database.WorkspaceDrift, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceDriftByBuildID(ctx, workspaceBuildID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceDriftByBuildID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m queryMetricsStore) GetWorkspaceDriftByBuildIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceDrift, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceDriftByBuildIDs(ctx, ids)
+	m.queryLatencies.WithLabelValues("GetWorkspaceDriftByBuildIDs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetWorkspaceModulesByJobID(ctx context.Context, jobID uuid.UUID) ([]database.WorkspaceModule, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetWorkspaceModulesByJobID(ctx, jobID)
@@ -1939,6 +2030,13 @@ func (m queryMetricsStore) InsertWorkspaceAgent(ctx context.Context, arg databas
 	return agent, err
 }
 
+func (m queryMetricsStore) InsertWorkspaceAgentDNSRecords(ctx context.Context, arg database.InsertWorkspaceAgentDNSRecordsParams) ([]database.WorkspaceAgentDNSRecord, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertWorkspaceAgentDNSRecords(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceAgentDNSRecords").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) InsertWorkspaceAgentLogSources(ctx context.Context, arg database.InsertWorkspaceAgentLogSourcesParams) ([]database.WorkspaceAgentLogSource, error) {
 	start := time.Now()
 	r0, r1 := m.s.InsertWorkspaceAgentLogSources(ctx, arg)
@@ -1974,6 +2072,13 @@ func (m queryMetricsStore) InsertWorkspaceAgentScripts(ctx context.Context, arg
 	return r0, r1
 }
 
+func (m queryMetricsStore) InsertWorkspaceAgentService(ctx context.Context, arg database.InsertWorkspaceAgentServiceParams) (database.WorkspaceAgentService, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertWorkspaceAgentService(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceAgentService").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) InsertWorkspaceAgentStats(ctx context.Context, arg database.InsertWorkspaceAgentStatsParams) error {
 	start := time.Now()
 	r0 := m.s.InsertWorkspaceAgentStats(ctx, arg)
@@ -2114,6 +2219,13 @@ func (m queryMetricsStore) RemoveUserFromGroups(ctx context.Context, arg databas
 	return r0, r1
 }
 
+func (m queryMetricsStore) RequestTemplateGitSourceSync(ctx context.Context, arg database.RequestTemplateGitSourceSyncParams) error {
+	start := time.Now()
+	r0 := m.s.RequestTemplateGitSourceSync(ctx, arg)
+	m.queryLatencies.WithLabelValues("RequestTemplateGitSourceSync").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) RevokeDBCryptKey(ctx context.Context, activeKeyDigest string) error {
 	start := time.Now()
 	r0 := m.s.RevokeDBCryptKey(ctx, activeKeyDigest)
@@ -2240,6 +2352,13 @@ func (m queryMetricsStore) UpdateProvisionerJobByID(ctx context.Context, arg dat
 	return err
 }
 
+func (m queryMetricsStore) UpdateProvisionerJobEngineByID(ctx context.Context, arg database.UpdateProvisionerJobEngineByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateProvisionerJobEngineByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateProvisionerJobEngineByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m queryMetricsStore) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg database.UpdateProvisionerJobWithCancelByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateProvisionerJobWithCancelByID(ctx, arg)
@@ -2296,6 +2415,20 @@ func (m queryMetricsStore) UpdateTemplateDeletedByID(ctx context.Context, arg da
 	return err
 }
 
+func (m queryMetricsStore) UpdateTemplateGitSourceDeployKeyByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateTemplateGitSourceDeployKeyByTemplateID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateGitSourceDeployKeyByTemplateID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) UpdateTemplateGitSourceSyncByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateTemplateGitSourceSyncByTemplateID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateGitSourceSyncByTemplateID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) UpdateTemplateMetaByID(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateTemplateMetaByID(ctx, arg)
@@ -2471,6 +2604,20 @@ func (m queryMetricsStore) UpdateWorkspaceAgentMetadata(ctx context.Context, arg
 	return err
 }
 
+func (m queryMetricsStore) UpdateWorkspaceAgentScriptDependsOnByID(ctx context.Context, arg database.UpdateWorkspaceAgentScriptDependsOnByIDParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateWorkspaceAgentScriptDependsOnByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceAgentScriptDependsOnByID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m queryMetricsStore) UpdateWorkspaceAgentServiceStatusByID(ctx context.Context, arg database.UpdateWorkspaceAgentServiceStatusByIDParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateWorkspaceAgentServiceStatusByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceAgentServiceStatusByID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateWorkspaceAgentStartupByID(ctx, arg)
@@ -2534,6 +2681,13 @@ func (m queryMetricsStore) UpdateWorkspaceDormantDeletingAt(ctx context.Context,
 	return ws, r0
 }
 
+func (m queryMetricsStore) UpdateWorkspaceDriftByJobID(ctx context.Context, arg database.UpdateWorkspaceDriftByJobIDParams) (database.WorkspaceDrift, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateWorkspaceDriftByJobID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceDriftByJobID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	start := time.Now()
 	err := m.s.UpdateWorkspaceLastUsedAt(ctx, arg)
@@ -2555,6 +2709,13 @@ func (m queryMetricsStore) UpdateWorkspaceProxyDeleted(ctx context.Context, arg
 	return r0
 }
 
+func (m queryMetricsStore) UpdateWorkspaceProxyDraining(ctx context.Context, arg database.UpdateWorkspaceProxyDrainingParams) (database.WorkspaceProxy, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateWorkspaceProxyDraining(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceProxyDraining").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) UpdateWorkspaceTTL(ctx context.Context, arg database.UpdateWorkspaceTTLParams) error {
 	start := time.Now()
 	r0 := m.s.UpdateWorkspaceTTL(ctx, arg)
@@ -2709,6 +2870,13 @@ func (m queryMetricsStore) UpsertTailnetTunnel(ctx context.Context, arg database
 	return r0, r1
 }
 
+func (m queryMetricsStore) UpsertTemplateGitSource(ctx context.Context, arg database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpsertTemplateGitSource(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertTemplateGitSource").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) UpsertTemplateUsageStats(ctx context.Context) error {
 	start := time.Now()
 	r0 := m.s.UpsertTemplateUsageStats(ctx)
@@ -2716,6 +2884,13 @@ func (m queryMetricsStore) UpsertTemplateUsageStats(ctx context.Context) error {
 	return r0
 }
 
+func (m queryMetricsStore) UpsertWorkspaceAgentDevcontainer(ctx context.Context, arg database.UpsertWorkspaceAgentDevcontainerParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertWorkspaceAgentDevcontainer(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertWorkspaceAgentDevcontainer").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) UpsertWorkspaceAgentPortShare(ctx context.Context, arg database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	start := time.Now()
 	r0, r1 := m.s.UpsertWorkspaceAgentPortShare(ctx, arg)
@@ -2723,6 +2898,13 @@ func (m queryMetricsStore) UpsertWorkspaceAgentPortShare(ctx context.Context, ar
 	return r0, r1
 }
 
+func (m queryMetricsStore) UpsertWorkspaceDriftCheck(ctx context.Context, arg database.UpsertWorkspaceDriftCheckParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertWorkspaceDriftCheck(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertWorkspaceDriftCheck").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, prepared rbac.PreparedAuthorized) ([]database.Template, error) {
 	start := time.Now()
 	templates, err := m.s.GetAuthorizedTemplates(ctx, arg, prepared)
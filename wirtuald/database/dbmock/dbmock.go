@@ -89,6 +89,21 @@ func (mr *MockStoreMockRecorder) AcquireProvisionerJob(ctx, arg any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireProvisionerJob", reflect.TypeOf((*MockStore)(nil).AcquireProvisionerJob), ctx, arg)
 }
 
+// AcquireTemplateGitSources mocks base method.
+func (m *MockStore) AcquireTemplateGitSources(ctx context.Context, arg database.AcquireTemplateGitSourcesParams) ([]database.TemplateGitSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireTemplateGitSources", ctx, arg)
+	ret0, _ := ret[0].([]database.TemplateGitSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireTemplateGitSources indicates an expected call of AcquireTemplateGitSources.
+func (mr *MockStoreMockRecorder) AcquireTemplateGitSources(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTemplateGitSources", reflect.TypeOf((*MockStore)(nil).AcquireTemplateGitSources), ctx, arg)
+}
+
 // ActivityBumpWorkspace mocks base method.
 func (m *MockStore) ActivityBumpWorkspace(ctx context.Context, arg database.ActivityBumpWorkspaceParams) error {
 	m.ctrl.T.Helper()
@@ -544,6 +559,20 @@ func (mr *MockStoreMockRecorder) DeleteOldWorkspaceAgentStats(ctx any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldWorkspaceAgentStats", reflect.TypeOf((*MockStore)(nil).DeleteOldWorkspaceAgentStats), ctx)
 }
 
+// DeleteOldWorkspaceBuildStates mocks base method.
+func (m *MockStore) DeleteOldWorkspaceBuildStates(ctx context.Context, threshold time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldWorkspaceBuildStates", ctx, threshold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOldWorkspaceBuildStates indicates an expected call of DeleteOldWorkspaceBuildStates.
+func (mr *MockStoreMockRecorder) DeleteOldWorkspaceBuildStates(ctx, threshold any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldWorkspaceBuildStates", reflect.TypeOf((*MockStore)(nil).DeleteOldWorkspaceBuildStates), ctx, threshold)
+}
+
 // DeleteOrganization mocks base method.
 func (m *MockStore) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -688,6 +717,20 @@ func (mr *MockStoreMockRecorder) DeleteTailnetTunnel(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTailnetTunnel", reflect.TypeOf((*MockStore)(nil).DeleteTailnetTunnel), ctx, arg)
 }
 
+// DeleteTemplateGitSource mocks base method.
+func (m *MockStore) DeleteTemplateGitSource(ctx context.Context, templateID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplateGitSource", ctx, templateID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplateGitSource indicates an expected call of DeleteTemplateGitSource.
+func (mr *MockStoreMockRecorder) DeleteTemplateGitSource(ctx, templateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplateGitSource", reflect.TypeOf((*MockStore)(nil).DeleteTemplateGitSource), ctx, templateID)
+}
+
 // DeleteWorkspaceAgentPortShare mocks base method.
 func (m *MockStore) DeleteWorkspaceAgentPortShare(ctx context.Context, arg database.DeleteWorkspaceAgentPortShareParams) error {
 	m.ctrl.T.Helper()
@@ -2364,6 +2407,36 @@ func (mr *MockStoreMockRecorder) GetTemplateDAUs(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateDAUs", reflect.TypeOf((*MockStore)(nil).GetTemplateDAUs), ctx, arg)
 }
 
+// GetTemplateGitSourceByTemplateID mocks base method.
+func (m *MockStore) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateGitSourceByTemplateID", ctx, templateID)
+	ret0, _ := ret[0].(database.TemplateGitSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateGitSourceByTemplateID indicates an expected call of GetTemplateGitSourceByTemplateID.
+func (mr *MockStoreMockRecorder) GetTemplateGitSourceByTemplateID(ctx, templateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateGitSourceByTemplateID", reflect.TypeOf((*MockStore)(nil).GetTemplateGitSourceByTemplateID), ctx, templateID)
+}
+
+// GetTemplateGitSourcesWithCompletedJobs mocks base method.
+func (m *MockStore) GetTemplateGitSourcesWithCompletedJobs(ctx context.Context) ([]database.TemplateGitSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateGitSourcesWithCompletedJobs", ctx)
+	ret0, _ := ret[0].([]database.TemplateGitSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateGitSourcesWithCompletedJobs indicates an expected call of GetTemplateGitSourcesWithCompletedJobs.
+func (mr *MockStoreMockRecorder) GetTemplateGitSourcesWithCompletedJobs(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateGitSourcesWithCompletedJobs", reflect.TypeOf((*MockStore)(nil).GetTemplateGitSourcesWithCompletedJobs), ctx)
+}
+
 // GetTemplateGroupRoles mocks base method.
 func (m *MockStore) GetTemplateGroupRoles(ctx context.Context, id uuid.UUID) ([]database.TemplateGroup, error) {
 	m.ctrl.T.Helper()
@@ -2379,6 +2452,21 @@ func (mr *MockStoreMockRecorder) GetTemplateGroupRoles(ctx, id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateGroupRoles", reflect.TypeOf((*MockStore)(nil).GetTemplateGroupRoles), ctx, id)
 }
 
+// GetTemplateGitSourcesWithDeployKeys mocks base method.
+func (m *MockStore) GetTemplateGitSourcesWithDeployKeys(ctx context.Context) ([]database.TemplateGitSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateGitSourcesWithDeployKeys", ctx)
+	ret0, _ := ret[0].([]database.TemplateGitSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateGitSourcesWithDeployKeys indicates an expected call of GetTemplateGitSourcesWithDeployKeys.
+func (mr *MockStoreMockRecorder) GetTemplateGitSourcesWithDeployKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateGitSourcesWithDeployKeys", reflect.TypeOf((*MockStore)(nil).GetTemplateGitSourcesWithDeployKeys), ctx)
+}
+
 // GetTemplateInsights mocks base method.
 func (m *MockStore) GetTemplateInsights(ctx context.Context, arg database.GetTemplateInsightsParams) (database.GetTemplateInsightsRow, error) {
 	m.ctrl.T.Helper()
@@ -2874,6 +2962,36 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentByInstanceID(ctx, authInstance
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentByInstanceID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentByInstanceID), ctx, authInstanceID)
 }
 
+// GetWorkspaceAgentDNSRecordsByAgentIDs mocks base method.
+func (m *MockStore) GetWorkspaceAgentDNSRecordsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentDNSRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentDNSRecordsByAgentIDs", ctx, ids)
+	ret0, _ := ret[0].([]database.WorkspaceAgentDNSRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentDNSRecordsByAgentIDs indicates an expected call of GetWorkspaceAgentDNSRecordsByAgentIDs.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentDNSRecordsByAgentIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentDNSRecordsByAgentIDs", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentDNSRecordsByAgentIDs), ctx, ids)
+}
+
+// GetWorkspaceAgentDevcontainersByAgentID mocks base method.
+func (m *MockStore) GetWorkspaceAgentDevcontainersByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentDevcontainer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentDevcontainersByAgentID", ctx, workspaceAgentID)
+	ret0, _ := ret[0].([]database.WorkspaceAgentDevcontainer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentDevcontainersByAgentID indicates an expected call of GetWorkspaceAgentDevcontainersByAgentID.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentDevcontainersByAgentID(ctx, workspaceAgentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentDevcontainersByAgentID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentDevcontainersByAgentID), ctx, workspaceAgentID)
+}
+
 // GetWorkspaceAgentLifecycleStateByID mocks base method.
 func (m *MockStore) GetWorkspaceAgentLifecycleStateByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceAgentLifecycleStateByIDRow, error) {
 	m.ctrl.T.Helper()
@@ -2904,6 +3022,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentLogSourcesByAgentIDs(ctx, ids
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentLogSourcesByAgentIDs", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentLogSourcesByAgentIDs), ctx, ids)
 }
 
+// GetWorkspaceAgentLogs mocks base method.
+func (m *MockStore) GetWorkspaceAgentLogs(ctx context.Context, arg database.GetWorkspaceAgentLogsParams) ([]database.WorkspaceAgentLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentLogs", ctx, arg)
+	ret0, _ := ret[0].([]database.WorkspaceAgentLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentLogs indicates an expected call of GetWorkspaceAgentLogs.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentLogs(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentLogs", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentLogs), ctx, arg)
+}
+
 // GetWorkspaceAgentLogsAfter mocks base method.
 func (m *MockStore) GetWorkspaceAgentLogsAfter(ctx context.Context, arg database.GetWorkspaceAgentLogsAfterParams) ([]database.WorkspaceAgentLog, error) {
 	m.ctrl.T.Helper()
@@ -2979,6 +3112,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentScriptsByAgentIDs(ctx, ids any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentScriptsByAgentIDs", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentScriptsByAgentIDs), ctx, ids)
 }
 
+// GetWorkspaceAgentServicesByAgentIDs mocks base method.
+func (m *MockStore) GetWorkspaceAgentServicesByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentServicesByAgentIDs", ctx, ids)
+	ret0, _ := ret[0].([]database.WorkspaceAgentService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentServicesByAgentIDs indicates an expected call of GetWorkspaceAgentServicesByAgentIDs.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentServicesByAgentIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentServicesByAgentIDs", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentServicesByAgentIDs), ctx, ids)
+}
+
 // GetWorkspaceAgentStats mocks base method.
 func (m *MockStore) GetWorkspaceAgentStats(ctx context.Context, createdAt time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
 	m.ctrl.T.Helper()
@@ -3249,6 +3397,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceBuildsCreatedAfter(ctx, createdAt a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildsCreatedAfter", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildsCreatedAfter), ctx, createdAt)
 }
 
+// GetWorkspaceBuildsForDriftCheck mocks base method.
+func (m *MockStore) GetWorkspaceBuildsForDriftCheck(ctx context.Context, checkedBefore time.Time) ([]database.GetWorkspaceBuildsForDriftCheckRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceBuildsForDriftCheck", ctx, checkedBefore)
+	ret0, _ := ret[0].([]database.GetWorkspaceBuildsForDriftCheckRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceBuildsForDriftCheck indicates an expected call of GetWorkspaceBuildsForDriftCheck.
+func (mr *MockStoreMockRecorder) GetWorkspaceBuildsForDriftCheck(ctx, checkedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildsForDriftCheck", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildsForDriftCheck), ctx, checkedBefore)
+}
+
 // GetWorkspaceByAgentID mocks base method.
 func (m *MockStore) GetWorkspaceByAgentID(ctx context.Context, agentID uuid.UUID) (database.Workspace, error) {
 	m.ctrl.T.Helper()
@@ -3309,6 +3472,36 @@ func (mr *MockStoreMockRecorder) GetWorkspaceByWorkspaceAppID(ctx, workspaceAppI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceByWorkspaceAppID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceByWorkspaceAppID), ctx, workspaceAppID)
 }
 
+// GetWorkspaceDriftByBuildID mocks base method.
+func (m *MockStore) GetWorkspaceDriftByBuildID(ctx context.Context, workspaceBuildID uuid.UUID) (database.WorkspaceDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceDriftByBuildID", ctx, workspaceBuildID)
+	ret0, _ := ret[0].(database.WorkspaceDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceDriftByBuildID indicates an expected call of GetWorkspaceDriftByBuildID.
+func (mr *MockStoreMockRecorder) GetWorkspaceDriftByBuildID(ctx, workspaceBuildID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceDriftByBuildID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceDriftByBuildID), ctx, workspaceBuildID)
+}
+
+// GetWorkspaceDriftByBuildIDs mocks base method.
+func (m *MockStore) GetWorkspaceDriftByBuildIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceDriftByBuildIDs", ctx, ids)
+	ret0, _ := ret[0].([]database.WorkspaceDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceDriftByBuildIDs indicates an expected call of GetWorkspaceDriftByBuildIDs.
+func (mr *MockStoreMockRecorder) GetWorkspaceDriftByBuildIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceDriftByBuildIDs", reflect.TypeOf((*MockStore)(nil).GetWorkspaceDriftByBuildIDs), ctx, ids)
+}
+
 // GetWorkspaceModulesByJobID mocks base method.
 func (m *MockStore) GetWorkspaceModulesByJobID(ctx context.Context, jobID uuid.UUID) ([]database.WorkspaceModule, error) {
 	m.ctrl.T.Helper()
@@ -4111,6 +4304,21 @@ func (mr *MockStoreMockRecorder) InsertWorkspaceAgent(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgent", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgent), ctx, arg)
 }
 
+// InsertWorkspaceAgentDNSRecords mocks base method.
+func (m *MockStore) InsertWorkspaceAgentDNSRecords(ctx context.Context, arg database.InsertWorkspaceAgentDNSRecordsParams) ([]database.WorkspaceAgentDNSRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceAgentDNSRecords", ctx, arg)
+	ret0, _ := ret[0].([]database.WorkspaceAgentDNSRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWorkspaceAgentDNSRecords indicates an expected call of InsertWorkspaceAgentDNSRecords.
+func (mr *MockStoreMockRecorder) InsertWorkspaceAgentDNSRecords(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentDNSRecords", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentDNSRecords), ctx, arg)
+}
+
 // InsertWorkspaceAgentLogSources mocks base method.
 func (m *MockStore) InsertWorkspaceAgentLogSources(ctx context.Context, arg database.InsertWorkspaceAgentLogSourcesParams) ([]database.WorkspaceAgentLogSource, error) {
 	m.ctrl.T.Helper()
@@ -4185,6 +4393,21 @@ func (mr *MockStoreMockRecorder) InsertWorkspaceAgentScripts(ctx, arg any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentScripts", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentScripts), ctx, arg)
 }
 
+// InsertWorkspaceAgentService mocks base method.
+func (m *MockStore) InsertWorkspaceAgentService(ctx context.Context, arg database.InsertWorkspaceAgentServiceParams) (database.WorkspaceAgentService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceAgentService", ctx, arg)
+	ret0, _ := ret[0].(database.WorkspaceAgentService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWorkspaceAgentService indicates an expected call of InsertWorkspaceAgentService.
+func (mr *MockStoreMockRecorder) InsertWorkspaceAgentService(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentService", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentService), ctx, arg)
+}
+
 // InsertWorkspaceAgentStats mocks base method.
 func (m *MockStore) InsertWorkspaceAgentStats(ctx context.Context, arg database.InsertWorkspaceAgentStatsParams) error {
 	m.ctrl.T.Helper()
@@ -4508,6 +4731,20 @@ func (mr *MockStoreMockRecorder) RemoveUserFromGroups(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserFromGroups", reflect.TypeOf((*MockStore)(nil).RemoveUserFromGroups), ctx, arg)
 }
 
+// RequestTemplateGitSourceSync mocks base method.
+func (m *MockStore) RequestTemplateGitSourceSync(ctx context.Context, arg database.RequestTemplateGitSourceSyncParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestTemplateGitSourceSync", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestTemplateGitSourceSync indicates an expected call of RequestTemplateGitSourceSync.
+func (mr *MockStoreMockRecorder) RequestTemplateGitSourceSync(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestTemplateGitSourceSync", reflect.TypeOf((*MockStore)(nil).RequestTemplateGitSourceSync), ctx, arg)
+}
+
 // RevokeDBCryptKey mocks base method.
 func (m *MockStore) RevokeDBCryptKey(ctx context.Context, activeKeyDigest string) error {
 	m.ctrl.T.Helper()
@@ -4772,6 +5009,20 @@ func (mr *MockStoreMockRecorder) UpdateProvisionerJobByID(ctx, arg any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionerJobByID", reflect.TypeOf((*MockStore)(nil).UpdateProvisionerJobByID), ctx, arg)
 }
 
+// UpdateProvisionerJobEngineByID mocks base method.
+func (m *MockStore) UpdateProvisionerJobEngineByID(ctx context.Context, arg database.UpdateProvisionerJobEngineByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProvisionerJobEngineByID", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProvisionerJobEngineByID indicates an expected call of UpdateProvisionerJobEngineByID.
+func (mr *MockStoreMockRecorder) UpdateProvisionerJobEngineByID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionerJobEngineByID", reflect.TypeOf((*MockStore)(nil).UpdateProvisionerJobEngineByID), ctx, arg)
+}
+
 // UpdateProvisionerJobWithCancelByID mocks base method.
 func (m *MockStore) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg database.UpdateProvisionerJobWithCancelByIDParams) error {
 	m.ctrl.T.Helper()
@@ -4885,6 +5136,34 @@ func (mr *MockStoreMockRecorder) UpdateTemplateDeletedByID(ctx, arg any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateDeletedByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateDeletedByID), ctx, arg)
 }
 
+// UpdateTemplateGitSourceDeployKeyByTemplateID mocks base method.
+func (m *MockStore) UpdateTemplateGitSourceDeployKeyByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSourceDeployKeyByTemplateIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateGitSourceDeployKeyByTemplateID", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateGitSourceDeployKeyByTemplateID indicates an expected call of UpdateTemplateGitSourceDeployKeyByTemplateID.
+func (mr *MockStoreMockRecorder) UpdateTemplateGitSourceDeployKeyByTemplateID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateGitSourceDeployKeyByTemplateID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateGitSourceDeployKeyByTemplateID), ctx, arg)
+}
+
+// UpdateTemplateGitSourceSyncByTemplateID mocks base method.
+func (m *MockStore) UpdateTemplateGitSourceSyncByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateGitSourceSyncByTemplateID", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateGitSourceSyncByTemplateID indicates an expected call of UpdateTemplateGitSourceSyncByTemplateID.
+func (mr *MockStoreMockRecorder) UpdateTemplateGitSourceSyncByTemplateID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateGitSourceSyncByTemplateID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateGitSourceSyncByTemplateID), ctx, arg)
+}
+
 // UpdateTemplateMetaByID mocks base method.
 func (m *MockStore) UpdateTemplateMetaByID(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) error {
 	m.ctrl.T.Helper()
@@ -5246,6 +5525,34 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceAgentMetadata(ctx, arg any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceAgentMetadata", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceAgentMetadata), ctx, arg)
 }
 
+// UpdateWorkspaceAgentScriptDependsOnByID mocks base method.
+func (m *MockStore) UpdateWorkspaceAgentScriptDependsOnByID(ctx context.Context, arg database.UpdateWorkspaceAgentScriptDependsOnByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceAgentScriptDependsOnByID", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceAgentScriptDependsOnByID indicates an expected call of UpdateWorkspaceAgentScriptDependsOnByID.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceAgentScriptDependsOnByID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceAgentScriptDependsOnByID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceAgentScriptDependsOnByID), ctx, arg)
+}
+
+// UpdateWorkspaceAgentServiceStatusByID mocks base method.
+func (m *MockStore) UpdateWorkspaceAgentServiceStatusByID(ctx context.Context, arg database.UpdateWorkspaceAgentServiceStatusByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceAgentServiceStatusByID", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceAgentServiceStatusByID indicates an expected call of UpdateWorkspaceAgentServiceStatusByID.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceAgentServiceStatusByID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceAgentServiceStatusByID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceAgentServiceStatusByID), ctx, arg)
+}
+
 // UpdateWorkspaceAgentStartupByID mocks base method.
 func (m *MockStore) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	m.ctrl.T.Helper()
@@ -5373,6 +5680,21 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceDormantDeletingAt(ctx, arg any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceDormantDeletingAt", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceDormantDeletingAt), ctx, arg)
 }
 
+// UpdateWorkspaceDriftByJobID mocks base method.
+func (m *MockStore) UpdateWorkspaceDriftByJobID(ctx context.Context, arg database.UpdateWorkspaceDriftByJobIDParams) (database.WorkspaceDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceDriftByJobID", ctx, arg)
+	ret0, _ := ret[0].(database.WorkspaceDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWorkspaceDriftByJobID indicates an expected call of UpdateWorkspaceDriftByJobID.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceDriftByJobID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceDriftByJobID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceDriftByJobID), ctx, arg)
+}
+
 // UpdateWorkspaceLastUsedAt mocks base method.
 func (m *MockStore) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	m.ctrl.T.Helper()
@@ -5416,6 +5738,21 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceProxyDeleted(ctx, arg any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceProxyDeleted", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceProxyDeleted), ctx, arg)
 }
 
+// UpdateWorkspaceProxyDraining mocks base method.
+func (m *MockStore) UpdateWorkspaceProxyDraining(ctx context.Context, arg database.UpdateWorkspaceProxyDrainingParams) (database.WorkspaceProxy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceProxyDraining", ctx, arg)
+	ret0, _ := ret[0].(database.WorkspaceProxy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWorkspaceProxyDraining indicates an expected call of UpdateWorkspaceProxyDraining.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceProxyDraining(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceProxyDraining", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceProxyDraining), ctx, arg)
+}
+
 // UpdateWorkspaceTTL mocks base method.
 func (m *MockStore) UpdateWorkspaceTTL(ctx context.Context, arg database.UpdateWorkspaceTTLParams) error {
 	m.ctrl.T.Helper()
@@ -5731,6 +6068,21 @@ func (mr *MockStoreMockRecorder) UpsertTailnetTunnel(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTailnetTunnel", reflect.TypeOf((*MockStore)(nil).UpsertTailnetTunnel), ctx, arg)
 }
 
+// UpsertTemplateGitSource mocks base method.
+func (m *MockStore) UpsertTemplateGitSource(ctx context.Context, arg database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTemplateGitSource", ctx, arg)
+	ret0, _ := ret[0].(database.TemplateGitSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTemplateGitSource indicates an expected call of UpsertTemplateGitSource.
+func (mr *MockStoreMockRecorder) UpsertTemplateGitSource(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTemplateGitSource", reflect.TypeOf((*MockStore)(nil).UpsertTemplateGitSource), ctx, arg)
+}
+
 // UpsertTemplateUsageStats mocks base method.
 func (m *MockStore) UpsertTemplateUsageStats(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -5745,6 +6097,20 @@ func (mr *MockStoreMockRecorder) UpsertTemplateUsageStats(ctx any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTemplateUsageStats", reflect.TypeOf((*MockStore)(nil).UpsertTemplateUsageStats), ctx)
 }
 
+// UpsertWorkspaceAgentDevcontainer mocks base method.
+func (m *MockStore) UpsertWorkspaceAgentDevcontainer(ctx context.Context, arg database.UpsertWorkspaceAgentDevcontainerParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceAgentDevcontainer", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorkspaceAgentDevcontainer indicates an expected call of UpsertWorkspaceAgentDevcontainer.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceAgentDevcontainer(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceAgentDevcontainer", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceAgentDevcontainer), ctx, arg)
+}
+
 // UpsertWorkspaceAgentPortShare mocks base method.
 func (m *MockStore) UpsertWorkspaceAgentPortShare(ctx context.Context, arg database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	m.ctrl.T.Helper()
@@ -5760,6 +6126,20 @@ func (mr *MockStoreMockRecorder) UpsertWorkspaceAgentPortShare(ctx, arg any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceAgentPortShare", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceAgentPortShare), ctx, arg)
 }
 
+// UpsertWorkspaceDriftCheck mocks base method.
+func (m *MockStore) UpsertWorkspaceDriftCheck(ctx context.Context, arg database.UpsertWorkspaceDriftCheckParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceDriftCheck", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorkspaceDriftCheck indicates an expected call of UpsertWorkspaceDriftCheck.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceDriftCheck(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceDriftCheck", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceDriftCheck), ctx, arg)
+}
+
 // Wrappers mocks base method.
 func (m *MockStore) Wrappers() []string {
 	m.ctrl.T.Helper()
@@ -13,6 +13,7 @@ import (
 	"github.com/onchainengineering/hmi
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
 	"github.com/coder/quartz"
 )
 
@@ -25,7 +26,7 @@ const (
 // It is the caller's responsibility to call Close on the returned instance.
 //
 // This is for cleaning up old, unused resources from the database that take up space.
-func New(ctx context.Context, logger slog.Logger, db database.Store, clk quartz.Clock) io.Closer {
+func New(ctx context.Context, logger slog.Logger, db database.Store, vals *wirtualsdk.DeploymentValues, clk quartz.Clock) io.Closer {
 	closed := make(chan struct{})
 
 	ctx, cancelFunc := context.WithCancel(ctx)
@@ -63,6 +64,11 @@ func New(ctx context.Context, logger slog.Logger, db database.Store, clk quartz.
 			if err := tx.DeleteOldNotificationMessages(ctx); err != nil {
 				return xerrors.Errorf("failed to delete old notification messages: %w", err)
 			}
+			if stateRetention := vals.Provisioner.StateRetention.Value(); stateRetention > 0 {
+				if err := tx.DeleteOldWorkspaceBuildStates(ctx, start.Add(-stateRetention)); err != nil {
+					return xerrors.Errorf("failed to delete old workspace build states: %w", err)
+				}
+			}
 
 			logger.Info(ctx, "purged old database entries", slog.F("duration", clk.Since(start)))
 
@@ -20,6 +20,7 @@ import (
 	"cdr.dev/slog/sloggers/slogtest"
 
 	"github.com/coder/quartz"
+	"github.com/coder/serpent"
 	"github.com/onchainengineering/hmi-wirtual/provisionerd/proto"
 	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
 	"github.com/onchainengineering/hmi-wirtual/testutil"
@@ -47,7 +48,7 @@ func TestPurge(t *testing.T) {
 	// We want to make sure dbpurge is actually started so that this test is meaningful.
 	clk := quartz.NewMock(t)
 	done := awaitDoTick(ctx, t, clk)
-	purger := dbpurge.New(context.Background(), testutil.Logger(t), dbmem.New(), clk)
+	purger := dbpurge.New(context.Background(), testutil.Logger(t), dbmem.New(), &wirtualsdk.DeploymentValues{}, clk)
 	<-done // wait for doTick() to run.
 	require.NoError(t, purger.Close())
 }
@@ -125,7 +126,7 @@ func TestDeleteOldWorkspaceAgentStats(t *testing.T) {
 	})
 
 	// when
-	closer := dbpurge.New(ctx, logger, db, clk)
+	closer := dbpurge.New(ctx, logger, db, &wirtualsdk.DeploymentValues{}, clk)
 	defer closer.Close()
 
 	// then
@@ -150,7 +151,7 @@ func TestDeleteOldWorkspaceAgentStats(t *testing.T) {
 
 	// Start a new purger to immediately trigger delete after rollup.
 	_ = closer.Close()
-	closer = dbpurge.New(ctx, logger, db, clk)
+	closer = dbpurge.New(ctx, logger, db, &wirtualsdk.DeploymentValues{}, clk)
 	defer closer.Close()
 
 	// then
@@ -241,7 +242,7 @@ func TestDeleteOldWorkspaceAgentLogs(t *testing.T) {
 	// After dbpurge completes, the ticker is reset. Trap this call.
 
 	done := awaitDoTick(ctx, t, clk)
-	closer := dbpurge.New(ctx, logger, db, clk)
+	closer := dbpurge.New(ctx, logger, db, &wirtualsdk.DeploymentValues{}, clk)
 	defer closer.Close()
 	<-done // doTick() has now run.
 
@@ -388,6 +389,74 @@ func mustCreateAgentLogs(ctx context.Context, t *testing.T, db database.Store, a
 }
 
 //nolint:paralleltest // It uses LockIDDBPurge.
+func TestDeleteOldWorkspaceBuildStates(t *testing.T) {
+	ctx := testutil.Context(t, testutil.WaitShort)
+	clk := quartz.NewMock(t)
+	now := dbtime.Now()
+	threshold := now.Add(-30 * 24 * time.Hour)
+	beforeThreshold := threshold.Add(-24 * time.Hour)
+	afterThreshold := threshold.Add(24 * time.Hour)
+	clk.Set(now).MustWait(ctx)
+
+	db, _ := dbtestutil.NewDB(t, dbtestutil.WithDumpOnFailure())
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	_ = dbgen.OrganizationMember(t, db, database.OrganizationMember{UserID: user.ID, OrganizationID: org.ID})
+	tv := dbgen.TemplateVersion(t, db, database.TemplateVersion{OrganizationID: org.ID, CreatedBy: user.ID})
+	tmpl := dbgen.Template(t, db, database.Template{OrganizationID: org.ID, ActiveVersionID: tv.ID, CreatedBy: user.ID})
+
+	logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+
+	createBuild := func(wsID uuid.UUID, createdAt time.Time, n int32) database.WorkspaceBuild {
+		job := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+			CreatedAt:      createdAt,
+			OrganizationID: org.ID,
+			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+		})
+		return dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			CreatedAt:         createdAt,
+			WorkspaceID:       wsID,
+			JobID:             job.ID,
+			TemplateVersionID: tv.ID,
+			BuildNumber:       n,
+			ProvisionerState:  []byte(fmt.Sprintf("state %d", n)),
+		})
+	}
+
+	// Workspace A was built twice before the threshold, and once after.
+	wsA := dbgen.Workspace(t, db, database.WorkspaceTable{Name: "a", OwnerID: user.ID, OrganizationID: org.ID, TemplateID: tmpl.ID})
+	wbA1 := createBuild(wsA.ID, beforeThreshold, 1)
+	wbA2 := createBuild(wsA.ID, beforeThreshold, 2)
+	wbA3 := createBuild(wsA.ID, afterThreshold, 3)
+
+	// Workspace B was built once before the threshold.
+	wsB := dbgen.Workspace(t, db, database.WorkspaceTable{Name: "b", OwnerID: user.ID, OrganizationID: org.ID, TemplateID: tmpl.ID})
+	wbB1 := createBuild(wsB.ID, beforeThreshold, 1)
+
+	done := awaitDoTick(ctx, t, clk)
+	closer := dbpurge.New(ctx, logger, db, &wirtualsdk.DeploymentValues{
+		Provisioner: wirtualsdk.ProvisionerConfig{
+			StateRetention: serpent.Duration(30 * 24 * time.Hour),
+		},
+	}, clk)
+	defer closer.Close()
+	<-done // doTick() has now run.
+
+	assertState := func(wb database.WorkspaceBuild, want []byte) {
+		t.Helper()
+		got, err := db.GetWorkspaceBuildByID(ctx, wb.ID)
+		require.NoError(t, err)
+		require.Equal(t, want, got.ProvisionerState, "build %d of workspace %s", wb.BuildNumber, wb.WorkspaceID)
+	}
+	// Builds A1 and A2 were replaced and are older than the threshold.
+	assertState(wbA1, nil)
+	assertState(wbA2, nil)
+	// Build A3 is the latest build.
+	assertState(wbA3, []byte("state 3"))
+	// Build B1 is the latest build, even though it is older than the threshold.
+	assertState(wbB1, []byte("state 1"))
+}
+
 func TestDeleteOldProvisionerDaemons(t *testing.T) {
 	// TODO: must refactor DeleteOldProvisionerDaemons to allow passing in cutoff
 	//       before using quartz.NewMock
@@ -462,7 +531,7 @@ func TestDeleteOldProvisionerDaemons(t *testing.T) {
 	require.NoError(t, err)
 
 	// when
-	closer := dbpurge.New(ctx, logger, db, clk)
+	closer := dbpurge.New(ctx, logger, db, &wirtualsdk.DeploymentValues{}, clk)
 	defer closer.Close()
 
 	// then
@@ -25,7 +25,8 @@ CREATE TYPE audit_action AS ENUM (
     'login',
     'logout',
     'register',
-    'request_password_reset'
+    'request_password_reset',
+    'exec'
 );
 
 CREATE TYPE automatic_updates AS ENUM (
@@ -137,6 +138,14 @@ CREATE TYPE port_share_protocol AS ENUM (
     'https'
 );
 
+CREATE TYPE provisioner_job_priority AS ENUM (
+    'interactive',
+    'autostart',
+    'bulk'
+);
+
+COMMENT ON TYPE provisioner_job_priority IS 'Priority of a provisioner job. Pending jobs are acquired in the order of the values of this type.';
+
 CREATE TYPE provisioner_job_status AS ENUM (
     'pending',
     'running',
@@ -159,7 +168,8 @@ CREATE TYPE provisioner_job_timing_stage AS ENUM (
 CREATE TYPE provisioner_job_type AS ENUM (
     'template_version_import',
     'workspace_build',
-    'template_version_dry_run'
+    'template_version_dry_run',
+    'workspace_drift_check'
 );
 
 CREATE TYPE provisioner_storage_method AS ENUM (
@@ -168,7 +178,8 @@ CREATE TYPE provisioner_storage_method AS ENUM (
 
 CREATE TYPE provisioner_type AS ENUM (
     'echo',
-    'terraform'
+    'terraform',
+    'exec'
 );
 
 CREATE TYPE resource_type AS ENUM (
@@ -207,6 +218,14 @@ CREATE TYPE tailnet_status AS ENUM (
     'lost'
 );
 
+CREATE TYPE template_git_sync_status AS ENUM (
+    'pending',
+    'importing',
+    'dry_run',
+    'succeeded',
+    'failed'
+);
+
 CREATE TYPE user_status AS ENUM (
     'active',
     'suspended',
@@ -215,6 +234,13 @@ CREATE TYPE user_status AS ENUM (
 
 COMMENT ON TYPE user_status IS 'Defines the users status: active, dormant, or suspended.';
 
+CREATE TYPE workspace_agent_devcontainer_state AS ENUM (
+    'stopped',
+    'starting',
+    'running',
+    'failed'
+);
+
 CREATE TYPE workspace_agent_lifecycle_state AS ENUM (
     'created',
     'starting',
@@ -239,11 +265,28 @@ CREATE TYPE workspace_agent_script_timing_status AS ENUM (
     'ok',
     'exit_failure',
     'timed_out',
-    'pipes_left_open'
+    'pipes_left_open',
+    'skipped'
 );
 
 COMMENT ON TYPE workspace_agent_script_timing_status IS 'What the exit status of the script is.';
 
+CREATE TYPE workspace_agent_service_restart_policy AS ENUM (
+    'always',
+    'on-failure',
+    'never'
+);
+
+CREATE TYPE workspace_agent_service_state AS ENUM (
+    'pending',
+    'starting',
+    'running',
+    'unhealthy',
+    'backoff',
+    'exited',
+    'failed'
+);
+
 CREATE TYPE workspace_agent_subsystem AS ENUM (
     'envbuilder',
     'envbox',
@@ -1009,11 +1052,18 @@ CASE
         WHEN (started_at IS NULL) THEN 'pending'::provisioner_job_status
         ELSE 'running'::provisioner_job_status
     END
-END) STORED NOT NULL
+END) STORED NOT NULL,
+    engine text DEFAULT ''::text NOT NULL,
+    engine_version text DEFAULT ''::text NOT NULL,
+    priority provisioner_job_priority DEFAULT 'interactive'::provisioner_job_priority NOT NULL
 );
 
 COMMENT ON COLUMN provisioner_jobs.job_status IS 'Computed column to track the status of the job.';
 
+COMMENT ON COLUMN provisioner_jobs.engine IS 'The infrastructure as code engine that ran the job, e.g. terraform or opentofu. Empty if the provisioner did not report one.';
+
+COMMENT ON COLUMN provisioner_jobs.engine_version IS 'The version of the engine that ran the job.';
+
 CREATE TABLE provisioner_keys (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -1086,6 +1136,51 @@ CREATE TABLE tailnet_tunnels (
     updated_at timestamp with time zone NOT NULL
 );
 
+CREATE TABLE template_git_sources (
+    template_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    repo_url text NOT NULL,
+    path text DEFAULT ''::text NOT NULL,
+    ref text DEFAULT ''::text NOT NULL,
+    external_auth_provider_id text DEFAULT ''::text NOT NULL,
+    deploy_private_key text DEFAULT ''::text NOT NULL,
+    deploy_public_key text DEFAULT ''::text NOT NULL,
+    webhook_secret text NOT NULL,
+    auto_promote boolean DEFAULT false NOT NULL,
+    sync_status template_git_sync_status DEFAULT 'pending'::template_git_sync_status NOT NULL,
+    sync_error text DEFAULT ''::text NOT NULL,
+    sync_requested_at timestamp with time zone,
+    last_checked_at timestamp with time zone,
+    last_synced_at timestamp with time zone,
+    last_commit_sha text DEFAULT ''::text NOT NULL,
+    template_version_id uuid,
+    job_id uuid,
+    deploy_private_key_key_id text,
+    known_hosts text DEFAULT ''::text NOT NULL
+);
+
+COMMENT ON TABLE template_git_sources IS 'Git repositories that new versions of templates are created from.';
+
+COMMENT ON COLUMN template_git_sources.user_id IS 'The user whose external auth link is used to fetch the repository. Synced template versions are created by this user.';
+
+COMMENT ON COLUMN template_git_sources.path IS 'The directory of the template within the repository.';
+
+COMMENT ON COLUMN template_git_sources.ref IS 'The branch or tag to sync. Empty means the default branch of the repository.';
+
+COMMENT ON COLUMN template_git_sources.deploy_private_key IS 'Private SSH key used to fetch the repository when it is configured as a deploy key.';
+
+COMMENT ON COLUMN template_git_sources.sync_requested_at IS 'Set by webhooks and manual syncs, so the repository is checked before the poll interval elapses.';
+
+COMMENT ON COLUMN template_git_sources.template_version_id IS 'The template version created from last_commit_sha.';
+
+COMMENT ON COLUMN template_git_sources.job_id IS 'The import or dry run job that the sync is waiting for.';
+
+COMMENT ON COLUMN template_git_sources.deploy_private_key_key_id IS 'The ID of the key used to encrypt the deploy private key. If this is NULL, the deploy private key is not encrypted';
+
+COMMENT ON COLUMN template_git_sources.known_hosts IS 'The SSH host keys of the git host in known_hosts format. SSH connections to hosts with other keys are refused.';
+
 CREATE TABLE template_usage_stats (
     start_time timestamp with time zone NOT NULL,
     end_time timestamp with time zone NOT NULL,
@@ -1218,11 +1313,15 @@ CREATE TABLE template_versions (
     external_auth_providers jsonb DEFAULT '[]'::jsonb NOT NULL,
     message character varying(1048576) DEFAULT ''::character varying NOT NULL,
     archived boolean DEFAULT false NOT NULL,
-    source_example_id text
+    source_example_id text,
+    git_commit_sha text DEFAULT ''::text NOT NULL,
+    git_commit_author text DEFAULT ''::text NOT NULL
 );
 
 COMMENT ON COLUMN template_versions.external_auth_providers IS 'IDs of External auth providers for a specific template version';
 
+COMMENT ON COLUMN template_versions.git_commit_sha IS 'The commit that the template version was synced from, if it was created from a template git source.';
+
 COMMENT ON COLUMN template_versions.message IS 'Message describing the changes in this version of the template, similar to a Git commit message. Like a commit message, this should be a short, high-level description of the changes in this version of the template. This message is immutable and should not be updated after the fact.';
 
 CREATE VIEW visible_users AS
@@ -1247,6 +1346,8 @@ CREATE VIEW template_version_with_user AS
     template_versions.message,
     template_versions.archived,
     template_versions.source_example_id,
+    template_versions.git_commit_sha,
+    template_versions.git_commit_author,
     COALESCE(visible_users.avatar_url, ''::text) AS created_by_avatar_url,
     COALESCE(visible_users.username, ''::text) AS created_by_username
    FROM (template_versions
@@ -1288,7 +1389,8 @@ CREATE TABLE templates (
     require_active_version boolean DEFAULT false NOT NULL,
     deprecated text DEFAULT ''::text NOT NULL,
     activity_bump bigint DEFAULT '3600000000000'::bigint NOT NULL,
-    max_port_sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL
+    max_port_sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL,
+    job_timeout bigint DEFAULT 0 NOT NULL
 );
 
 COMMENT ON COLUMN templates.default_ttl IS 'The default duration for autostop for workspaces created from this template.';
@@ -1309,6 +1411,8 @@ COMMENT ON COLUMN templates.autostart_block_days_of_week IS 'A bitmap of days of
 
 COMMENT ON COLUMN templates.deprecated IS 'If set to a non empty string, the template will no longer be able to be used. The message will be displayed to the user.';
 
+COMMENT ON COLUMN templates.job_timeout IS 'The maximum duration of a workspace build job in nanoseconds. Jobs that run longer are canceled. 0 means no timeout.';
+
 CREATE VIEW template_with_names AS
  SELECT templates.id,
     templates.created_at,
@@ -1338,6 +1442,7 @@ CREATE VIEW template_with_names AS
     templates.deprecated,
     templates.activity_bump,
     templates.max_port_sharing_level,
+    templates.job_timeout,
     COALESCE(visible_users.avatar_url, ''::text) AS created_by_avatar_url,
     COALESCE(visible_users.username, ''::text) AS created_by_username,
     COALESCE(organizations.name, ''::text) AS organization_name,
@@ -1367,6 +1472,36 @@ COMMENT ON COLUMN user_links.oauth_refresh_token_key_id IS 'The ID of the key us
 
 COMMENT ON COLUMN user_links.claims IS 'Claims from the IDP for the linked user. Includes both id_token and userinfo claims. ';
 
+CREATE TABLE workspace_agent_devcontainers (
+    workspace_agent_id uuid NOT NULL,
+    name text NOT NULL,
+    workspace_folder text NOT NULL,
+    config_path text NOT NULL,
+    state workspace_agent_devcontainer_state NOT NULL,
+    container_id text DEFAULT ''::text NOT NULL,
+    image text DEFAULT ''::text NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    state_changed_at timestamp with time zone,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE workspace_agent_devcontainers IS 'Dev containers found by the workspace agent, with the state it last reported for each.';
+
+COMMENT ON COLUMN workspace_agent_devcontainers.updated_at IS 'When the agent last reported the dev container.';
+
+CREATE TABLE workspace_agent_dns_records (
+    workspace_agent_id uuid NOT NULL,
+    name text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    port integer DEFAULT 0 NOT NULL
+);
+
+COMMENT ON TABLE workspace_agent_dns_records IS 'DNS names served for a workspace agent by the VPN resolver, relative to the workspace name.';
+
+COMMENT ON COLUMN workspace_agent_dns_records.name IS 'The name relative to the workspace, e.g. "api" for api.<workspace>.coder. A leading "*." label makes it a wildcard.';
+
+COMMENT ON COLUMN workspace_agent_dns_records.port IS 'The agent port that port 80 of the record''s own address is forwarded to. Zero means the name resolves to the agent''s address.';
+
 CREATE TABLE workspace_agent_log_sources (
     workspace_agent_id uuid NOT NULL,
     id uuid NOT NULL,
@@ -1428,9 +1563,49 @@ CREATE TABLE workspace_agent_scripts (
     run_on_stop boolean NOT NULL,
     timeout_seconds integer NOT NULL,
     display_name text NOT NULL,
-    id uuid DEFAULT gen_random_uuid() NOT NULL
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    depends_on uuid[] DEFAULT '{}'::uuid[] NOT NULL,
+    ready_port integer DEFAULT 0 NOT NULL,
+    ready_file text DEFAULT ''::text NOT NULL,
+    ready_timeout_seconds integer DEFAULT 0 NOT NULL
+);
+
+COMMENT ON COLUMN workspace_agent_scripts.depends_on IS 'The IDs of the scripts of the same agent that must succeed, or become ready, before this script is started.';
+
+COMMENT ON COLUMN workspace_agent_scripts.ready_port IS 'A local TCP port that must accept connections before the dependents of this script are started. Zero means no port is awaited.';
+
+COMMENT ON COLUMN workspace_agent_scripts.ready_file IS 'A file that must exist before the dependents of this script are started. Empty means no file is awaited.';
+
+CREATE TABLE workspace_agent_services (
+    id uuid NOT NULL,
+    workspace_agent_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    name text NOT NULL,
+    display_name text NOT NULL,
+    command text NOT NULL,
+    directory text NOT NULL,
+    env jsonb NOT NULL,
+    restart_policy workspace_agent_service_restart_policy NOT NULL,
+    restart_backoff_seconds integer NOT NULL,
+    max_restart_backoff_seconds integer NOT NULL,
+    healthcheck_url text DEFAULT ''::text NOT NULL,
+    healthcheck_interval integer DEFAULT 0 NOT NULL,
+    healthcheck_threshold integer DEFAULT 0 NOT NULL,
+    state workspace_agent_service_state DEFAULT 'pending'::workspace_agent_service_state NOT NULL,
+    restart_count integer DEFAULT 0 NOT NULL,
+    exit_code integer DEFAULT 0 NOT NULL,
+    status_message text DEFAULT ''::text NOT NULL,
+    state_changed_at timestamp with time zone
 );
 
+COMMENT ON TABLE workspace_agent_services IS 'Long-running processes declared by coder_service resources and supervised by the workspace agent.';
+
+COMMENT ON COLUMN workspace_agent_services.env IS 'Extra environment variables for the service process, as a JSON object of strings.';
+
+COMMENT ON COLUMN workspace_agent_services.state IS 'The last state reported by the agent.';
+
+COMMENT ON COLUMN workspace_agent_services.restart_count IS 'The number of times the agent has restarted the service since the agent started.';
+
 CREATE SEQUENCE workspace_agent_startup_logs_id_seq
     START WITH 1
     INCREMENT BY 1
@@ -1580,13 +1755,29 @@ CREATE TABLE workspace_apps (
     slug text NOT NULL,
     external boolean DEFAULT false NOT NULL,
     display_order integer DEFAULT 0 NOT NULL,
-    hidden boolean DEFAULT false NOT NULL
+    hidden boolean DEFAULT false NOT NULL,
+    healthcheck_tcp text DEFAULT ''::text NOT NULL,
+    healthcheck_command text DEFAULT ''::text NOT NULL,
+    healthcheck_expected_statuses integer[] DEFAULT '{}'::integer[] NOT NULL,
+    healthcheck_body_regex text DEFAULT ''::text NOT NULL,
+    healthcheck_headers jsonb DEFAULT '{}'::jsonb NOT NULL,
+    health_reason text DEFAULT ''::text NOT NULL
 );
 
 COMMENT ON COLUMN workspace_apps.display_order IS 'Specifies the order in which to display agent app in user interfaces.';
 
 COMMENT ON COLUMN workspace_apps.hidden IS 'Determines if the app is not shown in user interfaces.';
 
+COMMENT ON COLUMN workspace_apps.healthcheck_tcp IS 'A host:port that must accept connections for the app to be healthy, instead of requesting healthcheck_url.';
+
+COMMENT ON COLUMN workspace_apps.healthcheck_command IS 'A command run by the agent that must exit with code zero for the app to be healthy, instead of requesting healthcheck_url.';
+
+COMMENT ON COLUMN workspace_apps.healthcheck_expected_statuses IS 'The HTTP status codes that count as healthy. Any status below 500 is healthy when empty.';
+
+COMMENT ON COLUMN workspace_apps.healthcheck_headers IS 'Extra headers for the health check request, as a JSON object of strings.';
+
+COMMENT ON COLUMN workspace_apps.health_reason IS 'Why the last health check failed, as reported by the agent. Empty when healthy.';
+
 CREATE TABLE workspace_build_parameters (
     workspace_build_id uuid NOT NULL,
     name text NOT NULL,
@@ -1636,6 +1827,20 @@ CREATE VIEW workspace_build_with_user AS
 
 COMMENT ON VIEW workspace_build_with_user IS 'Joins in the username + avatar url of the initiated by user.';
 
+CREATE TABLE workspace_drift (
+    workspace_build_id uuid NOT NULL,
+    job_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    checked_at timestamp with time zone,
+    resources jsonb DEFAULT '[]'::jsonb NOT NULL
+);
+
+COMMENT ON TABLE workspace_drift IS 'The latest drift check of a workspace build. A drift check runs a refresh-only plan to find the resources that were changed outside of builds.';
+
+COMMENT ON COLUMN workspace_drift.checked_at IS 'When the latest completed check finished. NULL until a check completes.';
+
+COMMENT ON COLUMN workspace_drift.resources IS 'The drifted resources found by the latest completed check.';
+
 CREATE TABLE workspace_modules (
     id uuid NOT NULL,
     job_id uuid NOT NULL,
@@ -1660,7 +1865,8 @@ CREATE TABLE workspace_proxies (
     region_id integer NOT NULL,
     derp_enabled boolean DEFAULT true NOT NULL,
     derp_only boolean DEFAULT false NOT NULL,
-    version text DEFAULT ''::text NOT NULL
+    version text DEFAULT ''::text NOT NULL,
+    draining boolean DEFAULT false NOT NULL
 );
 
 COMMENT ON COLUMN workspace_proxies.icon IS 'Expects an emoji character. (/emojis/1f1fa-1f1f8.png)';
@@ -1675,6 +1881,8 @@ COMMENT ON COLUMN workspace_proxies.token_hashed_secret IS 'Hashed secret is use
 
 COMMENT ON COLUMN workspace_proxies.derp_only IS 'Disables app/terminal proxying for this proxy and only acts as a DERP relay.';
 
+COMMENT ON COLUMN workspace_proxies.draining IS 'Draining proxies are removed from the list of regions and avoided as DERP home regions, but keep serving existing connections.';
+
 CREATE SEQUENCE workspace_proxies_region_id_seq
     AS integer
     START WITH 1
@@ -1928,6 +2136,9 @@ ALTER TABLE ONLY tailnet_peers
 ALTER TABLE ONLY tailnet_tunnels
     ADD CONSTRAINT tailnet_tunnels_pkey PRIMARY KEY (coordinator_id, src_id, dst_id);
 
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_pkey PRIMARY KEY (template_id);
+
 ALTER TABLE ONLY template_usage_stats
     ADD CONSTRAINT template_usage_stats_pkey PRIMARY KEY (start_time, template_id, user_id);
 
@@ -1955,6 +2166,12 @@ ALTER TABLE ONLY user_links
 ALTER TABLE ONLY users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_agent_devcontainers
+    ADD CONSTRAINT workspace_agent_devcontainers_pkey PRIMARY KEY (workspace_agent_id, name);
+
+ALTER TABLE ONLY workspace_agent_dns_records
+    ADD CONSTRAINT workspace_agent_dns_records_pkey PRIMARY KEY (workspace_agent_id, name);
+
 ALTER TABLE ONLY workspace_agent_log_sources
     ADD CONSTRAINT workspace_agent_log_sources_pkey PRIMARY KEY (workspace_agent_id, id);
 
@@ -1970,6 +2187,12 @@ ALTER TABLE ONLY workspace_agent_script_timings
 ALTER TABLE ONLY workspace_agent_scripts
     ADD CONSTRAINT workspace_agent_scripts_id_key UNIQUE (id);
 
+ALTER TABLE ONLY workspace_agent_services
+    ADD CONSTRAINT workspace_agent_services_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY workspace_agent_services
+    ADD CONSTRAINT workspace_agent_services_workspace_agent_id_name_key UNIQUE (workspace_agent_id, name);
+
 ALTER TABLE ONLY workspace_agent_logs
     ADD CONSTRAINT workspace_agent_startup_logs_pkey PRIMARY KEY (id);
 
@@ -2000,6 +2223,9 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_build_number_key UNIQUE (workspace_id, build_number);
 
+ALTER TABLE ONLY workspace_drift
+    ADD CONSTRAINT workspace_drift_pkey PRIMARY KEY (workspace_build_id);
+
 ALTER TABLE ONLY workspace_proxies
     ADD CONSTRAINT workspace_proxies_pkey PRIMARY KEY (id);
 
@@ -2072,6 +2298,12 @@ CREATE UNIQUE INDEX organizations_single_default_org ON organizations USING btre
 
 CREATE INDEX provisioner_job_logs_id_job_id_idx ON provisioner_job_logs USING btree (job_id, id);
 
+CREATE INDEX provisioner_jobs_initiator_id_running_idx ON provisioner_jobs USING btree (initiator_id) WHERE ((started_at IS NOT NULL) AND (completed_at IS NULL));
+
+CREATE INDEX provisioner_jobs_organization_id_completed_at_idx ON provisioner_jobs USING btree (organization_id, completed_at) WHERE (completed_at IS NOT NULL);
+
+CREATE INDEX provisioner_jobs_organization_id_running_idx ON provisioner_jobs USING btree (organization_id) WHERE ((started_at IS NOT NULL) AND (completed_at IS NULL));
+
 CREATE INDEX provisioner_jobs_started_at_idx ON provisioner_jobs USING btree (started_at) WHERE (started_at IS NULL);
 
 CREATE UNIQUE INDEX provisioner_keys_organization_id_name_idx ON provisioner_keys USING btree (organization_id, lower((name)::text));
@@ -2108,6 +2340,8 @@ CREATE INDEX workspace_agents_resource_id_idx ON workspace_agents USING btree (r
 
 CREATE INDEX workspace_app_stats_workspace_id_idx ON workspace_app_stats USING btree (workspace_id);
 
+CREATE INDEX workspace_drift_job_id_idx ON workspace_drift USING btree (job_id);
+
 CREATE INDEX workspace_modules_created_at_idx ON workspace_modules USING btree (created_at);
 
 CREATE UNIQUE INDEX workspace_proxies_lower_name_idx ON workspace_proxies USING btree (lower(name)) WHERE (deleted = false);
@@ -2297,6 +2531,21 @@ ALTER TABLE ONLY tailnet_peers
 ALTER TABLE ONLY tailnet_tunnels
     ADD CONSTRAINT tailnet_tunnels_coordinator_id_fkey FOREIGN KEY (coordinator_id) REFERENCES tailnet_coordinators(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_deploy_private_key_key_id_fkey FOREIGN KEY (deploy_private_key_key_id) REFERENCES dbcrypt_keys(active_key_digest);
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
 
@@ -2330,6 +2579,12 @@ ALTER TABLE ONLY user_links
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_agent_devcontainers
+    ADD CONSTRAINT workspace_agent_devcontainers_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_agent_dns_records
+    ADD CONSTRAINT workspace_agent_dns_records_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_agent_log_sources
     ADD CONSTRAINT workspace_agent_log_sources_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
@@ -2345,6 +2600,9 @@ ALTER TABLE ONLY workspace_agent_script_timings
 ALTER TABLE ONLY workspace_agent_scripts
     ADD CONSTRAINT workspace_agent_scripts_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_agent_services
+    ADD CONSTRAINT workspace_agent_services_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_agent_logs
     ADD CONSTRAINT workspace_agent_startup_logs_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
@@ -2375,6 +2633,12 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_drift
+    ADD CONSTRAINT workspace_drift_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_drift
+    ADD CONSTRAINT workspace_drift_workspace_build_id_fkey FOREIGN KEY (workspace_build_id) REFERENCES workspace_builds(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_modules
     ADD CONSTRAINT workspace_modules_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
@@ -12,6 +12,8 @@ const (
 	LockIDDBPurge
 	LockIDNotificationsReportGenerator
 	LockIDCryptoKeyRotation
+	LockIDWorkspaceDriftCheck
+	LockIDTemplateGitSync
 )
 
 // GenLockID generates a unique and consistent lock ID from a given string.
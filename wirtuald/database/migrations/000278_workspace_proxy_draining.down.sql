@@ -0,0 +1,2 @@
+ALTER TABLE workspace_proxies
+	DROP COLUMN draining;
@@ -0,0 +1,4 @@
+ALTER TABLE workspace_proxies
+	ADD COLUMN draining boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN workspace_proxies.draining IS 'Draining proxies are removed from the DERP map and the list of regions, but keep serving existing connections.';
@@ -0,0 +1,11 @@
+CREATE TABLE workspace_agent_dns_records (
+	workspace_agent_id uuid NOT NULL REFERENCES workspace_agents(id) ON DELETE CASCADE,
+	name text NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	port integer DEFAULT 0 NOT NULL,
+	PRIMARY KEY (workspace_agent_id, name)
+);
+
+COMMENT ON TABLE workspace_agent_dns_records IS 'DNS names served for a workspace agent by the VPN resolver, relative to the workspace name.';
+COMMENT ON COLUMN workspace_agent_dns_records.name IS 'The name relative to the workspace, e.g. "api" for api.<workspace>.coder. A leading "*." label makes it a wildcard.';
+COMMENT ON COLUMN workspace_agent_dns_records.port IS 'The agent port that port 80 of the record''s own address is forwarded to. Zero means the name resolves to the agent''s address.';
@@ -0,0 +1,3 @@
+ALTER TABLE provisioner_jobs
+	DROP COLUMN engine,
+	DROP COLUMN engine_version;
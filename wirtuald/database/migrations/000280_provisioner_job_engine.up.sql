@@ -0,0 +1,6 @@
+ALTER TABLE provisioner_jobs
+	ADD COLUMN engine text NOT NULL DEFAULT '',
+	ADD COLUMN engine_version text NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN provisioner_jobs.engine IS 'The infrastructure as code engine that ran the job, e.g. terraform or opentofu. Empty if the provisioner did not report one.';
+COMMENT ON COLUMN provisioner_jobs.engine_version IS 'The version of the engine that ran the job.';
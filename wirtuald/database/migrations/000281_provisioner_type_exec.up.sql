@@ -0,0 +1,2 @@
+ALTER TYPE provisioner_type
+  ADD VALUE IF NOT EXISTS 'exec';
@@ -0,0 +1,3 @@
+DELETE FROM notification_templates WHERE id = '8c3d1a5e-6f0b-4e2a-9d47-b2e91f6c0a83';
+
+DROP TABLE IF EXISTS workspace_drift;
@@ -0,0 +1,36 @@
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+ALTER TYPE provisioner_job_type
+  ADD VALUE IF NOT EXISTS 'workspace_drift_check';
+
+CREATE TABLE workspace_drift (
+	workspace_build_id uuid NOT NULL PRIMARY KEY REFERENCES workspace_builds(id) ON DELETE CASCADE,
+	job_id uuid NOT NULL REFERENCES provisioner_jobs(id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	checked_at timestamp with time zone,
+	resources jsonb DEFAULT '[]'::jsonb NOT NULL
+);
+
+CREATE INDEX workspace_drift_job_id_idx ON workspace_drift USING btree (job_id);
+
+COMMENT ON TABLE workspace_drift IS 'The latest drift check of a workspace build. A drift check runs a refresh-only plan to find the resources that were changed outside of builds.';
+COMMENT ON COLUMN workspace_drift.checked_at IS 'When the latest completed check finished. NULL until a check completes.';
+COMMENT ON COLUMN workspace_drift.resources IS 'The drifted resources found by the latest completed check.';
+
+INSERT INTO notification_templates
+	(id, name, title_template, body_template, "group", actions)
+VALUES (
+	'8c3d1a5e-6f0b-4e2a-9d47-b2e91f6c0a83',
+	'Workspace Drift Detected',
+	E'Workspace "{{.Labels.name}}" has drifted',
+	E'Hi {{.UserName}},\n\n'||
+		E'Resources of your workspace **{{.Labels.name}}** were changed outside of its builds: {{.Labels.resources}}.\n\n' ||
+		E'The next build of the workspace may revert these changes.',
+	'Workspace Events',
+	'[
+		{
+			"label": "View workspace",
+			"url": "{{base_url}}/@{{.UserUsername}}/{{.Labels.name}}"
+		}
+	]'::jsonb
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS provisioner_jobs_organization_id_completed_at_idx;
+DROP INDEX IF EXISTS provisioner_jobs_initiator_id_running_idx;
+
+ALTER TABLE provisioner_jobs
+	DROP COLUMN priority;
+
+DROP TYPE provisioner_job_priority;
@@ -0,0 +1,18 @@
+-- The values are ordered from the highest to the lowest priority, so that
+-- jobs can be ordered by priority directly.
+CREATE TYPE provisioner_job_priority AS ENUM (
+	'interactive',
+	'autostart',
+	'bulk'
+);
+
+COMMENT ON TYPE provisioner_job_priority IS 'Priority of a provisioner job. Pending jobs are acquired in the order of the values of this type.';
+
+ALTER TABLE provisioner_jobs
+	ADD COLUMN priority provisioner_job_priority NOT NULL DEFAULT 'interactive';
+
+-- Fair-share scheduling counts the running jobs of each initiator.
+CREATE INDEX provisioner_jobs_initiator_id_running_idx ON provisioner_jobs (initiator_id) WHERE started_at IS NOT NULL AND completed_at IS NULL;
+
+-- Queue wait estimates use the jobs completed recently.
+CREATE INDEX provisioner_jobs_organization_id_completed_at_idx ON provisioner_jobs (organization_id, completed_at) WHERE completed_at IS NOT NULL;
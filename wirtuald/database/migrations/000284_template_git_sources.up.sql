@@ -0,0 +1,74 @@
+CREATE TYPE template_git_sync_status AS ENUM (
+	'pending',
+	'importing',
+	'dry_run',
+	'succeeded',
+	'failed'
+);
+
+CREATE TABLE template_git_sources (
+	template_id uuid NOT NULL PRIMARY KEY REFERENCES templates(id) ON DELETE CASCADE,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	repo_url text NOT NULL,
+	path text DEFAULT ''::text NOT NULL,
+	ref text DEFAULT ''::text NOT NULL,
+	external_auth_provider_id text DEFAULT ''::text NOT NULL,
+	deploy_private_key text DEFAULT ''::text NOT NULL,
+	deploy_public_key text DEFAULT ''::text NOT NULL,
+	webhook_secret text NOT NULL,
+	auto_promote boolean DEFAULT false NOT NULL,
+	sync_status template_git_sync_status DEFAULT 'pending'::template_git_sync_status NOT NULL,
+	sync_error text DEFAULT ''::text NOT NULL,
+	sync_requested_at timestamp with time zone,
+	last_checked_at timestamp with time zone,
+	last_synced_at timestamp with time zone,
+	last_commit_sha text DEFAULT ''::text NOT NULL,
+	template_version_id uuid REFERENCES template_versions(id) ON DELETE SET NULL,
+	job_id uuid REFERENCES provisioner_jobs(id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE template_git_sources IS 'Git repositories that new versions of templates are created from.';
+COMMENT ON COLUMN template_git_sources.user_id IS 'The user whose external auth link is used to fetch the repository. Synced template versions are created by this user.';
+COMMENT ON COLUMN template_git_sources.path IS 'The directory of the template within the repository.';
+COMMENT ON COLUMN template_git_sources.ref IS 'The branch or tag to sync. Empty means the default branch of the repository.';
+COMMENT ON COLUMN template_git_sources.deploy_private_key IS 'Private SSH key used to fetch the repository when it is configured as a deploy key.';
+COMMENT ON COLUMN template_git_sources.sync_requested_at IS 'Set by webhooks and manual syncs, so the repository is checked before the poll interval elapses.';
+COMMENT ON COLUMN template_git_sources.template_version_id IS 'The template version created from last_commit_sha.';
+COMMENT ON COLUMN template_git_sources.job_id IS 'The import or dry run job that the sync is waiting for.';
+
+-- We cannot alter the columns of the table while a view depends on it, so we
+-- drop it and recreate it.
+DROP VIEW template_version_with_user;
+
+ALTER TABLE template_versions
+	ADD COLUMN git_commit_sha text DEFAULT ''::text NOT NULL,
+	ADD COLUMN git_commit_author text DEFAULT ''::text NOT NULL;
+
+COMMENT ON COLUMN template_versions.git_commit_sha IS 'The commit that the template version was synced from, if it was created from a template git source.';
+
+-- Recreate `template_version_with_user` as described in dump.sql
+CREATE VIEW template_version_with_user AS
+SELECT
+  template_versions.id,
+  template_versions.template_id,
+  template_versions.organization_id,
+  template_versions.created_at,
+  template_versions.updated_at,
+  template_versions.name,
+  template_versions.readme,
+  template_versions.job_id,
+  template_versions.created_by,
+  template_versions.external_auth_providers,
+  template_versions.message,
+  template_versions.archived,
+  template_versions.source_example_id,
+  template_versions.git_commit_sha,
+  template_versions.git_commit_author,
+  COALESCE(visible_users.avatar_url, ''::text) AS created_by_avatar_url,
+  COALESCE(visible_users.username, ''::text) AS created_by_username
+FROM (template_versions
+  LEFT JOIN visible_users ON (template_versions.created_by = visible_users.id));
+
+COMMENT ON VIEW template_version_with_user IS 'Joins in the username + avatar url of the created by user.';
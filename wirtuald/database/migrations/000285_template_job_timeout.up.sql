@@ -0,0 +1,31 @@
+-- We cannot add a column to the table while a view selects all of its
+-- columns, so we drop the view and recreate it.
+DROP VIEW template_with_names;
+
+ALTER TABLE templates ADD COLUMN job_timeout bigint DEFAULT 0 NOT NULL;
+
+COMMENT ON COLUMN templates.job_timeout IS 'The maximum duration of a workspace build job in nanoseconds. Jobs that run longer are canceled. 0 means no timeout.';
+
+-- Recreate `template_with_names` as described in dump.sql
+CREATE VIEW
+	template_with_names
+AS
+SELECT
+	templates.*,
+	coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+	coalesce(visible_users.username, '') AS created_by_username,
+	coalesce(organizations.name, '') AS organization_name,
+	coalesce(organizations.display_name, '') AS organization_display_name,
+	coalesce(organizations.icon, '') AS organization_icon
+FROM
+	templates
+		LEFT JOIN
+	visible_users
+	ON
+		templates.created_by = visible_users.id
+		LEFT JOIN
+	organizations
+	ON templates.organization_id = organizations.id
+;
+
+COMMENT ON VIEW template_with_names IS 'Joins in the display name information such as username, avatar, and organization name.';
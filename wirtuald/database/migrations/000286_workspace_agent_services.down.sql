@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS workspace_agent_services;
+DROP TYPE IF EXISTS workspace_agent_service_state;
+DROP TYPE IF EXISTS workspace_agent_service_restart_policy;
@@ -0,0 +1,43 @@
+CREATE TYPE workspace_agent_service_restart_policy AS ENUM (
+	'always',
+	'on-failure',
+	'never'
+);
+
+CREATE TYPE workspace_agent_service_state AS ENUM (
+	'pending',
+	'starting',
+	'running',
+	'unhealthy',
+	'backoff',
+	'exited',
+	'failed'
+);
+
+CREATE TABLE workspace_agent_services (
+	id uuid NOT NULL PRIMARY KEY,
+	workspace_agent_id uuid NOT NULL REFERENCES workspace_agents(id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	name text NOT NULL,
+	display_name text NOT NULL,
+	command text NOT NULL,
+	directory text NOT NULL,
+	env jsonb NOT NULL,
+	restart_policy workspace_agent_service_restart_policy NOT NULL,
+	restart_backoff_seconds integer NOT NULL,
+	max_restart_backoff_seconds integer NOT NULL,
+	healthcheck_url text DEFAULT ''::text NOT NULL,
+	healthcheck_interval integer DEFAULT 0 NOT NULL,
+	healthcheck_threshold integer DEFAULT 0 NOT NULL,
+	state workspace_agent_service_state DEFAULT 'pending'::workspace_agent_service_state NOT NULL,
+	restart_count integer DEFAULT 0 NOT NULL,
+	exit_code integer DEFAULT 0 NOT NULL,
+	status_message text DEFAULT ''::text NOT NULL,
+	state_changed_at timestamp with time zone,
+	UNIQUE (workspace_agent_id, name)
+);
+
+COMMENT ON TABLE workspace_agent_services IS 'Long-running processes declared by coder_service resources and supervised by the workspace agent.';
+COMMENT ON COLUMN workspace_agent_services.env IS 'Extra environment variables for the service process, as a JSON object of strings.';
+COMMENT ON COLUMN workspace_agent_services.state IS 'The last state reported by the agent.';
+COMMENT ON COLUMN workspace_agent_services.restart_count IS 'The number of times the agent has restarted the service since the agent started.';
@@ -0,0 +1,5 @@
+ALTER TABLE workspace_agent_scripts
+	DROP COLUMN IF EXISTS depends_on,
+	DROP COLUMN IF EXISTS ready_port,
+	DROP COLUMN IF EXISTS ready_file,
+	DROP COLUMN IF EXISTS ready_timeout_seconds;
@@ -0,0 +1,14 @@
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+ALTER TYPE workspace_agent_script_timing_status
+  ADD VALUE IF NOT EXISTS 'skipped';
+
+ALTER TABLE workspace_agent_scripts
+	ADD COLUMN depends_on uuid[] DEFAULT '{}'::uuid[] NOT NULL,
+	ADD COLUMN ready_port integer DEFAULT 0 NOT NULL,
+	ADD COLUMN ready_file text DEFAULT ''::text NOT NULL,
+	ADD COLUMN ready_timeout_seconds integer DEFAULT 0 NOT NULL;
+
+COMMENT ON COLUMN workspace_agent_scripts.depends_on IS 'The IDs of the scripts of the same agent that must succeed, or become ready, before this script is started.';
+COMMENT ON COLUMN workspace_agent_scripts.ready_port IS 'A local TCP port that must accept connections before the dependents of this script are started. Zero means no port is awaited.';
+COMMENT ON COLUMN workspace_agent_scripts.ready_file IS 'A file that must exist before the dependents of this script are started. Empty means no file is awaited.';
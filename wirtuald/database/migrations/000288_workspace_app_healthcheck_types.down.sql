@@ -0,0 +1,7 @@
+ALTER TABLE workspace_apps
+	DROP COLUMN health_reason,
+	DROP COLUMN healthcheck_headers,
+	DROP COLUMN healthcheck_body_regex,
+	DROP COLUMN healthcheck_expected_statuses,
+	DROP COLUMN healthcheck_command,
+	DROP COLUMN healthcheck_tcp;
@@ -0,0 +1,13 @@
+ALTER TABLE workspace_apps
+	ADD COLUMN healthcheck_tcp text DEFAULT ''::text NOT NULL,
+	ADD COLUMN healthcheck_command text DEFAULT ''::text NOT NULL,
+	ADD COLUMN healthcheck_expected_statuses integer[] DEFAULT '{}'::integer[] NOT NULL,
+	ADD COLUMN healthcheck_body_regex text DEFAULT ''::text NOT NULL,
+	ADD COLUMN healthcheck_headers jsonb DEFAULT '{}'::jsonb NOT NULL,
+	ADD COLUMN health_reason text DEFAULT ''::text NOT NULL;
+
+COMMENT ON COLUMN workspace_apps.healthcheck_tcp IS 'A host:port that must accept connections for the app to be healthy, instead of requesting healthcheck_url.';
+COMMENT ON COLUMN workspace_apps.healthcheck_command IS 'A command run by the agent that must exit with code zero for the app to be healthy, instead of requesting healthcheck_url.';
+COMMENT ON COLUMN workspace_apps.healthcheck_expected_statuses IS 'The HTTP status codes that count as healthy. Any status below 500 is healthy when empty.';
+COMMENT ON COLUMN workspace_apps.healthcheck_headers IS 'Extra headers for the health check request, as a JSON object of strings.';
+COMMENT ON COLUMN workspace_apps.health_reason IS 'Why the last health check failed, as reported by the agent. Empty when healthy.';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS workspace_agent_devcontainers;
+DROP TYPE IF EXISTS workspace_agent_devcontainer_state;
@@ -0,0 +1,23 @@
+CREATE TYPE workspace_agent_devcontainer_state AS ENUM (
+	'stopped',
+	'starting',
+	'running',
+	'failed'
+);
+
+CREATE TABLE workspace_agent_devcontainers (
+	workspace_agent_id uuid NOT NULL REFERENCES workspace_agents(id) ON DELETE CASCADE,
+	name text NOT NULL,
+	workspace_folder text NOT NULL,
+	config_path text NOT NULL,
+	state workspace_agent_devcontainer_state NOT NULL,
+	container_id text DEFAULT ''::text NOT NULL,
+	image text DEFAULT ''::text NOT NULL,
+	error text DEFAULT ''::text NOT NULL,
+	state_changed_at timestamp with time zone,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (workspace_agent_id, name)
+);
+
+COMMENT ON TABLE workspace_agent_devcontainers IS 'Dev containers found by the workspace agent, with the state it last reported for each.';
+COMMENT ON COLUMN workspace_agent_devcontainers.updated_at IS 'When the agent last reported the dev container.';
@@ -0,0 +1,3 @@
+ALTER TABLE template_git_sources
+	DROP COLUMN IF EXISTS known_hosts,
+	DROP COLUMN IF EXISTS deploy_private_key_key_id;
@@ -0,0 +1,6 @@
+ALTER TABLE template_git_sources
+	ADD COLUMN deploy_private_key_key_id text REFERENCES dbcrypt_keys(active_key_digest),
+	ADD COLUMN known_hosts text DEFAULT ''::text NOT NULL;
+
+COMMENT ON COLUMN template_git_sources.deploy_private_key_key_id IS 'The ID of the key used to encrypt the deploy private key. If this is NULL, the deploy private key is not encrypted';
+COMMENT ON COLUMN template_git_sources.known_hosts IS 'The SSH host keys of the git host in known_hosts format. SSH connections to hosts with other keys are refused.';
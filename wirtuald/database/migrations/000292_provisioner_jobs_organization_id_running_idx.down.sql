@@ -0,0 +1 @@
+DROP INDEX IF EXISTS provisioner_jobs_organization_id_running_idx;
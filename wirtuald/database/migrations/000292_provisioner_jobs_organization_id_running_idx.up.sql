@@ -0,0 +1,3 @@
+-- Fair-share scheduling between organizations counts the running jobs of each
+-- organization.
+CREATE INDEX provisioner_jobs_organization_id_running_idx ON provisioner_jobs (organization_id) WHERE started_at IS NOT NULL AND completed_at IS NULL;
@@ -0,0 +1 @@
+COMMENT ON COLUMN workspace_proxies.draining IS 'Draining proxies are removed from the DERP map and the list of regions, but keep serving existing connections.';
@@ -0,0 +1 @@
+COMMENT ON COLUMN workspace_proxies.draining IS 'Draining proxies are removed from the list of regions and avoided as DERP home regions, but keep serving existing connections.';
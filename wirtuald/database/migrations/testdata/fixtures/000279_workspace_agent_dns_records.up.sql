@@ -0,0 +1,4 @@
+INSERT INTO workspace_agent_dns_records (workspace_agent_id, name, created_at)
+VALUES
+    ((SELECT id FROM workspace_agents LIMIT 1), 'api', NOW()),
+    ((SELECT id FROM workspace_agents LIMIT 1), '*.apps', NOW());
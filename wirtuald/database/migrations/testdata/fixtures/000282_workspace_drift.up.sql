@@ -0,0 +1,8 @@
+INSERT INTO workspace_drift (workspace_build_id, job_id, created_at, checked_at, resources)
+VALUES (
+	(SELECT id FROM workspace_builds LIMIT 1),
+	(SELECT job_id FROM workspace_builds LIMIT 1),
+	NOW(),
+	NOW(),
+	'[{"address": "null_resource.dev", "type": "null_resource", "action": "update", "attributes": [{"name": "triggers", "before": "{\"a\":\"1\"}", "after": "{\"a\":\"2\"}"}]}]'::jsonb
+);
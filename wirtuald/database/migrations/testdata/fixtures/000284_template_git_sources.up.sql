@@ -0,0 +1,13 @@
+INSERT INTO template_git_sources (template_id, user_id, created_at, updated_at, repo_url, path, ref, webhook_secret, sync_status, last_commit_sha)
+VALUES (
+	(SELECT id FROM templates LIMIT 1),
+	(SELECT id FROM users LIMIT 1),
+	NOW(),
+	NOW(),
+	'https://github.com/coder/templates.git',
+	'docker',
+	'main',
+	'secret',
+	'succeeded',
+	'2f5b0c7f6d0bb4e0e0a7a7a4bde7e3c4f9d1a2b3'
+);
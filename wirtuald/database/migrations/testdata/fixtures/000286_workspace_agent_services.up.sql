@@ -0,0 +1,3 @@
+INSERT INTO workspace_agent_services (id, workspace_agent_id, created_at, name, display_name, command, directory, env, restart_policy, restart_backoff_seconds, max_restart_backoff_seconds, healthcheck_url, healthcheck_interval, healthcheck_threshold)
+VALUES
+    ('6c1e9b1e-60f2-4b0e-9b1a-2d5c8f3a7e41', (SELECT id FROM workspace_agents LIMIT 1), NOW(), 'web', 'Web server', 'npm run dev', '/home/coder/app', '{"PORT": "3000"}', 'always', 1, 60, 'http://localhost:3000/healthz', 5, 3);
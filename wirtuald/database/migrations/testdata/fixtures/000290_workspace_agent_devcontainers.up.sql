@@ -0,0 +1,3 @@
+INSERT INTO workspace_agent_devcontainers (workspace_agent_id, name, workspace_folder, config_path, state, container_id, image, state_changed_at, updated_at)
+VALUES
+    ((SELECT id FROM workspace_agents LIMIT 1), 'project', '/home/coder/project', '/home/coder/project/.devcontainer/devcontainer.json', 'running', '3f1c2a9b8e7d', 'mcr.microsoft.com/devcontainers/go:1', NOW(), NOW());
@@ -147,6 +147,7 @@ const (
 	AuditActionLogout               AuditAction = "logout"
 	AuditActionRegister             AuditAction = "register"
 	AuditActionRequestPasswordReset AuditAction = "request_password_reset"
+	AuditActionExec                 AuditAction = "exec"
 )
 
 func (e *AuditAction) Scan(src interface{}) error {
@@ -194,7 +195,8 @@ func (e AuditAction) Valid() bool {
 		AuditActionLogin,
 		AuditActionLogout,
 		AuditActionRegister,
-		AuditActionRequestPasswordReset:
+		AuditActionRequestPasswordReset,
+		AuditActionExec:
 		return true
 	}
 	return false
@@ -211,6 +213,7 @@ func AllAuditActionValues() []AuditAction {
 		AuditActionLogout,
 		AuditActionRegister,
 		AuditActionRequestPasswordReset,
+		AuditActionExec,
 	}
 }
 
@@ -1209,6 +1212,68 @@ func AllPortShareProtocolValues() []PortShareProtocol {
 	}
 }
 
+// Priority of a provisioner job. Pending jobs are acquired in the order of the values of this type.
+type ProvisionerJobPriority string
+
+const (
+	ProvisionerJobPriorityInteractive ProvisionerJobPriority = "interactive"
+	ProvisionerJobPriorityAutostart   ProvisionerJobPriority = "autostart"
+	ProvisionerJobPriorityBulk        ProvisionerJobPriority = "bulk"
+)
+
+func (e *ProvisionerJobPriority) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProvisionerJobPriority(s)
+	case string:
+		*e = ProvisionerJobPriority(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProvisionerJobPriority: %T", src)
+	}
+	return nil
+}
+
+type NullProvisionerJobPriority struct {
+	ProvisionerJobPriority ProvisionerJobPriority `json:"provisioner_job_priority"`
+	Valid                  bool                   `json:"valid"` // Valid is true if ProvisionerJobPriority is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProvisionerJobPriority) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProvisionerJobPriority, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProvisionerJobPriority.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProvisionerJobPriority) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProvisionerJobPriority), nil
+}
+
+func (e ProvisionerJobPriority) Valid() bool {
+	switch e {
+	case ProvisionerJobPriorityInteractive,
+		ProvisionerJobPriorityAutostart,
+		ProvisionerJobPriorityBulk:
+		return true
+	}
+	return false
+}
+
+func AllProvisionerJobPriorityValues() []ProvisionerJobPriority {
+	return []ProvisionerJobPriority{
+		ProvisionerJobPriorityInteractive,
+		ProvisionerJobPriorityAutostart,
+		ProvisionerJobPriorityBulk,
+	}
+}
+
 // Computed status of a provisioner job. Jobs could be stuck in a hung state, these states do not guarantee any transition to another state.
 type ProvisionerJobStatus string
 
@@ -1353,6 +1418,7 @@ const (
 	ProvisionerJobTypeTemplateVersionImport ProvisionerJobType = "template_version_import"
 	ProvisionerJobTypeWorkspaceBuild        ProvisionerJobType = "workspace_build"
 	ProvisionerJobTypeTemplateVersionDryRun ProvisionerJobType = "template_version_dry_run"
+	ProvisionerJobTypeWorkspaceDriftCheck   ProvisionerJobType = "workspace_drift_check"
 )
 
 func (e *ProvisionerJobType) Scan(src interface{}) error {
@@ -1394,7 +1460,8 @@ func (e ProvisionerJobType) Valid() bool {
 	switch e {
 	case ProvisionerJobTypeTemplateVersionImport,
 		ProvisionerJobTypeWorkspaceBuild,
-		ProvisionerJobTypeTemplateVersionDryRun:
+		ProvisionerJobTypeTemplateVersionDryRun,
+		ProvisionerJobTypeWorkspaceDriftCheck:
 		return true
 	}
 	return false
@@ -1405,6 +1472,7 @@ func AllProvisionerJobTypeValues() []ProvisionerJobType {
 		ProvisionerJobTypeTemplateVersionImport,
 		ProvisionerJobTypeWorkspaceBuild,
 		ProvisionerJobTypeTemplateVersionDryRun,
+		ProvisionerJobTypeWorkspaceDriftCheck,
 	}
 }
 
@@ -1468,6 +1536,7 @@ type ProvisionerType string
 const (
 	ProvisionerTypeEcho      ProvisionerType = "echo"
 	ProvisionerTypeTerraform ProvisionerType = "terraform"
+	ProvisionerTypeExec      ProvisionerType = "exec"
 )
 
 func (e *ProvisionerType) Scan(src interface{}) error {
@@ -1508,7 +1577,8 @@ func (ns NullProvisionerType) Value() (driver.Value, error) {
 func (e ProvisionerType) Valid() bool {
 	switch e {
 	case ProvisionerTypeEcho,
-		ProvisionerTypeTerraform:
+		ProvisionerTypeTerraform,
+		ProvisionerTypeExec:
 		return true
 	}
 	return false
@@ -1518,6 +1588,7 @@ func AllProvisionerTypeValues() []ProvisionerType {
 	return []ProvisionerType{
 		ProvisionerTypeEcho,
 		ProvisionerTypeTerraform,
+		ProvisionerTypeExec,
 	}
 }
 
@@ -1746,6 +1817,73 @@ func AllTailnetStatusValues() []TailnetStatus {
 	}
 }
 
+type TemplateGitSyncStatus string
+
+const (
+	TemplateGitSyncStatusPending   TemplateGitSyncStatus = "pending"
+	TemplateGitSyncStatusImporting TemplateGitSyncStatus = "importing"
+	TemplateGitSyncStatusDryRun    TemplateGitSyncStatus = "dry_run"
+	TemplateGitSyncStatusSucceeded TemplateGitSyncStatus = "succeeded"
+	TemplateGitSyncStatusFailed    TemplateGitSyncStatus = "failed"
+)
+
+func (e *TemplateGitSyncStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TemplateGitSyncStatus(s)
+	case string:
+		*e = TemplateGitSyncStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TemplateGitSyncStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTemplateGitSyncStatus struct {
+	TemplateGitSyncStatus TemplateGitSyncStatus `json:"template_git_sync_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if TemplateGitSyncStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTemplateGitSyncStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TemplateGitSyncStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TemplateGitSyncStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTemplateGitSyncStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TemplateGitSyncStatus), nil
+}
+
+func (e TemplateGitSyncStatus) Valid() bool {
+	switch e {
+	case TemplateGitSyncStatusPending,
+		TemplateGitSyncStatusImporting,
+		TemplateGitSyncStatusDryRun,
+		TemplateGitSyncStatusSucceeded,
+		TemplateGitSyncStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllTemplateGitSyncStatusValues() []TemplateGitSyncStatus {
+	return []TemplateGitSyncStatus{
+		TemplateGitSyncStatusPending,
+		TemplateGitSyncStatusImporting,
+		TemplateGitSyncStatusDryRun,
+		TemplateGitSyncStatusSucceeded,
+		TemplateGitSyncStatusFailed,
+	}
+}
+
 // Defines the users status: active, dormant, or suspended.
 type UserStatus string
 
@@ -1808,6 +1946,70 @@ func AllUserStatusValues() []UserStatus {
 	}
 }
 
+type WorkspaceAgentDevcontainerState string
+
+const (
+	WorkspaceAgentDevcontainerStateStopped  WorkspaceAgentDevcontainerState = "stopped"
+	WorkspaceAgentDevcontainerStateStarting WorkspaceAgentDevcontainerState = "starting"
+	WorkspaceAgentDevcontainerStateRunning  WorkspaceAgentDevcontainerState = "running"
+	WorkspaceAgentDevcontainerStateFailed   WorkspaceAgentDevcontainerState = "failed"
+)
+
+func (e *WorkspaceAgentDevcontainerState) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceAgentDevcontainerState(s)
+	case string:
+		*e = WorkspaceAgentDevcontainerState(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceAgentDevcontainerState: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceAgentDevcontainerState struct {
+	WorkspaceAgentDevcontainerState WorkspaceAgentDevcontainerState `json:"workspace_agent_devcontainer_state"`
+	Valid                           bool                            `json:"valid"` // Valid is true if WorkspaceAgentDevcontainerState is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceAgentDevcontainerState) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceAgentDevcontainerState, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceAgentDevcontainerState.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceAgentDevcontainerState) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceAgentDevcontainerState), nil
+}
+
+func (e WorkspaceAgentDevcontainerState) Valid() bool {
+	switch e {
+	case WorkspaceAgentDevcontainerStateStopped,
+		WorkspaceAgentDevcontainerStateStarting,
+		WorkspaceAgentDevcontainerStateRunning,
+		WorkspaceAgentDevcontainerStateFailed:
+		return true
+	}
+	return false
+}
+
+func AllWorkspaceAgentDevcontainerStateValues() []WorkspaceAgentDevcontainerState {
+	return []WorkspaceAgentDevcontainerState{
+		WorkspaceAgentDevcontainerStateStopped,
+		WorkspaceAgentDevcontainerStateStarting,
+		WorkspaceAgentDevcontainerStateRunning,
+		WorkspaceAgentDevcontainerStateFailed,
+	}
+}
+
 type WorkspaceAgentLifecycleState string
 
 const (
@@ -1957,6 +2159,7 @@ const (
 	WorkspaceAgentScriptTimingStatusExitFailure   WorkspaceAgentScriptTimingStatus = "exit_failure"
 	WorkspaceAgentScriptTimingStatusTimedOut      WorkspaceAgentScriptTimingStatus = "timed_out"
 	WorkspaceAgentScriptTimingStatusPipesLeftOpen WorkspaceAgentScriptTimingStatus = "pipes_left_open"
+	WorkspaceAgentScriptTimingStatusSkipped       WorkspaceAgentScriptTimingStatus = "skipped"
 )
 
 func (e *WorkspaceAgentScriptTimingStatus) Scan(src interface{}) error {
@@ -1999,7 +2202,8 @@ func (e WorkspaceAgentScriptTimingStatus) Valid() bool {
 	case WorkspaceAgentScriptTimingStatusOk,
 		WorkspaceAgentScriptTimingStatusExitFailure,
 		WorkspaceAgentScriptTimingStatusTimedOut,
-		WorkspaceAgentScriptTimingStatusPipesLeftOpen:
+		WorkspaceAgentScriptTimingStatusPipesLeftOpen,
+		WorkspaceAgentScriptTimingStatusSkipped:
 		return true
 	}
 	return false
@@ -2011,6 +2215,141 @@ func AllWorkspaceAgentScriptTimingStatusValues() []WorkspaceAgentScriptTimingSta
 		WorkspaceAgentScriptTimingStatusExitFailure,
 		WorkspaceAgentScriptTimingStatusTimedOut,
 		WorkspaceAgentScriptTimingStatusPipesLeftOpen,
+		WorkspaceAgentScriptTimingStatusSkipped,
+	}
+}
+
+type WorkspaceAgentServiceRestartPolicy string
+
+const (
+	WorkspaceAgentServiceRestartPolicyAlways    WorkspaceAgentServiceRestartPolicy = "always"
+	WorkspaceAgentServiceRestartPolicyOnFailure WorkspaceAgentServiceRestartPolicy = "on-failure"
+	WorkspaceAgentServiceRestartPolicyNever     WorkspaceAgentServiceRestartPolicy = "never"
+)
+
+func (e *WorkspaceAgentServiceRestartPolicy) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceAgentServiceRestartPolicy(s)
+	case string:
+		*e = WorkspaceAgentServiceRestartPolicy(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceAgentServiceRestartPolicy: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceAgentServiceRestartPolicy struct {
+	WorkspaceAgentServiceRestartPolicy WorkspaceAgentServiceRestartPolicy `json:"workspace_agent_service_restart_policy"`
+	Valid                              bool                               `json:"valid"` // Valid is true if WorkspaceAgentServiceRestartPolicy is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceAgentServiceRestartPolicy) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceAgentServiceRestartPolicy, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceAgentServiceRestartPolicy.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceAgentServiceRestartPolicy) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceAgentServiceRestartPolicy), nil
+}
+
+func (e WorkspaceAgentServiceRestartPolicy) Valid() bool {
+	switch e {
+	case WorkspaceAgentServiceRestartPolicyAlways,
+		WorkspaceAgentServiceRestartPolicyOnFailure,
+		WorkspaceAgentServiceRestartPolicyNever:
+		return true
+	}
+	return false
+}
+
+func AllWorkspaceAgentServiceRestartPolicyValues() []WorkspaceAgentServiceRestartPolicy {
+	return []WorkspaceAgentServiceRestartPolicy{
+		WorkspaceAgentServiceRestartPolicyAlways,
+		WorkspaceAgentServiceRestartPolicyOnFailure,
+		WorkspaceAgentServiceRestartPolicyNever,
+	}
+}
+
+type WorkspaceAgentServiceState string
+
+const (
+	WorkspaceAgentServiceStatePending   WorkspaceAgentServiceState = "pending"
+	WorkspaceAgentServiceStateStarting  WorkspaceAgentServiceState = "starting"
+	WorkspaceAgentServiceStateRunning   WorkspaceAgentServiceState = "running"
+	WorkspaceAgentServiceStateUnhealthy WorkspaceAgentServiceState = "unhealthy"
+	WorkspaceAgentServiceStateBackoff   WorkspaceAgentServiceState = "backoff"
+	WorkspaceAgentServiceStateExited    WorkspaceAgentServiceState = "exited"
+	WorkspaceAgentServiceStateFailed    WorkspaceAgentServiceState = "failed"
+)
+
+func (e *WorkspaceAgentServiceState) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceAgentServiceState(s)
+	case string:
+		*e = WorkspaceAgentServiceState(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceAgentServiceState: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceAgentServiceState struct {
+	WorkspaceAgentServiceState WorkspaceAgentServiceState `json:"workspace_agent_service_state"`
+	Valid                      bool                       `json:"valid"` // Valid is true if WorkspaceAgentServiceState is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceAgentServiceState) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceAgentServiceState, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceAgentServiceState.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceAgentServiceState) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceAgentServiceState), nil
+}
+
+func (e WorkspaceAgentServiceState) Valid() bool {
+	switch e {
+	case WorkspaceAgentServiceStatePending,
+		WorkspaceAgentServiceStateStarting,
+		WorkspaceAgentServiceStateRunning,
+		WorkspaceAgentServiceStateUnhealthy,
+		WorkspaceAgentServiceStateBackoff,
+		WorkspaceAgentServiceStateExited,
+		WorkspaceAgentServiceStateFailed:
+		return true
+	}
+	return false
+}
+
+func AllWorkspaceAgentServiceStateValues() []WorkspaceAgentServiceState {
+	return []WorkspaceAgentServiceState{
+		WorkspaceAgentServiceStatePending,
+		WorkspaceAgentServiceStateStarting,
+		WorkspaceAgentServiceStateRunning,
+		WorkspaceAgentServiceStateUnhealthy,
+		WorkspaceAgentServiceStateBackoff,
+		WorkspaceAgentServiceStateExited,
+		WorkspaceAgentServiceStateFailed,
 	}
 }
 
@@ -2544,6 +2883,11 @@ type ProvisionerJob struct {
 	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
 	// Computed column to track the status of the job.
 	JobStatus ProvisionerJobStatus `db:"job_status" json:"job_status"`
+	// The infrastructure as code engine that ran the job, e.g. terraform or opentofu. Empty if the provisioner did not report one.
+	Engine string `db:"engine" json:"engine"`
+	// The version of the engine that ran the job.
+	EngineVersion string                 `db:"engine_version" json:"engine_version"`
+	Priority      ProvisionerJobPriority `db:"priority" json:"priority"`
 }
 
 type ProvisionerJobLog struct {
@@ -2684,6 +3028,7 @@ type Template struct {
 	Deprecated                    string          `db:"deprecated" json:"deprecated"`
 	ActivityBump                  int64           `db:"activity_bump" json:"activity_bump"`
 	MaxPortSharingLevel           AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	JobTimeout                    int64           `db:"job_timeout" json:"job_timeout"`
 	CreatedByAvatarURL            string          `db:"created_by_avatar_url" json:"created_by_avatar_url"`
 	CreatedByUsername             string          `db:"created_by_username" json:"created_by_username"`
 	OrganizationName              string          `db:"organization_name" json:"organization_name"`
@@ -2691,6 +3036,41 @@ type Template struct {
 	OrganizationIcon              string          `db:"organization_icon" json:"organization_icon"`
 }
 
+// Git repositories that new versions of templates are created from.
+type TemplateGitSource struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	// The user whose external auth link is used to fetch the repository. Synced template versions are created by this user.
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	RepoURL   string    `db:"repo_url" json:"repo_url"`
+	// The directory of the template within the repository.
+	Path string `db:"path" json:"path"`
+	// The branch or tag to sync. Empty means the default branch of the repository.
+	Ref                    string `db:"ref" json:"ref"`
+	ExternalAuthProviderID string `db:"external_auth_provider_id" json:"external_auth_provider_id"`
+	// Private SSH key used to fetch the repository when it is configured as a deploy key.
+	DeployPrivateKey string                `db:"deploy_private_key" json:"deploy_private_key"`
+	DeployPublicKey  string                `db:"deploy_public_key" json:"deploy_public_key"`
+	WebhookSecret    string                `db:"webhook_secret" json:"webhook_secret"`
+	AutoPromote      bool                  `db:"auto_promote" json:"auto_promote"`
+	SyncStatus       TemplateGitSyncStatus `db:"sync_status" json:"sync_status"`
+	SyncError        string                `db:"sync_error" json:"sync_error"`
+	// Set by webhooks and manual syncs, so the repository is checked before the poll interval elapses.
+	SyncRequestedAt sql.NullTime `db:"sync_requested_at" json:"sync_requested_at"`
+	LastCheckedAt   sql.NullTime `db:"last_checked_at" json:"last_checked_at"`
+	LastSyncedAt    sql.NullTime `db:"last_synced_at" json:"last_synced_at"`
+	LastCommitSHA   string       `db:"last_commit_sha" json:"last_commit_sha"`
+	// The template version created from last_commit_sha.
+	TemplateVersionID uuid.NullUUID `db:"template_version_id" json:"template_version_id"`
+	// The import or dry run job that the sync is waiting for.
+	JobID uuid.NullUUID `db:"job_id" json:"job_id"`
+	// The ID of the key used to encrypt the deploy private key. If this is NULL, the deploy private key is not encrypted
+	DeployPrivateKeyKeyID sql.NullString `db:"deploy_private_key_key_id" json:"deploy_private_key_key_id"`
+	// The SSH host keys of the git host in known_hosts format. SSH connections to hosts with other keys are refused.
+	KnownHosts string `db:"known_hosts" json:"known_hosts"`
+}
+
 type TemplateTable struct {
 	ID              uuid.UUID       `db:"id" json:"id"`
 	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
@@ -2729,6 +3109,8 @@ type TemplateTable struct {
 	Deprecated          string          `db:"deprecated" json:"deprecated"`
 	ActivityBump        int64           `db:"activity_bump" json:"activity_bump"`
 	MaxPortSharingLevel AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	// The maximum duration of a workspace build job in nanoseconds. Jobs that run longer are canceled. 0 means no timeout.
+	JobTimeout int64 `db:"job_timeout" json:"job_timeout"`
 }
 
 // Records aggregated usage statistics for templates/users. All usage is rounded up to the nearest minute.
@@ -2774,6 +3156,8 @@ type TemplateVersion struct {
 	Message               string          `db:"message" json:"message"`
 	Archived              bool            `db:"archived" json:"archived"`
 	SourceExampleID       sql.NullString  `db:"source_example_id" json:"source_example_id"`
+	GitCommitSHA          string          `db:"git_commit_sha" json:"git_commit_sha"`
+	GitCommitAuthor       string          `db:"git_commit_author" json:"git_commit_author"`
 	CreatedByAvatarURL    string          `db:"created_by_avatar_url" json:"created_by_avatar_url"`
 	CreatedByUsername     string          `db:"created_by_username" json:"created_by_username"`
 }
@@ -2830,6 +3214,9 @@ type TemplateVersionTable struct {
 	Message         string         `db:"message" json:"message"`
 	Archived        bool           `db:"archived" json:"archived"`
 	SourceExampleID sql.NullString `db:"source_example_id" json:"source_example_id"`
+	// The commit that the template version was synced from, if it was created from a template git source.
+	GitCommitSHA    string `db:"git_commit_sha" json:"git_commit_sha"`
+	GitCommitAuthor string `db:"git_commit_author" json:"git_commit_author"`
 }
 
 type TemplateVersionVariable struct {
@@ -2979,6 +3366,31 @@ type WorkspaceAgent struct {
 	DisplayOrder int32 `db:"display_order" json:"display_order"`
 }
 
+// Dev containers found by the workspace agent, with the state it last reported for each.
+type WorkspaceAgentDevcontainer struct {
+	WorkspaceAgentID uuid.UUID                       `db:"workspace_agent_id" json:"workspace_agent_id"`
+	Name             string                          `db:"name" json:"name"`
+	WorkspaceFolder  string                          `db:"workspace_folder" json:"workspace_folder"`
+	ConfigPath       string                          `db:"config_path" json:"config_path"`
+	State            WorkspaceAgentDevcontainerState `db:"state" json:"state"`
+	ContainerID      string                          `db:"container_id" json:"container_id"`
+	Image            string                          `db:"image" json:"image"`
+	Error            string                          `db:"error" json:"error"`
+	StateChangedAt   sql.NullTime                    `db:"state_changed_at" json:"state_changed_at"`
+	// When the agent last reported the dev container.
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// DNS names served for a workspace agent by the VPN resolver, relative to the workspace name.
+type WorkspaceAgentDNSRecord struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	// The name relative to the workspace, e.g. "api" for api.<workspace>.coder. A leading "*." label makes it a wildcard.
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// The agent port that port 80 of the record's own address is forwarded to. Zero means the name resolves to the agent's address.
+	Port int32 `db:"port" json:"port"`
+}
+
 type WorkspaceAgentLog struct {
 	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
@@ -3031,6 +3443,13 @@ type WorkspaceAgentScript struct {
 	TimeoutSeconds   int32     `db:"timeout_seconds" json:"timeout_seconds"`
 	DisplayName      string    `db:"display_name" json:"display_name"`
 	ID               uuid.UUID `db:"id" json:"id"`
+	// The IDs of the scripts of the same agent that must succeed, or become ready, before this script is started.
+	DependsOn []uuid.UUID `db:"depends_on" json:"depends_on"`
+	// A local TCP port that must accept connections before the dependents of this script are started. Zero means no port is awaited.
+	ReadyPort int32 `db:"ready_port" json:"ready_port"`
+	// A file that must exist before the dependents of this script are started. Empty means no file is awaited.
+	ReadyFile           string `db:"ready_file" json:"ready_file"`
+	ReadyTimeoutSeconds int32  `db:"ready_timeout_seconds" json:"ready_timeout_seconds"`
 }
 
 type WorkspaceAgentScriptTiming struct {
@@ -3042,6 +3461,32 @@ type WorkspaceAgentScriptTiming struct {
 	Status    WorkspaceAgentScriptTimingStatus `db:"status" json:"status"`
 }
 
+// Long-running processes declared by coder_service resources and supervised by the workspace agent.
+type WorkspaceAgentService struct {
+	ID               uuid.UUID `db:"id" json:"id"`
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	Name             string    `db:"name" json:"name"`
+	DisplayName      string    `db:"display_name" json:"display_name"`
+	Command          string    `db:"command" json:"command"`
+	Directory        string    `db:"directory" json:"directory"`
+	// Extra environment variables for the service process, as a JSON object of strings.
+	Env                      json.RawMessage                    `db:"env" json:"env"`
+	RestartPolicy            WorkspaceAgentServiceRestartPolicy `db:"restart_policy" json:"restart_policy"`
+	RestartBackoffSeconds    int32                              `db:"restart_backoff_seconds" json:"restart_backoff_seconds"`
+	MaxRestartBackoffSeconds int32                              `db:"max_restart_backoff_seconds" json:"max_restart_backoff_seconds"`
+	HealthcheckUrl           string                             `db:"healthcheck_url" json:"healthcheck_url"`
+	HealthcheckInterval      int32                              `db:"healthcheck_interval" json:"healthcheck_interval"`
+	HealthcheckThreshold     int32                              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
+	// The last state reported by the agent.
+	State WorkspaceAgentServiceState `db:"state" json:"state"`
+	// The number of times the agent has restarted the service since the agent started.
+	RestartCount   int32        `db:"restart_count" json:"restart_count"`
+	ExitCode       int32        `db:"exit_code" json:"exit_code"`
+	StatusMessage  string       `db:"status_message" json:"status_message"`
+	StateChangedAt sql.NullTime `db:"state_changed_at" json:"state_changed_at"`
+}
+
 type WorkspaceAgentStat struct {
 	ID                          uuid.UUID       `db:"id" json:"id"`
 	CreatedAt                   time.Time       `db:"created_at" json:"created_at"`
@@ -3083,6 +3528,17 @@ type WorkspaceApp struct {
 	DisplayOrder int32 `db:"display_order" json:"display_order"`
 	// Determines if the app is not shown in user interfaces.
 	Hidden bool `db:"hidden" json:"hidden"`
+	// A host:port that must accept connections for the app to be healthy, instead of requesting healthcheck_url.
+	HealthcheckTcp string `db:"healthcheck_tcp" json:"healthcheck_tcp"`
+	// A command run by the agent that must exit with code zero for the app to be healthy, instead of requesting healthcheck_url.
+	HealthcheckCommand string `db:"healthcheck_command" json:"healthcheck_command"`
+	// The HTTP status codes that count as healthy. Any status below 500 is healthy when empty.
+	HealthcheckExpectedStatuses []int32 `db:"healthcheck_expected_statuses" json:"healthcheck_expected_statuses"`
+	HealthcheckBodyRegex        string  `db:"healthcheck_body_regex" json:"healthcheck_body_regex"`
+	// Extra headers for the health check request, as a JSON object of strings.
+	HealthcheckHeaders json.RawMessage `db:"healthcheck_headers" json:"healthcheck_headers"`
+	// Why the last health check failed, as reported by the agent. Empty when healthy.
+	HealthReason string `db:"health_reason" json:"health_reason"`
 }
 
 // A record of workspace app usage statistics
@@ -3154,6 +3610,17 @@ type WorkspaceBuildTable struct {
 	MaxDeadline       time.Time           `db:"max_deadline" json:"max_deadline"`
 }
 
+// The latest drift check of a workspace build. A drift check runs a refresh-only plan to find the resources that were changed outside of builds.
+type WorkspaceDrift struct {
+	WorkspaceBuildID uuid.UUID `db:"workspace_build_id" json:"workspace_build_id"`
+	JobID            uuid.UUID `db:"job_id" json:"job_id"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	// When the latest completed check finished. NULL until a check completes.
+	CheckedAt sql.NullTime `db:"checked_at" json:"checked_at"`
+	// The drifted resources found by the latest completed check.
+	Resources json.RawMessage `db:"resources" json:"resources"`
+}
+
 type WorkspaceModule struct {
 	ID         uuid.UUID           `db:"id" json:"id"`
 	JobID      uuid.UUID           `db:"job_id" json:"job_id"`
@@ -3185,6 +3652,8 @@ type WorkspaceProxy struct {
 	// Disables app/terminal proxying for this proxy and only acts as a DERP relay.
 	DerpOnly bool   `db:"derp_only" json:"derp_only"`
 	Version  string `db:"version" json:"version"`
+	// Draining proxies are removed from the list of regions and avoided as DERP home regions, but keep serving existing connections.
+	Draining bool `db:"draining" json:"draining"`
 }
 
 type WorkspaceResource struct {
@@ -1010,7 +1010,7 @@ func TestQueuePosition(t *testing.T) {
 	}
 
 	job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-		OrganizationID: org.ID,
+		OrganizationIDs: []uuid.UUID{org.ID},
 		StartedAt: sql.NullTime{
 			Time:  dbtime.Now(),
 			Valid: true,
@@ -1042,6 +1042,150 @@ func TestQueuePosition(t *testing.T) {
 	}
 }
 
+func TestQueuePriority(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.SkipNow()
+	}
+	sqlDB := testSQLDB(t)
+	err := migrations.Up(sqlDB)
+	require.NoError(t, err)
+	db := database.New(sqlDB)
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	busyUser := uuid.New()
+	otherUser := uuid.New()
+	now := dbtime.Now()
+
+	// The busy user already has a job running.
+	_ = dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		InitiatorID:    busyUser,
+		CreatedAt:      now.Add(-5 * time.Minute),
+		StartedAt:      sql.NullTime{Time: now, Valid: true},
+	})
+	bulk := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		InitiatorID:    otherUser,
+		CreatedAt:      now.Add(-4 * time.Minute),
+		Tags:           database.StringMap{},
+		Priority:       database.ProvisionerJobPriorityBulk,
+	})
+	autostart := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		InitiatorID:    otherUser,
+		CreatedAt:      now.Add(-3 * time.Minute),
+		Tags:           database.StringMap{},
+		Priority:       database.ProvisionerJobPriorityAutostart,
+	})
+	busyInteractive := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		InitiatorID:    busyUser,
+		CreatedAt:      now.Add(-2 * time.Minute),
+		Tags:           database.StringMap{},
+	})
+	otherInteractive := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		InitiatorID:    otherUser,
+		CreatedAt:      now.Add(-time.Minute),
+		Tags:           database.StringMap{},
+	})
+
+	// Queue positions follow the priority, then the creation time.
+	queued, err := db.GetProvisionerJobsByIDsWithQueuePosition(ctx, []uuid.UUID{
+		bulk.ID, autostart.ID, busyInteractive.ID, otherInteractive.ID,
+	})
+	require.NoError(t, err)
+	positions := map[uuid.UUID]int64{}
+	for _, job := range queued {
+		require.EqualValues(t, 4, job.QueueSize)
+		positions[job.ProvisionerJob.ID] = job.QueuePosition
+	}
+	require.Equal(t, map[uuid.UUID]int64{
+		busyInteractive.ID:  1,
+		otherInteractive.ID: 2,
+		autostart.ID:        3,
+		bulk.ID:             4,
+	}, positions)
+
+	// Within a priority, users without running jobs go first.
+	for _, expected := range []uuid.UUID{otherInteractive.ID, busyInteractive.ID, autostart.ID, bulk.ID} {
+		job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			OrganizationIDs: []uuid.UUID{org.ID},
+			StartedAt: sql.NullTime{
+				Time:  dbtime.Now(),
+				Valid: true,
+			},
+			Types: database.AllProvisionerTypeValues(),
+			WorkerID: uuid.NullUUID{
+				UUID:  uuid.New(),
+				Valid: true,
+			},
+			ProvisionerTags: json.RawMessage("{}"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, job.ID)
+	}
+}
+
+func TestQueueOrganizationFairness(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.SkipNow()
+	}
+	sqlDB := testSQLDB(t)
+	err := migrations.Up(sqlDB)
+	require.NoError(t, err)
+	db := database.New(sqlDB)
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	busyOrg := dbgen.Organization(t, db, database.Organization{})
+	otherOrg := dbgen.Organization(t, db, database.Organization{})
+	now := dbtime.Now()
+
+	// The busy organization already has a job running.
+	_ = dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: busyOrg.ID,
+		CreatedAt:      now.Add(-5 * time.Minute),
+		StartedAt:      sql.NullTime{Time: now, Valid: true},
+	})
+	busyJob := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: busyOrg.ID,
+		InitiatorID:    uuid.New(),
+		CreatedAt:      now.Add(-2 * time.Minute),
+		Tags:           database.StringMap{},
+	})
+	otherJob := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: otherOrg.ID,
+		InitiatorID:    uuid.New(),
+		CreatedAt:      now.Add(-time.Minute),
+		Tags:           database.StringMap{},
+	})
+
+	// A daemon serving both organizations picks the organization without
+	// running jobs first, even though its job is newer.
+	for _, expected := range []uuid.UUID{otherJob.ID, busyJob.ID} {
+		job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			OrganizationIDs: []uuid.UUID{busyOrg.ID, otherOrg.ID},
+			StartedAt: sql.NullTime{
+				Time:  dbtime.Now(),
+				Valid: true,
+			},
+			Types: database.AllProvisionerTypeValues(),
+			WorkerID: uuid.NullUUID{
+				UUID:  uuid.New(),
+				Valid: true,
+			},
+			ProvisionerTags: json.RawMessage("{}"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, job.ID)
+	}
+}
+
 func TestUserLastSeenFilter(t *testing.T) {
 	t.Parallel()
 	if testing.Short() {
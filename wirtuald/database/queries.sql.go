@@ -5558,25 +5558,53 @@ WHERE
 			provisioner_jobs AS potential_job
 		WHERE
 			potential_job.started_at IS NULL
-			AND potential_job.organization_id = $3
+			AND potential_job.organization_id = ANY($3 :: uuid [ ])
 			-- Ensure the caller has the correct provisioner.
 			AND potential_job.provisioner = ANY($4 :: provisioner_type [ ])
 			-- elsewhere, we use the tagset type, but here we use jsonb for backward compatibility
 			-- they are aliases and the code that calls this query already relies on a different type
 			AND provisioner_tagset_contains($5 :: jsonb, potential_job.tags :: jsonb)
 		ORDER BY
+			potential_job.priority,
+			-- Fair-share between organizations: daemons that serve several
+			-- organizations prefer the jobs of organizations with the fewest
+			-- running jobs, so that one busy organization doesn't hold back the
+			-- others.
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running_job
+				WHERE
+					running_job.organization_id = potential_job.organization_id
+					AND running_job.started_at IS NOT NULL
+					AND running_job.completed_at IS NULL
+			),
+			-- Fair-share between users: prefer the jobs of initiators with the
+			-- fewest running jobs, so that one user queueing many jobs doesn't
+			-- hold back the jobs of everyone else.
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running_job
+				WHERE
+					running_job.initiator_id = potential_job.initiator_id
+					AND running_job.started_at IS NOT NULL
+					AND running_job.completed_at IS NULL
+			),
 			potential_job.created_at
 		FOR UPDATE
 		SKIP LOCKED
 		LIMIT
 			1
-	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, engine, engine_version, priority
 `
 
 type AcquireProvisionerJobParams struct {
 	StartedAt       sql.NullTime      `db:"started_at" json:"started_at"`
 	WorkerID        uuid.NullUUID     `db:"worker_id" json:"worker_id"`
-	OrganizationID  uuid.UUID         `db:"organization_id" json:"organization_id"`
+	OrganizationIDs []uuid.UUID       `db:"organization_ids" json:"organization_ids"`
 	Types           []ProvisionerType `db:"types" json:"types"`
 	ProvisionerTags json.RawMessage   `db:"provisioner_tags" json:"provisioner_tags"`
 }
@@ -5591,7 +5619,7 @@ func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvi
 	row := q.db.QueryRowContext(ctx, acquireProvisionerJob,
 		arg.StartedAt,
 		arg.WorkerID,
-		arg.OrganizationID,
+		pq.Array(arg.OrganizationIDs),
 		pq.Array(arg.Types),
 		arg.ProvisionerTags,
 	)
@@ -5616,13 +5644,16 @@ func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvi
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Engine,
+		&i.EngineVersion,
+		&i.Priority,
 	)
 	return i, err
 }
 
 const getHungProvisionerJobs = `-- name: GetHungProvisionerJobs :many
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, engine, engine_version, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -5660,6 +5691,9 @@ func (q *sqlQuerier) GetHungProvisionerJobs(ctx context.Context, updatedAt time.
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Engine,
+			&i.EngineVersion,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -5676,7 +5710,7 @@ func (q *sqlQuerier) GetHungProvisionerJobs(ctx context.Context, updatedAt time.
 
 const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, engine, engine_version, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -5706,6 +5740,9 @@ func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (P
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Engine,
+		&i.EngineVersion,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -5749,7 +5786,7 @@ func (q *sqlQuerier) GetProvisionerJobTimingsByJobID(ctx context.Context, jobID
 
 const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, engine, engine_version, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -5785,6 +5822,9 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Engine,
+			&i.EngineVersion,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -5802,7 +5842,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 const getProvisionerJobsByIDsWithQueuePosition = `-- name: GetProvisionerJobsByIDsWithQueuePosition :many
 WITH unstarted_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, organization_id, priority
     FROM
         provisioner_jobs
     WHERE
@@ -5811,31 +5851,65 @@ WITH unstarted_jobs AS (
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        -- Most daemons only acquire the jobs of their own organization, so the
+        -- position counts the jobs of the same organization.
+        ROW_NUMBER() OVER (PARTITION BY organization_id ORDER BY priority ASC, created_at ASC) AS queue_position
     FROM
         unstarted_jobs
 ),
 queue_size AS (
-	SELECT COUNT(*) as count FROM unstarted_jobs
+	SELECT organization_id, COUNT(*) as count FROM unstarted_jobs GROUP BY organization_id
+),
+-- The wait in the queue is estimated from the duration of recently completed
+-- jobs and the number of connected daemons.
+job_durations AS (
+	SELECT
+		organization_id,
+		AVG(EXTRACT(EPOCH FROM completed_at - started_at) * 1000) AS average_duration_ms
+	FROM
+		provisioner_jobs
+	WHERE
+		completed_at > NOW() - INTERVAL '1 day'
+		AND started_at IS NOT NULL
+	GROUP BY
+		organization_id
+),
+connected_daemons AS (
+	SELECT
+		organization_id,
+		COUNT(*) AS count
+	FROM
+		provisioner_daemons
+	WHERE
+		-- Matches provisionerdserver.StaleInterval.
+		last_seen_at > NOW() - INTERVAL '90 seconds'
+	GROUP BY
+		organization_id
 )
 SELECT
-	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.job_status,
+	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.job_status, pj.engine, pj.engine_version, pj.priority,
     COALESCE(qp.queue_position, 0) AS queue_position,
-    COALESCE(qs.count, 0) AS queue_size
+    COALESCE(qs.count, 0) AS queue_size,
+    COALESCE(CEIL(qp.queue_position :: float / GREATEST(cd.count, 1)) * jd.average_duration_ms, 0) :: bigint AS queue_estimated_wait_ms
 FROM
 	provisioner_jobs pj
 LEFT JOIN
 	queue_position qp ON qp.id = pj.id
 LEFT JOIN
-	queue_size qs ON TRUE
+	queue_size qs ON qs.organization_id = pj.organization_id
+LEFT JOIN
+	job_durations jd ON jd.organization_id = pj.organization_id
+LEFT JOIN
+	connected_daemons cd ON cd.organization_id = pj.organization_id
 WHERE
 	pj.id = ANY($1 :: uuid [ ])
 `
 
 type GetProvisionerJobsByIDsWithQueuePositionRow struct {
-	ProvisionerJob ProvisionerJob `db:"provisioner_job" json:"provisioner_job"`
-	QueuePosition  int64          `db:"queue_position" json:"queue_position"`
-	QueueSize      int64          `db:"queue_size" json:"queue_size"`
+	ProvisionerJob       ProvisionerJob `db:"provisioner_job" json:"provisioner_job"`
+	QueuePosition        int64          `db:"queue_position" json:"queue_position"`
+	QueueSize            int64          `db:"queue_size" json:"queue_size"`
+	QueueEstimatedWaitMs int64          `db:"queue_estimated_wait_ms" json:"queue_estimated_wait_ms"`
 }
 
 func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Context, ids []uuid.UUID) ([]GetProvisionerJobsByIDsWithQueuePositionRow, error) {
@@ -5867,8 +5941,12 @@ func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Contex
 			&i.ProvisionerJob.ErrorCode,
 			&i.ProvisionerJob.TraceMetadata,
 			&i.ProvisionerJob.JobStatus,
+			&i.ProvisionerJob.Engine,
+			&i.ProvisionerJob.EngineVersion,
+			&i.ProvisionerJob.Priority,
 			&i.QueuePosition,
 			&i.QueueSize,
+			&i.QueueEstimatedWaitMs,
 		); err != nil {
 			return nil, err
 		}
@@ -5884,7 +5962,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Contex
 }
 
 const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
-SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status FROM provisioner_jobs WHERE created_at > $1
+SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, engine, engine_version, priority FROM provisioner_jobs WHERE created_at > $1
 `
 
 func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
@@ -5916,6 +5994,9 @@ func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, created
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Engine,
+			&i.EngineVersion,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -5944,10 +6025,11 @@ INSERT INTO
 		"type",
 		"input",
 		tags,
-		trace_metadata
+		trace_metadata,
+		priority
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, engine, engine_version, priority
 `
 
 type InsertProvisionerJobParams struct {
@@ -5963,6 +6045,7 @@ type InsertProvisionerJobParams struct {
 	Input          json.RawMessage          `db:"input" json:"input"`
 	Tags           StringMap                `db:"tags" json:"tags"`
 	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+	Priority       ProvisionerJobPriority   `db:"priority" json:"priority"`
 }
 
 func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
@@ -5979,6 +6062,7 @@ func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisi
 		arg.Input,
 		arg.Tags,
 		arg.TraceMetadata,
+		arg.Priority,
 	)
 	var i ProvisionerJob
 	err := row.Scan(
@@ -6001,6 +6085,9 @@ func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisi
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Engine,
+		&i.EngineVersion,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -6138,6 +6225,34 @@ func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, a
 	return err
 }
 
+const updateProvisionerJobEngineByID = `-- name: UpdateProvisionerJobEngineByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $1,
+	engine = $2,
+	engine_version = $3
+WHERE
+	id = $4
+`
+
+type UpdateProvisionerJobEngineByIDParams struct {
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	Engine        string    `db:"engine" json:"engine"`
+	EngineVersion string    `db:"engine_version" json:"engine_version"`
+	ID            uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobEngineByID(ctx context.Context, arg UpdateProvisionerJobEngineByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobEngineByID,
+		arg.UpdatedAt,
+		arg.Engine,
+		arg.EngineVersion,
+		arg.ID,
+	)
+	return err
+}
+
 const deleteProvisionerKey = `-- name: DeleteProvisionerKey :exec
 DELETE FROM
     provisioner_keys
@@ -6359,7 +6474,7 @@ func (q *sqlQuerier) ListProvisionerKeysByOrganizationExcludeReserved(ctx contex
 
 const getWorkspaceProxies = `-- name: GetWorkspaceProxies :many
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version, draining
 FROM
 	workspace_proxies
 WHERE
@@ -6390,6 +6505,7 @@ func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy,
 			&i.DerpEnabled,
 			&i.DerpOnly,
 			&i.Version,
+			&i.Draining,
 		); err != nil {
 			return nil, err
 		}
@@ -6406,7 +6522,7 @@ func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy,
 
 const getWorkspaceProxyByHostname = `-- name: GetWorkspaceProxyByHostname :one
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version, draining
 FROM
 	workspace_proxies
 WHERE
@@ -6466,13 +6582,14 @@ func (q *sqlQuerier) GetWorkspaceProxyByHostname(ctx context.Context, arg GetWor
 		&i.DerpEnabled,
 		&i.DerpOnly,
 		&i.Version,
+		&i.Draining,
 	)
 	return i, err
 }
 
 const getWorkspaceProxyByID = `-- name: GetWorkspaceProxyByID :one
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version, draining
 FROM
 	workspace_proxies
 WHERE
@@ -6499,13 +6616,14 @@ func (q *sqlQuerier) GetWorkspaceProxyByID(ctx context.Context, id uuid.UUID) (W
 		&i.DerpEnabled,
 		&i.DerpOnly,
 		&i.Version,
+		&i.Draining,
 	)
 	return i, err
 }
 
 const getWorkspaceProxyByName = `-- name: GetWorkspaceProxyByName :one
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version, draining
 FROM
 	workspace_proxies
 WHERE
@@ -6533,6 +6651,7 @@ func (q *sqlQuerier) GetWorkspaceProxyByName(ctx context.Context, name string) (
 		&i.DerpEnabled,
 		&i.DerpOnly,
 		&i.Version,
+		&i.Draining,
 	)
 	return i, err
 }
@@ -6554,7 +6673,7 @@ INSERT INTO
 		deleted
 	)
 VALUES
-	($1, '', '', $2, $3, $4, $5, $6, $7, $8, $9, false) RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version
+	($1, '', '', $2, $3, $4, $5, $6, $7, $8, $9, false) RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version, draining
 `
 
 type InsertWorkspaceProxyParams struct {
@@ -6597,6 +6716,7 @@ func (q *sqlQuerier) InsertWorkspaceProxy(ctx context.Context, arg InsertWorkspa
 		&i.DerpEnabled,
 		&i.DerpOnly,
 		&i.Version,
+		&i.Draining,
 	)
 	return i, err
 }
@@ -6613,7 +6733,7 @@ SET
 	updated_at = Now()
 WHERE
 	id = $6
-RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version
+RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version, draining
 `
 
 type RegisterWorkspaceProxyParams struct {
@@ -6650,6 +6770,7 @@ func (q *sqlQuerier) RegisterWorkspaceProxy(ctx context.Context, arg RegisterWor
 		&i.DerpEnabled,
 		&i.DerpOnly,
 		&i.Version,
+		&i.Draining,
 	)
 	return i, err
 }
@@ -6672,7 +6793,7 @@ SET
 	updated_at = Now()
 WHERE
 	id = $5
-RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version
+RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version, draining
 `
 
 type UpdateWorkspaceProxyParams struct {
@@ -6708,6 +6829,7 @@ func (q *sqlQuerier) UpdateWorkspaceProxy(ctx context.Context, arg UpdateWorkspa
 		&i.DerpEnabled,
 		&i.DerpOnly,
 		&i.Version,
+		&i.Draining,
 	)
 	return i, err
 }
@@ -6732,6 +6854,45 @@ func (q *sqlQuerier) UpdateWorkspaceProxyDeleted(ctx context.Context, arg Update
 	return err
 }
 
+const updateWorkspaceProxyDraining = `-- name: UpdateWorkspaceProxyDraining :one
+UPDATE
+	workspace_proxies
+SET
+	updated_at = Now(),
+	draining = $1
+WHERE
+	id = $2
+RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, version, draining
+`
+
+type UpdateWorkspaceProxyDrainingParams struct {
+	Draining bool      `db:"draining" json:"draining"`
+	ID       uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceProxyDraining(ctx context.Context, arg UpdateWorkspaceProxyDrainingParams) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceProxyDraining, arg.Draining, arg.ID)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+		&i.RegionID,
+		&i.DerpEnabled,
+		&i.DerpOnly,
+		&i.Version,
+		&i.Draining,
+	)
+	return i, err
+}
+
 const getQuotaAllowanceForUser = `-- name: GetQuotaAllowanceForUser :one
 SELECT
 	coalesce(SUM(groups.quota_allowance), 0)::BIGINT
@@ -8234,6 +8395,430 @@ func (q *sqlQuerier) UpsertTailnetTunnel(ctx context.Context, arg UpsertTailnetT
 	return i, err
 }
 
+const acquireTemplateGitSources = `-- name: AcquireTemplateGitSources :many
+UPDATE
+	template_git_sources
+SET
+	last_checked_at = $1 :: timestamptz,
+	sync_requested_at = NULL
+WHERE
+	template_id IN (
+		SELECT
+			template_git_sources.template_id
+		FROM
+			template_git_sources
+		INNER JOIN
+			templates ON templates.id = template_git_sources.template_id
+		WHERE
+			templates.deleted = false
+			AND template_git_sources.job_id IS NULL
+			AND (
+				template_git_sources.sync_requested_at IS NOT NULL
+				OR template_git_sources.last_checked_at IS NULL
+				OR template_git_sources.last_checked_at < $2 :: timestamptz
+			)
+		ORDER BY
+			template_git_sources.sync_requested_at ASC NULLS LAST,
+			template_git_sources.last_checked_at ASC NULLS FIRST
+		LIMIT
+			$3 :: int
+		FOR UPDATE OF template_git_sources SKIP LOCKED
+	)
+RETURNING template_id, user_id, created_at, updated_at, repo_url, path, ref, external_auth_provider_id, deploy_private_key, deploy_public_key, webhook_secret, auto_promote, sync_status, sync_error, sync_requested_at, last_checked_at, last_synced_at, last_commit_sha, template_version_id, job_id, deploy_private_key_key_id, known_hosts
+`
+
+type AcquireTemplateGitSourcesParams struct {
+	Now           time.Time `db:"now" json:"now"`
+	CheckedBefore time.Time `db:"checked_before" json:"checked_before"`
+	LimitOpt      int32     `db:"limit_opt" json:"limit_opt"`
+}
+
+// Claims the sources whose repository must be checked for new commits: those
+// that were requested to sync, and those that haven't been checked since
+// checked_before. Sources that are waiting for a job are skipped. Concurrent
+// callers never claim the same source.
+func (q *sqlQuerier) AcquireTemplateGitSources(ctx context.Context, arg AcquireTemplateGitSourcesParams) ([]TemplateGitSource, error) {
+	rows, err := q.db.QueryContext(ctx, acquireTemplateGitSources, arg.Now, arg.CheckedBefore, arg.LimitOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateGitSource
+	for rows.Next() {
+		var i TemplateGitSource
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RepoURL,
+			&i.Path,
+			&i.Ref,
+			&i.ExternalAuthProviderID,
+			&i.DeployPrivateKey,
+			&i.DeployPublicKey,
+			&i.WebhookSecret,
+			&i.AutoPromote,
+			&i.SyncStatus,
+			&i.SyncError,
+			&i.SyncRequestedAt,
+			&i.LastCheckedAt,
+			&i.LastSyncedAt,
+			&i.LastCommitSHA,
+			&i.TemplateVersionID,
+			&i.JobID,
+			&i.DeployPrivateKeyKeyID,
+			&i.KnownHosts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteTemplateGitSource = `-- name: DeleteTemplateGitSource :exec
+DELETE FROM template_git_sources WHERE template_id = $1
+`
+
+func (q *sqlQuerier) DeleteTemplateGitSource(ctx context.Context, templateID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateGitSource, templateID)
+	return err
+}
+
+const getTemplateGitSourceByTemplateID = `-- name: GetTemplateGitSourceByTemplateID :one
+SELECT template_id, user_id, created_at, updated_at, repo_url, path, ref, external_auth_provider_id, deploy_private_key, deploy_public_key, webhook_secret, auto_promote, sync_status, sync_error, sync_requested_at, last_checked_at, last_synced_at, last_commit_sha, template_version_id, job_id, deploy_private_key_key_id, known_hosts FROM template_git_sources WHERE template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateGitSource, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateGitSourceByTemplateID, templateID)
+	var i TemplateGitSource
+	err := row.Scan(
+		&i.TemplateID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RepoURL,
+		&i.Path,
+		&i.Ref,
+		&i.ExternalAuthProviderID,
+		&i.DeployPrivateKey,
+		&i.DeployPublicKey,
+		&i.WebhookSecret,
+		&i.AutoPromote,
+		&i.SyncStatus,
+		&i.SyncError,
+		&i.SyncRequestedAt,
+		&i.LastCheckedAt,
+		&i.LastSyncedAt,
+		&i.LastCommitSHA,
+		&i.TemplateVersionID,
+		&i.JobID,
+		&i.DeployPrivateKeyKeyID,
+		&i.KnownHosts,
+	)
+	return i, err
+}
+
+const getTemplateGitSourcesWithCompletedJobs = `-- name: GetTemplateGitSourcesWithCompletedJobs :many
+SELECT
+	template_git_sources.template_id, template_git_sources.user_id, template_git_sources.created_at, template_git_sources.updated_at, template_git_sources.repo_url, template_git_sources.path, template_git_sources.ref, template_git_sources.external_auth_provider_id, template_git_sources.deploy_private_key, template_git_sources.deploy_public_key, template_git_sources.webhook_secret, template_git_sources.auto_promote, template_git_sources.sync_status, template_git_sources.sync_error, template_git_sources.sync_requested_at, template_git_sources.last_checked_at, template_git_sources.last_synced_at, template_git_sources.last_commit_sha, template_git_sources.template_version_id, template_git_sources.job_id, template_git_sources.deploy_private_key_key_id, template_git_sources.known_hosts
+FROM
+	template_git_sources
+INNER JOIN
+	provisioner_jobs ON provisioner_jobs.id = template_git_sources.job_id
+WHERE
+	provisioner_jobs.completed_at IS NOT NULL
+`
+
+// Returns the sources whose import or dry run job has completed, so the sync
+// can move on.
+func (q *sqlQuerier) GetTemplateGitSourcesWithCompletedJobs(ctx context.Context) ([]TemplateGitSource, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateGitSourcesWithCompletedJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateGitSource
+	for rows.Next() {
+		var i TemplateGitSource
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RepoURL,
+			&i.Path,
+			&i.Ref,
+			&i.ExternalAuthProviderID,
+			&i.DeployPrivateKey,
+			&i.DeployPublicKey,
+			&i.WebhookSecret,
+			&i.AutoPromote,
+			&i.SyncStatus,
+			&i.SyncError,
+			&i.SyncRequestedAt,
+			&i.LastCheckedAt,
+			&i.LastSyncedAt,
+			&i.LastCommitSHA,
+			&i.TemplateVersionID,
+			&i.JobID,
+			&i.DeployPrivateKeyKeyID,
+			&i.KnownHosts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateGitSourcesWithDeployKeys = `-- name: GetTemplateGitSourcesWithDeployKeys :many
+SELECT template_id, user_id, created_at, updated_at, repo_url, path, ref, external_auth_provider_id, deploy_private_key, deploy_public_key, webhook_secret, auto_promote, sync_status, sync_error, sync_requested_at, last_checked_at, last_synced_at, last_commit_sha, template_version_id, job_id, deploy_private_key_key_id, known_hosts FROM template_git_sources WHERE deploy_private_key != ''
+`
+
+// Returns the sources that fetch their repository with a deploy key, so the
+// keys can be encrypted again when the database encryption keys are rotated.
+func (q *sqlQuerier) GetTemplateGitSourcesWithDeployKeys(ctx context.Context) ([]TemplateGitSource, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateGitSourcesWithDeployKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateGitSource
+	for rows.Next() {
+		var i TemplateGitSource
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RepoURL,
+			&i.Path,
+			&i.Ref,
+			&i.ExternalAuthProviderID,
+			&i.DeployPrivateKey,
+			&i.DeployPublicKey,
+			&i.WebhookSecret,
+			&i.AutoPromote,
+			&i.SyncStatus,
+			&i.SyncError,
+			&i.SyncRequestedAt,
+			&i.LastCheckedAt,
+			&i.LastSyncedAt,
+			&i.LastCommitSHA,
+			&i.TemplateVersionID,
+			&i.JobID,
+			&i.DeployPrivateKeyKeyID,
+			&i.KnownHosts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requestTemplateGitSourceSync = `-- name: RequestTemplateGitSourceSync :exec
+UPDATE
+	template_git_sources
+SET
+	sync_requested_at = $1 :: timestamptz
+WHERE
+	template_id = $2
+`
+
+type RequestTemplateGitSourceSyncParams struct {
+	RequestedAt time.Time `db:"requested_at" json:"requested_at"`
+	TemplateID  uuid.UUID `db:"template_id" json:"template_id"`
+}
+
+func (q *sqlQuerier) RequestTemplateGitSourceSync(ctx context.Context, arg RequestTemplateGitSourceSyncParams) error {
+	_, err := q.db.ExecContext(ctx, requestTemplateGitSourceSync, arg.RequestedAt, arg.TemplateID)
+	return err
+}
+
+const updateTemplateGitSourceDeployKeyByTemplateID = `-- name: UpdateTemplateGitSourceDeployKeyByTemplateID :exec
+UPDATE
+	template_git_sources
+SET
+	deploy_private_key = $1,
+	deploy_private_key_key_id = $2
+WHERE
+	template_id = $3
+`
+
+type UpdateTemplateGitSourceDeployKeyByTemplateIDParams struct {
+	DeployPrivateKey      string         `db:"deploy_private_key" json:"deploy_private_key"`
+	DeployPrivateKeyKeyID sql.NullString `db:"deploy_private_key_key_id" json:"deploy_private_key_key_id"`
+	TemplateID            uuid.UUID      `db:"template_id" json:"template_id"`
+}
+
+func (q *sqlQuerier) UpdateTemplateGitSourceDeployKeyByTemplateID(ctx context.Context, arg UpdateTemplateGitSourceDeployKeyByTemplateIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateGitSourceDeployKeyByTemplateID, arg.DeployPrivateKey, arg.DeployPrivateKeyKeyID, arg.TemplateID)
+	return err
+}
+
+const updateTemplateGitSourceSyncByTemplateID = `-- name: UpdateTemplateGitSourceSyncByTemplateID :exec
+UPDATE
+	template_git_sources
+SET
+	sync_status = $1,
+	sync_error = $2,
+	last_synced_at = $3,
+	last_commit_sha = $4,
+	template_version_id = $5,
+	job_id = $6
+WHERE
+	template_id = $7
+`
+
+type UpdateTemplateGitSourceSyncByTemplateIDParams struct {
+	SyncStatus        TemplateGitSyncStatus `db:"sync_status" json:"sync_status"`
+	SyncError         string                `db:"sync_error" json:"sync_error"`
+	LastSyncedAt      sql.NullTime          `db:"last_synced_at" json:"last_synced_at"`
+	LastCommitSHA     string                `db:"last_commit_sha" json:"last_commit_sha"`
+	TemplateVersionID uuid.NullUUID         `db:"template_version_id" json:"template_version_id"`
+	JobID             uuid.NullUUID         `db:"job_id" json:"job_id"`
+	TemplateID        uuid.UUID             `db:"template_id" json:"template_id"`
+}
+
+func (q *sqlQuerier) UpdateTemplateGitSourceSyncByTemplateID(ctx context.Context, arg UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateGitSourceSyncByTemplateID,
+		arg.SyncStatus,
+		arg.SyncError,
+		arg.LastSyncedAt,
+		arg.LastCommitSHA,
+		arg.TemplateVersionID,
+		arg.JobID,
+		arg.TemplateID,
+	)
+	return err
+}
+
+const upsertTemplateGitSource = `-- name: UpsertTemplateGitSource :one
+INSERT INTO
+	template_git_sources (
+		template_id,
+		user_id,
+		created_at,
+		updated_at,
+		repo_url,
+		path,
+		ref,
+		external_auth_provider_id,
+		deploy_private_key,
+		deploy_private_key_key_id,
+		deploy_public_key,
+		known_hosts,
+		webhook_secret,
+		auto_promote,
+		sync_requested_at
+	)
+VALUES
+	($1, $2, $3, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $3)
+ON CONFLICT (template_id) DO UPDATE SET
+	user_id = $2,
+	updated_at = $3,
+	repo_url = $4,
+	path = $5,
+	ref = $6,
+	external_auth_provider_id = $7,
+	deploy_private_key = $8,
+	deploy_private_key_key_id = $9,
+	deploy_public_key = $10,
+	known_hosts = $11,
+	webhook_secret = $12,
+	auto_promote = $13,
+	sync_requested_at = $3,
+	last_commit_sha = CASE
+		WHEN (template_git_sources.repo_url, template_git_sources.path, template_git_sources.ref) = ($4, $5, $6) THEN template_git_sources.last_commit_sha
+		ELSE ''
+	END
+RETURNING template_id, user_id, created_at, updated_at, repo_url, path, ref, external_auth_provider_id, deploy_private_key, deploy_public_key, webhook_secret, auto_promote, sync_status, sync_error, sync_requested_at, last_checked_at, last_synced_at, last_commit_sha, template_version_id, job_id, deploy_private_key_key_id, known_hosts
+`
+
+type UpsertTemplateGitSourceParams struct {
+	TemplateID             uuid.UUID      `db:"template_id" json:"template_id"`
+	UserID                 uuid.UUID      `db:"user_id" json:"user_id"`
+	UpdatedAt              time.Time      `db:"updated_at" json:"updated_at"`
+	RepoURL                string         `db:"repo_url" json:"repo_url"`
+	Path                   string         `db:"path" json:"path"`
+	Ref                    string         `db:"ref" json:"ref"`
+	ExternalAuthProviderID string         `db:"external_auth_provider_id" json:"external_auth_provider_id"`
+	DeployPrivateKey       string         `db:"deploy_private_key" json:"deploy_private_key"`
+	DeployPrivateKeyKeyID  sql.NullString `db:"deploy_private_key_key_id" json:"deploy_private_key_key_id"`
+	DeployPublicKey        string         `db:"deploy_public_key" json:"deploy_public_key"`
+	KnownHosts             string         `db:"known_hosts" json:"known_hosts"`
+	WebhookSecret          string         `db:"webhook_secret" json:"webhook_secret"`
+	AutoPromote            bool           `db:"auto_promote" json:"auto_promote"`
+}
+
+// The repository is synced again when the source changes. The commit that was
+// synced last is kept unless the template moved to another repository, path or
+// ref.
+func (q *sqlQuerier) UpsertTemplateGitSource(ctx context.Context, arg UpsertTemplateGitSourceParams) (TemplateGitSource, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateGitSource,
+		arg.TemplateID,
+		arg.UserID,
+		arg.UpdatedAt,
+		arg.RepoURL,
+		arg.Path,
+		arg.Ref,
+		arg.ExternalAuthProviderID,
+		arg.DeployPrivateKey,
+		arg.DeployPrivateKeyKeyID,
+		arg.DeployPublicKey,
+		arg.KnownHosts,
+		arg.WebhookSecret,
+		arg.AutoPromote,
+	)
+	var i TemplateGitSource
+	err := row.Scan(
+		&i.TemplateID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RepoURL,
+		&i.Path,
+		&i.Ref,
+		&i.ExternalAuthProviderID,
+		&i.DeployPrivateKey,
+		&i.DeployPublicKey,
+		&i.WebhookSecret,
+		&i.AutoPromote,
+		&i.SyncStatus,
+		&i.SyncError,
+		&i.SyncRequestedAt,
+		&i.LastCheckedAt,
+		&i.LastSyncedAt,
+		&i.LastCommitSHA,
+		&i.TemplateVersionID,
+		&i.JobID,
+		&i.DeployPrivateKeyKeyID,
+		&i.KnownHosts,
+	)
+	return i, err
+}
+
 const getTemplateAverageBuildTime = `-- name: GetTemplateAverageBuildTime :one
 WITH build_times AS (
 SELECT
@@ -8296,7 +8881,7 @@ func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTem
 
 const getTemplateByID = `-- name: GetTemplateByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, job_timeout, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names
 WHERE
@@ -8337,6 +8922,7 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 		&i.Deprecated,
 		&i.ActivityBump,
 		&i.MaxPortSharingLevel,
+		&i.JobTimeout,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 		&i.OrganizationName,
@@ -8348,7 +8934,7 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 
 const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, job_timeout, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names AS templates
 WHERE
@@ -8397,6 +8983,7 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 		&i.Deprecated,
 		&i.ActivityBump,
 		&i.MaxPortSharingLevel,
+		&i.JobTimeout,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 		&i.OrganizationName,
@@ -8407,7 +8994,7 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 }
 
 const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon FROM template_with_names AS templates
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, job_timeout, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon FROM template_with_names AS templates
 ORDER BY (name, id) ASC
 `
 
@@ -8449,6 +9036,7 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 			&i.Deprecated,
 			&i.ActivityBump,
 			&i.MaxPortSharingLevel,
+			&i.JobTimeout,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.OrganizationName,
@@ -8470,7 +9058,7 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 
 const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, job_timeout, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names AS templates
 WHERE
@@ -8570,6 +9158,7 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 			&i.Deprecated,
 			&i.ActivityBump,
 			&i.MaxPortSharingLevel,
+			&i.JobTimeout,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.OrganizationName,
@@ -8746,7 +9335,8 @@ SET
 	display_name = $6,
 	allow_user_cancel_workspace_jobs = $7,
 	group_acl = $8,
-	max_port_sharing_level = $9
+	max_port_sharing_level = $9,
+	job_timeout = $10
 WHERE
 	id = $1
 `
@@ -8761,6 +9351,7 @@ type UpdateTemplateMetaByIDParams struct {
 	AllowUserCancelWorkspaceJobs bool            `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
 	GroupACL                     TemplateACL     `db:"group_acl" json:"group_acl"`
 	MaxPortSharingLevel          AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	JobTimeout                   int64           `db:"job_timeout" json:"job_timeout"`
 }
 
 func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) error {
@@ -8774,6 +9365,7 @@ func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTempl
 		arg.AllowUserCancelWorkspaceJobs,
 		arg.GroupACL,
 		arg.MaxPortSharingLevel,
+		arg.JobTimeout,
 	)
 	return err
 }
@@ -8996,7 +9588,7 @@ FROM
 			-- Scope an archive to a single template and ignore already archived template versions
 			(
 				SELECT
-					id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id
+					id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, git_commit_sha, git_commit_author
 				FROM
 					template_versions
 				WHERE
@@ -9097,7 +9689,7 @@ func (q *sqlQuerier) ArchiveUnusedTemplateVersions(ctx context.Context, arg Arch
 
 const getPreviousTemplateVersion = `-- name: GetPreviousTemplateVersion :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, created_by_avatar_url, created_by_username
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, git_commit_sha, git_commit_author, created_by_avatar_url, created_by_username
 FROM
 	template_version_with_user AS template_versions
 WHERE
@@ -9135,6 +9727,8 @@ func (q *sqlQuerier) GetPreviousTemplateVersion(ctx context.Context, arg GetPrev
 		&i.Message,
 		&i.Archived,
 		&i.SourceExampleID,
+		&i.GitCommitSHA,
+		&i.GitCommitAuthor,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 	)
@@ -9143,7 +9737,7 @@ func (q *sqlQuerier) GetPreviousTemplateVersion(ctx context.Context, arg GetPrev
 
 const getTemplateVersionByID = `-- name: GetTemplateVersionByID :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, created_by_avatar_url, created_by_username
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, git_commit_sha, git_commit_author, created_by_avatar_url, created_by_username
 FROM
 	template_version_with_user AS template_versions
 WHERE
@@ -9167,6 +9761,8 @@ func (q *sqlQuerier) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (
 		&i.Message,
 		&i.Archived,
 		&i.SourceExampleID,
+		&i.GitCommitSHA,
+		&i.GitCommitAuthor,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 	)
@@ -9175,7 +9771,7 @@ func (q *sqlQuerier) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (
 
 const getTemplateVersionByJobID = `-- name: GetTemplateVersionByJobID :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, created_by_avatar_url, created_by_username
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, git_commit_sha, git_commit_author, created_by_avatar_url, created_by_username
 FROM
 	template_version_with_user AS template_versions
 WHERE
@@ -9199,6 +9795,8 @@ func (q *sqlQuerier) GetTemplateVersionByJobID(ctx context.Context, jobID uuid.U
 		&i.Message,
 		&i.Archived,
 		&i.SourceExampleID,
+		&i.GitCommitSHA,
+		&i.GitCommitAuthor,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 	)
@@ -9207,7 +9805,7 @@ func (q *sqlQuerier) GetTemplateVersionByJobID(ctx context.Context, jobID uuid.U
 
 const getTemplateVersionByTemplateIDAndName = `-- name: GetTemplateVersionByTemplateIDAndName :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, created_by_avatar_url, created_by_username
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, git_commit_sha, git_commit_author, created_by_avatar_url, created_by_username
 FROM
 	template_version_with_user AS template_versions
 WHERE
@@ -9237,6 +9835,8 @@ func (q *sqlQuerier) GetTemplateVersionByTemplateIDAndName(ctx context.Context,
 		&i.Message,
 		&i.Archived,
 		&i.SourceExampleID,
+		&i.GitCommitSHA,
+		&i.GitCommitAuthor,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 	)
@@ -9245,7 +9845,7 @@ func (q *sqlQuerier) GetTemplateVersionByTemplateIDAndName(ctx context.Context,
 
 const getTemplateVersionsByIDs = `-- name: GetTemplateVersionsByIDs :many
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, created_by_avatar_url, created_by_username
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, git_commit_sha, git_commit_author, created_by_avatar_url, created_by_username
 FROM
 	template_version_with_user AS template_versions
 WHERE
@@ -9275,6 +9875,8 @@ func (q *sqlQuerier) GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UU
 			&i.Message,
 			&i.Archived,
 			&i.SourceExampleID,
+			&i.GitCommitSHA,
+			&i.GitCommitAuthor,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -9293,7 +9895,7 @@ func (q *sqlQuerier) GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UU
 
 const getTemplateVersionsByTemplateID = `-- name: GetTemplateVersionsByTemplateID :many
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, created_by_avatar_url, created_by_username
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, git_commit_sha, git_commit_author, created_by_avatar_url, created_by_username
 FROM
 	template_version_with_user AS template_versions
 WHERE
@@ -9370,6 +9972,8 @@ func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg Ge
 			&i.Message,
 			&i.Archived,
 			&i.SourceExampleID,
+			&i.GitCommitSHA,
+			&i.GitCommitAuthor,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -9387,7 +9991,7 @@ func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg Ge
 }
 
 const getTemplateVersionsCreatedAfter = `-- name: GetTemplateVersionsCreatedAfter :many
-SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, created_by_avatar_url, created_by_username FROM template_version_with_user AS template_versions WHERE created_at > $1
+SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, external_auth_providers, message, archived, source_example_id, git_commit_sha, git_commit_author, created_by_avatar_url, created_by_username FROM template_version_with_user AS template_versions WHERE created_at > $1
 `
 
 func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error) {
@@ -9413,6 +10017,8 @@ func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, create
 			&i.Message,
 			&i.Archived,
 			&i.SourceExampleID,
+			&i.GitCommitSHA,
+			&i.GitCommitAuthor,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -9442,10 +10048,12 @@ INSERT INTO
 		readme,
 		job_id,
 		created_by,
-		source_example_id
+		source_example_id,
+		git_commit_sha,
+		git_commit_author
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 `
 
 type InsertTemplateVersionParams struct {
@@ -9460,6 +10068,8 @@ type InsertTemplateVersionParams struct {
 	JobID           uuid.UUID      `db:"job_id" json:"job_id"`
 	CreatedBy       uuid.UUID      `db:"created_by" json:"created_by"`
 	SourceExampleID sql.NullString `db:"source_example_id" json:"source_example_id"`
+	GitCommitSHA    string         `db:"git_commit_sha" json:"git_commit_sha"`
+	GitCommitAuthor string         `db:"git_commit_author" json:"git_commit_author"`
 }
 
 func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) error {
@@ -9475,6 +10085,8 @@ func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTempla
 		arg.JobID,
 		arg.CreatedBy,
 		arg.SourceExampleID,
+		arg.GitCommitSHA,
+		arg.GitCommitAuthor,
 	)
 	return err
 }
@@ -10992,8 +11604,183 @@ func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusP
 	return i, err
 }
 
-const deleteWorkspaceAgentPortShare = `-- name: DeleteWorkspaceAgentPortShare :exec
-DELETE FROM
+const getWorkspaceAgentDevcontainersByAgentID = `-- name: GetWorkspaceAgentDevcontainersByAgentID :many
+SELECT workspace_agent_id, name, workspace_folder, config_path, state, container_id, image, error, state_changed_at, updated_at FROM workspace_agent_devcontainers WHERE workspace_agent_id = $1 ORDER BY name ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceAgentDevcontainersByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]WorkspaceAgentDevcontainer, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentDevcontainersByAgentID, workspaceAgentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentDevcontainer
+	for rows.Next() {
+		var i WorkspaceAgentDevcontainer
+		if err := rows.Scan(
+			&i.WorkspaceAgentID,
+			&i.Name,
+			&i.WorkspaceFolder,
+			&i.ConfigPath,
+			&i.State,
+			&i.ContainerID,
+			&i.Image,
+			&i.Error,
+			&i.StateChangedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertWorkspaceAgentDevcontainer = `-- name: UpsertWorkspaceAgentDevcontainer :exec
+INSERT INTO
+	workspace_agent_devcontainers (
+		workspace_agent_id,
+		name,
+		workspace_folder,
+		config_path,
+		state,
+		container_id,
+		image,
+		error,
+		state_changed_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT
+	(workspace_agent_id, name)
+DO UPDATE
+SET
+	workspace_folder = EXCLUDED.workspace_folder,
+	config_path = EXCLUDED.config_path,
+	state = EXCLUDED.state,
+	container_id = EXCLUDED.container_id,
+	image = EXCLUDED.image,
+	error = EXCLUDED.error,
+	state_changed_at = EXCLUDED.state_changed_at,
+	updated_at = EXCLUDED.updated_at
+`
+
+type UpsertWorkspaceAgentDevcontainerParams struct {
+	WorkspaceAgentID uuid.UUID                       `db:"workspace_agent_id" json:"workspace_agent_id"`
+	Name             string                          `db:"name" json:"name"`
+	WorkspaceFolder  string                          `db:"workspace_folder" json:"workspace_folder"`
+	ConfigPath       string                          `db:"config_path" json:"config_path"`
+	State            WorkspaceAgentDevcontainerState `db:"state" json:"state"`
+	ContainerID      string                          `db:"container_id" json:"container_id"`
+	Image            string                          `db:"image" json:"image"`
+	Error            string                          `db:"error" json:"error"`
+	StateChangedAt   sql.NullTime                    `db:"state_changed_at" json:"state_changed_at"`
+	UpdatedAt        time.Time                       `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertWorkspaceAgentDevcontainer(ctx context.Context, arg UpsertWorkspaceAgentDevcontainerParams) error {
+	_, err := q.db.ExecContext(ctx, upsertWorkspaceAgentDevcontainer,
+		arg.WorkspaceAgentID,
+		arg.Name,
+		arg.WorkspaceFolder,
+		arg.ConfigPath,
+		arg.State,
+		arg.ContainerID,
+		arg.Image,
+		arg.Error,
+		arg.StateChangedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const getWorkspaceAgentDNSRecordsByAgentIDs = `-- name: GetWorkspaceAgentDNSRecordsByAgentIDs :many
+SELECT workspace_agent_id, name, created_at, port FROM workspace_agent_dns_records WHERE workspace_agent_id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetWorkspaceAgentDNSRecordsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgentDNSRecord, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentDNSRecordsByAgentIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentDNSRecord
+	for rows.Next() {
+		var i WorkspaceAgentDNSRecord
+		if err := rows.Scan(
+			&i.WorkspaceAgentID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.Port,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceAgentDNSRecords = `-- name: InsertWorkspaceAgentDNSRecords :many
+INSERT INTO
+	workspace_agent_dns_records (workspace_agent_id, created_at, name, port)
+SELECT
+	$1 :: uuid AS workspace_agent_id,
+	$2 :: timestamptz AS created_at,
+	unnest($3 :: text [ ]) AS name,
+	unnest($4 :: integer [ ]) AS port
+RETURNING workspace_agent_dns_records.workspace_agent_id, workspace_agent_dns_records.name, workspace_agent_dns_records.created_at, workspace_agent_dns_records.port
+`
+
+type InsertWorkspaceAgentDNSRecordsParams struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	Name             []string  `db:"name" json:"name"`
+	Port             []int32   `db:"port" json:"port"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAgentDNSRecords(ctx context.Context, arg InsertWorkspaceAgentDNSRecordsParams) ([]WorkspaceAgentDNSRecord, error) {
+	rows, err := q.db.QueryContext(ctx, insertWorkspaceAgentDNSRecords, arg.WorkspaceAgentID, arg.CreatedAt, pq.Array(arg.Name), pq.Array(arg.Port))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentDNSRecord
+	for rows.Next() {
+		var i WorkspaceAgentDNSRecord
+		if err := rows.Scan(
+			&i.WorkspaceAgentID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.Port,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteWorkspaceAgentPortShare = `-- name: DeleteWorkspaceAgentPortShare :exec
+DELETE FROM
 	workspace_agent_port_share
 WHERE
 	workspace_id = $1
@@ -11493,6 +12280,82 @@ func (q *sqlQuerier) GetWorkspaceAgentLogSourcesByAgentIDs(ctx context.Context,
 	return items, nil
 }
 
+const getWorkspaceAgentLogs = `-- name: GetWorkspaceAgentLogs :many
+SELECT
+	agent_id, created_at, output, id, level, log_source_id
+FROM
+	workspace_agent_logs
+WHERE
+	agent_id = $1
+	AND id > $2
+	AND CASE
+		WHEN $3 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			created_at > $3
+		ELSE true
+	END
+	AND CASE
+		WHEN COALESCE(array_length($4 :: uuid[], 1), 0) > 0 THEN
+			log_source_id = ANY($4 :: uuid[])
+		ELSE true
+	END
+	AND CASE
+		WHEN COALESCE(array_length($5 :: log_level[], 1), 0) > 0 THEN
+			level = ANY($5 :: log_level[])
+		ELSE true
+	END
+ORDER BY id ASC
+LIMIT
+	NULLIF($6 :: int, 0)
+`
+
+type GetWorkspaceAgentLogsParams struct {
+	AgentID      uuid.UUID   `db:"agent_id" json:"agent_id"`
+	AfterID      int64       `db:"after_id" json:"after_id"`
+	CreatedAfter time.Time   `db:"created_after" json:"created_after"`
+	LogSourceIDs []uuid.UUID `db:"log_source_ids" json:"log_source_ids"`
+	Levels       []LogLevel  `db:"levels" json:"levels"`
+	LimitOpt     int32       `db:"limit_opt" json:"limit_opt"`
+}
+
+// Returns the logs of an agent after the given log ID, optionally filtered by
+// creation time, log source and level. A limit of zero returns every log.
+func (q *sqlQuerier) GetWorkspaceAgentLogs(ctx context.Context, arg GetWorkspaceAgentLogsParams) ([]WorkspaceAgentLog, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentLogs,
+		arg.AgentID,
+		arg.AfterID,
+		arg.CreatedAfter,
+		pq.Array(arg.LogSourceIDs),
+		pq.Array(arg.Levels),
+		arg.LimitOpt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentLog
+	for rows.Next() {
+		var i WorkspaceAgentLog
+		if err := rows.Scan(
+			&i.AgentID,
+			&i.CreatedAt,
+			&i.Output,
+			&i.ID,
+			&i.Level,
+			&i.LogSourceID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspaceAgentLogsAfter = `-- name: GetWorkspaceAgentLogsAfter :many
 SELECT
 	agent_id, created_at, output, id, level, log_source_id
@@ -12304,6 +13167,171 @@ func (q *sqlQuerier) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg Up
 	return err
 }
 
+const getWorkspaceAgentServicesByAgentIDs = `-- name: GetWorkspaceAgentServicesByAgentIDs :many
+SELECT id, workspace_agent_id, created_at, name, display_name, command, directory, env, restart_policy, restart_backoff_seconds, max_restart_backoff_seconds, healthcheck_url, healthcheck_interval, healthcheck_threshold, state, restart_count, exit_code, status_message, state_changed_at FROM workspace_agent_services WHERE workspace_agent_id = ANY($1 :: uuid [ ]) ORDER BY name ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceAgentServicesByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgentService, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentServicesByAgentIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentService
+	for rows.Next() {
+		var i WorkspaceAgentService
+		if err := rows.Scan(
+			&i.ID,
+			&i.WorkspaceAgentID,
+			&i.CreatedAt,
+			&i.Name,
+			&i.DisplayName,
+			&i.Command,
+			&i.Directory,
+			&i.Env,
+			&i.RestartPolicy,
+			&i.RestartBackoffSeconds,
+			&i.MaxRestartBackoffSeconds,
+			&i.HealthcheckUrl,
+			&i.HealthcheckInterval,
+			&i.HealthcheckThreshold,
+			&i.State,
+			&i.RestartCount,
+			&i.ExitCode,
+			&i.StatusMessage,
+			&i.StateChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceAgentService = `-- name: InsertWorkspaceAgentService :one
+INSERT INTO
+	workspace_agent_services (
+		id,
+		workspace_agent_id,
+		created_at,
+		name,
+		display_name,
+		command,
+		directory,
+		env,
+		restart_policy,
+		restart_backoff_seconds,
+		max_restart_backoff_seconds,
+		healthcheck_url,
+		healthcheck_interval,
+		healthcheck_threshold
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, workspace_agent_id, created_at, name, display_name, command, directory, env, restart_policy, restart_backoff_seconds, max_restart_backoff_seconds, healthcheck_url, healthcheck_interval, healthcheck_threshold, state, restart_count, exit_code, status_message, state_changed_at
+`
+
+type InsertWorkspaceAgentServiceParams struct {
+	ID                       uuid.UUID                          `db:"id" json:"id"`
+	WorkspaceAgentID         uuid.UUID                          `db:"workspace_agent_id" json:"workspace_agent_id"`
+	CreatedAt                time.Time                          `db:"created_at" json:"created_at"`
+	Name                     string                             `db:"name" json:"name"`
+	DisplayName              string                             `db:"display_name" json:"display_name"`
+	Command                  string                             `db:"command" json:"command"`
+	Directory                string                             `db:"directory" json:"directory"`
+	Env                      json.RawMessage                    `db:"env" json:"env"`
+	RestartPolicy            WorkspaceAgentServiceRestartPolicy `db:"restart_policy" json:"restart_policy"`
+	RestartBackoffSeconds    int32                              `db:"restart_backoff_seconds" json:"restart_backoff_seconds"`
+	MaxRestartBackoffSeconds int32                              `db:"max_restart_backoff_seconds" json:"max_restart_backoff_seconds"`
+	HealthcheckUrl           string                             `db:"healthcheck_url" json:"healthcheck_url"`
+	HealthcheckInterval      int32                              `db:"healthcheck_interval" json:"healthcheck_interval"`
+	HealthcheckThreshold     int32                              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAgentService(ctx context.Context, arg InsertWorkspaceAgentServiceParams) (WorkspaceAgentService, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceAgentService,
+		arg.ID,
+		arg.WorkspaceAgentID,
+		arg.CreatedAt,
+		arg.Name,
+		arg.DisplayName,
+		arg.Command,
+		arg.Directory,
+		arg.Env,
+		arg.RestartPolicy,
+		arg.RestartBackoffSeconds,
+		arg.MaxRestartBackoffSeconds,
+		arg.HealthcheckUrl,
+		arg.HealthcheckInterval,
+		arg.HealthcheckThreshold,
+	)
+	var i WorkspaceAgentService
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceAgentID,
+		&i.CreatedAt,
+		&i.Name,
+		&i.DisplayName,
+		&i.Command,
+		&i.Directory,
+		&i.Env,
+		&i.RestartPolicy,
+		&i.RestartBackoffSeconds,
+		&i.MaxRestartBackoffSeconds,
+		&i.HealthcheckUrl,
+		&i.HealthcheckInterval,
+		&i.HealthcheckThreshold,
+		&i.State,
+		&i.RestartCount,
+		&i.ExitCode,
+		&i.StatusMessage,
+		&i.StateChangedAt,
+	)
+	return i, err
+}
+
+const updateWorkspaceAgentServiceStatusByID = `-- name: UpdateWorkspaceAgentServiceStatusByID :exec
+UPDATE
+	workspace_agent_services
+SET
+	state = $3,
+	restart_count = $4,
+	exit_code = $5,
+	status_message = $6,
+	state_changed_at = $7
+WHERE
+	id = $1
+	AND workspace_agent_id = $2
+`
+
+type UpdateWorkspaceAgentServiceStatusByIDParams struct {
+	ID               uuid.UUID                  `db:"id" json:"id"`
+	WorkspaceAgentID uuid.UUID                  `db:"workspace_agent_id" json:"workspace_agent_id"`
+	State            WorkspaceAgentServiceState `db:"state" json:"state"`
+	RestartCount     int32                      `db:"restart_count" json:"restart_count"`
+	ExitCode         int32                      `db:"exit_code" json:"exit_code"`
+	StatusMessage    string                     `db:"status_message" json:"status_message"`
+	StateChangedAt   sql.NullTime               `db:"state_changed_at" json:"state_changed_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceAgentServiceStatusByID(ctx context.Context, arg UpdateWorkspaceAgentServiceStatusByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentServiceStatusByID,
+		arg.ID,
+		arg.WorkspaceAgentID,
+		arg.State,
+		arg.RestartCount,
+		arg.ExitCode,
+		arg.StatusMessage,
+		arg.StateChangedAt,
+	)
+	return err
+}
+
 const deleteOldWorkspaceAgentStats = `-- name: DeleteOldWorkspaceAgentStats :exec
 DELETE FROM
 	workspace_agent_stats
@@ -13070,7 +14098,7 @@ func (q *sqlQuerier) InsertWorkspaceAgentStats(ctx context.Context, arg InsertWo
 }
 
 const getWorkspaceAppByAgentIDAndSlug = `-- name: GetWorkspaceAppByAgentIDAndSlug :one
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden FROM workspace_apps WHERE agent_id = $1 AND slug = $2
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden, healthcheck_tcp, healthcheck_command, healthcheck_expected_statuses, healthcheck_body_regex, healthcheck_headers, health_reason FROM workspace_apps WHERE agent_id = $1 AND slug = $2
 `
 
 type GetWorkspaceAppByAgentIDAndSlugParams struct {
@@ -13099,12 +14127,18 @@ func (q *sqlQuerier) GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg Ge
 		&i.External,
 		&i.DisplayOrder,
 		&i.Hidden,
+		&i.HealthcheckTcp,
+		&i.HealthcheckCommand,
+		pq.Array(&i.HealthcheckExpectedStatuses),
+		&i.HealthcheckBodyRegex,
+		&i.HealthcheckHeaders,
+		&i.HealthReason,
 	)
 	return i, err
 }
 
 const getWorkspaceAppsByAgentID = `-- name: GetWorkspaceAppsByAgentID :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden FROM workspace_apps WHERE agent_id = $1 ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden, healthcheck_tcp, healthcheck_command, healthcheck_expected_statuses, healthcheck_body_regex, healthcheck_headers, health_reason FROM workspace_apps WHERE agent_id = $1 ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error) {
@@ -13134,6 +14168,12 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid
 			&i.External,
 			&i.DisplayOrder,
 			&i.Hidden,
+			&i.HealthcheckTcp,
+			&i.HealthcheckCommand,
+			pq.Array(&i.HealthcheckExpectedStatuses),
+			&i.HealthcheckBodyRegex,
+			&i.HealthcheckHeaders,
+			&i.HealthReason,
 		); err != nil {
 			return nil, err
 		}
@@ -13149,7 +14189,7 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid
 }
 
 const getWorkspaceAppsByAgentIDs = `-- name: GetWorkspaceAppsByAgentIDs :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden, healthcheck_tcp, healthcheck_command, healthcheck_expected_statuses, healthcheck_body_regex, healthcheck_headers, health_reason FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error) {
@@ -13179,6 +14219,12 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.
 			&i.External,
 			&i.DisplayOrder,
 			&i.Hidden,
+			&i.HealthcheckTcp,
+			&i.HealthcheckCommand,
+			pq.Array(&i.HealthcheckExpectedStatuses),
+			&i.HealthcheckBodyRegex,
+			&i.HealthcheckHeaders,
+			&i.HealthReason,
 		); err != nil {
 			return nil, err
 		}
@@ -13194,7 +14240,7 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.
 }
 
 const getWorkspaceAppsCreatedAfter = `-- name: GetWorkspaceAppsCreatedAfter :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden FROM workspace_apps WHERE created_at > $1 ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden, healthcheck_tcp, healthcheck_command, healthcheck_expected_statuses, healthcheck_body_regex, healthcheck_headers, health_reason FROM workspace_apps WHERE created_at > $1 ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error) {
@@ -13224,6 +14270,12 @@ func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt
 			&i.External,
 			&i.DisplayOrder,
 			&i.Hidden,
+			&i.HealthcheckTcp,
+			&i.HealthcheckCommand,
+			pq.Array(&i.HealthcheckExpectedStatuses),
+			&i.HealthcheckBodyRegex,
+			&i.HealthcheckHeaders,
+			&i.HealthReason,
 		); err != nil {
 			return nil, err
 		}
@@ -13257,30 +14309,40 @@ INSERT INTO
         healthcheck_threshold,
         health,
         display_order,
-        hidden
+        hidden,
+        healthcheck_tcp,
+        healthcheck_command,
+        healthcheck_expected_statuses,
+        healthcheck_body_regex,
+        healthcheck_headers
     )
 VALUES
-    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22) RETURNING id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, display_order, hidden, healthcheck_tcp, healthcheck_command, healthcheck_expected_statuses, healthcheck_body_regex, healthcheck_headers, health_reason
 `
 
 type InsertWorkspaceAppParams struct {
-	ID                   uuid.UUID          `db:"id" json:"id"`
-	CreatedAt            time.Time          `db:"created_at" json:"created_at"`
-	AgentID              uuid.UUID          `db:"agent_id" json:"agent_id"`
-	Slug                 string             `db:"slug" json:"slug"`
-	DisplayName          string             `db:"display_name" json:"display_name"`
-	Icon                 string             `db:"icon" json:"icon"`
-	Command              sql.NullString     `db:"command" json:"command"`
-	Url                  sql.NullString     `db:"url" json:"url"`
-	External             bool               `db:"external" json:"external"`
-	Subdomain            bool               `db:"subdomain" json:"subdomain"`
-	SharingLevel         AppSharingLevel    `db:"sharing_level" json:"sharing_level"`
-	HealthcheckUrl       string             `db:"healthcheck_url" json:"healthcheck_url"`
-	HealthcheckInterval  int32              `db:"healthcheck_interval" json:"healthcheck_interval"`
-	HealthcheckThreshold int32              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
-	Health               WorkspaceAppHealth `db:"health" json:"health"`
-	DisplayOrder         int32              `db:"display_order" json:"display_order"`
-	Hidden               bool               `db:"hidden" json:"hidden"`
+	ID                          uuid.UUID          `db:"id" json:"id"`
+	CreatedAt                   time.Time          `db:"created_at" json:"created_at"`
+	AgentID                     uuid.UUID          `db:"agent_id" json:"agent_id"`
+	Slug                        string             `db:"slug" json:"slug"`
+	DisplayName                 string             `db:"display_name" json:"display_name"`
+	Icon                        string             `db:"icon" json:"icon"`
+	Command                     sql.NullString     `db:"command" json:"command"`
+	Url                         sql.NullString     `db:"url" json:"url"`
+	External                    bool               `db:"external" json:"external"`
+	Subdomain                   bool               `db:"subdomain" json:"subdomain"`
+	SharingLevel                AppSharingLevel    `db:"sharing_level" json:"sharing_level"`
+	HealthcheckUrl              string             `db:"healthcheck_url" json:"healthcheck_url"`
+	HealthcheckInterval         int32              `db:"healthcheck_interval" json:"healthcheck_interval"`
+	HealthcheckThreshold        int32              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
+	Health                      WorkspaceAppHealth `db:"health" json:"health"`
+	DisplayOrder                int32              `db:"display_order" json:"display_order"`
+	Hidden                      bool               `db:"hidden" json:"hidden"`
+	HealthcheckTcp              string             `db:"healthcheck_tcp" json:"healthcheck_tcp"`
+	HealthcheckCommand          string             `db:"healthcheck_command" json:"healthcheck_command"`
+	HealthcheckExpectedStatuses []int32            `db:"healthcheck_expected_statuses" json:"healthcheck_expected_statuses"`
+	HealthcheckBodyRegex        string             `db:"healthcheck_body_regex" json:"healthcheck_body_regex"`
+	HealthcheckHeaders          json.RawMessage    `db:"healthcheck_headers" json:"healthcheck_headers"`
 }
 
 func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error) {
@@ -13302,6 +14364,11 @@ func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspace
 		arg.Health,
 		arg.DisplayOrder,
 		arg.Hidden,
+		arg.HealthcheckTcp,
+		arg.HealthcheckCommand,
+		pq.Array(arg.HealthcheckExpectedStatuses),
+		arg.HealthcheckBodyRegex,
+		arg.HealthcheckHeaders,
 	)
 	var i WorkspaceApp
 	err := row.Scan(
@@ -13322,6 +14389,12 @@ func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspace
 		&i.External,
 		&i.DisplayOrder,
 		&i.Hidden,
+		&i.HealthcheckTcp,
+		&i.HealthcheckCommand,
+		pq.Array(&i.HealthcheckExpectedStatuses),
+		&i.HealthcheckBodyRegex,
+		&i.HealthcheckHeaders,
+		&i.HealthReason,
 	)
 	return i, err
 }
@@ -13330,18 +14403,20 @@ const updateWorkspaceAppHealthByID = `-- name: UpdateWorkspaceAppHealthByID :exe
 UPDATE
 	workspace_apps
 SET
-	health = $2
+	health = $2,
+	health_reason = $3
 WHERE
 	id = $1
 `
 
 type UpdateWorkspaceAppHealthByIDParams struct {
-	ID     uuid.UUID          `db:"id" json:"id"`
-	Health WorkspaceAppHealth `db:"health" json:"health"`
+	ID           uuid.UUID          `db:"id" json:"id"`
+	Health       WorkspaceAppHealth `db:"health" json:"health"`
+	HealthReason string             `db:"health_reason" json:"health_reason"`
 }
 
 func (q *sqlQuerier) UpdateWorkspaceAppHealthByID(ctx context.Context, arg UpdateWorkspaceAppHealthByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceAppHealthByID, arg.ID, arg.Health)
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAppHealthByID, arg.ID, arg.Health, arg.HealthReason)
 	return err
 }
 
@@ -13525,6 +14600,37 @@ func (q *sqlQuerier) InsertWorkspaceBuildParameters(ctx context.Context, arg Ins
 	return err
 }
 
+const deleteOldWorkspaceBuildStates = `-- name: DeleteOldWorkspaceBuildStates :exec
+WITH
+	latest_builds AS (
+		SELECT
+			workspace_id, max(build_number) AS max_build_number
+		FROM
+			workspace_builds
+		GROUP BY
+			workspace_id
+	)
+UPDATE
+	workspace_builds
+SET
+	provisioner_state = NULL
+FROM
+	latest_builds
+WHERE
+	workspace_builds.workspace_id = latest_builds.workspace_id
+	AND workspace_builds.build_number < latest_builds.max_build_number
+	AND workspace_builds.created_at < $1 :: timestamptz
+	AND workspace_builds.provisioner_state IS NOT NULL
+`
+
+// Clears the provisioner state of builds created before the threshold, so that
+// the state history of workspaces is bounded. The state of the latest build of
+// each workspace is always kept, since the next build starts from it.
+func (q *sqlQuerier) DeleteOldWorkspaceBuildStates(ctx context.Context, threshold time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldWorkspaceBuildStates, threshold)
+	return err
+}
+
 const getActiveWorkspaceBuildsByTemplateID = `-- name: GetActiveWorkspaceBuildsByTemplateID :many
 SELECT wb.id, wb.created_at, wb.updated_at, wb.workspace_id, wb.template_version_id, wb.build_number, wb.transition, wb.initiator_id, wb.provisioner_state, wb.job_id, wb.deadline, wb.reason, wb.daily_cost, wb.max_deadline, wb.initiator_by_avatar_url, wb.initiator_by_username
 FROM (
@@ -14252,6 +15358,198 @@ func (q *sqlQuerier) UpdateWorkspaceBuildProvisionerStateByID(ctx context.Contex
 	return err
 }
 
+const getWorkspaceBuildsForDriftCheck = `-- name: GetWorkspaceBuildsForDriftCheck :many
+SELECT
+	workspace_builds.id AS workspace_build_id,
+	workspace_builds.workspace_id,
+	workspaces.owner_id,
+	provisioner_jobs.organization_id,
+	provisioner_jobs.provisioner,
+	provisioner_jobs.storage_method,
+	provisioner_jobs.file_id,
+	provisioner_jobs.tags
+FROM
+	workspace_builds
+INNER JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+INNER JOIN
+	provisioner_jobs ON provisioner_jobs.id = workspace_builds.job_id
+LEFT JOIN
+	workspace_drift ON workspace_drift.workspace_build_id = workspace_builds.id
+LEFT JOIN
+	provisioner_jobs drift_jobs ON drift_jobs.id = workspace_drift.job_id
+WHERE
+	workspace_builds.build_number = (
+		SELECT
+			MAX(build_number)
+		FROM
+			workspace_builds latest_builds
+		WHERE
+			latest_builds.workspace_id = workspace_builds.workspace_id
+	)
+	AND workspaces.deleted = false
+	AND workspaces.dormant_at IS NULL
+	AND workspace_builds.transition = 'start'::workspace_transition
+	AND provisioner_jobs.job_status = 'succeeded'::provisioner_job_status
+	AND (
+		workspace_drift.workspace_build_id IS NULL
+		OR (
+			workspace_drift.created_at < $1 :: timestamptz
+			-- Never start a check while the previous one is still running.
+			AND drift_jobs.completed_at IS NOT NULL
+		)
+	)
+`
+
+type GetWorkspaceBuildsForDriftCheckRow struct {
+	WorkspaceBuildID uuid.UUID                `db:"workspace_build_id" json:"workspace_build_id"`
+	WorkspaceID      uuid.UUID                `db:"workspace_id" json:"workspace_id"`
+	OwnerID          uuid.UUID                `db:"owner_id" json:"owner_id"`
+	OrganizationID   uuid.UUID                `db:"organization_id" json:"organization_id"`
+	Provisioner      ProvisionerType          `db:"provisioner" json:"provisioner"`
+	StorageMethod    ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
+	FileID           uuid.UUID                `db:"file_id" json:"file_id"`
+	Tags             StringMap                `db:"tags" json:"tags"`
+}
+
+// Returns the latest builds of running workspaces that haven't been checked for
+// drift since checked_before, along with the job that built them.
+func (q *sqlQuerier) GetWorkspaceBuildsForDriftCheck(ctx context.Context, checkedBefore time.Time) ([]GetWorkspaceBuildsForDriftCheckRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceBuildsForDriftCheck, checkedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceBuildsForDriftCheckRow
+	for rows.Next() {
+		var i GetWorkspaceBuildsForDriftCheckRow
+		if err := rows.Scan(
+			&i.WorkspaceBuildID,
+			&i.WorkspaceID,
+			&i.OwnerID,
+			&i.OrganizationID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.FileID,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceDriftByBuildID = `-- name: GetWorkspaceDriftByBuildID :one
+SELECT workspace_build_id, job_id, created_at, checked_at, resources FROM workspace_drift WHERE workspace_build_id = $1
+`
+
+func (q *sqlQuerier) GetWorkspaceDriftByBuildID(ctx context.Context, workspaceBuildID uuid.UUID) (WorkspaceDrift, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceDriftByBuildID, workspaceBuildID)
+	var i WorkspaceDrift
+	err := row.Scan(
+		&i.WorkspaceBuildID,
+		&i.JobID,
+		&i.CreatedAt,
+		&i.CheckedAt,
+		&i.Resources,
+	)
+	return i, err
+}
+
+const getWorkspaceDriftByBuildIDs = `-- name: GetWorkspaceDriftByBuildIDs :many
+SELECT workspace_build_id, job_id, created_at, checked_at, resources FROM workspace_drift WHERE workspace_build_id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetWorkspaceDriftByBuildIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceDrift, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceDriftByBuildIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceDrift
+	for rows.Next() {
+		var i WorkspaceDrift
+		if err := rows.Scan(
+			&i.WorkspaceBuildID,
+			&i.JobID,
+			&i.CreatedAt,
+			&i.CheckedAt,
+			&i.Resources,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateWorkspaceDriftByJobID = `-- name: UpdateWorkspaceDriftByJobID :one
+UPDATE
+	workspace_drift
+SET
+	checked_at = $1,
+	resources = $2
+WHERE
+	job_id = $3
+RETURNING workspace_build_id, job_id, created_at, checked_at, resources
+`
+
+type UpdateWorkspaceDriftByJobIDParams struct {
+	CheckedAt sql.NullTime    `db:"checked_at" json:"checked_at"`
+	Resources json.RawMessage `db:"resources" json:"resources"`
+	JobID     uuid.UUID       `db:"job_id" json:"job_id"`
+}
+
+// Returns no rows when a newer check of the build has started since.
+func (q *sqlQuerier) UpdateWorkspaceDriftByJobID(ctx context.Context, arg UpdateWorkspaceDriftByJobIDParams) (WorkspaceDrift, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceDriftByJobID, arg.CheckedAt, arg.Resources, arg.JobID)
+	var i WorkspaceDrift
+	err := row.Scan(
+		&i.WorkspaceBuildID,
+		&i.JobID,
+		&i.CreatedAt,
+		&i.CheckedAt,
+		&i.Resources,
+	)
+	return i, err
+}
+
+const upsertWorkspaceDriftCheck = `-- name: UpsertWorkspaceDriftCheck :exec
+INSERT INTO
+	workspace_drift (workspace_build_id, job_id, created_at)
+VALUES
+	($1, $2, $3)
+ON CONFLICT (workspace_build_id) DO UPDATE SET
+	job_id = $2,
+	created_at = $3
+`
+
+type UpsertWorkspaceDriftCheckParams struct {
+	WorkspaceBuildID uuid.UUID `db:"workspace_build_id" json:"workspace_build_id"`
+	JobID            uuid.UUID `db:"job_id" json:"job_id"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+}
+
+// The drift found by the previous check of the build is kept until the new
+// check completes.
+func (q *sqlQuerier) UpsertWorkspaceDriftCheck(ctx context.Context, arg UpsertWorkspaceDriftCheckParams) error {
+	_, err := q.db.ExecContext(ctx, upsertWorkspaceDriftCheck, arg.WorkspaceBuildID, arg.JobID, arg.CreatedAt)
+	return err
+}
+
 const getWorkspaceModulesByJobID = `-- name: GetWorkspaceModulesByJobID :many
 SELECT
 	id, job_id, transition, source, version, key, created_at
@@ -15134,7 +16432,7 @@ LEFT JOIN LATERAL (
 ) latest_build ON TRUE
 LEFT JOIN LATERAL (
 	SELECT
-		id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level
+		id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, job_timeout
 	FROM
 		templates
 	WHERE
@@ -16108,7 +17406,7 @@ func (q *sqlQuerier) UpdateWorkspacesDormantDeletingAtByTemplateID(ctx context.C
 }
 
 const getWorkspaceAgentScriptsByAgentIDs = `-- name: GetWorkspaceAgentScriptsByAgentIDs :many
-SELECT workspace_agent_id, log_source_id, log_path, created_at, script, cron, start_blocks_login, run_on_start, run_on_stop, timeout_seconds, display_name, id FROM workspace_agent_scripts WHERE workspace_agent_id = ANY($1 :: uuid [ ])
+SELECT workspace_agent_id, log_source_id, log_path, created_at, script, cron, start_blocks_login, run_on_start, run_on_stop, timeout_seconds, display_name, id, depends_on, ready_port, ready_file, ready_timeout_seconds FROM workspace_agent_scripts WHERE workspace_agent_id = ANY($1 :: uuid [ ])
 `
 
 func (q *sqlQuerier) GetWorkspaceAgentScriptsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgentScript, error) {
@@ -16133,6 +17431,10 @@ func (q *sqlQuerier) GetWorkspaceAgentScriptsByAgentIDs(ctx context.Context, ids
 			&i.TimeoutSeconds,
 			&i.DisplayName,
 			&i.ID,
+			pq.Array(&i.DependsOn),
+			&i.ReadyPort,
+			&i.ReadyFile,
+			&i.ReadyTimeoutSeconds,
 		); err != nil {
 			return nil, err
 		}
@@ -16149,7 +17451,7 @@ func (q *sqlQuerier) GetWorkspaceAgentScriptsByAgentIDs(ctx context.Context, ids
 
 const insertWorkspaceAgentScripts = `-- name: InsertWorkspaceAgentScripts :many
 INSERT INTO
-	workspace_agent_scripts (workspace_agent_id, created_at, log_source_id, log_path, script, cron, start_blocks_login, run_on_start, run_on_stop, timeout_seconds, display_name, id)
+	workspace_agent_scripts (workspace_agent_id, created_at, log_source_id, log_path, script, cron, start_blocks_login, run_on_start, run_on_stop, timeout_seconds, display_name, id, ready_port, ready_file, ready_timeout_seconds)
 SELECT
 	$1 :: uuid AS workspace_agent_id,
 	$2 :: timestamptz AS created_at,
@@ -16162,23 +17464,29 @@ SELECT
 	unnest($9 :: boolean [ ]) AS run_on_stop,
 	unnest($10 :: integer [ ]) AS timeout_seconds,
 	unnest($11 :: text [ ]) AS display_name,
-	unnest($12 :: uuid [ ]) AS id
-RETURNING workspace_agent_scripts.workspace_agent_id, workspace_agent_scripts.log_source_id, workspace_agent_scripts.log_path, workspace_agent_scripts.created_at, workspace_agent_scripts.script, workspace_agent_scripts.cron, workspace_agent_scripts.start_blocks_login, workspace_agent_scripts.run_on_start, workspace_agent_scripts.run_on_stop, workspace_agent_scripts.timeout_seconds, workspace_agent_scripts.display_name, workspace_agent_scripts.id
+	unnest($12 :: uuid [ ]) AS id,
+	unnest($13 :: integer [ ]) AS ready_port,
+	unnest($14 :: text [ ]) AS ready_file,
+	unnest($15 :: integer [ ]) AS ready_timeout_seconds
+RETURNING workspace_agent_scripts.workspace_agent_id, workspace_agent_scripts.log_source_id, workspace_agent_scripts.log_path, workspace_agent_scripts.created_at, workspace_agent_scripts.script, workspace_agent_scripts.cron, workspace_agent_scripts.start_blocks_login, workspace_agent_scripts.run_on_start, workspace_agent_scripts.run_on_stop, workspace_agent_scripts.timeout_seconds, workspace_agent_scripts.display_name, workspace_agent_scripts.id, workspace_agent_scripts.depends_on, workspace_agent_scripts.ready_port, workspace_agent_scripts.ready_file, workspace_agent_scripts.ready_timeout_seconds
 `
 
 type InsertWorkspaceAgentScriptsParams struct {
-	WorkspaceAgentID uuid.UUID   `db:"workspace_agent_id" json:"workspace_agent_id"`
-	CreatedAt        time.Time   `db:"created_at" json:"created_at"`
-	LogSourceID      []uuid.UUID `db:"log_source_id" json:"log_source_id"`
-	LogPath          []string    `db:"log_path" json:"log_path"`
-	Script           []string    `db:"script" json:"script"`
-	Cron             []string    `db:"cron" json:"cron"`
-	StartBlocksLogin []bool      `db:"start_blocks_login" json:"start_blocks_login"`
-	RunOnStart       []bool      `db:"run_on_start" json:"run_on_start"`
-	RunOnStop        []bool      `db:"run_on_stop" json:"run_on_stop"`
-	TimeoutSeconds   []int32     `db:"timeout_seconds" json:"timeout_seconds"`
-	DisplayName      []string    `db:"display_name" json:"display_name"`
-	ID               []uuid.UUID `db:"id" json:"id"`
+	WorkspaceAgentID    uuid.UUID   `db:"workspace_agent_id" json:"workspace_agent_id"`
+	CreatedAt           time.Time   `db:"created_at" json:"created_at"`
+	LogSourceID         []uuid.UUID `db:"log_source_id" json:"log_source_id"`
+	LogPath             []string    `db:"log_path" json:"log_path"`
+	Script              []string    `db:"script" json:"script"`
+	Cron                []string    `db:"cron" json:"cron"`
+	StartBlocksLogin    []bool      `db:"start_blocks_login" json:"start_blocks_login"`
+	RunOnStart          []bool      `db:"run_on_start" json:"run_on_start"`
+	RunOnStop           []bool      `db:"run_on_stop" json:"run_on_stop"`
+	TimeoutSeconds      []int32     `db:"timeout_seconds" json:"timeout_seconds"`
+	DisplayName         []string    `db:"display_name" json:"display_name"`
+	ID                  []uuid.UUID `db:"id" json:"id"`
+	ReadyPort           []int32     `db:"ready_port" json:"ready_port"`
+	ReadyFile           []string    `db:"ready_file" json:"ready_file"`
+	ReadyTimeoutSeconds []int32     `db:"ready_timeout_seconds" json:"ready_timeout_seconds"`
 }
 
 func (q *sqlQuerier) InsertWorkspaceAgentScripts(ctx context.Context, arg InsertWorkspaceAgentScriptsParams) ([]WorkspaceAgentScript, error) {
@@ -16195,6 +17503,9 @@ func (q *sqlQuerier) InsertWorkspaceAgentScripts(ctx context.Context, arg Insert
 		pq.Array(arg.TimeoutSeconds),
 		pq.Array(arg.DisplayName),
 		pq.Array(arg.ID),
+		pq.Array(arg.ReadyPort),
+		pq.Array(arg.ReadyFile),
+		pq.Array(arg.ReadyTimeoutSeconds),
 	)
 	if err != nil {
 		return nil, err
@@ -16216,6 +17527,10 @@ func (q *sqlQuerier) InsertWorkspaceAgentScripts(ctx context.Context, arg Insert
 			&i.TimeoutSeconds,
 			&i.DisplayName,
 			&i.ID,
+			pq.Array(&i.DependsOn),
+			&i.ReadyPort,
+			&i.ReadyFile,
+			&i.ReadyTimeoutSeconds,
 		); err != nil {
 			return nil, err
 		}
@@ -16229,3 +17544,22 @@ func (q *sqlQuerier) InsertWorkspaceAgentScripts(ctx context.Context, arg Insert
 	}
 	return items, nil
 }
+
+const updateWorkspaceAgentScriptDependsOnByID = `-- name: UpdateWorkspaceAgentScriptDependsOnByID :exec
+UPDATE
+	workspace_agent_scripts
+SET
+	depends_on = $1 :: uuid [ ]
+WHERE
+	id = $2
+`
+
+type UpdateWorkspaceAgentScriptDependsOnByIDParams struct {
+	DependsOn []uuid.UUID `db:"depends_on" json:"depends_on"`
+	ID        uuid.UUID   `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceAgentScriptDependsOnByID(ctx context.Context, arg UpdateWorkspaceAgentScriptDependsOnByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentScriptDependsOnByID, pq.Array(arg.DependsOn), arg.ID)
+	return err
+}
@@ -19,13 +19,41 @@ WHERE
 			provisioner_jobs AS potential_job
 		WHERE
 			potential_job.started_at IS NULL
-			AND potential_job.organization_id = @organization_id
+			AND potential_job.organization_id = ANY(@organization_ids :: uuid [ ])
 			-- Ensure the caller has the correct provisioner.
 			AND potential_job.provisioner = ANY(@types :: provisioner_type [ ])
 			-- elsewhere, we use the tagset type, but here we use jsonb for backward compatibility
 			-- they are aliases and the code that calls this query already relies on a different type
 			AND provisioner_tagset_contains(@provisioner_tags :: jsonb, potential_job.tags :: jsonb)
 		ORDER BY
+			potential_job.priority,
+			-- Fair-share between organizations: daemons that serve several
+			-- organizations prefer the jobs of organizations with the fewest
+			-- running jobs, so that one busy organization doesn't hold back the
+			-- others.
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running_job
+				WHERE
+					running_job.organization_id = potential_job.organization_id
+					AND running_job.started_at IS NOT NULL
+					AND running_job.completed_at IS NULL
+			),
+			-- Fair-share between users: prefer the jobs of initiators with the
+			-- fewest running jobs, so that one user queueing many jobs doesn't
+			-- hold back the jobs of everyone else.
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running_job
+				WHERE
+					running_job.initiator_id = potential_job.initiator_id
+					AND running_job.started_at IS NOT NULL
+					AND running_job.completed_at IS NULL
+			),
 			potential_job.created_at
 		FOR UPDATE
 		SKIP LOCKED
@@ -52,7 +80,7 @@ WHERE
 -- name: GetProvisionerJobsByIDsWithQueuePosition :many
 WITH unstarted_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, organization_id, priority
     FROM
         provisioner_jobs
     WHERE
@@ -61,23 +89,56 @@ WITH unstarted_jobs AS (
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        -- Most daemons only acquire the jobs of their own organization, so the
+        -- position counts the jobs of the same organization.
+        ROW_NUMBER() OVER (PARTITION BY organization_id ORDER BY priority ASC, created_at ASC) AS queue_position
     FROM
         unstarted_jobs
 ),
 queue_size AS (
-	SELECT COUNT(*) as count FROM unstarted_jobs
+	SELECT organization_id, COUNT(*) as count FROM unstarted_jobs GROUP BY organization_id
+),
+-- The wait in the queue is estimated from the duration of recently completed
+-- jobs and the number of connected daemons.
+job_durations AS (
+	SELECT
+		organization_id,
+		AVG(EXTRACT(EPOCH FROM completed_at - started_at) * 1000) AS average_duration_ms
+	FROM
+		provisioner_jobs
+	WHERE
+		completed_at > NOW() - INTERVAL '1 day'
+		AND started_at IS NOT NULL
+	GROUP BY
+		organization_id
+),
+connected_daemons AS (
+	SELECT
+		organization_id,
+		COUNT(*) AS count
+	FROM
+		provisioner_daemons
+	WHERE
+		-- Matches provisionerdserver.StaleInterval.
+		last_seen_at > NOW() - INTERVAL '90 seconds'
+	GROUP BY
+		organization_id
 )
 SELECT
 	sqlc.embed(pj),
     COALESCE(qp.queue_position, 0) AS queue_position,
-    COALESCE(qs.count, 0) AS queue_size
+    COALESCE(qs.count, 0) AS queue_size,
+    COALESCE(CEIL(qp.queue_position :: float / GREATEST(cd.count, 1)) * jd.average_duration_ms, 0) :: bigint AS queue_estimated_wait_ms
 FROM
 	provisioner_jobs pj
 LEFT JOIN
 	queue_position qp ON qp.id = pj.id
 LEFT JOIN
-	queue_size qs ON TRUE
+	queue_size qs ON qs.organization_id = pj.organization_id
+LEFT JOIN
+	job_durations jd ON jd.organization_id = pj.organization_id
+LEFT JOIN
+	connected_daemons cd ON cd.organization_id = pj.organization_id
 WHERE
 	pj.id = ANY(@ids :: uuid [ ]);
 
@@ -98,10 +159,11 @@ INSERT INTO
 		"type",
 		"input",
 		tags,
-		trace_metadata
+		trace_metadata,
+		priority
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING *;
 
 -- name: UpdateProvisionerJobByID :exec
 UPDATE
@@ -131,6 +193,16 @@ SET
 WHERE
 	id = $1;
 
+-- name: UpdateProvisionerJobEngineByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = @updated_at,
+	engine = @engine,
+	engine_version = @engine_version
+WHERE
+	id = @id;
+
 -- name: GetHungProvisionerJobs :many
 SELECT
 	*
@@ -41,6 +41,16 @@ SET
 WHERE
 	id = @id;
 
+-- name: UpdateWorkspaceProxyDraining :one
+UPDATE
+	workspace_proxies
+SET
+	updated_at = Now(),
+	draining = @draining
+WHERE
+	id = @id
+RETURNING *;
+
 -- name: UpdateWorkspaceProxy :one
 -- This allows editing the properties of a workspace proxy.
 UPDATE
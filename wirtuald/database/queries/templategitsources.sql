@@ -0,0 +1,131 @@
+-- name: GetTemplateGitSourceByTemplateID :one
+SELECT * FROM template_git_sources WHERE template_id = $1;
+
+-- name: UpsertTemplateGitSource :one
+-- The repository is synced again when the source changes. The commit that was
+-- synced last is kept unless the template moved to another repository, path or
+-- ref.
+INSERT INTO
+	template_git_sources (
+		template_id,
+		user_id,
+		created_at,
+		updated_at,
+		repo_url,
+		path,
+		ref,
+		external_auth_provider_id,
+		deploy_private_key,
+		deploy_private_key_key_id,
+		deploy_public_key,
+		known_hosts,
+		webhook_secret,
+		auto_promote,
+		sync_requested_at
+	)
+VALUES
+	(@template_id, @user_id, @updated_at, @updated_at, @repo_url, @path, @ref, @external_auth_provider_id, @deploy_private_key, @deploy_private_key_key_id, @deploy_public_key, @known_hosts, @webhook_secret, @auto_promote, @updated_at)
+ON CONFLICT (template_id) DO UPDATE SET
+	user_id = @user_id,
+	updated_at = @updated_at,
+	repo_url = @repo_url,
+	path = @path,
+	ref = @ref,
+	external_auth_provider_id = @external_auth_provider_id,
+	deploy_private_key = @deploy_private_key,
+	deploy_private_key_key_id = @deploy_private_key_key_id,
+	deploy_public_key = @deploy_public_key,
+	known_hosts = @known_hosts,
+	webhook_secret = @webhook_secret,
+	auto_promote = @auto_promote,
+	sync_requested_at = @updated_at,
+	last_commit_sha = CASE
+		WHEN (template_git_sources.repo_url, template_git_sources.path, template_git_sources.ref) = (@repo_url, @path, @ref) THEN template_git_sources.last_commit_sha
+		ELSE ''
+	END
+RETURNING *;
+
+-- name: DeleteTemplateGitSource :exec
+DELETE FROM template_git_sources WHERE template_id = $1;
+
+-- name: RequestTemplateGitSourceSync :exec
+UPDATE
+	template_git_sources
+SET
+	sync_requested_at = @requested_at :: timestamptz
+WHERE
+	template_id = @template_id;
+
+-- name: AcquireTemplateGitSources :many
+-- Claims the sources whose repository must be checked for new commits: those
+-- that were requested to sync, and those that haven't been checked since
+-- checked_before. Sources that are waiting for a job are skipped. Concurrent
+-- callers never claim the same source.
+UPDATE
+	template_git_sources
+SET
+	last_checked_at = @now :: timestamptz,
+	sync_requested_at = NULL
+WHERE
+	template_id IN (
+		SELECT
+			template_git_sources.template_id
+		FROM
+			template_git_sources
+		INNER JOIN
+			templates ON templates.id = template_git_sources.template_id
+		WHERE
+			templates.deleted = false
+			AND template_git_sources.job_id IS NULL
+			AND (
+				template_git_sources.sync_requested_at IS NOT NULL
+				OR template_git_sources.last_checked_at IS NULL
+				OR template_git_sources.last_checked_at < @checked_before :: timestamptz
+			)
+		ORDER BY
+			template_git_sources.sync_requested_at ASC NULLS LAST,
+			template_git_sources.last_checked_at ASC NULLS FIRST
+		LIMIT
+			@limit_opt :: int
+		FOR UPDATE OF template_git_sources SKIP LOCKED
+	)
+RETURNING *;
+
+-- name: GetTemplateGitSourcesWithCompletedJobs :many
+-- Returns the sources whose import or dry run job has completed, so the sync
+-- can move on.
+SELECT
+	template_git_sources.*
+FROM
+	template_git_sources
+INNER JOIN
+	provisioner_jobs ON provisioner_jobs.id = template_git_sources.job_id
+WHERE
+	provisioner_jobs.completed_at IS NOT NULL;
+
+-- name: GetTemplateGitSourcesWithDeployKeys :many
+-- Returns the sources that fetch their repository with a deploy key, so the
+-- keys can be encrypted again when the database encryption keys are rotated.
+SELECT * FROM template_git_sources WHERE deploy_private_key != '';
+
+-- name: UpdateTemplateGitSourceDeployKeyByTemplateID :exec
+UPDATE
+	template_git_sources
+SET
+	deploy_private_key = @deploy_private_key,
+	deploy_private_key_key_id = @deploy_private_key_key_id
+WHERE
+	template_id = @template_id;
+
+-- name: UpdateTemplateGitSourceSyncByTemplateID :exec
+UPDATE
+	template_git_sources
+SET
+	sync_status = @sync_status,
+	sync_error = @sync_error,
+	last_synced_at = @last_synced_at,
+	last_commit_sha = @last_commit_sha,
+	template_version_id = @template_version_id,
+	job_id = @job_id
+WHERE
+	template_id = @template_id;
@@ -88,10 +88,12 @@ INSERT INTO
 		readme,
 		job_id,
 		created_by,
-		source_example_id
+		source_example_id,
+		git_commit_sha,
+		git_commit_author
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
 
 -- name: UpdateTemplateVersionByID :exec
 UPDATE
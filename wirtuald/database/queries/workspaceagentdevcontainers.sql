@@ -0,0 +1,31 @@
+-- name: UpsertWorkspaceAgentDevcontainer :exec
+INSERT INTO
+	workspace_agent_devcontainers (
+		workspace_agent_id,
+		name,
+		workspace_folder,
+		config_path,
+		state,
+		container_id,
+		image,
+		error,
+		state_changed_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT
+	(workspace_agent_id, name)
+DO UPDATE
+SET
+	workspace_folder = EXCLUDED.workspace_folder,
+	config_path = EXCLUDED.config_path,
+	state = EXCLUDED.state,
+	container_id = EXCLUDED.container_id,
+	image = EXCLUDED.image,
+	error = EXCLUDED.error,
+	state_changed_at = EXCLUDED.state_changed_at,
+	updated_at = EXCLUDED.updated_at;
+
+-- name: GetWorkspaceAgentDevcontainersByAgentID :many
+SELECT * FROM workspace_agent_devcontainers WHERE workspace_agent_id = $1 ORDER BY name ASC;
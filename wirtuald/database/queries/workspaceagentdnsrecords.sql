@@ -0,0 +1,12 @@
+-- name: InsertWorkspaceAgentDNSRecords :many
+INSERT INTO
+	workspace_agent_dns_records (workspace_agent_id, created_at, name, port)
+SELECT
+	@workspace_agent_id :: uuid AS workspace_agent_id,
+	@created_at :: timestamptz AS created_at,
+	unnest(@name :: text [ ]) AS name,
+	unnest(@port :: integer [ ]) AS port
+RETURNING workspace_agent_dns_records.*;
+
+-- name: GetWorkspaceAgentDNSRecordsByAgentIDs :many
+SELECT * FROM workspace_agent_dns_records WHERE workspace_agent_id = ANY(@ids :: uuid [ ]);
@@ -158,6 +158,35 @@ WHERE
 		id > @created_after
 	) ORDER BY id ASC;
 
+-- name: GetWorkspaceAgentLogs :many
+-- Returns the logs of an agent after the given log ID, optionally filtered by
+-- creation time, log source and level. A limit of zero returns every log.
+SELECT
+	*
+FROM
+	workspace_agent_logs
+WHERE
+	agent_id = @agent_id
+	AND id > @after_id
+	AND CASE
+		WHEN @created_after :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			created_at > @created_after
+		ELSE true
+	END
+	AND CASE
+		WHEN COALESCE(array_length(@log_source_ids :: uuid[], 1), 0) > 0 THEN
+			log_source_id = ANY(@log_source_ids :: uuid[])
+		ELSE true
+	END
+	AND CASE
+		WHEN COALESCE(array_length(@levels :: log_level[], 1), 0) > 0 THEN
+			level = ANY(@levels :: log_level[])
+		ELSE true
+	END
+ORDER BY id ASC
+LIMIT
+	NULLIF(@limit_opt :: int, 0);
+
 -- name: InsertWorkspaceAgentLogs :many
 WITH new_length AS (
 	UPDATE workspace_agents SET
@@ -0,0 +1,36 @@
+-- name: InsertWorkspaceAgentService :one
+INSERT INTO
+	workspace_agent_services (
+		id,
+		workspace_agent_id,
+		created_at,
+		name,
+		display_name,
+		command,
+		directory,
+		env,
+		restart_policy,
+		restart_backoff_seconds,
+		max_restart_backoff_seconds,
+		healthcheck_url,
+		healthcheck_interval,
+		healthcheck_threshold
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING *;
+
+-- name: GetWorkspaceAgentServicesByAgentIDs :many
+SELECT * FROM workspace_agent_services WHERE workspace_agent_id = ANY(@ids :: uuid [ ]) ORDER BY name ASC;
+
+-- name: UpdateWorkspaceAgentServiceStatusByID :exec
+UPDATE
+	workspace_agent_services
+SET
+	state = $3,
+	restart_count = $4,
+	exit_code = $5,
+	status_message = $6,
+	state_changed_at = $7
+WHERE
+	id = $1
+	AND workspace_agent_id = $2;
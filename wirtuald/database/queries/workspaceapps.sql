@@ -29,15 +29,21 @@ INSERT INTO
         healthcheck_threshold,
         health,
         display_order,
-        hidden
+        hidden,
+        healthcheck_tcp,
+        healthcheck_command,
+        healthcheck_expected_statuses,
+        healthcheck_body_regex,
+        healthcheck_headers
     )
 VALUES
-    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING *;
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22) RETURNING *;
 
 -- name: UpdateWorkspaceAppHealthByID :exec
 UPDATE
 	workspace_apps
 SET
-	health = $2
+	health = $2,
+	health_reason = $3
 WHERE
 	id = $1;
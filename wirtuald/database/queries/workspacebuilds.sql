@@ -242,3 +242,28 @@ WHERE
 	AND pj.job_status = 'failed'
 ORDER BY
 	tv.name ASC, wb.build_number DESC;
+
+-- Clears the provisioner state of builds created before the threshold, so that
+-- the state history of workspaces is bounded. The state of the latest build of
+-- each workspace is always kept, since the next build starts from it.
+-- name: DeleteOldWorkspaceBuildStates :exec
+WITH
+	latest_builds AS (
+		SELECT
+			workspace_id, max(build_number) AS max_build_number
+		FROM
+			workspace_builds
+		GROUP BY
+			workspace_id
+	)
+UPDATE
+	workspace_builds
+SET
+	provisioner_state = NULL
+FROM
+	latest_builds
+WHERE
+	workspace_builds.workspace_id = latest_builds.workspace_id
+	AND workspace_builds.build_number < latest_builds.max_build_number
+	AND workspace_builds.created_at < @threshold :: timestamptz
+	AND workspace_builds.provisioner_state IS NOT NULL;
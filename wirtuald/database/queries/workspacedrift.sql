@@ -0,0 +1,71 @@
+-- name: UpsertWorkspaceDriftCheck :exec
+-- The drift found by the previous check of the build is kept until the new
+-- check completes.
+INSERT INTO
+	workspace_drift (workspace_build_id, job_id, created_at)
+VALUES
+	(@workspace_build_id, @job_id, @created_at)
+ON CONFLICT (workspace_build_id) DO UPDATE SET
+	job_id = @job_id,
+	created_at = @created_at;
+
+-- name: UpdateWorkspaceDriftByJobID :one
+-- Returns no rows when a newer check of the build has started since.
+UPDATE
+	workspace_drift
+SET
+	checked_at = @checked_at,
+	resources = @resources
+WHERE
+	job_id = @job_id
+RETURNING *;
+
+-- name: GetWorkspaceDriftByBuildID :one
+SELECT * FROM workspace_drift WHERE workspace_build_id = $1;
+
+-- name: GetWorkspaceDriftByBuildIDs :many
+SELECT * FROM workspace_drift WHERE workspace_build_id = ANY(@ids :: uuid [ ]);
+
+-- name: GetWorkspaceBuildsForDriftCheck :many
+-- Returns the latest builds of running workspaces that haven't been checked for
+-- drift since checked_before, along with the job that built them.
+SELECT
+	workspace_builds.id AS workspace_build_id,
+	workspace_builds.workspace_id,
+	workspaces.owner_id,
+	provisioner_jobs.organization_id,
+	provisioner_jobs.provisioner,
+	provisioner_jobs.storage_method,
+	provisioner_jobs.file_id,
+	provisioner_jobs.tags
+FROM
+	workspace_builds
+INNER JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+INNER JOIN
+	provisioner_jobs ON provisioner_jobs.id = workspace_builds.job_id
+LEFT JOIN
+	workspace_drift ON workspace_drift.workspace_build_id = workspace_builds.id
+LEFT JOIN
+	provisioner_jobs drift_jobs ON drift_jobs.id = workspace_drift.job_id
+WHERE
+	workspace_builds.build_number = (
+		SELECT
+			MAX(build_number)
+		FROM
+			workspace_builds latest_builds
+		WHERE
+			latest_builds.workspace_id = workspace_builds.workspace_id
+	)
+	AND workspaces.deleted = false
+	AND workspaces.dormant_at IS NULL
+	AND workspace_builds.transition = 'start'::workspace_transition
+	AND provisioner_jobs.job_status = 'succeeded'::provisioner_job_status
+	AND (
+		workspace_drift.workspace_build_id IS NULL
+		OR (
+			workspace_drift.created_at < @checked_before :: timestamptz
+			-- Never start a check while the previous one is still running.
+			AND drift_jobs.completed_at IS NOT NULL
+		)
+	);
@@ -1,6 +1,6 @@
 -- name: InsertWorkspaceAgentScripts :many
 INSERT INTO
-	workspace_agent_scripts (workspace_agent_id, created_at, log_source_id, log_path, script, cron, start_blocks_login, run_on_start, run_on_stop, timeout_seconds, display_name, id)
+	workspace_agent_scripts (workspace_agent_id, created_at, log_source_id, log_path, script, cron, start_blocks_login, run_on_start, run_on_stop, timeout_seconds, display_name, id, ready_port, ready_file, ready_timeout_seconds)
 SELECT
 	@workspace_agent_id :: uuid AS workspace_agent_id,
 	@created_at :: timestamptz AS created_at,
@@ -13,8 +13,19 @@ SELECT
 	unnest(@run_on_stop :: boolean [ ]) AS run_on_stop,
 	unnest(@timeout_seconds :: integer [ ]) AS timeout_seconds,
 	unnest(@display_name :: text [ ]) AS display_name,
-	unnest(@id :: uuid [ ]) AS id
+	unnest(@id :: uuid [ ]) AS id,
+	unnest(@ready_port :: integer [ ]) AS ready_port,
+	unnest(@ready_file :: text [ ]) AS ready_file,
+	unnest(@ready_timeout_seconds :: integer [ ]) AS ready_timeout_seconds
 RETURNING workspace_agent_scripts.*;
 
 -- name: GetWorkspaceAgentScriptsByAgentIDs :many
 SELECT * FROM workspace_agent_scripts WHERE workspace_agent_id = ANY(@ids :: uuid [ ]);
+
+-- name: UpdateWorkspaceAgentScriptDependsOnByID :exec
+UPDATE
+	workspace_agent_scripts
+SET
+	depends_on = @depends_on :: uuid [ ]
+WHERE
+	id = @id;
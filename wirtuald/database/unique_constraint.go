@@ -55,6 +55,7 @@ const (
 	UniqueTailnetCoordinatorsPkey                             UniqueConstraint = "tailnet_coordinators_pkey"                                   // ALTER TABLE ONLY tailnet_coordinators ADD CONSTRAINT tailnet_coordinators_pkey PRIMARY KEY (id);
 	UniqueTailnetPeersPkey                                    UniqueConstraint = "tailnet_peers_pkey"                                          // ALTER TABLE ONLY tailnet_peers ADD CONSTRAINT tailnet_peers_pkey PRIMARY KEY (id, coordinator_id);
 	UniqueTailnetTunnelsPkey                                  UniqueConstraint = "tailnet_tunnels_pkey"                                        // ALTER TABLE ONLY tailnet_tunnels ADD CONSTRAINT tailnet_tunnels_pkey PRIMARY KEY (coordinator_id, src_id, dst_id);
+	UniqueTemplateGitSourcesPkey                              UniqueConstraint = "template_git_sources_pkey"                                   // ALTER TABLE ONLY template_git_sources ADD CONSTRAINT template_git_sources_pkey PRIMARY KEY (template_id);
 	UniqueTemplateUsageStatsPkey                              UniqueConstraint = "template_usage_stats_pkey"                                   // ALTER TABLE ONLY template_usage_stats ADD CONSTRAINT template_usage_stats_pkey PRIMARY KEY (start_time, template_id, user_id);
 	UniqueTemplateVersionParametersTemplateVersionIDNameKey   UniqueConstraint = "template_version_parameters_template_version_id_name_key"    // ALTER TABLE ONLY template_version_parameters ADD CONSTRAINT template_version_parameters_template_version_id_name_key UNIQUE (template_version_id, name);
 	UniqueTemplateVersionVariablesTemplateVersionIDNameKey    UniqueConstraint = "template_version_variables_template_version_id_name_key"     // ALTER TABLE ONLY template_version_variables ADD CONSTRAINT template_version_variables_template_version_id_name_key UNIQUE (template_version_id, name);
@@ -64,11 +65,15 @@ const (
 	UniqueTemplatesPkey                                       UniqueConstraint = "templates_pkey"                                              // ALTER TABLE ONLY templates ADD CONSTRAINT templates_pkey PRIMARY KEY (id);
 	UniqueUserLinksPkey                                       UniqueConstraint = "user_links_pkey"                                             // ALTER TABLE ONLY user_links ADD CONSTRAINT user_links_pkey PRIMARY KEY (user_id, login_type);
 	UniqueUsersPkey                                           UniqueConstraint = "users_pkey"                                                  // ALTER TABLE ONLY users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+	UniqueWorkspaceAgentDevcontainersPkey                     UniqueConstraint = "workspace_agent_devcontainers_pkey"                          // ALTER TABLE ONLY workspace_agent_devcontainers ADD CONSTRAINT workspace_agent_devcontainers_pkey PRIMARY KEY (workspace_agent_id, name);
+	UniqueWorkspaceAgentDNSRecordsPkey                        UniqueConstraint = "workspace_agent_dns_records_pkey"                            // ALTER TABLE ONLY workspace_agent_dns_records ADD CONSTRAINT workspace_agent_dns_records_pkey PRIMARY KEY (workspace_agent_id, name);
 	UniqueWorkspaceAgentLogSourcesPkey                        UniqueConstraint = "workspace_agent_log_sources_pkey"                            // ALTER TABLE ONLY workspace_agent_log_sources ADD CONSTRAINT workspace_agent_log_sources_pkey PRIMARY KEY (workspace_agent_id, id);
 	UniqueWorkspaceAgentMetadataPkey                          UniqueConstraint = "workspace_agent_metadata_pkey"                               // ALTER TABLE ONLY workspace_agent_metadata ADD CONSTRAINT workspace_agent_metadata_pkey PRIMARY KEY (workspace_agent_id, key);
 	UniqueWorkspaceAgentPortSharePkey                         UniqueConstraint = "workspace_agent_port_share_pkey"                             // ALTER TABLE ONLY workspace_agent_port_share ADD CONSTRAINT workspace_agent_port_share_pkey PRIMARY KEY (workspace_id, agent_name, port);
 	UniqueWorkspaceAgentScriptTimingsScriptIDStartedAtKey     UniqueConstraint = "workspace_agent_script_timings_script_id_started_at_key"     // ALTER TABLE ONLY workspace_agent_script_timings ADD CONSTRAINT workspace_agent_script_timings_script_id_started_at_key UNIQUE (script_id, started_at);
 	UniqueWorkspaceAgentScriptsIDKey                          UniqueConstraint = "workspace_agent_scripts_id_key"                              // ALTER TABLE ONLY workspace_agent_scripts ADD CONSTRAINT workspace_agent_scripts_id_key UNIQUE (id);
+	UniqueWorkspaceAgentServicesPkey                          UniqueConstraint = "workspace_agent_services_pkey"                               // ALTER TABLE ONLY workspace_agent_services ADD CONSTRAINT workspace_agent_services_pkey PRIMARY KEY (id);
+	UniqueWorkspaceAgentServicesWorkspaceAgentIDNameKey       UniqueConstraint = "workspace_agent_services_workspace_agent_id_name_key"        // ALTER TABLE ONLY workspace_agent_services ADD CONSTRAINT workspace_agent_services_workspace_agent_id_name_key UNIQUE (workspace_agent_id, name);
 	UniqueWorkspaceAgentStartupLogsPkey                       UniqueConstraint = "workspace_agent_startup_logs_pkey"                           // ALTER TABLE ONLY workspace_agent_logs ADD CONSTRAINT workspace_agent_startup_logs_pkey PRIMARY KEY (id);
 	UniqueWorkspaceAgentsPkey                                 UniqueConstraint = "workspace_agents_pkey"                                       // ALTER TABLE ONLY workspace_agents ADD CONSTRAINT workspace_agents_pkey PRIMARY KEY (id);
 	UniqueWorkspaceAppStatsPkey                               UniqueConstraint = "workspace_app_stats_pkey"                                    // ALTER TABLE ONLY workspace_app_stats ADD CONSTRAINT workspace_app_stats_pkey PRIMARY KEY (id);
@@ -79,6 +84,7 @@ const (
 	UniqueWorkspaceBuildsJobIDKey                             UniqueConstraint = "workspace_builds_job_id_key"                                 // ALTER TABLE ONLY workspace_builds ADD CONSTRAINT workspace_builds_job_id_key UNIQUE (job_id);
 	UniqueWorkspaceBuildsPkey                                 UniqueConstraint = "workspace_builds_pkey"                                       // ALTER TABLE ONLY workspace_builds ADD CONSTRAINT workspace_builds_pkey PRIMARY KEY (id);
 	UniqueWorkspaceBuildsWorkspaceIDBuildNumberKey            UniqueConstraint = "workspace_builds_workspace_id_build_number_key"              // ALTER TABLE ONLY workspace_builds ADD CONSTRAINT workspace_builds_workspace_id_build_number_key UNIQUE (workspace_id, build_number);
+	UniqueWorkspaceDriftPkey                                  UniqueConstraint = "workspace_drift_pkey"                                        // ALTER TABLE ONLY workspace_drift ADD CONSTRAINT workspace_drift_pkey PRIMARY KEY (workspace_build_id);
 	UniqueWorkspaceProxiesPkey                                UniqueConstraint = "workspace_proxies_pkey"                                      // ALTER TABLE ONLY workspace_proxies ADD CONSTRAINT workspace_proxies_pkey PRIMARY KEY (id);
 	UniqueWorkspaceProxiesRegionIDUnique                      UniqueConstraint = "workspace_proxies_region_id_unique"                          // ALTER TABLE ONLY workspace_proxies ADD CONSTRAINT workspace_proxies_region_id_unique UNIQUE (region_id);
 	UniqueWorkspaceResourceMetadataName                       UniqueConstraint = "workspace_resource_metadata_name"                            // ALTER TABLE ONLY workspace_resource_metadata ADD CONSTRAINT workspace_resource_metadata_name UNIQUE (workspace_resource_id, key);
@@ -0,0 +1,210 @@
+// Package drift periodically checks running workspaces for resources that were
+// changed outside of their builds.
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/provisionerjobs"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/pubsub"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/provisionerdserver"
+)
+
+// MaxChecksPerRun is the maximum number of drift check jobs that the detector
+// will queue in a single run, so a large deployment doesn't flood its
+// provisioners.
+const MaxChecksPerRun = 50
+
+// Detector queues a refresh-only plan for the latest build of every running
+// workspace once per interval. The provisioner reports the resources that
+// drifted, which are stored with the build.
+type Detector struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	db       database.Store
+	pubsub   pubsub.Pubsub
+	log      slog.Logger
+	tick     <-chan time.Time
+	interval time.Duration
+	stats    chan<- Stats
+}
+
+// Stats contains statistics about the last run of the detector.
+type Stats struct {
+	// JobIDs contains the IDs of the drift check jobs that were queued.
+	JobIDs []uuid.UUID
+	// Error is the fatal error that occurred during the last run of the
+	// detector, if any.
+	Error error
+}
+
+// New returns a new drift detector. Builds are checked again once interval
+// has passed since their previous check.
+func New(ctx context.Context, db database.Store, pub pubsub.Pubsub, log slog.Logger, tick <-chan time.Time, interval time.Duration) *Detector {
+	//nolint:gocritic // The drift detector queues jobs on behalf of workspace owners.
+	ctx, cancel := context.WithCancel(dbauthz.AsSystemRestricted(ctx))
+	return &Detector{
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		db:       db,
+		pubsub:   pub,
+		log:      log,
+		tick:     tick,
+		interval: interval,
+		stats:    nil,
+	}
+}
+
+// WithStatsChannel will cause the detector to push Stats to ch after every
+// tick. This push is blocking, so if ch is not read, the detector will hang.
+// This should only be used in tests.
+func (d *Detector) WithStatsChannel(ch chan<- Stats) *Detector {
+	d.stats = ch
+	return d
+}
+
+// Start will cause the detector to queue drift checks on every tick from its
+// channel. It will stop when its context is Done, or when its channel is
+// closed.
+//
+// Start should only be called once.
+func (d *Detector) Start() {
+	go func() {
+		defer close(d.done)
+		defer d.cancel()
+
+		for {
+			select {
+			case <-d.ctx.Done():
+				return
+			case t, ok := <-d.tick:
+				if !ok {
+					return
+				}
+				stats := d.run(t)
+				if stats.Error != nil {
+					d.log.Warn(d.ctx, "error running workspace drift detector once", slog.Error(stats.Error))
+				}
+				if d.stats != nil {
+					select {
+					case <-d.ctx.Done():
+						return
+					case d.stats <- stats:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait will block until the detector is stopped.
+func (d *Detector) Wait() {
+	<-d.done
+}
+
+// Close will stop the detector.
+func (d *Detector) Close() {
+	d.cancel()
+	<-d.done
+}
+
+func (d *Detector) run(t time.Time) Stats {
+	ctx, cancel := context.WithTimeout(d.ctx, time.Minute)
+	defer cancel()
+
+	stats := Stats{
+		JobIDs: []uuid.UUID{},
+		Error:  nil,
+	}
+
+	var jobs []database.ProvisionerJob
+	err := d.db.InTx(func(db database.Store) error {
+		// Only one replica queues checks at a time, so builds aren't
+		// checked twice.
+		locked, err := db.TryAcquireLock(ctx, database.LockIDWorkspaceDriftCheck)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			d.log.Debug(ctx, "unable to acquire lock for drift check, skipping run")
+			return nil
+		}
+
+		builds, err := db.GetWorkspaceBuildsForDriftCheck(ctx, t.Add(-d.interval))
+		if err != nil {
+			return xerrors.Errorf("get workspace builds for drift check: %w", err)
+		}
+		if len(builds) > MaxChecksPerRun {
+			// The remaining builds are checked on the next run.
+			builds = builds[:MaxChecksPerRun]
+		}
+
+		now := dbtime.Now()
+		for _, build := range builds {
+			input, err := json.Marshal(provisionerdserver.WorkspaceDriftCheckJob{
+				WorkspaceBuildID: build.WorkspaceBuildID,
+			})
+			if err != nil {
+				return xerrors.Errorf("marshal job input: %w", err)
+			}
+			job, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+				ID:             uuid.New(),
+				CreatedAt:      now,
+				UpdatedAt:      now,
+				InitiatorID:    build.OwnerID,
+				OrganizationID: build.OrganizationID,
+				Provisioner:    build.Provisioner,
+				Type:           database.ProvisionerJobTypeWorkspaceDriftCheck,
+				StorageMethod:  build.StorageMethod,
+				FileID:         build.FileID,
+				Input:          input,
+				Tags:           build.Tags,
+				// Drift checks run in the background, so they must never
+				// delay the builds of users.
+				Priority: database.ProvisionerJobPriorityBulk,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert provisioner job: %w", err)
+			}
+			err = db.UpsertWorkspaceDriftCheck(ctx, database.UpsertWorkspaceDriftCheckParams{
+				WorkspaceBuildID: build.WorkspaceBuildID,
+				JobID:            job.ID,
+				CreatedAt:        now,
+			})
+			if err != nil {
+				return xerrors.Errorf("upsert workspace drift check: %w", err)
+			}
+			jobs = append(jobs, job)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+
+	for _, job := range jobs {
+		stats.JobIDs = append(stats.JobIDs, job.ID)
+		err := provisionerjobs.PostJob(d.pubsub, job)
+		if err != nil {
+			d.log.Error(ctx, "failed to post provisioner job to pubsub", slog.F("job_id", job.ID), slog.Error(err))
+		}
+	}
+	if len(jobs) > 0 {
+		d.log.Debug(ctx, "queued workspace drift checks", slog.F("count", len(jobs)))
+	}
+	return stats
+}
@@ -0,0 +1,112 @@
+package drift_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog"
+
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbfake"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbgen"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtestutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/drift"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/rbac"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = testutil.Context(t, testutil.WaitLong)
+		db, pubsub = dbtestutil.NewDB(t)
+		log        = testutil.Logger(t)
+		tickCh     = make(chan time.Time)
+		statsCh    = make(chan drift.Stats)
+		interval   = time.Hour
+	)
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	running := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+	}).Do()
+	// Stopped workspaces have no resources to check.
+	_ = dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+	}).Seed(database.WorkspaceBuild{
+		Transition: database.WorkspaceTransitionStop,
+	}).Do()
+
+	detector := drift.New(ctx, wrapDBAuthz(db, log), pubsub, log, tickCh, interval).WithStatsChannel(statsCh)
+	detector.Start()
+	defer detector.Close()
+
+	now := dbtime.Now()
+	tickCh <- now
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.JobIDs, 1)
+
+	job, err := db.GetProvisionerJobByID(ctx, stats.JobIDs[0])
+	require.NoError(t, err)
+	require.Equal(t, database.ProvisionerJobTypeWorkspaceDriftCheck, job.Type)
+	require.Equal(t, user.ID, job.InitiatorID)
+	check, err := db.GetWorkspaceDriftByBuildID(ctx, running.Build.ID)
+	require.NoError(t, err)
+	require.Equal(t, job.ID, check.JobID)
+
+	// The check is still running, so no other check is queued.
+	tickCh <- now.Add(2 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.JobIDs)
+
+	err = db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		ID:        job.ID,
+		UpdatedAt: dbtime.Now(),
+		CompletedAt: sql.NullTime{
+			Time:  dbtime.Now(),
+			Valid: true,
+		},
+	})
+	require.NoError(t, err)
+
+	// The build was checked recently.
+	tickCh <- now.Add(interval / 2)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.JobIDs)
+
+	tickCh <- now.Add(2 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.JobIDs, 1)
+	check, err = db.GetWorkspaceDriftByBuildID(ctx, running.Build.ID)
+	require.NoError(t, err)
+	require.Equal(t, stats.JobIDs[0], check.JobID)
+}
+
+func wrapDBAuthz(db database.Store, logger slog.Logger) database.Store {
+	return dbauthz.New(
+		db,
+		rbac.NewStrictCachingAuthorizer(prometheus.NewRegistry()),
+		logger,
+		wirtualdtest.AccessControlStorePointer(),
+	)
+}
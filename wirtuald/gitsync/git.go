@@ -0,0 +1,204 @@
+package gitsync
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+)
+
+// allowedProtocols are the protocols that git may use to fetch repositories.
+// Other protocols, like file:// URLs and local paths, would read repositories
+// on the server.
+const allowedProtocols = "https:ssh"
+
+// ValidateRepoURL returns an error unless rawURL is an https:// or ssh:// URL,
+// or an scp-like SSH address such as git@github.com:org/repo.git.
+func ValidateRepoURL(rawURL string) error {
+	if strings.HasPrefix(rawURL, "-") {
+		return xerrors.New("the URL must not start with a dash")
+	}
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return xerrors.Errorf("parse URL: %w", err)
+		}
+		if u.Scheme != "https" && u.Scheme != "ssh" {
+			return xerrors.Errorf("unsupported protocol %q, expected https or ssh", u.Scheme)
+		}
+		if u.Hostname() == "" {
+			return xerrors.New("the URL has no host")
+		}
+		return nil
+	}
+	// Git treats [user@]host:path as an SSH address, unless there is a slash
+	// before the first colon. transport::address runs a remote helper.
+	host, repoPath, ok := strings.Cut(rawURL, ":")
+	if _, after, found := strings.Cut(host, "@"); found {
+		host = after
+	}
+	if !ok || host == "" || strings.Contains(host, "/") || strings.HasPrefix(repoPath, ":") {
+		return xerrors.New("expected an https:// or ssh:// URL, or an SSH address like git@example.com:org/repo.git")
+	}
+	return nil
+}
+
+// ValidateKnownHosts returns an error unless knownHosts holds at least one
+// host key in the known_hosts format, like the output of ssh-keyscan.
+func ValidateKnownHosts(knownHosts string) error {
+	rest := []byte(knownHosts)
+	found := false
+	for {
+		_, _, _, _, next, err := ssh.ParseKnownHosts(rest)
+		if xerrors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return xerrors.Errorf("parse known hosts: %w", err)
+		}
+		found = true
+		rest = next
+	}
+	if !found {
+		return xerrors.New("no host keys found")
+	}
+	return nil
+}
+
+// repository is a git repository and the environment that authenticates the
+// git commands that read it.
+type repository struct {
+	url string
+	ref string
+	env []string
+}
+
+// commit is the commit that a template version is created from.
+type commit struct {
+	SHA     string
+	Author  string
+	Subject string
+}
+
+// basicAuthEnv configures git to send the credentials with every HTTP request.
+// The header is passed through the environment so it isn't visible in the
+// process list.
+func basicAuthEnv(username, password string) []string {
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + credentials,
+	}
+}
+
+// sshEnv writes the known hosts and the private key, if any, to dir and
+// configures git to use them for SSH. Only the pinned host keys are trusted.
+func sshEnv(dir, privateKey, knownHosts string) ([]string, error) {
+	knownHostsPath := filepath.Join(dir, "known_hosts")
+	err := os.WriteFile(knownHostsPath, []byte(knownHosts), 0o600)
+	if err != nil {
+		return nil, xerrors.Errorf("write known hosts: %w", err)
+	}
+	command := "ssh -o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + knownHostsPath
+	if privateKey != "" {
+		keyPath := filepath.Join(dir, "deploy_key")
+		err = os.WriteFile(keyPath, []byte(privateKey), 0o600)
+		if err != nil {
+			return nil, xerrors.Errorf("write deploy key: %w", err)
+		}
+		command += " -i " + keyPath + " -o IdentitiesOnly=yes"
+	}
+	return []string{"GIT_SSH_COMMAND=" + command}, nil
+}
+
+// resolve returns the SHA of the commit that the ref of the repository points
+// to, without fetching it.
+func resolve(ctx context.Context, repo repository) (string, error) {
+	ref := repo.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := git(ctx, "", repo.env, "ls-remote", "--", repo.url, ref, ref+"^{}")
+	if err != nil {
+		return "", err
+	}
+
+	refs := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		sha, name, ok := strings.Cut(scanner.Text(), "\t")
+		if ok {
+			refs[name] = sha
+		}
+	}
+	// Annotated tags are peeled, so the SHA is the one of the commit rather
+	// than the one of the tag object.
+	for _, name := range []string{
+		ref,
+		"refs/heads/" + ref,
+		"refs/tags/" + ref + "^{}",
+		"refs/tags/" + ref,
+	} {
+		if sha, ok := refs[name]; ok {
+			return sha, nil
+		}
+	}
+	return "", xerrors.Errorf("ref %q not found in repository", ref)
+}
+
+// checkout fetches the latest commit of the ref of the repository into dir.
+// Only that commit is fetched.
+func checkout(ctx context.Context, repo repository, dir string) (commit, error) {
+	ref := repo.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if _, err := git(ctx, "", repo.env, "init", "--quiet", dir); err != nil {
+		return commit{}, err
+	}
+	if _, err := git(ctx, dir, repo.env, "fetch", "--quiet", "--depth", "1", "--", repo.url, ref); err != nil {
+		return commit{}, err
+	}
+	if _, err := git(ctx, dir, repo.env, "checkout", "--quiet", "FETCH_HEAD"); err != nil {
+		return commit{}, err
+	}
+	out, err := git(ctx, dir, repo.env, "log", "-1", "--format=%H%x00%an <%ae>%x00%s")
+	if err != nil {
+		return commit{}, err
+	}
+	parts := strings.SplitN(strings.TrimSpace(string(out)), "\x00", 3)
+	if len(parts) != 3 {
+		return commit{}, xerrors.Errorf("unexpected git log output %q", out)
+	}
+	return commit{
+		SHA:     parts[0],
+		Author:  parts[1],
+		Subject: parts[2],
+	}, nil
+}
+
+func git(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Git must never wait for credentials on a terminal, or fetch with any
+	// protocol other than the allowed ones.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL="+allowedProtocols)
+	cmd.Env = append(cmd.Env, env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, xerrors.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package gitsync_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/gitsync"
+)
+
+func TestValidateRepoURL(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		url   string
+		valid bool
+	}{
+		{"https://github.com/coder/templates.git", true},
+		{"ssh://git@github.com/coder/templates.git", true},
+		{"ssh://git@gitlab.example.com:2222/coder/templates.git", true},
+		{"git@github.com:coder/templates.git", true},
+		{"github.com:coder/templates.git", true},
+		{"http://github.com/coder/templates.git", false},
+		{"file:///var/lib/coder/repo", false},
+		{"git://github.com/coder/templates.git", false},
+		{"ext::sh -c touch% /tmp/pwned", false},
+		{"https:///templates.git", false},
+		{"/var/lib/coder/repo", false},
+		{"./repo", false},
+		{"../repo:main", false},
+		{"git@:templates.git", false},
+		{"--upload-pack=touch /tmp/pwned", false},
+		{"", false},
+	} {
+		err := gitsync.ValidateRepoURL(tc.url)
+		if tc.valid {
+			require.NoError(t, err, tc.url)
+		} else {
+			require.Error(t, err, tc.url)
+		}
+	}
+}
+
+func TestValidateKnownHosts(t *testing.T) {
+	t.Parallel()
+
+	const githubKey = "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+	for _, tc := range []struct {
+		knownHosts string
+		valid      bool
+	}{
+		{githubKey, true},
+		{"# github.com:22 SSH-2.0-babeld\n" + githubKey + "\n", true},
+		{"", false},
+		{"# only a comment\n", false},
+		{"github.com ssh-ed25519 not-a-key", false},
+	} {
+		err := gitsync.ValidateKnownHosts(tc.knownHosts)
+		if tc.valid {
+			require.NoError(t, err, tc.knownHosts)
+		} else {
+			require.Error(t, err, tc.knownHosts)
+		}
+	}
+}
@@ -0,0 +1,682 @@
+// Package gitsync creates new versions of templates from the commits of the git
+// repositories that they are bound to.
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform/tfparse"
+	"github.com/onchainengineering/hmi-wirtual/provisionersdk"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/provisionerjobs"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/pubsub"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/externalauth"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpmw"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/provisionerdserver"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/rbac"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+const (
+	// MaxSyncsPerRun is the maximum number of repositories that the syncer
+	// checks in a single run. The remaining repositories are checked on the
+	// next run.
+	MaxSyncsPerRun = 10
+	// DefaultPollInterval is how often repositories are checked for new
+	// commits when no webhook is received.
+	DefaultPollInterval = 5 * time.Minute
+
+	tarMimeType = "application/x-tar"
+	// dryRunWorkspaceName is the name of the workspace in the dry run of
+	// synced template versions.
+	dryRunWorkspaceName = "git-sync"
+)
+
+var errUserNotActive = xerrors.New("the user that configured the git source isn't active")
+
+// Syncer checks the git sources of templates for new commits on every tick. A
+// new commit is imported as a template version, which is optionally promoted
+// after a successful dry run.
+type Syncer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	db                  database.Store
+	pubsub              pubsub.Pubsub
+	log                 slog.Logger
+	externalAuthConfigs []*externalauth.Config
+	tick                <-chan time.Time
+	interval            time.Duration
+	allowedProtocols    string
+	stats               chan<- Stats
+}
+
+// Stats contains statistics about the last run of the syncer.
+type Stats struct {
+	// JobIDs contains the IDs of the import and dry run jobs that were queued.
+	JobIDs []uuid.UUID
+	// Errors contains the sync errors of templates, keyed by template ID.
+	Errors map[uuid.UUID]error
+	// Error is the fatal error that occurred during the last run of the
+	// syncer, if any.
+	Error error
+}
+
+// New returns a new git syncer. Repositories are checked again once interval
+// has passed since their previous check.
+func New(ctx context.Context, db database.Store, pub pubsub.Pubsub, log slog.Logger, externalAuthConfigs []*externalauth.Config, tick <-chan time.Time, interval time.Duration) *Syncer {
+	//nolint:gocritic // The syncer creates template versions on behalf of template admins.
+	ctx, cancel := context.WithCancel(dbauthz.AsSystemRestricted(ctx))
+	return &Syncer{
+		ctx:                 ctx,
+		cancel:              cancel,
+		done:                make(chan struct{}),
+		db:                  db,
+		pubsub:              pub,
+		log:                 log,
+		externalAuthConfigs: externalAuthConfigs,
+		tick:                tick,
+		interval:            interval,
+		allowedProtocols:    allowedProtocols,
+		stats:               nil,
+	}
+}
+
+// WithStatsChannel will cause the syncer to push Stats to ch after every tick.
+// This push is blocking, so if ch is not read, the syncer will hang. This
+// should only be used in tests.
+func (s *Syncer) WithStatsChannel(ch chan<- Stats) *Syncer {
+	s.stats = ch
+	return s
+}
+
+// WithAllowedProtocols overrides the protocols that git may fetch repositories
+// with, e.g. to fetch local repositories with file:// URLs. This should only be
+// used in tests.
+func (s *Syncer) WithAllowedProtocols(protocols string) *Syncer {
+	s.allowedProtocols = protocols
+	return s
+}
+
+// Start will cause the syncer to sync repositories on every tick from its
+// channel. It will stop when its context is Done, or when its channel is
+// closed.
+//
+// Start should only be called once.
+func (s *Syncer) Start() {
+	go func() {
+		defer close(s.done)
+		defer s.cancel()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case t, ok := <-s.tick:
+				if !ok {
+					return
+				}
+				stats := s.run(t)
+				if stats.Error != nil {
+					s.log.Warn(s.ctx, "error running template git syncer once", slog.Error(stats.Error))
+				}
+				if s.stats != nil {
+					select {
+					case <-s.ctx.Done():
+						return
+					case s.stats <- stats:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait will block until the syncer is stopped.
+func (s *Syncer) Wait() {
+	<-s.done
+}
+
+// Close will stop the syncer.
+func (s *Syncer) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *Syncer) run(t time.Time) Stats {
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Minute)
+	defer cancel()
+
+	stats := Stats{
+		JobIDs: []uuid.UUID{},
+		Errors: map[uuid.UUID]error{},
+		Error:  nil,
+	}
+
+	var jobs []database.ProvisionerJob
+	err := s.db.InTx(func(db database.Store) error {
+		// Only one replica advances syncs at a time, so dry runs aren't
+		// queued twice.
+		locked, err := db.TryAcquireLock(ctx, database.LockIDTemplateGitSync)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			s.log.Debug(ctx, "unable to acquire lock for template git sync, skipping completed jobs")
+			return nil
+		}
+
+		sources, err := db.GetTemplateGitSourcesWithCompletedJobs(ctx)
+		if err != nil {
+			return xerrors.Errorf("get template git sources with completed jobs: %w", err)
+		}
+		for _, source := range sources {
+			job, err := s.completeJob(ctx, db, source)
+			if err != nil {
+				return xerrors.Errorf("complete job of template %s: %w", source.TemplateID, err)
+			}
+			if job != nil {
+				jobs = append(jobs, *job)
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+
+	// Sources are claimed with SKIP LOCKED, so replicas never check the same
+	// repository at once.
+	sources, err := s.db.AcquireTemplateGitSources(ctx, database.AcquireTemplateGitSourcesParams{
+		Now:           dbtime.Time(t),
+		CheckedBefore: dbtime.Time(t.Add(-s.interval)),
+		LimitOpt:      MaxSyncsPerRun,
+	})
+	if err != nil {
+		stats.Error = xerrors.Errorf("acquire template git sources: %w", err)
+		return stats
+	}
+	for _, source := range sources {
+		job, err := s.sync(ctx, source)
+		if err != nil {
+			stats.Errors[source.TemplateID] = err
+			s.log.Warn(ctx, "failed to sync template from git",
+				slog.F("template_id", source.TemplateID),
+				slog.F("repo_url", source.RepoURL),
+				slog.Error(err),
+			)
+			err = s.db.UpdateTemplateGitSourceSyncByTemplateID(ctx, database.UpdateTemplateGitSourceSyncByTemplateIDParams{
+				TemplateID:        source.TemplateID,
+				SyncStatus:        database.TemplateGitSyncStatusFailed,
+				SyncError:         err.Error(),
+				LastSyncedAt:      source.LastSyncedAt,
+				LastCommitSHA:     source.LastCommitSHA,
+				TemplateVersionID: source.TemplateVersionID,
+				JobID:             uuid.NullUUID{},
+			})
+			if err != nil {
+				s.log.Error(ctx, "failed to update template git sync status", slog.F("template_id", source.TemplateID), slog.Error(err))
+			}
+			continue
+		}
+		if job != nil {
+			jobs = append(jobs, *job)
+		}
+	}
+
+	for _, job := range jobs {
+		stats.JobIDs = append(stats.JobIDs, job.ID)
+		err := provisionerjobs.PostJob(s.pubsub, job)
+		if err != nil {
+			s.log.Error(ctx, "failed to post provisioner job to pubsub", slog.F("job_id", job.ID), slog.Error(err))
+		}
+	}
+	if len(jobs) > 0 {
+		s.log.Debug(ctx, "queued template git sync jobs", slog.F("count", len(jobs)))
+	}
+	return stats
+}
+
+// completeJob moves the sync of source forward now that its job completed. It
+// returns the dry run job that was queued, if any.
+func (s *Syncer) completeJob(ctx context.Context, db database.Store, source database.TemplateGitSource) (*database.ProvisionerJob, error) {
+	job, err := db.GetProvisionerJobByID(ctx, source.JobID.UUID)
+	if err != nil {
+		return nil, xerrors.Errorf("get provisioner job: %w", err)
+	}
+	update := database.UpdateTemplateGitSourceSyncByTemplateIDParams{
+		TemplateID:        source.TemplateID,
+		SyncStatus:        database.TemplateGitSyncStatusSucceeded,
+		SyncError:         "",
+		LastSyncedAt:      sql.NullTime{Time: dbtime.Now(), Valid: true},
+		LastCommitSHA:     source.LastCommitSHA,
+		TemplateVersionID: source.TemplateVersionID,
+		JobID:             uuid.NullUUID{},
+	}
+
+	if job.JobStatus != database.ProvisionerJobStatusSucceeded {
+		update.SyncStatus = database.TemplateGitSyncStatusFailed
+		update.SyncError = job.Error.String
+		update.LastSyncedAt = source.LastSyncedAt
+		if update.SyncError == "" {
+			update.SyncError = fmt.Sprintf("The %s job was %s.", job.Type, job.JobStatus)
+		}
+		if job.Type == database.ProvisionerJobTypeTemplateVersionDryRun {
+			update.SyncError = "The dry run failed, so the version wasn't promoted: " + update.SyncError
+		}
+		return nil, db.UpdateTemplateGitSourceSyncByTemplateID(ctx, update)
+	}
+
+	var dryRun *database.ProvisionerJob
+	switch {
+	case job.Type == database.ProvisionerJobTypeTemplateVersionImport && source.AutoPromote:
+		input, err := json.Marshal(provisionerdserver.TemplateVersionDryRunJob{
+			TemplateVersionID: source.TemplateVersionID.UUID,
+			WorkspaceName:     dryRunWorkspaceName,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("marshal job input: %w", err)
+		}
+		now := dbtime.Now()
+		dryRunJob, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:             uuid.New(),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			OrganizationID: job.OrganizationID,
+			InitiatorID:    job.InitiatorID,
+			Provisioner:    job.Provisioner,
+			StorageMethod:  job.StorageMethod,
+			FileID:         job.FileID,
+			Type:           database.ProvisionerJobTypeTemplateVersionDryRun,
+			Input:          input,
+			Tags:           job.Tags,
+			Priority:       database.ProvisionerJobPriorityBulk,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("insert provisioner job: %w", err)
+		}
+		dryRun = &dryRunJob
+		update.SyncStatus = database.TemplateGitSyncStatusDryRun
+		update.LastSyncedAt = source.LastSyncedAt
+		update.JobID = uuid.NullUUID{UUID: dryRunJob.ID, Valid: true}
+	case job.Type == database.ProvisionerJobTypeTemplateVersionDryRun:
+		userCtx, err := asUser(ctx, db, source.UserID)
+		if err == nil {
+			err = db.UpdateTemplateActiveVersionByID(userCtx, database.UpdateTemplateActiveVersionByIDParams{
+				ID:              source.TemplateID,
+				ActiveVersionID: source.TemplateVersionID.UUID,
+				UpdatedAt:       dbtime.Now(),
+			})
+		}
+		if err != nil {
+			if !errIsUser(err) {
+				return nil, xerrors.Errorf("update active version: %w", err)
+			}
+			update.SyncStatus = database.TemplateGitSyncStatusFailed
+			update.SyncError = "The version wasn't promoted: " + err.Error()
+			update.LastSyncedAt = source.LastSyncedAt
+			return nil, db.UpdateTemplateGitSourceSyncByTemplateID(ctx, update)
+		}
+		// Workspaces watch this channel to learn that they are outdated.
+		err = s.pubsub.Publish(fmt.Sprintf("template:%s", source.TemplateID), []byte{})
+		if err != nil {
+			s.log.Warn(ctx, "failed to publish template update", slog.F("template_id", source.TemplateID), slog.Error(err))
+		}
+	}
+	err = db.UpdateTemplateGitSourceSyncByTemplateID(ctx, update)
+	if err != nil {
+		return nil, xerrors.Errorf("update template git sync: %w", err)
+	}
+	return dryRun, nil
+}
+
+// sync checks the repository of source for a new commit, and queues the import
+// of a new template version if there is one.
+func (s *Syncer) sync(ctx context.Context, source database.TemplateGitSource) (*database.ProvisionerJob, error) {
+	dir, err := os.MkdirTemp("", "gitsync-*")
+	if err != nil {
+		return nil, xerrors.Errorf("create temp dir: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			s.log.Error(ctx, "failed to remove temporary git sync dir", slog.Error(err))
+		}
+	}()
+
+	repo, err := s.repository(ctx, source, dir)
+	if err != nil {
+		return nil, err
+	}
+	sha, err := resolve(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if sha == source.LastCommitSHA {
+		return nil, s.clearCheckError(ctx, source)
+	}
+
+	checkoutDir := filepath.Join(dir, "repo")
+	head, err := checkout(ctx, repo, checkoutDir)
+	if err != nil {
+		return nil, err
+	}
+	templateDir := filepath.Join(checkoutDir, filepath.FromSlash(source.Path))
+	if stat, err := os.Stat(templateDir); err != nil || !stat.IsDir() {
+		return nil, xerrors.Errorf("path %q is not a directory in commit %s", source.Path, head.SHA)
+	}
+	templateDir, err = resolveTemplateDir(checkoutDir, templateDir)
+	if err != nil {
+		return nil, xerrors.Errorf("path %q in commit %s: %w", source.Path, head.SHA, err)
+	}
+	var archive bytes.Buffer
+	err = provisionersdk.Tar(&archive, s.log, templateDir, provisionersdk.TemplateArchiveLimit)
+	if err != nil {
+		return nil, xerrors.Errorf("archive template: %w", err)
+	}
+
+	parser, diags := tfparse.New(templateDir, tfparse.WithLogger(s.log.Named("tfparse")))
+	if diags.HasErrors() {
+		return nil, xerrors.Errorf("parse template: %s", diags.Error())
+	}
+	parsedTags, err := parser.WorkspaceTagDefaults(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("evaluate default values of workspace tags: %w", err)
+	}
+
+	// Template versions are created by the user of the source.
+	userCtx, err := asUser(ctx, s.db, source.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var job database.ProvisionerJob
+	err = s.db.InTx(func(db database.Store) error {
+		template, err := db.GetTemplateByID(ctx, source.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		activeVersion, err := db.GetTemplateVersionByID(ctx, template.ActiveVersionID)
+		if err != nil {
+			return xerrors.Errorf("get active template version: %w", err)
+		}
+		activeJob, err := db.GetProvisionerJobByID(ctx, activeVersion.JobID)
+		if err != nil {
+			return xerrors.Errorf("get active template version job: %w", err)
+		}
+		// The variables and provisioner tags of the active version are kept,
+		// so synced versions are imported the same way as pushed ones.
+		variables, err := db.GetTemplateVersionVariables(ctx, activeVersion.ID)
+		if err != nil {
+			return xerrors.Errorf("get active template version variables: %w", err)
+		}
+		variableValues := make([]wirtualsdk.VariableValue, 0, len(variables))
+		for _, variable := range variables {
+			variableValues = append(variableValues, wirtualsdk.VariableValue{
+				Name:  variable.Name,
+				Value: variable.Value,
+			})
+		}
+		tags := provisionersdk.MutateTags(source.UserID, activeJob.Tags, parsedTags)
+
+		hashBytes := sha256.Sum256(archive.Bytes())
+		hash := hex.EncodeToString(hashBytes[:])
+		file, err := db.GetFileByHashAndCreator(ctx, database.GetFileByHashAndCreatorParams{
+			Hash:      hash,
+			CreatedBy: source.UserID,
+		})
+		if err != nil {
+			if !xerrors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get file: %w", err)
+			}
+			file, err = db.InsertFile(userCtx, database.InsertFileParams{
+				ID:        uuid.New(),
+				Hash:      hash,
+				CreatedBy: source.UserID,
+				CreatedAt: dbtime.Now(),
+				Mimetype:  tarMimeType,
+				Data:      archive.Bytes(),
+			})
+			if err != nil {
+				return xerrors.Errorf("insert file: %w", err)
+			}
+		}
+
+		versionID := uuid.New()
+		input, err := json.Marshal(provisionerdserver.TemplateVersionImportJob{
+			TemplateVersionID:  versionID,
+			UserVariableValues: variableValues,
+		})
+		if err != nil {
+			return xerrors.Errorf("marshal job input: %w", err)
+		}
+		now := dbtime.Now()
+		job, err = db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:             uuid.New(),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			OrganizationID: template.OrganizationID,
+			InitiatorID:    source.UserID,
+			Provisioner:    template.Provisioner,
+			StorageMethod:  database.ProvisionerStorageMethodFile,
+			FileID:         file.ID,
+			Type:           database.ProvisionerJobTypeTemplateVersionImport,
+			Input:          input,
+			Tags:           tags,
+			Priority:       database.ProvisionerJobPriorityBulk,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert provisioner job: %w", err)
+		}
+		err = db.InsertTemplateVersion(userCtx, database.InsertTemplateVersionParams{
+			ID:              versionID,
+			TemplateID:      uuid.NullUUID{UUID: template.ID, Valid: true},
+			OrganizationID:  template.OrganizationID,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+			Name:            namesgenerator.GetRandomName(1),
+			Message:         head.Subject,
+			JobID:           job.ID,
+			CreatedBy:       source.UserID,
+			GitCommitSHA:    head.SHA,
+			GitCommitAuthor: head.Author,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert template version: %w", err)
+		}
+		return db.UpdateTemplateGitSourceSyncByTemplateID(ctx, database.UpdateTemplateGitSourceSyncByTemplateIDParams{
+			TemplateID:        source.TemplateID,
+			SyncStatus:        database.TemplateGitSyncStatusImporting,
+			SyncError:         "",
+			LastSyncedAt:      source.LastSyncedAt,
+			LastCommitSHA:     head.SHA,
+			TemplateVersionID: uuid.NullUUID{UUID: versionID, Valid: true},
+			JobID:             uuid.NullUUID{UUID: job.ID, Valid: true},
+		})
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info(ctx, "importing template version from git",
+		slog.F("template_id", source.TemplateID),
+		slog.F("commit", head.SHA),
+	)
+	return &job, nil
+}
+
+// resolveTemplateDir resolves the symlinks of templateDir and checks that it's
+// inside checkoutDir. Repositories are untrusted, so every symlink inside the
+// template must also point inside it, or files of the server could be parsed
+// and archived.
+func resolveTemplateDir(checkoutDir, templateDir string) (string, error) {
+	root, err := filepath.EvalSymlinks(checkoutDir)
+	if err != nil {
+		return "", xerrors.Errorf("resolve checkout: %w", err)
+	}
+	dir, err := filepath.EvalSymlinks(templateDir)
+	if err != nil {
+		return "", xerrors.Errorf("resolve template directory: %w", err)
+	}
+	if !isInside(root, dir) {
+		return "", xerrors.New("the template directory is outside of the repository")
+	}
+	err = filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type()&os.ModeSymlink == 0 {
+			return nil
+		}
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil || !isInside(dir, target) {
+			rel, _ := filepath.Rel(dir, path)
+			return xerrors.Errorf("symlink %q points outside of the template directory", filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// isInside returns whether path is dir or inside of it. Both must be clean.
+func isInside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// asUser returns a context that authorizes queries as the user of a source, so
+// templates are only synced while that user can still update them.
+func asUser(ctx context.Context, db database.Store, userID uuid.UUID) (context.Context, error) {
+	actor, status, err := httpmw.UserRBACSubject(ctx, db, userID, rbac.ScopeAll)
+	if err != nil {
+		return nil, xerrors.Errorf("get user subject: %w", err)
+	}
+	if status != database.UserStatusActive {
+		return nil, xerrors.Errorf("%w: the user is %s", errUserNotActive, status)
+	}
+	return dbauthz.As(ctx, actor), nil
+}
+
+// errIsUser returns whether err is caused by the user of a source, rather than
+// by the syncer.
+func errIsUser(err error) bool {
+	return dbauthz.IsNotAuthorizedError(err) || xerrors.Is(err, errUserNotActive)
+}
+
+// clearCheckError clears the error of a source whose repository couldn't be
+// checked before, but could be now. Errors of the jobs of the latest commit are
+// kept, since the commit must change for them to be resolved.
+func (s *Syncer) clearCheckError(ctx context.Context, source database.TemplateGitSource) error {
+	if source.SyncStatus != database.TemplateGitSyncStatusFailed || !source.TemplateVersionID.Valid {
+		return nil
+	}
+	version, err := s.db.GetTemplateVersionByID(ctx, source.TemplateVersionID.UUID)
+	if err != nil {
+		return xerrors.Errorf("get template version: %w", err)
+	}
+	job, err := s.db.GetProvisionerJobByID(ctx, version.JobID)
+	if err != nil {
+		return xerrors.Errorf("get provisioner job: %w", err)
+	}
+	if job.JobStatus != database.ProvisionerJobStatusSucceeded {
+		return nil
+	}
+	if source.AutoPromote {
+		template, err := s.db.GetTemplateByID(ctx, source.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		if template.ActiveVersionID != version.ID {
+			// The dry run failed.
+			return nil
+		}
+	}
+	return s.db.UpdateTemplateGitSourceSyncByTemplateID(ctx, database.UpdateTemplateGitSourceSyncByTemplateIDParams{
+		TemplateID:        source.TemplateID,
+		SyncStatus:        database.TemplateGitSyncStatusSucceeded,
+		SyncError:         "",
+		LastSyncedAt:      source.LastSyncedAt,
+		LastCommitSHA:     source.LastCommitSHA,
+		TemplateVersionID: source.TemplateVersionID,
+		JobID:             uuid.NullUUID{},
+	})
+}
+
+// repository returns the repository of source, authenticated with its deploy
+// key or the external auth link of its user. SSH host keys are checked against
+// the known hosts of the source.
+func (s *Syncer) repository(ctx context.Context, source database.TemplateGitSource, dir string) (repository, error) {
+	repo := repository{
+		url: source.RepoURL,
+		ref: source.Ref,
+	}
+	if source.DeployPrivateKey != "" || source.KnownHosts != "" {
+		env, err := sshEnv(dir, source.DeployPrivateKey, source.KnownHosts)
+		if err != nil {
+			return repository{}, err
+		}
+		repo.env = env
+	}
+	if source.ExternalAuthProviderID != "" {
+		var config *externalauth.Config
+		for _, c := range s.externalAuthConfigs {
+			if c.ID == source.ExternalAuthProviderID {
+				config = c
+				break
+			}
+		}
+		if config == nil {
+			return repository{}, xerrors.Errorf("external auth provider %q is not configured", source.ExternalAuthProviderID)
+		}
+		link, err := s.db.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
+			ProviderID: config.ID,
+			UserID:     source.UserID,
+		})
+		if err != nil {
+			if xerrors.Is(err, sql.ErrNoRows) {
+				return repository{}, xerrors.Errorf("the user that configured the git source isn't authenticated with %q", config.ID)
+			}
+			return repository{}, xerrors.Errorf("get external auth link: %w", err)
+		}
+		link, err = config.RefreshToken(ctx, s.db, link)
+		if err != nil {
+			return repository{}, xerrors.Errorf("refresh %q token: %w", config.ID, err)
+		}
+		repo.env = append(repo.env, basicAuthEnv(externalAuthCredentials(config.Type, link.OAuthAccessToken))...)
+	}
+	repo.env = append(repo.env, "GIT_ALLOW_PROTOCOL="+s.allowedProtocols)
+	return repo, nil
+}
+
+// externalAuthCredentials returns the HTTP credentials that the git host of
+// the provider accepts for an access token.
+func externalAuthCredentials(typ, token string) (string, string) {
+	switch typ {
+	case string(wirtualsdk.EnhancedExternalAuthProviderGitLab):
+		return "oauth2", token
+	case string(wirtualsdk.EnhancedExternalAuthProviderBitBucketCloud), string(wirtualsdk.EnhancedExternalAuthProviderBitBucketServer):
+		return "x-token-auth", token
+	default:
+		return token, ""
+	}
+}
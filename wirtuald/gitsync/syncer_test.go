@@ -0,0 +1,244 @@
+package gitsync_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog"
+
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbfake"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbgen"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtestutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/gitsync"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/rbac"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestSyncer(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	var (
+		ctx        = testutil.Context(t, testutil.WaitLong)
+		db, pubsub = dbtestutil.NewDB(t)
+		log        = testutil.Logger(t)
+		tickCh     = make(chan time.Time)
+		statsCh    = make(chan gitsync.Stats)
+		interval   = time.Hour
+	)
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "--quiet", "--initial-branch", "main")
+	writeFile(t, filepath.Join(repo, "templates", "docker", "main.tf"), `resource "null_resource" "example" {}`)
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "--quiet", "-m", "Add the docker template")
+	sha := strings.TrimSpace(runGit(t, repo, "rev-parse", "HEAD"))
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{RBACRoles: []string{wirtualsdk.RoleTemplateAdmin}})
+	_ = dbgen.OrganizationMember(t, db, database.OrganizationMember{OrganizationID: org.ID, UserID: user.ID})
+	active := dbfake.TemplateVersion(t, db).Seed(database.TemplateVersion{
+		OrganizationID: org.ID,
+		CreatedBy:      user.ID,
+	}).Do()
+	source := dbgen.TemplateGitSource(t, db, database.TemplateGitSource{
+		TemplateID:  active.Template.ID,
+		UserID:      user.ID,
+		RepoURL:     "file://" + repo,
+		Path:        "templates/docker",
+		Ref:         "main",
+		AutoPromote: true,
+	})
+
+	syncer := gitsync.New(ctx, wrapDBAuthz(db, log), pubsub, log, nil, tickCh, interval).WithStatsChannel(statsCh).WithAllowedProtocols("file")
+	syncer.Start()
+	defer syncer.Close()
+
+	// The new commit is imported.
+	now := dbtime.Now()
+	tickCh <- now
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Errors)
+	require.Len(t, stats.JobIDs, 1)
+
+	source, err := db.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateGitSyncStatusImporting, source.SyncStatus)
+	require.Equal(t, sha, source.LastCommitSHA)
+	version, err := db.GetTemplateVersionByID(ctx, source.TemplateVersionID.UUID)
+	require.NoError(t, err)
+	require.Equal(t, sha, version.GitCommitSHA)
+	require.Equal(t, "Test User <test@example.com>", version.GitCommitAuthor)
+	require.Equal(t, "Add the docker template", version.Message)
+	require.Equal(t, user.ID, version.CreatedBy)
+	require.Equal(t, stats.JobIDs[0], version.JobID)
+
+	// The repository was checked recently, and the import is still running.
+	tickCh <- now.Add(2 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.JobIDs)
+
+	// Once imported, the version is dry run before it's promoted.
+	completeJob(ctx, t, db, version.JobID)
+	tickCh <- now.Add(2 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.JobIDs, 1)
+	dryRun, err := db.GetProvisionerJobByID(ctx, stats.JobIDs[0])
+	require.NoError(t, err)
+	require.Equal(t, database.ProvisionerJobTypeTemplateVersionDryRun, dryRun.Type)
+	source, err = db.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateGitSyncStatusDryRun, source.SyncStatus)
+
+	completeJob(ctx, t, db, dryRun.ID)
+	tickCh <- now.Add(2 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Errors)
+	source, err = db.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateGitSyncStatusSucceeded, source.SyncStatus)
+	require.True(t, source.LastSyncedAt.Valid)
+	template, err := db.GetTemplateByID(ctx, source.TemplateID)
+	require.NoError(t, err)
+	require.Equal(t, version.ID, template.ActiveVersionID)
+
+	// Nothing changed in the repository.
+	tickCh <- now.Add(4 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Errors)
+	require.Empty(t, stats.JobIDs)
+
+	// A sync is requested, but the path no longer exists.
+	runGit(t, repo, "rm", "--quiet", "-r", "templates")
+	runGit(t, repo, "commit", "--quiet", "-m", "Remove the docker template")
+	err = db.RequestTemplateGitSourceSync(ctx, database.RequestTemplateGitSourceSyncParams{
+		TemplateID:  source.TemplateID,
+		RequestedAt: dbtime.Now(),
+	})
+	require.NoError(t, err)
+	tickCh <- now.Add(4 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.JobIDs)
+	require.Contains(t, stats.Errors, source.TemplateID)
+	source, err = db.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateGitSyncStatusFailed, source.SyncStatus)
+	require.Contains(t, source.SyncError, `path "templates/docker" is not a directory`)
+
+	// Symlinks must not point outside of the template.
+	outside := t.TempDir()
+	writeFile(t, filepath.Join(outside, "main.tf"), `resource "null_resource" "example" {}`)
+	writeFile(t, filepath.Join(repo, "templates", "docker", "main.tf"), `resource "null_resource" "example" {}`)
+	err = os.Symlink(filepath.Join(outside, "main.tf"), filepath.Join(repo, "templates", "docker", "outside.tf"))
+	require.NoError(t, err)
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "--quiet", "-m", "Link a file outside of the repository")
+	err = db.RequestTemplateGitSourceSync(ctx, database.RequestTemplateGitSourceSyncParams{
+		TemplateID:  source.TemplateID,
+		RequestedAt: dbtime.Now(),
+	})
+	require.NoError(t, err)
+	tickCh <- now.Add(4 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.JobIDs)
+	require.Contains(t, stats.Errors, source.TemplateID)
+	source, err = db.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateGitSyncStatusFailed, source.SyncStatus)
+	require.Contains(t, source.SyncError, `symlink "outside.tf" points outside of the template directory`)
+
+	// Neither must the template directory itself.
+	runGit(t, repo, "rm", "--quiet", "-r", "templates")
+	err = os.MkdirAll(filepath.Join(repo, "templates"), 0o755)
+	require.NoError(t, err)
+	err = os.Symlink(outside, filepath.Join(repo, "templates", "docker"))
+	require.NoError(t, err)
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "--quiet", "-m", "Link the template outside of the repository")
+	err = db.RequestTemplateGitSourceSync(ctx, database.RequestTemplateGitSourceSyncParams{
+		TemplateID:  source.TemplateID,
+		RequestedAt: dbtime.Now(),
+	})
+	require.NoError(t, err)
+	tickCh <- now.Add(4 * interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.JobIDs)
+	source, err = db.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+	require.NoError(t, err)
+	require.Contains(t, source.SyncError, "the template directory is outside of the repository")
+}
+
+func completeJob(ctx context.Context, t *testing.T, db database.Store, jobID uuid.UUID) {
+	t.Helper()
+	err := db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		ID:        jobID,
+		UpdatedAt: dbtime.Now(),
+		CompletedAt: sql.NullTime{
+			Time:  dbtime.Now(),
+			Valid: true,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test User",
+		"GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test User",
+		"GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+}
+
+func wrapDBAuthz(db database.Store, logger slog.Logger) database.Store {
+	return dbauthz.New(
+		db,
+		rbac.NewStrictCachingAuthorizer(prometheus.NewRegistry()),
+		logger,
+		wirtualdtest.AccessControlStorePointer(),
+	)
+}
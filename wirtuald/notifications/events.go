@@ -13,6 +13,7 @@ var (
 	TemplateWorkspaceAutoUpdated       = uuid.MustParse("c34a0c09-0704-4cac-bd1c-0c0146811c2b")
 	TemplateWorkspaceMarkedForDeletion = uuid.MustParse("51ce2fdf-c9ca-4be1-8d70-628674f9bc42")
 	TemplateWorkspaceManualBuildFailed = uuid.MustParse("2faeee0f-26cb-4e96-821c-85ccb9f71513")
+	TemplateWorkspaceDriftDetected     = uuid.MustParse("8c3d1a5e-6f0b-4e2a-9d47-b2e91f6c0a83")
 )
 
 // Account-related events.
@@ -939,6 +939,19 @@ func TestNotificationTemplates_Golden(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "TemplateWorkspaceDriftDetected",
+			id:   notifications.TemplateWorkspaceDriftDetected,
+			payload: types.MessagePayload{
+				UserName:     "Bobby",
+				UserEmail:    "bobby@coder.com",
+				UserUsername: "bobby",
+				Labels: map[string]string{
+					"name":      "bobby-workspace",
+					"resources": "docker_container.workspace, docker_volume.home",
+				},
+			},
+		},
 		{
 			name: "TemplateWorkspaceBuildsFailedReport",
 			id:   notifications.TemplateWorkspaceBuildsFailedReport,
@@ -801,6 +801,7 @@ func insertRunning(t *testing.T, db database.Store) database.ProvisionerJob {
 		Provisioner:   database.ProvisionerTypeEcho,
 		StorageMethod: database.ProvisionerStorageMethodFile,
 		Type:          database.ProvisionerJobTypeWorkspaceBuild,
+		Priority:      database.ProvisionerJobPriorityInteractive,
 	})
 	require.NoError(t, err)
 	err = db.InsertWorkspaceBuild(context.Background(), database.InsertWorkspaceBuildParams{
@@ -815,7 +816,7 @@ func insertRunning(t *testing.T, db database.Store) database.ProvisionerJob {
 	require.NoError(t, err)
 	// This marks the job as started.
 	_, err = db.AcquireProvisionerJob(context.Background(), database.AcquireProvisionerJobParams{
-		OrganizationID: job.OrganizationID,
+		OrganizationIDs: []uuid.UUID{job.OrganizationID},
 		StartedAt: sql.NullTime{
 			Time:  dbtime.Now(),
 			Valid: true,
@@ -84,22 +84,23 @@ func NewAcquirer(ctx context.Context, logger slog.Logger, store AcquirerStore, p
 	return a
 }
 
-// AcquireJob acquires a job with one of the given provisioner types and compatible
-// tags from the database.  The call blocks until a job is acquired, the context is
-// done, or the database returns an error _other_ than that no jobs are available.
-// If no jobs are available, this method handles retrying as appropriate.
+// AcquireJob acquires a job of one of the given organizations with one of the
+// given provisioner types and compatible tags from the database.  The call blocks
+// until a job is acquired, the context is done, or the database returns an error
+// _other_ than that no jobs are available. If no jobs are available, this method
+// handles retrying as appropriate.
 func (a *Acquirer) AcquireJob(
-	ctx context.Context, organization uuid.UUID, worker uuid.UUID, pt []database.ProvisionerType, tags Tags,
+	ctx context.Context, organizations []uuid.UUID, worker uuid.UUID, pt []database.ProvisionerType, tags Tags,
 ) (
 	retJob database.ProvisionerJob, retErr error,
 ) {
 	logger := a.logger.With(
-		slog.F("organization_id", organization),
+		slog.F("organization_ids", organizations),
 		slog.F("worker_id", worker),
 		slog.F("provisioner_types", pt),
 		slog.F("tags", tags))
 	logger.Debug(ctx, "acquiring job")
-	dk := domainKey(organization, pt, tags)
+	dk := domainKey(organizations, pt, tags)
 	dbTags, err := tags.ToJSON()
 	if err != nil {
 		return database.ProvisionerJob{}, err
@@ -107,7 +108,7 @@ func (a *Acquirer) AcquireJob(
 	// buffer of 1 so that cancel doesn't deadlock while writing to the channel
 	clearance := make(chan struct{}, 1)
 	for {
-		a.want(organization, pt, tags, clearance)
+		a.want(organizations, pt, tags, clearance)
 		select {
 		case <-ctx.Done():
 			err := ctx.Err()
@@ -121,7 +122,7 @@ func (a *Acquirer) AcquireJob(
 		case <-clearance:
 			logger.Debug(ctx, "got clearance to call database")
 			job, err := a.store.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-				OrganizationID: organization,
+				OrganizationIDs: organizations,
 				StartedAt: sql.NullTime{
 					Time:  dbtime.Now(),
 					Valid: true,
@@ -154,8 +155,8 @@ func (a *Acquirer) AcquireJob(
 }
 
 // want signals that an acquiree wants clearance to query for a job with the given dKey.
-func (a *Acquirer) want(organization uuid.UUID, pt []database.ProvisionerType, tags Tags, clearance chan<- struct{}) {
-	dk := domainKey(organization, pt, tags)
+func (a *Acquirer) want(organizations []uuid.UUID, pt []database.ProvisionerType, tags Tags, clearance chan<- struct{}) {
+	dk := domainKey(organizations, pt, tags)
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	cleared := false
@@ -163,14 +164,14 @@ func (a *Acquirer) want(organization uuid.UUID, pt []database.ProvisionerType, t
 	if !ok {
 		ctx, cancel := context.WithCancel(a.ctx)
 		d = domain{
-			ctx:            ctx,
-			cancel:         cancel,
-			a:              a,
-			key:            dk,
-			pt:             pt,
-			tags:           tags,
-			organizationID: organization,
-			acquirees:      make(map[chan<- struct{}]*acquiree),
+			ctx:             ctx,
+			cancel:          cancel,
+			a:               a,
+			key:             dk,
+			pt:              pt,
+			tags:            tags,
+			organizationIDs: organizations,
+			acquirees:       make(map[chan<- struct{}]*acquiree),
 		}
 		a.q[dk] = d
 		go d.poll(a.backupPollDuration)
@@ -407,9 +408,17 @@ type dKey string
 // unprintable control character and won't show up in any "reasonable" set of
 // string tags, even in non-Latin scripts.  It is important that Tags are
 // validated not to contain this control character prior to use.
-func domainKey(orgID uuid.UUID, pt []database.ProvisionerType, tags Tags) dKey {
+func domainKey(orgIDs []uuid.UUID, pt []database.ProvisionerType, tags Tags) dKey {
 	sb := strings.Builder{}
-	_, _ = sb.WriteString(orgID.String())
+	orgs := make([]string, 0, len(orgIDs))
+	for _, orgID := range orgIDs {
+		orgs = append(orgs, orgID.String())
+	}
+	slices.Sort(orgs)
+	for _, org := range orgs {
+		_, _ = sb.WriteString(org)
+		_ = sb.WriteByte(0x00)
+	}
 	_ = sb.WriteByte(0x00)
 
 	// make a copy of pt before sorting, so that we don't mutate the original
@@ -447,24 +456,24 @@ type acquiree struct {
 	pending bool
 }
 
-// domain represents a set of acquirees with the same provisioner types and
+// domain represents a set of acquirees with the same organizations, provisioner types and
 // tags.  Acquirees in the same domain are restricted such that only one queries
 // the database at a time.
 type domain struct {
-	ctx            context.Context
-	cancel         context.CancelFunc
-	a              *Acquirer
-	key            dKey
-	pt             []database.ProvisionerType
-	tags           Tags
-	organizationID uuid.UUID
-	acquirees      map[chan<- struct{}]*acquiree
+	ctx             context.Context
+	cancel          context.CancelFunc
+	a               *Acquirer
+	key             dKey
+	pt              []database.ProvisionerType
+	tags            Tags
+	organizationIDs []uuid.UUID
+	acquirees       map[chan<- struct{}]*acquiree
 }
 
 func (d domain) contains(p provisionerjobs.JobPosting) bool {
 	// If the organization ID is 'uuid.Nil', this is a legacy job posting.
 	// Ignore this check in the legacy case.
-	if p.OrganizationID != uuid.Nil && p.OrganizationID != d.organizationID {
+	if p.OrganizationID != uuid.Nil && !slices.Contains(d.organizationIDs, p.OrganizationID) {
 		return false
 	}
 	if !slices.Contains(d.pt, p.ProvisionerType) {
@@ -258,6 +258,49 @@ func TestAcquirer_DifferentDomains(t *testing.T) {
 	acquiree0.requireCanceled(ctx)
 }
 
+// TestAcquirer_MultipleOrganizations tests that an acquiree for several
+// organizations queries all of them, and is woken up by job postings of any of
+// them.
+func TestAcquirer_MultipleOrganizations(t *testing.T) {
+	t.Parallel()
+	fs := newFakeOrderedStore()
+	ps := pubsub.NewInMemory()
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+	logger := testutil.Logger(t)
+	uut := provisionerdserver.NewAcquirer(ctx, logger.Named("acquirer"), fs, ps)
+
+	orgA := uuid.New()
+	orgB := uuid.New()
+	workerID := uuid.New()
+	pt := []database.ProvisionerType{database.ProvisionerTypeEcho}
+	acquiree := newTestAcquiree(t, orgA, workerID, pt, provisionerdserver.Tags{})
+	acquiree.orgIDs = []uuid.UUID{orgA, orgB}
+	jobID := uuid.New()
+	err := fs.sendCtx(ctx, database.ProvisionerJob{}, sql.ErrNoRows)
+	require.NoError(t, err)
+	err = fs.sendCtx(ctx, database.ProvisionerJob{ID: jobID}, nil)
+	require.NoError(t, err)
+	acquiree.startAcquire(ctx, uut)
+	require.Eventually(t, func() bool {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		return len(fs.params) == 1
+	}, testutil.WaitShort, testutil.IntervalFast)
+	fs.mu.Lock()
+	require.Equal(t, []uuid.UUID{orgA, orgB}, fs.params[0].OrganizationIDs)
+	fs.mu.Unlock()
+	acquiree.requireBlocked()
+
+	// A job of another organization doesn't wake the acquiree.
+	postOrgJob(t, ps, uuid.New(), database.ProvisionerTypeEcho)
+	acquiree.requireBlocked()
+
+	postOrgJob(t, ps, orgB, database.ProvisionerTypeEcho)
+	job := acquiree.success(ctx)
+	require.Equal(t, jobID, job.ID)
+}
+
 func TestAcquirer_BackupPoll(t *testing.T) {
 	t.Parallel()
 	fs := newFakeOrderedStore()
@@ -496,6 +539,7 @@ func TestAcquirer_MatchTags(t *testing.T) {
 				Input:          []byte("{}"),
 				Tags:           tt.provisionerJobTags,
 				TraceMetadata:  pqtype.NullRawMessage{},
+				Priority:       database.ProvisionerJobPriorityInteractive,
 			})
 			require.NoError(t, err)
 			ptypes := []database.ProvisionerType{database.ProvisionerTypeEcho}
@@ -505,7 +549,7 @@ func TestAcquirer_MatchTags(t *testing.T) {
 			if tt.unmatchedOrg {
 				acquireOrgID = uuid.New()
 			}
-			aj, err := acq.AcquireJob(ctx, acquireOrgID, uuid.New(), ptypes, tt.acquireJobTags)
+			aj, err := acq.AcquireJob(ctx, []uuid.UUID{acquireOrgID}, uuid.New(), ptypes, tt.acquireJobTags)
 			if tt.expectAcquire {
 				assert.NoError(t, err)
 				assert.Equal(t, pj.ID, aj.ID)
@@ -563,6 +607,18 @@ func postJob(t *testing.T, ps pubsub.Pubsub, pt database.ProvisionerType, tags p
 	require.NoError(t, err)
 }
 
+func postOrgJob(t *testing.T, ps pubsub.Pubsub, orgID uuid.UUID, pt database.ProvisionerType) {
+	t.Helper()
+	msg, err := json.Marshal(provisionerjobs.JobPosting{
+		OrganizationID:  orgID,
+		ProvisionerType: pt,
+		Tags:            provisionerdserver.Tags{},
+	})
+	require.NoError(t, err)
+	err = ps.Publish(provisionerjobs.EventJobPosted, msg)
+	require.NoError(t, err)
+}
+
 // fakeOrderedStore is a fake store that lets tests send AcquireProvisionerJob
 // results in order over a channel, and tests for overlapped calls.
 type fakeOrderedStore struct {
@@ -682,7 +738,7 @@ jobLoop:
 // and asserting whether or not it returns, blocks, or is canceled.
 type testAcquiree struct {
 	t        *testing.T
-	orgID    uuid.UUID
+	orgIDs   []uuid.UUID
 	workerID uuid.UUID
 	pt       []database.ProvisionerType
 	tags     provisionerdserver.Tags
@@ -693,7 +749,7 @@ type testAcquiree struct {
 func newTestAcquiree(t *testing.T, orgID uuid.UUID, workerID uuid.UUID, pt []database.ProvisionerType, tags provisionerdserver.Tags) *testAcquiree {
 	return &testAcquiree{
 		t:        t,
-		orgID:    orgID,
+		orgIDs:   []uuid.UUID{orgID},
 		workerID: workerID,
 		pt:       pt,
 		tags:     tags,
@@ -704,7 +760,7 @@ func newTestAcquiree(t *testing.T, orgID uuid.UUID, workerID uuid.UUID, pt []dat
 
 func (a *testAcquiree) startAcquire(ctx context.Context, uut *provisionerdserver.Acquirer) {
 	go func() {
-		j, e := uut.AcquireJob(ctx, a.orgID, a.workerID, a.pt, a.tags)
+		j, e := uut.AcquireJob(ctx, a.orgIDs, a.workerID, a.pt, a.tags)
 		a.ec <- e
 		a.jc <- j
 	}()
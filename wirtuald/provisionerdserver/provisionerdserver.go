@@ -303,7 +303,7 @@ func (s *server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Acquire
 	// database.
 	acqCtx, acqCancel := context.WithTimeout(ctx, s.acquireJobLongPollDur)
 	defer acqCancel()
-	job, err := s.Acquirer.AcquireJob(acqCtx, s.OrganizationID, s.ID, s.Provisioners, s.Tags)
+	job, err := s.Acquirer.AcquireJob(acqCtx, []uuid.UUID{s.OrganizationID}, s.ID, s.Provisioners, s.Tags)
 	if xerrors.Is(err, context.DeadlineExceeded) {
 		s.Logger.Debug(ctx, "successful cancel")
 		return &proto.AcquiredJob{}, nil
@@ -340,7 +340,7 @@ func (s *server) AcquireJobWithCancel(stream proto.DRPCProvisionerDaemon_Acquire
 	}()
 	jec := make(chan jobAndErr, 1)
 	go func() {
-		job, err := s.Acquirer.AcquireJob(acqCtx, s.OrganizationID, s.ID, s.Provisioners, s.Tags)
+		job, err := s.Acquirer.AcquireJob(acqCtx, []uuid.UUID{s.OrganizationID}, s.ID, s.Provisioners, s.Tags)
 		jec <- jobAndErr{job: job, err: err}
 	}()
 	var recvErr error
@@ -491,7 +491,7 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 		}
 		ownerGroups, err := s.Database.GetGroups(ctx, database.GetGroupsParams{
 			HasMemberID:    owner.ID,
-			OrganizationID: s.OrganizationID,
+			OrganizationID: job.OrganizationID,
 		})
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("get owner group names: %s", err))
@@ -545,53 +545,9 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 			return nil, failJob(fmt.Sprintf("get workspace build parameters: %s", err))
 		}
 
-		dbExternalAuthProviders := []database.ExternalAuthProvider{}
-		err = json.Unmarshal(templateVersion.ExternalAuthProviders, &dbExternalAuthProviders)
+		externalAuthProviders, err := s.workspaceExternalAuthProviders(ctx, templateVersion, owner.ID, workspace.ID)
 		if err != nil {
-			return nil, xerrors.Errorf("failed to deserialize external_auth_providers value: %w", err)
-		}
-
-		externalAuthProviders := make([]*sdkproto.ExternalAuthProvider, 0, len(dbExternalAuthProviders))
-		for _, p := range dbExternalAuthProviders {
-			link, err := s.Database.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
-				ProviderID: p.ID,
-				UserID:     owner.ID,
-			})
-			if errors.Is(err, sql.ErrNoRows) {
-				continue
-			}
-			if err != nil {
-				return nil, failJob(fmt.Sprintf("acquire external auth link: %s", err))
-			}
-			var config *externalauth.Config
-			for _, c := range s.ExternalAuthConfigs {
-				if c.ID != p.ID {
-					continue
-				}
-				config = c
-				break
-			}
-			// We weren't able to find a matching config for the ID!
-			if config == nil {
-				s.Logger.Warn(ctx, "workspace build job is missing external auth provider",
-					slog.F("provider_id", p.ID),
-					slog.F("template_version_id", templateVersion.ID),
-					slog.F("workspace_id", workspaceBuild.WorkspaceID))
-				continue
-			}
-
-			refreshed, err := config.RefreshToken(ctx, s.Database, link)
-			if err != nil && !externalauth.IsInvalidTokenError(err) {
-				return nil, failJob(fmt.Sprintf("refresh external auth link %q: %s", p.ID, err))
-			}
-			if err != nil {
-				// Invalid tokens are skipped
-				continue
-			}
-			externalAuthProviders = append(externalAuthProviders, &sdkproto.ExternalAuthProvider{
-				Id:          p.ID,
-				AccessToken: refreshed.OAuthAccessToken,
-			})
+			return nil, failJob(err.Error())
 		}
 
 		protoJob.Type = &proto.AcquiredJob_WorkspaceBuild_{
@@ -625,6 +581,87 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 				LogLevel: input.LogLevel,
 			},
 		}
+		protoJob.TimeoutMs = time.Duration(template.JobTimeout).Milliseconds()
+		// nolint:gosec // The number of retries is validated at startup.
+		protoJob.MaxRetries = int32(s.DeploymentValues.Provisioner.JobRetries.Value())
+		protoJob.RetryBackoffMs = s.DeploymentValues.Provisioner.JobRetryBackoff.Value().Milliseconds()
+		protoJob.RetryableErrors = s.DeploymentValues.Provisioner.RetryableErrors.Value()
+	case database.ProvisionerJobTypeWorkspaceDriftCheck:
+		var input WorkspaceDriftCheckJob
+		err = json.Unmarshal(job.Input, &input)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("unmarshal job input %q: %s", job.Input, err))
+		}
+		workspaceBuild, err := s.Database.GetWorkspaceBuildByID(ctx, input.WorkspaceBuildID)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("get workspace build: %s", err))
+		}
+		workspace, err := s.Database.GetWorkspaceByID(ctx, workspaceBuild.WorkspaceID)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("get workspace: %s", err))
+		}
+		templateVersion, err := s.Database.GetTemplateVersionByID(ctx, workspaceBuild.TemplateVersionID)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("get template version: %s", err))
+		}
+		templateVariables, err := s.Database.GetTemplateVersionVariables(ctx, templateVersion.ID)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return nil, failJob(fmt.Sprintf("get template version variables: %s", err))
+		}
+		template, err := s.Database.GetTemplateByID(ctx, templateVersion.TemplateID.UUID)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("get template: %s", err))
+		}
+		owner, err := s.Database.GetUserByID(ctx, workspace.OwnerID)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("get owner: %s", err))
+		}
+		workspaceBuildParameters, err := s.Database.GetWorkspaceBuildParameters(ctx, workspaceBuild.ID)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("get workspace build parameters: %s", err))
+		}
+		// Providers may authenticate with the tokens of the owner to read
+		// the resources.
+		externalAuthProviders, err := s.workspaceExternalAuthProviders(ctx, templateVersion, owner.ID, workspace.ID)
+		if err != nil {
+			return nil, failJob(err.Error())
+		}
+		var workspaceOwnerOIDCAccessToken string
+		if s.OIDCConfig != nil {
+			workspaceOwnerOIDCAccessToken, err = obtainOIDCAccessToken(ctx, s.Database, s.OIDCConfig, owner.ID)
+			if err != nil {
+				return nil, failJob(fmt.Sprintf("obtain OIDC access token: %s", err))
+			}
+		}
+
+		// A refresh-only plan never changes the workspace, so the session
+		// token of the running agent is left alone.
+		protoJob.Type = &proto.AcquiredJob_WorkspaceDriftCheck_{
+			WorkspaceDriftCheck: &proto.AcquiredJob_WorkspaceDriftCheck{
+				WorkspaceBuildId:      workspaceBuild.ID.String(),
+				State:                 workspaceBuild.ProvisionerState,
+				RichParameterValues:   convertRichParameterValues(workspaceBuildParameters),
+				VariableValues:        asVariableValues(templateVariables),
+				ExternalAuthProviders: externalAuthProviders,
+				Metadata: &sdkproto.Metadata{
+					CoderUrl:                      s.AccessURL.String(),
+					WorkspaceTransition:           sdkproto.WorkspaceTransition_START,
+					WorkspaceName:                 workspace.Name,
+					WorkspaceOwner:                owner.Username,
+					WorkspaceOwnerEmail:           owner.Email,
+					WorkspaceOwnerName:            owner.Name,
+					WorkspaceOwnerOidcAccessToken: workspaceOwnerOIDCAccessToken,
+					WorkspaceId:                   workspace.ID.String(),
+					WorkspaceOwnerId:              owner.ID.String(),
+					TemplateId:                    template.ID.String(),
+					TemplateName:                  template.Name,
+					TemplateVersion:               templateVersion.Name,
+					WorkspaceBuildId:              workspaceBuild.ID.String(),
+					WorkspaceOwnerLoginType:       string(owner.LoginType),
+				},
+			},
+		}
+		protoJob.TimeoutMs = time.Duration(template.JobTimeout).Milliseconds()
 	case database.ProvisionerJobTypeTemplateVersionDryRun:
 		var input TemplateVersionDryRunJob
 		err = json.Unmarshal(job.Input, &input)
@@ -689,6 +726,60 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 	return protoJob, err
 }
 
+// workspaceExternalAuthProviders returns fresh access tokens of the external
+// auth providers that the template version requires and the owner has linked.
+func (s *server) workspaceExternalAuthProviders(ctx context.Context, templateVersion database.TemplateVersion, ownerID, workspaceID uuid.UUID) ([]*sdkproto.ExternalAuthProvider, error) {
+	dbExternalAuthProviders := []database.ExternalAuthProvider{}
+	err := json.Unmarshal(templateVersion.ExternalAuthProviders, &dbExternalAuthProviders)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to deserialize external_auth_providers value: %w", err)
+	}
+
+	externalAuthProviders := make([]*sdkproto.ExternalAuthProvider, 0, len(dbExternalAuthProviders))
+	for _, p := range dbExternalAuthProviders {
+		link, err := s.Database.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
+			ProviderID: p.ID,
+			UserID:     ownerID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("acquire external auth link: %w", err)
+		}
+		var config *externalauth.Config
+		for _, c := range s.ExternalAuthConfigs {
+			if c.ID != p.ID {
+				continue
+			}
+			config = c
+			break
+		}
+		// We weren't able to find a matching config for the ID!
+		if config == nil {
+			s.Logger.Warn(ctx, "workspace job is missing external auth provider",
+				slog.F("provider_id", p.ID),
+				slog.F("template_version_id", templateVersion.ID),
+				slog.F("workspace_id", workspaceID))
+			continue
+		}
+
+		refreshed, err := config.RefreshToken(ctx, s.Database, link)
+		if err != nil && !externalauth.IsInvalidTokenError(err) {
+			return nil, xerrors.Errorf("refresh external auth link %q: %w", p.ID, err)
+		}
+		if err != nil {
+			// Invalid tokens are skipped
+			continue
+		}
+		externalAuthProviders = append(externalAuthProviders, &sdkproto.ExternalAuthProvider{
+			Id:          p.ID,
+			AccessToken: refreshed.OAuthAccessToken,
+		})
+	}
+	return externalAuthProviders, nil
+}
+
 func (s *server) includeLastVariableValues(ctx context.Context, templateVersionID uuid.UUID, userVariableValues []wirtualsdk.VariableValue) ([]wirtualsdk.VariableValue, error) {
 	var values []wirtualsdk.VariableValue
 	values = append(values, userVariableValues...)
@@ -976,7 +1067,7 @@ func (s *server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*proto.
 		Valid:  failJob.ErrorCode != "",
 	}
 
-	err = s.Database.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+	err = s.updateJobWithComplete(ctx, s.Database, failJob.Engine, database.UpdateProvisionerJobWithCompleteByIDParams{
 		ID:          jobID,
 		CompletedAt: job.CompletedAt,
 		UpdatedAt:   s.timeNow(),
@@ -1037,6 +1128,10 @@ func (s *server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*proto.
 			return nil, err
 		}
 
+		// Every attempt of a retried build is kept in the timeline, so the
+		// timings of a failed build are stored as well.
+		s.insertJobTimings(ctx, jobID, jobType.WorkspaceBuild.Timings)
+
 		s.notifyWorkspaceBuildFailed(ctx, workspace, build)
 
 		msg, err := json.Marshal(wspubsub.WorkspaceEvent{
@@ -1051,6 +1146,9 @@ func (s *server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*proto.
 			return nil, xerrors.Errorf("publish workspace update: %w", err)
 		}
 	case *proto.FailedJob_TemplateImport_:
+	case *proto.FailedJob_WorkspaceDriftCheck_:
+		// The previous drift of the build is kept, and the build is
+		// checked again after the next interval.
 	}
 
 	// if failed job is a workspace build, audit the outcome
@@ -1224,6 +1322,26 @@ func (s *server) prepareForNotifyWorkspaceManualBuildFailed(ctx context.Context,
 	return templateAdmins, template, templateVersion, workspaceOwner, nil
 }
 
+// updateJobWithComplete marks the job as completed and records the
+// infrastructure as code engine that ran it, in one transaction. Older
+// provisioners don't report an engine, in which case none is stored.
+func (s *server) updateJobWithComplete(ctx context.Context, db database.Store, engine *sdkproto.Engine, arg database.UpdateProvisionerJobWithCompleteByIDParams) error {
+	return db.InTx(func(db database.Store) error {
+		if engine != nil && engine.Name != "" {
+			err := db.UpdateProvisionerJobEngineByID(ctx, database.UpdateProvisionerJobEngineByIDParams{
+				ID:            arg.ID,
+				UpdatedAt:     arg.UpdatedAt,
+				Engine:        engine.Name,
+				EngineVersion: engine.Version,
+			})
+			if err != nil {
+				return xerrors.Errorf("update provisioner job engine: %w", err)
+			}
+		}
+		return db.UpdateProvisionerJobWithCompleteByID(ctx, arg)
+	}, nil)
+}
+
 // CompleteJob is triggered by a provision daemon to mark a provisioner job as completed.
 func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob) (*proto.Empty, error) {
 	ctx, span := s.startTrace(ctx, tracing.FuncName())
@@ -1394,7 +1512,7 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 			return nil, xerrors.Errorf("update template version external auth providers: %w", err)
 		}
 
-		err = s.Database.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		err = s.updateJobWithComplete(ctx, s.Database, completed.Engine, database.UpdateProvisionerJobWithCompleteByIDParams{
 			ID:        jobID,
 			UpdatedAt: s.timeNow(),
 			CompletedAt: sql.NullTime{
@@ -1451,7 +1569,7 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 				return xerrors.Errorf("calculate auto stop: %w", err)
 			}
 
-			err = db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+			err = s.updateJobWithComplete(ctx, db, completed.Engine, database.UpdateProvisionerJobWithCompleteByIDParams{
 				ID:        jobID,
 				UpdatedAt: now,
 				CompletedAt: sql.NullTime{
@@ -1578,34 +1696,7 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 		}
 
 		// Insert timings outside transaction since it is metadata.
-		// nolint:exhaustruct // The other fields are set further down.
-		params := database.InsertProvisionerJobTimingsParams{
-			JobID: jobID,
-		}
-		for _, t := range completed.GetWorkspaceBuild().GetTimings() {
-			if t.Start == nil || t.End == nil {
-				s.Logger.Warn(ctx, "timings entry has nil start or end time", slog.F("entry", t.String()))
-				continue
-			}
-
-			var stg database.ProvisionerJobTimingStage
-			if err := stg.Scan(t.Stage); err != nil {
-				s.Logger.Warn(ctx, "failed to parse timings stage, skipping", slog.F("value", t.Stage))
-				continue
-			}
-
-			params.Stage = append(params.Stage, stg)
-			params.Source = append(params.Source, t.Source)
-			params.Resource = append(params.Resource, t.Resource)
-			params.Action = append(params.Action, t.Action)
-			params.StartedAt = append(params.StartedAt, t.Start.AsTime())
-			params.EndedAt = append(params.EndedAt, t.End.AsTime())
-		}
-		_, err = s.Database.InsertProvisionerJobTimings(ctx, params)
-		if err != nil {
-			// Don't fail the transaction for non-critical data.
-			s.Logger.Warn(ctx, "failed to update provisioner job timings", slog.F("job_id", jobID), slog.Error(err))
-		}
+		s.insertJobTimings(ctx, jobID, completed.GetWorkspaceBuild().GetTimings())
 
 		// audit the outcome of the workspace build
 		if getWorkspaceError == nil {
@@ -1691,7 +1782,7 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 			}
 		}
 
-		err = s.Database.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		err = s.updateJobWithComplete(ctx, s.Database, completed.Engine, database.UpdateProvisionerJobWithCompleteByIDParams{
 			ID:        jobID,
 			UpdatedAt: s.timeNow(),
 			CompletedAt: sql.NullTime{
@@ -1706,6 +1797,91 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 		}
 		s.Logger.Debug(ctx, "marked template dry-run job as completed", slog.F("job_id", jobID))
 
+	case *proto.CompletedJob_WorkspaceDriftCheck_:
+		var input WorkspaceDriftCheckJob
+		err = json.Unmarshal(job.Input, &input)
+		if err != nil {
+			return nil, xerrors.Errorf("unmarshal job data: %w", err)
+		}
+		resources := convertResourceDrift(jobType.WorkspaceDriftCheck.Drift)
+		resourcesJSON, err := json.Marshal(resources)
+		if err != nil {
+			return nil, xerrors.Errorf("marshal drift: %w", err)
+		}
+
+		var (
+			previous database.WorkspaceDrift
+			drift    database.WorkspaceDrift
+		)
+		err = s.Database.InTx(func(db database.Store) error {
+			previous, err = db.GetWorkspaceDriftByBuildID(ctx, input.WorkspaceBuildID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get workspace drift: %w", err)
+			}
+			// No rows are updated when a newer check of the build has
+			// started, which will record the drift instead.
+			drift, err = db.UpdateWorkspaceDriftByJobID(ctx, database.UpdateWorkspaceDriftByJobIDParams{
+				JobID: jobID,
+				CheckedAt: sql.NullTime{
+					Time:  s.timeNow(),
+					Valid: true,
+				},
+				Resources: resourcesJSON,
+			})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("update workspace drift: %w", err)
+			}
+			err = s.updateJobWithComplete(ctx, db, completed.Engine, database.UpdateProvisionerJobWithCompleteByIDParams{
+				ID:        jobID,
+				UpdatedAt: s.timeNow(),
+				CompletedAt: sql.NullTime{
+					Time:  s.timeNow(),
+					Valid: true,
+				},
+				Error:     sql.NullString{},
+				ErrorCode: sql.NullString{},
+			})
+			if err != nil {
+				return xerrors.Errorf("update provisioner job: %w", err)
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.insertJobTimings(ctx, jobID, jobType.WorkspaceDriftCheck.Timings)
+		s.Logger.Debug(ctx, "marked workspace drift check job as completed",
+			slog.F("job_id", jobID),
+			slog.F("drifted_resources", len(resources)),
+		)
+
+		if drift.JobID != jobID {
+			break
+		}
+		workspaceBuild, err := s.Database.GetWorkspaceBuildByID(ctx, input.WorkspaceBuildID)
+		if err != nil {
+			return nil, xerrors.Errorf("get workspace build: %w", err)
+		}
+		workspace, err := s.Database.GetWorkspaceByID(ctx, workspaceBuild.WorkspaceID)
+		if err != nil {
+			return nil, xerrors.Errorf("get workspace: %w", err)
+		}
+		if driftChanged(previous, resources) {
+			s.notifyWorkspaceDrift(ctx, workspace, resources)
+		}
+
+		msg, err := json.Marshal(wspubsub.WorkspaceEvent{
+			Kind:        wspubsub.WorkspaceEventKindStateChange,
+			WorkspaceID: workspace.ID,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("marshal workspace update event: %s", err)
+		}
+		err = s.Pubsub.Publish(wspubsub.WorkspaceEventChannel(workspace.OwnerID), msg)
+		if err != nil {
+			return nil, xerrors.Errorf("publish workspace update: %w", err)
+		}
+
 	default:
 		if completed.Type == nil {
 			return nil, xerrors.Errorf("type payload must be provided")
@@ -1728,6 +1904,103 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 	return &proto.Empty{}, nil
 }
 
+// insertJobTimings stores the timings of a finished job. Timings are
+// metadata, so failures are logged instead of failing the job.
+func (s *server) insertJobTimings(ctx context.Context, jobID uuid.UUID, timings []*sdkproto.Timing) {
+	// nolint:exhaustruct // The other fields are set further down.
+	params := database.InsertProvisionerJobTimingsParams{
+		JobID: jobID,
+	}
+	for _, t := range timings {
+		if t.Start == nil || t.End == nil {
+			s.Logger.Warn(ctx, "timings entry has nil start or end time", slog.F("entry", t.String()))
+			continue
+		}
+
+		var stg database.ProvisionerJobTimingStage
+		if err := stg.Scan(t.Stage); err != nil {
+			s.Logger.Warn(ctx, "failed to parse timings stage, skipping", slog.F("value", t.Stage))
+			continue
+		}
+
+		params.Stage = append(params.Stage, stg)
+		params.Source = append(params.Source, t.Source)
+		params.Resource = append(params.Resource, t.Resource)
+		params.Action = append(params.Action, t.Action)
+		params.StartedAt = append(params.StartedAt, t.Start.AsTime())
+		params.EndedAt = append(params.EndedAt, t.End.AsTime())
+	}
+	_, err := s.Database.InsertProvisionerJobTimings(ctx, params)
+	if err != nil {
+		s.Logger.Warn(ctx, "failed to update provisioner job timings", slog.F("job_id", jobID), slog.Error(err))
+	}
+}
+
+func (s *server) notifyWorkspaceDrift(ctx context.Context, workspace database.Workspace, resources []wirtualsdk.WorkspaceDriftResource) {
+	addresses := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		addresses = append(addresses, resource.Address)
+	}
+
+	if _, err := s.NotificationsEnqueuer.Enqueue(ctx, workspace.OwnerID, notifications.TemplateWorkspaceDriftDetected,
+		map[string]string{
+			"name":      workspace.Name,
+			"resources": strings.Join(addresses, ", "),
+		}, "provisionerdserver",
+		// Associate this notification with all the related entities.
+		workspace.ID, workspace.OwnerID, workspace.TemplateID, workspace.OrganizationID,
+	); err != nil {
+		s.Logger.Warn(ctx, "failed to notify of workspace drift", slog.Error(err))
+	}
+}
+
+// driftChanged reports whether a check found drifted resources that the
+// previous check of the build didn't. Only the addresses are compared, since
+// some attributes change on every refresh.
+func driftChanged(previous database.WorkspaceDrift, resources []wirtualsdk.WorkspaceDriftResource) bool {
+	if len(resources) == 0 {
+		return false
+	}
+	var previousResources []wirtualsdk.WorkspaceDriftResource
+	if previous.CheckedAt.Valid {
+		// The previous drift is only used for comparison, so it's fine to
+		// ignore malformed values.
+		_ = json.Unmarshal(previous.Resources, &previousResources)
+	}
+	seen := make(map[string]struct{}, len(previousResources))
+	for _, resource := range previousResources {
+		seen[resource.Address] = struct{}{}
+	}
+	for _, resource := range resources {
+		if _, ok := seen[resource.Address]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+func convertResourceDrift(drift []*sdkproto.ResourceDrift) []wirtualsdk.WorkspaceDriftResource {
+	resources := make([]wirtualsdk.WorkspaceDriftResource, 0, len(drift))
+	for _, resource := range drift {
+		attributes := make([]wirtualsdk.WorkspaceDriftAttribute, 0, len(resource.Attributes))
+		for _, attribute := range resource.Attributes {
+			attributes = append(attributes, wirtualsdk.WorkspaceDriftAttribute{
+				Name:      attribute.Name,
+				Before:    attribute.Before,
+				After:     attribute.After,
+				Sensitive: attribute.Sensitive,
+			})
+		}
+		resources = append(resources, wirtualsdk.WorkspaceDriftResource{
+			Address:    resource.Address,
+			Type:       resource.Type,
+			Action:     wirtualsdk.WorkspaceDriftAction(resource.Action),
+			Attributes: attributes,
+		})
+	}
+	return resources
+}
+
 func (s *server) notifyWorkspaceDeleted(ctx context.Context, workspace database.Workspace, build database.WorkspaceBuild) {
 	var reason string
 	initiator := build.InitiatorByUsername
@@ -1918,12 +2191,20 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 		scriptStartBlocksLogin := make([]bool, 0, len(prAgent.Scripts))
 		scriptRunOnStart := make([]bool, 0, len(prAgent.Scripts))
 		scriptRunOnStop := make([]bool, 0, len(prAgent.Scripts))
+		scriptReadyPort := make([]int32, 0, len(prAgent.Scripts))
+		scriptReadyFile := make([]string, 0, len(prAgent.Scripts))
+		scriptReadyTimeout := make([]int32, 0, len(prAgent.Scripts))
+		scriptIDsByAddress := make(map[string]uuid.UUID, len(prAgent.Scripts))
 
 		for _, script := range prAgent.Scripts {
+			scriptID := uuid.New()
+			if script.Address != "" {
+				scriptIDsByAddress[script.Address] = scriptID
+			}
 			logSourceIDs = append(logSourceIDs, uuid.New())
 			logSourceDisplayNames = append(logSourceDisplayNames, script.DisplayName)
 			logSourceIcons = append(logSourceIcons, script.Icon)
-			scriptIDs = append(scriptIDs, uuid.New())
+			scriptIDs = append(scriptIDs, scriptID)
 			scriptDisplayName = append(scriptDisplayName, script.DisplayName)
 			scriptLogPaths = append(scriptLogPaths, script.LogPath)
 			scriptSources = append(scriptSources, script.Script)
@@ -1932,6 +2213,32 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 			scriptStartBlocksLogin = append(scriptStartBlocksLogin, script.StartBlocksLogin)
 			scriptRunOnStart = append(scriptRunOnStart, script.RunOnStart)
 			scriptRunOnStop = append(scriptRunOnStop, script.RunOnStop)
+			scriptReadyPort = append(scriptReadyPort, script.GetReady().GetPort())
+			scriptReadyFile = append(scriptReadyFile, script.GetReady().GetFile())
+			scriptReadyTimeout = append(scriptReadyTimeout, script.GetReady().GetTimeoutSeconds())
+		}
+
+		// Resolve the dependencies between scripts to their IDs, and refuse
+		// cycles so they never reach the agent.
+		scriptDependencies := make([]wirtualsdk.WorkspaceAgentScript, 0, len(prAgent.Scripts))
+		for i, script := range prAgent.Scripts {
+			dependsOn := make([]uuid.UUID, 0, len(script.DependsOn))
+			for _, address := range script.DependsOn {
+				dependencyID, ok := scriptIDsByAddress[address]
+				if !ok {
+					return xerrors.Errorf("script %q depends on %q, which is not a script of agent %q", script.DisplayName, address, prAgent.Name)
+				}
+				dependsOn = append(dependsOn, dependencyID)
+			}
+			scriptDependencies = append(scriptDependencies, wirtualsdk.WorkspaceAgentScript{
+				ID:          scriptIDs[i],
+				DisplayName: script.DisplayName,
+				DependsOn:   dependsOn,
+			})
+		}
+		err = wirtualsdk.ValidateWorkspaceAgentScriptDependencies(scriptDependencies)
+		if err != nil {
+			return xerrors.Errorf("agent %q: %w", prAgent.Name, err)
 		}
 
 		_, err = db.InsertWorkspaceAgentLogSources(ctx, database.InsertWorkspaceAgentLogSourcesParams{
@@ -1946,22 +2253,123 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 		}
 
 		_, err = db.InsertWorkspaceAgentScripts(ctx, database.InsertWorkspaceAgentScriptsParams{
-			WorkspaceAgentID: agentID,
-			LogSourceID:      logSourceIDs,
-			LogPath:          scriptLogPaths,
-			CreatedAt:        dbtime.Now(),
-			Script:           scriptSources,
-			Cron:             scriptCron,
-			TimeoutSeconds:   scriptTimeout,
-			StartBlocksLogin: scriptStartBlocksLogin,
-			RunOnStart:       scriptRunOnStart,
-			RunOnStop:        scriptRunOnStop,
-			DisplayName:      scriptDisplayName,
-			ID:               scriptIDs,
+			WorkspaceAgentID:    agentID,
+			LogSourceID:         logSourceIDs,
+			LogPath:             scriptLogPaths,
+			CreatedAt:           dbtime.Now(),
+			Script:              scriptSources,
+			Cron:                scriptCron,
+			TimeoutSeconds:      scriptTimeout,
+			StartBlocksLogin:    scriptStartBlocksLogin,
+			RunOnStart:          scriptRunOnStart,
+			RunOnStop:           scriptRunOnStop,
+			DisplayName:         scriptDisplayName,
+			ID:                  scriptIDs,
+			ReadyPort:           scriptReadyPort,
+			ReadyFile:           scriptReadyFile,
+			ReadyTimeoutSeconds: scriptReadyTimeout,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert agent scripts: %w", err)
 		}
+		for _, script := range scriptDependencies {
+			if len(script.DependsOn) == 0 {
+				continue
+			}
+			err = db.UpdateWorkspaceAgentScriptDependsOnByID(ctx, database.UpdateWorkspaceAgentScriptDependsOnByIDParams{
+				ID:        script.ID,
+				DependsOn: script.DependsOn,
+			})
+			if err != nil {
+				return xerrors.Errorf("update agent script dependencies: %w", err)
+			}
+		}
+
+		if len(prAgent.DnsRecords) > 0 {
+			dnsRecordNames := make([]string, 0, len(prAgent.DnsRecords))
+			dnsRecordPorts := make([]int32, 0, len(prAgent.DnsRecords))
+			for _, record := range prAgent.DnsRecords {
+				if !provisioner.DNSRecordNameRegex.MatchString(record.Name) {
+					return xerrors.Errorf("dns record name %q does not match regex %q", record.Name, provisioner.DNSRecordNameRegex.String())
+				}
+				if slices.Contains(dnsRecordNames, record.Name) {
+					return xerrors.Errorf("duplicate dns record, must be unique per agent: %q", record.Name)
+				}
+				if record.Port < 0 || record.Port > 65535 {
+					return xerrors.Errorf("dns record %q has invalid port %d", record.Name, record.Port)
+				}
+				dnsRecordNames = append(dnsRecordNames, record.Name)
+				dnsRecordPorts = append(dnsRecordPorts, record.Port)
+			}
+			_, err = db.InsertWorkspaceAgentDNSRecords(ctx, database.InsertWorkspaceAgentDNSRecordsParams{
+				WorkspaceAgentID: agentID,
+				CreatedAt:        dbtime.Now(),
+				Name:             dnsRecordNames,
+				Port:             dnsRecordPorts,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert agent dns records: %w", err)
+			}
+		}
+
+		serviceNames := make(map[string]struct{}, len(prAgent.Services))
+		for _, service := range prAgent.Services {
+			if !provisioner.AppSlugRegex.MatchString(service.Name) {
+				return xerrors.Errorf("service name %q does not match regex %q", service.Name, provisioner.AppSlugRegex.String())
+			}
+			if _, exists := serviceNames[service.Name]; exists {
+				return xerrors.Errorf("duplicate service name, must be unique per agent: %q", service.Name)
+			}
+			serviceNames[service.Name] = struct{}{}
+
+			var restartPolicy database.WorkspaceAgentServiceRestartPolicy
+			switch service.RestartPolicy {
+			case sdkproto.ServiceRestartPolicy_ALWAYS:
+				restartPolicy = database.WorkspaceAgentServiceRestartPolicyAlways
+			case sdkproto.ServiceRestartPolicy_ON_FAILURE:
+				restartPolicy = database.WorkspaceAgentServiceRestartPolicyOnFailure
+			case sdkproto.ServiceRestartPolicy_NEVER:
+				restartPolicy = database.WorkspaceAgentServiceRestartPolicyNever
+			default:
+				return xerrors.Errorf("unknown restart policy %q for service %q", service.RestartPolicy, service.Name)
+			}
+
+			env := make(map[string]string, len(service.Env))
+			for _, e := range service.Env {
+				env[e.Name] = e.Value
+			}
+			envJSON, err := json.Marshal(env)
+			if err != nil {
+				return xerrors.Errorf("marshal service env: %w", err)
+			}
+
+			// Provisioners other than Terraform may leave the display name
+			// out, the name is shown instead.
+			displayName := service.DisplayName
+			if displayName == "" {
+				displayName = service.Name
+			}
+
+			_, err = db.InsertWorkspaceAgentService(ctx, database.InsertWorkspaceAgentServiceParams{
+				ID:                       uuid.New(),
+				WorkspaceAgentID:         agentID,
+				CreatedAt:                dbtime.Now(),
+				Name:                     service.Name,
+				DisplayName:              displayName,
+				Command:                  service.Command,
+				Directory:                service.Dir,
+				Env:                      envJSON,
+				RestartPolicy:            restartPolicy,
+				RestartBackoffSeconds:    service.RestartBackoffSeconds,
+				MaxRestartBackoffSeconds: service.MaxRestartBackoffSeconds,
+				HealthcheckUrl:           service.Healthcheck.GetUrl(),
+				HealthcheckInterval:      service.Healthcheck.GetInterval(),
+				HealthcheckThreshold:     service.Healthcheck.GetThreshold(),
+			})
+			if err != nil {
+				return xerrors.Errorf("insert agent service %q: %w", service.Name, err)
+			}
+		}
 
 		for _, app := range prAgent.Apps {
 			slug := app.Slug
@@ -1980,9 +2388,21 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 			if app.Healthcheck == nil {
 				app.Healthcheck = &sdkproto.Healthcheck{}
 			}
-			if app.Healthcheck.Url != "" {
+			if app.Healthcheck.Url != "" || app.Healthcheck.Tcp != "" || app.Healthcheck.Command != "" {
 				health = database.WorkspaceAppHealthInitializing
 			}
+			expectedStatuses := app.Healthcheck.ExpectedStatuses
+			if expectedStatuses == nil {
+				expectedStatuses = []int32{}
+			}
+			headers := app.Healthcheck.Headers
+			if headers == nil {
+				headers = map[string]string{}
+			}
+			headersJSON, err := json.Marshal(headers)
+			if err != nil {
+				return xerrors.Errorf("marshal app %q healthcheck headers: %w", slug, err)
+			}
 
 			sharingLevel := database.AppSharingLevelOwner
 			switch app.SharingLevel {
@@ -2007,15 +2427,20 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 					String: app.Url,
 					Valid:  app.Url != "",
 				},
-				External:             app.External,
-				Subdomain:            app.Subdomain,
-				SharingLevel:         sharingLevel,
-				HealthcheckUrl:       app.Healthcheck.Url,
-				HealthcheckInterval:  app.Healthcheck.Interval,
-				HealthcheckThreshold: app.Healthcheck.Threshold,
-				Health:               health,
-				DisplayOrder:         int32(app.Order),
-				Hidden:               app.Hidden,
+				External:                    app.External,
+				Subdomain:                   app.Subdomain,
+				SharingLevel:                sharingLevel,
+				HealthcheckUrl:              app.Healthcheck.Url,
+				HealthcheckInterval:         app.Healthcheck.Interval,
+				HealthcheckThreshold:        app.Healthcheck.Threshold,
+				HealthcheckTcp:              app.Healthcheck.Tcp,
+				HealthcheckCommand:          app.Healthcheck.Command,
+				HealthcheckExpectedStatuses: expectedStatuses,
+				HealthcheckBodyRegex:        app.Healthcheck.BodyRegex,
+				HealthcheckHeaders:          headersJSON,
+				Health:                      health,
+				DisplayOrder:                int32(app.Order),
+				Hidden:                      app.Hidden,
 			})
 			if err != nil {
 				return xerrors.Errorf("insert app: %w", err)
@@ -2248,6 +2673,11 @@ type TemplateVersionDryRunJob struct {
 	RichParameterValues []database.WorkspaceBuildParameter `json:"rich_parameter_values"`
 }
 
+// WorkspaceDriftCheckJob is the payload for the "workspace_drift_check" job type.
+type WorkspaceDriftCheckJob struct {
+	WorkspaceBuildID uuid.UUID `json:"workspace_build_id"`
+}
+
 func asVariableValues(templateVariables []database.TemplateVersionVariable) []*sdkproto.VariableValue {
 	var apiVariableValues []*sdkproto.VariableValue
 	for _, v := range templateVariables {
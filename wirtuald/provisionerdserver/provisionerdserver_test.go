@@ -158,6 +158,7 @@ func TestAcquireJob(t *testing.T) {
 				Provisioner:    database.ProvisionerTypeEcho,
 				StorageMethod:  database.ProvisionerStorageMethodFile,
 				Type:           database.ProvisionerJobTypeTemplateVersionDryRun,
+				Priority:       database.ProvisionerJobPriorityInteractive,
 			})
 			require.NoError(t, err)
 			_, err = tc.acquire(ctx, srv)
@@ -573,6 +574,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.UpdateJob(ctx, &proto.UpdateJobRequest{
@@ -589,6 +591,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -611,6 +614,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -832,6 +836,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -855,6 +860,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -907,6 +913,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		err = db.InsertWorkspaceBuild(ctx, database.InsertWorkspaceBuildParams{
@@ -999,10 +1006,11 @@ func TestCompleteJob(t *testing.T) {
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
 			OrganizationID: pd.OrganizationID,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-			OrganizationID: pd.OrganizationID,
+			OrganizationIDs: []uuid.UUID{pd.OrganizationID},
 			WorkerID: uuid.NullUUID{
 				UUID:  uuid.New(),
 				Valid: true,
@@ -1034,10 +1042,11 @@ func TestCompleteJob(t *testing.T) {
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
 			OrganizationID: pd.OrganizationID,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-			OrganizationID: pd.OrganizationID,
+			OrganizationIDs: []uuid.UUID{pd.OrganizationID},
 			WorkerID: uuid.NullUUID{
 				UUID:  pd.ID,
 				Valid: true,
@@ -1091,10 +1100,11 @@ func TestCompleteJob(t *testing.T) {
 			Input:          []byte(`{"template_version_id": "` + versionID.String() + `"}`),
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-			OrganizationID: pd.OrganizationID,
+			OrganizationIDs: []uuid.UUID{pd.OrganizationID},
 			WorkerID: uuid.NullUUID{
 				UUID:  pd.ID,
 				Valid: true,
@@ -1316,7 +1326,7 @@ func TestCompleteJob(t *testing.T) {
 					OrganizationID: pd.OrganizationID,
 				})
 				_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-					OrganizationID: pd.OrganizationID,
+					OrganizationIDs: []uuid.UUID{pd.OrganizationID},
 					WorkerID: uuid.NullUUID{
 						UUID:  pd.ID,
 						Valid: true,
@@ -1403,6 +1413,7 @@ func TestCompleteJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -1428,6 +1439,127 @@ func TestCompleteJob(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("WorkspaceDriftCheck", func(t *testing.T) {
+		t.Parallel()
+		notifEnq := &notificationstest.FakeEnqueuer{}
+		srv, db, ps, pd := setup(t, false, &overrides{notificationEnqueuer: notifEnq})
+
+		user := dbgen.User(t, db, database.User{})
+		template := dbgen.Template(t, db, database.Template{
+			Name:           "template",
+			Provisioner:    database.ProvisionerTypeEcho,
+			OrganizationID: pd.OrganizationID,
+			CreatedBy:      user.ID,
+		})
+		version := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: pd.OrganizationID,
+			TemplateID: uuid.NullUUID{
+				UUID:  template.ID,
+				Valid: true,
+			},
+			CreatedBy: user.ID,
+		})
+		workspace := dbgen.Workspace(t, db, database.WorkspaceTable{
+			TemplateID:     template.ID,
+			OwnerID:        user.ID,
+			OrganizationID: pd.OrganizationID,
+		})
+		build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			WorkspaceID:       workspace.ID,
+			TemplateVersionID: version.ID,
+			InitiatorID:       user.ID,
+			Transition:        database.WorkspaceTransitionStart,
+		})
+		job := dbgen.ProvisionerJob(t, db, ps, database.ProvisionerJob{
+			Provisioner:    database.ProvisionerTypeEcho,
+			InitiatorID:    user.ID,
+			Type:           database.ProvisionerJobTypeWorkspaceDriftCheck,
+			StorageMethod:  database.ProvisionerStorageMethodFile,
+			OrganizationID: pd.OrganizationID,
+			Input: must(json.Marshal(provisionerdserver.WorkspaceDriftCheckJob{
+				WorkspaceBuildID: build.ID,
+			})),
+		})
+		err := db.UpsertWorkspaceDriftCheck(ctx, database.UpsertWorkspaceDriftCheckParams{
+			WorkspaceBuildID: build.ID,
+			JobID:            job.ID,
+			CreatedAt:        dbtime.Now(),
+		})
+		require.NoError(t, err)
+		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			OrganizationIDs: []uuid.UUID{pd.OrganizationID},
+			WorkerID: uuid.NullUUID{
+				UUID:  pd.ID,
+				Valid: true,
+			},
+			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+		})
+		require.NoError(t, err)
+
+		_, err = srv.CompleteJob(ctx, &proto.CompletedJob{
+			JobId: job.ID.String(),
+			Type: &proto.CompletedJob_WorkspaceDriftCheck_{
+				WorkspaceDriftCheck: &proto.CompletedJob_WorkspaceDriftCheck{
+					Drift: []*sdkproto.ResourceDrift{{
+						Address: "docker_container.workspace",
+						Type:    "docker_container",
+						Action:  "update",
+					}},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		drift, err := db.GetWorkspaceDriftByBuildID(ctx, build.ID)
+		require.NoError(t, err)
+		require.True(t, drift.CheckedAt.Valid)
+		require.Contains(t, string(drift.Resources), "docker_container.workspace")
+
+		sent := notifEnq.Sent()
+		require.Len(t, sent, 1)
+		require.Equal(t, notifications.TemplateWorkspaceDriftDetected, sent[0].TemplateID)
+		require.Equal(t, user.ID, sent[0].UserID)
+		require.Equal(t, "docker_container.workspace", sent[0].Labels["resources"])
+	})
+
+	t.Run("Engine", func(t *testing.T) {
+		t.Parallel()
+		srv, db, _, pd := setup(t, false, &overrides{})
+		job, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:            uuid.New(),
+			Provisioner:   database.ProvisionerTypeTerraform,
+			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
+		})
+		require.NoError(t, err)
+		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			WorkerID: uuid.NullUUID{
+				UUID:  pd.ID,
+				Valid: true,
+			},
+			Types: []database.ProvisionerType{database.ProvisionerTypeTerraform},
+		})
+		require.NoError(t, err)
+
+		_, err = srv.CompleteJob(ctx, &proto.CompletedJob{
+			JobId: job.ID.String(),
+			Type: &proto.CompletedJob_TemplateDryRun_{
+				TemplateDryRun: &proto.CompletedJob_TemplateDryRun{},
+			},
+			Engine: &sdkproto.Engine{
+				Name:    "opentofu",
+				Version: "1.8.5",
+			},
+		})
+		require.NoError(t, err)
+
+		job, err = db.GetProvisionerJobByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, "opentofu", job.Engine)
+		require.Equal(t, "1.8.5", job.EngineVersion)
+	})
+
 	t.Run("Modules", func(t *testing.T) {
 		t.Parallel()
 
@@ -1489,7 +1621,8 @@ func TestCompleteJob(t *testing.T) {
 					Transition: database.WorkspaceTransitionStart,
 				}},
 				provisionerJobParams: database.InsertProvisionerJobParams{
-					Type: database.ProvisionerJobTypeTemplateVersionDryRun,
+					Type:     database.ProvisionerJobTypeTemplateVersionDryRun,
+					Priority: database.ProvisionerJobPriorityInteractive,
 				},
 			},
 			{
@@ -1529,6 +1662,7 @@ func TestCompleteJob(t *testing.T) {
 					Input: must(json.Marshal(provisionerdserver.TemplateVersionImportJob{
 						TemplateVersionID: templateVersionID,
 					})),
+					Priority: database.ProvisionerJobPriorityInteractive,
 				},
 				expectedResources: []database.WorkspaceResource{{
 					Name: "something",
@@ -1611,6 +1745,7 @@ func TestCompleteJob(t *testing.T) {
 					Input: must(json.Marshal(provisionerdserver.WorkspaceProvisionJob{
 						WorkspaceBuildID: workspaceBuildID,
 					})),
+					Priority: database.ProvisionerJobPriorityInteractive,
 				},
 			},
 		}
@@ -1851,6 +1986,171 @@ func TestInsertWorkspaceResource(t *testing.T) {
 		require.ElementsMatch(t, database.AllDisplayAppValues(), agent.DisplayApps)
 	})
 
+	t.Run("DNSRecords", func(t *testing.T) {
+		t.Parallel()
+		db := dbmem.New()
+		job := uuid.New()
+		err := insert(db, job, &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				DnsRecords: []*sdkproto.DNSRecord{
+					{Name: "api", Port: 8080},
+					{Name: "*.apps"},
+				},
+			}},
+		})
+		require.NoError(t, err)
+		resources, err := db.GetWorkspaceResourcesByJobID(ctx, job)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		agents, err := db.GetWorkspaceAgentsByResourceIDs(ctx, []uuid.UUID{resources[0].ID})
+		require.NoError(t, err)
+		require.Len(t, agents, 1)
+		records, err := db.GetWorkspaceAgentDNSRecordsByAgentIDs(ctx, []uuid.UUID{agents[0].ID})
+		require.NoError(t, err)
+		ports := make(map[string]int32, len(records))
+		for _, record := range records {
+			ports[record.Name] = record.Port
+		}
+		require.Equal(t, map[string]int32{"api": 8080, "*.apps": 0}, ports)
+
+		err = insert(dbmem.New(), uuid.New(), &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				DnsRecords: []*sdkproto.DNSRecord{
+					{Name: "api"},
+					{Name: "api"},
+				},
+			}},
+		})
+		require.ErrorContains(t, err, "duplicate dns record")
+	})
+
+	t.Run("Services", func(t *testing.T) {
+		t.Parallel()
+		db := dbmem.New()
+		job := uuid.New()
+		err := insert(db, job, &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				Services: []*sdkproto.Service{{
+					Name:          "web",
+					DisplayName:   "Web",
+					Command:       "npm run dev",
+					Dir:           "/home/coder/app",
+					Env:           []*sdkproto.Env{{Name: "PORT", Value: "3000"}},
+					RestartPolicy: sdkproto.ServiceRestartPolicy_ON_FAILURE,
+					Healthcheck: &sdkproto.Healthcheck{
+						Url:       "http://localhost:3000",
+						Interval:  5,
+						Threshold: 3,
+					},
+				}},
+			}},
+		})
+		require.NoError(t, err)
+		resources, err := db.GetWorkspaceResourcesByJobID(ctx, job)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		agents, err := db.GetWorkspaceAgentsByResourceIDs(ctx, []uuid.UUID{resources[0].ID})
+		require.NoError(t, err)
+		require.Len(t, agents, 1)
+		services, err := db.GetWorkspaceAgentServicesByAgentIDs(ctx, []uuid.UUID{agents[0].ID})
+		require.NoError(t, err)
+		require.Len(t, services, 1)
+		require.Equal(t, "web", services[0].Name)
+		require.Equal(t, "npm run dev", services[0].Command)
+		require.Equal(t, database.WorkspaceAgentServiceRestartPolicyOnFailure, services[0].RestartPolicy)
+		require.Equal(t, database.WorkspaceAgentServiceStatePending, services[0].State)
+		require.Equal(t, "http://localhost:3000", services[0].HealthcheckUrl)
+		require.JSONEq(t, `{"PORT":"3000"}`, string(services[0].Env))
+
+		err = insert(dbmem.New(), uuid.New(), &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				Services: []*sdkproto.Service{
+					{Name: "web", Command: "true"},
+					{Name: "web", Command: "true"},
+				},
+			}},
+		})
+		require.ErrorContains(t, err, "duplicate service name")
+	})
+
+	t.Run("ScriptDependencies", func(t *testing.T) {
+		t.Parallel()
+		db := dbmem.New()
+		job := uuid.New()
+		err := insert(db, job, &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				Scripts: []*sdkproto.Script{{
+					DisplayName: "Database",
+					Script:      "dockerd &",
+					RunOnStart:  true,
+					Address:     "coder_script.database",
+					Ready: &sdkproto.ScriptReadiness{
+						Port:           5432,
+						TimeoutSeconds: 30,
+					},
+				}, {
+					DisplayName: "Migrate",
+					Script:      "make migrate",
+					RunOnStart:  true,
+					Address:     "coder_script.migrate",
+					DependsOn:   []string{"coder_script.database"},
+				}},
+			}},
+		})
+		require.NoError(t, err)
+		resources, err := db.GetWorkspaceResourcesByJobID(ctx, job)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		agents, err := db.GetWorkspaceAgentsByResourceIDs(ctx, []uuid.UUID{resources[0].ID})
+		require.NoError(t, err)
+		require.Len(t, agents, 1)
+		scripts, err := db.GetWorkspaceAgentScriptsByAgentIDs(ctx, []uuid.UUID{agents[0].ID})
+		require.NoError(t, err)
+		require.Len(t, scripts, 2)
+		scriptsByName := map[string]database.WorkspaceAgentScript{}
+		for _, script := range scripts {
+			scriptsByName[script.Script] = script
+		}
+		dockerd := scriptsByName["dockerd &"]
+		require.EqualValues(t, 5432, dockerd.ReadyPort)
+		require.EqualValues(t, 30, dockerd.ReadyTimeoutSeconds)
+		require.Empty(t, dockerd.DependsOn)
+		require.Equal(t, []uuid.UUID{dockerd.ID}, scriptsByName["make migrate"].DependsOn)
+
+		err = insert(dbmem.New(), uuid.New(), &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				Scripts: []*sdkproto.Script{
+					{DisplayName: "A", Address: "coder_script.a", DependsOn: []string{"coder_script.b"}},
+					{DisplayName: "B", Address: "coder_script.b", DependsOn: []string{"coder_script.a"}},
+				},
+			}},
+		})
+		require.ErrorContains(t, err, "script dependency cycle")
+
+		err = insert(dbmem.New(), uuid.New(), &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				Scripts: []*sdkproto.Script{
+					{DisplayName: "A", Address: "coder_script.a", DependsOn: []string{"coder_script.missing"}},
+				},
+			}},
+		})
+		require.ErrorContains(t, err, "which is not a script of agent")
+	})
+
 	t.Run("DisableDefaultApps", func(t *testing.T) {
 		t.Parallel()
 		db := dbmem.New()
@@ -1962,7 +2262,7 @@ func TestNotifications(t *testing.T) {
 					OrganizationID: pd.OrganizationID,
 				})
 				_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-					OrganizationID: pd.OrganizationID,
+					OrganizationIDs: []uuid.UUID{pd.OrganizationID},
 					WorkerID: uuid.NullUUID{
 						UUID:  pd.ID,
 						Valid: true,
@@ -2082,7 +2382,7 @@ func TestNotifications(t *testing.T) {
 					OrganizationID: pd.OrganizationID,
 				})
 				_, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-					OrganizationID: pd.OrganizationID,
+					OrganizationIDs: []uuid.UUID{pd.OrganizationID},
 					WorkerID: uuid.NullUUID{
 						UUID:  pd.ID,
 						Valid: true,
@@ -2153,9 +2453,9 @@ func TestNotifications(t *testing.T) {
 			OrganizationID: pd.OrganizationID,
 		})
 		_, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
-			OrganizationID: pd.OrganizationID,
-			WorkerID:       uuid.NullUUID{UUID: pd.ID, Valid: true},
-			Types:          []database.ProvisionerType{database.ProvisionerTypeEcho},
+			OrganizationIDs: []uuid.UUID{pd.OrganizationID},
+			WorkerID:        uuid.NullUUID{UUID: pd.ID, Valid: true},
+			Types:           []database.ProvisionerType{database.ProvisionerTypeEcho},
 		})
 		require.NoError(t, err)
 
@@ -236,14 +236,18 @@ func convertProvisionerJobLog(provisionerJobLog database.ProvisionerJobLog) wirt
 func convertProvisionerJob(pj database.GetProvisionerJobsByIDsWithQueuePositionRow) wirtualsdk.ProvisionerJob {
 	provisionerJob := pj.ProvisionerJob
 	job := wirtualsdk.ProvisionerJob{
-		ID:            provisionerJob.ID,
-		CreatedAt:     provisionerJob.CreatedAt,
-		Error:         provisionerJob.Error.String,
-		ErrorCode:     wirtualsdk.JobErrorCode(provisionerJob.ErrorCode.String),
-		FileID:        provisionerJob.FileID,
-		Tags:          provisionerJob.Tags,
-		QueuePosition: int(pj.QueuePosition),
-		QueueSize:     int(pj.QueueSize),
+		ID:                       provisionerJob.ID,
+		CreatedAt:                provisionerJob.CreatedAt,
+		Error:                    provisionerJob.Error.String,
+		ErrorCode:                wirtualsdk.JobErrorCode(provisionerJob.ErrorCode.String),
+		FileID:                   provisionerJob.FileID,
+		Tags:                     provisionerJob.Tags,
+		QueuePosition:            int(pj.QueuePosition),
+		QueueSize:                int(pj.QueueSize),
+		QueueEstimatedWaitMillis: pj.QueueEstimatedWaitMs,
+		Priority:                 wirtualsdk.ProvisionerJobPriority(provisionerJob.Priority),
+		Engine:                   provisionerJob.Engine,
+		EngineVersion:            provisionerJob.EngineVersion,
 	}
 	// Applying values optional to the struct.
 	if provisionerJob.StartedAt.Valid {
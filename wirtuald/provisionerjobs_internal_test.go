@@ -100,6 +100,17 @@ func TestConvertProvisionerJob_Unit(t *testing.T) {
 				Status: wirtualsdk.ProvisionerJobPending,
 			},
 		},
+		{
+			name: "bulk job pending",
+			input: database.ProvisionerJob{
+				Priority:  database.ProvisionerJobPriorityBulk,
+				JobStatus: database.ProvisionerJobStatusPending,
+			},
+			expected: wirtualsdk.ProvisionerJob{
+				Priority: wirtualsdk.ProvisionerJobPriorityBulk,
+				Status:   wirtualsdk.ProvisionerJobPending,
+			},
+		},
 		{
 			name: "job failed",
 			input: database.ProvisionerJob{
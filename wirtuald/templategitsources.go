@@ -0,0 +1,415 @@
+package wirtuald
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onchainengineering/hmi-wirtual/cryptorand"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/gitsshkey"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/gitsync"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpmw"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// maxTemplateGitWebhookBodySize is the largest push payload that is verified.
+// GitHub caps payloads at 25 MB.
+const maxTemplateGitWebhookBodySize = 25 << 20
+
+// @Summary Get template git source
+// @ID get-template-git-source
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} wirtualsdk.TemplateGitSource
+// @Router /templates/{template}/git-source [get]
+func (api *API) templateGitSource(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	source, err := api.Database.GetTemplateGitSourceByTemplateID(ctx, template.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, wirtualsdk.Response{
+			Message: "The template isn't synced from git.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplateGitSource(source))
+}
+
+// @Summary Update template git source
+// @ID update-template-git-source
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body wirtualsdk.UpdateTemplateGitSourceRequest true "Update template git source request"
+// @Success 200 {object} wirtualsdk.TemplateGitSource
+// @Router /templates/{template}/git-source [put]
+func (api *API) putTemplateGitSource(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		apiKey   = httpmw.APIKey(r)
+		template = httpmw.TemplateParam(r)
+	)
+
+	var req wirtualsdk.UpdateTemplateGitSourceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []wirtualsdk.ValidationError
+	repoURL := strings.TrimSpace(req.RepoURL)
+	if err := gitsync.ValidateRepoURL(repoURL); err != nil {
+		validErrs = append(validErrs, wirtualsdk.ValidationError{
+			Field:  "repo_url",
+			Detail: fmt.Sprintf("Invalid repository URL: %s.", err),
+		})
+	} else if strings.HasPrefix(repoURL, "https://") {
+		if req.UseDeployKey {
+			validErrs = append(validErrs, wirtualsdk.ValidationError{
+				Field:  "use_deploy_key",
+				Detail: "A deploy key can only be used with SSH URLs.",
+			})
+		}
+	} else if knownHostsErr := gitsync.ValidateKnownHosts(req.KnownHosts); knownHostsErr != nil {
+		// Host keys are pinned, so that a spoofed host can't serve the
+		// repository or receive the deploy key.
+		validErrs = append(validErrs, wirtualsdk.ValidationError{
+			Field:  "known_hosts",
+			Detail: fmt.Sprintf("SSH URLs require the known hosts of the repository host: %s.", knownHostsErr),
+		})
+	}
+	templatePath := path.Clean("/" + strings.TrimSpace(req.Path))[1:]
+	if path.IsAbs(req.Path) || strings.Contains("/"+req.Path+"/", "/../") {
+		validErrs = append(validErrs, wirtualsdk.ValidationError{
+			Field:  "path",
+			Detail: "The path must be relative to the root of the repository.",
+		})
+	}
+	if req.ExternalAuthProviderID != "" && req.UseDeployKey {
+		validErrs = append(validErrs, wirtualsdk.ValidationError{
+			Field:  "use_deploy_key",
+			Detail: "A deploy key can't be used with external auth.",
+		})
+	}
+	if req.ExternalAuthProviderID != "" {
+		found := false
+		for _, config := range api.ExternalAuthConfigs {
+			if config.ID == req.ExternalAuthProviderID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			validErrs = append(validErrs, wirtualsdk.ValidationError{
+				Field:  "external_auth_provider_id",
+				Detail: fmt.Sprintf("External auth provider %q isn't configured.", req.ExternalAuthProviderID),
+			})
+		} else {
+			// The repository is fetched with the link of the user, so they
+			// must have authenticated.
+			_, err := api.Database.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
+				ProviderID: req.ExternalAuthProviderID,
+				UserID:     apiKey.UserID,
+			})
+			if httpapi.Is404Error(err) {
+				validErrs = append(validErrs, wirtualsdk.ValidationError{
+					Field:  "external_auth_provider_id",
+					Detail: fmt.Sprintf("You must authenticate with %q first.", req.ExternalAuthProviderID),
+				})
+			} else if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+					Message: "Internal error fetching external auth link.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+		}
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message:     "Invalid request to update template git source.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	existing, err := api.Database.GetTemplateGitSourceByTemplateID(ctx, template.ID)
+	if err != nil && !httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	knownHosts := req.KnownHosts
+	if strings.HasPrefix(repoURL, "https://") {
+		knownHosts = ""
+	}
+	// The deploy key and webhook secret are kept, so they don't have to be
+	// configured in the repository again.
+	privateKey, publicKey := existing.DeployPrivateKey, existing.DeployPublicKey
+	if !req.UseDeployKey {
+		privateKey, publicKey = "", ""
+	} else if privateKey == "" {
+		privateKey, publicKey, err = gitsshkey.Generate(api.SSHKeygenAlgorithm)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+				Message: "Internal error generating a deploy key.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	webhookSecret := existing.WebhookSecret
+	if webhookSecret == "" {
+		webhookSecret, err = cryptorand.String(32)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+				Message: "Internal error generating a webhook secret.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	source, err := api.Database.UpsertTemplateGitSource(ctx, database.UpsertTemplateGitSourceParams{
+		TemplateID:             template.ID,
+		UserID:                 apiKey.UserID,
+		UpdatedAt:              dbtime.Now(),
+		RepoURL:                repoURL,
+		Path:                   templatePath,
+		Ref:                    strings.TrimSpace(req.Ref),
+		ExternalAuthProviderID: req.ExternalAuthProviderID,
+		DeployPrivateKey:       privateKey,
+		DeployPublicKey:        publicKey,
+		KnownHosts:             knownHosts,
+		WebhookSecret:          webhookSecret,
+		AutoPromote:            req.AutoPromote,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error updating template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplateGitSource(source))
+}
+
+// @Summary Delete template git source
+// @ID delete-template-git-source
+// @Security CoderSessionToken
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 204
+// @Router /templates/{template}/git-source [delete]
+func (api *API) deleteTemplateGitSource(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	err := api.Database.DeleteTemplateGitSource(ctx, template.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error deleting template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Sync template from git
+// @ID sync-template-from-git
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 202 {object} wirtualsdk.Response
+// @Router /templates/{template}/git-source/sync [post]
+func (api *API) postTemplateGitSourceSync(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	_, err := api.Database.GetTemplateGitSourceByTemplateID(ctx, template.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, wirtualsdk.Response{
+			Message: "The template isn't synced from git.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	err = api.Database.RequestTemplateGitSourceSync(ctx, database.RequestTemplateGitSourceSyncParams{
+		TemplateID:  template.ID,
+		RequestedAt: dbtime.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error requesting template git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusAccepted, wirtualsdk.Response{
+		Message: "The repository will be checked for new commits shortly.",
+	})
+}
+
+// postTemplateGitSourceWebhook requests a sync when the repository is pushed
+// to. It's called by git hosts, so it's authenticated with the webhook secret
+// of the source instead of a session token. GitHub and Gitea sign the payload,
+// while GitLab sends the secret as a token.
+//
+// @Summary Receive template git source webhook
+// @ID receive-template-git-source-webhook
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 202 {object} wirtualsdk.Response
+// @Router /templategitsources/{template}/webhook [post]
+func (api *API) postTemplateGitSourceWebhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "template"))
+	if err != nil {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	//nolint:gocritic // The webhook is authenticated with the secret of the source below.
+	source, err := api.Database.GetTemplateGitSourceByTemplateID(dbauthz.AsSystemRestricted(ctx), templateID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTemplateGitWebhookBodySize))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: "Failed to read webhook payload.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !verifyTemplateGitWebhook(r.Header, body, source.WebhookSecret) {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, wirtualsdk.Response{
+			Message: "The webhook signature or token is invalid.",
+		})
+		return
+	}
+
+	//nolint:gocritic // The webhook was verified above.
+	err = api.Database.RequestTemplateGitSourceSync(dbauthz.AsSystemRestricted(ctx), database.RequestTemplateGitSourceSyncParams{
+		TemplateID:  templateID,
+		RequestedAt: dbtime.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error requesting template git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusAccepted, wirtualsdk.Response{
+		Message: "The repository will be checked for new commits shortly.",
+	})
+}
+
+// verifyTemplateGitWebhook returns whether the headers of a webhook prove that
+// it was sent with secret.
+func verifyTemplateGitWebhook(header http.Header, body []byte, secret string) bool {
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	signature, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func (api *API) convertTemplateGitSource(source database.TemplateGitSource) wirtualsdk.TemplateGitSource {
+	apiSource := wirtualsdk.TemplateGitSource{
+		TemplateID:             source.TemplateID,
+		RepoURL:                source.RepoURL,
+		Path:                   source.Path,
+		Ref:                    source.Ref,
+		ExternalAuthProviderID: source.ExternalAuthProviderID,
+		DeployKey:              source.DeployPublicKey,
+		KnownHosts:             source.KnownHosts,
+		AutoPromote:            source.AutoPromote,
+		WebhookURL:             api.AccessURL.JoinPath("/api/v2/templategitsources", source.TemplateID.String(), "webhook").String(),
+		WebhookSecret:          source.WebhookSecret,
+		UserID:                 source.UserID,
+		CreatedAt:              source.CreatedAt,
+		UpdatedAt:              source.UpdatedAt,
+		Status:                 wirtualsdk.TemplateGitSyncStatus(source.SyncStatus),
+		Error:                  source.SyncError,
+		LastCommitSHA:          source.LastCommitSHA,
+	}
+	if source.LastCheckedAt.Valid {
+		apiSource.LastCheckedAt = &source.LastCheckedAt.Time
+	}
+	if source.LastSyncedAt.Valid {
+		apiSource.LastSyncedAt = &source.LastSyncedAt.Time
+	}
+	if source.TemplateVersionID.Valid {
+		apiSource.TemplateVersionID = &source.TemplateVersionID.UUID
+	}
+	return apiSource
+}
@@ -0,0 +1,152 @@
+package wirtuald_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// exampleKnownHosts is a host key of example.com in the known_hosts format.
+const exampleKnownHosts = "example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+
+func TestTemplateGitSource(t *testing.T) {
+	t.Parallel()
+
+	client := wirtualdtest.New(t, &wirtualdtest.Options{IncludeProvisionerDaemon: true})
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	member, _ := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+	createTemplate := func(t *testing.T) wirtualsdk.Template {
+		t.Helper()
+		version := wirtualdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+		wirtualdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		return wirtualdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+	}
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		template := createTemplate(t)
+
+		_, err := client.TemplateGitSource(ctx, template.ID)
+		var apiErr *wirtualsdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		source, err := client.UpdateTemplateGitSource(ctx, template.ID, wirtualsdk.UpdateTemplateGitSourceRequest{
+			RepoURL:      "git@example.com:templates.git",
+			Path:         "docker/",
+			Ref:          "main",
+			UseDeployKey: true,
+			KnownHosts:   exampleKnownHosts,
+			AutoPromote:  true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "docker", source.Path)
+		require.Equal(t, wirtualsdk.TemplateGitSyncStatusPending, source.Status)
+		require.Contains(t, source.DeployKey, "ssh-")
+		require.Equal(t, exampleKnownHosts, source.KnownHosts)
+		require.NotEmpty(t, source.WebhookSecret)
+		require.Contains(t, source.WebhookURL, fmt.Sprintf("/api/v2/templategitsources/%s/webhook", template.ID))
+
+		// The deploy key and webhook secret are kept.
+		updated, err := client.UpdateTemplateGitSource(ctx, template.ID, wirtualsdk.UpdateTemplateGitSourceRequest{
+			RepoURL:      "git@example.com:templates.git",
+			Path:         "kubernetes",
+			UseDeployKey: true,
+			KnownHosts:   exampleKnownHosts,
+		})
+		require.NoError(t, err)
+		require.Equal(t, source.DeployKey, updated.DeployKey)
+		require.Equal(t, source.WebhookSecret, updated.WebhookSecret)
+		require.False(t, updated.AutoPromote)
+
+		got, err := client.TemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, updated, got)
+
+		err = client.SyncTemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+
+		// Members can't see the secrets of the source.
+		_, err = member.TemplateGitSource(ctx, template.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		err = client.DeleteTemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = client.TemplateGitSource(ctx, template.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		template := createTemplate(t)
+
+		for _, req := range []wirtualsdk.UpdateTemplateGitSourceRequest{
+			{RepoURL: "https://example.com/templates.git", Path: "../docker"},
+			{RepoURL: "https://example.com/templates.git", Path: "/docker"},
+			{RepoURL: "https://example.com/templates.git", ExternalAuthProviderID: "github"},
+			{RepoURL: ""},
+			{RepoURL: "file:///var/lib/coder/templates"},
+			{RepoURL: "/var/lib/coder/templates"},
+			{RepoURL: "http://example.com/templates.git"},
+			{RepoURL: "https://example.com/templates.git", UseDeployKey: true},
+			{RepoURL: "git@example.com:templates.git", UseDeployKey: true},
+			{RepoURL: "git@example.com:templates.git", UseDeployKey: true, KnownHosts: "example.com ssh-ed25519 invalid"},
+		} {
+			_, err := client.UpdateTemplateGitSource(ctx, template.ID, req)
+			var apiErr *wirtualsdk.Error
+			require.ErrorAs(t, err, &apiErr, "request %+v", req)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), "request %+v", req)
+		}
+	})
+
+	t.Run("Webhook", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		template := createTemplate(t)
+
+		source, err := client.UpdateTemplateGitSource(ctx, template.ID, wirtualsdk.UpdateTemplateGitSourceRequest{
+			RepoURL: "https://example.com/templates.git",
+		})
+		require.NoError(t, err)
+
+		payload := []byte(`{"ref":"refs/heads/main"}`)
+		mac := hmac.New(sha256.New, []byte(source.WebhookSecret))
+		_, _ = mac.Write(payload)
+		path := fmt.Sprintf("/api/v2/templategitsources/%s/webhook", template.ID)
+		anonymous := wirtualsdk.New(client.URL)
+
+		for _, tc := range []struct {
+			name   string
+			header string
+			value  string
+			status int
+		}{
+			{"GitHub", "X-Hub-Signature-256", "sha256=" + hex.EncodeToString(mac.Sum(nil)), http.StatusAccepted},
+			{"GitLab", "X-Gitlab-Token", source.WebhookSecret, http.StatusAccepted},
+			{"InvalidSignature", "X-Hub-Signature-256", "sha256=" + hex.EncodeToString([]byte("invalid")), http.StatusUnauthorized},
+			{"InvalidToken", "X-Gitlab-Token", "invalid", http.StatusUnauthorized},
+			{"Missing", "X-Unrelated", "", http.StatusUnauthorized},
+		} {
+			res, err := anonymous.Request(ctx, http.MethodPost, path, payload, func(r *http.Request) {
+				r.Header.Set(tc.header, tc.value)
+			})
+			require.NoError(t, err, tc.name)
+			_ = res.Body.Close()
+			require.Equal(t, tc.status, res.StatusCode, tc.name)
+		}
+	})
+}
@@ -695,6 +695,15 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	if req.TimeTilDormantAutoDeleteMillis < 0 || (req.TimeTilDormantAutoDeleteMillis > 0 && req.TimeTilDormantAutoDeleteMillis < minTTL) {
 		validErrs = append(validErrs, wirtualsdk.ValidationError{Field: "time_til_dormant_autodelete_ms", Detail: "Value must be at least one minute."})
 	}
+	// Defaults to the existing.
+	jobTimeout := template.JobTimeout
+	if req.JobTimeoutMillis != nil {
+		if *req.JobTimeoutMillis < 0 || (*req.JobTimeoutMillis > 0 && *req.JobTimeoutMillis < minTTL) {
+			validErrs = append(validErrs, wirtualsdk.ValidationError{Field: "job_timeout_ms", Detail: "Value must be at least one minute."})
+		} else {
+			jobTimeout = int64(time.Duration(*req.JobTimeoutMillis) * time.Millisecond)
+		}
+	}
 	maxPortShareLevel := template.MaxPortSharingLevel
 	if req.MaxPortShareLevel != nil && *req.MaxPortShareLevel != portSharer.ConvertMaxLevel(template.MaxPortSharingLevel) {
 		err := portSharer.ValidateTemplateMaxLevel(*req.MaxPortShareLevel)
@@ -732,7 +741,8 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			req.TimeTilDormantAutoDeleteMillis == time.Duration(template.TimeTilDormantAutoDelete).Milliseconds() &&
 			req.RequireActiveVersion == template.RequireActiveVersion &&
 			(deprecationMessage == template.Deprecated) &&
-			maxPortShareLevel == template.MaxPortSharingLevel {
+			maxPortShareLevel == template.MaxPortSharingLevel &&
+			jobTimeout == template.JobTimeout {
 			return nil
 		}
 
@@ -773,6 +783,7 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			AllowUserCancelWorkspaceJobs: req.AllowUserCancelWorkspaceJobs,
 			GroupACL:                     groupACL,
 			MaxPortSharingLevel:          maxPortShareLevel,
+			JobTimeout:                   jobTimeout,
 		})
 		if err != nil {
 			return xerrors.Errorf("update template metadata: %w", err)
@@ -1055,6 +1066,7 @@ func (api *API) convertTemplate(
 		Deprecated:           templateAccessControl.IsDeprecated(),
 		DeprecationMessage:   templateAccessControl.Deprecated,
 		MaxPortShareLevel:    maxPortShareLevel,
+		JobTimeoutMillis:     time.Duration(template.JobTimeout).Milliseconds(),
 	}
 }
 
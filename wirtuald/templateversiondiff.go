@@ -0,0 +1,408 @@
+package wirtuald
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/pkg/diff"
+	"golang.org/x/xerrors"
+
+	"github.com/onchainengineering/hmi-wirtual/archive"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpmw"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// @Summary Compare template versions
+// @ID compare-template-versions
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID to compare from" format(uuid)
+// @Param target path string true "Template version ID to compare to" format(uuid)
+// @Success 200 {object} wirtualsdk.TemplateVersionDiff
+// @Router /templateversions/{templateversion}/diff/{target} [get]
+func (api *API) templateVersionDiff(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	from := httpmw.TemplateVersionParam(r)
+	targetID, ok := httpmw.ParseUUIDParam(rw, r, "target")
+	if !ok {
+		return
+	}
+	to, err := api.Database.GetTemplateVersionByID(ctx, targetID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	fromSnapshot, ok := api.templateVersionSnapshot(rw, r, from)
+	if !ok {
+		return
+	}
+	toSnapshot, ok := api.templateVersionSnapshot(rw, r, to)
+	if !ok {
+		return
+	}
+
+	apiDiff := wirtualsdk.TemplateVersionDiff{
+		FromTemplateVersionID: from.ID,
+		ToTemplateVersionID:   to.ID,
+		Files:                 diffTemplateVersionFiles(fromSnapshot.files, toSnapshot.files),
+	}
+	for _, objects := range []struct {
+		diff     *wirtualsdk.TemplateVersionObjectDiff
+		from, to map[string]any
+	}{
+		{&apiDiff.Parameters, fromSnapshot.parameters, toSnapshot.parameters},
+		{&apiDiff.Variables, fromSnapshot.variables, toSnapshot.variables},
+		{&apiDiff.WorkspaceTags, fromSnapshot.workspaceTags, toSnapshot.workspaceTags},
+		{&apiDiff.Resources, fromSnapshot.resources, toSnapshot.resources},
+	} {
+		*objects.diff, err = diffTemplateVersionObjects(objects.from, objects.to)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+				Message: "Internal error comparing template versions.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiDiff)
+}
+
+// templateVersionSnapshot is what's recorded on a template version, keyed by
+// the names that are compared.
+type templateVersionSnapshot struct {
+	files         map[string][]byte
+	parameters    map[string]any
+	variables     map[string]any
+	workspaceTags map[string]any
+	resources     map[string]any
+}
+
+func (api *API) templateVersionSnapshot(rw http.ResponseWriter, r *http.Request, templateVersion database.TemplateVersion) (templateVersionSnapshot, bool) {
+	ctx := r.Context()
+	snapshot := templateVersionSnapshot{
+		parameters:    map[string]any{},
+		variables:     map[string]any{},
+		workspaceTags: map[string]any{},
+		resources:     map[string]any{},
+	}
+
+	job, err := api.Database.GetProvisionerJobByID(ctx, templateVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return snapshot, false
+	}
+	if !job.CompletedAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: fmt.Sprintf("Template version %q hasn't been imported yet.", templateVersion.Name),
+		})
+		return snapshot, false
+	}
+
+	file, err := api.Database.GetFileByID(ctx, job.FileID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, wirtualsdk.Response{
+			Message: fmt.Sprintf("You aren't allowed to read the source of template version %q.", templateVersion.Name),
+		})
+		return snapshot, false
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template version source.",
+			Detail:  err.Error(),
+		})
+		return snapshot, false
+	}
+	snapshot.files, err = archive.ReadTarFiles(tar.NewReader(bytes.NewReader(file.Data)), HTTPFileMaxBytes)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error reading template version source.",
+			Detail:  err.Error(),
+		})
+		return snapshot, false
+	}
+
+	dbParameters, err := api.Database.GetTemplateVersionParameters(ctx, templateVersion.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template version parameters.",
+			Detail:  err.Error(),
+		})
+		return snapshot, false
+	}
+	parameters, err := convertTemplateVersionParameters(dbParameters)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error converting template version parameter.",
+			Detail:  err.Error(),
+		})
+		return snapshot, false
+	}
+	for _, parameter := range parameters {
+		snapshot.parameters[parameter.Name] = parameter
+	}
+
+	dbVariables, err := api.Database.GetTemplateVersionVariables(ctx, templateVersion.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template version variables.",
+			Detail:  err.Error(),
+		})
+		return snapshot, false
+	}
+	// Values of sensitive variables are redacted, so changes to them aren't
+	// reported.
+	for _, variable := range convertTemplateVersionVariables(dbVariables) {
+		snapshot.variables[variable.Name] = variable
+	}
+
+	tags, err := api.Database.GetTemplateVersionWorkspaceTags(ctx, templateVersion.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template version workspace tags.",
+			Detail:  err.Error(),
+		})
+		return snapshot, false
+	}
+	for _, tag := range tags {
+		snapshot.workspaceTags[tag.Key] = struct {
+			Value string `json:"value"`
+		}{tag.Value}
+	}
+
+	snapshot.resources, err = templateVersionResourceSnapshot(ctx, api.Database, job.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching template version resources.",
+			Detail:  err.Error(),
+		})
+		return snapshot, false
+	}
+	return snapshot, true
+}
+
+// templateVersionResource is the part of a resource that was planned during
+// the import of a template version that's compared.
+type templateVersionResource struct {
+	Transitions  []string `json:"transitions"`
+	Agents       []string `json:"agents"`
+	Apps         []string `json:"apps"`
+	Hide         bool     `json:"hide"`
+	Icon         string   `json:"icon"`
+	InstanceType string   `json:"instance_type"`
+	DailyCost    int32    `json:"daily_cost"`
+}
+
+func templateVersionResourceSnapshot(ctx context.Context, db database.Store, jobID uuid.UUID) (map[string]any, error) {
+	//nolint:gocritic // The template version was already authorized, like in provisionerJobResources.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	resources, err := db.GetWorkspaceResourcesByJobID(ctx, jobID)
+	if err != nil {
+		return nil, xerrors.Errorf("get resources: %w", err)
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := db.GetWorkspaceAgentsByResourceIDs(ctx, resourceIDs)
+	if err != nil {
+		return nil, xerrors.Errorf("get agents: %w", err)
+	}
+	agentIDs := make([]uuid.UUID, 0, len(agents))
+	for _, agent := range agents {
+		agentIDs = append(agentIDs, agent.ID)
+	}
+	apps, err := db.GetWorkspaceAppsByAgentIDs(ctx, agentIDs)
+	if err != nil {
+		return nil, xerrors.Errorf("get apps: %w", err)
+	}
+
+	// Resources are planned once per transition, and are merged by their
+	// address.
+	merged := map[string]*templateVersionResource{}
+	for _, resource := range resources {
+		address := resource.Type + "." + resource.Name
+		snapshot, ok := merged[address]
+		if !ok {
+			snapshot = &templateVersionResource{
+				Transitions: []string{},
+				Agents:      []string{},
+				Apps:        []string{},
+			}
+			merged[address] = snapshot
+		}
+		snapshot.Transitions = appendUnique(snapshot.Transitions, []string{string(resource.Transition)})
+		snapshot.Hide = resource.Hide
+		snapshot.Icon = resource.Icon
+		snapshot.InstanceType = resource.InstanceType.String
+		snapshot.DailyCost = resource.DailyCost
+		for _, agent := range agents {
+			if agent.ResourceID != resource.ID {
+				continue
+			}
+			snapshot.Agents = appendUnique(snapshot.Agents, []string{agent.Name})
+			for _, app := range apps {
+				if app.AgentID == agent.ID {
+					snapshot.Apps = appendUnique(snapshot.Apps, []string{agent.Name + "." + app.Slug})
+				}
+			}
+		}
+	}
+
+	snapshots := make(map[string]any, len(merged))
+	for address, snapshot := range merged {
+		sort.Strings(snapshot.Transitions)
+		sort.Strings(snapshot.Agents)
+		sort.Strings(snapshot.Apps)
+		snapshots[address] = snapshot
+	}
+	return snapshots, nil
+}
+
+// diffTemplateVersionFiles returns a unified diff of every file that differs
+// between two template versions, sorted by path.
+func diffTemplateVersionFiles(from, to map[string][]byte) []wirtualsdk.TemplateVersionFileDiff {
+	paths := make([]string, 0, len(from)+len(to))
+	for path := range from {
+		paths = append(paths, path)
+	}
+	for path := range to {
+		if _, ok := from[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	diffs := []wirtualsdk.TemplateVersionFileDiff{}
+	for _, path := range paths {
+		fromData, inFrom := from[path]
+		toData, inTo := to[path]
+		fileDiff := wirtualsdk.TemplateVersionFileDiff{
+			Path:   path,
+			Change: wirtualsdk.TemplateVersionFileChangeModified,
+		}
+		switch {
+		case !inFrom:
+			fileDiff.Change = wirtualsdk.TemplateVersionFileChangeAdded
+		case !inTo:
+			fileDiff.Change = wirtualsdk.TemplateVersionFileChangeRemoved
+		case bytes.Equal(fromData, toData):
+			continue
+		}
+		if isBinary(fromData) || isBinary(toData) {
+			fileDiff.Binary = true
+		} else {
+			var buf bytes.Buffer
+			// Writing to a buffer can't fail.
+			_ = diff.Text("a/"+path, "b/"+path, fromData, toData, &buf)
+			fileDiff.Diff = buf.String()
+		}
+		diffs = append(diffs, fileDiff)
+	}
+	return diffs
+}
+
+func isBinary(data []byte) bool {
+	return !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0
+}
+
+// diffTemplateVersionObjects compares objects by the fields of their JSON
+// representation.
+func diffTemplateVersionObjects(from, to map[string]any) (wirtualsdk.TemplateVersionObjectDiff, error) {
+	objectDiff := wirtualsdk.TemplateVersionObjectDiff{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []wirtualsdk.TemplateVersionObjectChange{},
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			objectDiff.Added = append(objectDiff.Added, name)
+		}
+	}
+	for name, fromObject := range from {
+		toObject, ok := to[name]
+		if !ok {
+			objectDiff.Removed = append(objectDiff.Removed, name)
+			continue
+		}
+		fromFields, err := jsonFields(fromObject)
+		if err != nil {
+			return objectDiff, xerrors.Errorf("%s: %w", name, err)
+		}
+		toFields, err := jsonFields(toObject)
+		if err != nil {
+			return objectDiff, xerrors.Errorf("%s: %w", name, err)
+		}
+		fieldNames := make([]string, 0, len(fromFields)+len(toFields))
+		for field := range fromFields {
+			fieldNames = append(fieldNames, field)
+		}
+		for field := range toFields {
+			if _, ok := fromFields[field]; !ok {
+				fieldNames = append(fieldNames, field)
+			}
+		}
+		sort.Strings(fieldNames)
+
+		change := wirtualsdk.TemplateVersionObjectChange{Name: name}
+		for _, field := range fieldNames {
+			if fromFields[field] == toFields[field] {
+				continue
+			}
+			change.Fields = append(change.Fields, wirtualsdk.TemplateVersionFieldChange{
+				Name: field,
+				From: fromFields[field],
+				To:   toFields[field],
+			})
+		}
+		if len(change.Fields) > 0 {
+			objectDiff.Changed = append(objectDiff.Changed, change)
+		}
+	}
+	sort.Strings(objectDiff.Added)
+	sort.Strings(objectDiff.Removed)
+	sort.Slice(objectDiff.Changed, func(i, j int) bool {
+		return objectDiff.Changed[i].Name < objectDiff.Changed[j].Name
+	})
+	return objectDiff, nil
+}
+
+// jsonFields returns the JSON encoded fields of an object.
+func jsonFields(object any) (map[string]string, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string, len(raw))
+	for name, value := range raw {
+		fields[name] = strings.TrimSpace(string(value))
+	}
+	return fields, nil
+}
@@ -507,6 +507,7 @@ func (api *API) postTemplateVersionDryRun(rw http.ResponseWriter, r *http.Reques
 			Valid:      true,
 			RawMessage: metadataRaw,
 		},
+		Priority: database.ProvisionerJobPriorityInteractive,
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
@@ -1550,6 +1551,7 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 				Valid:      true,
 				RawMessage: traceMetadataRaw,
 			},
+			Priority: database.ProvisionerJobPriorityInteractive,
 		})
 		if err != nil {
 			httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
@@ -1718,6 +1720,8 @@ func convertTemplateVersion(version database.TemplateVersion, job wirtualsdk.Pro
 			AvatarURL: version.CreatedByAvatarURL,
 		},
 		Archived:            version.Archived,
+		GitCommitSHA:        version.GitCommitSHA,
+		GitCommitAuthor:     version.GitCommitAuthor,
 		Warnings:            warnings,
 		MatchedProvisioners: matchedProvisioners,
 	}
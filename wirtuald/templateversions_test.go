@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -1806,6 +1808,128 @@ func TestTemplateVersionParameters_Order(t *testing.T) {
 	require.Equal(t, thirdParameterName, templateRichParameters[4].Name)
 }
 
+func TestTemplateVersionDiff(t *testing.T) {
+	t.Parallel()
+
+	client := wirtualdtest.New(t, &wirtualdtest.Options{IncludeProvisionerDaemon: true})
+	user := wirtualdtest.CreateFirstUser(t, client)
+
+	// createVersion creates a template version whose source contains the
+	// echo responses and the given main.tf.
+	createVersion := func(t *testing.T, mainTF string, parameters []*proto.RichParameter, resources []*proto.Resource) wirtualsdk.TemplateVersion {
+		t.Helper()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		data, err := echo.Tar(&echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionApply: []*proto.Response{{
+				Type: &proto.Response_Apply{Apply: &proto.ApplyComplete{
+					Parameters: parameters,
+					Resources:  resources,
+				}},
+			}},
+		})
+		require.NoError(t, err)
+		dir := t.TempDir()
+		require.NoError(t, provisionersdk.Untar(dir, bytes.NewReader(data)))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(mainTF), 0o600))
+		var archive bytes.Buffer
+		require.NoError(t, provisionersdk.Tar(&archive, client.Logger(), dir, provisionersdk.TemplateArchiveLimit))
+		file, err := client.Upload(ctx, wirtualsdk.ContentTypeTar, &archive)
+		require.NoError(t, err)
+
+		version := wirtualdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil, func(req *wirtualsdk.CreateTemplateVersionRequest) {
+			req.FileID = file.ID
+		})
+		return wirtualdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+	}
+
+	from := createVersion(t, "locals {\n  region = \"us\"\n}\n", []*proto.RichParameter{{
+		Name:         "region",
+		Type:         "string",
+		DefaultValue: "us",
+	}, {
+		Name: "legacy",
+		Type: "string",
+	}}, []*proto.Resource{{
+		Name: "workspace",
+		Type: "docker_container",
+		Agents: []*proto.Agent{{
+			Id:   "main",
+			Name: "main",
+			Auth: &proto.Agent_Token{},
+		}},
+	}})
+	to := createVersion(t, "locals {\n  region = \"eu\"\n}\n", []*proto.RichParameter{{
+		Name:         "region",
+		Type:         "string",
+		DefaultValue: "eu",
+	}, {
+		Name: "size",
+		Type: "number",
+	}}, []*proto.Resource{{
+		Name: "workspace",
+		Type: "docker_container",
+		Agents: []*proto.Agent{{
+			Id:   "main",
+			Name: "main",
+			Auth: &proto.Agent_Token{},
+			Apps: []*proto.App{{Slug: "code-server"}},
+		}},
+	}, {
+		Name: "home",
+		Type: "docker_volume",
+	}})
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	diff, err := client.TemplateVersionDiff(ctx, from.ID, to.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.ID, diff.FromTemplateVersionID)
+	require.Equal(t, to.ID, diff.ToTemplateVersionID)
+
+	var mainTF *wirtualsdk.TemplateVersionFileDiff
+	for i, file := range diff.Files {
+		if file.Path == "main.tf" {
+			mainTF = &diff.Files[i]
+			continue
+		}
+		// The echo responses are binary.
+		require.True(t, file.Binary, file.Path)
+	}
+	require.NotNil(t, mainTF)
+	require.Equal(t, wirtualsdk.TemplateVersionFileChangeModified, mainTF.Change)
+	require.Contains(t, mainTF.Diff, "-  region = \"us\"\n+  region = \"eu\"\n")
+
+	require.Equal(t, []string{"size"}, diff.Parameters.Added)
+	require.Equal(t, []string{"legacy"}, diff.Parameters.Removed)
+	require.Equal(t, []wirtualsdk.TemplateVersionObjectChange{{
+		Name: "region",
+		Fields: []wirtualsdk.TemplateVersionFieldChange{{
+			Name: "default_value",
+			From: `"us"`,
+			To:   `"eu"`,
+		}},
+	}}, diff.Parameters.Changed)
+
+	require.Equal(t, []string{"docker_volume.home"}, diff.Resources.Added)
+	require.Empty(t, diff.Resources.Removed)
+	require.Equal(t, []wirtualsdk.TemplateVersionObjectChange{{
+		Name: "docker_container.workspace",
+		Fields: []wirtualsdk.TemplateVersionFieldChange{{
+			Name: "apps",
+			From: `[]`,
+			To:   `["main.code-server"]`,
+		}},
+	}}, diff.Resources.Changed)
+
+	// Comparing a version with itself finds no changes.
+	diff, err = client.TemplateVersionDiff(ctx, to.ID, to.ID)
+	require.NoError(t, err)
+	require.Empty(t, diff.Files)
+	require.Empty(t, diff.Parameters.Added)
+	require.Empty(t, diff.Parameters.Changed)
+	require.Empty(t, diff.Resources.Changed)
+}
+
 func TestTemplateArchiveVersions(t *testing.T) {
 	t.Parallel()
 
@@ -27,6 +27,7 @@ import (
 	"github.com/onchainengineering/hmi-wirtual/tailnet"
 	"github.com/onchainengineering/hmi-wirtual/tailnet/proto"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/agentapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/audit"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/db2sdk"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
@@ -307,6 +308,10 @@ func (api *API) patchWorkspaceAgentLogs(rw http.ResponseWriter, r *http.Request)
 // @Param after query int false "After log id"
 // @Param follow query bool false "Follow log stream"
 // @Param no_compression query bool false "Disable compression for WebSocket connection"
+// @Param source query []string false "Only return logs of these log sources" collectionFormat(multi)
+// @Param level query string false "Minimum log level" Enums(trace,debug,info,warn,error)
+// @Param since query string false "Only return logs created after this time" format(date-time)
+// @Param limit query int false "Maximum number of logs to return, ignored when following"
 // @Success 200 {array} wirtualsdk.WorkspaceAgentLog
 // @Router /workspaceagents/{workspaceagent}/logs [get]
 func (api *API) workspaceAgentLogs(rw http.ResponseWriter, r *http.Request) {
@@ -336,10 +341,40 @@ func (api *API) workspaceAgentLogs(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logs, err := api.Database.GetWorkspaceAgentLogsAfter(ctx, database.GetWorkspaceAgentLogsAfterParams{
+	queryParams := r.URL.Query()
+	parser := httpapi.NewQueryParamParser()
+	sourceIDs := parser.UUIDs(queryParams, nil, "source")
+	minLevel := httpapi.ParseCustom(parser, queryParams, "", "level", httpapi.ParseEnum[database.LogLevel])
+	since := parser.Time3339Nano(queryParams, time.Time{}, "since")
+	limit := parser.PositiveInt32(queryParams, 0, "limit")
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+	if follow {
+		// Logs are streamed as they arrive, so there's nothing to page.
+		limit = 0
+	}
+	// Log levels are ordered by severity, so the minimum level selects it and
+	// every level after it.
+	var levels []database.LogLevel
+	if minLevel != "" {
+		allLevels := database.AllLogLevelValues()
+		levels = allLevels[slices.Index(allLevels, minLevel):]
+	}
+	getLogsParams := database.GetWorkspaceAgentLogsParams{
 		AgentID:      workspaceAgent.ID,
-		CreatedAfter: after,
-	})
+		AfterID:      after,
+		CreatedAfter: since,
+		LogSourceIDs: sourceIDs,
+		Levels:       levels,
+		LimitOpt:     limit,
+	}
+
+	logs, err := api.Database.GetWorkspaceAgentLogs(ctx, getLogsParams)
 	if errors.Is(err, sql.ErrNoRows) {
 		err = nil
 	}
@@ -515,10 +550,8 @@ func (api *API) workspaceAgentLogs(rw http.ResponseWriter, r *http.Request) {
 				continue
 			}
 
-			logs, err := api.Database.GetWorkspaceAgentLogsAfter(ctx, database.GetWorkspaceAgentLogsAfterParams{
-				AgentID:      workspaceAgent.ID,
-				CreatedAfter: lastSentLogID,
-			})
+			getLogsParams.AfterID = lastSentLogID
+			logs, err := api.Database.GetWorkspaceAgentLogs(ctx, getLogsParams)
 			if err != nil {
 				if xerrors.Is(err, context.Canceled) {
 					return
@@ -569,6 +602,40 @@ func (api *API) workspaceAgentLogs(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// @Summary Get services for workspace agent
+// @ID get-services-for-workspace-agent
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Success 200 {array} wirtualsdk.WorkspaceAgentService
+// @Router /workspaceagents/{workspaceagent}/services [get]
+func (api *API) workspaceAgentServices(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgentParam(r)
+
+	//nolint:gocritic // Read access to the workspace was checked by the middleware.
+	dbServices, err := api.Database.GetWorkspaceAgentServicesByAgentIDs(dbauthz.AsSystemRestricted(ctx), []uuid.UUID{workspaceAgent.ID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching workspace agent services.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	services, err := db2sdk.WorkspaceAgentServices(dbServices)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error converting workspace agent services.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, services)
+}
+
 // @Summary Get listening ports for workspace agent
 // @ID get-listening-ports-for-workspace-agent
 // @Security CoderSessionToken
@@ -679,6 +746,204 @@ func (api *API) workspaceAgentListeningPorts(rw http.ResponseWriter, r *http.Req
 	httpapi.Write(ctx, rw, http.StatusOK, portsResponse)
 }
 
+// @Summary Get dev containers for workspace agent
+// @ID get-dev-containers-for-workspace-agent
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Success 200 {object} wirtualsdk.WorkspaceAgentDevcontainersResponse
+// @Router /workspaceagents/{workspaceagent}/devcontainers [get]
+func (api *API) workspaceAgentDevcontainers(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgentParam(r)
+
+	// The state is the one last reported by the agent, so it's known while
+	// the agent is disconnected.
+	dbDevcontainers, err := api.Database.GetWorkspaceAgentDevcontainersByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching dev containers.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, wirtualsdk.WorkspaceAgentDevcontainersResponse{
+		Devcontainers: db2sdk.WorkspaceAgentDevcontainers(dbDevcontainers),
+	})
+}
+
+// workspaceAgentExecAuditFields are the additional fields of the audit logs
+// of commands. Only the names of environment variables are kept, since their
+// values may be secrets.
+type workspaceAgentExecAuditFields struct {
+	WorkspaceName    string   `json:"workspace_name"`
+	WorkspaceOwner   string   `json:"workspace_owner"`
+	AgentName        string   `json:"agent_name"`
+	Command          []string `json:"command"`
+	Env              []string `json:"env,omitempty"`
+	WorkingDirectory string   `json:"working_directory,omitempty"`
+	ExitCode         *int     `json:"exit_code,omitempty"`
+	TimedOut         bool     `json:"timed_out,omitempty"`
+}
+
+// workspaceAgentExec runs a command in the workspace through the agent, and
+// streams its output and result as server-sent events. It requires the same
+// permission as SSH, and is audited.
+//
+// @Summary Run command in workspace agent
+// @ID run-command-in-workspace-agent
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Param request body wirtualsdk.WorkspaceAgentExecRequest true "Command to run"
+// @Success 200 {object} wirtualsdk.WorkspaceAgentExecEvent
+// @Router /workspaceagents/{workspaceagent}/exec [post]
+func (api *API) workspaceAgentExec(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx            = r.Context()
+		workspace      = httpmw.WorkspaceParam(r)
+		workspaceAgent = httpmw.WorkspaceAgentParam(r)
+		auditor        = api.Auditor.Load()
+		auditFields    = &workspaceAgentExecAuditFields{
+			WorkspaceName:  workspace.Name,
+			WorkspaceOwner: workspace.OwnerUsername,
+			AgentName:      workspaceAgent.Name,
+		}
+	)
+	aReq, commitAudit := audit.InitRequest[database.WorkspaceTable](rw, &audit.RequestParams{
+		Audit:            *auditor,
+		Log:              api.Logger,
+		Request:          r,
+		Action:           database.AuditActionExec,
+		OrganizationID:   workspace.OrganizationID,
+		AdditionalFields: auditFields,
+	})
+	defer commitAudit()
+	aReq.Old = workspace.WorkspaceTable()
+	aReq.New = workspace.WorkspaceTable()
+
+	if !api.Authorize(r, policy.ActionSSH, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req wirtualsdk.WorkspaceAgentExecRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	auditFields.Command = req.Command
+	auditFields.WorkingDirectory = req.WorkingDirectory
+	auditFields.Env = maps.Keys(req.Env)
+	slices.Sort(auditFields.Env)
+	if len(req.Command) == 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: "A command is required.",
+			Validations: []wirtualsdk.ValidationError{
+				{Field: "command", Detail: "must not be empty"},
+			},
+		})
+		return
+	}
+
+	apiAgent, err := db2sdk.WorkspaceAgent(
+		api.DERPMap(), *api.TailnetCoordinator.Load(), workspaceAgent, nil, nil, nil, api.AgentInactiveDisconnectTimeout,
+		api.DeploymentValues.AgentFallbackTroubleshootingURL.String(),
+	)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error reading workspace agent.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if apiAgent.Status != wirtualsdk.WorkspaceAgentConnected {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: fmt.Sprintf("Agent state is %q, it must be in the %q state.", apiAgent.Status, wirtualsdk.WorkspaceAgentConnected),
+		})
+		return
+	}
+
+	// If the agent is unreachable, dialing will hang. Assume that if it's not
+	// reachable after 30s that it's unreachable. The command itself may run
+	// for longer.
+	dialCtx, cancelDial := context.WithTimeout(ctx, 30*time.Second)
+	defer cancelDial()
+	agentConn, release, err := api.agentProvider.AgentConn(dialCtx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error dialing workspace agent.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer release()
+	if !agentConn.AwaitReachable(dialCtx) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Workspace agent not reachable in time.",
+			Detail:  dialCtx.Err().Error(),
+		})
+		return
+	}
+
+	// Events are streamed once the agent started the command, errors before
+	// that are returned as regular responses. The sender stops once the
+	// request context is done, which is canceled when the handler returns.
+	sendCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		sendEvent    func(context.Context, wirtualsdk.ServerSentEvent) error
+		senderClosed chan struct{}
+	)
+	defer func() {
+		if senderClosed != nil {
+			cancel()
+			<-senderClosed
+		}
+	}()
+	result, err := agentConn.Exec(ctx, req, func(event wirtualsdk.WorkspaceAgentExecEvent) error {
+		if sendEvent == nil {
+			var err error
+			sendEvent, senderClosed, err = httpapi.ServerSentEventSender(rw, r.WithContext(sendCtx))
+			if err != nil {
+				return xerrors.Errorf("set up server-sent events: %w", err)
+			}
+		}
+		return sendEvent(sendCtx, wirtualsdk.ServerSentEvent{
+			Type: wirtualsdk.ServerSentEventTypeData,
+			Data: event,
+		})
+	})
+	if err == nil {
+		auditFields.ExitCode = &result.ExitCode
+		auditFields.TimedOut = result.TimedOut
+		return
+	}
+	if sendEvent != nil {
+		_ = sendEvent(sendCtx, wirtualsdk.ServerSentEvent{
+			Type: wirtualsdk.ServerSentEventTypeError,
+			Data: wirtualsdk.Response{
+				Message: "Internal error running command.",
+				Detail:  err.Error(),
+			},
+		})
+		return
+	}
+	var sdkErr *wirtualsdk.Error
+	if errors.As(err, &sdkErr) {
+		// Errors of the agent, like a working directory that doesn't exist.
+		httpapi.Write(ctx, rw, sdkErr.StatusCode(), sdkErr.Response)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+		Message: "Internal error running command.",
+		Detail:  err.Error(),
+	})
+}
+
 // @Summary Get connection info for workspace agent
 // @ID get-connection-info-for-workspace-agent
 // @Security CoderSessionToken
@@ -985,6 +1250,14 @@ func convertLogSources(dbLogSources []database.WorkspaceAgentLogSource) []wirtua
 func convertScripts(dbScripts []database.WorkspaceAgentScript) []wirtualsdk.WorkspaceAgentScript {
 	scripts := make([]wirtualsdk.WorkspaceAgentScript, 0)
 	for _, dbScript := range dbScripts {
+		var ready *wirtualsdk.WorkspaceAgentScriptReadiness
+		if dbScript.ReadyPort != 0 || dbScript.ReadyFile != "" {
+			ready = &wirtualsdk.WorkspaceAgentScriptReadiness{
+				Port:    dbScript.ReadyPort,
+				File:    dbScript.ReadyFile,
+				Timeout: time.Duration(dbScript.ReadyTimeoutSeconds) * time.Second,
+			}
+		}
 		scripts = append(scripts, wirtualsdk.WorkspaceAgentScript{
 			ID:               dbScript.ID,
 			LogPath:          dbScript.LogPath,
@@ -996,6 +1269,8 @@ func convertScripts(dbScripts []database.WorkspaceAgentScript) []wirtualsdk.Work
 			StartBlocksLogin: dbScript.StartBlocksLogin,
 			Timeout:          time.Duration(dbScript.TimeoutSeconds) * time.Second,
 			DisplayName:      dbScript.DisplayName,
+			DependsOn:        dbScript.DependsOn,
+			Ready:            ready,
 		})
 	}
 	return scripts
@@ -1,12 +1,16 @@
 package wirtuald_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"maps"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -35,6 +39,7 @@ import (
 	tailnetproto "github.com/onchainengineering/hmi-wirtual/tailnet/proto"
 	"github.com/onchainengineering/hmi-wirtual/tailnet/tailnettest"
 	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/audit"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
 	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbfake"
@@ -960,6 +965,11 @@ func TestWorkspaceAgentListeningPorts(t *testing.T) {
 						}
 						expected[port.Port] = true
 					}
+					if port.Port == wirtualdPort {
+						// The agent runs in the same process as wirtuald here.
+						assert.Equal(t, int32(os.Getpid()), port.PID)
+						assert.Equal(t, wirtualsdk.WorkspaceAgentPortShareProtocolHTTP, port.Protocol)
+					}
 				}
 				for port, found := range expected {
 					if !found {
@@ -1150,6 +1160,247 @@ func TestWorkspaceAgentAppHealth(t *testing.T) {
 	require.EqualValues(t, wirtualsdk.WorkspaceAppHealthUnhealthy, manifest.Apps[1].Health)
 }
 
+func TestWorkspaceAgentServices(t *testing.T) {
+	t.Parallel()
+	client, db := wirtualdtest.NewWithDatabase(t, nil)
+	user := wirtualdtest.CreateFirstUser(t, client)
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: user.OrganizationID,
+		OwnerID:        user.UserID,
+	}).WithAgent(func(agents []*proto.Agent) []*proto.Agent {
+		agents[0].Services = []*proto.Service{
+			{
+				Name:                  "web",
+				Command:               "npm run dev",
+				Dir:                   "/home/coder/app",
+				Env:                   []*proto.Env{{Name: "PORT", Value: "3000"}},
+				RestartPolicy:         proto.ServiceRestartPolicy_ON_FAILURE,
+				RestartBackoffSeconds: 2,
+			},
+		}
+		return agents
+	}).Do()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	workspace, err := client.Workspace(ctx, r.Workspace.ID)
+	require.NoError(t, err)
+	agentID := workspace.LatestBuild.Resources[0].Agents[0].ID
+
+	services, err := client.WorkspaceAgentServices(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	require.Equal(t, "web", services[0].Name)
+	require.Equal(t, "web", services[0].DisplayName)
+	require.Equal(t, map[string]string{"PORT": "3000"}, services[0].Env)
+	require.Equal(t, wirtualsdk.WorkspaceAgentServiceRestartOnFailure, services[0].RestartPolicy)
+	require.Equal(t, 2*time.Second, services[0].RestartBackoff)
+	require.Equal(t, wirtualsdk.WorkspaceAgentServicePending, services[0].State)
+	require.Nil(t, services[0].StateChangedAt)
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(r.AgentToken)
+	conn, err := agentClient.ConnectRPC(ctx)
+	require.NoError(t, err)
+	defer func() {
+		cErr := conn.Close()
+		require.NoError(t, cErr)
+	}()
+	aAPI := agentproto.NewDRPCAgentClient(conn)
+
+	manifest := requireGetManifest(ctx, t, aAPI)
+	require.Len(t, manifest.Services, 1)
+	require.Equal(t, services[0].ID, manifest.Services[0].ID)
+
+	_, err = aAPI.BatchUpdateServiceStatuses(ctx, &agentproto.BatchUpdateServiceStatusesRequest{
+		Statuses: []*agentproto.ServiceStatus{
+			{
+				Id:           services[0].ID[:],
+				State:        agentproto.ServiceStatus_BACKOFF,
+				RestartCount: 1,
+				ExitCode:     1,
+				Message:      "exit status 1",
+				ChangedAt:    timestamppb.Now(),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	services, err = client.WorkspaceAgentServices(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	require.Equal(t, wirtualsdk.WorkspaceAgentServiceBackoff, services[0].State)
+	require.EqualValues(t, 1, services[0].RestartCount)
+	require.EqualValues(t, 1, services[0].ExitCode)
+	require.Equal(t, "exit status 1", services[0].StatusMessage)
+	require.NotNil(t, services[0].StateChangedAt)
+}
+
+func TestWorkspaceAgentDevcontainers(t *testing.T) {
+	t.Parallel()
+	client, db := wirtualdtest.NewWithDatabase(t, nil)
+	user := wirtualdtest.CreateFirstUser(t, client)
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: user.OrganizationID,
+		OwnerID:        user.UserID,
+	}).WithAgent().Do()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	workspace, err := client.Workspace(ctx, r.Workspace.ID)
+	require.NoError(t, err)
+	agentID := workspace.LatestBuild.Resources[0].Agents[0].ID
+
+	res, err := client.WorkspaceAgentDevcontainers(ctx, agentID)
+	require.NoError(t, err)
+	require.Empty(t, res.Devcontainers)
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(r.AgentToken)
+	conn, err := agentClient.ConnectRPC(ctx)
+	require.NoError(t, err)
+	aAPI := agentproto.NewDRPCAgentClient(conn)
+
+	_, err = aAPI.BatchUpdateDevcontainerStatuses(ctx, &agentproto.BatchUpdateDevcontainerStatusesRequest{
+		Statuses: []*agentproto.DevcontainerStatus{
+			{
+				Name:            "project",
+				WorkspaceFolder: "/home/coder/project",
+				ConfigPath:      "/home/coder/project/.devcontainer/devcontainer.json",
+				State:           agentproto.DevcontainerStatus_RUNNING,
+				ContainerId:     "abc123",
+				Image:           "ubuntu",
+				ChangedAt:       timestamppb.Now(),
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	// The last reported state is served while the agent is disconnected.
+	res, err = client.WorkspaceAgentDevcontainers(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, res.Devcontainers, 1)
+	devcontainer := res.Devcontainers[0]
+	require.Equal(t, "project", devcontainer.Name)
+	require.Equal(t, "/home/coder/project", devcontainer.WorkspaceFolder)
+	require.Equal(t, wirtualsdk.WorkspaceAgentDevcontainerRunning, devcontainer.State)
+	require.Equal(t, "abc123", devcontainer.ContainerID)
+	require.Equal(t, "ubuntu", devcontainer.Image)
+	require.NotNil(t, devcontainer.StateChangedAt)
+}
+
+func TestWorkspaceAgentExec(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are run with sh")
+	}
+
+	auditor := audit.NewMock()
+	client, db := wirtualdtest.NewWithDatabase(t, &wirtualdtest.Options{Auditor: auditor})
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        owner.UserID,
+	}).WithAgent().Do()
+	_ = agenttest.New(t, client.URL, r.AgentToken)
+	resources := wirtualdtest.AwaitWorkspaceAgents(t, client, r.Workspace.ID)
+	agentID := resources[0].Agents[0].ID
+
+	t.Run("Output", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		var stdout, stderr bytes.Buffer
+		result, err := client.WorkspaceAgentExec(ctx, agentID, wirtualsdk.WorkspaceAgentExecRequest{
+			Command: []string{"sh", "-c", "echo out; echo err >&2; exit 3"},
+		}, &stdout, &stderr)
+		require.NoError(t, err)
+		require.Equal(t, 3, result.ExitCode)
+		require.False(t, result.TimedOut)
+		require.Empty(t, result.Error)
+		require.Equal(t, "out\n", stdout.String())
+		require.Equal(t, "err\n", stderr.String())
+
+		// The audit log is committed once the handler returns, which can be
+		// after the exit event was received.
+		require.Eventually(t, func() bool {
+			return auditor.Contains(t, database.AuditLog{
+				ResourceType:   database.ResourceTypeWorkspace,
+				Action:         database.AuditActionExec,
+				ResourceTarget: r.Workspace.Name,
+			})
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+
+	t.Run("EnvAndDirectory", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		dir := t.TempDir()
+		var stdout bytes.Buffer
+		result, err := client.WorkspaceAgentExec(ctx, agentID, wirtualsdk.WorkspaceAgentExecRequest{
+			Command:          []string{"sh", "-c", `echo "$GREETING"; pwd`},
+			Env:              map[string]string{"GREETING": "hello world"},
+			WorkingDirectory: dir,
+		}, &stdout, io.Discard)
+		require.NoError(t, err)
+		require.Equal(t, 0, result.ExitCode)
+		require.Equal(t, "hello world\n"+dir+"\n", stdout.String())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		result, err := client.WorkspaceAgentExec(ctx, agentID, wirtualsdk.WorkspaceAgentExecRequest{
+			Command:       []string{"sleep", "30"},
+			TimeoutMillis: 100,
+		}, io.Discard, io.Discard)
+		require.NoError(t, err)
+		require.True(t, result.TimedOut)
+		require.NotEqual(t, 0, result.ExitCode)
+	})
+
+	t.Run("MissingDirectory", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.WorkspaceAgentExec(ctx, agentID, wirtualsdk.WorkspaceAgentExecRequest{
+			Command:          []string{"true"},
+			WorkingDirectory: filepath.Join(t.TempDir(), "missing"),
+		}, io.Discard, io.Discard)
+		var apiErr *wirtualsdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("NoCommand", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.WorkspaceAgentExec(ctx, agentID, wirtualsdk.WorkspaceAgentExecRequest{}, io.Discard, io.Discard)
+		var apiErr *wirtualsdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("NotAuthorized", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		member, _ := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+		_, err := member.WorkspaceAgentExec(ctx, agentID, wirtualsdk.WorkspaceAgentExecRequest{
+			Command: []string{"true"},
+		}, io.Discard, io.Discard)
+		var apiErr *wirtualsdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
 func TestWorkspaceAgentPostLogSource(t *testing.T) {
 	t.Parallel()
 
@@ -2041,7 +2292,7 @@ func requireGetManifest(ctx context.Context, t testing.TB, aAPI agentproto.DRPCA
 }
 
 func postStartup(ctx context.Context, t testing.TB, client agent.Client, startup *agentproto.Startup) error {
-	aAPI, _, err := client.ConnectRPC23(ctx)
+	aAPI, _, err := client.ConnectRPC24(ctx)
 	require.NoError(t, err)
 	defer func() {
 		cErr := aAPI.DRPCConn().Close()
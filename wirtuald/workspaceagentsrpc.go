@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/netip"
 	"runtime/pprof"
 	"sync"
 	"sync/atomic"
@@ -85,6 +86,24 @@ func (api *API) workspaceAgentRPC(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// DNS records with a port have addresses of their own, which the agent
+	// advertises alongside its own addresses.
+	//nolint:gocritic // System access to read the DNS records of the agent.
+	dnsRecords, err := api.Database.GetWorkspaceAgentDNSRecordsByAgentIDs(dbauthz.AsSystemRestricted(ctx), []uuid.UUID{workspaceAgent.ID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching workspace agent DNS records.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	var dnsRecordAddrs []netip.Addr
+	for _, record := range dnsRecords {
+		if record.Port != 0 {
+			dnsRecordAddrs = append(dnsRecordAddrs, tailnet.DNSRecordAddr(workspaceAgent.ID, record.Name))
+		}
+	}
+
 	logger = logger.With(
 		slog.F("owner", owner.Username),
 		slog.F("workspace_name", workspace.Name),
@@ -164,12 +183,13 @@ func (api *API) workspaceAgentRPC(rw http.ResponseWriter, r *http.Request) {
 
 		// Optional:
 		UpdateAgentMetricsFn: api.UpdateAgentMetrics,
+		AgentUpdateFn:        api.agentUpdate,
 	})
 
 	streamID := tailnet.StreamID{
 		Name: fmt.Sprintf("%s-%s-%s", owner.Username, workspace.Name, workspaceAgent.Name),
 		ID:   workspaceAgent.ID,
-		Auth: tailnet.AgentCoordinateeAuth{ID: workspaceAgent.ID},
+		Auth: tailnet.AgentCoordinateeAuth{ID: workspaceAgent.ID, DNSRecordAddrs: dnsRecordAddrs},
 	}
 	ctx = tailnet.WithStreamID(ctx, streamID)
 	ctx = agentapi.WithAPIVersion(ctx, version)
@@ -332,6 +332,9 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 	if createBuild.TemplateVersionID != uuid.Nil {
 		builder = builder.VersionID(createBuild.TemplateVersionID)
 	}
+	if createBuild.Priority != "" {
+		builder = builder.Priority(database.ProvisionerJobPriority(createBuild.Priority))
+	}
 
 	if createBuild.Orphan {
 		if createBuild.Transition != wirtualsdk.WorkspaceTransitionDelete {
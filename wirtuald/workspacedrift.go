@@ -0,0 +1,60 @@
+package wirtuald
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpmw"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// @Summary Get workspace drift
+// @ID get-workspace-drift
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 200 {object} wirtualsdk.WorkspaceDrift
+// @Router /workspaces/{workspace}/drift [get]
+func (api *API) workspaceDrift(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+
+	build, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiDrift := wirtualsdk.WorkspaceDrift{
+		BuildID:     build.ID,
+		BuildNumber: build.BuildNumber,
+		Resources:   []wirtualsdk.WorkspaceDriftResource{},
+	}
+	drift, err := api.Database.GetWorkspaceDriftByBuildID(ctx, build.ID)
+	if err != nil && !httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching workspace drift.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Builds that haven't been checked yet have no drift.
+	if err == nil && drift.CheckedAt.Valid {
+		apiDrift.CheckedAt = &drift.CheckedAt.Time
+		err = json.Unmarshal(drift.Resources, &apiDrift.Resources)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+				Message: "Internal error reading workspace drift.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, apiDrift)
+}
@@ -0,0 +1,113 @@
+package wirtuald_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbfake"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbgen"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbtime"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/provisionerdserver"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestWorkspaceDrift(t *testing.T) {
+	t.Parallel()
+
+	client, store := wirtualdtest.NewWithDatabase(t, nil)
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	member, memberUser := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	r := dbfake.WorkspaceBuild(t, store, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        memberUser.ID,
+	}).Do()
+
+	t.Run("NotChecked", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		other := dbfake.WorkspaceBuild(t, store, database.WorkspaceTable{
+			OrganizationID: owner.OrganizationID,
+			OwnerID:        memberUser.ID,
+		}).Do()
+		drift, err := member.WorkspaceDrift(ctx, other.Workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, other.Build.ID, drift.BuildID)
+		require.Nil(t, drift.CheckedAt)
+		require.Empty(t, drift.Resources)
+
+		workspace, err := member.Workspace(ctx, other.Workspace.ID)
+		require.NoError(t, err)
+		require.False(t, workspace.HasDrift)
+	})
+
+	t.Run("Drifted", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		resources := []wirtualsdk.WorkspaceDriftResource{{
+			Address: "docker_container.workspace",
+			Type:    "docker_container",
+			Action:  wirtualsdk.WorkspaceDriftActionUpdate,
+			Attributes: []wirtualsdk.WorkspaceDriftAttribute{{
+				Name:   "memory",
+				Before: "512",
+				After:  "1024",
+			}, {
+				Name:      "env",
+				Sensitive: true,
+			}},
+		}}
+		recordDrift(t, store, r.Build.ID, resources)
+
+		drift, err := member.WorkspaceDrift(ctx, r.Workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, r.Build.ID, drift.BuildID)
+		require.NotNil(t, drift.CheckedAt)
+		require.Equal(t, resources, drift.Resources)
+
+		workspace, err := member.Workspace(ctx, r.Workspace.ID)
+		require.NoError(t, err)
+		require.True(t, workspace.HasDrift)
+	})
+}
+
+// recordDrift stores the result of a completed drift check of the build.
+func recordDrift(t *testing.T, store database.Store, buildID uuid.UUID, resources []wirtualsdk.WorkspaceDriftResource) {
+	t.Helper()
+	ctx := dbauthz.AsSystemRestricted(testutil.Context(t, testutil.WaitShort))
+
+	input, err := json.Marshal(provisionerdserver.WorkspaceDriftCheckJob{
+		WorkspaceBuildID: buildID,
+	})
+	require.NoError(t, err)
+	job := dbgen.ProvisionerJob(t, store, nil, database.ProvisionerJob{
+		Type:  database.ProvisionerJobTypeWorkspaceDriftCheck,
+		Input: input,
+	})
+	err = store.UpsertWorkspaceDriftCheck(ctx, database.UpsertWorkspaceDriftCheckParams{
+		WorkspaceBuildID: buildID,
+		JobID:            job.ID,
+		CreatedAt:        dbtime.Now(),
+	})
+	require.NoError(t, err)
+	data, err := json.Marshal(resources)
+	require.NoError(t, err)
+	_, err = store.UpdateWorkspaceDriftByJobID(ctx, database.UpdateWorkspaceDriftByJobIDParams{
+		JobID: job.ID,
+		CheckedAt: sql.NullTime{
+			Time:  dbtime.Now(),
+			Valid: true,
+		},
+		Resources: data,
+	})
+	require.NoError(t, err)
+}
@@ -107,6 +107,7 @@ func (api *API) workspace(rw http.ResponseWriter, r *http.Request) {
 		data.builds[0],
 		data.templates[0],
 		api.Options.AllowWorkspaceRenames,
+		data.drifted[data.builds[0].ID],
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
@@ -305,6 +306,7 @@ func (api *API) workspaceByOwnerAndName(rw http.ResponseWriter, r *http.Request)
 		data.builds[0],
 		data.templates[0],
 		api.Options.AllowWorkspaceRenames,
+		data.drifted[data.builds[0].ID],
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
@@ -711,6 +713,7 @@ func createWorkspace(
 		apiBuild,
 		template,
 		api.Options.AllowWorkspaceRenames,
+		false,
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
@@ -1112,6 +1115,7 @@ func (api *API) putWorkspaceDormant(rw http.ResponseWriter, r *http.Request) {
 		data.builds[0],
 		data.templates[0],
 		api.Options.AllowWorkspaceRenames,
+		data.drifted[data.builds[0].ID],
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
@@ -1661,6 +1665,7 @@ func (api *API) watchWorkspace(rw http.ResponseWriter, r *http.Request) {
 			data.builds[0],
 			data.templates[0],
 			api.Options.AllowWorkspaceRenames,
+			data.drifted[data.builds[0].ID],
 		)
 		if err != nil {
 			_ = sendEvent(ctx, wirtualsdk.ServerSentEvent{
@@ -1772,6 +1777,9 @@ type workspaceData struct {
 	templates    []database.Template
 	builds       []wirtualsdk.WorkspaceBuild
 	allowRenames bool
+	// drifted contains the IDs of the builds whose latest drift check found
+	// drifted resources.
+	drifted map[uuid.UUID]bool
 }
 
 // workspacesData only returns the data the caller can access. If the caller
@@ -1821,10 +1829,30 @@ func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspa
 		return workspaceData{}, xerrors.Errorf("convert workspace builds: %w", err)
 	}
 
+	buildIDs := make([]uuid.UUID, 0, len(builds))
+	for _, build := range builds {
+		buildIDs = append(buildIDs, build.ID)
+	}
+	// The builds are already fetched, so the caller can read their drift.
+	// nolint:gocritic
+	drift, err := api.Database.GetWorkspaceDriftByBuildIDs(dbauthz.AsSystemRestricted(ctx), buildIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return workspaceData{}, xerrors.Errorf("get workspace drift: %w", err)
+	}
+	drifted := make(map[uuid.UUID]bool, len(drift))
+	for _, d := range drift {
+		var resources []json.RawMessage
+		if err := json.Unmarshal(d.Resources, &resources); err != nil {
+			return workspaceData{}, xerrors.Errorf("unmarshal workspace drift: %w", err)
+		}
+		drifted[d.WorkspaceBuildID] = len(resources) > 0
+	}
+
 	return workspaceData{
 		templates:    templates,
 		builds:       apiBuilds,
 		allowRenames: api.Options.AllowWorkspaceRenames,
+		drifted:      drifted,
 	}, nil
 }
 
@@ -1860,6 +1888,7 @@ func convertWorkspaces(requesterID uuid.UUID, workspaces []database.Workspace, d
 			build,
 			template,
 			data.allowRenames,
+			data.drifted[build.ID],
 		)
 		if err != nil {
 			return nil, xerrors.Errorf("convert workspace: %w", err)
@@ -1876,6 +1905,7 @@ func convertWorkspace(
 	workspaceBuild wirtualsdk.WorkspaceBuild,
 	template database.Template,
 	allowRenames bool,
+	hasDrift bool,
 ) (wirtualsdk.Workspace, error) {
 	if requesterID == uuid.Nil {
 		return wirtualsdk.Workspace{}, xerrors.Errorf("developer error: requesterID cannot be uuid.Nil!")
@@ -1945,6 +1975,7 @@ func convertWorkspace(
 		AutomaticUpdates: wirtualsdk.AutomaticUpdates(workspace.AutomaticUpdates),
 		AllowRenames:     allowRenames,
 		Favorite:         requesterFavorite,
+		HasDrift:         hasDrift,
 	}, nil
 }
 
@@ -0,0 +1,339 @@
+package wirtuald
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/onchainengineering/hmi-wirtual/provisioner/terraform/tfstate"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/audit"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbauthz"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/provisionerjobs"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpapi"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/httpmw"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/rbac/policy"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wsbuilder"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wspubsub"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+// @Summary Get workspace state history
+// @ID get-workspace-state-history
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 200 {array} wirtualsdk.WorkspaceState
+// @Router /workspaces/{workspace}/states [get]
+func (api *API) workspaceStates(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+	if !api.authorizeWorkspaceState(rw, r, workspace) {
+		return
+	}
+
+	builds, err := api.Database.GetWorkspaceBuildsByWorkspaceID(ctx, database.GetWorkspaceBuildsByWorkspaceIDParams{
+		WorkspaceID: workspace.ID,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching workspace builds.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	states := make([]wirtualsdk.WorkspaceState, 0, len(builds))
+	for _, build := range builds {
+		state := wirtualsdk.WorkspaceState{
+			BuildID:       build.ID,
+			BuildNumber:   build.BuildNumber,
+			Transition:    wirtualsdk.WorkspaceTransition(build.Transition),
+			CreatedAt:     build.CreatedAt,
+			InitiatorName: build.InitiatorByUsername,
+			Size:          len(build.ProvisionerState),
+		}
+		if len(build.ProvisionerState) > 0 {
+			// States of other provisioners can't be parsed, so they don't
+			// have a resource count.
+			if parsed, err := tfstate.Parse(build.ProvisionerState); err == nil {
+				count := len(tfstate.Resources(parsed))
+				state.ResourceCount = &count
+			}
+		}
+		states = append(states, state)
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, states)
+}
+
+// @Summary Compare workspace states
+// @ID compare-workspace-states
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param from query int true "Build number of the state to compare from"
+// @Param to query int true "Build number of the state to compare to"
+// @Success 200 {object} wirtualsdk.WorkspaceStateDiff
+// @Router /workspaces/{workspace}/states/diff [get]
+func (api *API) workspaceStateDiff(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+	if !api.authorizeWorkspaceState(rw, r, workspace) {
+		return
+	}
+
+	p := httpapi.NewQueryParamParser().
+		RequiredNotEmpty("from").
+		RequiredNotEmpty("to")
+	vals := r.URL.Query()
+	var (
+		from = p.PositiveInt32(vals, 0, "from")
+		to   = p.PositiveInt32(vals, 0, "to")
+	)
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	fromBuild, ok := api.workspaceStateBuild(rw, r, workspace, from)
+	if !ok {
+		return
+	}
+	toBuild, ok := api.workspaceStateBuild(rw, r, workspace, to)
+	if !ok {
+		return
+	}
+	fromState, err := tfstate.Parse(fromBuild.ProvisionerState)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: fmt.Sprintf("The state of build %d is not a Terraform state.", from),
+			Detail:  err.Error(),
+		})
+		return
+	}
+	toState, err := tfstate.Parse(toBuild.ProvisionerState)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: fmt.Sprintf("The state of build %d is not a Terraform state.", to),
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	diff := tfstate.Diff(fromState, toState)
+	apiDiff := wirtualsdk.WorkspaceStateDiff{
+		FromBuildNumber: from,
+		ToBuildNumber:   to,
+		Added:           append([]string{}, diff.Added...),
+		Removed:         append([]string{}, diff.Removed...),
+		Changed:         make([]wirtualsdk.WorkspaceStateResourceChange, 0, len(diff.Changed)),
+	}
+	for _, change := range diff.Changed {
+		apiDiff.Changed = append(apiDiff.Changed, wirtualsdk.WorkspaceStateResourceChange{
+			Address:    change.Address,
+			Attributes: change.Attributes,
+		})
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiDiff)
+}
+
+// @Summary Restore workspace state
+// @ID restore-workspace-state
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body wirtualsdk.RestoreWorkspaceStateRequest true "Restore workspace state request"
+// @Success 201 {object} wirtualsdk.WorkspaceBuild
+// @Router /workspaces/{workspace}/states/restore [post]
+func (api *API) postWorkspaceStateRestore(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+		apiKey    = httpmw.APIKey(r)
+		auditor   = api.Auditor.Load()
+	)
+	if !api.authorizeWorkspaceState(rw, r, workspace) {
+		return
+	}
+
+	var req wirtualsdk.RestoreWorkspaceStateRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	// The build that restores the state is audited, since the workspace
+	// itself doesn't change. Its build number is only known once it's
+	// created.
+	additionalFields := &audit.AdditionalFields{
+		WorkspaceName:  workspace.Name,
+		WorkspaceOwner: workspace.OwnerUsername,
+		WorkspaceID:    workspace.ID,
+	}
+	aReq, commitAudit := audit.InitRequest[database.WorkspaceBuild](rw, &audit.RequestParams{
+		Audit:            *auditor,
+		Log:              api.Logger,
+		Request:          r,
+		Action:           database.AuditActionCreate,
+		OrganizationID:   workspace.OrganizationID,
+		AdditionalFields: additionalFields,
+	})
+	defer commitAudit()
+
+	build, ok := api.workspaceStateBuild(rw, r, workspace, req.BuildNumber)
+	if !ok {
+		return
+	}
+	latestBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// The new build repeats the latest build with the restored state, so the
+	// next build of the workspace starts from the old state.
+	builder := wsbuilder.New(workspace, latestBuild.Transition).
+		Initiator(apiKey.UserID).
+		VersionID(latestBuild.TemplateVersionID).
+		State(build.ProvisionerState).
+		DeploymentValues(api.Options.DeploymentValues)
+
+	// Like pushing a state, restoring one only requires update permissions
+	// on the template, which were checked above. Template admins can't
+	// build the workspaces of other users, so the build is inserted as the
+	// system, with the user as its initiator.
+	// nolint:gocritic // The template permissions were checked above.
+	workspaceBuild, provisionerJob, err := builder.Build(
+		dbauthz.AsSystemRestricted(ctx),
+		api.Database,
+		nil,
+		audit.WorkspaceBuildBaggageFromRequest(r),
+	)
+	var buildErr wsbuilder.BuildError
+	if xerrors.As(err, &buildErr) {
+		var authErr dbauthz.NotAuthorizedError
+		if xerrors.As(err, &authErr) {
+			buildErr.Status = http.StatusForbidden
+		}
+
+		if buildErr.Status == http.StatusInternalServerError {
+			api.Logger.Error(ctx, "workspace build error", slog.Error(buildErr.Wrapped))
+		}
+
+		httpapi.Write(ctx, rw, buildErr.Status, wirtualsdk.Response{
+			Message: buildErr.Message,
+			Detail:  buildErr.Error(),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Error posting new build",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = *workspaceBuild
+	additionalFields.BuildNumber = strconv.FormatInt(int64(workspaceBuild.BuildNumber), 10)
+
+	err = provisionerjobs.PostJob(api.Pubsub, *provisionerJob)
+	if err != nil {
+		// Client probably doesn't care about this error, so just log it.
+		api.Logger.Error(ctx, "failed to post provisioner job to pubsub", slog.Error(err))
+	}
+
+	apiBuild, err := api.convertWorkspaceBuild(
+		*workspaceBuild,
+		workspace,
+		database.GetProvisionerJobsByIDsWithQueuePositionRow{
+			ProvisionerJob: *provisionerJob,
+			QueuePosition:  0,
+		},
+		[]database.WorkspaceResource{},
+		[]database.WorkspaceResourceMetadatum{},
+		[]database.WorkspaceAgent{},
+		[]database.WorkspaceApp{},
+		[]database.WorkspaceAgentScript{},
+		[]database.WorkspaceAgentLogSource{},
+		database.TemplateVersion{},
+	)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error converting workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.publishWorkspaceUpdate(ctx, workspace.OwnerID, wspubsub.WorkspaceEvent{
+		Kind:        wspubsub.WorkspaceEventKindStateChange,
+		WorkspaceID: workspace.ID,
+	})
+
+	httpapi.Write(ctx, rw, http.StatusCreated, apiBuild)
+}
+
+// authorizeWorkspaceState checks that the user may read and write the state of
+// the workspace. Like a state push, this requires update permissions on the
+// template.
+func (api *API) authorizeWorkspaceState(rw http.ResponseWriter, r *http.Request, workspace database.Workspace) bool {
+	ctx := r.Context()
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Failed to get template",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+	if !api.Authorize(r, policy.ActionUpdate, template.RBACObject()) {
+		httpapi.ResourceNotFound(rw)
+		return false
+	}
+	return true
+}
+
+// workspaceStateBuild returns the build of the workspace with the given build
+// number, as long as its state is still retained.
+func (api *API) workspaceStateBuild(rw http.ResponseWriter, r *http.Request, workspace database.Workspace, buildNumber int32) (database.WorkspaceBuild, bool) {
+	ctx := r.Context()
+	build, err := api.Database.GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx, database.GetWorkspaceBuildByWorkspaceIDAndBuildNumberParams{
+		WorkspaceID: workspace.ID,
+		BuildNumber: buildNumber,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, wirtualsdk.Response{
+			Message: fmt.Sprintf("Workspace %q Build %d does not exist.", workspace.Name, buildNumber),
+		})
+		return database.WorkspaceBuild{}, false
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, wirtualsdk.Response{
+			Message: "Internal error fetching workspace build.",
+			Detail:  err.Error(),
+		})
+		return database.WorkspaceBuild{}, false
+	}
+	if len(build.ProvisionerState) == 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, wirtualsdk.Response{
+			Message: fmt.Sprintf("Build %d has no state. It may have been removed by the state retention policy.", buildNumber),
+		})
+		return database.WorkspaceBuild{}, false
+	}
+	return build, true
+}
@@ -0,0 +1,162 @@
+package wirtuald_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/testutil"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/audit"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/database/dbfake"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/rbac"
+	"github.com/onchainengineering/hmi-wirtual/wirtuald/wirtualdtest"
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+const workspaceStateV1 = `{
+  "version": 4,
+  "terraform_version": "1.9.8",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "null_resource",
+      "name": "dev",
+      "provider": "provider[\"registry.terraform.io/hashicorp/null\"]",
+      "instances": [{"schema_version": 0, "attributes": {"id": "1", "triggers": {"a": "1"}}}]
+    }
+  ]
+}`
+
+const workspaceStateV2 = `{
+  "version": 4,
+  "terraform_version": "1.9.8",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "null_resource",
+      "name": "dev",
+      "provider": "provider[\"registry.terraform.io/hashicorp/null\"]",
+      "instances": [{"schema_version": 0, "attributes": {"id": "1", "triggers": {"a": "2"}}}]
+    },
+    {
+      "mode": "managed",
+      "type": "null_resource",
+      "name": "extra",
+      "provider": "provider[\"registry.terraform.io/hashicorp/null\"]",
+      "instances": [{"schema_version": 0, "attributes": {"id": "2"}}]
+    }
+  ]
+}`
+
+func TestWorkspaceStates(t *testing.T) {
+	t.Parallel()
+
+	client, store := wirtualdtest.NewWithDatabase(t, nil)
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	templateAdmin, _ := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID, rbac.RoleTemplateAdmin())
+	member, memberUser := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	workspace := createWorkspaceWithStates(t, store, owner.OrganizationID, memberUser.ID)
+
+	t.Run("History", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		states, err := templateAdmin.WorkspaceStates(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Len(t, states, 2)
+		for _, state := range states {
+			require.NotNil(t, state.ResourceCount)
+			switch state.BuildNumber {
+			case 1:
+				require.Equal(t, 1, *state.ResourceCount)
+				require.Equal(t, len(workspaceStateV1), state.Size)
+			case 2:
+				require.Equal(t, 2, *state.ResourceCount)
+				require.Equal(t, len(workspaceStateV2), state.Size)
+			}
+		}
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		diff, err := templateAdmin.WorkspaceStateDiff(ctx, workspace.ID, 1, 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"null_resource.extra"}, diff.Added)
+		require.Empty(t, diff.Removed)
+		require.Equal(t, []wirtualsdk.WorkspaceStateResourceChange{{
+			Address:    "null_resource.dev",
+			Attributes: []string{"triggers"},
+		}}, diff.Changed)
+	})
+
+	t.Run("MissingBuild", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		_, err := templateAdmin.WorkspaceStateDiff(ctx, workspace.ID, 1, 5)
+		var sdkErr *wirtualsdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+	})
+
+	t.Run("RequiresTemplateUpdate", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		// The owner of the workspace can't read its state, just like
+		// they can't pull it.
+		_, err := member.WorkspaceStates(ctx, workspace.ID)
+		var sdkErr *wirtualsdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+	})
+}
+
+func TestWorkspaceStateRestore(t *testing.T) {
+	t.Parallel()
+
+	auditor := audit.NewMock()
+	client, store := wirtualdtest.NewWithDatabase(t, &wirtualdtest.Options{Auditor: auditor})
+	owner := wirtualdtest.CreateFirstUser(t, client)
+	templateAdmin, _ := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID, rbac.RoleTemplateAdmin())
+	_, memberUser := wirtualdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	workspace := createWorkspaceWithStates(t, store, owner.OrganizationID, memberUser.ID)
+	ctx := testutil.Context(t, testutil.WaitShort)
+
+	build, err := templateAdmin.RestoreWorkspaceState(ctx, workspace.ID, wirtualsdk.RestoreWorkspaceStateRequest{
+		BuildNumber: 1,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, build.BuildNumber)
+
+	state, err := templateAdmin.WorkspaceBuildState(ctx, build.ID)
+	require.NoError(t, err)
+	require.Equal(t, workspaceStateV1, string(state))
+
+	require.True(t, auditor.Contains(t, database.AuditLog{
+		ResourceType: database.ResourceTypeWorkspaceBuild,
+		ResourceID:   build.ID,
+		Action:       database.AuditActionCreate,
+	}))
+}
+
+// createWorkspaceWithStates creates a workspace with two builds, whose states
+// are workspaceStateV1 and workspaceStateV2.
+func createWorkspaceWithStates(t *testing.T, store database.Store, orgID, ownerID uuid.UUID) database.WorkspaceTable {
+	t.Helper()
+	r := dbfake.WorkspaceBuild(t, store, database.WorkspaceTable{
+		OrganizationID: orgID,
+		OwnerID:        ownerID,
+	}).
+		Seed(database.WorkspaceBuild{ProvisionerState: []byte(workspaceStateV1)}).
+		Do()
+	_ = dbfake.WorkspaceBuild(t, store, r.Workspace).
+		Seed(database.WorkspaceBuild{BuildNumber: 2, ProvisionerState: []byte(workspaceStateV2)}).
+		Do()
+	return r.Workspace
+}
@@ -25,6 +25,7 @@ type UpdatesQuerier interface {
 	// GetAuthorizedWorkspacesAndAgentsByOwnerID requires a context with an actor set
 	GetWorkspacesAndAgentsByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]database.GetWorkspacesAndAgentsByOwnerIDRow, error)
 	GetWorkspaceByAgentID(ctx context.Context, agentID uuid.UUID) (database.Workspace, error)
+	GetWorkspaceAgentDNSRecordsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentDNSRecord, error)
 }
 
 type workspacesByID = map[uuid.UUID]ownedWorkspace
@@ -33,6 +34,9 @@ type ownedWorkspace struct {
 	WorkspaceName string
 	Status        proto.Workspace_Status
 	Agents        []database.AgentIDNamePair
+	// DNSRecords are the DNS records of each agent. They are set when the
+	// agent is created, so they never change for a given agent ID.
+	DNSRecords map[uuid.UUID][]*proto.Agent_DNSRecord
 }
 
 // Equal does not compare agents
@@ -77,12 +81,11 @@ func (s *sub) handleEvent(ctx context.Context, event wspubsub.WorkspaceEvent, er
 	}
 
 	// Use context containing actor
-	rows, err := s.db.GetWorkspacesAndAgentsByOwnerID(s.ctx, s.userID)
+	latest, err := getOwnedWorkspaces(s.ctx, s.db, s.userID)
 	if err != nil {
 		s.logger.Warn(ctx, "failed to get workspaces and agents by owner ID", slog.Error(err))
 		return
 	}
-	latest := convertRows(rows)
 
 	out, updated := produceUpdate(s.prev, latest)
 	if !updated {
@@ -98,12 +101,11 @@ func (s *sub) handleEvent(ctx context.Context, event wspubsub.WorkspaceEvent, er
 }
 
 func (s *sub) start(ctx context.Context) (err error) {
-	rows, err := s.db.GetWorkspacesAndAgentsByOwnerID(ctx, s.userID)
+	latest, err := getOwnedWorkspaces(ctx, s.db, s.userID)
 	if err != nil {
 		return xerrors.Errorf("get workspaces and agents by owner ID: %w", err)
 	}
 
-	latest := convertRows(rows)
 	initUpdate, _ := produceUpdate(workspacesByID{}, latest)
 	s.ch <- initUpdate
 	s.prev = latest
@@ -221,6 +223,7 @@ func produceUpdate(old, new workspacesByID) (out *proto.WorkspaceUpdate, updated
 					Id:          tailnet.UUIDToByteSlice(agent.ID),
 					Name:        agent.Name,
 					WorkspaceId: tailnet.UUIDToByteSlice(wsID),
+					DnsRecords:  newWorkspace.DNSRecords[agent.ID],
 				})
 			}
 			updated = true
@@ -242,6 +245,7 @@ func produceUpdate(old, new workspacesByID) (out *proto.WorkspaceUpdate, updated
 				Id:          tailnet.UUIDToByteSlice(agent.ID),
 				Name:        agent.Name,
 				WorkspaceId: tailnet.UUIDToByteSlice(wsID),
+				DnsRecords:  newWorkspace.DNSRecords[agent.ID],
 			})
 			updated = true
 		}
@@ -277,20 +281,58 @@ func produceUpdate(old, new workspacesByID) (out *proto.WorkspaceUpdate, updated
 	return out, updated
 }
 
-func convertRows(rows []database.GetWorkspacesAndAgentsByOwnerIDRow) workspacesByID {
+// getOwnedWorkspaces returns the workspaces, agents and agent DNS records of
+// the user. ctx must contain an actor.
+func getOwnedWorkspaces(ctx context.Context, db UpdatesQuerier, userID uuid.UUID) (workspacesByID, error) {
+	rows, err := db.GetWorkspacesAndAgentsByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var agentIDs []uuid.UUID
+	for _, row := range rows {
+		for _, agent := range row.Agents {
+			agentIDs = append(agentIDs, agent.ID)
+		}
+	}
+	if len(agentIDs) == 0 {
+		return convertRows(rows, nil), nil
+	}
+	// The agent IDs come from the query above, which only returns the
+	// workspaces the actor is authorized to read.
+	//nolint:gocritic // System access to read the DNS records of those agents.
+	records, err := db.GetWorkspaceAgentDNSRecordsByAgentIDs(dbauthz.AsSystemRestricted(ctx), agentIDs)
+	if err != nil {
+		return nil, xerrors.Errorf("get workspace agent DNS records: %w", err)
+	}
+	return convertRows(rows, records), nil
+}
+
+func convertRows(rows []database.GetWorkspacesAndAgentsByOwnerIDRow, records []database.WorkspaceAgentDNSRecord) workspacesByID {
+	recordsByAgent := map[uuid.UUID][]*proto.Agent_DNSRecord{}
+	for _, record := range records {
+		recordsByAgent[record.WorkspaceAgentID] = append(recordsByAgent[record.WorkspaceAgentID], &proto.Agent_DNSRecord{
+			Name: record.Name,
+			Port: record.Port,
+		})
+	}
 	out := workspacesByID{}
 	for _, row := range rows {
 		agents := []database.AgentIDNamePair{}
+		dnsRecords := map[uuid.UUID][]*proto.Agent_DNSRecord{}
 		for _, agent := range row.Agents {
 			agents = append(agents, database.AgentIDNamePair{
 				ID:   agent.ID,
 				Name: agent.Name,
 			})
+			if agentRecords, ok := recordsByAgent[agent.ID]; ok {
+				dnsRecords[agent.ID] = agentRecords
+			}
 		}
 		out[row.ID] = ownedWorkspace{
 			WorkspaceName: row.Name,
 			Status:        tailnet.WorkspaceStatusToProto(wirtualsdk.ConvertWorkspaceStatus(wirtualsdk.ProvisionerJobStatus(row.JobStatus), wirtualsdk.WorkspaceTransition(row.Transition))),
 			Agents:        agents,
+			DNSRecords:    dnsRecords,
 		}
 	}
 	return out
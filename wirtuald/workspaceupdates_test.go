@@ -302,6 +302,63 @@ func TestWorkspaceUpdates(t *testing.T) {
 		})
 		require.Equal(t, expected, update)
 	})
+
+	t.Run("DNSRecords", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		db := &mockWorkspaceStore{
+			orderedRows: []database.GetWorkspacesAndAgentsByOwnerIDRow{
+				{
+					ID:         ws1ID,
+					Name:       "ws1",
+					JobStatus:  database.ProvisionerJobStatusRunning,
+					Transition: database.WorkspaceTransitionStart,
+					Agents: []database.AgentIDNamePair{
+						{
+							ID:   agent1ID,
+							Name: "agent1",
+						},
+					},
+				},
+			},
+			dnsRecords: []database.WorkspaceAgentDNSRecord{
+				{WorkspaceAgentID: agent1ID, Name: "api", Port: 8080},
+				{WorkspaceAgentID: agent1ID, Name: "*.apps"},
+				// Not one of the user's agents.
+				{WorkspaceAgentID: uuid.UUID{0xff}, Name: "other"},
+			},
+		}
+
+		ps := &mockPubsub{
+			cbs: map[string]pubsub.ListenerWithErr{},
+		}
+
+		updateProvider := wirtuald.NewUpdatesProvider(testutil.Logger(t), ps, db, &mockAuthorizer{})
+		t.Cleanup(func() {
+			_ = updateProvider.Close()
+		})
+
+		sub, err := updateProvider.Subscribe(dbauthz.As(ctx, ownerSubject), ownerID)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = sub.Close()
+		})
+
+		update := testutil.RequireRecvCtx(ctx, t, sub.Updates())
+		require.Equal(t, []*proto.Agent{
+			{
+				Id:          agent1IDSlice,
+				Name:        "agent1",
+				WorkspaceId: ws1IDSlice,
+				DnsRecords: []*proto.Agent_DNSRecord{
+					{Name: "api", Port: 8080},
+					{Name: "*.apps"},
+				},
+			},
+		}, update.UpsertedAgents)
+	})
 }
 
 func publishWorkspaceEvent(t *testing.T, ps pubsub.Pubsub, ownerID uuid.UUID, event *wspubsub.WorkspaceEvent) {
@@ -312,6 +369,7 @@ func publishWorkspaceEvent(t *testing.T, ps pubsub.Pubsub, ownerID uuid.UUID, ev
 
 type mockWorkspaceStore struct {
 	orderedRows []database.GetWorkspacesAndAgentsByOwnerIDRow
+	dnsRecords  []database.WorkspaceAgentDNSRecord
 }
 
 // GetAuthorizedWorkspacesAndAgentsByOwnerID implements wirtuald.UpdatesQuerier.
@@ -324,6 +382,17 @@ func (*mockWorkspaceStore) GetWorkspaceByAgentID(context.Context, uuid.UUID) (da
 	return database.Workspace{}, nil
 }
 
+// GetWorkspaceAgentDNSRecordsByAgentIDs implements wirtuald.UpdatesQuerier.
+func (m *mockWorkspaceStore) GetWorkspaceAgentDNSRecordsByAgentIDs(_ context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentDNSRecord, error) {
+	var out []database.WorkspaceAgentDNSRecord
+	for _, record := range m.dnsRecords {
+		if slices.Contains(ids, record.WorkspaceAgentID) {
+			out = append(out, record)
+		}
+	}
+	return out, nil
+}
+
 var _ wirtuald.UpdatesQuerier = (*mockWorkspaceStore)(nil)
 
 type mockPubsub struct {
@@ -53,6 +53,7 @@ type Builder struct {
 	richParameterValues []wirtualsdk.WorkspaceBuildParameter
 	initiator           uuid.UUID
 	reason              database.BuildReason
+	priority            database.ProvisionerJobPriority
 
 	// used during build, makes function arguments less verbose
 	ctx   context.Context
@@ -160,6 +161,15 @@ func (b Builder) Reason(r database.BuildReason) Builder {
 	return b
 }
 
+// Priority sets the priority of the provisioner job of the build. By default,
+// builds started by the lifecycle executor get the autostart priority, and all
+// other builds the interactive priority.
+func (b Builder) Priority(p database.ProvisionerJobPriority) Builder {
+	// nolint: revive
+	b.priority = p
+	return b
+}
+
 func (b Builder) RichParameterValues(p []wirtualsdk.WorkspaceBuildParameter) Builder {
 	// nolint: revive
 	b.richParameterValues = p
@@ -327,6 +337,7 @@ func (b *Builder) buildTx(authFunc func(action policy.Action, object rbac.Object
 			Valid:      true,
 			RawMessage: traceMetadataRaw,
 		},
+		Priority: b.getPriority(),
 	})
 	if err != nil {
 		return nil, nil, BuildError{http.StatusInternalServerError, "insert provisioner job", err}
@@ -493,6 +504,17 @@ func (b *Builder) getBuildNumber() (int32, error) {
 	return bld.BuildNumber + 1, nil
 }
 
+func (b *Builder) getPriority() database.ProvisionerJobPriority {
+	if b.priority != "" {
+		return b.priority
+	}
+	if b.reason == database.BuildReasonInitiator {
+		return database.ProvisionerJobPriorityInteractive
+	}
+	// All other reasons are builds started by the lifecycle executor.
+	return database.ProvisionerJobPriorityAutostart
+}
+
 func (b *Builder) getState() ([]byte, error) {
 	if b.state.orphan {
 		// Orphan means empty state.
@@ -66,6 +66,7 @@ func TestBuilder_NoOptions(t *testing.T) {
 		expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
 			asrt.Equal(userID, job.InitiatorID)
 			asrt.Equal(inactiveFileID, job.FileID)
+			asrt.Equal(database.ProvisionerJobPriorityInteractive, job.Priority)
 			input := provisionerdserver.WorkspaceProvisionJob{}
 			err := json.Unmarshal(job.Input, &input)
 			req.NoError(err)
@@ -194,7 +195,8 @@ func TestBuilder_Reason(t *testing.T) {
 		withWorkspaceTags(inactiveVersionID, nil),
 
 		// Outputs
-		expectProvisionerJob(func(_ database.InsertProvisionerJobParams) {
+		expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
+			asrt.Equal(database.ProvisionerJobPriorityAutostart, job.Priority)
 		}),
 		withInTx,
 		expectBuild(func(bld database.InsertWorkspaceBuildParams) {
@@ -211,6 +213,42 @@ func TestBuilder_Reason(t *testing.T) {
 	req.NoError(err)
 }
 
+func TestBuilder_Priority(t *testing.T) {
+	t.Parallel()
+	req := require.New(t)
+	asrt := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mDB := expectDB(t,
+		// Inputs
+		withTemplate,
+		withInactiveVersion(nil),
+		withLastBuildFound,
+		withRichParameters(nil),
+		withParameterSchemas(inactiveJobID, nil),
+		withWorkspaceTags(inactiveVersionID, nil),
+
+		// Outputs
+		expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
+			asrt.Equal(database.ProvisionerJobPriorityBulk, job.Priority)
+		}),
+		withInTx,
+		expectBuild(func(bld database.InsertWorkspaceBuildParams) {
+			asrt.Equal(database.BuildReasonInitiator, bld.Reason)
+		}),
+		expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {
+		}),
+		withBuild,
+	)
+
+	ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+	uut := wsbuilder.New(ws, database.WorkspaceTransitionStart).Priority(database.ProvisionerJobPriorityBulk)
+	_, _, err := uut.Build(ctx, mDB, nil, audit.WorkspaceBuildBaggage{})
+	req.NoError(err)
+}
+
 func TestBuilder_ActiveVersion(t *testing.T) {
 	t.Parallel()
 	req := require.New(t)
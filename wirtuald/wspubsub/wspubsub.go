@@ -50,11 +50,13 @@ const (
 	WorkspaceEventKindMetadataUpdate  WorkspaceEventKind = "mtd_update"
 	WorkspaceEventKindAppHealthUpdate WorkspaceEventKind = "app_health"
 
-	WorkspaceEventKindAgentLifecycleUpdate  WorkspaceEventKind = "agt_lifecycle_update"
-	WorkspaceEventKindAgentConnectionUpdate WorkspaceEventKind = "agt_connection_update"
-	WorkspaceEventKindAgentFirstLogs        WorkspaceEventKind = "agt_first_logs"
-	WorkspaceEventKindAgentLogsOverflow     WorkspaceEventKind = "agt_logs_overflow"
-	WorkspaceEventKindAgentTimeout          WorkspaceEventKind = "agt_timeout"
+	WorkspaceEventKindAgentLifecycleUpdate    WorkspaceEventKind = "agt_lifecycle_update"
+	WorkspaceEventKindAgentConnectionUpdate   WorkspaceEventKind = "agt_connection_update"
+	WorkspaceEventKindAgentFirstLogs          WorkspaceEventKind = "agt_first_logs"
+	WorkspaceEventKindAgentLogsOverflow       WorkspaceEventKind = "agt_logs_overflow"
+	WorkspaceEventKindAgentTimeout            WorkspaceEventKind = "agt_timeout"
+	WorkspaceEventKindAgentServiceUpdate      WorkspaceEventKind = "agt_service_update"
+	WorkspaceEventKindAgentDevcontainerUpdate WorkspaceEventKind = "agt_devcontainer_update"
 )
 
 func (w *WorkspaceEvent) Validate() error {
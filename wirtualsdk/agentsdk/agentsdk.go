@@ -109,6 +109,33 @@ type Manifest struct {
 	DisableDirectConnections bool                                           `json:"disable_direct_connections"`
 	Metadata                 []wirtualsdk.WorkspaceAgentMetadataDescription `json:"metadata"`
 	Scripts                  []wirtualsdk.WorkspaceAgentScript              `json:"scripts"`
+	Services                 []wirtualsdk.WorkspaceAgentService             `json:"services"`
+	// AgentUpdate is set when the deployment offers the agent a newer
+	// version of itself.
+	AgentUpdate *AgentUpdate `json:"agent_update,omitempty"`
+	// DNSRecords are the DNS records with a port. The agent serves each
+	// record's address, and forwards port 80 on it to the port.
+	DNSRecords []DNSRecord `json:"dns_records"`
+}
+
+// DNSRecord is a template DNS record that maps to a port of the agent.
+type DNSRecord struct {
+	Name string `json:"name"`
+	Port uint16 `json:"port"`
+}
+
+// AgentUpdate is a newer version of the agent binary.
+type AgentUpdate struct {
+	Version string `json:"version"`
+	// OS and Arch are the platform the binary is built for.
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	URL  string `json:"url"`
+	// SHA256 is the checksum of the binary.
+	SHA256 []byte `json:"sha256"`
+	// Signature is an ed25519 signature of the manifest of the version,
+	// platform and checksum.
+	Signature []byte `json:"signature"`
 }
 
 type LogSource struct {
@@ -229,6 +256,18 @@ func (c *Client) ConnectRPC23(ctx context.Context) (
 	return proto.NewDRPCAgentClient(conn), tailnetproto.NewDRPCTailnetClient(conn), nil
 }
 
+// ConnectRPC24 returns a dRPC client to the Agent API v2.4.  It adds support for agent-supervised
+// services.
+func (c *Client) ConnectRPC24(ctx context.Context) (
+	proto.DRPCAgentClient24, tailnetproto.DRPCTailnetClient24, error,
+) {
+	conn, err := c.connectRPCVersion(ctx, apiversion.New(2, 4))
+	if err != nil {
+		return nil, nil, err
+	}
+	return proto.NewDRPCAgentClient(conn), tailnetproto.NewDRPCTailnetClient(conn), nil
+}
+
 // ConnectRPC connects to the workspace agent API and tailnet API
 func (c *Client) ConnectRPC(ctx context.Context) (drpc.Conn, error) {
 	return c.connectRPCVersion(ctx, proto.CurrentVersion)
@@ -284,6 +323,9 @@ func (c *Client) connectRPCVersion(ctx context.Context, version *apiversion.APIV
 type PostAppHealthsRequest struct {
 	// Healths is a map of the workspace app name and the health of the app.
 	Healths map[uuid.UUID]wirtualsdk.WorkspaceAppHealth
+	// Reasons is a map of the workspace app ID and why its last health check
+	// failed. Apps without a failure are omitted.
+	Reasons map[uuid.UUID]string
 }
 
 // BatchUpdateAppHealthsClient is a partial interface of proto.DRPCAgentClient.
@@ -23,6 +23,14 @@ func ManifestFromProto(manifest *proto.Manifest) (Manifest, error) {
 	if err != nil {
 		return Manifest{}, xerrors.Errorf("error converting workspace agent scripts: %w", err)
 	}
+	services, err := AgentServicesFromProto(manifest.Services)
+	if err != nil {
+		return Manifest{}, xerrors.Errorf("error converting workspace agent services: %w", err)
+	}
+	dnsRecords, err := DNSRecordsFromProto(manifest.DnsRecords)
+	if err != nil {
+		return Manifest{}, xerrors.Errorf("error converting workspace agent DNS records: %w", err)
+	}
 	agentID, err := uuid.FromBytes(manifest.AgentId)
 	if err != nil {
 		return Manifest{}, xerrors.Errorf("error converting workspace agent ID: %w", err)
@@ -39,6 +47,7 @@ func ManifestFromProto(manifest *proto.Manifest) (Manifest, error) {
 		WorkspaceName:            manifest.WorkspaceName,
 		Apps:                     apps,
 		Scripts:                  scripts,
+		Services:                 services,
 		DERPMap:                  tailnet.DERPMapFromProto(manifest.DerpMap),
 		DERPForceWebSockets:      manifest.DerpForceWebsockets,
 		GitAuthConfigs:           int(manifest.GitAuthConfigs),
@@ -48,6 +57,8 @@ func ManifestFromProto(manifest *proto.Manifest) (Manifest, error) {
 		MOTDFile:                 manifest.MotdPath,
 		DisableDirectConnections: manifest.DisableDirectConnections,
 		Metadata:                 MetadataDescriptionsFromProto(manifest.Metadata),
+		AgentUpdate:              AgentUpdateFromProto(manifest.AgentUpdate),
+		DNSRecords:               dnsRecords,
 	}, nil
 }
 
@@ -56,6 +67,10 @@ func ProtoFromManifest(manifest Manifest) (*proto.Manifest, error) {
 	if err != nil {
 		return nil, xerrors.Errorf("convert workspace apps: %w", err)
 	}
+	services, err := ProtoFromServices(manifest.Services)
+	if err != nil {
+		return nil, xerrors.Errorf("convert workspace agent services: %w", err)
+	}
 	return &proto.Manifest{
 		AgentId:                  manifest.AgentID[:],
 		AgentName:                manifest.AgentName,
@@ -71,11 +86,67 @@ func ProtoFromManifest(manifest Manifest) (*proto.Manifest, error) {
 		DerpForceWebsockets:      manifest.DERPForceWebSockets,
 		DerpMap:                  tailnet.DERPMapToProto(manifest.DERPMap),
 		Scripts:                  ProtoFromScripts(manifest.Scripts),
+		Services:                 services,
 		Apps:                     apps,
 		Metadata:                 ProtoFromMetadataDescriptions(manifest.Metadata),
+		AgentUpdate:              ProtoFromAgentUpdate(manifest.AgentUpdate),
+		DnsRecords:               ProtoFromDNSRecords(manifest.DNSRecords),
 	}, nil
 }
 
+func AgentUpdateFromProto(update *proto.AgentUpdate) *AgentUpdate {
+	if update == nil {
+		return nil
+	}
+	return &AgentUpdate{
+		Version:   update.Version,
+		OS:        update.Os,
+		Arch:      update.Arch,
+		URL:       update.Url,
+		SHA256:    update.Sha256,
+		Signature: update.Signature,
+	}
+}
+
+func ProtoFromAgentUpdate(update *AgentUpdate) *proto.AgentUpdate {
+	if update == nil {
+		return nil
+	}
+	return &proto.AgentUpdate{
+		Version:   update.Version,
+		Os:        update.OS,
+		Arch:      update.Arch,
+		Url:       update.URL,
+		Sha256:    update.SHA256,
+		Signature: update.Signature,
+	}
+}
+
+func DNSRecordsFromProto(protoRecords []*proto.WorkspaceAgentDNSRecord) ([]DNSRecord, error) {
+	ret := make([]DNSRecord, len(protoRecords))
+	for i, record := range protoRecords {
+		if record.Port <= 0 || record.Port > 65535 {
+			return nil, xerrors.Errorf("invalid port %d of DNS record %q", record.Port, record.Name)
+		}
+		ret[i] = DNSRecord{
+			Name: record.Name,
+			Port: uint16(record.Port),
+		}
+	}
+	return ret, nil
+}
+
+func ProtoFromDNSRecords(records []DNSRecord) []*proto.WorkspaceAgentDNSRecord {
+	ret := make([]*proto.WorkspaceAgentDNSRecord, len(records))
+	for i, record := range records {
+		ret[i] = &proto.WorkspaceAgentDNSRecord{
+			Name: record.Name,
+			Port: int32(record.Port),
+		}
+	}
+	return ret
+}
+
 func MetadataDescriptionsFromProto(descriptions []*proto.WorkspaceAgentMetadata_Description) []wirtualsdk.WorkspaceAgentMetadataDescription {
 	ret := make([]wirtualsdk.WorkspaceAgentMetadataDescription, len(descriptions))
 	for i, description := range descriptions {
@@ -168,6 +239,24 @@ func AgentScriptFromProto(protoScript *proto.WorkspaceAgentScript) (wirtualsdk.W
 		return wirtualsdk.WorkspaceAgentScript{}, xerrors.Errorf("parse log source id: %w", err)
 	}
 
+	var dependsOn []uuid.UUID
+	for i, protoDependency := range protoScript.DependsOn {
+		dependency, err := uuid.FromBytes(protoDependency)
+		if err != nil {
+			return wirtualsdk.WorkspaceAgentScript{}, xerrors.Errorf("parse depends on %v: %w", i, err)
+		}
+		dependsOn = append(dependsOn, dependency)
+	}
+
+	var ready *wirtualsdk.WorkspaceAgentScriptReadiness
+	if protoScript.Ready != nil {
+		ready = &wirtualsdk.WorkspaceAgentScriptReadiness{
+			Port:    protoScript.Ready.Port,
+			File:    protoScript.Ready.File,
+			Timeout: protoScript.Ready.Timeout.AsDuration(),
+		}
+	}
+
 	return wirtualsdk.WorkspaceAgentScript{
 		ID:               id,
 		LogSourceID:      logSourceID,
@@ -179,10 +268,26 @@ func AgentScriptFromProto(protoScript *proto.WorkspaceAgentScript) (wirtualsdk.W
 		StartBlocksLogin: protoScript.StartBlocksLogin,
 		Timeout:          protoScript.Timeout.AsDuration(),
 		DisplayName:      protoScript.DisplayName,
+		DependsOn:        dependsOn,
+		Ready:            ready,
 	}, nil
 }
 
 func ProtoFromScript(s wirtualsdk.WorkspaceAgentScript) *proto.WorkspaceAgentScript {
+	var dependsOn [][]byte
+	for _, dependency := range s.DependsOn {
+		dependsOn = append(dependsOn, dependency[:])
+	}
+
+	var ready *proto.WorkspaceAgentScript_Readiness
+	if s.Ready != nil {
+		ready = &proto.WorkspaceAgentScript_Readiness{
+			Port:    s.Ready.Port,
+			File:    s.Ready.File,
+			Timeout: durationpb.New(s.Ready.Timeout),
+		}
+	}
+
 	return &proto.WorkspaceAgentScript{
 		Id:               s.ID[:],
 		LogSourceId:      s.LogSourceID[:],
@@ -194,7 +299,99 @@ func ProtoFromScript(s wirtualsdk.WorkspaceAgentScript) *proto.WorkspaceAgentScr
 		StartBlocksLogin: s.StartBlocksLogin,
 		Timeout:          durationpb.New(s.Timeout),
 		DisplayName:      s.DisplayName,
+		DependsOn:        dependsOn,
+		Ready:            ready,
+	}
+}
+
+func AgentServicesFromProto(protoServices []*proto.WorkspaceAgentService) ([]wirtualsdk.WorkspaceAgentService, error) {
+	ret := make([]wirtualsdk.WorkspaceAgentService, len(protoServices))
+	for i, protoService := range protoServices {
+		service, err := AgentServiceFromProto(protoService)
+		if err != nil {
+			return nil, xerrors.Errorf("parse service %v (%q): %w", i, protoService.Name, err)
+		}
+		ret[i] = service
 	}
+	return ret, nil
+}
+
+func ProtoFromServices(services []wirtualsdk.WorkspaceAgentService) ([]*proto.WorkspaceAgentService, error) {
+	ret := make([]*proto.WorkspaceAgentService, len(services))
+	var err error
+	for i, s := range services {
+		ret[i], err = ProtoFromService(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func AgentServiceFromProto(protoService *proto.WorkspaceAgentService) (wirtualsdk.WorkspaceAgentService, error) {
+	id, err := uuid.FromBytes(protoService.Id)
+	if err != nil {
+		return wirtualsdk.WorkspaceAgentService{}, xerrors.Errorf("parse id: %w", err)
+	}
+
+	var restartPolicy wirtualsdk.WorkspaceAgentServiceRestartPolicy
+	switch protoService.RestartPolicy {
+	case proto.WorkspaceAgentService_ALWAYS:
+		restartPolicy = wirtualsdk.WorkspaceAgentServiceRestartAlways
+	case proto.WorkspaceAgentService_ON_FAILURE:
+		restartPolicy = wirtualsdk.WorkspaceAgentServiceRestartOnFailure
+	case proto.WorkspaceAgentService_NEVER:
+		restartPolicy = wirtualsdk.WorkspaceAgentServiceRestartNever
+	default:
+		return wirtualsdk.WorkspaceAgentService{}, xerrors.Errorf("unknown restart policy: %v", protoService.RestartPolicy)
+	}
+
+	return wirtualsdk.WorkspaceAgentService{
+		ID:                id,
+		Name:              protoService.Name,
+		DisplayName:       protoService.DisplayName,
+		Command:           protoService.Command,
+		Directory:         protoService.Directory,
+		Env:               protoService.Env,
+		RestartPolicy:     restartPolicy,
+		RestartBackoff:    protoService.RestartBackoff.AsDuration(),
+		MaxRestartBackoff: protoService.MaxRestartBackoff.AsDuration(),
+		Healthcheck: wirtualsdk.Healthcheck{
+			URL:       protoService.Healthcheck.GetUrl(),
+			Interval:  int32(protoService.Healthcheck.GetInterval().AsDuration().Seconds()),
+			Threshold: protoService.Healthcheck.GetThreshold(),
+		},
+	}, nil
+}
+
+func ProtoFromService(s wirtualsdk.WorkspaceAgentService) (*proto.WorkspaceAgentService, error) {
+	var restartPolicy proto.WorkspaceAgentService_RestartPolicy
+	switch s.RestartPolicy {
+	case wirtualsdk.WorkspaceAgentServiceRestartAlways:
+		restartPolicy = proto.WorkspaceAgentService_ALWAYS
+	case wirtualsdk.WorkspaceAgentServiceRestartOnFailure:
+		restartPolicy = proto.WorkspaceAgentService_ON_FAILURE
+	case wirtualsdk.WorkspaceAgentServiceRestartNever:
+		restartPolicy = proto.WorkspaceAgentService_NEVER
+	default:
+		return nil, xerrors.Errorf("unknown restart policy %q", s.RestartPolicy)
+	}
+	return &proto.WorkspaceAgentService{
+		Id:                s.ID[:],
+		Name:              s.Name,
+		DisplayName:       s.DisplayName,
+		Command:           s.Command,
+		Directory:         s.Directory,
+		Env:               s.Env,
+		RestartPolicy:     restartPolicy,
+		RestartBackoff:    durationpb.New(s.RestartBackoff),
+		MaxRestartBackoff: durationpb.New(s.MaxRestartBackoff),
+		Healthcheck: &proto.WorkspaceApp_Healthcheck{
+			Url:       s.Healthcheck.URL,
+			Interval:  durationpb.New(time.Duration(s.Healthcheck.Interval) * time.Second),
+			Threshold: s.Healthcheck.Threshold,
+		},
+	}, nil
 }
 
 func AppsFromProto(protoApps []*proto.WorkspaceApp) ([]wirtualsdk.WorkspaceApp, error) {
@@ -249,9 +446,14 @@ func AppFromProto(protoApp *proto.WorkspaceApp) (wirtualsdk.WorkspaceApp, error)
 		SubdomainName: protoApp.SubdomainName,
 		SharingLevel:  sharingLevel,
 		Healthcheck: wirtualsdk.Healthcheck{
-			URL:       protoApp.Healthcheck.Url,
-			Interval:  int32(protoApp.Healthcheck.Interval.AsDuration().Seconds()),
-			Threshold: protoApp.Healthcheck.Threshold,
+			URL:              protoApp.Healthcheck.Url,
+			Interval:         int32(protoApp.Healthcheck.Interval.AsDuration().Seconds()),
+			Threshold:        protoApp.Healthcheck.Threshold,
+			TCP:              protoApp.Healthcheck.Tcp,
+			Command:          protoApp.Healthcheck.Command,
+			ExpectedStatuses: protoApp.Healthcheck.ExpectedStatuses,
+			BodyRegex:        protoApp.Healthcheck.BodyRegex,
+			Headers:          protoApp.Healthcheck.Headers,
 		},
 		Health: health,
 		Hidden: protoApp.Hidden,
@@ -279,9 +481,14 @@ func ProtoFromApp(a wirtualsdk.WorkspaceApp) (*proto.WorkspaceApp, error) {
 		SubdomainName: a.SubdomainName,
 		SharingLevel:  proto.WorkspaceApp_SharingLevel(sharingLevel),
 		Healthcheck: &proto.WorkspaceApp_Healthcheck{
-			Url:       a.Healthcheck.URL,
-			Interval:  durationpb.New(time.Duration(a.Healthcheck.Interval) * time.Second),
-			Threshold: a.Healthcheck.Threshold,
+			Url:              a.Healthcheck.URL,
+			Interval:         durationpb.New(time.Duration(a.Healthcheck.Interval) * time.Second),
+			Threshold:        a.Healthcheck.Threshold,
+			Tcp:              a.Healthcheck.TCP,
+			Command:          a.Healthcheck.Command,
+			ExpectedStatuses: a.Healthcheck.ExpectedStatuses,
+			BodyRegex:        a.Healthcheck.BodyRegex,
+			Headers:          a.Healthcheck.Headers,
 		},
 		Health: proto.WorkspaceApp_Health(health),
 		Hidden: a.Hidden,
@@ -347,6 +554,7 @@ func ProtoFromAppHealthsRequest(req PostAppHealthsRequest) (*proto.BatchUpdateAp
 		pReq.Updates = append(pReq.Updates, &proto.BatchUpdateAppHealthRequest_HealthUpdate{
 			Id:     idCopy[:],
 			Health: proto.AppHealth(hp),
+			Reason: req.Reasons[id],
 		})
 	}
 	return pReq, nil
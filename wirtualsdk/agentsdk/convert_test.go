@@ -39,9 +39,12 @@ func TestManifest(t *testing.T) {
 				SubdomainName: "app1.example.com",
 				SharingLevel:  wirtualsdk.WorkspaceAppSharingLevelAuthenticated,
 				Healthcheck: wirtualsdk.Healthcheck{
-					URL:       "http://localhost:3030/healthz",
-					Interval:  55555666,
-					Threshold: 55555666,
+					URL:              "http://localhost:3030/healthz",
+					Interval:         55555666,
+					Threshold:        55555666,
+					ExpectedStatuses: []int32{200, 204},
+					BodyRegex:        "ok",
+					Headers:          map[string]string{"Authorization": "Bearer token"},
 				},
 				Health: wirtualsdk.WorkspaceAppHealthHealthy,
 				Hidden: false,
@@ -58,7 +61,7 @@ func TestManifest(t *testing.T) {
 				SubdomainName: "app2.example.com",
 				SharingLevel:  wirtualsdk.WorkspaceAppSharingLevelPublic,
 				Healthcheck: wirtualsdk.Healthcheck{
-					URL:       "http://localhost:3032/healthz",
+					TCP:       "localhost:3032",
 					Interval:  22555666,
 					Threshold: 22555666,
 				},
@@ -128,8 +131,43 @@ func TestManifest(t *testing.T) {
 				StartBlocksLogin: true,
 				Timeout:          time.Second * 4,
 				DisplayName:      "bar",
+				DependsOn:        []uuid.UUID{uuid.New()},
+				Ready: &wirtualsdk.WorkspaceAgentScriptReadiness{
+					Port:    5432,
+					File:    "/tmp/ready",
+					Timeout: time.Minute,
+				},
+			},
+		},
+		Services: []wirtualsdk.WorkspaceAgentService{
+			{
+				ID:                uuid.New(),
+				Name:              "web",
+				DisplayName:       "Web",
+				Command:           "npm run dev",
+				Directory:         "/home/coder/app",
+				Env:               map[string]string{"PORT": "3000"},
+				RestartPolicy:     wirtualsdk.WorkspaceAgentServiceRestartOnFailure,
+				RestartBackoff:    time.Second,
+				MaxRestartBackoff: time.Minute,
+				Healthcheck: wirtualsdk.Healthcheck{
+					URL:       "http://localhost:3000",
+					Interval:  5,
+					Threshold: 3,
+				},
 			},
 		},
+		AgentUpdate: &agentsdk.AgentUpdate{
+			Version:   "v2.17.0",
+			OS:        "linux",
+			Arch:      "amd64",
+			URL:       "https://coder.example.com/bin/coder-linux-amd64",
+			SHA256:    []byte{1, 2, 3},
+			Signature: []byte{4, 5, 6},
+		},
+		DNSRecords: []agentsdk.DNSRecord{
+			{Name: "api", Port: 8080},
+		},
 	}
 	p, err := agentsdk.ProtoFromManifest(manifest)
 	require.NoError(t, err)
@@ -152,6 +190,9 @@ func TestManifest(t *testing.T) {
 	require.Equal(t, manifest.DisableDirectConnections, back.DisableDirectConnections)
 	require.Equal(t, manifest.Metadata, back.Metadata)
 	require.Equal(t, manifest.Scripts, back.Scripts)
+	require.Equal(t, manifest.Services, back.Services)
+	require.Equal(t, manifest.AgentUpdate, back.AgentUpdate)
+	require.Equal(t, manifest.DNSRecords, back.DNSRecords)
 }
 
 func TestSubsystems(t *testing.T) {
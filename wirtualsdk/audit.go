@@ -95,6 +95,7 @@ const (
 	AuditActionLogout               AuditAction = "logout"
 	AuditActionRegister             AuditAction = "register"
 	AuditActionRequestPasswordReset AuditAction = "request_password_reset"
+	AuditActionExec                 AuditAction = "exec"
 )
 
 func (a AuditAction) Friendly() string {
@@ -117,6 +118,8 @@ func (a AuditAction) Friendly() string {
 		return "registered"
 	case AuditActionRequestPasswordReset:
 		return "password reset requested"
+	case AuditActionExec:
+		return "ran a command in"
 	default:
 		return "unknown"
 	}
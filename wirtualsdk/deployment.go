@@ -364,6 +364,7 @@ type DeploymentValues struct {
 	MetricsCacheRefreshInterval     serpent.Duration                     `json:"metrics_cache_refresh_interval,omitempty" typescript:",notnull"`
 	AgentStatRefreshInterval        serpent.Duration                     `json:"agent_stat_refresh_interval,omitempty" typescript:",notnull"`
 	AgentFallbackTroubleshootingURL serpent.URL                          `json:"agent_fallback_troubleshooting_url,omitempty" typescript:",notnull"`
+	AgentAutoUpdate                 serpent.Bool                         `json:"agent_auto_update,omitempty" typescript:",notnull"`
 	BrowserOnly                     serpent.Bool                         `json:"browser_only,omitempty" typescript:",notnull"`
 	SCIMAPIKey                      serpent.String                       `json:"scim_api_key,omitempty" typescript:",notnull"`
 	ExternalTokenEncryptionKeys     serpent.StringArray                  `json:"external_token_encryption_keys,omitempty" typescript:",notnull"`
@@ -611,6 +612,12 @@ type ProvisionerConfig struct {
 	DaemonPollJitter    serpent.Duration    `json:"daemon_poll_jitter" typescript:",notnull"`
 	ForceCancelInterval serpent.Duration    `json:"force_cancel_interval" typescript:",notnull"`
 	DaemonPSK           serpent.String      `json:"daemon_psk" typescript:",notnull"`
+	StateRetention      serpent.Duration    `json:"state_retention" typescript:",notnull"`
+	DriftCheckInterval  serpent.Duration    `json:"drift_check_interval" typescript:",notnull"`
+	JobRetries          serpent.Int64       `json:"job_retries" typescript:",notnull"`
+	JobRetryBackoff     serpent.Duration    `json:"job_retry_backoff" typescript:",notnull"`
+	RetryableErrors     serpent.StringArray `json:"retryable_errors" typescript:",notnull"`
+	TerraformEngine     string              `json:"terraform_engine" typescript:",notnull"`
 }
 
 type RateLimitConfig struct {
@@ -2003,7 +2010,7 @@ when required by your organization's security policy.`,
 			Name: "Provisioner Daemon Types",
 			Description: fmt.Sprintf("The supported job types for the built-in provisioners. By default, this is only the terraform type. Supported types: %s.",
 				strings.Join([]string{
-					string(ProvisionerTypeTerraform), string(ProvisionerTypeEcho),
+					string(ProvisionerTypeTerraform), string(ProvisionerTypeEcho), string(ProvisionerTypeExec),
 				}, ",")),
 			Flag:    "provisioner-types",
 			Env:     "WIRTUAL_PROVISIONER_TYPES",
@@ -2067,6 +2074,68 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupProvisioning,
 			Annotations: serpent.Annotations{}.Mark(annotationSecretKey, "true"),
 		},
+		{
+			Name:        "State Retention",
+			Description: "How long the provisioner state of a workspace build is kept after a newer build replaces it. The state of the latest build of each workspace is always kept. Set to 0 to keep the state of every build.",
+			Flag:        "provisioner-state-retention",
+			Env:         "WIRTUAL_PROVISIONER_STATE_RETENTION",
+			Default:     "0s",
+			Value:       &c.Provisioner.StateRetention,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "stateRetention",
+			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true"),
+		},
+		{
+			Name:        "Drift Check Interval",
+			Description: "How often the resources of running workspaces are compared with the real infrastructure to detect drift. Each check runs a refresh-only plan on a provisioner. Set to 0 to disable drift detection.",
+			Flag:        "provisioner-drift-check-interval",
+			Env:         "WIRTUAL_PROVISIONER_DRIFT_CHECK_INTERVAL",
+			Default:     "0s",
+			Value:       &c.Provisioner.DriftCheckInterval,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "driftCheckInterval",
+			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true"),
+		},
+		{
+			Name:        "Job Retries",
+			Description: "How many times a workspace build that failed with a retryable error, such as a cloud provider rate limit, is retried. Set to 0 to disable retries.",
+			Flag:        "provisioner-job-retries",
+			Env:         "WIRTUAL_PROVISIONER_JOB_RETRIES",
+			Default:     "2",
+			Value:       &c.Provisioner.JobRetries,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "jobRetries",
+		},
+		{
+			Name:        "Job Retry Backoff",
+			Description: "Time to wait before the first retry of a failed workspace build. The wait doubles with each further retry.",
+			Flag:        "provisioner-job-retry-backoff",
+			Env:         "WIRTUAL_PROVISIONER_JOB_RETRY_BACKOFF",
+			Default:     (30 * time.Second).String(),
+			Value:       &c.Provisioner.JobRetryBackoff,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "jobRetryBackoff",
+			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true"),
+		},
+		{
+			Name:        "Retryable Errors",
+			Description: "Regular expressions matched against the errors of failed workspace builds. A build whose error matches one of them is retried. Defaults to common rate limit, throttling and network errors.",
+			Flag:        "provisioner-retryable-errors",
+			Env:         "WIRTUAL_PROVISIONER_RETRYABLE_ERRORS",
+			Value:       &c.Provisioner.RetryableErrors,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "retryableErrors",
+		},
+		{
+			Name:        "Terraform Engine",
+			Description: "The infrastructure as code engine that the built-in provisioners run Terraform templates with.",
+			Flag:        "provisioner-terraform-engine",
+			Env:         "WIRTUAL_PROVISIONER_TERRAFORM_ENGINE",
+			Default:     "terraform",
+			Value:       serpent.EnumOf(&c.Provisioner.TerraformEngine, "terraform", "opentofu"),
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "terraformEngine",
+		},
 		// RateLimit settings
 		{
 			Name:        "Disable All Rate Limits",
@@ -2380,6 +2449,14 @@ when required by your organization's security policy.`,
 			Value:       &c.AgentFallbackTroubleshootingURL,
 			YAML:        "agentFallbackTroubleshootingURL",
 		},
+		{
+			Name:        "Agent Auto Update",
+			Description: "Offer workspace agents that are older than the server an update to the server's version, which they install without a workspace rebuild. Agents only install updates whose signature they can verify, see the agent --update-public-key flag.",
+			Flag:        "agent-auto-update",
+			Env:         "WIRTUAL_AGENT_AUTO_UPDATE",
+			Value:       &c.AgentAutoUpdate,
+			YAML:        "agentAutoUpdate",
+		},
 		{
 			Name:        "Browser Only",
 			Description: "Whether Coder only allows connections to workspaces via the browser.",
@@ -90,11 +90,27 @@ const (
 	ProvisionerJobUnknown   ProvisionerJobStatus = "unknown"
 )
 
+// ProvisionerJobPriority decides the order in which provisioner daemons
+// acquire pending jobs. Jobs with a higher priority are acquired first.
+type ProvisionerJobPriority string
+
+const (
+	// ProvisionerJobPriorityInteractive is used for jobs a user waits on.
+	ProvisionerJobPriorityInteractive ProvisionerJobPriority = "interactive"
+	// ProvisionerJobPriorityAutostart is used for builds started by the
+	// lifecycle executor.
+	ProvisionerJobPriorityAutostart ProvisionerJobPriority = "autostart"
+	// ProvisionerJobPriorityBulk is used for bulk and administrative jobs
+	// that nobody waits on.
+	ProvisionerJobPriorityBulk ProvisionerJobPriority = "bulk"
+)
+
 // JobErrorCode defines the error code returned by job runner.
 type JobErrorCode string
 
 const (
 	RequiredTemplateVariables JobErrorCode = "REQUIRED_TEMPLATE_VARIABLES"
+	JobTimeout                JobErrorCode = "JOB_TIMEOUT"
 )
 
 // JobIsMissingParameterErrorCode returns whether the error is a missing parameter error.
@@ -111,13 +127,20 @@ type ProvisionerJob struct {
 	CompletedAt   *time.Time           `json:"completed_at,omitempty" format:"date-time"`
 	CanceledAt    *time.Time           `json:"canceled_at,omitempty" format:"date-time"`
 	Error         string               `json:"error,omitempty"`
-	ErrorCode     JobErrorCode         `json:"error_code,omitempty" enums:"REQUIRED_TEMPLATE_VARIABLES"`
+	ErrorCode     JobErrorCode         `json:"error_code,omitempty" enums:"REQUIRED_TEMPLATE_VARIABLES,JOB_TIMEOUT"`
 	Status        ProvisionerJobStatus `json:"status" enums:"pending,running,succeeded,canceling,canceled,failed"`
 	WorkerID      *uuid.UUID           `json:"worker_id,omitempty" format:"uuid"`
 	FileID        uuid.UUID            `json:"file_id" format:"uuid"`
 	Tags          map[string]string    `json:"tags"`
 	QueuePosition int                  `json:"queue_position"`
 	QueueSize     int                  `json:"queue_size"`
+	// QueueEstimatedWaitMillis is a rough estimate of how long a pending
+	// job waits before a provisioner daemon acquires it. It's zero when
+	// there are no recent jobs to estimate from.
+	QueueEstimatedWaitMillis int64                  `json:"queue_estimated_wait_ms"`
+	Priority                 ProvisionerJobPriority `json:"priority" enums:"interactive,autostart,bulk"`
+	Engine                   string                 `json:"engine,omitempty"`
+	EngineVersion            string                 `json:"engine_version,omitempty"`
 }
 
 // ProvisionerJobLog represents the provisioner log entry annotated with source and level.
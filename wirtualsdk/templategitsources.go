@@ -0,0 +1,144 @@
+package wirtualsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TemplateGitSyncStatus string
+
+const (
+	// TemplateGitSyncStatusPending means that the repository hasn't been
+	// synced yet.
+	TemplateGitSyncStatusPending TemplateGitSyncStatus = "pending"
+	// TemplateGitSyncStatusImporting means that a template version is being
+	// imported from the latest commit.
+	TemplateGitSyncStatusImporting TemplateGitSyncStatus = "importing"
+	// TemplateGitSyncStatusDryRun means that the imported template version is
+	// being dry run before it's promoted.
+	TemplateGitSyncStatusDryRun TemplateGitSyncStatus = "dry_run"
+	// TemplateGitSyncStatusSucceeded means that the latest commit was imported
+	// and, if enabled, promoted.
+	TemplateGitSyncStatusSucceeded TemplateGitSyncStatus = "succeeded"
+	// TemplateGitSyncStatusFailed means that the latest sync failed. The error
+	// is reported on the source.
+	TemplateGitSyncStatusFailed TemplateGitSyncStatus = "failed"
+)
+
+// TemplateGitSource is a git repository that new versions of a template are
+// created from. The repository is polled for new commits, and can be synced
+// immediately by sending a push webhook to WebhookURL.
+type TemplateGitSource struct {
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	RepoURL    string    `json:"repo_url"`
+	// Path is the directory of the template within the repository.
+	Path string `json:"path"`
+	// Ref is the branch or tag to sync. Empty means the default branch.
+	Ref                    string `json:"ref"`
+	ExternalAuthProviderID string `json:"external_auth_provider_id,omitempty"`
+	// DeployKey is the public SSH key to add as a read-only deploy key to the
+	// repository. It's empty when the repository is fetched with external
+	// auth.
+	DeployKey string `json:"deploy_key,omitempty"`
+	// KnownHosts are the SSH host keys that the repository host is verified
+	// with.
+	KnownHosts  string `json:"known_hosts,omitempty"`
+	AutoPromote bool   `json:"auto_promote"`
+	WebhookURL  string `json:"webhook_url"`
+	// WebhookSecret verifies the signature of GitHub webhooks and the token of
+	// GitLab webhooks.
+	WebhookSecret string `json:"webhook_secret"`
+	// UserID is the user that synced template versions are created by.
+	UserID    uuid.UUID `json:"user_id" format:"uuid"`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+
+	Status            TemplateGitSyncStatus `json:"status" enums:"pending,importing,dry_run,succeeded,failed"`
+	Error             string                `json:"error,omitempty"`
+	LastCheckedAt     *time.Time            `json:"last_checked_at,omitempty" format:"date-time"`
+	LastSyncedAt      *time.Time            `json:"last_synced_at,omitempty" format:"date-time"`
+	LastCommitSHA     string                `json:"last_commit_sha,omitempty"`
+	TemplateVersionID *uuid.UUID            `json:"template_version_id,omitempty" format:"uuid"`
+}
+
+type UpdateTemplateGitSourceRequest struct {
+	RepoURL string `json:"repo_url" validate:"required"`
+	Path    string `json:"path,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	// ExternalAuthProviderID fetches the repository with the external auth
+	// link of the user. It can't be used with UseDeployKey.
+	ExternalAuthProviderID string `json:"external_auth_provider_id,omitempty"`
+	// UseDeployKey fetches the repository over SSH with a generated key. The
+	// key is kept when the source is updated.
+	UseDeployKey bool `json:"use_deploy_key,omitempty"`
+	// KnownHosts are the SSH host keys of the repository host, in the
+	// known_hosts format like the output of ssh-keyscan. They are required for
+	// SSH URLs.
+	KnownHosts string `json:"known_hosts,omitempty"`
+	// AutoPromote promotes new template versions to active after a successful
+	// dry run.
+	AutoPromote bool `json:"auto_promote,omitempty"`
+}
+
+// TemplateGitSource returns the git repository that the template is synced
+// from.
+func (c *Client) TemplateGitSource(ctx context.Context, templateID uuid.UUID) (TemplateGitSource, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/git-source", templateID), nil)
+	if err != nil {
+		return TemplateGitSource{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSource{}, ReadBodyAsError(res)
+	}
+	var source TemplateGitSource
+	return source, json.NewDecoder(res.Body).Decode(&source)
+}
+
+// UpdateTemplateGitSource binds the template to a git repository, or updates
+// the existing binding. The repository is synced shortly after.
+func (c *Client) UpdateTemplateGitSource(ctx context.Context, templateID uuid.UUID, req UpdateTemplateGitSourceRequest) (TemplateGitSource, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/git-source", templateID), req)
+	if err != nil {
+		return TemplateGitSource{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSource{}, ReadBodyAsError(res)
+	}
+	var source TemplateGitSource
+	return source, json.NewDecoder(res.Body).Decode(&source)
+}
+
+// DeleteTemplateGitSource stops syncing the template from git. Existing
+// template versions are kept.
+func (c *Client) DeleteTemplateGitSource(ctx context.Context, templateID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/templates/%s/git-source", templateID), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// SyncTemplateGitSource checks the repository for new commits without waiting
+// for the poll interval.
+func (c *Client) SyncTemplateGitSource(ctx context.Context, templateID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/git-source/sync", templateID), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
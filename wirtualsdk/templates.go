@@ -61,6 +61,9 @@ type Template struct {
 	// template version.
 	RequireActiveVersion bool                         `json:"require_active_version"`
 	MaxPortShareLevel    WorkspaceAgentPortShareLevel `json:"max_port_share_level"`
+	// JobTimeoutMillis is the maximum duration of a workspace build. Builds
+	// that run longer are canceled. 0 means builds never time out.
+	JobTimeoutMillis int64 `json:"job_timeout_ms"`
 }
 
 // WeekdaysToBitmap converts a list of weekdays to a bitmap in accordance with
@@ -250,6 +253,10 @@ type UpdateTemplateMeta struct {
 	// of the template.
 	DisableEveryoneGroupAccess bool                          `json:"disable_everyone_group_access"`
 	MaxPortShareLevel          *WorkspaceAgentPortShareLevel `json:"max_port_share_level"`
+	// JobTimeoutMillis allows optionally specifying the maximum duration of
+	// a workspace build. Set it to 0 to remove the timeout. If omitted, the
+	// timeout is left unchanged.
+	JobTimeoutMillis *int64 `json:"job_timeout_ms,omitempty"`
 }
 
 type TemplateExample struct {
@@ -30,6 +30,10 @@ type TemplateVersion struct {
 	Readme         string         `json:"readme"`
 	CreatedBy      MinimalUser    `json:"created_by"`
 	Archived       bool           `json:"archived"`
+	// GitCommitSHA and GitCommitAuthor are set when the version was synced
+	// from the git source of the template.
+	GitCommitSHA    string `json:"git_commit_sha,omitempty"`
+	GitCommitAuthor string `json:"git_commit_author,omitempty"`
 
 	Warnings            []TemplateVersionWarning `json:"warnings,omitempty" enums:"DEPRECATED_PARAMETERS"`
 	MatchedProvisioners *MatchedProvisioners     `json:"matched_provisioners,omitempty"`
@@ -91,6 +95,62 @@ type TemplateVersionVariable struct {
 	Sensitive    bool   `json:"sensitive"`
 }
 
+type TemplateVersionFileChange string
+
+const (
+	TemplateVersionFileChangeAdded    TemplateVersionFileChange = "added"
+	TemplateVersionFileChangeRemoved  TemplateVersionFileChange = "removed"
+	TemplateVersionFileChangeModified TemplateVersionFileChange = "modified"
+)
+
+// TemplateVersionDiff describes what changed between two template versions.
+type TemplateVersionDiff struct {
+	FromTemplateVersionID uuid.UUID                 `json:"from_template_version_id" format:"uuid"`
+	ToTemplateVersionID   uuid.UUID                 `json:"to_template_version_id" format:"uuid"`
+	Files                 []TemplateVersionFileDiff `json:"files"`
+	Parameters            TemplateVersionObjectDiff `json:"parameters"`
+	Variables             TemplateVersionObjectDiff `json:"variables"`
+	WorkspaceTags         TemplateVersionObjectDiff `json:"workspace_tags"`
+	// Resources are the resources that were planned when the versions were
+	// imported, keyed by "type.name".
+	Resources TemplateVersionObjectDiff `json:"resources"`
+}
+
+// TemplateVersionFileDiff is a file of the template source that differs
+// between two template versions.
+type TemplateVersionFileDiff struct {
+	Path   string                    `json:"path"`
+	Change TemplateVersionFileChange `json:"change" enums:"added,removed,modified"`
+	// Binary files have no diff.
+	Binary bool `json:"binary"`
+	// Diff is a unified diff of the file.
+	Diff string `json:"diff"`
+}
+
+// TemplateVersionObjectDiff lists the names of the objects of a kind, e.g.
+// parameters, that differ between two template versions.
+type TemplateVersionObjectDiff struct {
+	Added   []string                      `json:"added"`
+	Removed []string                      `json:"removed"`
+	Changed []TemplateVersionObjectChange `json:"changed"`
+}
+
+// TemplateVersionObjectChange is an object that exists in both template
+// versions, but whose fields differ.
+type TemplateVersionObjectChange struct {
+	Name   string                       `json:"name"`
+	Fields []TemplateVersionFieldChange `json:"fields"`
+}
+
+// TemplateVersionFieldChange is a field of an object that differs between two
+// template versions. The values are JSON encoded, and empty when the field
+// doesn't exist in a version.
+type TemplateVersionFieldChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
 type PatchTemplateVersionRequest struct {
 	Name    string  `json:"name" validate:"omitempty,template_version_name"`
 	Message *string `json:"message,omitempty" validate:"omitempty,lt=1048577"`
@@ -179,6 +239,20 @@ func (c *Client) TemplateVersionVariables(ctx context.Context, version uuid.UUID
 	return variables, json.NewDecoder(res.Body).Decode(&variables)
 }
 
+// TemplateVersionDiff returns what changed between two template versions.
+func (c *Client) TemplateVersionDiff(ctx context.Context, from, to uuid.UUID) (TemplateVersionDiff, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/diff/%s", from, to), nil)
+	if err != nil {
+		return TemplateVersionDiff{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionDiff{}, ReadBodyAsError(res)
+	}
+	var diff TemplateVersionDiff
+	return diff, json.NewDecoder(res.Body).Decode(&diff)
+}
+
 // TemplateVersionLogsAfter streams logs for a template version that occurred after a specific log ID.
 func (c *Client) TemplateVersionLogsAfter(ctx context.Context, version uuid.UUID, after int64) (<-chan ProvisionerJobLog, io.Closer, error) {
 	return c.provisionerJobLogsAfter(ctx, fmt.Sprintf("/api/v2/templateversions/%s/logs", version), after)
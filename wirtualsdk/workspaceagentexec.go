@@ -0,0 +1,149 @@
+package wirtualsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// WorkspaceAgentExecRequest runs a command in a workspace without SSH.
+type WorkspaceAgentExecRequest struct {
+	// Command is the program and its arguments. It's run by the shell of the
+	// workspace user, with the same environment as commands run over SSH.
+	Command []string `json:"command"`
+	// Env are environment variables set in addition to the environment of
+	// the agent.
+	Env map[string]string `json:"env,omitempty"`
+	// WorkingDirectory defaults to the directory of the agent. Relative
+	// paths are resolved against it.
+	WorkingDirectory string `json:"working_directory,omitempty"`
+	// TimeoutMillis kills the command when it runs for longer. Zero means the
+	// command runs until the request is canceled.
+	TimeoutMillis int64 `json:"timeout_ms,omitempty"`
+}
+
+type WorkspaceAgentExecEventType string
+
+const (
+	WorkspaceAgentExecEventStdout WorkspaceAgentExecEventType = "stdout"
+	WorkspaceAgentExecEventStderr WorkspaceAgentExecEventType = "stderr"
+	// WorkspaceAgentExecEventExit is the last event, sent once the command
+	// exited or couldn't be run.
+	WorkspaceAgentExecEventExit WorkspaceAgentExecEventType = "exit"
+)
+
+// WorkspaceAgentExecEvent is output of a command, or its result.
+type WorkspaceAgentExecEvent struct {
+	Type WorkspaceAgentExecEventType `json:"type" enums:"stdout,stderr,exit"`
+	// Output is set for stdout and stderr events.
+	Output []byte `json:"output,omitempty"`
+	// Result is set for exit events.
+	Result *WorkspaceAgentExecResult `json:"result,omitempty"`
+}
+
+type WorkspaceAgentExecResult struct {
+	// ExitCode is -1 when the command didn't exit by itself, like when it was
+	// killed after its timeout.
+	ExitCode int  `json:"exit_code"`
+	TimedOut bool `json:"timed_out"`
+	// Error is set when the command couldn't be run at all.
+	Error string `json:"error,omitempty"`
+}
+
+// WorkspaceAgentExec runs a command in the workspace of an agent and writes
+// its output to stdout and stderr as it's produced. It returns once the
+// command exited, a non-zero exit code isn't an error.
+//
+// The command is run through wirtuald, so no connection to the workspace is
+// established, and commands can be run in many workspaces concurrently with
+// the same client.
+func (c *Client) WorkspaceAgentExec(ctx context.Context, agentID uuid.UUID, req WorkspaceAgentExecRequest, stdout, stderr io.Writer) (WorkspaceAgentExecResult, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaceagents/%s/exec", agentID), req)
+	if err != nil {
+		return WorkspaceAgentExecResult{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceAgentExecResult{}, ReadBodyAsError(res)
+	}
+	return ReadWorkspaceAgentExecEvents(ctx, res.Body, func(event WorkspaceAgentExecEvent) error {
+		return WriteWorkspaceAgentExecOutput(event, stdout, stderr)
+	})
+}
+
+// WriteWorkspaceAgentExecOutput writes the output of an event to stdout or
+// stderr. Nil writers discard the output.
+func WriteWorkspaceAgentExecOutput(event WorkspaceAgentExecEvent, stdout, stderr io.Writer) error {
+	var w io.Writer
+	switch event.Type {
+	case WorkspaceAgentExecEventStdout:
+		w = stdout
+	case WorkspaceAgentExecEventStderr:
+		w = stderr
+	}
+	if w == nil {
+		return nil
+	}
+	_, err := w.Write(event.Output)
+	return err
+}
+
+// ReadWorkspaceAgentExecEvents reads the server-sent events of a command,
+// calling onEvent for each of them, until the command exited.
+func ReadWorkspaceAgentExecEvents(ctx context.Context, rc io.ReadCloser, onEvent func(WorkspaceAgentExecEvent) error) (WorkspaceAgentExecResult, error) {
+	nextEvent := ServerSentEventReader(ctx, rc)
+	for {
+		sse, err := nextEvent()
+		if err != nil {
+			if xerrors.Is(err, io.EOF) {
+				return WorkspaceAgentExecResult{}, xerrors.New("the connection closed before the command exited")
+			}
+			return WorkspaceAgentExecResult{}, err
+		}
+		// Ignore pings.
+		if sse.Type == ServerSentEventTypePing {
+			continue
+		}
+
+		b, ok := sse.Data.([]byte)
+		if !ok {
+			return WorkspaceAgentExecResult{}, xerrors.Errorf("unexpected data type: %T", sse.Data)
+		}
+
+		switch sse.Type {
+		case ServerSentEventTypeData:
+			var event WorkspaceAgentExecEvent
+			err = json.Unmarshal(b, &event)
+			if err != nil {
+				return WorkspaceAgentExecResult{}, xerrors.Errorf("unmarshal exec event: %w", err)
+			}
+			err = onEvent(event)
+			if err != nil {
+				return WorkspaceAgentExecResult{}, err
+			}
+			if event.Type == WorkspaceAgentExecEventExit {
+				if event.Result == nil {
+					return WorkspaceAgentExecResult{}, xerrors.New("exit event without a result")
+				}
+				return *event.Result, nil
+			}
+		case ServerSentEventTypeError:
+			var r Response
+			err = json.Unmarshal(b, &r)
+			if err != nil {
+				return WorkspaceAgentExecResult{}, xerrors.Errorf("unmarshal error: %w", err)
+			}
+			if r.Detail != "" {
+				return WorkspaceAgentExecResult{}, xerrors.Errorf("%s: %s", r.Message, r.Detail)
+			}
+			return WorkspaceAgentExecResult{}, xerrors.New(r.Message)
+		default:
+			return WorkspaceAgentExecResult{}, xerrors.Errorf("unexpected event type: %s", sse.Type)
+		}
+	}
+}
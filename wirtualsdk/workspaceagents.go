@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/cookiejar"
+	"slices"
 	"strings"
 	"time"
 
@@ -195,6 +196,140 @@ type WorkspaceAgentScript struct {
 	StartBlocksLogin bool          `json:"start_blocks_login"`
 	Timeout          time.Duration `json:"timeout"`
 	DisplayName      string        `json:"display_name"`
+	// DependsOn are the IDs of the scripts of the same agent that must
+	// succeed, or become ready, before this script is started.
+	DependsOn []uuid.UUID `json:"depends_on,omitempty" format:"uuid"`
+	// Ready is the condition that must be met before the dependents of this
+	// script are started. Without it, they start once the script exits
+	// successfully.
+	Ready *WorkspaceAgentScriptReadiness `json:"ready,omitempty"`
+}
+
+// WorkspaceAgentScriptReadiness is checked by the agent while a script runs
+// and after it exits. All conditions that are set must hold.
+type WorkspaceAgentScriptReadiness struct {
+	// Port is a local TCP port that must accept connections.
+	Port int32 `json:"port,omitempty"`
+	// File is a path that must exist.
+	File string `json:"file,omitempty"`
+	// Timeout is how long the agent waits for the condition. Zero uses the
+	// agent default.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// ValidateWorkspaceAgentScriptDependencies returns an error if a script
+// depends on a script that is not in the list, or if the dependencies form a
+// cycle.
+func ValidateWorkspaceAgentScriptDependencies(scripts []WorkspaceAgentScript) error {
+	byID := make(map[uuid.UUID]WorkspaceAgentScript, len(scripts))
+	for _, script := range scripts {
+		byID[script.ID] = script
+	}
+	name := func(id uuid.UUID) string {
+		if displayName := byID[id].DisplayName; displayName != "" {
+			return displayName
+		}
+		return id.String()
+	}
+	for _, script := range scripts {
+		for _, dependency := range script.DependsOn {
+			if _, ok := byID[dependency]; !ok {
+				return xerrors.Errorf("script %q depends on unknown script %s", name(script.ID), dependency)
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[uuid.UUID]int, len(scripts))
+	// path holds the scripts currently being visited, in order.
+	var path []uuid.UUID
+	var visit func(id uuid.UUID) error
+	visit = func(id uuid.UUID) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			names := []string{}
+			for i := slices.Index(path, id); i < len(path); i++ {
+				names = append(names, name(path[i]))
+			}
+			names = append(names, name(id))
+			return xerrors.Errorf("script dependency cycle: %s", strings.Join(names, " -> "))
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dependency := range byID[id].DependsOn {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, script := range scripts {
+		if err := visit(script.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type WorkspaceAgentServiceRestartPolicy string
+
+const (
+	// WorkspaceAgentServiceRestartAlways restarts the service whenever it
+	// exits.
+	WorkspaceAgentServiceRestartAlways WorkspaceAgentServiceRestartPolicy = "always"
+	// WorkspaceAgentServiceRestartOnFailure restarts the service only when it
+	// exits with a non-zero code or fails its health check.
+	WorkspaceAgentServiceRestartOnFailure WorkspaceAgentServiceRestartPolicy = "on-failure"
+	// WorkspaceAgentServiceRestartNever leaves the service stopped once it
+	// exits.
+	WorkspaceAgentServiceRestartNever WorkspaceAgentServiceRestartPolicy = "never"
+)
+
+type WorkspaceAgentServiceState string
+
+const (
+	// WorkspaceAgentServicePending means the agent has not reported on the
+	// service yet.
+	WorkspaceAgentServicePending   WorkspaceAgentServiceState = "pending"
+	WorkspaceAgentServiceStarting  WorkspaceAgentServiceState = "starting"
+	WorkspaceAgentServiceRunning   WorkspaceAgentServiceState = "running"
+	WorkspaceAgentServiceUnhealthy WorkspaceAgentServiceState = "unhealthy"
+	// WorkspaceAgentServiceBackoff means the service exited and the agent is
+	// waiting before restarting it.
+	WorkspaceAgentServiceBackoff WorkspaceAgentServiceState = "backoff"
+	// WorkspaceAgentServiceExited means the service exited and will not be
+	// restarted under its restart policy.
+	WorkspaceAgentServiceExited WorkspaceAgentServiceState = "exited"
+	// WorkspaceAgentServiceFailed means the service could not be started.
+	WorkspaceAgentServiceFailed WorkspaceAgentServiceState = "failed"
+)
+
+// WorkspaceAgentService is a long-running process supervised by the agent,
+// declared with a coder_service resource.
+type WorkspaceAgentService struct {
+	ID                uuid.UUID                          `json:"id" format:"uuid"`
+	Name              string                             `json:"name"`
+	DisplayName       string                             `json:"display_name"`
+	Command           string                             `json:"command"`
+	Directory         string                             `json:"directory"`
+	Env               map[string]string                  `json:"env"`
+	RestartPolicy     WorkspaceAgentServiceRestartPolicy `json:"restart_policy" enums:"always,on-failure,never"`
+	RestartBackoff    time.Duration                      `json:"restart_backoff"`
+	MaxRestartBackoff time.Duration                      `json:"max_restart_backoff"`
+	Healthcheck       Healthcheck                        `json:"healthcheck"`
+
+	State          WorkspaceAgentServiceState `json:"state" enums:"pending,starting,running,unhealthy,backoff,exited,failed"`
+	RestartCount   int32                      `json:"restart_count"`
+	ExitCode       int32                      `json:"exit_code"`
+	StatusMessage  string                     `json:"status_message,omitempty"`
+	StateChangedAt *time.Time                 `json:"state_changed_at,omitempty" format:"date-time"`
 }
 
 type WorkspaceAgentHealth struct {
@@ -374,6 +509,90 @@ type WorkspaceAgentListeningPort struct {
 	ProcessName string `json:"process_name"` // may be empty
 	Network     string `json:"network"`      // only "tcp" at the moment
 	Port        uint16 `json:"port"`
+	// PID, Cmdline and User describe the process listening on the port. They
+	// are empty when the agent can't inspect the process.
+	PID     int32  `json:"pid,omitempty"`
+	Cmdline string `json:"cmdline,omitempty"`
+	User    string `json:"user,omitempty"`
+	// Protocol is the protocol the port appears to serve. It's only probed
+	// for watches that ask for it, and is empty otherwise or when the port
+	// serves neither HTTP nor HTTPS.
+	Protocol WorkspaceAgentPortShareProtocol `json:"protocol,omitempty" enums:"http,https"`
+}
+
+type WorkspaceAgentListeningPortEventType string
+
+const (
+	WorkspaceAgentListeningPortOpened WorkspaceAgentListeningPortEventType = "opened"
+	WorkspaceAgentListeningPortClosed WorkspaceAgentListeningPortEventType = "closed"
+)
+
+// WorkspaceAgentListeningPortEvent reports a port that started or stopped
+// listening in the workspace.
+type WorkspaceAgentListeningPortEvent struct {
+	Type WorkspaceAgentListeningPortEventType `json:"type" enums:"opened,closed"`
+	Port WorkspaceAgentListeningPort          `json:"port"`
+}
+
+// WorkspaceAgentProcessesResponse is a snapshot of the processes running in a
+// workspace, busiest first.
+type WorkspaceAgentProcessesResponse struct {
+	CollectedAt time.Time               `json:"collected_at" format:"date-time"`
+	Processes   []WorkspaceAgentProcess `json:"processes"`
+}
+
+type WorkspaceAgentProcess struct {
+	PID     int32  `json:"pid"`
+	Name    string `json:"name"`
+	Cmdline string `json:"cmdline"`
+	User    string `json:"user"`
+	// CPUPercent is the percentage of one CPU core used by the process while
+	// the snapshot was taken. It can exceed 100 for multi-threaded processes.
+	CPUPercent float64 `json:"cpu_percent"`
+	// MemoryRSSBytes is the resident set size of the process.
+	MemoryRSSBytes uint64 `json:"memory_rss_bytes"`
+	// IOReadBytes and IOWriteBytes are the bytes read from and written to
+	// storage since the process started.
+	IOReadBytes  uint64 `json:"io_read_bytes"`
+	IOWriteBytes uint64 `json:"io_write_bytes"`
+	// Cgroup is the cgroup the process belongs to.
+	Cgroup string `json:"cgroup"`
+}
+
+type WorkspaceAgentDevcontainerState string
+
+const (
+	// WorkspaceAgentDevcontainerStopped means the dev container has no
+	// running container.
+	WorkspaceAgentDevcontainerStopped  WorkspaceAgentDevcontainerState = "stopped"
+	WorkspaceAgentDevcontainerStarting WorkspaceAgentDevcontainerState = "starting"
+	WorkspaceAgentDevcontainerRunning  WorkspaceAgentDevcontainerState = "running"
+	// WorkspaceAgentDevcontainerFailed means building or starting the
+	// container failed, see the error for why.
+	WorkspaceAgentDevcontainerFailed WorkspaceAgentDevcontainerState = "failed"
+)
+
+// WorkspaceAgentDevcontainersResponse lists the dev containers the agent
+// found in the workspace.
+type WorkspaceAgentDevcontainersResponse struct {
+	Devcontainers []WorkspaceAgentDevcontainer `json:"devcontainers"`
+}
+
+// WorkspaceAgentDevcontainer is a container defined by a devcontainer.json
+// file in the workspace, which the agent builds and starts.
+type WorkspaceAgentDevcontainer struct {
+	// Name identifies the dev container, for example to connect to it with
+	// `coder ssh workspace.name`.
+	Name string `json:"name"`
+	// WorkspaceFolder is the folder of the workspace that holds the config
+	// and is mounted in the container.
+	WorkspaceFolder string                          `json:"workspace_folder"`
+	ConfigPath      string                          `json:"config_path"`
+	State           WorkspaceAgentDevcontainerState `json:"state" enums:"stopped,starting,running,failed"`
+	ContainerID     string                          `json:"container_id,omitempty"`
+	Image           string                          `json:"image,omitempty"`
+	Error           string                          `json:"error,omitempty"`
+	StateChangedAt  *time.Time                      `json:"state_changed_at,omitempty" format:"date-time"`
 }
 
 // WorkspaceAgentListeningPorts returns a list of ports that are currently being
@@ -391,15 +610,84 @@ func (c *Client) WorkspaceAgentListeningPorts(ctx context.Context, agentID uuid.
 	return listeningPorts, json.NewDecoder(res.Body).Decode(&listeningPorts)
 }
 
+// WorkspaceAgentServices returns the services of an agent along with the
+// status the agent last reported for each.
+func (c *Client) WorkspaceAgentServices(ctx context.Context, agentID uuid.UUID) ([]WorkspaceAgentService, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/services", agentID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var services []WorkspaceAgentService
+	return services, json.NewDecoder(res.Body).Decode(&services)
+}
+
+// WorkspaceAgentDevcontainers returns the dev containers of an agent along
+// with the state the agent last reported for each.
+func (c *Client) WorkspaceAgentDevcontainers(ctx context.Context, agentID uuid.UUID) (WorkspaceAgentDevcontainersResponse, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/devcontainers", agentID), nil)
+	if err != nil {
+		return WorkspaceAgentDevcontainersResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceAgentDevcontainersResponse{}, ReadBodyAsError(res)
+	}
+	var devcontainers WorkspaceAgentDevcontainersResponse
+	return devcontainers, json.NewDecoder(res.Body).Decode(&devcontainers)
+}
+
 //nolint:revive // Follow is a control flag on the server as well.
 func (c *Client) WorkspaceAgentLogsAfter(ctx context.Context, agentID uuid.UUID, after int64, follow bool) (<-chan []WorkspaceAgentLog, io.Closer, error) {
+	return c.WorkspaceAgentLogs(ctx, agentID, WorkspaceAgentLogsRequest{
+		After:  after,
+		Follow: follow,
+	})
+}
+
+// WorkspaceAgentLogsRequest filters the logs returned by WorkspaceAgentLogs.
+type WorkspaceAgentLogsRequest struct {
+	// After only returns logs with a greater ID, to page through logs.
+	After int64 `json:"after,omitempty"`
+	// Follow streams new logs until the agent is no longer part of the
+	// latest build of its workspace.
+	Follow bool `json:"follow,omitempty"`
+	// SourceIDs only returns logs of these log sources.
+	SourceIDs []uuid.UUID `json:"source,omitempty" format:"uuid"`
+	// Level is the minimum level of the logs returned.
+	Level LogLevel `json:"level,omitempty"`
+	// Since only returns logs created after this time.
+	Since time.Time `json:"since,omitempty" format:"date-time"`
+	// Limit is the maximum number of logs returned. It's ignored when
+	// following.
+	Limit int `json:"limit,omitempty"`
+}
+
+// WorkspaceAgentLogs returns the logs of an agent matching the request.
+// Unless the request follows the logs, the channel receives a single chunk.
+func (c *Client) WorkspaceAgentLogs(ctx context.Context, agentID uuid.UUID, req WorkspaceAgentLogsRequest) (<-chan []WorkspaceAgentLog, io.Closer, error) {
 	var queryParams []string
-	if after != 0 {
-		queryParams = append(queryParams, fmt.Sprintf("after=%d", after))
+	if req.After != 0 {
+		queryParams = append(queryParams, fmt.Sprintf("after=%d", req.After))
 	}
-	if follow {
+	if req.Follow {
 		queryParams = append(queryParams, "follow")
 	}
+	for _, sourceID := range req.SourceIDs {
+		queryParams = append(queryParams, fmt.Sprintf("source=%s", sourceID))
+	}
+	if req.Level != "" {
+		queryParams = append(queryParams, fmt.Sprintf("level=%s", req.Level))
+	}
+	if !req.Since.IsZero() {
+		queryParams = append(queryParams, fmt.Sprintf("since=%s", req.Since.UTC().Format(time.RFC3339Nano)))
+	}
+	if req.Limit > 0 {
+		queryParams = append(queryParams, fmt.Sprintf("limit=%d", req.Limit))
+	}
 	var query string
 	if len(queryParams) > 0 {
 		query = "?" + strings.Join(queryParams, "&")
@@ -409,7 +697,7 @@ func (c *Client) WorkspaceAgentLogsAfter(ctx context.Context, agentID uuid.UUID,
 		return nil, nil, err
 	}
 
-	if !follow {
+	if !req.Follow {
 		resp, err := c.Request(ctx, http.MethodGet, reqURL.String(), nil)
 		if err != nil {
 			return nil, nil, xerrors.Errorf("execute request: %w", err)
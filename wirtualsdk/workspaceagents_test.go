@@ -0,0 +1,54 @@
+package wirtualsdk_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onchainengineering/hmi-wirtual/wirtualsdk"
+)
+
+func TestValidateWorkspaceAgentScriptDependencies(t *testing.T) {
+	t.Parallel()
+
+	database := uuid.New()
+	migrate := uuid.New()
+	seed := uuid.New()
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+		err := wirtualsdk.ValidateWorkspaceAgentScriptDependencies([]wirtualsdk.WorkspaceAgentScript{
+			{ID: seed, DisplayName: "seed", DependsOn: []uuid.UUID{migrate, database}},
+			{ID: migrate, DisplayName: "migrate", DependsOn: []uuid.UUID{database}},
+			{ID: database, DisplayName: "database"},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		t.Parallel()
+		err := wirtualsdk.ValidateWorkspaceAgentScriptDependencies([]wirtualsdk.WorkspaceAgentScript{
+			{ID: migrate, DisplayName: "migrate", DependsOn: []uuid.UUID{database}},
+		})
+		require.ErrorContains(t, err, `script "migrate" depends on unknown script`)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		t.Parallel()
+		err := wirtualsdk.ValidateWorkspaceAgentScriptDependencies([]wirtualsdk.WorkspaceAgentScript{
+			{ID: database, DisplayName: "database"},
+			{ID: migrate, DisplayName: "migrate", DependsOn: []uuid.UUID{database, seed}},
+			{ID: seed, DisplayName: "seed", DependsOn: []uuid.UUID{migrate}},
+		})
+		require.EqualError(t, err, "script dependency cycle: migrate -> seed -> migrate")
+	})
+
+	t.Run("SelfDependency", func(t *testing.T) {
+		t.Parallel()
+		err := wirtualsdk.ValidateWorkspaceAgentScriptDependencies([]wirtualsdk.WorkspaceAgentScript{
+			{ID: database, DependsOn: []uuid.UUID{database}},
+		})
+		require.EqualError(t, err, "script dependency cycle: "+database.String()+" -> "+database.String())
+	})
+}
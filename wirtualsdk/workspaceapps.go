@@ -61,7 +61,10 @@ type WorkspaceApp struct {
 	// Healthcheck specifies the configuration for checking app health.
 	Healthcheck Healthcheck        `json:"healthcheck"`
 	Health      WorkspaceAppHealth `json:"health"`
-	Hidden      bool               `json:"hidden"`
+	// HealthReason is why the last health check failed. It is empty unless
+	// the app is unhealthy.
+	HealthReason string `json:"health_reason,omitempty"`
+	Hidden       bool   `json:"hidden"`
 }
 
 type Healthcheck struct {
@@ -71,4 +74,22 @@ type Healthcheck struct {
 	Interval int32 `json:"interval"`
 	// Threshold specifies the number of consecutive failed health checks before returning "unhealthy".
 	Threshold int32 `json:"threshold"`
+	// TCP specifies a host:port that must accept connections, instead of
+	// requesting URL.
+	TCP string `json:"tcp,omitempty"`
+	// Command specifies a command that must exit with code zero, instead of
+	// requesting URL. It runs in the workspace like agent scripts do.
+	Command string `json:"command,omitempty"`
+	// ExpectedStatuses are the HTTP status codes that count as healthy. Any
+	// status below 500 is healthy when empty.
+	ExpectedStatuses []int32 `json:"expected_statuses,omitempty"`
+	// BodyRegex is a regular expression the HTTP response body must match.
+	BodyRegex string `json:"body_regex,omitempty"`
+	// Headers are added to the HTTP request.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Enabled reports whether a health check is configured.
+func (h Healthcheck) Enabled() bool {
+	return h.URL != "" || h.TCP != "" || h.Command != ""
 }
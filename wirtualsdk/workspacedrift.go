@@ -0,0 +1,67 @@
+package wirtualsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WorkspaceDriftAction string
+
+const (
+	// WorkspaceDriftActionUpdate means that attributes of the resource were
+	// changed outside of the builds of the workspace.
+	WorkspaceDriftActionUpdate WorkspaceDriftAction = "update"
+	// WorkspaceDriftActionDelete means that the resource no longer exists.
+	WorkspaceDriftActionDelete WorkspaceDriftAction = "delete"
+)
+
+// WorkspaceDrift is the result of the latest drift check of the running build
+// of a workspace. Drift checks run a refresh-only plan, which compares the
+// resources in the state of the build with the real infrastructure.
+type WorkspaceDrift struct {
+	BuildID     uuid.UUID `json:"build_id" format:"uuid"`
+	BuildNumber int32     `json:"build_number"`
+	// CheckedAt is when the latest check completed. It's nil when the build
+	// hasn't been checked yet.
+	CheckedAt *time.Time               `json:"checked_at,omitempty" format:"date-time"`
+	Resources []WorkspaceDriftResource `json:"resources"`
+}
+
+// WorkspaceDriftResource is a resource that was changed outside of the builds
+// of a workspace.
+type WorkspaceDriftResource struct {
+	Address    string                    `json:"address"`
+	Type       string                    `json:"type"`
+	Action     WorkspaceDriftAction      `json:"action" enums:"update,delete"`
+	Attributes []WorkspaceDriftAttribute `json:"attributes"`
+}
+
+// WorkspaceDriftAttribute is a top-level attribute of a resource whose value
+// changed. Values are JSON encoded, and are omitted when the attribute is
+// sensitive.
+type WorkspaceDriftAttribute struct {
+	Name      string `json:"name"`
+	Before    string `json:"before,omitempty"`
+	After     string `json:"after,omitempty"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// WorkspaceDrift returns the drift detected in the latest build of the
+// workspace.
+func (c *Client) WorkspaceDrift(ctx context.Context, workspaceID uuid.UUID) (WorkspaceDrift, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/drift", workspaceID), nil)
+	if err != nil {
+		return WorkspaceDrift{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceDrift{}, ReadBodyAsError(res)
+	}
+	var drift WorkspaceDrift
+	return drift, json.NewDecoder(res.Body).Decode(&drift)
+}
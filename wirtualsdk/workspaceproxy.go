@@ -43,6 +43,21 @@ type ProxyHealthReport struct {
 	// Warnings do not prevent the workspace proxy from being healthy, but
 	// should be addressed.
 	Warnings []string `json:"warnings"`
+	// Load is the load on the replica of the workspace proxy that served the
+	// health check. External autoscalers can use it to scale proxies.
+	Load ProxyLoadReport `json:"load"`
+}
+
+// ProxyLoadReport is the load on a workspace proxy replica, averaged over a
+// short window.
+type ProxyLoadReport struct {
+	// ActiveConnections is the number of in-flight requests, including
+	// long-lived connections such as terminals, app websockets and DERP.
+	ActiveConnections int64 `json:"active_connections"`
+	// RxBytesPerSecond is the rate of bytes received from clients.
+	RxBytesPerSecond float64 `json:"rx_bytes_per_second"`
+	// TxBytesPerSecond is the rate of bytes sent to clients.
+	TxBytesPerSecond float64 `json:"tx_bytes_per_second"`
 }
 
 type WorkspaceProxy struct {
@@ -50,6 +65,10 @@ type WorkspaceProxy struct {
 	Region      `table:"region,recursive_inline"`
 	DerpEnabled bool `json:"derp_enabled" table:"derp enabled"`
 	DerpOnly    bool `json:"derp_only" table:"derp only"`
+	// Draining proxies are not advertised to clients and are avoided as
+	// DERP home regions, but keep serving existing connections so they can
+	// be removed without dropping them.
+	Draining bool `json:"draining" table:"draining"`
 
 	// Status is the latest status check of the proxy. This will be empty for deleted
 	// proxies. This value can be used to determine if a workspace proxy is healthy
@@ -133,6 +152,31 @@ func (c *Client) PatchWorkspaceProxy(ctx context.Context, req PatchWorkspaceProx
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+type UpdateWorkspaceProxyDrainingRequest struct {
+	Draining bool `json:"draining"`
+}
+
+// UpdateWorkspaceProxyDraining starts or stops draining a workspace proxy. A
+// draining proxy is removed from the list of regions and avoided as a DERP
+// home region, so new connections go elsewhere while existing connections
+// finish.
+func (c *Client) UpdateWorkspaceProxyDraining(ctx context.Context, nameOrID string, req UpdateWorkspaceProxyDrainingRequest) (WorkspaceProxy, error) {
+	res, err := c.Request(ctx, http.MethodPut,
+		fmt.Sprintf("/api/v2/workspaceproxies/%s/draining", nameOrID),
+		req,
+	)
+	if err != nil {
+		return WorkspaceProxy{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceProxy{}, ReadBodyAsError(res)
+	}
+	var resp WorkspaceProxy
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 func (c *Client) DeleteWorkspaceProxyByName(ctx context.Context, name string) error {
 	res, err := c.Request(ctx, http.MethodDelete,
 		fmt.Sprintf("/api/v2/workspaceproxies/%s", name),
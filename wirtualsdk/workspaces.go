@@ -63,6 +63,9 @@ type Workspace struct {
 	AutomaticUpdates AutomaticUpdates `json:"automatic_updates" enums:"always,never"`
 	AllowRenames     bool             `json:"allow_renames"`
 	Favorite         bool             `json:"favorite"`
+	// HasDrift is true when the latest drift check of the running build found
+	// resources that were changed outside of the builds of the workspace.
+	HasDrift bool `json:"has_drift"`
 }
 
 func (w Workspace) FullName() string {
@@ -105,6 +108,10 @@ type CreateWorkspaceBuildRequest struct {
 
 	// Log level changes the default logging verbosity of a provider ("info" if empty).
 	LogLevel ProvisionerLogLevel `json:"log_level,omitempty" validate:"omitempty,oneof=debug"`
+	// Priority of the provisioner job of the build ("interactive" if empty).
+	// Bulk operations should use "bulk" so interactive builds of other users
+	// are acquired first.
+	Priority ProvisionerJobPriority `json:"priority,omitempty" validate:"omitempty,oneof=interactive bulk" enums:"interactive,bulk"`
 }
 
 type WorkspaceOptions struct {
@@ -1,6 +1,7 @@
 package workspacesdk
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"encoding/json"
@@ -9,7 +10,9 @@ import (
 	"net"
 	"net/http"
 	"net/netip"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -71,6 +74,18 @@ func (c *AgentConn) Ping(ctx context.Context) (time.Duration, bool, *ipnstate.Pi
 	return c.Conn.Ping(ctx, c.agentAddress())
 }
 
+// Diagnose measures latency, jitter, loss and the path MTU to the agent, and
+// detects the type of NAT the client is behind.
+func (c *AgentConn) Diagnose(ctx context.Context, opts tailnet.DiagnoseOptions) (*tailnet.ConnDiagnostics, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	if opts.MTUProbePort == 0 {
+		opts.MTUProbePort = AgentMTUProbePort
+	}
+	return c.Conn.Diagnose(ctx, c.agentAddress(), opts)
+}
+
 // Close ends the connection to the workspace agent.
 func (c *AgentConn) Close() error {
 	var cerr error
@@ -93,6 +108,21 @@ type AgentReconnectingPTYInit struct {
 	Height  uint16
 	Width   uint16
 	Command string
+	// Container is the name of the dev container to run the command in, or
+	// empty to run it in the workspace.
+	Container string `json:",omitempty"`
+}
+
+// AgentReconnectingPTYInitOption is a functional option for
+// AgentReconnectingPTYInit.
+type AgentReconnectingPTYInitOption func(*AgentReconnectingPTYInit)
+
+// AgentReconnectingPTYInitWithContainer runs the command of the reconnecting
+// PTY in the named dev container.
+func AgentReconnectingPTYInitWithContainer(container string) AgentReconnectingPTYInitOption {
+	return func(init *AgentReconnectingPTYInit) {
+		init.Container = container
+	}
 }
 
 // ReconnectingPTYRequest is sent from the client to the server
@@ -107,7 +137,7 @@ type ReconnectingPTYRequest struct {
 // ReconnectingPTY spawns a new reconnecting terminal session.
 // `ReconnectingPTYRequest` should be JSON marshaled and written to the returned net.Conn.
 // Raw terminal output will be read from the returned net.Conn.
-func (c *AgentConn) ReconnectingPTY(ctx context.Context, id uuid.UUID, height, width uint16, command string) (net.Conn, error) {
+func (c *AgentConn) ReconnectingPTY(ctx context.Context, id uuid.UUID, height, width uint16, command string, initOpts ...AgentReconnectingPTYInitOption) (net.Conn, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
@@ -119,12 +149,16 @@ func (c *AgentConn) ReconnectingPTY(ctx context.Context, id uuid.UUID, height, w
 	if err != nil {
 		return nil, err
 	}
-	data, err := json.Marshal(AgentReconnectingPTYInit{
+	init := AgentReconnectingPTYInit{
 		ID:      id,
 		Height:  height,
 		Width:   width,
 		Command: command,
-	})
+	}
+	for _, opt := range initOpts {
+		opt(&init)
+	}
+	data, err := json.Marshal(init)
 	if err != nil {
 		_ = conn.Close()
 		return nil, err
@@ -242,6 +276,257 @@ func (c *AgentConn) ListeningPorts(ctx context.Context) (wirtualsdk.WorkspaceAge
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+// WatchListeningPortsOptions configures WatchListeningPorts.
+type WatchListeningPortsOptions struct {
+	// ProbeProtocols makes the agent connect to each port to guess its
+	// protocol. Ports are reported again once their protocol is known.
+	ProbeProtocols bool
+	// SkipProbe are ports that are never probed.
+	SkipProbe []uint16
+}
+
+// WatchListeningPorts streams changes to the ports that are listening in the
+// workspace. The first events report the ports that are already listening.
+// The returned channel is never closed. Exactly one error will be sent on the
+// error channel, after which no more events are sent.
+func (c *AgentConn) WatchListeningPorts(ctx context.Context, opts WatchListeningPortsOptions) (<-chan wirtualsdk.WorkspaceAgentListeningPortEvent, <-chan error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	eventChan := make(chan wirtualsdk.WorkspaceAgentListeningPortEvent, 256)
+	errorChan := make(chan error, 1)
+	go func() {
+		defer close(errorChan)
+		errorChan <- c.watchListeningPorts(ctx, opts, eventChan)
+	}()
+	return eventChan, errorChan
+}
+
+func (c *AgentConn) watchListeningPorts(ctx context.Context, opts WatchListeningPortsOptions, eventChan chan<- wirtualsdk.WorkspaceAgentListeningPortEvent) error {
+	query := url.Values{}
+	if opts.ProbeProtocols {
+		query.Set("probe", "true")
+	}
+	if len(opts.SkipProbe) > 0 {
+		skip := make([]string, 0, len(opts.SkipProbe))
+		for _, port := range opts.SkipProbe {
+			skip = append(skip, strconv.Itoa(int(port)))
+		}
+		query.Set("skip_probe", strings.Join(skip, ","))
+	}
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/listening-ports/watch?"+query.Encode(), nil)
+	if err != nil {
+		return xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return wirtualsdk.ReadBodyAsError(res)
+	}
+
+	nextEvent := wirtualsdk.ServerSentEventReader(ctx, res.Body)
+	for {
+		sse, err := nextEvent()
+		if err != nil {
+			return err
+		}
+		// Ignore pings.
+		if sse.Type == wirtualsdk.ServerSentEventTypePing {
+			continue
+		}
+
+		b, ok := sse.Data.([]byte)
+		if !ok {
+			return xerrors.Errorf("unexpected data type: %T", sse.Data)
+		}
+
+		switch sse.Type {
+		case wirtualsdk.ServerSentEventTypeData:
+			var events []wirtualsdk.WorkspaceAgentListeningPortEvent
+			err = json.Unmarshal(b, &events)
+			if err != nil {
+				return xerrors.Errorf("unmarshal listening port events: %w", err)
+			}
+			for _, event := range events {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case eventChan <- event:
+				}
+			}
+		case wirtualsdk.ServerSentEventTypeError:
+			var r wirtualsdk.Response
+			err = json.Unmarshal(b, &r)
+			if err != nil {
+				return xerrors.Errorf("unmarshal error: %w", err)
+			}
+			return xerrors.Errorf("%+v", r)
+		default:
+			return xerrors.Errorf("unexpected event type: %s", sse.Type)
+		}
+	}
+}
+
+// Processes returns a snapshot of the busiest processes in the workspace. At
+// most limit processes are returned, or all of them if limit is zero.
+func (c *AgentConn) Processes(ctx context.Context, limit int) (wirtualsdk.WorkspaceAgentProcessesResponse, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, fmt.Sprintf("/api/v0/processes?limit=%d", limit), nil)
+	if err != nil {
+		return wirtualsdk.WorkspaceAgentProcessesResponse{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return wirtualsdk.WorkspaceAgentProcessesResponse{}, wirtualsdk.ReadBodyAsError(res)
+	}
+
+	var resp wirtualsdk.WorkspaceAgentProcessesResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// Services returns the services supervised by the workspace agent along
+// with their live state.
+func (c *AgentConn) Services(ctx context.Context) ([]wirtualsdk.WorkspaceAgentService, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/services", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, wirtualsdk.ReadBodyAsError(res)
+	}
+
+	var services []wirtualsdk.WorkspaceAgentService
+	return services, json.NewDecoder(res.Body).Decode(&services)
+}
+
+// RestartService restarts a service supervised by the workspace agent.
+func (c *AgentConn) RestartService(ctx context.Context, name string) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodPost, fmt.Sprintf("/api/v0/services/%s/restart", name), nil)
+	if err != nil {
+		return xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return wirtualsdk.ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// ServiceLogs returns the most recent output of a service supervised by the
+// workspace agent.
+func (c *AgentConn) ServiceLogs(ctx context.Context, name string) ([]byte, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, fmt.Sprintf("/api/v0/services/%s/logs", name), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, wirtualsdk.ReadBodyAsError(res)
+	}
+	bs, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, xerrors.Errorf("read response body: %w", err)
+	}
+	return bs, nil
+}
+
+// Devcontainers returns the dev containers found by the workspace agent along
+// with the state of their containers.
+func (c *AgentConn) Devcontainers(ctx context.Context) (wirtualsdk.WorkspaceAgentDevcontainersResponse, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/devcontainers", nil)
+	if err != nil {
+		return wirtualsdk.WorkspaceAgentDevcontainersResponse{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return wirtualsdk.WorkspaceAgentDevcontainersResponse{}, wirtualsdk.ReadBodyAsError(res)
+	}
+
+	var resp wirtualsdk.WorkspaceAgentDevcontainersResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// StartDevcontainer starts the container of a dev container, creating it if
+// it doesn't exist. The container is started in the background.
+func (c *AgentConn) StartDevcontainer(ctx context.Context, name string) error {
+	return c.devcontainerAction(ctx, name, "start")
+}
+
+// StopDevcontainer stops the container of a dev container.
+func (c *AgentConn) StopDevcontainer(ctx context.Context, name string) error {
+	return c.devcontainerAction(ctx, name, "stop")
+}
+
+// RebuildDevcontainer replaces the container of a dev container with one
+// built from its current config. The container is rebuilt in the
+// background.
+func (c *AgentConn) RebuildDevcontainer(ctx context.Context, name string) error {
+	return c.devcontainerAction(ctx, name, "rebuild")
+}
+
+func (c *AgentConn) devcontainerAction(ctx context.Context, name, action string) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodPost, fmt.Sprintf("/api/v0/devcontainers/%s/%s", name, action), nil)
+	if err != nil {
+		return xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return wirtualsdk.ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// DevcontainerLogs returns the output of the last build and start of a dev
+// container.
+func (c *AgentConn) DevcontainerLogs(ctx context.Context, name string) ([]byte, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, fmt.Sprintf("/api/v0/devcontainers/%s/logs", name), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, wirtualsdk.ReadBodyAsError(res)
+	}
+	bs, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, xerrors.Errorf("read response body: %w", err)
+	}
+	return bs, nil
+}
+
+// Exec runs a command in the workspace and calls onEvent for its output and
+// for its exit, as they happen. It returns once the command exited.
+func (c *AgentConn) Exec(ctx context.Context, req wirtualsdk.WorkspaceAgentExecRequest, onEvent func(wirtualsdk.WorkspaceAgentExecEvent) error) (wirtualsdk.WorkspaceAgentExecResult, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	body, err := json.Marshal(req)
+	if err != nil {
+		return wirtualsdk.WorkspaceAgentExecResult{}, xerrors.Errorf("marshal request: %w", err)
+	}
+	res, err := c.apiRequest(ctx, http.MethodPost, "/api/v0/exec", bytes.NewReader(body))
+	if err != nil {
+		return wirtualsdk.WorkspaceAgentExecResult{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return wirtualsdk.WorkspaceAgentExecResult{}, wirtualsdk.ReadBodyAsError(res)
+	}
+	return wirtualsdk.ReadWorkspaceAgentExecEvents(ctx, res.Body, onEvent)
+}
+
 // Netcheck returns a network check report from the workspace agent.
 func (c *AgentConn) Netcheck(ctx context.Context) (healthsdk.AgentNetcheckReport, error) {
 	ctx, span := tracing.StartSpan(ctx)
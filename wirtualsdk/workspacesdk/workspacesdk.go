@@ -34,10 +34,13 @@ const (
 	// AgentHTTPAPIServerPort serves a HTTP server with endpoints for e.g.
 	// gathering agent statistics.
 	AgentHTTPAPIServerPort = 4
+	// AgentMTUProbePort echoes the path MTU probes sent by `coder ping
+	// --diagnose`.
+	AgentMTUProbePort = tailnet.WorkspaceAgentMTUProbePort
 
 	// AgentMinimumListeningPort is the minimum port that the listening-ports
 	// endpoint will return to the client, and the minimum port that is accepted
-	// by the proxy applications endpoint. Coder consumes ports 1-4 at the
+	// by the proxy applications endpoint. Coder consumes ports 1-5 at the
 	// moment, and we reserve some extra ports for future use. Port 9 and up are
 	// available for the user.
 	//
@@ -169,6 +172,51 @@ func (c *Client) AgentConnectionInfo(ctx context.Context, agentID uuid.UUID) (Ag
 	return connInfo, json.NewDecoder(res.Body).Decode(&connInfo)
 }
 
+// preferredDERPRegionScore is the home region score given to the preferred
+// DERP region. Its latency is halved when picking the home region, so it wins
+// unless another region is considerably closer.
+const preferredDERPRegionScore = 0.5
+
+// preferDERPRegion returns a copy of derpMap that prefers the region with the
+// given code as the home region. derpMap is returned unchanged if no region
+// has the code.
+func preferDERPRegion(derpMap *tailcfg.DERPMap, regionCode string) *tailcfg.DERPMap {
+	if derpMap == nil || regionCode == "" {
+		return derpMap
+	}
+	regionID := 0
+	for id, region := range derpMap.Regions {
+		if region != nil && region.RegionCode == regionCode {
+			regionID = id
+			break
+		}
+	}
+	if regionID == 0 {
+		return derpMap
+	}
+
+	derpMap = derpMap.Clone()
+	if derpMap.HomeParams == nil {
+		derpMap.HomeParams = &tailcfg.DERPHomeParams{}
+	}
+	if derpMap.HomeParams.RegionScore == nil {
+		derpMap.HomeParams.RegionScore = map[int]float64{}
+	}
+	derpMap.HomeParams.RegionScore[regionID] = preferredDERPRegionScore
+	return derpMap
+}
+
+// preferredDERPRegionSetter applies the preferred DERP region to every DERP
+// map update received from the coordinator.
+type preferredDERPRegionSetter struct {
+	setter     tailnet.DERPMapSetter
+	regionCode string
+}
+
+func (s preferredDERPRegionSetter) SetDERPMap(derpMap *tailcfg.DERPMap) {
+	s.setter.SetDERPMap(preferDERPRegion(derpMap, s.regionCode))
+}
+
 // @typescript-ignore DialAgentOptions
 type DialAgentOptions struct {
 	Logger slog.Logger
@@ -181,6 +229,11 @@ type DialAgentOptions struct {
 	// Whether the client will send network telemetry events.
 	// Enable instead of Disable so it's initialized to false (in tests).
 	EnableTelemetry bool
+	// PreferredDERPRegionCode is the RegionCode of a DERP region to prefer as
+	// the home region, e.g. the region of the workspace proxy with the lowest
+	// latency to the client. It is ignored if the region is not in the DERP
+	// map.
+	PreferredDERPRegionCode string
 }
 
 func (c *Client) DialAgent(dialCtx context.Context, agentID uuid.UUID, options *DialAgentOptions) (agentConn *AgentConn, err error) {
@@ -240,7 +293,7 @@ func (c *Client) DialAgent(dialCtx context.Context, agentID uuid.UUID, options *
 	}
 	conn, err := tailnet.NewConn(&tailnet.Options{
 		Addresses:           []netip.Prefix{netip.PrefixFrom(ip, 128)},
-		DERPMap:             connInfo.DERPMap,
+		DERPMap:             preferDERPRegion(connInfo.DERPMap, options.PreferredDERPRegionCode),
 		DERPHeader:          &header,
 		DERPForceWebSockets: connInfo.DERPForceWebSockets,
 		Logger:              options.Logger,
@@ -260,7 +313,10 @@ func (c *Client) DialAgent(dialCtx context.Context, agentID uuid.UUID, options *
 	coordCtrl := tailnet.NewTunnelSrcCoordController(options.Logger, conn)
 	coordCtrl.AddDestination(agentID)
 	controller.CoordCtrl = coordCtrl
-	controller.DERPCtrl = tailnet.NewBasicDERPController(options.Logger, conn)
+	controller.DERPCtrl = tailnet.NewBasicDERPController(options.Logger, preferredDERPRegionSetter{
+		setter:     conn,
+		regionCode: options.PreferredDERPRegionCode,
+	})
 	controller.Run(ctx)
 
 	options.Logger.Debug(ctx, "running tailnet API v2+ connector")
@@ -300,6 +356,8 @@ type WorkspaceAgentReconnectingPTYOpts struct {
 	Width     uint16
 	Height    uint16
 	Command   string
+	// Container is the name of the dev container to open the terminal in.
+	Container string
 
 	// SignedToken is an optional signed token from the
 	// issue-reconnecting-pty-signed-token endpoint. If set, the session token
@@ -320,6 +378,9 @@ func (c *Client) AgentReconnectingPTY(ctx context.Context, opts WorkspaceAgentRe
 	q.Set("width", strconv.Itoa(int(opts.Width)))
 	q.Set("height", strconv.Itoa(int(opts.Height)))
 	q.Set("command", opts.Command)
+	if opts.Container != "" {
+		q.Set("container", opts.Container)
+	}
 	// If we're using a signed token, set the query parameter.
 	if opts.SignedToken != "" {
 		q.Set(wirtualsdk.SignedAppTokenQueryParameter, opts.SignedToken)
@@ -0,0 +1,39 @@
+package workspacesdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+)
+
+func TestPreferDERPRegion(t *testing.T) {
+	t.Parallel()
+
+	derpMap := &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			999:  {RegionID: 999, RegionCode: "coder"},
+			1001: {RegionID: 1001, RegionCode: "coder_sydney"},
+		},
+	}
+
+	t.Run("NoCode", func(t *testing.T) {
+		t.Parallel()
+		require.Same(t, derpMap, preferDERPRegion(derpMap, ""))
+	})
+
+	t.Run("UnknownRegion", func(t *testing.T) {
+		t.Parallel()
+		require.Same(t, derpMap, preferDERPRegion(derpMap, "coder_london"))
+	})
+
+	t.Run("Preferred", func(t *testing.T) {
+		t.Parallel()
+		got := preferDERPRegion(derpMap, "coder_sydney")
+		require.NotSame(t, derpMap, got)
+		require.NotNil(t, got.HomeParams)
+		require.Equal(t, map[int]float64{1001: preferredDERPRegionScore}, got.HomeParams.RegionScore)
+		// The original map must not be modified.
+		require.Nil(t, derpMap.HomeParams)
+	})
+}
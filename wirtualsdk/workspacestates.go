@@ -0,0 +1,93 @@
+package wirtualsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceState is the provisioner state stored by a build of a workspace.
+type WorkspaceState struct {
+	BuildID       uuid.UUID           `json:"build_id" format:"uuid"`
+	BuildNumber   int32               `json:"build_number"`
+	Transition    WorkspaceTransition `json:"transition" enums:"start,stop,delete"`
+	CreatedAt     time.Time           `json:"created_at" format:"date-time"`
+	InitiatorName string              `json:"initiator_name"`
+	// Size is the size of the state in bytes. It's zero when the state has
+	// been removed by the retention policy.
+	Size int `json:"size"`
+	// ResourceCount is the number of resources in the state. It's omitted
+	// when the state isn't a Terraform state.
+	ResourceCount *int `json:"resource_count,omitempty"`
+}
+
+// WorkspaceStateDiff lists the resources that differ between the states of
+// two builds of a workspace.
+type WorkspaceStateDiff struct {
+	FromBuildNumber int32                          `json:"from_build_number"`
+	ToBuildNumber   int32                          `json:"to_build_number"`
+	Added           []string                       `json:"added"`
+	Removed         []string                       `json:"removed"`
+	Changed         []WorkspaceStateResourceChange `json:"changed"`
+}
+
+// WorkspaceStateResourceChange is a resource whose attributes differ between
+// two states. Only the names of the attributes are included, since their
+// values may be sensitive.
+type WorkspaceStateResourceChange struct {
+	Address    string   `json:"address"`
+	Attributes []string `json:"attributes"`
+}
+
+// RestoreWorkspaceStateRequest restores the state of an earlier build.
+type RestoreWorkspaceStateRequest struct {
+	BuildNumber int32 `json:"build_number" validate:"required"`
+}
+
+// WorkspaceStates returns the states of every build of the workspace, newest
+// first.
+func (c *Client) WorkspaceStates(ctx context.Context, workspaceID uuid.UUID) ([]WorkspaceState, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/states", workspaceID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var states []WorkspaceState
+	return states, json.NewDecoder(res.Body).Decode(&states)
+}
+
+// WorkspaceStateDiff compares the states of two builds of the workspace.
+func (c *Client) WorkspaceStateDiff(ctx context.Context, workspaceID uuid.UUID, fromBuildNumber, toBuildNumber int32) (WorkspaceStateDiff, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/states/diff?from=%d&to=%d", workspaceID, fromBuildNumber, toBuildNumber), nil)
+	if err != nil {
+		return WorkspaceStateDiff{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceStateDiff{}, ReadBodyAsError(res)
+	}
+	var diff WorkspaceStateDiff
+	return diff, json.NewDecoder(res.Body).Decode(&diff)
+}
+
+// RestoreWorkspaceState starts a build of the workspace that uses the state
+// of an earlier build.
+func (c *Client) RestoreWorkspaceState(ctx context.Context, workspaceID uuid.UUID, req RestoreWorkspaceStateRequest) (WorkspaceBuild, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaces/%s/states/restore", workspaceID), req)
+	if err != nil {
+		return WorkspaceBuild{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return WorkspaceBuild{}, ReadBodyAsError(res)
+	}
+	var build WorkspaceBuild
+	return build, json.NewDecoder(res.Body).Decode(&build)
+}